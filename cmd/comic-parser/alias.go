@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// aliasCommands maps `comic-parser alias` subcommands to their handlers.
+var aliasCommands = map[string]func(args []string) error{
+	"add":    aliasAddCommand,
+	"list":   aliasListCommand,
+	"remove": aliasRemoveCommand,
+}
+
+// aliasCommand implements `comic-parser alias <subcommand>`, which manages
+// the series aliases the processor applies before searching. Without a
+// subcommand it prints the aliases.
+func aliasCommand(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return aliasListCommand(args)
+	}
+	cmd, ok := aliasCommands[args[0]]
+	if !ok {
+		return errors.New(aliasUsage())
+	}
+	return cmd(args[1:])
+}
+
+func aliasUsage() string {
+	names := make([]string, 0, len(aliasCommands))
+	for name := range aliasCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("usage: comic-parser alias [<%s>] [flags]", strings.Join(names, "|"))
+}
+
+// loadSeriesAliases reads the stored series aliases from store, or when it
+// is nil from the database at dbPath, for the processor to apply. It
+// returns nil when there are none.
+func loadSeriesAliases(store *storage.Storage, backend, dbPath string) []models.SeriesAlias {
+	store, closeStore, ok := openForLookup(store, backend, dbPath, "series aliases")
+	if !ok {
+		return nil
+	}
+	defer closeStore()
+
+	aliases, err := store.ListSeriesAliases(context.Background())
+	if err != nil {
+		slog.Warn("could not load series aliases", "error", err)
+		return nil
+	}
+	return aliases
+}
+
+// flagsFirst moves flags given after the positional arguments, as in
+// `alias add TMNT "Teenage Mutant Ninja Turtles" -volume-id 12345`, in front
+// of them, where the flag package looks for them. Every flag of the alias
+// and ignore subcommands takes a value.
+func flagsFirst(args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		if !strings.Contains(arg, "=") && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
+		}
+	}
+	return append(append(flags, "--"), positional...)
+}
+
+// aliasAddCommand stores an alias mapping a series name as filenames spell
+// it to the series searched for instead, optionally pinned to a ComicVine
+// volume. Without a series the name itself is searched, in the pinned
+// volume.
+func aliasAddCommand(args []string) error {
+	fs := flag.NewFlagSet("alias add", flag.ExitOnError)
+	volumeID := fs.Int("volume-id", 0, "ComicVine volume ID the series' issues are looked up in")
+	const usage = "usage: comic-parser alias add [-db path] [-volume-id id] <name> [<series>]"
+	store, err := parseListFlags(fs, flagsFirst(args), usage, 1, 2)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if *volumeID < 0 {
+		return fmt.Errorf("invalid volume ID %d", *volumeID)
+	}
+	alias := models.SeriesAlias{Name: fs.Arg(0), Series: fs.Arg(1), VolumeID: *volumeID}
+	if alias.Series == "" {
+		if alias.VolumeID == 0 {
+			return errors.New("alias add: give the series to search for, a -volume-id, or both")
+		}
+		alias.Series = alias.Name
+	}
+	if err := store.SaveSeriesAlias(context.Background(), alias); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is searched as %s", alias.Name, alias.Series)
+	if alias.VolumeID > 0 {
+		fmt.Printf(" in ComicVine volume %d", alias.VolumeID)
+	}
+	fmt.Println()
+	return nil
+}
+
+func aliasRemoveCommand(args []string) error {
+	fs := flag.NewFlagSet("alias remove", flag.ExitOnError)
+	store, err := parseListFlags(fs, flagsFirst(args), "usage: comic-parser alias remove [-db path] <name>", 1, 1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.DeleteSeriesAlias(context.Background(), fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Printf("Removed the alias of %s\n", fs.Arg(0))
+	return nil
+}
+
+// aliasListCommand prints the series aliases.
+func aliasListCommand(args []string) error {
+	fs := flag.NewFlagSet("alias list", flag.ExitOnError)
+	store, err := parseListFlags(fs, args, "usage: comic-parser alias list [-db path]", 0, 0)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	aliases, err := store.ListSeriesAliases(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(aliases) == 0 {
+		fmt.Println("No series aliases.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSERIES\tVOLUME\tADDED")
+	for _, a := range aliases {
+		volume := "-"
+		if a.VolumeID > 0 {
+			volume = fmt.Sprint(a.VolumeID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.Name, a.Series, volume, a.CreatedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
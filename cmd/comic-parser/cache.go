@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/llm"
+)
+
+const cacheUsage = "usage: comic-parser cache clear [flags]"
+
+// cacheCommand implements `comic-parser cache <subcommand>`.
+func cacheCommand(args []string) error {
+	if len(args) == 0 || args[0] != "clear" {
+		return errors.New(cacheUsage)
+	}
+	return cacheClearCommand(args[1:])
+}
+
+// cacheClearCommand deletes the cached ComicVine and LLM responses.
+func cacheClearCommand(args []string) error {
+	fs := flag.NewFlagSet("cache clear", flag.ExitOnError)
+	configFile := fs.String("config", "config.json", "Path to configuration file")
+	dir := fs.String("dir", "", "Cache directory (default from config: .cache)")
+	fs.Parse(args)
+
+	if *dir == "" {
+		cfg, err := config.LoadConfig(*configFile)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		*dir = cfg.CacheDir
+	}
+	if *dir == "" {
+		return errors.New("no cache directory configured")
+	}
+
+	removed, err := comicvine.ClearCache(*dir)
+	if err != nil {
+		return fmt.Errorf("clearing cache: %w", err)
+	}
+	removedLLM, err := llm.ClearCache(*dir)
+	if err != nil {
+		return fmt.Errorf("clearing LLM cache: %w", err)
+	}
+	fmt.Printf("Removed %d cached API responses and %d cached LLM responses from %s\n", removed, removedLLM, *dir)
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"comic-parser/internal/archive"
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// checkCommand implements `comic-parser check`, which reads every archive
+// under the given roots (or every stored file found locally), counts its
+// pages, stores its health and reports the damaged ones.
+func checkCommand(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	stored := fs.Bool("stored", false, "Report the damaged archives of earlier checks without reading any file")
+	asJSON := fs.Bool("json", false, "Print every check as JSON")
+	indent := addIndentFlag(fs)
+	damagedOut := fs.String("damaged-out", "", "Write the damaged archives to this list, one path per line")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: comic-parser check [flags] [roots or files...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	var checks []models.ArchiveCheck
+	if *stored {
+		if checks, err = store.ListArchiveChecks(ctx); err != nil {
+			return err
+		}
+	} else {
+		paths, err := checkPaths(ctx, store, fs.Args())
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			check, err := archive.Check(path)
+			if errors.Is(err, archive.ErrUnsupported) {
+				fmt.Fprintf(os.Stderr, "skip  %s: unsupported format\n", path)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("checking %s: %w", path, err)
+			}
+			if err := store.SaveArchiveCheck(ctx, check); err != nil {
+				return err
+			}
+			checks = append(checks, *check)
+		}
+	}
+
+	var damaged []string
+	for _, check := range checks {
+		if check.Health == models.ArchiveDamaged {
+			damaged = append(damaged, check.Path)
+		}
+	}
+	if *damagedOut != "" && len(damaged) > 0 {
+		if err := os.WriteFile(*damagedOut, []byte(strings.Join(damaged, "\n")+"\n"), 0644); err != nil {
+			return fmt.Errorf("writing damaged list: %w", err)
+		}
+	}
+
+	if *asJSON {
+		if checks == nil {
+			checks = []models.ArchiveCheck{}
+		}
+		return writeJSON(stdoutPath, checks, *indent)
+	}
+
+	for _, check := range checks {
+		if check.Health != models.ArchiveDamaged {
+			continue
+		}
+		fmt.Printf("%s (%d pages)\n", check.Path, check.PageCount)
+		for _, problem := range check.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+	if len(damaged) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("Archives: %d, healthy: %d, damaged: %d\n", len(checks), len(checks)-len(damaged), len(damaged))
+	if *damagedOut != "" && len(damaged) > 0 {
+		fmt.Printf("Wrote %d damaged archives to %s\n", len(damaged), *damagedOut)
+	}
+	return nil
+}
+
+// checkPaths returns the archives to check: those under each root or named
+// directly, or without arguments the stored files that exist locally.
+func checkPaths(ctx context.Context, store *storage.Storage, args []string) ([]string, error) {
+	if len(args) == 0 {
+		names, err := store.ListKnownFilenames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, name := range names {
+			if path := library.LocalPath(name); path != "" {
+				paths = append(paths, path)
+			}
+		}
+		return paths, nil
+	}
+
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			abs, err := filepath.Abs(arg)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, abs)
+			continue
+		}
+		files, err := library.Walk(arg)
+		if err != nil {
+			return nil, fmt.Errorf("scanning %s: %w", arg, err)
+		}
+		paths = append(paths, files...)
+	}
+	return paths, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// pipelineCommand names the flag-based parse/process pipeline. It is also
+// what runs when no subcommand is given.
+const pipelineCommand = "parse"
+
+// commands maps subcommand names to their handlers. Invocations that don't
+// start with a known subcommand fall through to the flag-based pipeline.
+var commands = map[string]func(args []string) error{
+	"alias":          aliasCommand,
+	"cache":          cacheCommand,
+	"check":          checkCommand,
+	"config":         configCommand,
+	"covers":         coversCommand,
+	"db":             dbCommand,
+	"enrich":         enrichCommand,
+	"ignore":         ignoreCommand,
+	"list":           listCommand,
+	"match":          matchCommand,
+	"organize":       organizeCommand,
+	"prompts":        promptsCommand,
+	"push":           pushCommand,
+	"reconcile":      reconcileCommand,
+	"rematch":        rematchCommand,
+	"review":         reviewCommand,
+	"runs":           runsCommand,
+	"serve":          serveCommand,
+	"status":         statusCommand,
+	"sync":           syncCommand,
+	"tv":             tvCommand,
+	"usage":          usageCommand,
+	"validate":       validateCommand,
+	"watch":          watchCommand,
+	"write-metadata": writeMetadataCommand,
+}
+
+// parseCommands maps `comic-parser parse` subcommands to their handlers.
+var parseCommands = map[string]func(args []string) error{
+	"eval": parseEvalCommand,
+}
+
+// usage prints the pipeline flags followed by the available subcommands.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [%s] [flags] [filenames...]\n", os.Args[0], pipelineCommand)
+	fmt.Fprintf(out, "       %s <command> [flags]\n\n", os.Args[0])
+	flag.PrintDefaults()
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	for name := range parseCommands {
+		names = append(names, pipelineCommand+" "+name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(out, "\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %s\n", name)
+	}
+}
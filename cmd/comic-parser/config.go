@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/keychain"
+)
+
+const configUsage = "usage: comic-parser config set-comicvine-key [flags] [key]"
+
+// configCommand implements `comic-parser config <subcommand>`.
+func configCommand(args []string) error {
+	if len(args) == 0 || args[0] != "set-comicvine-key" {
+		return errors.New(configUsage)
+	}
+	return configSetComicVineKeyCommand(args[1:])
+}
+
+// configSetComicVineKeyCommand stores the ComicVine API key in the config
+// file, or with -keychain in the OS keychain, removing it from the file.
+func configSetComicVineKeyCommand(args []string) error {
+	fs := flag.NewFlagSet("config set-comicvine-key", flag.ExitOnError)
+	configFile := fs.String("config", "config.json", "Path to configuration file")
+	useKeychain := fs.Bool("keychain", false, "Store the key in the OS keychain instead of the config file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), configUsage)
+		fmt.Fprintln(fs.Output(), "The key is read from standard input when not given, keeping it out of shell history.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return errors.New(configUsage)
+	}
+
+	key := fs.Arg(0)
+	if key == "" {
+		fmt.Fprint(os.Stderr, "ComicVine API key: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("reading key: %w", err)
+		}
+		key = line
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return errors.New("no key given")
+	}
+
+	// Environment keys are left out so they don't end up in the file
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	if *useKeychain {
+		kc, err := keychain.Open()
+		if err != nil {
+			return err
+		}
+		if err := kc.Set(config.KeychainComicVineAccount, key); err != nil {
+			return err
+		}
+		cfg.ComicVineAPIKey = ""
+		cfg.Keychain = true
+		if err := cfg.SaveConfig(*configFile); err != nil {
+			return err
+		}
+		fmt.Printf("Stored the ComicVine API key in the keychain; %s no longer holds it\n", *configFile)
+		return nil
+	}
+
+	if cfg.Keychain {
+		// The keychain's key would otherwise keep taking precedence
+		kc, err := keychain.Open()
+		if err != nil {
+			return fmt.Errorf("%w (to keep using the file, set \"keychain\": false in %s)", err, *configFile)
+		}
+		if err := kc.Delete(config.KeychainComicVineAccount); err != nil {
+			return err
+		}
+		cfg.Keychain = false
+	}
+	cfg.ComicVineAPIKey = key
+	if err := cfg.SaveConfig(*configFile); err != nil {
+		return err
+	}
+	fmt.Printf("Stored the ComicVine API key in %s\n", *configFile)
+	return nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/covers"
+	"comic-parser/internal/storage"
+)
+
+const coversUsage = "usage: comic-parser covers <download|prune> [flags]"
+
+// coversCommand implements `comic-parser covers <subcommand>`.
+func coversCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(coversUsage)
+	}
+	switch args[0] {
+	case "download":
+		return coversDownloadCommand(args[1:])
+	case "prune":
+		return coversPruneCommand(args[1:])
+	}
+	return errors.New(coversUsage)
+}
+
+// coverCache opens the cover cache under the cache directory, which -dir
+// overrides.
+func coverCache(configFile, dir string) (*covers.Cache, error) {
+	if dir == "" {
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading config: %w", err)
+		}
+		dir = cfg.CacheDir
+	}
+	if dir == "" {
+		return nil, errors.New("no cache directory configured")
+	}
+	return covers.NewCache(dir, &http.Client{Timeout: 60 * time.Second}), nil
+}
+
+// coversDownloadCommand caches the covers of stored matches that are not
+// cached yet, e.g. for matches made without -download-covers.
+func coversDownloadCommand(args []string) error {
+	fs := flag.NewFlagSet("covers download", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file")
+	dir := fs.String("dir", "", "Cache directory (default from config: .cache)")
+	fs.Parse(args)
+
+	cache, err := coverCache(*configFile, *dir)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return err
+	}
+
+	var downloaded, failed int
+	seen := make(map[int]bool)
+	for _, r := range results {
+		issue := r.Match.SelectedIssue
+		if seen[issue.ID] {
+			continue
+		}
+		seen[issue.ID] = true
+
+		n, err := cache.Download(ctx, issue)
+		downloaded += n
+		if err != nil {
+			fmt.Printf("fail  %s: %v\n", r.Filename, err)
+			failed++
+		}
+	}
+
+	fmt.Printf("Downloaded %d cover images for %d issues (%d failed)\n", downloaded, len(seen), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d issues' covers could not be downloaded", failed)
+	}
+	return nil
+}
+
+// coversPruneCommand deletes cached covers of issues that no stored match
+// or mapping references anymore.
+func coversPruneCommand(args []string) error {
+	fs := flag.NewFlagSet("covers prune", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file")
+	dir := fs.String("dir", "", "Cache directory (default from config: .cache)")
+	dryRun := fs.Bool("dry-run", false, "Report what would be removed without deleting anything")
+	fs.Parse(args)
+
+	cache, err := coverCache(*configFile, *dir)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	referenced := make(map[int]bool)
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range results {
+		referenced[r.Match.SelectedIssue.ID] = true
+	}
+	mappings, err := store.ListMappings(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range mappings {
+		referenced[m.Issue.ID] = true
+	}
+
+	removed, err := cache.Prune(func(id int) bool { return referenced[id] }, *dryRun)
+	if err != nil {
+		return fmt.Errorf("pruning covers: %w", err)
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s cached covers of %d unreferenced issues\n", verb, removed)
+	return nil
+}
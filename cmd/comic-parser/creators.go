@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// dbCreatorsCommand lists the stored files whose matched issue credits a
+// creator.
+func dbCreatorsCommand(args []string) error {
+	return appearancesCommand("creators", "creator", args, library.FindCreatorAppearances)
+}
+
+// dbCharactersCommand lists the stored files whose matched issue features a
+// character.
+func dbCharactersCommand(args []string) error {
+	return appearancesCommand("characters", "character", args, library.FindCharacterAppearances)
+}
+
+// appearancesCommand implements `comic-parser db creators` and
+// `comic-parser db characters`, which differ only in what find looks at.
+func appearancesCommand(name, noun string, args []string, find func([]*models.ProcessingResult, string) []library.Appearance) error {
+	usage := fmt.Sprintf(`usage: comic-parser db %s [-db path] [-format text|json|csv] [-indent n] -name "%s name"`, name, noun)
+	fs := flag.NewFlagSet("db "+name, flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	query := fs.String("name", "", fmt.Sprintf("Name of the %s, or part of it (case-insensitive)", noun))
+	format := fs.String("format", "text", "Output format: text, json or csv")
+	indent := addIndentFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *query == "" {
+		return errors.New(usage)
+	}
+	if *format != "text" && *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown format %q (want text, json or csv)", *format)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	matched, err := store.ListMatchedResults(context.Background())
+	if err != nil {
+		return err
+	}
+	appearances := find(matched, *query)
+
+	switch *format {
+	case "json":
+		if appearances == nil {
+			appearances = []library.Appearance{}
+		}
+		return writeJSON(stdoutPath, appearances, *indent)
+	case "csv":
+		return writeAppearancesCSV(appearances)
+	}
+
+	if len(appearances) == 0 {
+		fmt.Println("No matches.")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, a := range appearances {
+			fmt.Fprintf(w, "%s #%s\t%s\t%s", a.Series, a.IssueNumber, a.CoverDate, a.Name)
+			if a.Role != "" {
+				fmt.Fprintf(w, " (%s)", a.Role)
+			}
+			fmt.Fprintf(w, "\t%s\n", a.Filename)
+		}
+		w.Flush()
+	}
+
+	// Credits are only known for enriched issues, so say when some are not
+	missing := 0
+	for _, r := range matched {
+		if r.Match != nil && r.Match.SelectedIssue != nil && !r.Match.SelectedIssue.HasDetails() {
+			missing++
+		}
+	}
+	if missing > 0 {
+		fmt.Printf("\n%d matched issues have no details yet; run `comic-parser enrich` to include them.\n", missing)
+	}
+	return nil
+}
+
+func writeAppearancesCSV(appearances []library.Appearance) error {
+	header := []string{"Filename", "Path", "ComicVine_ID", "Series", "Issue", "Cover_Date", "Name", "Role"}
+	rows := make([][]string, len(appearances))
+	for i, a := range appearances {
+		rows[i] = []string{a.Filename, a.Path, strconv.Itoa(a.ComicVineID), a.Series, a.IssueNumber, a.CoverDate, a.Name, a.Role}
+	}
+	return writeCSV(stdoutPath, header, rows)
+}
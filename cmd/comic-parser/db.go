@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/catalog"
+	"comic-parser/internal/corpus"
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// dbCommands maps `comic-parser db` subcommands to their handlers.
+var dbCommands = map[string]func(args []string) error{
+	"characters":      dbCharactersCommand,
+	"creators":        dbCreatorsCommand,
+	"delete":          dbDeleteCommand,
+	"duplicates":      dbDuplicatesCommand,
+	"edit":            dbEditCommand,
+	"export":          dbExportCommand,
+	"gaps":            dbGapsCommand,
+	"import":          dbImportCommand,
+	"import-mappings": dbImportMappingsCommand,
+	"migrate":         dbMigrateCommand,
+	"prune":           dbPruneCommand,
+	"purge":           dbPurgeCommand,
+	"restore":         dbRestoreCommand,
+	"search":          dbSearchCommand,
+	"stats":           dbStatsCommand,
+	"undelete":        dbUndeleteCommand,
+	"verify":          dbVerifyCommand,
+}
+
+// dbCommand implements `comic-parser db <subcommand>`.
+func dbCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(dbUsage())
+	}
+	cmd, ok := dbCommands[args[0]]
+	if !ok {
+		return errors.New(dbUsage())
+	}
+	return cmd(args[1:])
+}
+
+func dbUsage() string {
+	names := make([]string, 0, len(dbCommands))
+	for name := range dbCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("usage: comic-parser db <%s> [flags]", strings.Join(names, "|"))
+}
+
+// dbMigrateCommand applies pending schema migrations, which opening the
+// database does anyway, or with -status lists them without applying any.
+func dbMigrateCommand(args []string) error {
+	fs := flag.NewFlagSet("db migrate", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	status := fs.Bool("status", false, "List applied and pending migrations without migrating")
+	fs.Parse(args)
+
+	if *status {
+		migrations, err := storage.ReadMigrationStatus(*dbPath)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		current := 0
+		for _, m := range migrations {
+			applied := "pending"
+			if m.Applied() {
+				applied = m.AppliedAt.Local().Format(time.DateTime)
+				current = m.Version
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", m.Version, m.Name, applied)
+		}
+		w.Flush()
+		fmt.Printf("\nSchema version: %d\n", current)
+		return nil
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	version, err := store.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schema version: %d\n", version)
+	return nil
+}
+
+// dbPruneCommand removes records whose files no longer exist and cleans up
+// ComicVine volumes and issues that nothing references anymore.
+func dbPruneCommand(args []string) error {
+	fs := flag.NewFlagSet("db prune", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	root := fs.String("root", "", "Library root; records for files missing under it are pruned (bare filenames are only checked with a root)")
+	dryRun := fs.Bool("dry-run", false, "Report what would be removed without changing the database")
+	fs.Parse(args)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	stored, err := store.ListKnownFilenames(ctx)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	if *root != "" {
+		files, err := library.Walk(*root)
+		if err != nil {
+			return fmt.Errorf("scanning %s: %w", *root, err)
+		}
+		report, err := library.Reconcile(*root, files, stored)
+		if err != nil {
+			return err
+		}
+		missing = report.Missing
+	} else {
+		missing = library.MissingPaths(stored)
+	}
+
+	stats, err := store.Prune(ctx, missing, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+		printList("Missing files", missing)
+		fmt.Println()
+	}
+	fmt.Printf("%s %d records for missing files, %d orphaned issues, %d orphaned volumes\n",
+		verb, stats.Records, stats.Issues, stats.Volumes)
+	return nil
+}
+
+// dbExportCommand writes parses and verified matches as a portable corpus,
+// or with -format the whole database as an SQL dump or Parquet files, or the
+// matched comics as an XML listing or an OPDS catalog feed.
+func dbExportCommand(args []string) error {
+	fs := flag.NewFlagSet("db export", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	output := fs.String("o", "", "Output file (default stdout), or directory for parquet")
+	format := fs.String("format", "corpus", "Export format: corpus, sql (a dump db restore reads), parquet (a file per table), xml or opds (matched comics)")
+	tables := fs.String("tables", "", "Comma-separated tables to export rows of with sql or parquet (default: all)")
+	allMatches := fs.Bool("all-matches", false, "Include medium and low confidence matches, not just high confidence ones")
+	language := fs.String("language", "", "Export only original releases (original), translated ones (translated) or one language (e.g. fr)")
+	fs.Parse(args)
+
+	var tableNames []string
+	if *tables != "" {
+		if *format != "sql" && *format != "parquet" {
+			return errors.New("-tables needs -format sql or parquet")
+		}
+		for _, t := range strings.Split(*tables, ",") {
+			tableNames = append(tableNames, strings.TrimSpace(t))
+		}
+	}
+	if *format == "parquet" && *output == "" {
+		return errors.New("-format parquet needs an output directory: -o dir")
+	}
+	if *language != "" && *format != "corpus" && *format != "xml" && *format != "opds" {
+		return errors.New("-language needs -format corpus, xml or opds")
+	}
+	if !models.ValidLanguageFilter(*language) {
+		return fmt.Errorf("unknown language %q (want original, translated or a language like fr)", *language)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	switch *format {
+	case "corpus", "sql", "xml", "opds":
+	case "parquet":
+		return exportParquet(ctx, store, *output, tableNames)
+	default:
+		return fmt.Errorf("unknown format %q (want corpus, sql, parquet, xml or opds)", *format)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if *format == "sql" {
+		if err := store.DumpSQL(ctx, w, tableNames); err != nil {
+			return err
+		}
+		if *output != "" {
+			fmt.Printf("Dumped %s to %s\n", *dbPath, *output)
+		}
+		return nil
+	}
+
+	if *format == "xml" || *format == "opds" {
+		n, err := exportCatalog(ctx, store, w, *format, *language, *allMatches)
+		if err != nil {
+			return err
+		}
+		if *output != "" {
+			fmt.Printf("Exported %d comics to %s\n", n, *output)
+		}
+		return nil
+	}
+
+	opts := corpus.ExportOptions{Language: *language}
+	if *allMatches {
+		opts.MatchConfidences = []string{"high", "medium", "low"}
+	}
+	c, err := corpus.Export(ctx, store, w, opts)
+	if err != nil {
+		return err
+	}
+
+	if *output != "" {
+		fmt.Printf("Exported %d parses and %d matches to %s\n", len(c.Parses), len(c.Matches), *output)
+	}
+	return nil
+}
+
+// exportCatalog writes the matched comics of the language filter, only the
+// high confidence ones unless allMatches is set, as an XML listing or an
+// OPDS acquisition feed linking to the local files. It returns the number of
+// comics written.
+func exportCatalog(ctx context.Context, store *storage.Storage, w io.Writer, format, language string, allMatches bool) (int, error) {
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return 0, err
+	}
+	kept := results[:0]
+	for _, result := range results {
+		if (allMatches || result.Match.MatchConfidence == "high") && models.MatchesLanguage(result.Match.ParsedInfo.Language, language) {
+			kept = append(kept, result)
+		}
+	}
+
+	if format == "xml" {
+		return len(kept), catalog.NewLibrary(kept, time.Now()).Write(w)
+	}
+	feed := catalog.NewAcquisitionFeed("urn:xander:catalog:all", "Comics", "", kept, catalog.Links{
+		File: func(result *models.ProcessingResult) string {
+			if result.Path == "" {
+				return ""
+			}
+			return (&url.URL{Scheme: "file", Path: filepath.ToSlash(result.Path)}).String()
+		},
+	})
+	return len(kept), feed.Write(w)
+}
+
+// exportParquet writes each table as <table>.parquet in dir.
+func exportParquet(ctx context.Context, store *storage.Storage, dir string, tables []string) error {
+	if len(tables) == 0 {
+		all, err := store.Tables(ctx)
+		if err != nil {
+			return err
+		}
+		tables = all
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	for _, table := range tables {
+		path := filepath.Join(dir, table+".parquet")
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		n, err := store.WriteParquet(ctx, f, table)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return err
+		}
+		fmt.Printf("%-24s %d rows\n", table, n)
+	}
+	fmt.Printf("Exported %d tables to %s\n", len(tables), dir)
+	return nil
+}
+
+// dbRestoreCommand creates a database from a dump written by
+// `db export -format sql`.
+func dbRestoreCommand(args []string) error {
+	fs := flag.NewFlagSet("db restore", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path to create; must not exist")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: comic-parser db restore [-db path] <dump.sql>")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("opening dump: %w", err)
+	}
+	defer f.Close()
+
+	if err := storage.Restore(context.Background(), *dbPath, f); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %s from %s\n", *dbPath, fs.Arg(0))
+	return nil
+}
+
+// dbImportCommand imports a corpus written by db export, or a CSV or JSON
+// table from another cataloging tool whose columns are mapped to series,
+// issue and the other fields with -map, by name, or interactively.
+func dbImportCommand(args []string) error {
+	fs := flag.NewFlagSet("db import", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	format := fs.String("format", "", "Input format: corpus, csv or json (default from the file: .csv is csv, a JSON array is a json table)")
+	mapSpec := fs.String("map", "", `Table columns of fields, e.g. "series=Series Name,issue=Number" (default: columns named like the fields)`)
+	interactive := fs.Bool("interactive", false, "Ask for the column of each field")
+	dryRun := fs.Bool("dry-run", false, "Validate the rows and report what would be imported without importing")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: comic-parser db import [-db path] [-format corpus|csv|json] [-map field=column,...] [-interactive] [-dry-run] <file>")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("opening import file: %w", err)
+	}
+	if *format == "" {
+		switch {
+		case strings.EqualFold(filepath.Ext(fs.Arg(0)), ".csv"):
+			*format = "csv"
+		case corpus.IsCorpus(data):
+			*format = "corpus"
+		default:
+			*format = "json"
+		}
+	}
+
+	var c *corpus.Corpus
+	switch *format {
+	case "corpus":
+		if *mapSpec != "" || *interactive {
+			return errors.New("corpus files need no column mapping")
+		}
+		c, err = corpus.Read(bytes.NewReader(data))
+	case "csv", "json":
+		c, err = readImportTable(data, *format, *mapSpec, *interactive)
+	default:
+		return fmt.Errorf("unknown format %q (want corpus, csv or json)", *format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Printf("Would import %d parses and %d matches\n", len(c.Parses), len(c.Matches))
+		return nil
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	if err := corpus.Import(context.Background(), store, c); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d parses and %d matches\n", len(c.Parses), len(c.Matches))
+	return nil
+}
+
+// readImportTable reads a CSV or JSON table and converts it to a corpus with
+// the column mapping, reporting the rows it skips.
+func readImportTable(data []byte, format, mapSpec string, interactive bool) (*corpus.Corpus, error) {
+	var table *corpus.Table
+	var err error
+	if format == "csv" {
+		table, err = corpus.ReadCSV(bytes.NewReader(data))
+	} else {
+		table, err = corpus.ReadJSONTable(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	explicit, err := corpus.ParseMapping(mapSpec)
+	if err != nil {
+		return nil, err
+	}
+	mapping := corpus.GuessMapping(table.Columns).Merge(explicit)
+	if interactive {
+		mapping, err = corpus.PromptMapping(os.Stdin, os.Stdout, table.Columns, mapping)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := mapping.Validate(table.Columns); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Mapping: %s\n", mapping)
+
+	c, problems := corpus.FromTable(table, mapping)
+	for _, p := range problems {
+		fmt.Printf("skip  %v\n", p)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("Skipped %d of %d rows\n", len(problems), len(table.Rows))
+	}
+	return c, nil
+}
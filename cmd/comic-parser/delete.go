@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/storage"
+)
+
+// resultFlags are the flags selecting processing results by database ID or
+// by matched series and issue.
+type resultFlags struct {
+	id     *int64
+	series *string
+	issue  *string
+}
+
+func addResultFlags(fs *flag.FlagSet) resultFlags {
+	return resultFlags{
+		id:     fs.Int64("id", 0, "Database ID of the processing result"),
+		series: fs.String("series", "", "ComicVine volume name of the matched results (with -issue)"),
+		issue:  fs.String("issue", "", "Issue number of the matched results (with -series)"),
+	}
+}
+
+func (f resultFlags) set() bool {
+	return *f.id != 0 || *f.series != "" || *f.issue != ""
+}
+
+// selectResults returns the results the flags select that are soft-deleted
+// when deleted is set, or not deleted otherwise.
+func (f resultFlags) selectResults(ctx context.Context, store *storage.Storage, usage string, deleted bool) ([]storage.ResultRef, error) {
+	var refs []storage.ResultRef
+	switch {
+	case *f.id != 0 && *f.series == "" && *f.issue == "":
+		ref, err := store.GetResultRef(ctx, *f.id)
+		if err != nil {
+			return nil, err
+		}
+		refs = []storage.ResultRef{*ref}
+	case *f.id == 0 && *f.series != "" && *f.issue != "":
+		var err error
+		if refs, err = store.FindResults(ctx, *f.series, *f.issue); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(usage)
+	}
+
+	var selected []storage.ResultRef
+	for _, ref := range refs {
+		if !ref.DeletedAt.IsZero() == deleted {
+			selected = append(selected, ref)
+		}
+	}
+	if len(selected) == 0 {
+		state := "stored"
+		if deleted {
+			state = "deleted"
+		}
+		return nil, fmt.Errorf("no matching %s results", state)
+	}
+	return selected, nil
+}
+
+func resultIDs(refs []storage.ResultRef) []int64 {
+	ids := make([]int64, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, ref.ID)
+	}
+	return ids
+}
+
+// dbDeleteCommand soft-deletes processing results, hiding them until they
+// are undeleted or purged.
+func dbDeleteCommand(args []string) error {
+	const usage = "usage: comic-parser db delete [-db path] (-id N | -series name -issue number)"
+	fs := flag.NewFlagSet("db delete", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	rf := addResultFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New(usage)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	refs, err := rf.selectResults(ctx, store, usage, false)
+	if err != nil {
+		return err
+	}
+	if err := store.SoftDeleteResults(ctx, resultIDs(refs)); err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		fmt.Printf("Deleted %d\t%s\n", ref.ID, ref.Filename)
+	}
+	fmt.Println("Restore with `comic-parser db undelete`, or remove for good with `comic-parser db purge`")
+	return nil
+}
+
+// dbUndeleteCommand restores soft-deleted processing results, or with -list
+// shows them.
+func dbUndeleteCommand(args []string) error {
+	const usage = "usage: comic-parser db undelete [-db path] (-list | -id N | -series name -issue number)"
+	fs := flag.NewFlagSet("db undelete", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	list := fs.Bool("list", false, "List the deleted results instead of restoring any")
+	rf := addResultFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 0 || *list == rf.set() {
+		return errors.New(usage)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if *list {
+		deleted, err := store.ListDeletedResults(ctx)
+		if err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			fmt.Println("No deleted results")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tDELETED\tFILENAME")
+		for _, ref := range deleted {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", ref.ID, ref.DeletedAt.Local().Format(time.DateTime), ref.Filename)
+		}
+		return w.Flush()
+	}
+
+	refs, err := rf.selectResults(ctx, store, usage, true)
+	if err != nil {
+		return err
+	}
+	if err := store.UndeleteResults(ctx, resultIDs(refs)); err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		fmt.Printf("Restored %d\t%s\n", ref.ID, ref.Filename)
+	}
+	return nil
+}
+
+// dbPurgeCommand permanently removes soft-deleted results and vacuums the
+// database.
+func dbPurgeCommand(args []string) error {
+	fs := flag.NewFlagSet("db purge", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	dryRun := fs.Bool("dry-run", false, "Report what would be removed without changing the database")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New("usage: comic-parser db purge [-db path] [-dry-run]")
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	stats, err := store.Purge(context.Background(), *dryRun)
+	if err != nil {
+		return err
+	}
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d deleted records, %d orphaned issues, %d orphaned volumes\n",
+		verb, stats.Records, stats.Issues, stats.Volumes)
+	return nil
+}
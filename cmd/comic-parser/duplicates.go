@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"comic-parser/internal/library"
+	"comic-parser/internal/storage"
+)
+
+// dbDuplicatesCommand reports stored files that resolve to the same issue,
+// or with -identical that have the same content, optionally recommending
+// which copy of each to keep.
+func dbDuplicatesCommand(args []string) error {
+	fs := flag.NewFlagSet("db duplicates", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	recommend := fs.Bool("recommend", false, "Mark the copy of each issue to keep and the ones to delete")
+	asJSON := fs.Bool("json", false, "Print the groups as JSON, with the recommendation in each file's keep field")
+	indent := addIndentFlag(fs)
+	identical := fs.Bool("identical", false, "Group files by content hash instead of by issue, whatever their names")
+	fs.Parse(args)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	groups, err := findDuplicates(context.Background(), store, *identical)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if groups == nil {
+			groups = []library.DuplicateGroup{}
+		}
+		return writeJSON(stdoutPath, groups, *indent)
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No duplicates.")
+		return nil
+	}
+	files := 0
+	for _, g := range groups {
+		heading := fmt.Sprintf("%s #%s", g.Series, g.IssueNumber)
+		if hash, ok := strings.CutPrefix(g.Key, "sha256:"); ok {
+			heading = "Identical files (SHA-256 " + hash[:12] + ")"
+		} else if g.ComicVineID != 0 {
+			heading += fmt.Sprintf(" (ComicVine %d)", g.ComicVineID)
+		} else {
+			heading += " (unmatched)"
+		}
+		fmt.Println(heading)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range g.Files {
+			fmt.Fprintf(w, "  %s\t%s\t%s", f.Filename, formatSize(f.Size), strings.Join(f.Tags, ","))
+			if *recommend {
+				action := "delete"
+				if f.Keep {
+					action = "keep"
+				}
+				fmt.Fprintf(w, "\t%s", action)
+			}
+			fmt.Fprintln(w)
+		}
+		w.Flush()
+		files += len(g.Files)
+	}
+	if *identical {
+		fmt.Printf("\nFiles stored more than once: %d (%d copies)\n", len(groups), files)
+	} else {
+		fmt.Printf("\nIssues stored more than once: %d (%d files)\n", len(groups), files)
+	}
+	return nil
+}
+
+// findDuplicates groups the stored files by issue, or by content hash when
+// identical is set.
+func findDuplicates(ctx context.Context, store *storage.Storage, identical bool) ([]library.DuplicateGroup, error) {
+	if identical {
+		hashed, err := store.ListFileHashes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return library.FindIdenticalFiles(hashed), nil
+	}
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := store.ListParsedFilenames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return library.FindDuplicates(matched, parsed), nil
+}
+
+// formatSize prints a file size in megabytes, or "-" when it's unknown.
+func formatSize(size int64) string {
+	if size == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+}
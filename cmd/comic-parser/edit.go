@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/storage"
+	"comic-parser/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const editUsage = "usage: comic-parser db edit [-db path] [-set field=value]... [-history] (<id> | -file filename)"
+
+// fieldValues collects repeated -set field=value flags.
+type fieldValues map[string]string
+
+func (f fieldValues) String() string {
+	pairs := make([]string, 0, len(f))
+	for field, value := range f {
+		pairs = append(pairs, field+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldValues) Set(s string) error {
+	field, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected field=value, got %q", s)
+	}
+	f[strings.ToLower(strings.TrimSpace(field))] = value
+	return nil
+}
+
+// dbEditCommand corrects the stored parse of a file, given by processing
+// result ID or filename, from -set flags or an interactive form, or with
+// -history shows its audit trail.
+func dbEditCommand(args []string) error {
+	fs := flag.NewFlagSet("db edit", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	file := fs.String("file", "", "Filename of the record, for files without a processing result ID")
+	history := fs.Bool("history", false, "Show the record's edits instead of editing it")
+	changes := fieldValues{}
+	fs.Var(changes, "set", "Set a field: "+strings.Join(storage.EditFields, ", ")+" (repeatable)")
+	fs.Parse(args)
+	if (fs.NArg() == 1) == (*file != "") || fs.NArg() > 1 {
+		return errors.New(editUsage)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	filename := *file
+	if filename == "" {
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", fs.Arg(0))
+		}
+		ref, err := store.GetResultRef(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ref.DeletedAt.IsZero() {
+			return fmt.Errorf("result %d is deleted; restore it with `comic-parser db undelete -id %d` first", id, id)
+		}
+		filename = ref.Filename
+	}
+
+	if *history {
+		edits, err := store.ListRecordEdits(ctx, filename)
+		if err != nil {
+			return err
+		}
+		if len(edits) == 0 {
+			fmt.Printf("No edits of %s\n", filename)
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "EDITED\tSOURCE\tFIELD\tOLD\tNEW")
+		for _, e := range edits {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%q\t%q\n", e.EditedAt.Local().Format(time.DateTime), e.Source, e.Field, e.OldValue, e.NewValue)
+		}
+		return w.Flush()
+	}
+
+	var edits []storage.RecordEdit
+	if len(changes) > 0 {
+		if edits, err = store.EditRecord(ctx, filename, changes, storage.EditSourceManual); err != nil {
+			return err
+		}
+	} else {
+		model, err := tui.NewEditModel(ctx, store, filename)
+		if err != nil {
+			return err
+		}
+		final, err := tea.NewProgram(model).Run()
+		if err != nil {
+			return fmt.Errorf("running editor: %w", err)
+		}
+		edits = final.(tui.EditModel).Saved()
+	}
+
+	if len(edits) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	for _, e := range edits {
+		fmt.Printf("%s: %q -> %q\n", e.Field, e.OldValue, e.NewValue)
+	}
+	return nil
+}
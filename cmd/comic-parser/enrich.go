@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/storage"
+)
+
+// enrichCommand implements `comic-parser enrich`, which fetches the details
+// of stored matches' issues that search results lack: description, creator
+// credits, characters, teams, locations and story arcs. Unless -all is set,
+// a run stops at what fits ComicVine's remaining hourly budget rather than
+// pausing, and the next run picks up the rest.
+func enrichCommand(args []string) error {
+	fs := flag.NewFlagSet("enrich", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the ComicVine API key)")
+	limit := fs.Int("limit", 0, "Enrich at most this many issues (default: as many as fit the remaining hourly budget)")
+	all := fs.Bool("all", false, "Enrich every issue, pausing whenever the hourly budget runs out")
+	refresh := fs.Duration("refresh", 0, "Also refetch details older than this, e.g. 720h (0 = only issues never enriched)")
+	workers := fs.Int("workers", 0, "Concurrent lookups (default from config: worker_count)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: comic-parser enrich [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	cfg.LoadFromEnv()
+	if err := cfg.ValidateComicVine(); err != nil {
+		return err
+	}
+
+	store, err := storage.Open(cfg.StorageBackend, *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var stale time.Time
+	if *refresh > 0 {
+		stale = time.Now().Add(-*refresh)
+	}
+	ids, err := store.IssuesToEnrich(ctx, stale)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		fmt.Println("Every matched issue is enriched")
+		return nil
+	}
+
+	client := comicvine.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+	defer client.Close()
+	useVolumeCache(client, store, cfg)
+
+	batch := len(ids)
+	switch {
+	case *limit > 0:
+		batch = min(batch, *limit)
+	case !*all && client.Budget() != nil:
+		batch = min(batch, client.Budget().Remaining("issue"))
+	}
+	if batch == 0 {
+		fmt.Printf("ComicVine's hourly budget is spent; %d issues are left to enrich. Run again later, or pass -all to wait.\n", len(ids))
+		return nil
+	}
+	fmt.Printf("Enriching %d of %d issues\n", batch, len(ids))
+
+	n := *workers
+	if n <= 0 {
+		n = cfg.WorkerCount
+	}
+	enriched, failed := enrichIssues(ctx, client, store, ids[:batch], max(n, 1))
+
+	fmt.Printf("\nEnriched %d issues (%d failed)", enriched, failed)
+	if left := len(ids) - enriched; left > 0 {
+		fmt.Printf("; %d left for a later run", left)
+	}
+	fmt.Println()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d issues could not be enriched", failed)
+	}
+	return nil
+}
+
+// enrichIssues fetches and stores the details of the issues with the given
+// workers, which share the client's rate limit and hourly budget. Each issue
+// is stored as soon as it is fetched, so an interrupted run keeps its work.
+func enrichIssues(ctx context.Context, client *comicvine.Client, store *storage.Storage, ids []int, workers int) (enriched, failed int) {
+	var mu sync.Mutex
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				issue, err := client.GetIssue(ctx, id)
+				if err == nil {
+					err = store.SaveIssueDetails(ctx, issue)
+				}
+				if ctx.Err() != nil {
+					continue // interrupted; the issue is left for the next run
+				}
+
+				mu.Lock()
+				if err != nil {
+					fmt.Printf("fail  issue %d: %v\n", id, err)
+					failed++
+				} else {
+					fmt.Printf("done  %s #%s\n", issue.Volume.Name, issue.IssueNumber)
+					enriched++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+	return enriched, failed
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/eval"
+	"comic-parser/internal/llm"
+)
+
+const parseEvalUsage = "usage: comic-parser parse eval -golden golden.csv [-parser name]... [flags]"
+
+// parseEvalCommand implements `comic-parser parse eval`, which scores
+// parsers against a golden CSV of labeled filenames and compares them.
+func parseEvalCommand(args []string) error {
+	fs := flag.NewFlagSet("parse eval", flag.ExitOnError)
+	golden := fs.String("golden", "", "CSV of labeled filenames with filename, title, issue and year columns")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the LLM settings)")
+	var parserNames []string
+	fs.Func("parser", "Parser to evaluate: regex, llm, or a chain such as regex,llm; repeat to compare parsers (default regex)", func(name string) error {
+		parserNames = append(parserNames, name)
+		return nil
+	})
+	workers := fs.Int("workers", 3, "Number of concurrent parses")
+	limit := fs.Int("limit", 20, "Most mismatches and disagreements to print per parser (0 = all)")
+	asJSON := fs.Bool("json", false, "Print the reports as JSON, with every result")
+	indent := addIndentFlag(fs)
+	noLLMCache := fs.Bool("no-llm-cache", false, "Send every LLM request instead of reusing cached responses")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), parseEvalUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *golden == "" || fs.NArg() > 0 {
+		return errors.New(parseEvalUsage)
+	}
+	if len(parserNames) == 0 {
+		parserNames = []string{"regex"}
+	}
+
+	f, err := os.Open(*golden)
+	if err != nil {
+		return err
+	}
+	cases, err := eval.LoadGolden(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", *golden, err)
+	}
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	cfg.LoadFromEnv()
+	if *noLLMCache {
+		cfg.LLMCache = false
+	}
+	for _, name := range parserNames {
+		if strings.Contains(name, "llm") {
+			if err := cfg.ValidateLLM(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	llmClient := llm.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+	defer llmClient.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var reports []*eval.Report
+	for _, name := range parserNames {
+		p, err := newParser(name, false, llmClient, cfg)
+		if err != nil {
+			return err
+		}
+		if !*asJSON {
+			fmt.Fprintf(os.Stderr, "Parsing %d filenames with %s...\n", len(cases), name)
+		}
+		report, err := eval.Run(ctx, name, p, cases, *workers)
+		if err != nil {
+			return err
+		}
+		reports = append(reports, report)
+	}
+
+	if *asJSON {
+		return writeJSON(stdoutPath, reports, *indent)
+	}
+
+	for _, r := range reports {
+		printEvalReport(r, *limit)
+	}
+	for _, other := range reports[1:] {
+		printEvalDiff(reports[0], other, *limit)
+	}
+	if llmClient.Calls() > 0 {
+		usage := llmClient.Usage()
+		fmt.Printf("\nLLM tokens: %d in / %d out (~$%.4f)\n", usage.InputTokens, usage.OutputTokens, llmClient.EstimatedCost())
+	}
+	if cache := llmClient.CacheStats(); cache.Hits > 0 {
+		fmt.Printf("LLM cache:  %d hits / %d misses (~$%.4f saved)\n", cache.Hits, cache.Misses, cache.Cost)
+	}
+	return nil
+}
+
+// printEvalReport prints a parser's accuracy per field followed by its
+// mismatches.
+func printEvalReport(r *eval.Report, limit int) {
+	fmt.Printf("\n=== %s ===\n", r.Parser)
+	for _, f := range r.Fields {
+		fmt.Printf("%-8s %5.1f%% (%d/%d)\n", f.Field+":", f.Percent, f.Correct, f.Total)
+	}
+	fmt.Printf("%-8s %5.1f%% (%d/%d)\n", "all:", r.Percent, r.Correct, r.Total)
+	if r.Errors > 0 {
+		fmt.Printf("errors:  %d\n", r.Errors)
+	}
+
+	printed := 0
+	for _, res := range r.Results {
+		if res.Correct() {
+			continue
+		}
+		if printed == 0 {
+			fmt.Println("Mismatches:")
+		}
+		if limit > 0 && printed == limit {
+			fmt.Printf("  ... %d more\n", r.Total-r.Correct-printed)
+			break
+		}
+		printed++
+		if res.Error != "" {
+			fmt.Printf("  %s: error: %s\n", res.Filename, res.Error)
+			continue
+		}
+		fields := make([]string, len(res.Mismatches))
+		for i, m := range res.Mismatches {
+			fields[i] = fmt.Sprintf("%s %q, want %q", m.Field, m.Got, m.Expected)
+		}
+		fmt.Printf("  %s: %s\n", res.Filename, strings.Join(fields, "; "))
+	}
+}
+
+// printEvalDiff prints the filenames only one of two parsers got right.
+func printEvalDiff(a, b *eval.Report, limit int) {
+	only := make(map[string][]string)
+	for _, d := range eval.Compare(a, b) {
+		only[d.Right] = append(only[d.Right], d.Filename)
+	}
+	fmt.Printf("\n=== %s vs %s ===\n", a.Parser, b.Parser)
+	for _, name := range []string{a.Parser, b.Parser} {
+		fmt.Printf("Only %s got right: %d\n", name, len(only[name]))
+		for i, filename := range only[name] {
+			if limit > 0 && i == limit {
+				fmt.Printf("  ... %d more\n", len(only[name])-i)
+				break
+			}
+			fmt.Printf("  %s\n", filename)
+		}
+	}
+}
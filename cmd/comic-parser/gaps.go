@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+const dbGapsUsage = `usage: comic-parser db gaps [-db path] [-wanted file] -series "name"`
+
+// dbGapsCommand compares the stored issues of a series with the full issue
+// list of its ComicVine volumes and reports the missing ones.
+func dbGapsCommand(args []string) error {
+	fs := flag.NewFlagSet("db gaps", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the ComicVine API key)")
+	series := fs.String("series", "", "Series to check, as named by the matched ComicVine volume")
+	wanted := fs.String("wanted", "", "Write the missing issues to this file, one per line")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), dbGapsUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if strings.TrimSpace(*series) == "" {
+		return errors.New(dbGapsUsage)
+	}
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	cfg.LoadFromEnv()
+	if err := cfg.ValidateComicVine(); err != nil {
+		return err
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return err
+	}
+
+	// A series name may cover several volumes, such as the runs of Batman.
+	// The later issues of multi-issue files count as present.
+	volumes := make(map[int]models.VolumeRef)
+	have := make(map[int][]string)
+	for _, r := range results {
+		issue := r.Match.SelectedIssue
+		if !strings.EqualFold(issue.Volume.Name, *series) {
+			continue
+		}
+		volumes[issue.Volume.ID] = issue.Volume
+		have[issue.Volume.ID] = append(have[issue.Volume.ID], issue.IssueNumber)
+	}
+	if len(volumes) == 0 {
+		return fmt.Errorf("no matched issues of %q are stored", *series)
+	}
+	ids := make([]int, 0, len(volumes))
+	for id := range volumes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return volumes[ids[i]].StartYear < volumes[ids[j]].StartYear
+	})
+
+	cvClient := comicvine.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+	defer cvClient.Close()
+	useVolumeCache(cvClient, store, cfg)
+
+	var lines []string
+	for _, id := range ids {
+		vol := volumes[id]
+		issues, err := cvClient.ListVolumeIssues(ctx, id)
+		if err != nil {
+			return fmt.Errorf("listing issues of %s: %w", vol.Name, err)
+		}
+		missing := library.MissingIssues(issues, have[id])
+
+		name := vol.Name
+		if vol.StartYear != "" {
+			name += " (" + vol.StartYear + ")"
+		}
+		fmt.Printf("%s, ComicVine %d: %d of %d issues stored\n", name, id, len(issues)-len(missing), len(issues))
+		if len(missing) == 0 {
+			fmt.Println("  Complete")
+			continue
+		}
+		numbers := make([]string, len(missing))
+		for i, issue := range missing {
+			numbers[i] = "#" + issue.IssueNumber
+			lines = append(lines, fmt.Sprintf("%s #%s", name, issue.IssueNumber))
+		}
+		fmt.Printf("  Missing: %s\n", strings.Join(numbers, ", "))
+	}
+
+	if *wanted != "" {
+		content := strings.Join(lines, "\n")
+		if content != "" {
+			content += "\n"
+		}
+		if err := os.WriteFile(*wanted, []byte(content), 0644); err != nil {
+			return fmt.Errorf("writing wanted list: %w", err)
+		}
+		fmt.Printf("\nWrote %d wanted issues to %s\n", len(lines), *wanted)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// ignoreCommands maps `comic-parser ignore` subcommands to their handlers.
+var ignoreCommands = map[string]func(args []string) error{
+	"add":    ignoreAddCommand,
+	"list":   ignoreListCommand,
+	"remove": ignoreRemoveCommand,
+}
+
+// ignoreCommand implements `comic-parser ignore <subcommand>`, which manages
+// the list of files scans and batches skip. Without a subcommand it prints
+// the list.
+func ignoreCommand(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return ignoreListCommand(args)
+	}
+	cmd, ok := ignoreCommands[args[0]]
+	if !ok {
+		return errors.New(ignoreUsage())
+	}
+	return cmd(args[1:])
+}
+
+func ignoreUsage() string {
+	names := make([]string, 0, len(ignoreCommands))
+	for name := range ignoreCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("usage: comic-parser ignore [<%s>] [flags]", strings.Join(names, "|"))
+}
+
+// loadIgnoreRules reads the ignore list from store, or when it is nil from
+// the database at dbPath, for the processor to apply. It returns nil when
+// the list is empty.
+func loadIgnoreRules(store *storage.Storage, backend, dbPath string) models.IgnoreList {
+	store, closeStore, ok := openForLookup(store, backend, dbPath, "the ignore list")
+	if !ok {
+		return nil
+	}
+	defer closeStore()
+
+	rules, err := store.ListIgnoreRules(context.Background())
+	if err != nil {
+		slog.Warn("could not load the ignore list", "error", err)
+		return nil
+	}
+	return rules
+}
+
+// ignoreAddCommand adds glob patterns or exact file names to the ignore
+// list.
+func ignoreAddCommand(args []string) error {
+	fs := flag.NewFlagSet("ignore add", flag.ExitOnError)
+	store, err := parseListFlags(fs, flagsFirst(args), "usage: comic-parser ignore add [-db path] <pattern>...", 1, -1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, pattern := range fs.Args() {
+		if err := store.AddIgnoreRule(context.Background(), pattern); err != nil {
+			return err
+		}
+		fmt.Printf("Ignoring %s\n", pattern)
+	}
+	return nil
+}
+
+func ignoreRemoveCommand(args []string) error {
+	fs := flag.NewFlagSet("ignore remove", flag.ExitOnError)
+	store, err := parseListFlags(fs, flagsFirst(args), "usage: comic-parser ignore remove [-db path] <pattern>...", 1, -1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	for _, pattern := range fs.Args() {
+		if err := store.RemoveIgnoreRule(context.Background(), pattern); err != nil {
+			return err
+		}
+		fmt.Printf("No longer ignoring %s\n", pattern)
+	}
+	return nil
+}
+
+// ignoreListCommand prints the ignore list.
+func ignoreListCommand(args []string) error {
+	fs := flag.NewFlagSet("ignore list", flag.ExitOnError)
+	store, err := parseListFlags(fs, args, "usage: comic-parser ignore list [-db path]", 0, 0)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	rules, err := store.ListIgnoreRules(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		fmt.Println("No files are ignored.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PATTERN\tADDED")
+	for _, r := range rules {
+		fmt.Fprintf(w, "%s\t%s\n", r.Pattern, r.CreatedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
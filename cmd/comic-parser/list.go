@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/readinglist"
+	"comic-parser/internal/storage"
+)
+
+// listCommands maps `comic-parser list` subcommands to their handlers.
+var listCommands = map[string]func(args []string) error{
+	"add":    listAddCommand,
+	"create": listCreateCommand,
+	"delete": listDeleteCommand,
+	"export": listExportCommand,
+	"move":   listMoveCommand,
+	"remove": listRemoveCommand,
+	"show":   listShowCommand,
+}
+
+// listCommand implements `comic-parser list <subcommand>`, which manages
+// reading lists. Without a subcommand it prints the lists.
+func listCommand(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return listListsCommand(args)
+	}
+	cmd, ok := listCommands[args[0]]
+	if !ok {
+		return errors.New(listUsage())
+	}
+	return cmd(args[1:])
+}
+
+func listUsage() string {
+	names := make([]string, 0, len(listCommands))
+	for name := range listCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("usage: comic-parser list [<%s>] [flags]", strings.Join(names, "|"))
+}
+
+// parseListFlags parses the -db flag shared by the list subcommands and
+// checks the number of positional arguments.
+func parseListFlags(fs *flag.FlagSet, args []string, usage string, minArgs, maxArgs int) (*storage.Storage, error) {
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < minArgs || maxArgs >= 0 && fs.NArg() > maxArgs {
+		return nil, errors.New(usage)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening storage: %w", err)
+	}
+	return store, nil
+}
+
+// listListsCommand prints the reading lists with their sizes.
+func listListsCommand(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	store, err := parseListFlags(fs, args, "usage: comic-parser list [-db path]", 0, 0)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	lists, err := store.ListReadingLists(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(lists) == 0 {
+		fmt.Println("No reading lists.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tISSUES\tCREATED")
+	for _, l := range lists {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", l.Name, l.EntryCount, l.CreatedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+func listCreateCommand(args []string) error {
+	fs := flag.NewFlagSet("list create", flag.ExitOnError)
+	store, err := parseListFlags(fs, args, "usage: comic-parser list create [-db path] <name>", 1, 1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.CreateReadingList(context.Background(), fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Printf("Created reading list %s\n", fs.Arg(0))
+	return nil
+}
+
+func listDeleteCommand(args []string) error {
+	fs := flag.NewFlagSet("list delete", flag.ExitOnError)
+	store, err := parseListFlags(fs, args, "usage: comic-parser list delete [-db path] <name>", 1, 1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.DeleteReadingList(context.Background(), fs.Arg(0)); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted reading list %s\n", fs.Arg(0))
+	return nil
+}
+
+// listAddCommand appends issues to a reading list. Issues are given by
+// ComicVine issue ID, or with -result by the database ID of a matched
+// processing result. ComicVine issues without a stored file are fetched
+// from ComicVine when an API key is configured.
+func listAddCommand(args []string) error {
+	fs := flag.NewFlagSet("list add", flag.ExitOnError)
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the ComicVine API key)")
+	byResult := fs.Bool("result", false, "IDs are database IDs of processing results instead of ComicVine issue IDs")
+	store, err := parseListFlags(fs, args, "usage: comic-parser list add [-db path] [-result] <name> <id>...", 2, -1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	name := fs.Arg(0)
+	var ids []int
+	for _, arg := range fs.Args()[1:] {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid id %q", arg)
+		}
+		if *byResult {
+			if id, err = store.ResultIssueID(ctx, int64(id)); err != nil {
+				return err
+			}
+			if id == 0 {
+				return fmt.Errorf("processing result %s has no ComicVine match", arg)
+			}
+		}
+		ids = append(ids, id)
+	}
+
+	var cvClient *comicvine.Client
+	for _, id := range ids {
+		stored, err := store.HasIssue(ctx, id)
+		if err != nil {
+			return err
+		}
+		if stored {
+			continue
+		}
+		if cvClient == nil {
+			cfg, err := config.LoadConfig(*configFile)
+			if err != nil {
+				return fmt.Errorf("loading config: %w", err)
+			}
+			cfg.LoadFromEnv()
+			if err := cfg.ValidateComicVine(); err != nil {
+				return fmt.Errorf("issue %d is not stored and can't be fetched: %w", id, err)
+			}
+			cvClient = comicvine.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+			defer cvClient.Close()
+		}
+		issue, err := cvClient.GetIssue(ctx, id)
+		if err != nil {
+			return fmt.Errorf("fetching issue %d: %w", id, err)
+		}
+		if err := store.SaveIssue(ctx, issue); err != nil {
+			return err
+		}
+	}
+
+	if err := store.AddToReadingList(ctx, name, ids); err != nil {
+		return err
+	}
+	fmt.Printf("Added %d issues to %s\n", len(ids), name)
+	return nil
+}
+
+// listMoveCommand moves the entry at one position of a reading list to
+// another.
+func listMoveCommand(args []string) error {
+	fs := flag.NewFlagSet("list move", flag.ExitOnError)
+	usage := "usage: comic-parser list move [-db path] <name> <from> <to>"
+	store, err := parseListFlags(fs, args, usage, 3, 3)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	from, err1 := strconv.Atoi(fs.Arg(1))
+	to, err2 := strconv.Atoi(fs.Arg(2))
+	if err1 != nil || err2 != nil {
+		return errors.New(usage)
+	}
+	if err := store.MoveReadingListEntry(context.Background(), fs.Arg(0), from, to); err != nil {
+		return err
+	}
+	fmt.Printf("Moved entry %d of %s to %d\n", from, fs.Arg(0), to)
+	return nil
+}
+
+func listRemoveCommand(args []string) error {
+	fs := flag.NewFlagSet("list remove", flag.ExitOnError)
+	usage := "usage: comic-parser list remove [-db path] <name> <position>"
+	store, err := parseListFlags(fs, args, usage, 2, 2)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	position, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return errors.New(usage)
+	}
+	if err := store.RemoveReadingListEntry(context.Background(), fs.Arg(0), position); err != nil {
+		return err
+	}
+	fmt.Printf("Removed entry %d of %s\n", position, fs.Arg(0))
+	return nil
+}
+
+// listShowCommand prints the entries of a reading list in order.
+func listShowCommand(args []string) error {
+	fs := flag.NewFlagSet("list show", flag.ExitOnError)
+	store, err := parseListFlags(fs, args, "usage: comic-parser list show [-db path] <name>", 1, 1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	list, err := store.GetReadingList(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(list.Entries) == 0 {
+		fmt.Printf("%s is empty.\n", list.Name)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSERIES\tISSUE\tCOMICVINE ID")
+	for _, e := range list.Entries {
+		series := e.Issue.Volume.Name
+		if e.Issue.Volume.StartYear != "" {
+			series += " (" + e.Issue.Volume.StartYear + ")"
+		}
+		fmt.Fprintf(w, "%d\t%s\t#%s\t%d\n", e.Position, series, e.Issue.IssueNumber, e.Issue.ID)
+	}
+	return w.Flush()
+}
+
+// listExportCommand writes a reading list as a ComicRack CBL file.
+func listExportCommand(args []string) error {
+	fs := flag.NewFlagSet("list export", flag.ExitOnError)
+	output := fs.String("o", "", "Output file (default stdout)")
+	store, err := parseListFlags(fs, args, "usage: comic-parser list export [-db path] [-o file.cbl] <name>", 1, 1)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	list, err := store.GetReadingList(context.Background(), fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := readinglist.WriteCBL(w, list); err != nil {
+		return fmt.Errorf("writing CBL: %w", err)
+	}
+	if *output != "" {
+		fmt.Printf("Exported %d issues of %s to %s\n", len(list.Entries), list.Name, *output)
+	}
+	return nil
+}
@@ -3,25 +3,32 @@ package main
 import (
 	"bufio"
 	"context"
-	"encoding/csv"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
 	"comic-parser/internal/comicvine"
 	"comic-parser/internal/config"
+	"comic-parser/internal/covers"
+	"comic-parser/internal/library"
 	"comic-parser/internal/llm"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/media"
 	"comic-parser/internal/models"
+	"comic-parser/internal/notify"
 	"comic-parser/internal/parser"
 	"comic-parser/internal/processor"
+	"comic-parser/internal/prompts"
+	"comic-parser/internal/provider"
 	"comic-parser/internal/selector"
 	"comic-parser/internal/storage"
 	"comic-parser/internal/tui"
@@ -29,22 +36,82 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// defaultDBPath is the database used when -db is not given.
+const defaultDBPath = "comics.db"
+
 func main() {
+	args := os.Args[1:]
+
+	// Dispatch subcommands before parsing the pipeline flags
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			if err := cmd(args[1:]); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			return
+		}
+		// `parse` is the explicit name of the flag-based pipeline
+		if args[0] == pipelineCommand {
+			args = args[1:]
+			if len(args) > 0 {
+				if cmd, ok := parseCommands[args[0]]; ok {
+					if err := cmd(args[1:]); err != nil {
+						log.Fatalf("Error: %v", err)
+					}
+					return
+				}
+			}
+		}
+	}
+	flag.Usage = usage
+
 	// Define flags
 	inputFile := flag.String("input", "", "Input file containing filenames (one per line)")
-	outputFile := flag.String("output", "results.json", "Output file for results")
-	outputFormat := flag.String("format", "json", "Output format: json, csv, or sqlite")
+	scanDir := flag.String("scan", "", "Scan a directory for comic archives instead of reading -input")
+	recursive := flag.Bool("recursive", true, "Descend into subdirectories when scanning")
+	include := flag.String("include", "", "Comma-separated glob patterns of files to scan (e.g. \"*.cbz,Batman*\")")
+	exclude := flag.String("exclude", "", "Comma-separated glob patterns of files or directories to skip when scanning")
+	outputFile := flag.String("output", "results.json", "Output file for results, or - for stdout")
+	indent := flag.Int("indent", 2, "Spaces to indent JSON results by (0 = one line)")
+	outputFormat := flag.String("format", "json", "Output format: json, jsonl (one result per line as files complete), csv, sqlite, or template")
+	resultTemplate := flag.String("template", "", "Go text/template to write each result with in the template format, e.g. '{{.Series}} #{{.Issue}} ({{.Year}})'")
 	configFile := flag.String("config", "config.json", "Path to configuration file")
+	providerName := flag.String("provider", "", "Metadata provider to search (default from config: comicvine)")
 	workers := flag.Int("workers", 3, "Number of concurrent workers")
-	verbose := flag.Bool("verbose", false, "Enable verbose logging")
+	verbose := flag.Bool("verbose", false, "Enable verbose logging (same as -log-level debug)")
+	logFormat := flag.String("log-format", logging.FormatText, "Log output: text, or json for log tooling")
+	logLevel := flag.String("log-level", "", "Log levels, e.g. \"info\" or \"warn,comicvine=debug\" (subsystems: comicvine, config, llm, parser, processor, server, storage)")
 	interactive := flag.Bool("interactive", false, "Enable interactive TUI mode")
+	remoteReview := flag.Duration("remote-review", 0, "Queue ambiguous matches for the serve review page and wait up to this long for each decision, e.g. 10m (0 = off)")
 	singleFile := flag.String("file", "", "Process a single filename (for testing)")
 	generateConfig := flag.Bool("generate-config", false, "Generate a sample config file")
-	parserName := flag.String("parser", "", "Parser to use: regex or llm (enables parse-only mode)")
-	dbPath := flag.String("db", "comics.db", "Database path for storing results")
+	parserName := flag.String("parser", "", "Parser to use: regex, llm, or a chain such as regex,llm (enables parse-only mode)")
+	selectorName := flag.String("selector", "llm", "Match selector: llm, or heuristic to score candidates without the LLM")
+	useComicInfo := flag.Bool("comicinfo", true, "Use ComicInfo.xml embedded in local archives before parsing the filename")
+	dbPath := flag.String("db", defaultDBPath, "Database path for storing results")
 	tuiMode := flag.Bool("tui", false, "Launch TUI to view parsed results")
-
-	flag.Parse()
+	maxLLMCost := flag.Float64("max-llm-cost", 0, "Stop LLM usage once estimated spend reaches this many dollars (0 = unlimited)")
+	resume := flag.Bool("resume", false, "Continue the last interrupted run of the same input, skipping files already done")
+	maxLLMTokens := flag.Int("max-llm-tokens", 0, "Stop LLM usage once this many tokens have been used (0 = unlimited)")
+	mediaType := flag.String("type", media.Comic, "Media type to identify: "+strings.Join(media.Types(), ", "))
+	downloadCovers := flag.Bool("download-covers", false, "Cache the cover images of matched issues under the cache directory")
+	enrich := flag.Bool("enrich", false, "Fetch the credits, characters, teams, locations and story arcs of matched issues (one request per file)")
+	noLLMCache := flag.Bool("no-llm-cache", false, "Send every LLM request instead of reusing cached responses")
+	offline := flag.Bool("offline", false, "Parse without network access and queue the files for the sync command to match")
+
+	flag.CommandLine.Parse(args)
+
+	levelSpec := *logLevel
+	if levelSpec == "" && *verbose {
+		levelSpec = "debug"
+	}
+	levels, err := logging.ParseLevels(levelSpec)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := logging.Setup(logging.Options{Format: *logFormat, Levels: levels}); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 
 	// Handle config generation
 	if *generateConfig {
@@ -52,7 +119,7 @@ func main() {
 		cfg.AnthropicAPIKey = "your-anthropic-api-key-here"
 		cfg.ComicVineAPIKey = "your-comicvine-api-key-here"
 		if err := cfg.SaveConfig("config.sample.json"); err != nil {
-			log.Fatalf("Error generating config: %v", err)
+			fatal("generating config failed", "error", err)
 		}
 		fmt.Println("Generated config.sample.json - copy to config.json and add your API keys")
 		return
@@ -61,7 +128,7 @@ func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig(*configFile)
 	if err != nil {
-		log.Fatalf("Error loading config: %v", err)
+		fatal("loading config failed", "path", *configFile, "error", err)
 	}
 	cfg.LoadFromEnv()
 
@@ -75,12 +142,77 @@ func main() {
 	if *outputFormat != "" {
 		cfg.OutputFormat = *outputFormat
 	}
+	if *maxLLMCost > 0 {
+		cfg.MaxLLMCost = *maxLLMCost
+	}
+	if *maxLLMTokens > 0 {
+		cfg.MaxLLMTokens = *maxLLMTokens
+	}
+	if *providerName != "" {
+		cfg.MetadataProvider = *providerName
+	}
+	if *noLLMCache {
+		cfg.LLMCache = false
+	}
 	cfg.Verbose = *verbose
 	cfg.Interactive = *interactive
+	if levelSpec == "" && cfg.LogLevel != "" {
+		levels, err := logging.ParseLevels(cfg.LogLevel)
+		if err != nil {
+			fatal("invalid log_level", "error", err)
+		}
+		if err := logging.SetLevels(levels); err != nil {
+			fatal("setting up logging failed", "error", err)
+		}
+	}
+	routeStdout(cfg.OutputFile)
+	if *indent < 0 {
+		fatal("-indent can't be negative")
+	}
+	output := outputOptions{format: cfg.OutputFormat, indent: *indent}
+	if output.format == formatTemplate {
+		if output.template, err = parseResultTemplate(*resultTemplate); err != nil {
+			fatal("invalid -template", "error", err)
+		}
+		if err := checkResultTemplate(output.template, func(result *models.ProcessingResult) any {
+			return newResultView(result)
+		}); err != nil {
+			fatal("invalid -template", "error", err)
+		}
+	} else if *resultTemplate != "" {
+		fatal("-template needs -format template")
+	}
+
+	// Other media types bring their own parser and metadata source
+	if *mediaType != media.Comic {
+		processMedia(cfg, *mediaType, *dbPath, mediaInput{
+			file:  *singleFile,
+			input: *inputFile,
+			scan:  *scanDir,
+			opts: library.ScanOptions{
+				Recursive: *recursive,
+				Include:   splitList(*include),
+				Exclude:   splitList(*exclude),
+			},
+			args: flag.Args(),
+		}, *resume, output.indent)
+		return
+	}
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Configuration error: %v", err)
+	// Offline runs parse with the regex parser only and leave the ComicVine
+	// and LLM work to sync, so they need no API keys
+	if *offline {
+		if *parserName == "" {
+			*parserName = "regex"
+		}
+		if slices.Contains(splitList(*parserName), "llm") {
+			fatal("-offline parses without the LLM; use -parser regex")
+		}
+		if *tuiMode || cfg.Interactive || *remoteReview > 0 {
+			fatal("-offline can't be combined with -tui, -interactive or -remote-review")
+		}
+	} else if err := cfg.Validate(); err != nil {
+		fatal("invalid configuration", "error", err)
 	}
 
 	// Create shared HTTP client
@@ -92,46 +224,91 @@ func main() {
 	llmClient := llm.NewClient(cfg, httpClient)
 	defer llmClient.Close()
 
-	cvClient := comicvine.NewClient(cfg, httpClient)
-
-	// Create parser
-	var p parser.Parser
-	if *parserName != "" {
-		switch *parserName {
-		case "regex":
-			p = parser.NewRegexParser()
-		case "llm":
-			p = parser.NewLLMParser(llmClient, cfg.RetryAttempts, cfg.RetryDelaySeconds)
-		default:
-			log.Fatalf("Unknown parser: %s (must be regex or llm)", *parserName)
+	// Offline runs never search, so they have no metadata provider
+	var metaProvider provider.MetadataProvider
+	if !*offline {
+		if metaProvider, err = provider.New(cfg.MetadataProvider, cfg, httpClient); err != nil {
+			fatal("creating metadata provider failed", "error", err)
 		}
-	} else {
-		// Since chain parser is removed, we require a parser to be specified
-		log.Fatal("Please specify a parser using -parser (regex or llm)")
+	}
+
+	// Create parser; full processing uses the config's parser chain
+	p, err := newParser(*parserName, *useComicInfo, llmClient, cfg)
+	if err != nil {
+		fatal("creating parser failed", "error", err)
 	}
 
 	// Create selector
+	if cfg.Interactive && *remoteReview > 0 {
+		fatal("-interactive and -remote-review are exclusive")
+	}
 	var sel selector.Selector
 	if cfg.Interactive {
 		sel = selector.NewTUISelector()
-	} else {
-		sel = selector.NewLLMSelector(llmClient, cfg)
+	} else if sel, err = newSelector(*selectorName, llmClient, cfg); err != nil {
+		fatal("creating selector failed", "error", err)
 	}
 
-	// Initialize Storage if parsing is enabled or TUI mode
+	// Initialize Storage if parsing is enabled, for TUI mode, to record and
+	// use match corrections, or to queue matches for remote review
 	var store *storage.Storage
-	if *parserName != "" || *tuiMode {
+	if *parserName != "" || *tuiMode || cfg.Interactive || cfg.MatchExamples > 0 || *remoteReview > 0 {
 		var err error
-		store, err = storage.NewStorage(*dbPath)
+		store, err = storage.Open(cfg.StorageBackend, *dbPath)
 		if err != nil {
-			log.Fatalf("Error initializing storage: %v", err)
+			fatal("initializing storage failed", "error", err)
 		}
 		defer store.Close()
 	}
 
+	// The volume cache needs the database even when results aren't stored
+	volumeStore := store
+	if volumeStore == nil && volumeCacheEnabled(cfg) {
+		var err error
+		volumeStore, err = storage.Open(cfg.StorageBackend, *dbPath)
+		if err != nil {
+			fatal("initializing storage failed", "error", err)
+		}
+		defer volumeStore.Close()
+	}
+	useVolumeCache(metaProvider, volumeStore, cfg)
+
 	// Create processor
-	proc := processor.NewProcessor(cfg, p, cvClient, sel, store)
+	useCorrections(sel, store, cfg)
+	if *remoteReview > 0 {
+		sel = selector.NewRemoteSelector(sel, store, *remoteReview)
+	}
+	proc := processor.NewProcessor(cfg, p, metaProvider, sel, store)
 	defer proc.Close()
+	trackUsage(store, llmClient)
+	notifier := notify.New(cfg, httpClient)
+	if *downloadCovers {
+		if cfg.CacheDir == "" {
+			fatal("-download-covers needs a cache_dir in the config")
+		}
+		proc.SetCovers(covers.NewCache(cfg.CacheDir, httpClient))
+	}
+	if *enrich {
+		proc.SetDetails(metaProvider)
+	}
+	if *offline {
+		proc.SetQueueMatches(true)
+		defer printMatchQueue(store)
+	}
+
+	// Full processing resolves known releases from imported mappings first
+	// and searches aliased series by their real names
+	if *parserName == "" && !*tuiMode {
+		if set := loadMappings(volumeStore, cfg.StorageBackend, *dbPath); set != nil {
+			proc.SetMappings(set)
+		}
+		if aliases := loadSeriesAliases(volumeStore, cfg.StorageBackend, *dbPath); aliases != nil {
+			proc.SetSeriesAliases(aliases)
+		}
+	}
+	if !*tuiMode {
+		proc.SetIgnoreRules(loadIgnoreRules(volumeStore, cfg.StorageBackend, *dbPath))
+	}
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -148,14 +325,14 @@ func main() {
 
 	if *tuiMode {
 		// Initialize TUI
-		model, err := tui.NewModel(ctx, store, cvClient)
+		model, err := tui.NewModel(ctx, store, metaProvider)
 		if err != nil {
-			log.Fatalf("Error initializing TUI: %v", err)
+			fatal("initializing TUI failed", "error", err)
 		}
 
 		p := tea.NewProgram(model)
 		if _, err := p.Run(); err != nil {
-			log.Fatalf("Error running TUI: %v", err)
+			fatal("running TUI failed", "error", err)
 		}
 		return
 	}
@@ -167,43 +344,64 @@ func main() {
 			fmt.Printf("Parsing single file with %s: %s\n", *parserName, *singleFile)
 			err := proc.ProcessFileParseOnly(ctx, *singleFile, *parserName)
 			if err != nil {
-				log.Fatalf("Error parsing file: %v", err)
+				fatal("parsing file failed", "error", err)
 			}
 			fmt.Println("Result saved to database.")
 			return
 		}
-		// Full processing (currently unreachable due to log.Fatal above if parser not set)
-		processSingle(ctx, proc, *singleFile)
+		processSingle(ctx, proc, cfg, *singleFile, output)
 		return
 	}
 
-	if *inputFile == "" {
+	if *scanDir == "" && *inputFile == "" {
 		// Check for filenames from stdin or command line args
 		if flag.NArg() > 0 {
 			if *parserName != "" {
-				proc.ParseBatch(ctx, flag.Args(), *parserName)
+				startTime := time.Now()
+				run, filenames := startRun(ctx, store, proc, llmClient, cfg, runModeParse, runSourceArgs, *parserName, flag.Args(), *resume)
+				proc.ParseBatch(ctx, filenames, *parserName)
+				finishRun(store, run, proc, llmClient)
+				notifyRun(notifier, store, run, proc, llmClient, batchOutcome{
+					mode: runModeParse, source: runSourceArgs, total: flag.NArg(), results: *dbPath, elapsed: time.Since(startTime),
+				})
 				return
 			}
-			processBatch(ctx, proc, cfg, flag.Args())
+			processBatch(ctx, proc, llmClient, metaProvider, store, notifier, cfg, runSourceArgs, flag.Args(), *resume, output)
 		} else {
 			flag.Usage()
 			fmt.Println("\nExamples:")
 			fmt.Println("  comic-parser -parser regex -file \"Amazing Spider-Man 001 (2018).cbz\"")
 			fmt.Println("  comic-parser -parser llm -input filenames.txt")
+			fmt.Println("  comic-parser -parser llm -scan ~/Comics -exclude \"*Preview*\"")
+			fmt.Println("  comic-parser -offline -scan ~/Comics")
 			fmt.Println("  comic-parser -generate-config")
 			os.Exit(1)
 		}
 		return
 	}
 
-	// Load filenames from input file
-	filenames, err := loadFilenames(*inputFile)
-	if err != nil {
-		log.Fatalf("Error loading input file: %v", err)
+	// Load filenames from the scanned directory or the input file
+	var filenames []string
+	source := *inputFile
+	if *scanDir != "" {
+		filenames, err = library.Scan(*scanDir, library.ScanOptions{
+			Recursive: *recursive,
+			Include:   splitList(*include),
+			Exclude:   splitList(*exclude),
+		})
+		if err != nil {
+			fatal("scanning failed", "dir", *scanDir, "error", err)
+		}
+		source = *scanDir
+	} else {
+		filenames, err = loadFilenames(*inputFile)
+		if err != nil {
+			fatal("loading input file failed", "error", err)
+		}
 	}
 
 	if len(filenames) == 0 {
-		log.Fatal("No filenames to process")
+		fatal("no filenames to process")
 	}
 
 	fmt.Printf("Loaded %d filenames to process\n", len(filenames))
@@ -212,30 +410,180 @@ func main() {
 		// Parse Only Mode
 		fmt.Printf("Starting parse-only batch with parser: %s\n", *parserName)
 		startTime := time.Now()
-		proc.ParseBatch(ctx, filenames, *parserName)
-
-		elapsed := time.Since(startTime)
-		progress := proc.GetProgress()
-		fmt.Printf("\n=== Summary ===\n")
-		fmt.Printf("Total processed: %d\n", progress.Processed)
-		fmt.Printf("Successful:      %d\n", progress.Successful)
-		fmt.Printf("Failed:          %d\n", progress.Failed)
-		fmt.Printf("Time elapsed:    %s\n", elapsed.Round(time.Second))
-		if progress.Processed > 0 {
-			fmt.Printf("Avg time/file:   %s\n", (elapsed / time.Duration(progress.Processed)).Round(time.Millisecond))
+		run, pending := startRun(ctx, store, proc, llmClient, cfg, runModeParse, source, *parserName, filenames, *resume)
+		runBatch(ctx, proc, len(pending), func(ctx context.Context) {
+			proc.ParseBatch(ctx, pending, *parserName)
+		})
+		finishRun(store, run, proc, llmClient)
+		printSummary(proc, llmClient, time.Since(startTime))
+		notifyRun(notifier, store, run, proc, llmClient, batchOutcome{
+			mode: runModeParse, source: source, total: len(filenames), results: *dbPath, elapsed: time.Since(startTime),
+		})
+		if run != nil {
+			fmt.Printf("Run ID:          %d\n", run.ID)
 		}
 		return
 	}
 
-	processBatch(ctx, proc, cfg, filenames)
+	processBatch(ctx, proc, llmClient, metaProvider, store, notifier, cfg, source, filenames, *resume, output)
 }
 
-func processSingle(ctx context.Context, proc *processor.Processor, filename string) {
+// newParser creates the named filename parser, consulting embedded
+// ComicInfo.xml first when useComicInfo is set. A comma-separated name such
+// as "regex,llm" creates a chain of parsers, and an empty one the chain of
+// the config.
+func newParser(name string, useComicInfo bool, llmClient *llm.Client, cfg *config.Config) (parser.Parser, error) {
+	if name == "" {
+		name = cfg.ParserChain
+	}
+	var stages []parser.ChainStage
+	for _, stage := range splitList(name) {
+		var p parser.Parser
+		switch stage {
+		case "regex":
+			patterns, err := loadPatterns(cfg)
+			if err != nil {
+				return nil, err
+			}
+			p = parser.NewRegexParser(patterns...)
+		case "llm":
+			if err := loadPrompts(cfg); err != nil {
+				return nil, err
+			}
+			if cfg.LLMBatchSize > 1 {
+				p = parser.NewBatchLLMParser(llmClient, cfg.LLMBatchSize, cfg.RetryAttempts, cfg.RetryDelaySeconds)
+			} else {
+				p = parser.NewLLMParser(llmClient, cfg.RetryAttempts, cfg.RetryDelaySeconds)
+			}
+		default:
+			return nil, fmt.Errorf("unknown parser %q (must be regex or llm)", stage)
+		}
+		stages = append(stages, parser.ChainStage{Name: stage, Parser: p})
+	}
+
+	var p parser.Parser
+	switch len(stages) {
+	case 0:
+		return nil, errors.New("no parser given (use regex, llm, or a chain such as regex,llm)")
+	case 1:
+		p = stages[0].Parser
+	default:
+		p = parser.NewChainParser(stages...)
+	}
+	if useComicInfo {
+		p = parser.NewComicInfoParser(p)
+	}
+	return p, nil
+}
+
+// loadPatterns reads the user-defined filename patterns of the config's
+// patterns file, or of the default one when it exists.
+func loadPatterns(cfg *config.Config) ([]*parser.Pattern, error) {
+	path := cfg.PatternsFile
+	if path == "" {
+		var err error
+		if path, err = parser.DefaultPatternsPath(); err != nil {
+			return nil, nil
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	patterns, err := parser.LoadPatterns(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading filename patterns: %w", err)
+	}
+	slog.Debug("loaded filename patterns", "path", path, "count", len(patterns))
+	return patterns, nil
+}
+
+// loadPrompts replaces the built-in LLM prompts with the templates of the
+// config's prompts directory, or of the default one when it exists.
+func loadPrompts(cfg *config.Config) error {
+	dir := cfg.PromptsDir
+	if dir == "" {
+		var err error
+		if dir, err = prompts.DefaultDir(); err != nil {
+			return nil
+		}
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+	} else if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("loading prompt templates: %w", err)
+	}
+	names, err := prompts.LoadDir(dir)
+	if err != nil {
+		return fmt.Errorf("loading prompt templates: %w", err)
+	}
+	if len(names) > 0 {
+		slog.Debug("loaded prompt templates", "dir", dir, "templates", names)
+	}
+	return nil
+}
+
+// newSelector creates the named match selector.
+func newSelector(name string, llmClient *llm.Client, cfg *config.Config) (selector.Selector, error) {
+	switch name {
+	case "llm":
+		if err := loadPrompts(cfg); err != nil {
+			return nil, err
+		}
+		return selector.NewLLMSelector(llmClient, cfg), nil
+	case "heuristic":
+		return selector.NewHeuristicSelector(), nil
+	}
+	return nil, fmt.Errorf("unknown selector %q (must be llm or heuristic)", name)
+}
+
+// useCorrections connects sel to the match corrections in store: the
+// interactive selector records the user's choices, and the LLM selector
+// shows the configured number of them as examples.
+func useCorrections(sel selector.Selector, store *storage.Storage, cfg *config.Config) {
+	if store == nil {
+		return
+	}
+	switch sel := sel.(type) {
+	case *selector.TUISelector:
+		sel.RecordCorrections(store)
+	case *selector.LLMSelector:
+		sel.SetExamples(store, cfg.MatchExamples)
+	}
+}
+
+// volumeCacheEnabled reports whether fetched ComicVine volumes are kept in
+// the database.
+func volumeCacheEnabled(cfg *config.Config) bool {
+	return cfg.CacheEnabled && cfg.VolumeCache
+}
+
+// useVolumeCache keeps the volumes, searches and issue lists meta fetches in
+// store between runs, for providers that support it.
+func useVolumeCache(meta provider.MetadataProvider, store *storage.Storage, cfg *config.Config) {
+	cached, ok := meta.(interface {
+		SetVolumeStore(comicvine.VolumeStore, time.Duration)
+	})
+	if !ok || store == nil || !volumeCacheEnabled(cfg) {
+		return
+	}
+	cached.SetVolumeStore(store, time.Duration(cfg.CacheTTLHours)*time.Hour)
+}
+
+// processSingle processes filename and prints its parse and match, or with
+// -output - writes the whole result to stdout in the output format instead.
+func processSingle(ctx context.Context, proc *processor.Processor, cfg *config.Config, filename string, output outputOptions) {
 	fmt.Printf("Processing: %s\n\n", filename)
 
 	result, err := proc.ProcessFile(ctx, filename)
 	if err != nil {
-		log.Fatalf("Error processing file: %v", err)
+		fatal("processing file failed", "error", err)
+	}
+
+	if cfg.OutputFile == stdoutPath {
+		if err := saveResults([]*models.ProcessingResult{result}, stdoutPath, output); err != nil {
+			fatal("writing result failed", "error", err)
+		}
+		return
 	}
 
 	if result.Error != "" {
@@ -272,49 +620,118 @@ func processSingle(ctx context.Context, proc *processor.Processor, filename stri
 	fmt.Printf("Confidence:   %s\n", result.Match.MatchConfidence)
 	fmt.Printf("Reasoning:    %s\n", result.Match.Reasoning)
 	fmt.Printf("\nProcessing time: %dms\n", result.ProcessingTimeMS)
+	if result.SearchRetries > 0 {
+		fmt.Printf("ComicVine retries: %d\n", result.SearchRetries)
+	}
 }
 
-func processBatch(ctx context.Context, proc *processor.Processor, cfg *config.Config, filenames []string) {
+func processBatch(ctx context.Context, proc *processor.Processor, llmClient *llm.Client, meta provider.MetadataProvider, store *storage.Storage, notifier *notify.Notifier, cfg *config.Config, source string, filenames []string, resume bool, output outputOptions) {
 	resultChan := make(chan *models.ProcessingResult, 100)
 	var results []*models.ProcessingResult
 
+	// JSON Lines and template output are written as results come in rather
+	// than at the end
+	var stream *resultStream
+	if output.streams() {
+		var err error
+		if stream, err = newResultStream(cfg.OutputFile, output); err != nil {
+			fatal("creating output file failed", "path", cfg.OutputFile, "error", err)
+		}
+	}
+
 	// Start collecting results
 	done := make(chan struct{})
 	go func() {
 		for result := range resultChan {
 			results = append(results, result)
-
-			// Print progress
-			progress := proc.GetProgress()
-			fmt.Printf("\rProgress: %d/%d (✓ %d, ✗ %d)",
-				progress.Processed, progress.Total,
-				progress.Successful, progress.Failed)
+			if stream != nil {
+				stream.Write(result)
+			}
 		}
 		close(done)
 	}()
 
 	// Start processing
 	startTime := time.Now()
-	proc.ProcessBatch(ctx, filenames, resultChan)
+	run, pending := startRun(ctx, store, proc, llmClient, cfg, runModeProcess, source, "", filenames, resume)
+	printBudgetPlan(meta, len(pending))
+	runBatch(ctx, proc, len(pending), func(ctx context.Context) {
+		proc.ProcessBatch(ctx, pending, resultChan)
+	})
 	close(resultChan)
 	<-done
-
-	fmt.Println() // New line after progress
+	finishRun(store, run, proc, llmClient)
 
 	// Save results
-	if err := saveResults(results, cfg.OutputFile, cfg.OutputFormat); err != nil {
-		log.Printf("Error saving results: %v", err)
+	var saveErr error
+	if stream != nil {
+		saveErr = stream.Close()
 	} else {
+		saveErr = saveResults(results, cfg.OutputFile, output)
+	}
+	if saveErr != nil {
+		slog.Error("saving results failed", "error", saveErr)
+	} else if cfg.OutputFile != stdoutPath {
 		fmt.Printf("\nResults saved to: %s\n", cfg.OutputFile)
 	}
 
-	// Print summary
-	elapsed := time.Since(startTime)
+	printSummary(proc, llmClient, time.Since(startTime))
+	notifyRun(notifier, store, run, proc, llmClient, batchOutcome{
+		mode: runModeProcess, source: source, total: len(filenames), results: cfg.OutputFile,
+		elapsed: time.Since(startTime), err: saveErr, processed: results,
+	})
+	if cfg.Verbose {
+		printCacheStats(meta)
+	}
+}
+
+// printCacheStats reports response cache use for providers that cache.
+func printCacheStats(meta provider.MetadataProvider) {
+	cached, ok := meta.(interface{ CacheStats() comicvine.CacheStats })
+	if !ok {
+		return
+	}
+	stats := cached.CacheStats()
+	fmt.Printf("API cache:       %d hits / %d misses\n", stats.Hits, stats.Misses)
+}
+
+// printBudgetPlan reports whether a batch of files is expected to fit the
+// provider's hourly request budget, for providers that track one.
+func printBudgetPlan(meta provider.MetadataProvider, files int) {
+	budgeted, ok := meta.(interface{ Budget() *comicvine.Budget })
+	if !ok || budgeted.Budget() == nil || files == 0 {
+		return
+	}
+	plan := budgeted.Budget().Plan(files)
+	if plan.Fits() {
+		return
+	}
+	fmt.Printf("ComicVine budget: %d files may need up to %d issue lookups, %d left this hour (limit %d/h).\n",
+		plan.Files, plan.Requests["issues"], plan.Remaining["issues"], plan.Limit)
+	fmt.Printf("Requests will be spread evenly; expect up to %s of waiting (cached lookups cost nothing).\n", plan.Wait)
+}
+
+func printSummary(proc *processor.Processor, llmClient *llm.Client, elapsed time.Duration) {
 	progress := proc.GetProgress()
+	usage := llmClient.Usage()
 	fmt.Printf("\n=== Summary ===\n")
 	fmt.Printf("Total processed: %d\n", progress.Processed)
 	fmt.Printf("Successful:      %d\n", progress.Successful)
 	fmt.Printf("Failed:          %d\n", progress.Failed)
+	if progress.Skipped > 0 {
+		fmt.Printf("Skipped:         %d (LLM budget exhausted)\n", progress.Skipped)
+	}
+	for _, stage := range parser.ChainStats(proc.Parser()) {
+		fmt.Printf("Parser %-10s%d parsed, %d passed on, %d failed\n", stage.Name+":", stage.Accepted, stage.Passed, stage.Failed)
+	}
+	fmt.Printf("LLM tokens:      %d in / %d out (~$%.4f)\n", usage.InputTokens, usage.OutputTokens, llmClient.EstimatedCost())
+	if calls := llmClient.Calls(); calls > 0 && progress.Processed > 0 {
+		fmt.Printf("LLM calls:       %d (~$%.4f/file)\n", calls, llmClient.EstimatedCost()/float64(progress.Processed))
+	}
+	if cache := llmClient.CacheStats(); cache.Hits+cache.Misses > 0 {
+		fmt.Printf("LLM cache:       %d hits / %d misses (~%d tokens, ~$%.4f saved)\n",
+			cache.Hits, cache.Misses, cache.Saved.InputTokens+cache.Saved.OutputTokens, cache.Cost)
+	}
 	fmt.Printf("Time elapsed:    %s\n", elapsed.Round(time.Second))
 	if progress.Processed > 0 {
 		fmt.Printf("Avg time/file:   %s\n", (elapsed / time.Duration(progress.Processed)).Round(time.Millisecond))
@@ -340,29 +757,41 @@ func loadFilenames(path string) ([]string, error) {
 	return filenames, scanner.Err()
 }
 
-func saveResults(results []*models.ProcessingResult, path string, format string) error {
-	// Create directory if needed
-	dir := filepath.Dir(path)
-	if dir != "" && dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return err
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
+	return items
+}
 
-	switch format {
+// saveResults writes results to path, or to stdout for "-", with opts.
+func saveResults(results []*models.ProcessingResult, path string, opts outputOptions) error {
+	switch opts.format {
 	case "json":
-		return saveJSON(results, path)
+		return saveJSON(results, path, opts.indent)
+	case formatJSONL, formatTemplate:
+		return saveStream(results, path, opts)
 	case "csv":
 		return saveCSV(results, path)
 	case "sqlite", "db":
+		if path == stdoutPath {
+			return errors.New("sqlite output can't be written to stdout")
+		}
+		if err := makeParentDir(path); err != nil {
+			return err
+		}
 		return saveDB(results, path)
 	default:
-		return fmt.Errorf("unknown output format: %s", format)
+		return fmt.Errorf("unknown output format: %s", opts.format)
 	}
 }
 
 func saveDB(results []*models.ProcessingResult, path string) error {
-	store, err := storage.NewStorage(path)
+	store, err := storage.Open("", path)
 	if err != nil {
 		return err
 	}
@@ -377,29 +806,11 @@ func saveDB(results []*models.ProcessingResult, path string) error {
 	return nil
 }
 
-func saveJSON(results []*models.ProcessingResult, path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(results)
+func saveJSON(results []*models.ProcessingResult, path string, indent int) error {
+	return writeJSON(path, results, indent)
 }
 
 func saveCSV(results []*models.ProcessingResult, path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write header
 	header := []string{
 		"Filename",
 		"Success",
@@ -410,17 +821,14 @@ func saveCSV(results []*models.ProcessingResult, path string) error {
 		"Match_Confidence",
 		"ComicVine_ID",
 		"ComicVine_Series",
+		"ComicVine_StartYear",
 		"ComicVine_Issue",
 		"ComicVine_CoverDate",
 		"ComicVine_Publisher",
 		"ComicVine_URL",
 		"Reasoning",
 	}
-	if err := writer.Write(header); err != nil {
-		return err
-	}
-
-	// Write rows
+	rows := make([][]string, 0, len(results))
 	for _, r := range results {
 		row := []string{
 			r.Filename,
@@ -440,23 +848,26 @@ func saveCSV(results []*models.ProcessingResult, path string) error {
 				row = append(row,
 					fmt.Sprintf("%d", r.Match.ComicVineID),
 					r.Match.SelectedIssue.Volume.Name,
+					r.Match.SelectedIssue.Volume.StartYear,
 					r.Match.SelectedIssue.IssueNumber,
-					r.Match.SelectedIssue.CoverDate,
+					r.Match.SelectedIssue.CoverDate.String(),
 					r.Match.SelectedIssue.Volume.Publisher,
 					r.Match.ComicVineURL,
 				)
 			} else {
-				row = append(row, "", "", "", "", "", "")
+				row = append(row, "", "", "", "", "", "", "")
 			}
 			row = append(row, r.Match.Reasoning)
 		} else {
-			row = append(row, "", "", "", "", "", "", "", "", "", "", "")
-		}
-
-		if err := writer.Write(row); err != nil {
-			return err
+			row = append(row, "", "", "", "", "", "", "", "", "", "", "", "")
 		}
+		rows = append(rows, row)
 	}
+	return writeCSV(path, header, rows)
+}
 
-	return nil
+// fatal logs msg and its attributes at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"comic-parser/internal/mapping"
+	"comic-parser/internal/storage"
+)
+
+// openForLookup returns store, or when it is nil the database at dbPath, so
+// an open connection is reused, with the function that closes what it
+// opened. It reports false when a SQLite database does not exist or the
+// database can't be opened, logging what it was opened for.
+func openForLookup(store *storage.Storage, backend, dbPath, purpose string) (*storage.Storage, func(), bool) {
+	if store != nil {
+		return store, func() {}, true
+	}
+	if backend == "" || backend == storage.BackendSQLite {
+		if _, err := os.Stat(dbPath); err != nil {
+			return nil, nil, false
+		}
+	}
+
+	store, err := storage.Open(backend, dbPath)
+	if err != nil {
+		slog.Warn("could not open database for "+purpose, "db", dbPath, "error", err)
+		return nil, nil, false
+	}
+	return store, func() { store.Close() }, true
+}
+
+// loadMappings reads imported mappings from store, or when it is nil from
+// the database at dbPath. It returns nil when a SQLite database does not
+// exist or holds no mappings.
+func loadMappings(store *storage.Storage, backend, dbPath string) *mapping.Set {
+	store, closeStore, ok := openForLookup(store, backend, dbPath, "mappings")
+	if !ok {
+		return nil
+	}
+	defer closeStore()
+
+	mappings, err := store.ListMappings(context.Background())
+	if err != nil {
+		slog.Warn("could not load mappings", "error", err)
+		return nil
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+	return mapping.NewSet(mappings)
+}
+
+// dbImportMappingsCommand imports a shared filename/hash to ComicVine mapping file.
+func dbImportMappingsCommand(args []string) error {
+	fs := flag.NewFlagSet("db import-mappings", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	source := fs.String("source", "", "Provenance recorded for the mappings (default: the file's source field)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: comic-parser db import-mappings [-db path] [-source name] <mappings.json>")
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("opening mapping file: %w", err)
+	}
+	defer f.Close()
+
+	mappings, err := mapping.Read(f, *source)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	if err := store.SaveMappings(context.Background(), mappings); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d mappings\n", len(mappings))
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/library"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/media"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/storage"
+)
+
+// mediaInput is where the files of a batch come from, as given by the
+// pipeline flags.
+type mediaInput struct {
+	file  string
+	input string
+	scan  string
+	opts  library.ScanOptions
+	args  []string
+}
+
+// load returns the files to process and the source to record for the run.
+// Scanned directories are searched for files of type mt.
+func (in mediaInput) load(mt media.Type) ([]string, string, error) {
+	switch {
+	case in.file != "":
+		return []string{in.file}, runSourceArgs, nil
+	case in.scan != "":
+		opts := in.opts
+		opts.Match = mt.Matches
+		files, err := library.Scan(in.scan, opts)
+		if err != nil {
+			return nil, "", fmt.Errorf("scanning %s: %w", in.scan, err)
+		}
+		return files, in.scan, nil
+	case in.input != "":
+		files, err := loadFilenames(in.input)
+		if err != nil {
+			return nil, "", fmt.Errorf("loading input file: %w", err)
+		}
+		return files, in.input, nil
+	}
+	return in.args, runSourceArgs, nil
+}
+
+// processMedia runs the pipeline for a media type other than comics: it
+// identifies the files with the type's own parser and metadata source on the
+// processor's workers, stores the results in the database at dbPath and
+// writes them to the output file as JSON indented by indent spaces.
+func processMedia(cfg *config.Config, typeName, dbPath string, in mediaInput, resume bool, indent int) {
+	if cfg.OutputFormat != "json" {
+		fatal("only json output is supported for this media type", "type", typeName, "format", cfg.OutputFormat)
+	}
+
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	mt, err := media.New(typeName, cfg, httpClient)
+	if err != nil {
+		fatal("creating media type failed", "error", err)
+	}
+
+	filenames, source, err := in.load(mt)
+	if err != nil {
+		fatal("loading filenames failed", "error", err)
+	}
+	if len(filenames) == 0 {
+		fatal("no filenames to process")
+	}
+	fmt.Printf("Loaded %d %s files to process\n", len(filenames), mt.Name())
+
+	store, err := storage.Open(cfg.StorageBackend, dbPath)
+	if err != nil {
+		fatal("initializing storage failed", "error", err)
+	}
+	defer store.Close()
+
+	// Media types don't use the LLM; the client only reports empty usage
+	llmClient := llm.NewClient(cfg, httpClient)
+	defer llmClient.Close()
+
+	proc := processor.NewProcessor(cfg, nil, nil, nil, store)
+	defer proc.Close()
+	proc.SetIgnoreRules(loadIgnoreRules(store, cfg.StorageBackend, dbPath))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	resultChan := make(chan media.Result, 100)
+	var results []media.Result
+	done := make(chan struct{})
+	go func() {
+		for result := range resultChan {
+			results = append(results, result)
+		}
+		close(done)
+	}()
+
+	startTime := time.Now()
+	run, pending := startRun(ctx, store, proc, llmClient, cfg, mt.Name(), source, "", filenames, resume)
+	runBatch(ctx, proc, len(pending), func(ctx context.Context) {
+		proc.RunBatch(ctx, mt, pending, resultChan)
+	})
+	close(resultChan)
+	<-done
+	finishRun(store, run, proc, llmClient)
+
+	if err := writeJSON(cfg.OutputFile, results, indent); err != nil {
+		fatal("saving results failed", "error", err)
+	}
+	if cfg.OutputFile != stdoutPath {
+		fmt.Printf("\nResults saved to: %s\n", cfg.OutputFile)
+	}
+	printSummary(proc, llmClient, time.Since(startTime))
+	if run != nil {
+		fmt.Printf("Run ID:          %d\n", run.ID)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"comic-parser/internal/archive"
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/library"
+	"comic-parser/internal/storage"
+)
+
+// writeMetadataCommand implements `comic-parser write-metadata`, which tags
+// matched CBZ files with a ComicInfo.xml built from their ComicVine match.
+func writeMetadataCommand(args []string) error {
+	fs := flag.NewFlagSet("write-metadata", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the ComicVine API key)")
+	dryRun := fs.Bool("dry-run", false, "Print the ComicInfo.xml that would be written without changing any file")
+	backup := fs.Bool("backup", false, "Keep each original archive as <name>"+archive.BackupSuffix)
+	allMatches := fs.Bool("all-matches", false, "Include medium and low confidence matches, not just high confidence ones")
+	credits := fs.Bool("credits", true, "Fetch creator credits from ComicVine (one request per file)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: comic-parser write-metadata [flags] [filenames...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return err
+	}
+
+	only := make(map[string]bool, fs.NArg())
+	for _, name := range fs.Args() {
+		only[name] = true
+		if abs, err := filepath.Abs(name); err == nil {
+			only[abs] = true
+		}
+	}
+
+	var cvClient *comicvine.Client
+	if *credits {
+		cfg, err := config.LoadConfig(*configFile)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		cfg.LoadFromEnv()
+		if cfg.ComicVineAPIKey != "" {
+			cvClient = comicvine.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+			defer cvClient.Close()
+		} else {
+			slog.Info("no ComicVine API key configured; writing metadata without credits")
+		}
+	}
+
+	var written, skipped, failed int
+	for _, r := range results {
+		if len(only) > 0 && !only[r.Filename] && !only[r.Path] {
+			continue
+		}
+		if r.LaterIssue() || !*allMatches && r.Match.MatchConfidence != "high" {
+			continue
+		}
+
+		path := r.Path
+		if path == "" {
+			path = library.LocalPath(r.Filename)
+		}
+		if path == "" {
+			fmt.Printf("skip  %s: file not found locally\n", r.Filename)
+			skipped++
+			continue
+		}
+		if !archive.Writable(path) {
+			fmt.Printf("skip  %s: only CBZ archives can be written\n", path)
+			skipped++
+			continue
+		}
+
+		ci, err := archive.ReadComicInfo(path)
+		if errors.Is(err, archive.ErrNoComicInfo) {
+			ci, err = &archive.ComicInfo{}, nil
+		}
+		if err != nil {
+			fmt.Printf("skip  %s: %v\n", path, err)
+			skipped++
+			continue
+		}
+
+		issue := *r.Match.SelectedIssue
+		if cvClient != nil {
+			details, err := cvClient.GetIssue(ctx, issue.ID)
+			if err != nil {
+				slog.Warn("fetching credits failed", "path", path, "error", err)
+			} else {
+				issue.Credits = details.Credits
+			}
+		}
+		ci.ApplyIssue(&issue)
+
+		if *dryRun {
+			data, err := archive.MarshalComicInfo(ci)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("=== %s ===\n%s", path, data)
+			written++
+			continue
+		}
+
+		if err := archive.WriteComicInfo(path, ci, archive.WriteOptions{Backup: *backup}); err != nil {
+			fmt.Printf("fail  %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("wrote %s\n", path)
+		written++
+	}
+
+	verb := "Wrote"
+	if *dryRun {
+		verb = "Would write"
+	}
+	fmt.Printf("\n%s metadata to %d files (%d skipped, %d failed)\n", verb, written, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be written", failed)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"comic-parser/internal/llm"
+	"comic-parser/internal/models"
+	"comic-parser/internal/notify"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/storage"
+)
+
+// notifyTimeout bounds how long a finished batch waits on the webhook
+const notifyTimeout = 15 * time.Second
+
+// batchOutcome is what a finished batch reports to the notify_url webhook.
+type batchOutcome struct {
+	mode, source string
+	total        int
+	results      string // where the results went
+	elapsed      time.Duration
+	err          error                      // set when the results could not be saved
+	processed    []*models.ProcessingResult // failures are listed from these without a run
+}
+
+// notifyRun posts a summary of a finished batch to the notify_url webhook.
+// finishRun must have been called, so run holds the final counts. A failed
+// notification is logged and otherwise ignored.
+func notifyRun(notifier *notify.Notifier, store *storage.Storage, run *models.BatchRun, proc *processor.Processor, llmClient *llm.Client, outcome batchOutcome) {
+	if notifier == nil {
+		return
+	}
+
+	progress := proc.GetProgress()
+	s := &notify.Summary{
+		Mode:           outcome.mode,
+		Source:         outcome.source,
+		Results:        outcome.results,
+		Total:          outcome.total,
+		Processed:      progress.Processed,
+		Successful:     progress.Successful,
+		Failed:         progress.Failed,
+		Skipped:        progress.Skipped,
+		ElapsedSeconds: outcome.elapsed.Seconds(),
+		LLMCost:        llmClient.EstimatedCost(),
+		FinishedAt:     time.Now(),
+	}
+	if run != nil {
+		// Resumed runs report their totals across sessions
+		s.RunID = run.ID
+		s.Total = run.Total
+		s.Processed, s.Successful, s.Failed, s.Skipped = run.Processed, run.Successful, run.Failed, run.Skipped
+		s.LLMCost = run.LLMCost
+	}
+	s.Failures = runFailures(store, run, outcome.processed)
+
+	switch {
+	case outcome.err != nil:
+		s.Status = notify.StatusFailed
+		s.Error = outcome.err.Error()
+		s.Results = ""
+	case s.Processed > 0 && s.Successful == 0:
+		s.Status = notify.StatusFailed
+		s.Error = "every file failed"
+	case s.Processed+s.Skipped < s.Total:
+		s.Status = notify.StatusInterrupted
+	default:
+		s.Status = notify.StatusCompleted
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	if err := notifier.Send(ctx, s); err != nil {
+		slog.Warn("could not send run notification", "error", err)
+	}
+}
+
+// runFailures lists up to notify.MaxFailures failed files, from the run's
+// stored results when there is a run and from processed otherwise.
+func runFailures(store *storage.Storage, run *models.BatchRun, processed []*models.ProcessingResult) []notify.Failure {
+	var failures []notify.Failure
+	if store != nil && run != nil {
+		results, err := store.ListRunResults(context.Background(), run.ID)
+		if err != nil {
+			slog.Warn("could not list failed files for the notification", "run", run.ID, "error", err)
+		}
+		for _, r := range results {
+			if !r.Success && len(failures) < notify.MaxFailures {
+				failures = append(failures, notify.Failure{Filename: r.Filename, Error: r.Error, Code: r.ErrorCode})
+			}
+		}
+		return failures
+	}
+	for _, r := range processed {
+		if !r.Success && len(failures) < notify.MaxFailures {
+			failures = append(failures, notify.Failure{Filename: r.Filename, Error: r.Error, Code: r.ErrorCode})
+		}
+	}
+	return failures
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+const organizeUsage = "usage: comic-parser organize [flags] <root>\n       comic-parser organize -undo [-db path]"
+
+// organizeCommand implements `comic-parser organize`, which renames matched
+// files into a library layout built from their ComicVine match.
+func organizeCommand(args []string) error {
+	fs := flag.NewFlagSet("organize", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for organize_template)")
+	template := fs.String("template", "", "Path template relative to the root (default from config)")
+	dryRun := fs.Bool("dry-run", false, "Print the planned moves without changing any file")
+	allMatches := fs.Bool("all-matches", false, "Include medium and low confidence matches, not just high confidence ones")
+	undo := fs.Bool("undo", false, "Move the files of the last organize back to where they were")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), organizeUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	if *undo {
+		return undoOrganize(ctx, store, *dryRun)
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New(organizeUsage)
+	}
+	root := fs.Arg(0)
+
+	if *template == "" {
+		cfg, err := config.LoadConfig(*configFile)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		*template = cfg.OrganizeTemplate
+	}
+	tmpl, err := library.ParseTemplate(*template)
+	if err != nil {
+		return err
+	}
+
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return err
+	}
+
+	var items []library.OrganizeItem
+	var skipped int
+	for _, r := range results {
+		if r.LaterIssue() || !*allMatches && r.Match.MatchConfidence != "high" {
+			continue
+		}
+		path := r.Path
+		if path == "" {
+			path = library.LocalPath(r.Filename)
+		}
+		if path == "" {
+			fmt.Printf("skip  %s: file not found locally\n", r.Filename)
+			skipped++
+			continue
+		}
+		items = append(items, library.OrganizeItem{Filename: r.Filename, Path: path, Issue: r.Match.SelectedIssue})
+	}
+
+	moves, held, err := library.PlanMoves(root, tmpl, items)
+	if err != nil {
+		return err
+	}
+	for _, m := range held {
+		fmt.Printf("skip  %s: %s\n", m.From, m.Reason)
+	}
+	skipped += len(held)
+
+	if *dryRun {
+		for _, m := range moves {
+			fmt.Printf("%s\n  -> %s\n", m.From, m.To)
+		}
+		fmt.Printf("\nWould move %d files (%d skipped)\n", len(moves), skipped)
+		return nil
+	}
+
+	operation, err := store.NextMoveOperation(ctx)
+	if err != nil {
+		return err
+	}
+
+	var moved, failed int
+	for _, m := range moves {
+		if err := library.MoveFile(m.From, m.To); err != nil {
+			fmt.Printf("fail  %s: %v\n", m.From, err)
+			failed++
+			continue
+		}
+		if err := recordMove(ctx, store, operation, m); err != nil {
+			return err
+		}
+		fmt.Printf("moved %s\n  -> %s\n", m.From, m.To)
+		moved++
+	}
+
+	fmt.Printf("\nMoved %d files (%d skipped, %d failed)\n", moved, skipped, failed)
+	if moved > 0 {
+		fmt.Println("Undo with: comic-parser organize -undo")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be moved", failed)
+	}
+	return nil
+}
+
+// undoOrganize moves the files of the last organize back and restores
+// their records.
+func undoOrganize(ctx context.Context, store *storage.Storage, dryRun bool) error {
+	moves, err := store.ListLastMoveOperation(ctx)
+	if err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		fmt.Println("Nothing to undo")
+		return nil
+	}
+
+	var restored, failed int
+	for i := range moves {
+		m := &moves[i]
+		if dryRun {
+			fmt.Printf("%s\n  -> %s\n", m.NewPath, m.OldPath)
+			restored++
+			continue
+		}
+		if err := library.MoveFile(m.NewPath, m.OldPath); err != nil {
+			fmt.Printf("fail  %s: %v\n", m.NewPath, err)
+			failed++
+			continue
+		}
+		if err := store.UndoMove(ctx, m); err != nil {
+			return err
+		}
+		restored++
+	}
+
+	verb := "Restored"
+	if dryRun {
+		verb = "Would restore"
+	}
+	fmt.Printf("\n%s %d files moved by organize operation %d (%d failed)\n", verb, restored, moves[0].OperationID, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d files could not be moved back", failed)
+	}
+	return nil
+}
+
+// recordMove records a file moved by organize operation so it can be undone,
+// moving the file back if the record can't be saved to keep the database
+// consistent with the disk.
+func recordMove(ctx context.Context, store *storage.Storage, operation int64, m library.PlannedMove) error {
+	move := &models.FileMove{
+		OperationID: operation,
+		Filename:    m.Filename,
+		OldPath:     m.From,
+		NewPath:     m.To,
+		MovedAt:     time.Now(),
+	}
+	return library.ConfirmMove(m.From, m.To, func() error {
+		return store.RecordMove(ctx, move)
+	})
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"comic-parser/internal/models"
+)
+
+const (
+	// stdoutPath is the -output value that writes results to stdout.
+	stdoutPath = "-"
+
+	// formatJSONL writes one JSON result per line, as each file completes.
+	formatJSONL = "jsonl"
+
+	// formatTemplate writes each result with the -template text/template, as
+	// each file completes.
+	formatTemplate = "template"
+)
+
+// outputOptions are the settings results are written with.
+type outputOptions struct {
+	format   string
+	indent   int                // spaces to indent JSON by
+	template *template.Template // for formatTemplate
+}
+
+// streams reports whether results are written as each file completes rather
+// than once the batch is done.
+func (o outputOptions) streams() bool {
+	return o.format == formatJSONL || o.format == formatTemplate
+}
+
+// parseResultTemplate parses a -template that renders one result per line:
+// a newline is added unless the template ends with one. The pipeline renders
+// resultViews with it.
+func parseResultTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		return nil, errors.New("-format template needs a -template")
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New("result").Parse(text)
+}
+
+// checkResultTemplate runs tmpl once against the view of a matched and of a
+// failed result with nothing written, so a field the view doesn't have, or
+// a Result.Match field used without a guard, is reported before any output.
+func checkResultTemplate(tmpl *template.Template, view func(*models.ProcessingResult) any) error {
+	if err := tmpl.Execute(io.Discard, view(sampleMatch)); err != nil {
+		return err
+	}
+	if err := tmpl.Execute(io.Discard, view(sampleFailure)); err != nil {
+		return fmt.Errorf("with a file that didn't match: %w", err)
+	}
+	return nil
+}
+
+var (
+	// sampleMatch has every part of a result a template can reach, for
+	// checkResultTemplate.
+	sampleMatch = &models.ProcessingResult{
+		Match: &models.MatchResult{SelectedIssue: &models.ComicVineIssue{}},
+	}
+	// sampleFailure is a result without a match, for checkResultTemplate.
+	sampleFailure = &models.ProcessingResult{Error: "no match"}
+)
+
+// resultView is what -template renders for each result. The flat fields
+// follow the organize template's names; Result has everything else.
+type resultView struct {
+	Filename   string
+	Path       string
+	Success    bool
+	Error      string
+	ErrorCode  string
+	Title      string // the parsed title; IssueName is the matched issue's
+	Confidence string // of the match
+	Publisher  string
+	Series     string
+	StartYear  string
+	Issue      string
+	IssueName  string
+	Year       string // cover year of the match, or the parsed year
+	ID         int    // ComicVine ID
+	URL        string
+	Result     *models.ProcessingResult
+}
+
+// newResultView flattens result for templates.
+func newResultView(result *models.ProcessingResult) resultView {
+	v := resultView{
+		Filename:  result.Filename,
+		Path:      result.Path,
+		Success:   result.Success,
+		Error:     result.Error,
+		ErrorCode: result.ErrorCode,
+		Result:    result,
+	}
+	m := result.Match
+	if m == nil {
+		return v
+	}
+	v.Title = m.ParsedInfo.Title
+	v.Issue = m.ParsedInfo.IssueNumber
+	v.Year = m.ParsedInfo.Year
+	v.Publisher = m.ParsedInfo.Publisher
+	v.Confidence = m.MatchConfidence
+	v.ID = m.ComicVineID
+	v.URL = m.ComicVineURL
+	if issue := m.SelectedIssue; issue != nil {
+		v.Series = issue.Volume.Name
+		v.StartYear = issue.Volume.StartYear
+		v.Issue = issue.IssueNumber
+		v.IssueName = issue.Name
+		if issue.Volume.Publisher != "" {
+			v.Publisher = issue.Volume.Publisher
+		}
+		if issue.CoverDate.Year > 0 {
+			v.Year = strconv.Itoa(issue.CoverDate.Year)
+		}
+	}
+	return v
+}
+
+// stdout is the process's standard output. When results are written to it,
+// os.Stdout is pointed at stderr so messages and progress stay out of them.
+var stdout = os.Stdout
+
+// routeStdout keeps stdout for the results when path writes them there.
+func routeStdout(path string) {
+	if path == stdoutPath {
+		os.Stdout = os.Stderr
+	}
+}
+
+// createOutput creates the results file at path and its directory, or
+// returns stdout for "-".
+func createOutput(path string) (io.WriteCloser, error) {
+	if path == stdoutPath {
+		return nopCloser{stdout}, nil
+	}
+	if err := makeParentDir(path); err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// makeParentDir creates the directory of path if needed.
+func makeParentDir(path string) error {
+	dir := filepath.Dir(path)
+	if dir == "" || dir == "." {
+		return nil
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// addIndentFlag adds the -indent flag of commands that print JSON.
+func addIndentFlag(fs *flag.FlagSet) *int {
+	return fs.Int("indent", 2, "Spaces to indent JSON by (0 = one line)")
+}
+
+// writeJSON writes v to path, or to stdout for "-", as JSON indented by
+// indent spaces, or on one line when indent is 0.
+func writeJSON(path string, v any, indent int) error {
+	if indent < 0 {
+		return errors.New("-indent can't be negative")
+	}
+	out, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", strings.Repeat(" ", indent))
+	if err := encoder.Encode(v); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeCSV writes a header and rows to path, or to stdout for "-", as CSV.
+func writeCSV(path string, header []string, rows [][]string) error {
+	out, err := createOutput(path)
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(out)
+	writer.Write(header)
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// resultStream writes processing results as JSON Lines or with a template
+// as they complete, so a long batch can be piped into other tools while it
+// runs.
+type resultStream struct {
+	mu     sync.Mutex
+	out    io.WriteCloser
+	render func(*models.ProcessingResult) ([]byte, error)
+	failed int   // results that could not be rendered
+	err    error // first write error
+}
+
+// newResultStream starts a stream of results to path in the streaming
+// format of opts.
+func newResultStream(path string, opts outputOptions) (*resultStream, error) {
+	out, err := createOutput(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &resultStream{out: out}
+	if opts.format == formatTemplate {
+		s.render = func(result *models.ProcessingResult) ([]byte, error) {
+			var buf bytes.Buffer
+			err := opts.template.Execute(&buf, newResultView(result))
+			return buf.Bytes(), err
+		}
+	} else {
+		s.render = func(result *models.ProcessingResult) ([]byte, error) {
+			data, err := json.Marshal(result)
+			return append(data, '\n'), err
+		}
+	}
+	return s, nil
+}
+
+// Write writes result. A result that can't be rendered is logged and left
+// out, and the stream goes on with the next one. After a failed write the
+// stream drops the remaining results, and Close returns the error.
+func (s *resultStream) Write(result *models.ProcessingResult) {
+	data, err := s.render(result)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		slog.Warn("rendering result failed", "file", result.Filename, "error", err)
+		s.failed++
+		return
+	}
+	if s.err == nil {
+		_, s.err = s.out.Write(data)
+	}
+}
+
+// Close closes the stream and returns the first write error, or an error
+// counting the results that could not be rendered.
+func (s *resultStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.out.Close(); s.err == nil {
+		s.err = err
+	}
+	if s.err == nil && s.failed > 0 {
+		return fmt.Errorf("%d results could not be rendered and were left out", s.failed)
+	}
+	return s.err
+}
+
+// saveStream writes results in a streaming format all at once.
+func saveStream(results []*models.ProcessingResult, path string, opts outputOptions) error {
+	stream, err := newResultStream(path, opts)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		stream.Write(result)
+	}
+	return stream.Close()
+}
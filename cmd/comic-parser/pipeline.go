@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/provider"
+	"comic-parser/internal/storage"
+)
+
+// pipelineFlags are the flags of the long-running commands (serve and
+// watch) that set up parsing and matching.
+type pipelineFlags struct {
+	dbPath       *string
+	configFile   *string
+	parserName   *string
+	selectorName *string
+	providerName *string
+	useComicInfo *bool
+	noLLMCache   *bool
+}
+
+func addPipelineFlags(fs *flag.FlagSet) *pipelineFlags {
+	return &pipelineFlags{
+		dbPath:       fs.String("db", defaultDBPath, "Database path"),
+		configFile:   fs.String("config", "config.json", "Path to configuration file"),
+		parserName:   fs.String("parser", "", "Parser to use: regex, llm, or a chain such as regex,llm (default from config: regex,llm)"),
+		selectorName: fs.String("selector", "llm", "Match selector: llm, or heuristic to score candidates without the LLM"),
+		providerName: fs.String("provider", "", "Metadata provider to search (default from config: comicvine)"),
+		useComicInfo: fs.Bool("comicinfo", true, "Use ComicInfo.xml embedded in local archives before parsing the filename"),
+		noLLMCache:   fs.Bool("no-llm-cache", false, "Send every LLM request instead of reusing cached responses"),
+	}
+}
+
+// pipeline is an opened parse/match setup with its storage.
+type pipeline struct {
+	cfg        *config.Config
+	httpClient *http.Client
+	llmClient  *llm.Client
+	parser     parser.Parser
+	meta       provider.MetadataProvider
+	store      *storage.Storage
+	proc       *processor.Processor
+}
+
+// open loads the configuration and builds the parser, processor and
+// storage the flags describe. The caller must Close the pipeline.
+func (f *pipelineFlags) open() (*pipeline, error) {
+	cfg, err := config.LoadConfig(*f.configFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	cfg.LoadFromEnv()
+	f.override(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.LogLevel != "" {
+		levels, _ := logging.ParseLevels(cfg.LogLevel) // checked by Validate
+		if err := logging.SetLevels(levels); err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient := &http.Client{Timeout: 60 * time.Second}
+	llmClient := llm.NewClient(cfg, httpClient)
+
+	metaProvider, err := provider.New(cfg.MetadataProvider, cfg, httpClient)
+	if err != nil {
+		llmClient.Close()
+		return nil, fmt.Errorf("creating metadata provider: %w", err)
+	}
+	p, err := newParser(*f.parserName, *f.useComicInfo, llmClient, cfg)
+	if err != nil {
+		llmClient.Close()
+		return nil, err
+	}
+	sel, err := newSelector(*f.selectorName, llmClient, cfg)
+	if err != nil {
+		llmClient.Close()
+		return nil, err
+	}
+
+	store, err := storage.Open(cfg.StorageBackend, *f.dbPath)
+	if err != nil {
+		llmClient.Close()
+		return nil, fmt.Errorf("opening storage: %w", err)
+	}
+
+	useVolumeCache(metaProvider, store, cfg)
+	useCorrections(sel, store, cfg)
+	proc := processor.NewProcessor(cfg, p, metaProvider, sel, store)
+	trackUsage(store, llmClient)
+	if set := loadMappings(store, cfg.StorageBackend, *f.dbPath); set != nil {
+		proc.SetMappings(set)
+	}
+	if aliases := loadSeriesAliases(store, cfg.StorageBackend, *f.dbPath); aliases != nil {
+		proc.SetSeriesAliases(aliases)
+	}
+	proc.SetIgnoreRules(loadIgnoreRules(store, cfg.StorageBackend, *f.dbPath))
+
+	return &pipeline{
+		cfg:        cfg,
+		httpClient: httpClient,
+		llmClient:  llmClient,
+		parser:     p,
+		meta:       metaProvider,
+		store:      store,
+		proc:       proc,
+	}, nil
+}
+
+// override applies the flags that replace config settings to cfg.
+func (f *pipelineFlags) override(cfg *config.Config) {
+	if *f.providerName != "" {
+		cfg.MetadataProvider = *f.providerName
+	}
+	if *f.noLLMCache {
+		cfg.LLMCache = false
+	}
+}
+
+// reloadOnSIGHUP re-reads the config file on every SIGHUP until ctx is done
+// and applies the settings that can change while the pipeline runs: the log
+// levels, the ComicVine hourly limit and the LLM provider, keys, model, rate
+// limit and budget. Other settings take effect on restart.
+func (p *pipeline) reloadOnSIGHUP(ctx context.Context, f *pipelineFlags) {
+	reloader := config.NewReloader(*f.configFile, p.cfg)
+	reloader.OnLoad(f.override)
+	reloader.OnChange(p.applyConfig)
+	go reloader.WatchSignals(ctx)
+}
+
+// applyConfig applies a reloaded configuration; see reloadOnSIGHUP.
+func (p *pipeline) applyConfig(old, updated *config.Config) {
+	if updated.LogLevel != old.LogLevel {
+		levels, _ := logging.ParseLevels(updated.LogLevel) // checked by Reload
+		if err := logging.SetLevels(levels); err != nil {
+			slog.Warn("applying the reloaded log level failed", "error", err)
+		}
+	}
+	if budgeted, ok := p.meta.(interface{ Budget() *comicvine.Budget }); ok && budgeted.Budget() != nil && updated.ComicVineHourlyLimit > 0 {
+		budgeted.Budget().SetLimit(updated.ComicVineHourlyLimit)
+	}
+	p.llmClient.Reconfigure(updated)
+}
+
+// Close releases the processor, storage and LLM client.
+func (p *pipeline) Close() {
+	p.proc.Close()
+	p.store.Close()
+	p.llmClient.Close()
+}
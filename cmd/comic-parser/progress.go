@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"comic-parser/internal/logging"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runBatch runs batch with a live dashboard when stdout is a terminal, or
+// with a plain progress line otherwise. Quitting the dashboard cancels the
+// context passed to batch, which stops after the files in progress;
+// runBatch always waits for batch to return so its results can be saved.
+func runBatch(ctx context.Context, proc *processor.Processor, total int, batch func(context.Context)) {
+	if !isTerminal(os.Stdout) {
+		proc.OnEvent(func(e processor.Event) {
+			if e.Type == processor.EventFileStarted {
+				return
+			}
+			fmt.Printf("\rProgress: %d/%d (✓ %d, ✗ %d)",
+				e.Progress.Processed, e.Progress.Total,
+				e.Progress.Successful, e.Progress.Failed)
+		})
+		defer proc.OnEvent(nil)
+		batch(ctx)
+		fmt.Println()
+		return
+	}
+
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	program := tea.NewProgram(tui.NewBatchModel(total, proc, stop))
+	proc.OnEvent(func(e processor.Event) { program.Send(tui.BatchEventMsg(e)) })
+	defer proc.OnEvent(nil)
+
+	// Log output would scramble the dashboard, so it goes to the log pane
+	logOutput := logging.SetOutput(tui.LogWriter(program))
+	defer logging.SetOutput(logOutput)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		batch(ctx)
+		program.Send(tui.BatchDoneMsg{})
+	}()
+
+	if _, err := program.Run(); err != nil {
+		logging.SetOutput(logOutput)
+		slog.Error("running progress dashboard failed", "error", err)
+	}
+	stop()
+	<-done
+}
+
+// isTerminal reports whether f is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"comic-parser/internal/prompts"
+)
+
+const promptsUsage = "usage: comic-parser prompts [-dir path] [-force]"
+
+// promptsCommand implements `comic-parser prompts`, which writes the
+// built-in LLM prompt templates to the prompts directory so they can be
+// edited there.
+func promptsCommand(args []string) error {
+	fs := flag.NewFlagSet("prompts", flag.ExitOnError)
+	dir := fs.String("dir", "", "Directory to write the templates to (default xander/prompts in the user configuration directory)")
+	force := fs.Bool("force", false, "Overwrite existing templates")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), promptsUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		return errors.New(promptsUsage)
+	}
+
+	if *dir == "" {
+		var err error
+		if *dir, err = prompts.DefaultDir(); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+
+	for _, name := range prompts.Names {
+		path := filepath.Join(*dir, name)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if !*force {
+			flags |= os.O_EXCL
+		}
+		f, err := os.OpenFile(path, flags, 0644)
+		if errors.Is(err, os.ErrExist) {
+			fmt.Printf("Kept %s (already exists; -force overwrites it)\n", path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = prompts.WriteBuiltin(f, name)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Printf("Wrote %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/komga"
+	"comic-parser/internal/storage"
+)
+
+// pushCommand implements `comic-parser push`, which sends matched metadata
+// to the books of a Komga library, pairing stored results with books by
+// file name.
+func pushCommand(args []string) error {
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the Komga server and credentials)")
+	dryRun := fs.Bool("dry-run", false, "Report what would be pushed without changing Komga")
+	series := fs.String("series", "", "Only push matches whose series name contains this text (case-insensitive)")
+	library := fs.String("library", "", "Only match books of this Komga library ID")
+	allMatches := fs.Bool("all-matches", false, "Include medium and low confidence matches, not just high confidence ones")
+	credits := fs.Bool("credits", true, "Fetch creator credits from ComicVine (one request per file)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: comic-parser push [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	cfg.LoadFromEnv()
+	if err := cfg.ValidateKomga(); err != nil {
+		return err
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return err
+	}
+
+	client := komga.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+	books, err := client.ListBooks(ctx, *library)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string][]komga.Book, len(books))
+	for _, book := range books {
+		byName[book.Filename()] = append(byName[book.Filename()], book)
+	}
+
+	var cvClient *comicvine.Client
+	if *credits && !*dryRun {
+		if cfg.ComicVineAPIKey != "" {
+			cvClient = comicvine.NewClient(cfg, &http.Client{Timeout: 60 * time.Second})
+			defer cvClient.Close()
+		} else {
+			slog.Info("no ComicVine API key configured; pushing metadata without credits")
+		}
+	}
+
+	filter := strings.ToLower(*series)
+	pushedSeries := make(map[string]bool)
+	var pushed, skipped, failed int
+	for _, r := range results {
+		if r.LaterIssue() || !*allMatches && r.Match.MatchConfidence != "high" {
+			continue
+		}
+		issue := *r.Match.SelectedIssue
+		if filter != "" && !strings.Contains(strings.ToLower(issue.Volume.Name), filter) {
+			continue
+		}
+
+		candidates := byName[filepath.Base(r.Filename)]
+		switch len(candidates) {
+		case 0:
+			fmt.Printf("skip  %s: no Komga book with this file name\n", r.Filename)
+			skipped++
+			continue
+		case 1:
+		default:
+			fmt.Printf("skip  %s: %d Komga books share this file name\n", r.Filename, len(candidates))
+			skipped++
+			continue
+		}
+		book := candidates[0]
+
+		if *dryRun {
+			fmt.Printf("would push %s -> book %s: %s #%s\n", r.Filename, book.ID, issue.Volume.Name, issue.IssueNumber)
+			pushed++
+			continue
+		}
+
+		if cvClient != nil {
+			details, err := cvClient.GetIssue(ctx, issue.ID)
+			if err != nil {
+				slog.Warn("fetching credits failed", "file", r.Filename, "error", err)
+			} else {
+				issue.Credits = details.Credits
+			}
+		}
+
+		if !pushedSeries[book.SeriesID] {
+			if err := client.UpdateSeriesMetadata(ctx, book.SeriesID, komga.SeriesMetadataFor(&issue)); err != nil {
+				fmt.Printf("fail  %s: %v\n", r.Filename, err)
+				failed++
+				continue
+			}
+			pushedSeries[book.SeriesID] = true
+		}
+		if err := client.UpdateBookMetadata(ctx, book.ID, komga.BookMetadataFor(&issue)); err != nil {
+			fmt.Printf("fail  %s: %v\n", r.Filename, err)
+			failed++
+			continue
+		}
+		fmt.Printf("push  %s -> book %s\n", r.Filename, book.ID)
+		pushed++
+	}
+
+	verb := "Pushed"
+	if *dryRun {
+		verb = "Would push"
+	}
+	fmt.Printf("\n%s metadata to %d books (%d skipped, %d failed)\n", verb, pushed, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d books could not be updated", failed)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"comic-parser/internal/library"
+	"comic-parser/internal/storage"
+)
+
+const reconcileUsage = "usage: comic-parser reconcile [-db path] [-fix-missing] [-fix-moved] [-unprocessed-out file] <root>"
+
+// reconcileCommand implements `comic-parser reconcile <root>`, comparing the
+// files under a library root with the records stored in the database.
+func reconcileCommand(args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	fixMissing := fs.Bool("fix-missing", false, "Delete records whose files no longer exist")
+	fixMoved := fs.Bool("fix-moved", false, "Update records of moved files to their new path")
+	unprocessedOut := fs.String("unprocessed-out", "", "Write unprocessed files to this list for use with -input")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New(reconcileUsage)
+	}
+	root := fs.Arg(0)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	files, err := library.Walk(root)
+	if err != nil {
+		return fmt.Errorf("scanning %s: %w", root, err)
+	}
+
+	stored, err := store.ListKnownFilenames(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := library.Reconcile(root, files, stored)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Files on disk:       %d\n", len(files))
+	fmt.Printf("Stored records:      %d\n", len(stored))
+	fmt.Printf("Never processed:     %d\n", len(report.Unprocessed))
+	fmt.Printf("Missing from disk:   %d\n", len(report.Missing))
+	fmt.Printf("Moved:               %d\n", len(report.Moved))
+
+	printList("Never processed", report.Unprocessed)
+	printList("Missing from disk", report.Missing)
+	if len(report.Moved) > 0 {
+		fmt.Printf("\n=== Moved ===\n")
+		for _, m := range report.Moved {
+			fmt.Printf("%s\n  -> %s\n", m.Stored, m.Current)
+		}
+	}
+
+	if *unprocessedOut != "" && len(report.Unprocessed) > 0 {
+		data := strings.Join(report.Unprocessed, "\n") + "\n"
+		if err := os.WriteFile(*unprocessedOut, []byte(data), 0644); err != nil {
+			return fmt.Errorf("writing unprocessed list: %w", err)
+		}
+		fmt.Printf("\nWrote %d unprocessed files to %s\n", len(report.Unprocessed), *unprocessedOut)
+	}
+
+	if *fixMissing {
+		for _, name := range report.Missing {
+			if err := store.DeleteRecords(ctx, name); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Deleted records for %d missing files\n", len(report.Missing))
+	}
+
+	if *fixMoved {
+		for _, m := range report.Moved {
+			if err := store.RenameRecords(ctx, m.Stored, m.Current); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Updated %d moved records\n", len(report.Moved))
+	}
+
+	return nil
+}
+
+func printList(heading string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("\n=== %s ===\n", heading)
+	for _, item := range items {
+		fmt.Println(item)
+	}
+}
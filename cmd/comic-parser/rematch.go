@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+const rematchUsage = "usage: comic-parser rematch -failed [-low] [flags]"
+
+// rematchCommand implements `comic-parser rematch`, which processes the
+// files of failed or weakly matched results again, typically with another
+// parser, selector or provider. Results are replaced in place.
+func rematchCommand(args []string) error {
+	fs := flag.NewFlagSet("rematch", flag.ExitOnError)
+	pf := addPipelineFlags(fs)
+	failed := fs.Bool("failed", false, "Re-match the files whose processing failed")
+	low := fs.Bool("low", false, "Also re-match the files matched with low or no confidence")
+	workers := fs.Int("workers", 3, "Number of concurrent workers")
+	dryRun := fs.Bool("dry-run", false, "List the files that would be re-matched without processing them")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), rematchUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if !*failed && !*low || fs.NArg() > 0 {
+		return errors.New(rematchUsage)
+	}
+
+	pl, err := pf.open()
+	if err != nil {
+		return err
+	}
+	defer pl.Close()
+	if *workers > 0 {
+		pl.cfg.WorkerCount = *workers
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	candidates, err := pl.store.ListRematchCandidates(ctx, *failed, *low)
+	if err != nil {
+		return err
+	}
+	// The later issues of a multi-issue file are re-matched by processing
+	// the file again.
+	var filenames []string
+	seen := make(map[string]bool)
+	rematched := make(map[string]bool)
+	for _, c := range candidates {
+		rematched[c.Filename] = true
+		filename := c.Filename
+		if c.SourceFilename != "" {
+			filename = c.SourceFilename
+		}
+		if !seen[filename] {
+			seen[filename] = true
+			filenames = append(filenames, filename)
+		}
+	}
+	if len(filenames) == 0 {
+		fmt.Println("Nothing to re-match.")
+		return nil
+	}
+	if *dryRun {
+		for _, filename := range filenames {
+			fmt.Println(filename)
+		}
+		fmt.Printf("\n%d files would be re-matched\n", len(filenames))
+		return nil
+	}
+
+	// Results replace the stored ones as they come in, the way RunBatch
+	// stores them
+	comic := pl.proc.Comic()
+	resultChan := make(chan *models.ProcessingResult, 100)
+	var results []*models.ProcessingResult
+	done := make(chan struct{})
+	go func() {
+		for result := range resultChan {
+			if err := comic.Store(context.WithoutCancel(ctx), pl.store, result); err != nil {
+				slog.Warn("storing result failed", "file", result.Filename, "error", err)
+			}
+			results = append(results, result)
+		}
+		close(done)
+	}()
+
+	parserName := *pf.parserName
+	if parserName == "" {
+		parserName = pl.cfg.ParserChain
+	}
+	startTime := time.Now()
+	run, pending := startRun(ctx, pl.store, pl.proc, pl.llmClient, pl.cfg, runModeProcess, runSourceRematch, parserName, filenames, false)
+	printBudgetPlan(pl.meta, len(pending))
+	runBatch(ctx, pl.proc, len(pending), func(ctx context.Context) {
+		pl.proc.ProcessBatch(ctx, pending, resultChan)
+	})
+	close(resultChan)
+	<-done
+	finishRun(pl.store, run, pl.proc, pl.llmClient)
+
+	recovered, stillLow, stillFailed := 0, 0, 0
+	for _, r := range results {
+		if !rematched[r.Filename] {
+			continue
+		}
+		switch {
+		case !r.Success:
+			stillFailed++
+		case r.Match == nil || lowConfidence(r.Match.MatchConfidence):
+			stillLow++
+		default:
+			recovered++
+		}
+	}
+	printSummary(pl.proc, pl.llmClient, time.Since(startTime))
+	fmt.Printf("\nRecovered %d of %d results", recovered, len(rematched))
+	fmt.Printf(" (%d still low confidence, %d still failing)\n", stillLow, stillFailed)
+	return nil
+}
+
+// lowConfidence reports whether a match confidence is too weak to trust.
+func lowConfidence(confidence string) bool {
+	return confidence == "low" || confidence == "none" || confidence == ""
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"comic-parser/internal/storage"
+	"comic-parser/internal/termimage"
+	"comic-parser/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const reviewUsage = "usage: comic-parser review [-db path] [-covers protocol]"
+
+// reviewCommand implements `comic-parser review`, a TUI for working through
+// the medium and low confidence matches queued in review mode.
+func reviewCommand(args []string) error {
+	fs := flag.NewFlagSet("review", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	coverMode := fs.String("covers", "auto", "Show cached covers with this image protocol: auto, kitty, iterm2, sixel, ascii or off")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the cache directory)")
+	dir := fs.String("dir", "", "Cache directory (default from config: .cache)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), reviewUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	protocol := termimage.Detect(os.Getenv)
+	if *coverMode != "auto" {
+		p, err := termimage.ParseProtocol(*coverMode)
+		if err != nil {
+			return err
+		}
+		protocol = p
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	model, err := tui.NewReviewModel(context.Background(), store)
+	if err != nil {
+		return fmt.Errorf("loading review queue: %w", err)
+	}
+	if protocol != termimage.Off {
+		cache, err := coverCache(*configFile, *dir)
+		switch {
+		case err == nil:
+			model = model.WithCovers(cache, protocol)
+		case *coverMode != "auto":
+			return err
+		}
+	}
+
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		return fmt.Errorf("running review: %w", err)
+	}
+	return nil
+}
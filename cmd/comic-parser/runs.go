@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/models"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/storage"
+)
+
+const (
+	// Batch run modes
+	runModeParse   = "parse"
+	runModeProcess = "process"
+
+	// runSourceArgs is the input source recorded when filenames come from the command line
+	runSourceArgs = "<args>"
+
+	// runSourceRematch is the input source recorded by rematch
+	runSourceRematch = "<rematch>"
+
+	// runSourceSync is the input source recorded by sync
+	runSourceSync = "<sync>"
+
+	runsUsage = "usage: comic-parser runs <list|show|diff> [-db path] [-json [-indent n]] [id...]"
+)
+
+// startRun records the start of a batch run and scopes the processor's
+// storage and the LLM usage log to it. With resume, it continues the latest interrupted run of the
+// same mode and source instead. It returns the run, or nil when no storage is
+// configured, and the filenames left to process. A new run leaves out the
+// files on the ignore list.
+func startRun(ctx context.Context, store *storage.Storage, proc *processor.Processor, llmClient *llm.Client, cfg *config.Config, mode, source, parserName string, filenames []string, resume bool) (*models.BatchRun, []string) {
+	if resume && store != nil {
+		if run, pending := resumeRun(ctx, store, proc, llmClient, mode, source); run != nil {
+			return run, pending
+		}
+		fmt.Printf("No interrupted run of %s to resume; starting a new run\n", source)
+	}
+
+	filenames, ignored := proc.FilterIgnored(filenames)
+	if ignored > 0 {
+		fmt.Printf("Ignoring %d files on the ignore list\n", ignored)
+	}
+	if store == nil {
+		return nil, filenames
+	}
+
+	settings, err := json.Marshal(cfg.Redacted())
+	if err != nil {
+		slog.Warn("could not snapshot settings", "error", err)
+	}
+
+	run := &models.BatchRun{
+		StartedAt:   time.Now(),
+		Mode:        mode,
+		InputSource: source,
+		ParserName:  parserName,
+		Total:       len(filenames),
+		Settings:    string(settings),
+	}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		slog.Warn("could not record batch run", "error", err)
+		return nil, filenames
+	}
+
+	runStore := store.WithRun(run.ID)
+	if err := runStore.QueueCheckpoints(ctx, filenames); err != nil {
+		slog.Warn("could not record checkpoints, the run can't be resumed", "error", err)
+	}
+	proc.SetStore(runStore)
+	trackUsage(runStore, llmClient)
+	return run, filenames
+}
+
+// resumeRun scopes the processor's storage to the latest run of mode and
+// source that has files left, and returns it with those files. The run's
+// counts are reset to the files already done, so finishRun adds this
+// session's outcomes on top. It returns nil when there is nothing to resume.
+func resumeRun(ctx context.Context, store *storage.Storage, proc *processor.Processor, llmClient *llm.Client, mode, source string) (*models.BatchRun, []string) {
+	run, err := store.FindResumableRun(ctx, mode, source)
+	if err != nil {
+		slog.Warn("could not look up interrupted runs", "error", err)
+		return nil, nil
+	}
+	if run == nil {
+		return nil, nil
+	}
+
+	pending, err := store.PendingCheckpoints(ctx, run.ID)
+	if err != nil {
+		slog.Warn("could not load checkpoints", "run", run.ID, "error", err)
+		return nil, nil
+	}
+
+	done := run.Total - len(pending)
+	run.Processed = done
+	run.Successful = done
+	run.Failed = 0
+	run.Skipped = 0
+
+	fmt.Printf("Resuming run %d: %d of %d files left\n", run.ID, len(pending), run.Total)
+	if err := store.ReopenBatchRun(ctx, run.ID); err != nil {
+		slog.Warn("could not mark the run as running", "run", run.ID, "error", err)
+	}
+	runStore := store.WithRun(run.ID)
+	proc.SetStore(runStore)
+	trackUsage(runStore, llmClient)
+	return run, pending
+}
+
+// trackUsage records every LLM call the client makes in store's usage
+// table, linked to the store's run if it has one. Failures are logged and
+// don't stop processing. It uses a context that isn't cancelled so the
+// calls made before an interrupt are still recorded.
+func trackUsage(store *storage.Storage, llmClient *llm.Client) {
+	if store == nil {
+		return
+	}
+	llmClient.SetUsageHook(func(ctx context.Context, call llm.Call) {
+		err := store.RecordLLMUsage(context.WithoutCancel(ctx), &models.LLMUsage{
+			Filename:     call.Filename,
+			Kind:         call.Kind,
+			Provider:     call.Provider,
+			Model:        call.Model,
+			InputTokens:  call.Usage.InputTokens,
+			OutputTokens: call.Usage.OutputTokens,
+			Cost:         call.Cost,
+			CreatedAt:    time.Now(),
+		})
+		if err != nil {
+			slog.Warn("could not record LLM usage", "error", err)
+		}
+	})
+}
+
+// finishRun stores the final counts and LLM usage of a batch run, adding
+// them to what a resumed run had already recorded. It uses a fresh context
+// so the summary is saved even after an interrupt.
+func finishRun(store *storage.Storage, run *models.BatchRun, proc *processor.Processor, llmClient *llm.Client) {
+	if store == nil || run == nil {
+		return
+	}
+
+	progress := proc.GetProgress()
+	usage := llmClient.Usage()
+
+	run.FinishedAt = time.Now()
+	run.Processed += progress.Processed
+	run.Successful += progress.Successful
+	run.Failed += progress.Failed
+	run.Skipped += progress.Skipped
+	run.LLMInputTokens += usage.InputTokens
+	run.LLMOutputTokens += usage.OutputTokens
+	run.LLMCost += llmClient.EstimatedCost()
+
+	if err := store.FinishBatchRun(context.Background(), run); err != nil {
+		slog.Warn("could not save batch run summary", "run", run.ID, "error", err)
+	}
+}
+
+// runsCommand implements `comic-parser runs list|show|diff`.
+func runsCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New(runsUsage)
+	}
+
+	fs := flag.NewFlagSet("runs "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	asJSON := fs.Bool("json", false, "Print diff output as JSON")
+	indent := addIndentFlag(fs)
+	fs.Parse(args[1:])
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list":
+		return listRuns(ctx, store)
+	case "show":
+		if fs.NArg() != 1 {
+			return errors.New(runsUsage)
+		}
+		id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid run id %q", fs.Arg(0))
+		}
+		return showRun(ctx, store, id)
+	case "diff":
+		if fs.NArg() != 2 {
+			return errors.New(runsUsage)
+		}
+		a, errA := strconv.ParseInt(fs.Arg(0), 10, 64)
+		b, errB := strconv.ParseInt(fs.Arg(1), 10, 64)
+		if errA != nil || errB != nil {
+			return fmt.Errorf("invalid run ids %q %q", fs.Arg(0), fs.Arg(1))
+		}
+		return diffRuns(ctx, store, a, b, *asJSON, *indent)
+	default:
+		return errors.New(runsUsage)
+	}
+}
+
+func listRuns(ctx context.Context, store *storage.Storage) error {
+	runs, err := store.ListBatchRuns(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("No batch runs recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tMODE\tSOURCE\tTOTAL\tOK\tFAILED\tSKIPPED\tCOST")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t$%.4f\n",
+			run.ID,
+			run.StartedAt.Local().Format(time.DateTime),
+			runDuration(run),
+			run.Mode,
+			run.InputSource,
+			run.Total,
+			run.Successful,
+			run.Failed,
+			run.Skipped,
+			run.LLMCost)
+	}
+	return w.Flush()
+}
+
+func showRun(ctx context.Context, store *storage.Storage, id int64) error {
+	run, err := store.GetBatchRun(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	parsed, processed, err := store.CountRunResults(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Run ID:          %d\n", run.ID)
+	fmt.Printf("Mode:            %s\n", run.Mode)
+	fmt.Printf("Input source:    %s\n", run.InputSource)
+	if run.ParserName != "" {
+		fmt.Printf("Parser:          %s\n", run.ParserName)
+	}
+	fmt.Printf("Started:         %s\n", run.StartedAt.Local().Format(time.DateTime))
+	if !run.FinishedAt.IsZero() {
+		fmt.Printf("Finished:        %s\n", run.FinishedAt.Local().Format(time.DateTime))
+	}
+	fmt.Printf("Duration:        %s\n", runDuration(run))
+	fmt.Printf("Total:           %d\n", run.Total)
+	fmt.Printf("Processed:       %d\n", run.Processed)
+	fmt.Printf("Successful:      %d\n", run.Successful)
+	fmt.Printf("Failed:          %d\n", run.Failed)
+	fmt.Printf("Skipped:         %d\n", run.Skipped)
+	fmt.Printf("LLM tokens:      %d in / %d out (~$%.4f)\n", run.LLMInputTokens, run.LLMOutputTokens, run.LLMCost)
+	fmt.Printf("Linked records:  %d parsed, %d results\n", parsed, processed)
+
+	if run.Settings != "" {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(run.Settings), "", "  "); err == nil {
+			fmt.Printf("\nSettings:\n%s\n", pretty.String())
+		}
+	}
+	return nil
+}
+
+func diffRuns(ctx context.Context, store *storage.Storage, a, b int64, asJSON bool, indent int) error {
+	for _, id := range []int64{a, b} {
+		if _, err := store.GetBatchRun(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	diff, err := store.DiffRuns(ctx, a, b)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return writeJSON(stdoutPath, diff, indent)
+	}
+
+	fmt.Printf("Comparing run %d -> run %d\n", a, b)
+	fmt.Printf("Newly matched: %d\n", len(diff.NewlyMatched))
+	fmt.Printf("Newly failed:  %d\n", len(diff.NewlyFailed))
+	fmt.Printf("Changed:       %d\n", len(diff.Changed))
+	fmt.Printf("Unchanged:     %d\n", diff.Unchanged)
+	if len(diff.OnlyBefore) > 0 || len(diff.OnlyAfter) > 0 {
+		fmt.Printf("Only in run %d: %d, only in run %d: %d\n", a, len(diff.OnlyBefore), b, len(diff.OnlyAfter))
+	}
+
+	printChanges("Newly matched", diff.NewlyMatched)
+	printChanges("Newly failed", diff.NewlyFailed)
+	printChanges("Changed selections", diff.Changed)
+	return nil
+}
+
+func printChanges(heading string, changes []models.RunResultChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("\n=== %s ===\n", heading)
+	for _, c := range changes {
+		fmt.Printf("%s\n  before: %s\n  after:  %s\n", c.Filename, describeRunResult(c.Before), describeRunResult(c.After))
+	}
+}
+
+func describeRunResult(r *models.RunResult) string {
+	if !r.Success {
+		return "failed: " + r.Error
+	}
+	desc := fmt.Sprintf("%s #%s", r.Title, r.IssueNumber)
+	if r.Year != "" {
+		desc += fmt.Sprintf(" (%s)", r.Year)
+	}
+	if r.MatchConfidence != "" {
+		if r.ComicVineID != 0 {
+			desc += fmt.Sprintf(" -> ComicVine %d [%s]", r.ComicVineID, r.MatchConfidence)
+		} else {
+			desc += " -> no match"
+		}
+	}
+	return desc
+}
+
+func runDuration(run *models.BatchRun) string {
+	if run.FinishedAt.IsZero() {
+		return "unfinished"
+	}
+	return run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+const dbSearchUsage = `usage: comic-parser db search [-db path] [-limit n] [-json [-indent n] | -template text] "query"`
+
+// dbSearchCommand finds stored comics whose filename, series, title or
+// description contain every word of the query.
+func dbSearchCommand(args []string) error {
+	fs := flag.NewFlagSet("db search", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	limit := fs.Int("limit", 20, "Maximum number of results (0 for all)")
+	asJSON := fs.Bool("json", false, "Print the results as JSON")
+	indent := addIndentFlag(fs)
+	tmplText := fs.String("template", "", "Go text/template to print each result with, with the fields of -format template and Snippet, e.g. '{{.Series}} #{{.Issue}}: {{.Filename}}'")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), dbSearchUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" || (*asJSON && *tmplText != "") {
+		return errors.New(dbSearchUsage)
+	}
+	var tmpl *template.Template
+	if *tmplText != "" {
+		var err error
+		if tmpl, err = parseResultTemplate(*tmplText); err != nil {
+			return fmt.Errorf("invalid -template: %w", err)
+		}
+		if err := checkResultTemplate(tmpl, func(result *models.ProcessingResult) any {
+			return searchView{resultView: newResultView(result)}
+		}); err != nil {
+			return fmt.Errorf("invalid -template: %w", err)
+		}
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	hits, err := store.Search(context.Background(), query, *limit)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if hits == nil {
+			hits = []models.SearchHit{}
+		}
+		return writeJSON(stdoutPath, hits, *indent)
+	}
+
+	if tmpl != nil {
+		views, err := searchViews(context.Background(), store, hits)
+		if err != nil {
+			return err
+		}
+		for _, view := range views {
+			if err := tmpl.Execute(os.Stdout, view); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(hits) == 0 {
+		fmt.Println("No matches.")
+		return nil
+	}
+	for _, hit := range hits {
+		fmt.Println(hit.Filename)
+		var heading []string
+		for _, part := range []string{hit.Series, hit.Title} {
+			if part != "" {
+				heading = append(heading, part)
+			}
+		}
+		if len(heading) > 0 {
+			fmt.Printf("  %s\n", strings.Join(heading, " - "))
+		}
+		if hit.Snippet != "" {
+			fmt.Printf("  %s\n", strings.Join(strings.Fields(hit.Snippet), " "))
+		}
+	}
+	return nil
+}
+
+// searchView is what db search -template renders for each hit: the stored
+// result under the field names of -format template, and the hit's snippet.
+type searchView struct {
+	resultView
+	Snippet string
+}
+
+// searchViews looks up the stored parse and match of each hit.
+func searchViews(ctx context.Context, store *storage.Storage, hits []models.SearchHit) ([]searchView, error) {
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := store.ListParsedFilenames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]*models.ProcessingResult, len(matched))
+	for _, r := range matched {
+		results[r.Filename] = r
+	}
+	parses := make(map[string]*models.ParsedFilename, len(parsed))
+	for _, p := range parsed {
+		parses[p.OriginalFilename] = p
+	}
+
+	views := make([]searchView, len(hits))
+	for i, hit := range hits {
+		result := results[hit.Filename]
+		p := parses[hit.Filename]
+		if result == nil {
+			result = &models.ProcessingResult{Filename: hit.Filename}
+		} else if p != nil {
+			result.Match.ParsedInfo = *p
+		}
+		view := searchView{resultView: newResultView(result), Snippet: hit.Snippet}
+		if result.Match == nil && p != nil {
+			// Unmatched: the parse is all there is
+			view.Path = p.Path
+			view.Title = p.Title
+			view.Issue = p.IssueNumber
+			view.Year = p.Year
+			view.Publisher = p.Publisher
+		}
+		views[i] = view
+	}
+	return views, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/covers"
+	"comic-parser/internal/server"
+)
+
+// shutdownTimeout bounds how long serve waits for in-flight requests on exit
+const shutdownTimeout = 30 * time.Second
+
+// serveCommand implements `comic-parser serve`, which exposes parsing,
+// matching, the stored comics and batch progress as a JSON HTTP API, along
+// with a web page for the review queue.
+func serveCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "Address to listen on")
+	pf := addPipelineFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: comic-parser serve [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	pl, err := pf.open()
+	if err != nil {
+		return err
+	}
+	defer pl.Close()
+
+	api := server.New(pl.parser, pl.proc, pl.store)
+	if pl.cfg.CacheDir != "" {
+		api.SetCovers(covers.NewCache(pl.cfg.CacheDir, pl.httpClient))
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           api.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	pl.reloadOnSIGHUP(ctx, pf)
+	// Shutdown returns once in-flight requests are done, so storage stays
+	// open until then
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("shutting down the server failed", "error", err)
+		}
+	}()
+
+	slog.Info("serving the API", "addr", *addr, "db", *pf.dbPath, "review", "http://"+*addr+"/review/")
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-shutdown
+	return nil
+}
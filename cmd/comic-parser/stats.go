@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// statsBarWidth is the length of the longest bar of a stats chart.
+const statsBarWidth = 40
+
+// dbStatsCommand summarizes the stored results as bar charts, or as JSON for
+// dashboards.
+func dbStatsCommand(args []string) error {
+	fs := flag.NewFlagSet("db stats", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	format := fs.String("format", "text", "Output format: text or json")
+	indent := addIndentFlag(fs)
+	top := fs.Int("top", 10, "Publishers to chart, the rest summed as \"other\" (0 for all)")
+	fs.Parse(args)
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q (want text or json)", *format)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	stats, err := store.Stats(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		return writeJSON(stdoutPath, stats, *indent)
+	}
+	printStats(os.Stdout, stats, *top)
+	return nil
+}
+
+func printStats(w io.Writer, stats *models.LibraryStats, top int) {
+	fmt.Fprintf(w, "Files:     %d\n", stats.Total)
+	fmt.Fprintf(w, "Matched:   %d\n", stats.Matched)
+	fmt.Fprintf(w, "Unmatched: %d\n", stats.Unmatched)
+	fmt.Fprintf(w, "Failed:    %d (%.1f%%)\n", stats.Failed, stats.FailureRate*100)
+	if stats.Ignored > 0 {
+		fmt.Fprintf(w, "Ignored:   %d\n", stats.Ignored)
+	}
+	if stats.Total == 0 {
+		return
+	}
+
+	printChart(w, "Match confidence", stats.Confidence)
+	publishers := stats.Publishers
+	if top > 0 && len(publishers) > top {
+		other := models.StatCount{Label: "other"}
+		for _, p := range publishers[top:] {
+			other.Count += p.Count
+		}
+		publishers = append(publishers[:top:top], other)
+	}
+	printChart(w, "Publishers", publishers)
+	printChart(w, "Issues per cover year", stats.Years)
+
+	added := make([]models.StatCount, len(stats.Added))
+	for i, m := range stats.Added {
+		added[i] = models.StatCount{Label: m.Month, Count: m.Added}
+	}
+	printChart(w, "Added per month", added)
+	if len(stats.Added) > 0 {
+		fmt.Fprintf(w, "\nFailure rate per month\n")
+		for _, m := range stats.Added {
+			fmt.Fprintf(w, "  %s  %5.1f%%  (%d of %d)\n", m.Month, m.FailureRate*100, m.Failed, m.Added)
+		}
+	}
+}
+
+// printChart draws counts as horizontal bars scaled to the largest.
+func printChart(w io.Writer, title string, counts []models.StatCount) {
+	if len(counts) == 0 {
+		return
+	}
+	labelWidth, largest := 0, 0
+	for _, c := range counts {
+		labelWidth = max(labelWidth, len(c.Label))
+		largest = max(largest, c.Count)
+	}
+
+	fmt.Fprintf(w, "\n%s\n", title)
+	for _, c := range counts {
+		bar := 0
+		if largest > 0 {
+			bar = c.Count * statsBarWidth / largest
+		}
+		if bar == 0 && c.Count > 0 {
+			bar = 1
+		}
+		fmt.Fprintf(w, "  %-*s  %s %d\n", labelWidth, c.Label, strings.Repeat("#", bar), c.Count)
+	}
+}
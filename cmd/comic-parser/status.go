@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// staleProgress is how old a run's latest snapshot can get before status
+// warns that the run may have stopped without finishing.
+const staleProgress = 5 * time.Minute
+
+// statusCommand implements `comic-parser status`, which reports on the
+// batch runs in progress, typically in another terminal, from the progress
+// snapshots they save.
+func statusCommand(args []string) error {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	asJSON := fs.Bool("json", false, "Print the progress of each run as JSON")
+	indent := addIndentFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New("usage: comic-parser status [-db path] [-json]")
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	runs, err := store.ListRunProgress(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(stdoutPath, runs, *indent)
+	}
+	if len(runs) == 0 {
+		fmt.Println("No batch runs in progress.")
+		return nil
+	}
+	for i, rp := range runs {
+		if i > 0 {
+			fmt.Println()
+		}
+		printRunStatus(rp)
+	}
+	return nil
+}
+
+func printRunStatus(rp models.RunProgress) {
+	run := rp.Run
+	fmt.Printf("Run ID:          %d\n", run.ID)
+	fmt.Printf("Mode:            %s\n", run.Mode)
+	fmt.Printf("Input source:    %s\n", run.InputSource)
+	fmt.Printf("Started:         %s\n", run.StartedAt.Local().Format(time.DateTime))
+
+	snap := rp.Snapshot
+	if snap == nil {
+		// Without a snapshot, the checkpoints still tell how far it got
+		done := rp.Done + rp.Failed
+		fmt.Printf("Progress:        %d/%d (%.0f%%), %d failed\n", done, run.Total, percentOf(done, run.Total), rp.Failed)
+		fmt.Println("No progress saved yet")
+		return
+	}
+
+	done := snap.Processed + snap.Skipped
+	fmt.Printf("Progress:        %d/%d (%.0f%%)\n", done, snap.Total, percentOf(done, snap.Total))
+	fmt.Printf("Successful:      %d\n", snap.Successful)
+	fmt.Printf("Failed:          %d\n", snap.Failed)
+	if snap.Skipped > 0 {
+		fmt.Printf("Skipped:         %d (LLM budget exhausted)\n", snap.Skipped)
+	}
+	if snap.Processed > 0 {
+		avg := time.Duration(snap.AvgLatencyMS) * time.Millisecond
+		fmt.Printf("Avg time/file:   %s (recent files, %d workers)\n", avg.Round(time.Millisecond), snap.Workers)
+		if s := snap.Stages; s.ParseMS+s.SearchMS+s.SelectMS > 0 {
+			perFile := func(ms int64) time.Duration {
+				return (time.Duration(ms) * time.Millisecond / time.Duration(snap.Processed)).Round(time.Millisecond)
+			}
+			fmt.Printf("Stage avg:       parse %s, search %s, select %s\n", perFile(s.ParseMS), perFile(s.SearchMS), perFile(s.SelectMS))
+		}
+	}
+	if eta := snap.ETA(); eta > 0 {
+		finish := snap.UpdatedAt.Add(eta)
+		fmt.Printf("ETA:             %s (around %s)\n", eta.Round(time.Second), finish.Local().Format(time.TimeOnly))
+	}
+
+	age := time.Since(snap.UpdatedAt)
+	fmt.Printf("Last update:     %s ago\n", age.Round(time.Second))
+	if age > staleProgress {
+		fmt.Println("The run has not reported progress for a while; it may have stopped. Resume it with -resume.")
+	}
+}
+
+// percentOf returns done as a percentage of total.
+func percentOf(done, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(done) / float64(total)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+const syncUsage = "usage: comic-parser sync [-limit N] [-list] [flags]"
+
+// syncCommand implements `comic-parser sync`, which matches the files parsed
+// with -offline, oldest first. A file leaves the queue once its result is
+// stored; files a shutdown or an exhausted budget kept from being matched
+// stay queued for the next sync.
+func syncCommand(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	pf := addPipelineFlags(fs)
+	limit := fs.Int("limit", 0, "Match at most this many queued files (0 = all)")
+	list := fs.Bool("list", false, "List the queued files without matching them")
+	workers := fs.Int("workers", 3, "Number of concurrent workers")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), syncUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *limit < 0 || fs.NArg() > 0 {
+		return errors.New(syncUsage)
+	}
+
+	if *list {
+		cfg, err := config.LoadConfig(*pf.configFile)
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		store, err := storage.Open(cfg.StorageBackend, *pf.dbPath)
+		if err != nil {
+			return fmt.Errorf("opening storage: %w", err)
+		}
+		defer store.Close()
+		return listMatchQueue(context.Background(), store)
+	}
+
+	pl, err := pf.open()
+	if err != nil {
+		return err
+	}
+	defer pl.Close()
+	if *workers > 0 {
+		pl.cfg.WorkerCount = *workers
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	queued, err := pl.store.ListMatchQueue(ctx)
+	if err != nil {
+		return err
+	}
+	if len(queued) == 0 {
+		fmt.Println("Nothing to sync.")
+		return nil
+	}
+	if *limit > 0 && len(queued) > *limit {
+		queued = queued[:*limit]
+	}
+	filenames := make([]string, 0, len(queued))
+	for _, q := range queued {
+		filenames = append(filenames, q.Filename)
+	}
+
+	// Results are stored the way RunBatch stores them, and their files
+	// leave the queue
+	comic := pl.proc.Comic()
+	resultChan := make(chan *models.ProcessingResult, 100)
+	synced := make(map[string]bool)
+	done := make(chan struct{})
+	go func() {
+		storeCtx := context.WithoutCancel(ctx)
+		for result := range resultChan {
+			if err := comic.Store(storeCtx, pl.store, result); err != nil {
+				slog.Warn("storing result failed", "file", result.Filename, "error", err)
+				continue
+			}
+			filename := result.Filename
+			if result.SourceFilename != "" {
+				filename = result.SourceFilename
+			}
+			if synced[filename] {
+				continue
+			}
+			synced[filename] = true
+			if err := pl.store.DequeueMatch(storeCtx, filename); err != nil {
+				slog.Warn("dequeuing file failed", "file", filename, "error", err)
+			}
+		}
+		close(done)
+	}()
+
+	parserName := *pf.parserName
+	if parserName == "" {
+		parserName = pl.cfg.ParserChain
+	}
+	startTime := time.Now()
+	run, pending := startRun(ctx, pl.store, pl.proc, pl.llmClient, pl.cfg, runModeProcess, runSourceSync, parserName, filenames, false)
+	printBudgetPlan(pl.meta, len(pending))
+	runBatch(ctx, pl.proc, len(pending), func(ctx context.Context) {
+		pl.proc.ProcessBatch(ctx, pending, resultChan)
+	})
+	close(resultChan)
+	<-done
+	finishRun(pl.store, run, pl.proc, pl.llmClient)
+
+	printSummary(pl.proc, pl.llmClient, time.Since(startTime))
+	fmt.Printf("\nSynced %d of %d queued files", len(synced), len(filenames))
+	if left, err := pl.store.ListMatchQueue(context.WithoutCancel(ctx)); err == nil && len(left) > 0 {
+		fmt.Printf(" (%d still queued)", len(left))
+	}
+	fmt.Println()
+	return nil
+}
+
+// listMatchQueue prints the files waiting for sync, oldest first.
+func listMatchQueue(ctx context.Context, store *storage.Storage) error {
+	queued, err := store.ListMatchQueue(ctx)
+	if err != nil {
+		return err
+	}
+	if len(queued) == 0 {
+		fmt.Println("No files waiting to be matched")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "QUEUED\tFILENAME")
+	for _, q := range queued {
+		fmt.Fprintf(w, "%s\t%s\n", q.QueuedAt.Local().Format(time.DateTime), q.Filename)
+	}
+	return w.Flush()
+}
+
+// printMatchQueue reports how many files wait for sync after an offline run.
+func printMatchQueue(store *storage.Storage) {
+	queued, err := store.ListMatchQueue(context.Background())
+	if err != nil {
+		slog.Warn("reading match queue failed", "error", err)
+		return
+	}
+	fmt.Printf("%d files are waiting to be matched; run `comic-parser sync` when online\n", len(queued))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/library"
+	"comic-parser/internal/media"
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/storage"
+)
+
+const tvUsage = "usage: comic-parser tv [flags] [filenames...]"
+
+// tvCommand implements `comic-parser tv`, which parses TV episode filenames
+// and matches them against TheTVDB, storing the results in the episodes
+// table next to the comics.
+func tvCommand(args []string) error {
+	fs := flag.NewFlagSet("tv", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	configFile := fs.String("config", "config.json", "Path to configuration file")
+	inputFile := fs.String("input", "", "Input file containing filenames (one per line)")
+	scanDir := fs.String("scan", "", "Directory to scan for video files")
+	parseOnly := fs.Bool("parse-only", false, "Only parse the filenames, without searching TheTVDB or storing anything")
+	jsonOutput := fs.Bool("json", false, "Print the results as JSON")
+	indent := addIndentFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), tvUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	filenames := fs.Args()
+	switch {
+	case *scanDir != "":
+		var err error
+		if filenames, err = library.Scan(*scanDir, library.ScanOptions{Recursive: true, Match: parser.IsVideoFile}); err != nil {
+			return fmt.Errorf("scanning %s: %w", *scanDir, err)
+		}
+	case *inputFile != "":
+		var err error
+		if filenames, err = loadFilenames(*inputFile); err != nil {
+			return fmt.Errorf("loading input file: %w", err)
+		}
+	}
+	if len(filenames) == 0 {
+		return errors.New(tvUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var results []*models.EpisodeResult
+	if *parseOnly {
+		for _, filename := range filenames {
+			result := parseEpisode(filename)
+			results = append(results, result)
+			if !*jsonOutput {
+				printEpisode(result)
+			}
+		}
+	} else {
+		var err error
+		if results, err = matchEpisodes(ctx, *configFile, *dbPath, filenames, !*jsonOutput); err != nil {
+			return err
+		}
+	}
+
+	if *jsonOutput {
+		return writeJSON(stdoutPath, results, *indent)
+	}
+	if *parseOnly {
+		fmt.Printf("\nParsed %d of %d files\n", countParsed(results), len(filenames))
+	} else {
+		fmt.Printf("\nMatched %d of %d files (saved to %s)\n", countMatched(results), len(filenames), *dbPath)
+	}
+	return nil
+}
+
+// matchEpisodes runs the files through the tv media type with the
+// processor's workers, storing the results in the database at dbPath. The
+// results are returned sorted by filename, and printed as they come when
+// print is set.
+func matchEpisodes(ctx context.Context, configFile, dbPath string, filenames []string, print bool) ([]*models.EpisodeResult, error) {
+	cfg, err := config.LoadConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	cfg.LoadFromEnv()
+	mt, err := media.New(media.TV, cfg, &http.Client{Timeout: 30 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := storage.Open(cfg.StorageBackend, dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	proc := processor.NewProcessor(cfg, nil, nil, nil, store)
+	defer proc.Close()
+
+	resultChan := make(chan media.Result, 100)
+	var results []*models.EpisodeResult
+	done := make(chan struct{})
+	go func() {
+		for r := range resultChan {
+			result := r.(*models.EpisodeResult)
+			results = append(results, result)
+			if print {
+				printEpisode(result)
+			}
+		}
+		close(done)
+	}()
+	proc.RunBatch(ctx, mt, filenames, resultChan)
+	close(resultChan)
+	<-done
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Filename < results[j].Filename })
+	return results, nil
+}
+
+// parseEpisode parses filename without looking it up.
+func parseEpisode(filename string) *models.EpisodeResult {
+	result := &models.EpisodeResult{
+		Filename:    filename,
+		Path:        library.LocalPath(filename),
+		ProcessedAt: time.Now(),
+	}
+	parsed, err := parser.ParseEpisode(filename)
+	if err != nil {
+		result.Error = fmt.Sprintf("parsing filename: %v", err)
+		result.ErrorCode = models.ErrorCodeParseFailed
+		return result
+	}
+	result.Parsed = parsed
+	return result
+}
+
+func printEpisode(result *models.EpisodeResult) {
+	switch {
+	case result.Episode != nil:
+		ep := result.Episode
+		fmt.Printf("match %s\n  -> %s S%02dE%02d %q (%s)\n", result.Filename, ep.SeriesName, ep.Season, ep.Number, ep.Name, result.Confidence)
+	case result.Error != "":
+		fmt.Printf("fail  %s: %s\n", result.Filename, result.Error)
+	default:
+		p := result.Parsed
+		switch {
+		case p.AirDate != "":
+			fmt.Printf("parse %s\n  -> %s %s\n", result.Filename, p.Series, p.AirDate)
+		case p.AbsoluteNumber > 0:
+			fmt.Printf("parse %s\n  -> %s #%d\n", result.Filename, p.Series, p.AbsoluteNumber)
+		default:
+			fmt.Printf("parse %s\n  -> %s S%02dE%02d\n", result.Filename, p.Series, p.Season, p.Episode)
+		}
+	}
+}
+
+func countMatched(results []*models.EpisodeResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Success {
+			n++
+		}
+	}
+	return n
+}
+
+func countParsed(results []*models.EpisodeResult) int {
+	n := 0
+	for _, r := range results {
+		if r.Parsed != nil {
+			n++
+		}
+	}
+	return n
+}
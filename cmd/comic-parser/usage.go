@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+const usageUsage = "usage: comic-parser usage [-db path] [-run id] [-json]"
+
+// usageCommand implements `comic-parser usage`, which reports recorded LLM
+// token usage and estimated cost per batch run and per model.
+func usageCommand(args []string) error {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	runID := fs.Int64("run", 0, "Only report the calls of this batch run")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	indent := addIndentFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usageUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		return errors.New(usageUsage)
+	}
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	var byRun []models.UsageSummary
+	if *runID == 0 {
+		if byRun, err = store.UsageByRun(ctx); err != nil {
+			return err
+		}
+	} else if _, err := store.GetBatchRun(ctx, *runID); err != nil {
+		return err
+	}
+	byModel, err := store.UsageByModel(ctx, *runID)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return writeJSON(stdoutPath, struct {
+			Runs   []models.UsageSummary `json:"runs,omitempty"`
+			Models []models.UsageSummary `json:"models"`
+		}{byRun, byModel}, *indent)
+	}
+
+	if len(byModel) == 0 {
+		fmt.Println("No LLM usage recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(byRun) > 0 {
+		fmt.Fprintln(w, "RUN\tMODE\tSOURCE\tCALLS\tINPUT\tOUTPUT\tCOST")
+		for _, u := range byRun {
+			run := "-"
+			if u.RunID != 0 {
+				run = strconv.FormatInt(u.RunID, 10)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t$%.4f\n",
+				run, u.Mode, u.InputSource, u.Calls, u.InputTokens, u.OutputTokens, u.Cost)
+		}
+		fmt.Fprintln(w)
+	}
+
+	var total models.UsageSummary
+	fmt.Fprintln(w, "PROVIDER\tMODEL\tKIND\tCALLS\tINPUT\tOUTPUT\tCOST")
+	for _, u := range byModel {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t$%.4f\n",
+			u.Provider, u.Model, u.Kind, u.Calls, u.InputTokens, u.OutputTokens, u.Cost)
+		total.Calls += u.Calls
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		total.Cost += u.Cost
+	}
+	fmt.Fprintf(w, "total\t\t\t%d\t%d\t%d\t$%.4f\n", total.Calls, total.InputTokens, total.OutputTokens, total.Cost)
+	return w.Flush()
+}
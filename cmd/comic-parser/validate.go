@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/parser"
+)
+
+const validateUsage = "usage: comic-parser validate -input filenames.txt [flags] | comic-parser validate [flags] <filename>..."
+
+// validateCommand implements `comic-parser validate`, which runs only the
+// regex parser over a list of filenames to predict how many of them a run
+// would send to the LLM, before any API is called.
+func validateCommand(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	inputFile := fs.String("input", "", "Input file containing filenames (one per line)")
+	configFile := fs.String("config", "config.json", "Path to configuration file (for the patterns file and LLM settings)")
+	show := fs.String("show", parser.PreflightUnparseable, "Comma-separated outcomes to list the files of: regex, llm, unparseable")
+	limit := fs.Int("limit", 20, "Most files to list per outcome (0 = all)")
+	asJSON := fs.Bool("json", false, "Print every result as JSON")
+	indent := addIndentFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), validateUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	filenames := fs.Args()
+	if *inputFile != "" {
+		if len(filenames) > 0 {
+			return errors.New(validateUsage)
+		}
+		var err error
+		if filenames, err = loadFilenames(*inputFile); err != nil {
+			return err
+		}
+	}
+	if len(filenames) == 0 {
+		return errors.New(validateUsage)
+	}
+	outcomes := splitList(*show)
+	for _, outcome := range outcomes {
+		if outcome != parser.PreflightRegex && outcome != parser.PreflightLLM && outcome != parser.PreflightUnparseable {
+			return fmt.Errorf("unknown outcome %q (must be regex, llm or unparseable)", outcome)
+		}
+	}
+
+	cfg, err := config.LoadConfig(*configFile)
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	patterns, err := loadPatterns(cfg)
+	if err != nil {
+		return err
+	}
+	p := parser.NewRegexParser(patterns...)
+
+	results := make([]parser.PreflightResult, len(filenames))
+	byOutcome := make(map[string][]parser.PreflightResult)
+	for i, filename := range filenames {
+		results[i] = parser.Preflight(context.Background(), p, filename)
+		byOutcome[results[i].Outcome] = append(byOutcome[results[i].Outcome], results[i])
+	}
+
+	if *asJSON {
+		return writeJSON(stdoutPath, results, *indent)
+	}
+
+	llmFiles := len(byOutcome[parser.PreflightLLM])
+	fmt.Printf("Checked %d filenames\n", len(filenames))
+	fmt.Printf("Regex parses:    %d\n", len(byOutcome[parser.PreflightRegex]))
+	fmt.Printf("Needs the LLM:   %d\n", llmFiles)
+	fmt.Printf("Unparseable:     %d\n", len(byOutcome[parser.PreflightUnparseable]))
+	if llmFiles > 0 && slices.Contains(splitList(cfg.ParserChain), "llm") {
+		printLLMPlan(cfg, llmFiles)
+	}
+
+	for _, outcome := range outcomes {
+		files := byOutcome[outcome]
+		if len(files) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", outcome)
+		for i, res := range files {
+			if *limit > 0 && i == *limit {
+				fmt.Printf("  ... %d more\n", len(files)-i)
+				break
+			}
+			if res.Reason != "" {
+				fmt.Printf("  %s: %s\n", res.Filename, res.Reason)
+			} else {
+				fmt.Printf("  %s\n", res.Filename)
+			}
+		}
+	}
+	return nil
+}
+
+// printLLMPlan reports how many parse requests files would take with the
+// config's LLM batch size, and how long the LLM rate limit spreads them
+// over.
+func printLLMPlan(cfg *config.Config, files int) {
+	size := max(cfg.LLMBatchSize, 1)
+	requests := (files + size - 1) / size
+	fmt.Printf("LLM parse requests: %d (llm_batch_size %d)\n", requests, size)
+	if cfg.RateLimitPerMin > 0 {
+		wait := time.Duration(requests) * time.Minute / time.Duration(cfg.RateLimitPerMin)
+		fmt.Printf("At %d requests/min that takes at least %s, before any match requests.\n", cfg.RateLimitPerMin, wait.Round(time.Second))
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"comic-parser/internal/library"
+	"comic-parser/internal/storage"
+)
+
+// dbVerifyCommand hashes the stored files again and reports the ones that
+// changed or went missing since they were processed.
+func dbVerifyCommand(args []string) error {
+	fs := flag.NewFlagSet("db verify", flag.ExitOnError)
+	dbPath := fs.String("db", defaultDBPath, "Database path")
+	fs.Parse(args)
+
+	store, err := storage.Open("", *dbPath)
+	if err != nil {
+		return fmt.Errorf("opening storage: %w", err)
+	}
+	defer store.Close()
+
+	hashed, err := store.ListFileHashes(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var changed, missing []string
+	for _, r := range hashed {
+		outcome, err := library.VerifyFile(r)
+		if err != nil {
+			return fmt.Errorf("verifying %s: %w", r.Filename, err)
+		}
+		switch outcome {
+		case library.FileChanged:
+			changed = append(changed, r.Path)
+		case library.FileMissing:
+			missing = append(missing, r.Path)
+		}
+	}
+
+	printList("Changed files", changed)
+	printList("Missing files", missing)
+	if len(changed)+len(missing) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("Verified %d files: %d unchanged, %d changed, %d missing\n",
+		len(hashed), len(hashed)-len(changed)-len(missing), len(changed), len(missing))
+	if len(changed)+len(missing) > 0 {
+		return errors.New("some stored files failed verification")
+	}
+	return nil
+}
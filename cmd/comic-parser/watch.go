@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+)
+
+const watchUsage = "usage: comic-parser watch [flags] <dir>"
+
+// watchCommand implements `comic-parser watch`, which matches comic
+// archives as they arrive in a directory and optionally files them into a
+// library layout.
+func watchCommand(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	pf := addPipelineFlags(fs)
+	interval := fs.Duration("interval", 5*time.Second, "How often to look for new archives")
+	settle := fs.Duration("settle", 10*time.Second, "How long an archive must stay unchanged before it is processed, so partially written files are skipped")
+	existing := fs.Bool("existing", false, "Also process the archives already in the directory at startup")
+	recursive := fs.Bool("recursive", true, "Watch subdirectories too")
+	include := fs.String("include", "", "Comma-separated glob patterns of files to process (e.g. \"*.cbz,Batman*\")")
+	exclude := fs.String("exclude", "", "Comma-separated glob patterns of files or directories to skip")
+	organizeRoot := fs.String("organize", "", "Move matched archives into this library root, as organize does")
+	template := fs.String("template", "", "Path template relative to the -organize root (default from config)")
+	allMatches := fs.Bool("all-matches", false, "Move medium and low confidence matches too, not just high confidence ones")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), watchUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New(watchUsage)
+	}
+	dir := fs.Arg(0)
+	if info, err := os.Stat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if *interval <= 0 {
+		return errors.New("-interval must be positive")
+	}
+
+	pl, err := pf.open()
+	if err != nil {
+		return err
+	}
+	defer pl.Close()
+
+	var tmpl *library.Template
+	if *organizeRoot != "" {
+		if *template == "" {
+			*template = pl.cfg.OrganizeTemplate
+		}
+		if tmpl, err = library.ParseTemplate(*template); err != nil {
+			return err
+		}
+	}
+
+	watcher := library.NewWatcher(dir, library.ScanOptions{
+		Recursive: *recursive,
+		Include:   splitList(*include),
+		Exclude:   splitList(*exclude),
+	}, *settle)
+	if !*existing {
+		if err := watcher.SkipExisting(); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	pl.reloadOnSIGHUP(ctx, pf)
+
+	w := &watchHandler{
+		pipeline:   pl,
+		watcher:    watcher,
+		root:       *organizeRoot,
+		tmpl:       tmpl,
+		allMatches: *allMatches,
+	}
+	slog.Info("watching for comic archives", "dir", dir, "interval", *interval, "settle", *settle, "organize", *organizeRoot)
+	if err := watcher.Watch(ctx, *interval, func(path string) error { return w.handle(ctx, path) }); err != nil {
+		return err
+	}
+	slog.Info("stopped watching", "processed", w.processed, "moved", w.moved)
+	return nil
+}
+
+// watchHandler processes the archives reported by a watch.
+type watchHandler struct {
+	*pipeline
+	watcher    *library.Watcher
+	root       string // library root to organize into, or "" to leave files in place
+	tmpl       *library.Template
+	allMatches bool
+
+	// operation groups the moves of this watch, so `organize -undo` moves
+	// them all back. It is allocated with the first move.
+	operation int64
+
+	processed, moved int
+}
+
+// handle matches one archive, stores the result and moves the file when it
+// matched. Files on the ignore list are skipped. Only an exhausted LLM budget
+// or a storage failure stops the watch.
+func (w *watchHandler) handle(ctx context.Context, path string) error {
+	if w.proc.Ignored(path) {
+		slog.Info("ignoring file", "file", path)
+		return nil
+	}
+	result, err := w.proc.ProcessFile(ctx, path)
+	if err != nil {
+		return fmt.Errorf("stopping the watch: %w", err)
+	}
+	if ctx.Err() != nil {
+		// Interrupted: the failure says nothing about the file
+		return nil
+	}
+	w.processed++
+
+	// In review mode the processor already stored matches or queued them
+	queued := w.cfg.ReviewQueue && result.Match != nil
+	if !queued {
+		if err := w.store.SaveResult(ctx, result); err != nil {
+			return fmt.Errorf("saving result for %s: %w", path, err)
+		}
+	}
+
+	switch {
+	case !result.Success:
+		slog.Warn("processing failed", "file", path, "error", result.Error)
+		return nil
+	case result.Match.SelectedIssue == nil:
+		slog.Info("no match found", "file", path, "reasoning", result.Match.Reasoning)
+		return nil
+	}
+
+	issue := result.Match.SelectedIssue
+	confidence := result.Match.MatchConfidence
+	slog.Info("matched", "file", path, "series", issue.Volume.Name, "issue", issue.IssueNumber, "confidence", confidence)
+
+	if w.tmpl == nil {
+		return nil
+	}
+	if confidence != "high" && (!w.allMatches || w.cfg.ReviewQueue) {
+		slog.Info("not moving a match that needs review", "file", path, "confidence", confidence)
+		return nil
+	}
+	return w.organize(ctx, result)
+}
+
+// organize moves a matched archive into the library and records the move.
+func (w *watchHandler) organize(ctx context.Context, result *models.ProcessingResult) error {
+	item := library.OrganizeItem{Filename: result.Filename, Path: result.Path, Issue: result.Match.SelectedIssue}
+	moves, held, err := library.PlanMoves(w.root, w.tmpl, []library.OrganizeItem{item})
+	if err != nil {
+		return err
+	}
+	for _, m := range held {
+		slog.Warn("not moving", "file", m.From, "reason", m.Reason)
+	}
+
+	for _, m := range moves {
+		if w.operation == 0 {
+			if w.operation, err = w.store.NextMoveOperation(ctx); err != nil {
+				return err
+			}
+		}
+		if err := library.MoveFile(m.From, m.To); err != nil {
+			slog.Warn("moving failed", "file", m.From, "error", err)
+			continue
+		}
+		if err := recordMove(ctx, w.store, w.operation, m); err != nil {
+			return err
+		}
+		// The library may be inside the watched directory
+		w.watcher.Ignore(m.To)
+		w.moved++
+		slog.Info("moved", "file", m.From, "to", m.To)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package archive inspects comic archives (CBZ, CBR, CBT) and reads the
+// ComicInfo.xml metadata some of them carry.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"comic-parser/internal/models"
+
+	"github.com/nwaples/rardecode/v2"
+)
+
+// ComicInfoName is the conventional name of the metadata file in an archive.
+const ComicInfoName = "ComicInfo.xml"
+
+// maxComicInfoSize caps how much of a ComicInfo.xml entry is read
+const maxComicInfoSize = 1 << 20
+
+var (
+	// ErrNoComicInfo is returned when an archive has no ComicInfo.xml.
+	ErrNoComicInfo = errors.New("archive: no ComicInfo.xml")
+	// ErrUnsupported is returned for archive formats that can't be read.
+	ErrUnsupported = errors.New("archive: unsupported format")
+)
+
+// ComicInfo is the subset of the ComicRack ComicInfo.xml schema used for
+// identification and tagging.
+type ComicInfo struct {
+	XMLName     xml.Name `xml:"ComicInfo"`
+	Title       string   `xml:"Title,omitempty"`
+	Series      string   `xml:"Series,omitempty"`
+	Number      string   `xml:"Number,omitempty"`
+	Count       int      `xml:"Count,omitempty"`
+	Volume      int      `xml:"Volume,omitempty"`
+	Summary     string   `xml:"Summary,omitempty"`
+	Notes       string   `xml:"Notes,omitempty"`
+	Year        int      `xml:"Year,omitempty"`
+	Month       int      `xml:"Month,omitempty"`
+	Day         int      `xml:"Day,omitempty"`
+	Writer      string   `xml:"Writer,omitempty"`
+	Penciller   string   `xml:"Penciller,omitempty"`
+	Inker       string   `xml:"Inker,omitempty"`
+	Colorist    string   `xml:"Colorist,omitempty"`
+	Letterer    string   `xml:"Letterer,omitempty"`
+	CoverArtist string   `xml:"CoverArtist,omitempty"`
+	Editor      string   `xml:"Editor,omitempty"`
+	Publisher   string   `xml:"Publisher,omitempty"`
+	Web         string   `xml:"Web,omitempty"`
+	PageCount   int      `xml:"PageCount,omitempty"`
+
+	// Extra keeps elements not modeled above (Pages, Genre, ...) so that
+	// rewriting the file doesn't lose them.
+	Extra []rawElement `xml:",any"`
+}
+
+type rawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// ReadComicInfo returns the ComicInfo.xml embedded in the archive at path.
+// It returns ErrNoComicInfo when there is none and ErrUnsupported for
+// formats other than CBZ, CBR and CBT.
+func ReadComicInfo(path string) (*ComicInfo, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".cbz", ".zip":
+		data, err = readZip(path)
+	case ".cbr", ".rar":
+		data, err = readRar(path)
+	case ".cbt", ".tar":
+		data, err = readTar(path)
+	default:
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), ErrUnsupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseComicInfo(data)
+}
+
+// ParseComicInfo decodes a ComicInfo.xml document.
+func ParseComicInfo(data []byte) (*ComicInfo, error) {
+	var ci ComicInfo
+	if err := xml.Unmarshal(data, &ci); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", ComicInfoName, err)
+	}
+	return &ci, nil
+}
+
+// isComicInfo reports whether an archive entry is the metadata file. Only
+// the archive root is considered, matching ComicRack.
+func isComicInfo(name string) bool {
+	name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+	return path.Dir(name) == "." && strings.EqualFold(name, ComicInfoName)
+}
+
+func readZip(path string) ([]byte, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath.Base(path), err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if !isComicInfo(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", ComicInfoName, err)
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxComicInfoSize))
+	}
+	return nil, ErrNoComicInfo
+}
+
+func readRar(path string) ([]byte, error) {
+	rr, err := rardecode.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", filepath.Base(path), err)
+	}
+	defer rr.Close()
+
+	for {
+		h, err := rr.Next()
+		if err == io.EOF {
+			return nil, ErrNoComicInfo
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+		}
+		if !h.IsDir && isComicInfo(h.Name) {
+			return io.ReadAll(io.LimitReader(rr, maxComicInfoSize))
+		}
+	}
+}
+
+func readTar(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil, ErrNoComicInfo
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+		}
+		if h.Typeflag == tar.TypeReg && isComicInfo(h.Name) {
+			return io.ReadAll(io.LimitReader(tr, maxComicInfoSize))
+		}
+	}
+}
+
+// ParsedFilename converts the metadata into a high-confidence parse of
+// filename. It returns nil when the metadata lacks a series or issue number,
+// since the filename is then a better source.
+func (ci *ComicInfo) ParsedFilename(filename string) *models.ParsedFilename {
+	series := strings.TrimSpace(ci.Series)
+	number := strings.TrimSpace(ci.Number)
+	if series == "" || number == "" {
+		return nil
+	}
+
+	parsed := &models.ParsedFilename{
+		OriginalFilename: filename,
+		Title:            series,
+		IssueNumber:      number,
+		Publisher:        strings.TrimSpace(ci.Publisher),
+		Confidence:       "high",
+		Notes:            "Parsed from embedded " + ComicInfoName,
+	}
+	if ci.Year > 0 {
+		parsed.Year = strconv.Itoa(ci.Year)
+	}
+	// Volume holds either a volume number or the series' start year
+	if ci.Volume > 0 && ci.Volume < 1000 {
+		parsed.VolumeNumber = strconv.Itoa(ci.Volume)
+	}
+	return parsed
+}
@@ -0,0 +1,247 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"comic-parser/internal/models"
+)
+
+const sampleComicInfo = `<?xml version="1.0"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Series>Saga</Series>
+  <Number>1</Number>
+  <Volume>2012</Volume>
+  <Year>2012</Year>
+  <Month>3</Month>
+  <Publisher>Image</Publisher>
+  <Writer>Brian K. Vaughan</Writer>
+</ComicInfo>`
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+}
+
+func TestReadComicInfo(t *testing.T) {
+	dir := t.TempDir()
+
+	cbz := filepath.Join(dir, "Saga 001.cbz")
+	writeZip(t, cbz, map[string]string{"001.jpg": "img", "comicinfo.xml": sampleComicInfo})
+
+	ci, err := ReadComicInfo(cbz)
+	if err != nil {
+		t.Fatalf("ReadComicInfo failed: %v", err)
+	}
+	if ci.Series != "Saga" || ci.Number != "1" || ci.Year != 2012 || ci.Writer != "Brian K. Vaughan" {
+		t.Errorf("Unexpected ComicInfo: %+v", ci)
+	}
+
+	cbt := filepath.Join(dir, "Saga 001.cbt")
+	f, err := os.Create(cbt)
+	if err != nil {
+		t.Fatalf("Failed to create tar: %v", err)
+	}
+	tw := tar.NewWriter(f)
+	tw.WriteHeader(&tar.Header{Name: "ComicInfo.xml", Mode: 0644, Size: int64(len(sampleComicInfo))})
+	tw.Write([]byte(sampleComicInfo))
+	tw.Close()
+	f.Close()
+	if ci, err := ReadComicInfo(cbt); err != nil || ci.Series != "Saga" {
+		t.Errorf("ReadComicInfo(cbt) = %+v, %v", ci, err)
+	}
+
+	// Metadata nested in a folder is not the archive's own
+	nested := filepath.Join(dir, "nested.cbz")
+	writeZip(t, nested, map[string]string{"extras/ComicInfo.xml": sampleComicInfo})
+	if _, err := ReadComicInfo(nested); !errors.Is(err, ErrNoComicInfo) {
+		t.Errorf("Expected ErrNoComicInfo, got %v", err)
+	}
+
+	if _, err := ReadComicInfo(filepath.Join(dir, "book.cb7")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestComicInfoParsedFilename(t *testing.T) {
+	ci, err := ParseComicInfo([]byte(sampleComicInfo))
+	if err != nil {
+		t.Fatalf("ParseComicInfo failed: %v", err)
+	}
+
+	parsed := ci.ParsedFilename("Saga 001.cbz")
+	if parsed == nil {
+		t.Fatal("Expected a parse")
+	}
+	if parsed.Title != "Saga" || parsed.IssueNumber != "1" || parsed.Year != "2012" || parsed.Publisher != "Image" {
+		t.Errorf("Unexpected parse: %+v", parsed)
+	}
+	if parsed.Confidence != "high" || parsed.VolumeNumber != "" {
+		t.Errorf("Expected high confidence and no volume number, got %+v", parsed)
+	}
+
+	if (&ComicInfo{Series: "Saga"}).ParsedFilename("x.cbz") != nil {
+		t.Error("Expected nil parse without an issue number")
+	}
+}
+
+func TestWriteComicInfo(t *testing.T) {
+	dir := t.TempDir()
+	cbz := filepath.Join(dir, "Saga 001.cbz")
+	existing := `<ComicInfo><Series>Old</Series><Genre>Sci-Fi</Genre><Pages><Page Image="0" Type="FrontCover"/></Pages></ComicInfo>`
+	writeZip(t, cbz, map[string]string{"001.jpg": "img", "ComicInfo.xml": existing})
+
+	ci, err := ReadComicInfo(cbz)
+	if err != nil {
+		t.Fatalf("ReadComicInfo failed: %v", err)
+	}
+	ci.ApplyIssue(&models.ComicVineIssue{
+		ID:            200,
+		Name:          "Chapter One",
+		IssueNumber:   "1",
+		CoverDate:     models.Date{Year: 2012, Month: 3},
+		SiteDetailURL: "https://comicvine.gamespot.com/saga-1/4000-200/",
+		Volume:        models.VolumeRef{Name: "Saga", Publisher: "Image", StartYear: "2012", IssueCount: 66},
+		Credits: []models.Credit{
+			{Name: "Brian K. Vaughan", Role: "writer"},
+			{Name: "Fiona Staples", Role: "artist, cover"},
+		},
+	})
+
+	if err := WriteComicInfo(cbz, ci, WriteOptions{Backup: true}); err != nil {
+		t.Fatalf("WriteComicInfo failed: %v", err)
+	}
+
+	got, err := ReadComicInfo(cbz)
+	if err != nil {
+		t.Fatalf("ReadComicInfo after write failed: %v", err)
+	}
+	if got.Series != "Saga" || got.Number != "1" || got.Year != 2012 || got.Month != 3 || got.Volume != 2012 || got.Count != 66 {
+		t.Errorf("Unexpected issue fields: %+v", got)
+	}
+	if got.Writer != "Brian K. Vaughan" || got.Penciller != "Fiona Staples" || got.Inker != "Fiona Staples" || got.CoverArtist != "Fiona Staples" {
+		t.Errorf("Unexpected credits: %+v", got)
+	}
+	if !strings.Contains(got.Notes, "[Issue ID 200]") {
+		t.Errorf("Expected ComicVine ID in notes, got %q", got.Notes)
+	}
+	if len(got.Extra) != 2 || got.Extra[0].XMLName.Local != "Genre" || !strings.Contains(got.Extra[1].Inner, "FrontCover") {
+		t.Errorf("Expected unmodeled elements to be kept, got %+v", got.Extra)
+	}
+
+	zr, err := zip.OpenReader(cbz)
+	if err != nil {
+		t.Fatalf("Failed to open rewritten archive: %v", err)
+	}
+	defer zr.Close()
+	if len(zr.File) != 2 {
+		t.Errorf("Expected 2 entries after rewrite, got %d", len(zr.File))
+	}
+
+	// The backup keeps its original contents and is no longer a .cbz
+	backup, err := readZip(cbz + BackupSuffix)
+	if err != nil || string(backup) != existing {
+		t.Errorf("Expected untouched backup, got %q, %v", backup, err)
+	}
+
+	if err := WriteComicInfo(filepath.Join(dir, "Saga 001.cbr"), ci, WriteOptions{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for CBR, got %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	check := func(path string) *models.ArchiveCheck {
+		t.Helper()
+		c, err := Check(path)
+		if err != nil {
+			t.Fatalf("Check(%s) failed: %v", filepath.Base(path), err)
+		}
+		return c
+	}
+
+	healthy := filepath.Join(dir, "healthy.cbz")
+	writeZip(t, healthy, map[string]string{
+		"p01.jpg":            "page one",
+		"p02.PNG":            "page two",
+		ComicInfoName:        sampleComicInfo,
+		"__MACOSX/._p01.jpg": "fork",
+	})
+	if c := check(healthy); c.Health != models.ArchiveHealthy || c.PageCount != 2 || len(c.Problems) != 0 {
+		t.Errorf("Expected a healthy archive of 2 pages, got %+v", c)
+	}
+
+	zeroByte := filepath.Join(dir, "zero.cbz")
+	writeZip(t, zeroByte, map[string]string{"p01.jpg": "page one", "p02.jpg": ""})
+	if c := check(zeroByte); c.Health != models.ArchiveDamaged || c.PageCount != 2 || len(c.Problems) != 1 || !strings.Contains(c.Problems[0], "p02.jpg") {
+		t.Errorf("Expected the zero-byte page reported, got %+v", c)
+	}
+
+	truncated := filepath.Join(dir, "truncated.cbz")
+	writeZip(t, truncated, map[string]string{"p01.jpg": strings.Repeat("page", 100)})
+	info, _ := os.Stat(truncated)
+	os.Truncate(truncated, info.Size()/2)
+	if c := check(truncated); c.Health != models.ArchiveDamaged || c.PageCount != 0 {
+		t.Errorf("Expected a truncated archive to be damaged, got %+v", c)
+	}
+
+	// A stored entry with a flipped byte fails its checksum
+	corrupt := filepath.Join(dir, "corrupt.cbz")
+	f, err := os.Create(corrupt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "p01.jpg", Method: zip.Store})
+	w.Write([]byte("PAGEDATA"))
+	zw.Close()
+	f.Close()
+	data, _ := os.ReadFile(corrupt)
+	i := strings.Index(string(data), "PAGEDATA")
+	data[i] = 'X'
+	os.WriteFile(corrupt, data, 0644)
+	if c := check(corrupt); c.Health != models.ArchiveDamaged || c.PageCount != 1 || len(c.Problems) != 1 {
+		t.Errorf("Expected a checksum error, got %+v", c)
+	}
+
+	tarPath := filepath.Join(dir, "issue.cbt")
+	tf, _ := os.Create(tarPath)
+	tw := tar.NewWriter(tf)
+	tw.WriteHeader(&tar.Header{Name: "p01.jpg", Mode: 0644, Size: 4, Typeflag: tar.TypeReg})
+	tw.Write([]byte("page"))
+	tw.Close()
+	tf.Close()
+	if c := check(tarPath); c.Health != models.ArchiveHealthy || c.PageCount != 1 {
+		t.Errorf("Expected a healthy tar, got %+v", c)
+	}
+
+	empty := filepath.Join(dir, "empty.cbr")
+	os.WriteFile(empty, nil, 0644)
+	if c := check(empty); c.Health != models.ArchiveDamaged || c.Problems[0] != "empty file" {
+		t.Errorf("Expected an empty file to be damaged, got %+v", c)
+	}
+
+	if _, err := Check(filepath.Join(dir, "issue.cb7")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported for cb7, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+
+	"github.com/nwaples/rardecode/v2"
+)
+
+// imageExtensions lists the entry extensions counted as pages
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+	".bmp":  true,
+	".avif": true,
+	".jxl":  true,
+}
+
+// entryFunc is called with each file entry of an archive and a reader of
+// its contents. A declared size of -1 means it's unknown.
+type entryFunc func(name string, size int64, r io.Reader)
+
+// Check reads every entry of the archive at path, so that checksum errors
+// and truncation surface, and reports its image pages and its problems:
+// entries that can't be read, zero-byte entries, an archive that can't be
+// opened or one without pages. It returns an error only when the file
+// can't be read at all, and ErrUnsupported for formats other than CBZ, CBR
+// and CBT.
+func Check(path string) (*models.ArchiveCheck, error) {
+	var walk func(string, entryFunc) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".cbz", ".zip":
+		walk = walkZip
+	case ".cbr", ".rar":
+		walk = walkRar
+	case ".cbt", ".tar":
+		walk = walkTar
+	default:
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), ErrUnsupported)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &models.ArchiveCheck{Path: path, CheckedAt: time.Now()}
+	if info.Size() == 0 {
+		check.Problems = append(check.Problems, "empty file")
+	} else {
+		err = walk(path, func(name string, size int64, r io.Reader) {
+			if isPage(name) {
+				check.PageCount++
+			}
+			n, err := io.Copy(io.Discard, r)
+			switch {
+			case err != nil:
+				check.Problems = append(check.Problems, fmt.Sprintf("%s: %v", name, err))
+			case n == 0:
+				check.Problems = append(check.Problems, name+": zero-byte entry")
+			case size >= 0 && n != size:
+				check.Problems = append(check.Problems, fmt.Sprintf("%s: read %d of %d bytes", name, n, size))
+			}
+		})
+		if err != nil {
+			check.Problems = append(check.Problems, err.Error())
+		} else if check.PageCount == 0 {
+			check.Problems = append(check.Problems, "no image pages")
+		}
+	}
+
+	check.Health = models.ArchiveHealthy
+	if len(check.Problems) > 0 {
+		check.Health = models.ArchiveDamaged
+	}
+	return check, nil
+}
+
+// isPage reports whether an archive entry is an image page. macOS resource
+// forks and hidden files are not.
+func isPage(name string) bool {
+	name = filepath.ToSlash(name)
+	base := path.Base(name)
+	if strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") {
+		return false
+	}
+	return imageExtensions[strings.ToLower(path.Ext(base))]
+}
+
+func walkZip(path string, fn entryFunc) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			fn(f.Name, int64(f.UncompressedSize64), errReader{err})
+			continue
+		}
+		fn(f.Name, int64(f.UncompressedSize64), rc)
+		rc.Close()
+	}
+	return nil
+}
+
+func walkRar(path string, fn entryFunc) error {
+	rr, err := rardecode.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("opening archive: %w", err)
+	}
+	defer rr.Close()
+
+	for {
+		h, err := rr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		if h.IsDir {
+			continue
+		}
+		size := h.UnPackedSize
+		if h.UnKnownSize {
+			size = -1
+		}
+		fn(h.Name, size, rr)
+	}
+}
+
+func walkTar(path string, fn entryFunc) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			fn(h.Name, h.Size, tr)
+		}
+	}
+}
+
+// errReader is an entry whose contents can't be read
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,197 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// BackupSuffix is appended to the original archive's name when
+// WriteOptions.Backup is set.
+const BackupSuffix = ".bak"
+
+// WriteOptions controls WriteComicInfo.
+type WriteOptions struct {
+	// Backup keeps the original archive alongside the updated one.
+	Backup bool
+}
+
+// ApplyIssue fills ci from a matched ComicVine issue. Fields ComicVine has no
+// value for, and elements this package doesn't model, are left as they are.
+// Credits replace existing ones only when the issue has credits.
+func (ci *ComicInfo) ApplyIssue(issue *models.ComicVineIssue) {
+	ci.Series = issue.Volume.Name
+	ci.Number = issue.IssueNumber
+	if issue.Name != "" {
+		ci.Title = issue.Name
+	}
+	if !issue.CoverDate.IsZero() {
+		ci.Year, ci.Month, ci.Day = issue.CoverDate.Year, issue.CoverDate.Month, issue.CoverDate.Day
+	}
+	if issue.Volume.Publisher != "" {
+		ci.Publisher = issue.Volume.Publisher
+	}
+	if issue.Volume.IssueCount > 0 {
+		ci.Count = issue.Volume.IssueCount
+	}
+	if year, ok := models.ParseYear(issue.Volume.StartYear); ok {
+		ci.Volume = year
+	}
+	if issue.SiteDetailURL != "" {
+		ci.Web = issue.SiteDetailURL
+	}
+	ci.Notes = fmt.Sprintf("Tagged by comic-parser using ComicVine [Issue ID %d]", issue.ID)
+
+	if len(issue.Credits) > 0 {
+		ci.applyCredits(issue.Credits)
+	}
+}
+
+func (ci *ComicInfo) applyCredits(credits []models.Credit) {
+	names := make(map[*string][]string)
+	for _, credit := range credits {
+		for _, role := range strings.Split(credit.Role, ",") {
+			for _, field := range ci.creditFields(strings.ToLower(strings.TrimSpace(role))) {
+				if !slices.Contains(names[field], credit.Name) {
+					names[field] = append(names[field], credit.Name)
+				}
+			}
+		}
+	}
+
+	for _, field := range []*string{&ci.Writer, &ci.Penciller, &ci.Inker, &ci.Colorist, &ci.Letterer, &ci.CoverArtist, &ci.Editor} {
+		*field = strings.Join(names[field], ", ")
+	}
+}
+
+// creditFields returns the fields a ComicVine credit role is listed under.
+func (ci *ComicInfo) creditFields(role string) []*string {
+	switch role {
+	case "writer":
+		return []*string{&ci.Writer}
+	case "penciler", "penciller":
+		return []*string{&ci.Penciller}
+	case "artist":
+		return []*string{&ci.Penciller, &ci.Inker}
+	case "inker":
+		return []*string{&ci.Inker}
+	case "colorist":
+		return []*string{&ci.Colorist}
+	case "letterer":
+		return []*string{&ci.Letterer}
+	case "cover":
+		return []*string{&ci.CoverArtist}
+	case "editor":
+		return []*string{&ci.Editor}
+	}
+	return nil
+}
+
+// MarshalComicInfo encodes ci as an indented ComicInfo.xml document.
+func MarshalComicInfo(ci *ComicInfo) ([]byte, error) {
+	data, err := xml.MarshalIndent(ci, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", ComicInfoName, err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// Writable reports whether WriteComicInfo supports the archive format of path.
+func Writable(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".cbz", ".zip":
+		return true
+	}
+	return false
+}
+
+// WriteComicInfo stores ci as the ComicInfo.xml of the CBZ at path, replacing
+// any existing one. Other entries are copied unchanged. The archive is
+// rewritten to a temporary file and renamed into place, so a failure leaves
+// the original intact. Only CBZ archives can be written; other formats return
+// ErrUnsupported.
+func WriteComicInfo(path string, ci *ComicInfo, opts WriteOptions) error {
+	if !Writable(path) {
+		return fmt.Errorf("%s: %w", filepath.Base(path), ErrUnsupported)
+	}
+
+	data, err := MarshalComicInfo(ci)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".comicinfo-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temporary archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := rewriteZip(path, tmp, data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("writing %s: %w", filepath.Base(path), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing %s: %w", filepath.Base(path), err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if opts.Backup {
+		if err := os.Rename(path, path+BackupSuffix); err != nil {
+			return fmt.Errorf("backing up %s: %w", filepath.Base(path), err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replacing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// rewriteZip copies the archive at path to out without its ComicInfo.xml,
+// then appends comicInfo as the new one.
+func rewriteZip(path string, out *os.File, comicInfo []byte) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", filepath.Base(path), err)
+	}
+	defer zr.Close()
+
+	zw := zip.NewWriter(out)
+	for _, f := range zr.File {
+		if isComicInfo(f.Name) {
+			continue
+		}
+		if err := zw.Copy(f); err != nil {
+			return fmt.Errorf("copying %s: %w", f.Name, err)
+		}
+	}
+
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     ComicInfoName,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("adding %s: %w", ComicInfoName, err)
+	}
+	if _, err := w.Write(comicInfo); err != nil {
+		return fmt.Errorf("adding %s: %w", ComicInfoName, err)
+	}
+	return zw.Close()
+}
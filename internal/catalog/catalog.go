@@ -0,0 +1,106 @@
+// Package catalog renders the matched comics of the library as documents
+// other tools read: a simple XML listing, and OPDS catalog feeds for
+// self-hosted comic readers.
+package catalog
+
+import (
+	"cmp"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// Library is the root of the XML listing.
+type Library struct {
+	XMLName   xml.Name  `xml:"library"`
+	Generated time.Time `xml:"generated,attr"`
+	Comics    []Comic   `xml:"comic"`
+}
+
+// Comic is a matched file of the XML listing.
+type Comic struct {
+	Filename    string `xml:"filename"`
+	Path        string `xml:"path,omitempty"`
+	Series      string `xml:"series"`
+	VolumeID    int    `xml:"series_id,attr,omitempty"`
+	StartYear   string `xml:"start_year,omitempty"`
+	Issue       string `xml:"issue"`
+	Title       string `xml:"title,omitempty"`
+	Publisher   string `xml:"publisher,omitempty"`
+	CoverDate   string `xml:"cover_date,omitempty"`
+	Language    string `xml:"language,omitempty"`
+	ComicVineID int    `xml:"comicvine_id,attr,omitempty"`
+	URL         string `xml:"url,omitempty"`
+	Confidence  string `xml:"confidence,omitempty"`
+}
+
+// NewLibrary lists the matched results, sorted by series and cover date.
+// Results without a selected issue are left out.
+func NewLibrary(results []*models.ProcessingResult, generated time.Time) *Library {
+	lib := &Library{Generated: generated.UTC()}
+	for _, result := range sorted(results) {
+		issue := result.Match.SelectedIssue
+		comic := Comic{
+			Filename:    result.Filename,
+			Path:        result.Path,
+			Series:      issue.Volume.Name,
+			VolumeID:    issue.Volume.ID,
+			StartYear:   issue.Volume.StartYear,
+			Issue:       issue.IssueNumber,
+			Title:       issue.Name,
+			Publisher:   issue.Volume.Publisher,
+			Language:    result.Match.ParsedInfo.Language,
+			ComicVineID: issue.ID,
+			URL:         issue.SiteDetailURL,
+			Confidence:  result.Match.MatchConfidence,
+		}
+		if !issue.CoverDate.IsZero() {
+			comic.CoverDate = issue.CoverDate.String()
+		}
+		lib.Comics = append(lib.Comics, comic)
+	}
+	return lib
+}
+
+// Write writes the listing as an indented XML document.
+func (l *Library) Write(w io.Writer) error {
+	return writeXML(w, l)
+}
+
+// sorted returns the results that have a selected issue, by series, volume,
+// cover date and filename.
+func sorted(results []*models.ProcessingResult) []*models.ProcessingResult {
+	var matched []*models.ProcessingResult
+	for _, result := range results {
+		if result.Match != nil && result.Match.SelectedIssue != nil {
+			matched = append(matched, result)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b *models.ProcessingResult) int {
+		ia, ib := a.Match.SelectedIssue, b.Match.SelectedIssue
+		return cmp.Or(
+			strings.Compare(strings.ToLower(ia.Volume.Name), strings.ToLower(ib.Volume.Name)),
+			cmp.Compare(ia.Volume.ID, ib.Volume.ID),
+			ia.CoverDate.Compare(ib.CoverDate),
+			strings.Compare(a.Filename, b.Filename),
+		)
+	})
+	return matched
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+func testResults() []*models.ProcessingResult {
+	processed := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	result := func(filename, series string, volumeID, issueID int, issue string, year int) *models.ProcessingResult {
+		return &models.ProcessingResult{
+			Filename:    filename,
+			Success:     true,
+			ProcessedAt: processed.Add(time.Duration(issueID) * time.Hour),
+			Match: &models.MatchResult{
+				MatchConfidence: "high",
+				ComicVineID:     issueID,
+				SelectedIssue: &models.ComicVineIssue{
+					ID:          issueID,
+					IssueNumber: issue,
+					CoverDate:   models.Date{Year: year, Month: 3},
+					Volume:      models.VolumeRef{ID: volumeID, Name: series, Publisher: "Image", StartYear: "2012"},
+				},
+			},
+		}
+	}
+	return []*models.ProcessingResult{
+		result("Saga 002.cbr", "Saga", 1, 2, "2", 2012),
+		result("Saga 001.cbz", "Saga", 1, 1, "1", 2012),
+		result("Batman 050.cbz", "Batman", 2, 3, "50", 2018),
+		{Filename: "Unmatched.cbz", Error: "no match"},
+	}
+}
+
+func TestNewLibrary(t *testing.T) {
+	lib := NewLibrary(testResults(), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	var got []string
+	for _, c := range lib.Comics {
+		got = append(got, c.Filename)
+	}
+	if want := "Batman 050.cbz,Saga 001.cbz,Saga 002.cbr"; strings.Join(got, ",") != want {
+		t.Errorf("comics = %q, want %s", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := lib.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for _, want := range []string{
+		`<library generated="2024-02-01T00:00:00Z">`,
+		`<comic series_id="1" comicvine_id="1">`,
+		`<series>Saga</series>`,
+		`<cover_date>2012-03</cover_date>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("XML is missing %s:\n%s", want, buf.String())
+		}
+	}
+	var decoded Library
+	if err := xml.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded.Comics) != 3 {
+		t.Errorf("Unmarshal = %d comics, %v", len(decoded.Comics), err)
+	}
+}
+
+func TestSeriesFeed(t *testing.T) {
+	f := NewSeriesFeed("urn:test", "Library", "/opds", testResults(), Links{
+		Start:  "/opds",
+		Series: func(id int) string { return "/opds/series/" + strconv.Itoa(id) },
+	})
+	if len(f.Entries) != 2 {
+		t.Fatalf("entries = %+v, want Batman and Saga", f.Entries)
+	}
+	saga := f.Entries[1]
+	if saga.Title != "Saga (2012)" || saga.ID != "urn:comicvine:volume:1" || saga.Updated != "2024-01-15T12:30:00Z" {
+		t.Errorf("Saga entry = %+v", saga)
+	}
+	if len(saga.Links) != 1 || saga.Links[0].Href != "/opds/series/1" || saga.Links[0].Type != AcquisitionType {
+		t.Errorf("Saga links = %+v", saga.Links)
+	}
+	if f.Updated != "2024-01-15T13:30:00Z" {
+		t.Errorf("feed updated = %s", f.Updated)
+	}
+}
+
+func TestAcquisitionFeed(t *testing.T) {
+	f := NewAcquisitionFeed("urn:test", "Saga", "", testResults()[:2], Links{
+		File: func(r *models.ProcessingResult) string { return "/files/" + r.Filename },
+		Cover: func(r *models.ProcessingResult) string {
+			if r.Match.ComicVineID == 2 {
+				return ""
+			}
+			return "/covers/1"
+		},
+	})
+	if len(f.Entries) != 2 || f.Entries[0].Title != "Saga #1" {
+		t.Fatalf("entries = %+v", f.Entries)
+	}
+	first, second := f.Entries[0], f.Entries[1]
+	if len(first.Links) != 3 || first.Links[0].Rel != relAcquisition || first.Links[0].Type != "application/vnd.comicbook+zip" {
+		t.Errorf("first links = %+v", first.Links)
+	}
+	if len(second.Links) != 1 || second.Links[0].Type != "application/vnd.comicbook-rar" {
+		t.Errorf("second links = %+v, want only the acquisition link", second.Links)
+	}
+	if first.ID != "urn:xander:file:Saga%20001.cbz" || first.Issued != "2012-03" {
+		t.Errorf("first entry = %+v", first)
+	}
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/terms/" xmlns:opds="http://opds-spec.org/2010/catalog">`,
+		`<dc:publisher>Image</dc:publisher>`,
+		`rel="http://opds-spec.org/image/thumbnail"`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("feed is missing %s:\n%s", want, buf.String())
+		}
+	}
+}
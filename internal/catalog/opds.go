@@ -0,0 +1,207 @@
+package catalog
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// Media types of OPDS feeds
+const (
+	NavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	AcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+)
+
+// Link relations of OPDS 1.2
+const (
+	relAcquisition = "http://opds-spec.org/acquisition"
+	relImage       = "http://opds-spec.org/image"
+	relThumbnail   = "http://opds-spec.org/image/thumbnail"
+)
+
+// archiveTypes maps comic archive extensions to their media types
+var archiveTypes = map[string]string{
+	".cbz": "application/vnd.comicbook+zip",
+	".cbr": "application/vnd.comicbook-rar",
+	".cb7": "application/x-cb7",
+	".cbt": "application/x-cbt",
+}
+
+// Feed is an OPDS catalog feed, an Atom feed of navigation or acquisition
+// entries.
+type Feed struct {
+	XMLName   xml.Name `xml:"feed"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	XmlnsDC   string   `xml:"xmlns:dc,attr"`
+	XmlnsOPDS string   `xml:"xmlns:opds,attr"`
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Links     []Link   `xml:"link"`
+	Entries   []Entry  `xml:"entry"`
+}
+
+// Entry is an entry of a Feed: a series in a navigation feed, or a comic in
+// an acquisition feed.
+type Entry struct {
+	Title     string   `xml:"title"`
+	ID        string   `xml:"id"`
+	Updated   string   `xml:"updated"`
+	Publisher string   `xml:"dc:publisher,omitempty"`
+	Issued    string   `xml:"dc:issued,omitempty"`
+	Language  string   `xml:"dc:language,omitempty"`
+	Content   *Content `xml:"content,omitempty"`
+	Links     []Link   `xml:"link"`
+}
+
+// Content is the description of an Entry.
+type Content struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// Link is a link of a Feed or an Entry.
+type Link struct {
+	Rel   string `xml:"rel,attr"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+// Links builds the links of a feed. A nil func, or one returning "", leaves
+// its link out.
+type Links struct {
+	Start  string                                // href of the root feed
+	Series func(volumeID int) string             // href of the acquisition feed of a series
+	File   func(*models.ProcessingResult) string // href to download the archive from
+	Cover  func(*models.ProcessingResult) string // href of the cover image
+}
+
+// NewSeriesFeed creates a navigation feed with an entry per series of the
+// matched results, linking to the series' acquisition feeds.
+func NewSeriesFeed(id, title, self string, results []*models.ProcessingResult, links Links) *Feed {
+	f := newFeed(id, title, self, NavigationType, results, links)
+	var last *Entry
+	lastVolume := 0
+	for _, result := range sorted(results) {
+		volume := result.Match.SelectedIssue.Volume
+		updated := formatTime(result.ProcessedAt)
+		if last != nil && volume.ID == lastVolume {
+			last.Updated = max(last.Updated, updated)
+			continue
+		}
+		name := volume.Name
+		if volume.StartYear != "" {
+			name += " (" + volume.StartYear + ")"
+		}
+		f.Entries = append(f.Entries, Entry{
+			Title:     name,
+			ID:        "urn:comicvine:volume:" + strconv.Itoa(volume.ID),
+			Updated:   updated,
+			Publisher: volume.Publisher,
+		})
+		last, lastVolume = &f.Entries[len(f.Entries)-1], volume.ID
+		if links.Series != nil {
+			last.Links = append(last.Links, Link{Rel: "subsection", Href: links.Series(volume.ID), Type: AcquisitionType})
+		}
+	}
+	return f
+}
+
+// NewAcquisitionFeed creates an acquisition feed with an entry per matched
+// result, sorted by series and cover date.
+func NewAcquisitionFeed(id, title, self string, results []*models.ProcessingResult, links Links) *Feed {
+	f := newFeed(id, title, self, AcquisitionType, results, links)
+	for _, result := range sorted(results) {
+		f.Entries = append(f.Entries, newComicEntry(result, links))
+	}
+	return f
+}
+
+// newComicEntry creates the acquisition entry of a matched result.
+func newComicEntry(result *models.ProcessingResult, links Links) Entry {
+	issue := result.Match.SelectedIssue
+	title := fmt.Sprintf("%s #%s", issue.Volume.Name, issue.IssueNumber)
+	if issue.Name != "" {
+		title += ": " + issue.Name
+	}
+	e := Entry{
+		Title:     title,
+		ID:        "urn:xander:file:" + url.PathEscape(result.Filename),
+		Updated:   formatTime(result.ProcessedAt),
+		Publisher: issue.Volume.Publisher,
+		Language:  result.Match.ParsedInfo.Language,
+	}
+	if !issue.CoverDate.IsZero() {
+		e.Issued = issue.CoverDate.String()
+	}
+	if issue.Description != "" {
+		e.Content = &Content{Type: "html", Text: issue.Description}
+	}
+	if links.File != nil {
+		if href := links.File(result); href != "" {
+			e.Links = append(e.Links, Link{Rel: relAcquisition, Href: href, Type: ArchiveType(result.Filename)})
+		}
+	}
+	if links.Cover != nil {
+		if href := links.Cover(result); href != "" {
+			e.Links = append(e.Links,
+				Link{Rel: relImage, Href: href, Type: "image/jpeg"},
+				Link{Rel: relThumbnail, Href: href, Type: "image/jpeg"})
+		}
+	}
+	if issue.SiteDetailURL != "" {
+		e.Links = append(e.Links, Link{Rel: "alternate", Href: issue.SiteDetailURL, Type: "text/html", Title: "ComicVine"})
+	}
+	return e
+}
+
+// newFeed creates an empty feed of kind, updated when the latest of results
+// was processed.
+func newFeed(id, title, self, kind string, results []*models.ProcessingResult, links Links) *Feed {
+	var updated time.Time
+	for _, result := range results {
+		if result.ProcessedAt.After(updated) {
+			updated = result.ProcessedAt
+		}
+	}
+	f := &Feed{
+		Xmlns:     "http://www.w3.org/2005/Atom",
+		XmlnsDC:   "http://purl.org/dc/terms/",
+		XmlnsOPDS: "http://opds-spec.org/2010/catalog",
+		ID:        id,
+		Title:     title,
+		Updated:   formatTime(updated),
+	}
+	if self != "" {
+		f.Links = append(f.Links, Link{Rel: "self", Href: self, Type: kind})
+	}
+	if links.Start != "" {
+		f.Links = append(f.Links, Link{Rel: "start", Href: links.Start, Type: NavigationType})
+	}
+	return f
+}
+
+// Write writes the feed as an indented XML document.
+func (f *Feed) Write(w io.Writer) error {
+	return writeXML(w, f)
+}
+
+// ArchiveType returns the media type of a comic archive by its extension.
+func ArchiveType(filename string) string {
+	if t, ok := archiveTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
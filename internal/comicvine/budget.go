@@ -0,0 +1,283 @@
+package comicvine
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// budgetWindow is the period ComicVine's request limit applies to.
+const budgetWindow = time.Hour
+
+// countdownInterval is how often a wait for the budget reports the time
+// left.
+const countdownInterval = time.Minute
+
+// budgetFile holds the request times of the current window between runs,
+// in the cache dir next to the cached responses.
+const budgetFile = "comicvine-budget.json"
+
+// requestsPerFile estimates the uncached requests matching one file makes
+// per resource: a volume search, issue lookups in a couple of the found
+// volumes, and now and then a volume for its publisher. A search counts
+// once per result page it may follow; issue lookups are filtered by volume
+// and issue number and fit one page.
+var requestsPerFile = map[string]float64{
+	"search": 1,
+	"issues": 2,
+	"volume": 0.5,
+}
+
+// Budget shares ComicVine's hourly request limit, counted per resource
+// (search, issues, volume, ...) over a rolling hour, among all the workers
+// using a client. A request that would go over the limit waits for the
+// window to free a slot instead of failing, logging a countdown meanwhile.
+type Budget struct {
+	limit int
+	path  string // where the window is kept between runs; "" for nowhere
+
+	// Result pages a volume search follows at most, for Plan
+	searchPages int
+
+	mu       sync.Mutex
+	requests map[string][]time.Time // request times in the window, oldest first
+	blocked  map[string]time.Time   // resources the API refused until then
+	pace     bool                   // spread requests over the window
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	logger *slog.Logger
+}
+
+// newBudget creates a budget of limit requests per resource and hour,
+// loading the window a previous run left in path.
+func newBudget(limit int, path string, logger *slog.Logger) *Budget {
+	b := &Budget{
+		limit:       limit,
+		path:        path,
+		searchPages: 1,
+		requests:    make(map[string][]time.Time),
+		blocked:     make(map[string]time.Time),
+		now:         time.Now,
+		sleep:       sleepContext,
+		logger:      logger,
+	}
+	b.load()
+	return b
+}
+
+// SetLimit changes the requests allowed per resource and hour, as when the
+// configuration is reloaded. Requests already in the window still count.
+func (b *Budget) SetLimit(limit int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = limit
+}
+
+// resource returns the ComicVine resource an endpoint such as
+// "/volume/4050-1/" counts against.
+func resource(endpoint string) string {
+	name, _, _ := strings.Cut(strings.Trim(endpoint, "/"), "/")
+	return name
+}
+
+// take waits until a request to resource fits the budget and counts it.
+func (b *Budget) take(ctx context.Context, resource string) error {
+	for {
+		wait := b.reserve(resource)
+		if wait <= 0 {
+			return nil
+		}
+		if err := b.wait(ctx, resource, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve counts a request to resource and returns 0 when it fits the
+// budget, or how long to wait before trying again.
+func (b *Budget) reserve(resource string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if until, ok := b.blocked[resource]; ok {
+		if now.Before(until) {
+			return until.Sub(now)
+		}
+		delete(b.blocked, resource)
+	}
+	times := b.prune(resource, now)
+	if len(times) >= b.limit {
+		return times[0].Add(budgetWindow).Sub(now)
+	}
+	if b.pace && len(times) > 0 {
+		if next := times[len(times)-1].Add(budgetWindow / time.Duration(b.limit)); now.Before(next) {
+			return next.Sub(now)
+		}
+	}
+	b.requests[resource] = append(times, now)
+	return 0
+}
+
+// prune drops the request times of resource that left the window.
+// b.mu must be held.
+func (b *Budget) prune(resource string, now time.Time) []time.Time {
+	times := b.requests[resource]
+	i := sort.Search(len(times), func(i int) bool { return now.Sub(times[i]) < budgetWindow })
+	times = times[i:]
+	b.requests[resource] = times
+	return times
+}
+
+// wait sleeps for d, reporting the time left every countdownInterval when
+// the wait is long enough to notice.
+func (b *Budget) wait(ctx context.Context, resource string, d time.Duration) error {
+	if d < countdownInterval {
+		return b.sleep(ctx, d)
+	}
+	for left := d; left > 0; left -= countdownInterval {
+		b.logger.Info("ComicVine hourly limit reached; pausing", "resource", resource,
+			"resume_in", left.Round(time.Second))
+		if err := b.sleep(ctx, min(left, countdownInterval)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exhausted records that the API refused a request to resource for going
+// over its limit, which requests of an earlier run or another program can
+// cause. Requests to it wait until the oldest one this budget knows of
+// leaves the window, or a whole window if none.
+func (b *Budget) exhausted(resource string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	until := now.Add(budgetWindow)
+	if times := b.prune(resource, now); len(times) > 0 {
+		until = times[0].Add(budgetWindow)
+	}
+	b.blocked[resource] = until
+	b.logger.Warn("ComicVine refused a request for exceeding the hourly limit", "resource", resource,
+		"resume_in", until.Sub(now).Round(time.Second))
+}
+
+// Remaining returns how many requests to resource, such as "issue" for
+// issue detail lookups, are left in the current window.
+func (b *Budget) Remaining(resource string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if until, ok := b.blocked[resource]; ok && now.Before(until) {
+		return 0
+	}
+	return max(b.limit-len(b.prune(resource, now)), 0)
+}
+
+// BatchPlan estimates how a batch fits the request budget.
+type BatchPlan struct {
+	Files     int
+	Requests  map[string]int // estimated uncached requests per resource
+	Remaining map[string]int // requests left in the current window per resource
+	Limit     int            // requests per resource and hour
+	Wait      time.Duration  // estimated time spent waiting for the budget
+}
+
+// Fits reports whether the batch is expected to finish without waiting for
+// the budget.
+func (p BatchPlan) Fits() bool {
+	return p.Wait == 0
+}
+
+// Plan estimates the requests a batch of files makes and how long it will
+// wait for the budget. A batch that won't fit has its requests paced evenly
+// over the window from then on, rather than spending the budget at once
+// and pausing. Cached responses make the estimate an upper bound.
+func (b *Budget) Plan(files int) BatchPlan {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	plan := BatchPlan{
+		Files:     files,
+		Requests:  make(map[string]int),
+		Remaining: make(map[string]int),
+		Limit:     b.limit,
+	}
+	for name, perFile := range requestsPerFile {
+		if name == "search" {
+			perFile *= float64(b.searchPages)
+		}
+		need := int(math.Ceil(perFile * float64(files)))
+		left := max(b.limit-len(b.prune(name, now)), 0)
+		plan.Requests[name] = need
+		plan.Remaining[name] = left
+		if over := need - left; over > 0 {
+			windows := time.Duration(math.Ceil(float64(over) / float64(b.limit)))
+			plan.Wait = max(plan.Wait, windows*budgetWindow)
+		}
+	}
+	b.pace = plan.Wait > 0
+	return plan
+}
+
+// load reads the window a previous run saved. A missing or unreadable file
+// starts an empty window.
+func (b *Budget) load() {
+	if b.path == "" {
+		return
+	}
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return
+	}
+	var saved map[string][]time.Time
+	if err := json.Unmarshal(data, &saved); err != nil {
+		b.logger.Debug("ignoring unreadable request budget", "path", b.path, "error", err)
+		return
+	}
+	now := b.now()
+	for name, times := range saved {
+		b.requests[name] = times
+		b.prune(name, now)
+	}
+}
+
+// save writes the current window for the next run. An empty window writes
+// nothing, as whatever an older file holds has expired too.
+func (b *Budget) save() error {
+	if b.path == "" {
+		return nil
+	}
+	b.mu.Lock()
+	now := b.now()
+	saved := make(map[string][]time.Time)
+	for name := range b.requests {
+		if times := b.prune(name, now); len(times) > 0 {
+			saved[name] = times
+		}
+	}
+	b.mu.Unlock()
+	if len(saved) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(b.path, data, 0644)
+}
@@ -0,0 +1,115 @@
+package comicvine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// cacheSubdir keeps ComicVine responses apart from other users of the cache dir
+const cacheSubdir = "comicvine"
+
+// CacheStats counts response cache lookups.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// diskCache stores raw API response bodies on disk, one file per request.
+// Entries older than ttl are treated as missing.
+type diskCache struct {
+	dir    string
+	ttl    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func newDiskCache(dir string, ttl time.Duration) *diskCache {
+	return &diskCache{dir: filepath.Join(dir, cacheSubdir), ttl: ttl}
+}
+
+// cacheKey identifies a request by endpoint and parameters. The API key is
+// left out so cached responses survive key rotation and never contain it.
+func cacheKey(endpoint string, params url.Values) string {
+	p := url.Values{}
+	for k, v := range params {
+		if k != paramAPIKey {
+			p[k] = v
+		}
+	}
+	sum := sha256.Sum256([]byte(endpoint + "?" + p.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (dc *diskCache) path(key string) string {
+	return filepath.Join(dc.dir, key[:2], key+".json")
+}
+
+// get returns the cached body for key if it exists and has not expired.
+func (dc *diskCache) get(key string) ([]byte, bool) {
+	path := dc.path(key)
+	info, err := os.Stat(path)
+	if err == nil && (dc.ttl <= 0 || time.Since(info.ModTime()) < dc.ttl) {
+		if body, err := os.ReadFile(path); err == nil {
+			dc.hits.Add(1)
+			return body, true
+		}
+	}
+	dc.misses.Add(1)
+	return nil, false
+}
+
+// set stores body under key. The write goes through a temp file so
+// concurrent readers never see a partial response.
+func (dc *diskCache) set(key string, body []byte) error {
+	path := dc.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (dc *diskCache) stats() CacheStats {
+	return CacheStats{Hits: dc.hits.Load(), Misses: dc.misses.Load()}
+}
+
+// ClearCache deletes the cached ComicVine responses under dir and reports
+// how many were removed. A missing cache is not an error.
+func ClearCache(dir string) (int, error) {
+	root := filepath.Join(dir, cacheSubdir)
+	removed := 0
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".json" {
+			removed++
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return removed, os.RemoveAll(root)
+}
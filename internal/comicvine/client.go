@@ -5,15 +5,20 @@ package comicvine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"comic-parser/internal/config"
+	"comic-parser/internal/logging"
 	"comic-parser/internal/models"
 )
 
@@ -26,6 +31,8 @@ const (
 	paramLimit      = "limit"
 	paramFieldList  = "field_list"
 	paramFilter     = "filter"
+	paramOffset     = "offset"
+	paramPage       = "page"
 	formatJSON      = "json"
 	userAgentValue  = "ComicParser/1.0"
 	headerUserAgent = "User-Agent"
@@ -34,11 +41,36 @@ const (
 	maxVolumesToCheck  = 5
 	defaultSearchLimit = 10
 	defaultIssueLimit  = 100
+	// defaultMaxPages bounds the result pages a search or issue lookup
+	// follows; every page is a request, so only the first by default
+	defaultMaxPages = 1
 
-	// Volume ID format prefix
+	// volumeLookupWorkers bounds the volumes whose issues a search looks up
+	// at once
+	volumeLookupWorkers = 3
+
+	// statusOK is the status_code of a successful ComicVine response
+	statusOK = 1
+
+	// Resource ID format prefixes
 	volumeIDPrefix = "4050-"
+	issueIDPrefix  = "4000-"
+
+	// volumeFields are the volume fields requested from search and volume lookups
+	volumeFields = "id,name,start_year,publisher,count_of_issues,site_detail_url"
+	// volumeIssueFields are the fields requested when listing a volume's issues
+	volumeIssueFields = "id,name,issue_number,cover_date,store_date,site_detail_url,volume,image"
+	// issueDetailFields are the fields requested for single issue lookups
+	issueDetailFields = "id,name,issue_number,cover_date,store_date,description,site_detail_url,volume,image," +
+		"person_credits,character_credits,team_credits,location_credits,story_arc_credits"
+
+	// ProviderName is the metadata provider name of this client
+	ProviderName = "comicvine"
 )
 
+// ErrNotFound is returned when ComicVine answers 404 for a resource.
+var ErrNotFound = errors.New("comicvine: not found")
+
 // HTTPClient defines the interface for making HTTP requests
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -52,12 +84,37 @@ type Client struct {
 
 	// Rate limiting
 	rateLimiter *time.Ticker
-	rateMutex   sync.Mutex
 
 	// Caches to reduce API calls
 	volumeCache map[int]*models.ComicVineVolume
 	searchCache map[string][]models.ComicVineVolume
 	cacheMutex  sync.RWMutex
+
+	// Response cache shared across runs; nil when disabled
+	cache *diskCache
+
+	// Volumes, searches and issue lists kept between runs; see SetVolumeStore
+	volumes   VolumeStore
+	volumeTTL time.Duration
+
+	// Hourly request limit shared by the workers; nil when not tracked
+	budget *Budget
+
+	// Candidate volumes searchByVolumeAndIssue looks the issue up in
+	maxVolumes int
+
+	// Result pages a volume search or issue lookup follows at most
+	maxPages int
+
+	// Volume IDs of aliased series, keyed by normalizeName of the series
+	aliases map[string]int
+
+	// Retries of transient failures; see fetchWithRetry
+	maxRetries int
+	retryDelay time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	logger *slog.Logger
 }
 
 // NewClient creates a new ComicVine API client.
@@ -66,21 +123,59 @@ func NewClient(cfg *config.Config, httpClient HTTPClient) *Client {
 	// We use 1.2 seconds to be safe and conservative
 	rateInterval := 1200 * time.Millisecond
 
-	return &Client{
+	c := &Client{
 		apiKey:      cfg.ComicVineAPIKey,
 		baseURL:     cfg.ComicVineAPIBaseURL,
 		httpClient:  httpClient,
 		rateLimiter: time.NewTicker(rateInterval),
 		volumeCache: make(map[int]*models.ComicVineVolume),
 		searchCache: make(map[string][]models.ComicVineVolume),
+		maxVolumes:  cfg.ComicVineMaxVolumes,
+		maxPages:    cfg.ComicVineMaxPages,
+		maxRetries:  cfg.RetryAttempts,
+		retryDelay:  time.Duration(cfg.RetryDelaySeconds) * time.Second,
+		sleep:       sleepContext,
+		logger:      logging.Logger(logging.ComicVine),
+	}
+	if c.maxVolumes <= 0 {
+		c.maxVolumes = maxVolumesToCheck
+	}
+	if c.maxPages <= 0 {
+		c.maxPages = defaultMaxPages
+	}
+	if len(cfg.SeriesAliases) > 0 {
+		c.aliases = make(map[string]int, len(cfg.SeriesAliases))
+		for series, volumeID := range cfg.SeriesAliases {
+			c.aliases[normalizeName(series)] = volumeID
+		}
+	}
+	if cfg.CacheEnabled && cfg.CacheDir != "" {
+		c.cache = newDiskCache(cfg.CacheDir, time.Duration(cfg.CacheTTLHours)*time.Hour)
 	}
+	if cfg.ComicVineHourlyLimit > 0 {
+		var path string
+		if cfg.CacheDir != "" {
+			path = filepath.Join(cfg.CacheDir, budgetFile)
+		}
+		c.budget = newBudget(cfg.ComicVineHourlyLimit, path, c.logger)
+		c.budget.searchPages = c.maxPages
+	}
+	return c
 }
 
-// waitRateLimit waits for the rate limiter to allow a request
-func (c *Client) waitRateLimit(ctx context.Context) error {
-	c.rateMutex.Lock()
-	defer c.rateMutex.Unlock()
+// Budget returns the hourly request budget the client's requests share, or
+// nil when it is not tracked.
+func (c *Client) Budget() *Budget {
+	return c.budget
+}
 
+// waitRateLimit waits for the rate limiter to allow a request. Concurrent
+// callers take turns on the ticker, and all of them return as soon as ctx is
+// done; a cancelled ctx never takes a tick.
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -89,6 +184,83 @@ func (c *Client) waitRateLimit(ctx context.Context) error {
 	}
 }
 
+// get fetches an API endpoint and returns the response body. Responses are
+// served from the disk cache when possible; only cache misses wait for the
+// rate limiter, and transient failures are retried.
+func (c *Client) get(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	var key string
+	if c.cache != nil {
+		key = cacheKey(endpoint, params)
+		if body, ok := c.cache.get(key); ok {
+			return body, nil
+		}
+	}
+
+	reqURL := fmt.Sprintf("%s%s?%s", c.baseURL, endpoint, params.Encode())
+	body, err := c.fetchWithRetry(ctx, endpoint, reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// ComicVine reports some errors (bad key, bad filter) with a 200 status;
+	// only successful responses are cached. A failed cache write just means
+	// the request is made again next time.
+	if c.cache != nil {
+		var status struct {
+			StatusCode int `json:"status_code"`
+		}
+		if json.Unmarshal(body, &status) == nil && status.StatusCode == statusOK {
+			c.cache.set(key, body)
+		}
+	}
+
+	return body, nil
+}
+
+// getPages fetches the pages of a paged endpoint, passing each body to
+// decode, which returns how many results the page held and the response's
+// number_of_total_results. Paging stops at the total, at an empty page or
+// after maxPages pages (0 for no limit). Lists such as /issues/ are paged
+// by offset. /search/ is paged by a 1-based page number, which the first
+// page leaves out so it shares its cache entry with earlier searches.
+func (c *Client) getPages(ctx context.Context, endpoint string, params url.Values, maxPages int, decode func(body []byte) (n, total int, err error)) error {
+	seen := 0
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		switch {
+		case endpoint != "/search/":
+			params.Set(paramOffset, strconv.Itoa(seen))
+		case page > 1:
+			params.Set(paramPage, strconv.Itoa(page))
+		}
+		body, err := c.get(ctx, endpoint, params)
+		if err != nil {
+			return err
+		}
+		n, total, err := decode(body)
+		if err != nil {
+			return fmt.Errorf("parsing response: %w", err)
+		}
+		seen += n
+		if n == 0 || seen >= total {
+			return nil
+		}
+		if page == maxPages {
+			c.logger.Debug("stopped at the page limit", "endpoint", endpoint, "filter", params.Get(paramFilter),
+				"query", params.Get(paramQuery), "results", seen, "total", total)
+		}
+	}
+	return nil
+}
+
+// CacheStats reports response cache hits and misses. It is zero when the
+// cache is disabled.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
 // SearchIssues searches for comic issues by title and optional issue number
 func (c *Client) SearchIssues(ctx context.Context, title string, issueNumber string) ([]models.ComicVineIssue, error) {
 	// Wait for rate limit happens inside sub-calls
@@ -101,12 +273,13 @@ func (c *Client) SearchIssues(ctx context.Context, title string, issueNumber str
 		return nil, err
 	}
 
-	// Enrich results with publisher info
+	// Enrich results with publisher and start year where the search lacked them
 	for i := range issues {
-		if issues[i].Volume.ID > 0 {
-			vol, err := c.getVolume(ctx, issues[i].Volume.ID)
-			if err == nil && vol != nil && vol.Publisher.Name != "" {
-				issues[i].Volume.Publisher = vol.Publisher.Name
+		ref := &issues[i].Volume
+		if ref.ID > 0 && (ref.Publisher == "" || ref.StartYear == "") {
+			vol, err := c.getVolume(ctx, ref.ID)
+			if err == nil && vol != nil {
+				mergeVolume(ref, vol)
 			}
 		}
 	}
@@ -114,8 +287,112 @@ func (c *Client) SearchIssues(ctx context.Context, title string, issueNumber str
 	return issues, nil
 }
 
+// mergeVolume fills in volume details on ref that it does not already have.
+func mergeVolume(ref *models.VolumeRef, vol *models.ComicVineVolume) {
+	if ref.ID == 0 {
+		ref.ID = vol.ID
+	}
+	if ref.Name == "" {
+		ref.Name = vol.Name
+	}
+	if ref.SiteURL == "" {
+		ref.SiteURL = vol.SiteDetailURL
+	}
+	if ref.Publisher == "" {
+		ref.Publisher = vol.Publisher.Name
+	}
+	if ref.PublisherID == 0 {
+		ref.PublisherID = vol.Publisher.ID
+	}
+	if ref.StartYear == "" {
+		ref.StartYear = vol.StartYear
+	}
+	if ref.IssueCount == 0 {
+		ref.IssueCount = vol.CountOfIssues
+	}
+}
+
+// volumeQuery is what is known of the volume a file belongs to.
+type volumeQuery struct {
+	name      string // normalized
+	year      int
+	hasYear   bool
+	publisher string // normalized, empty when unknown
+}
+
+func newVolumeQuery(series, year, publisher string) volumeQuery {
+	q := volumeQuery{name: normalizeName(series), publisher: normalizeName(publisher)}
+	q.year, q.hasYear = models.ParseYear(year)
+	return q
+}
+
+// score rates how well vol fits the query. Exact (normalized) names beat
+// partial ones; with a year, volumes that started closest before it are
+// preferred and later volumes are penalized; with a publisher, volumes from
+// another publisher are penalized.
+func (q volumeQuery) score(vol *models.ComicVineVolume) int {
+	name := normalizeName(vol.Name)
+
+	score := 0
+	switch {
+	case name == q.name:
+		score += 100
+	case strings.Contains(name, q.name) || strings.Contains(q.name, name):
+		score += 50
+	}
+
+	if q.hasYear {
+		if start, ok := models.ParseYear(vol.StartYear); ok {
+			if gap := q.year - start; gap >= 0 {
+				score += 20 - min(gap, 20)
+			} else {
+				score -= 50
+			}
+		}
+	}
+
+	// "Image" and "Image Comics" are the same publisher
+	if publisher := normalizeName(vol.Publisher.Name); q.publisher != "" && publisher != "" {
+		if strings.Contains(publisher, q.publisher) || strings.Contains(q.publisher, publisher) {
+			score += 30
+		} else {
+			score -= 30
+		}
+	}
+	return score
+}
+
+// rankVolumes returns volumes ordered by how well they fit q, best first.
+// Ties keep ComicVine's relevance order.
+func rankVolumes(volumes []models.ComicVineVolume, q volumeQuery) []models.ComicVineVolume {
+	ranked := slices.Clone(volumes)
+	slices.SortStableFunc(ranked, func(a, b models.ComicVineVolume) int {
+		return q.score(&b) - q.score(&a)
+	})
+	return ranked
+}
+
+// normalizeName lowercases a series name and strips everything but letters and digits
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 // searchByVolumeAndIssue performs a search using the issues endpoint with filters
 func (c *Client) searchByVolumeAndIssue(ctx context.Context, title string, issueNumber string) ([]models.ComicVineIssue, error) {
+	hint, hinted := ctx.Value(volumeHintKey{}).(VolumeHint)
+	if hinted && hint.VolumeID > 0 {
+		return c.aliasedIssues(ctx, hint.VolumeID, issueNumber)
+	}
+	if volumeID, ok := c.aliases[normalizeName(title)]; ok {
+		return c.aliasedIssues(ctx, volumeID, issueNumber)
+	}
+
 	// First, search for the volume
 	volumes, err := c.searchVolumes(ctx, title)
 	if err != nil {
@@ -127,33 +404,23 @@ func (c *Client) searchByVolumeAndIssue(ctx context.Context, title string, issue
 		return c.searchIssuesDirectly(ctx, title, issueNumber)
 	}
 
-	// Collect volume IDs for filtering
-	var allIssues []models.ComicVineIssue
-	seen := make(map[int]bool)
-
-	// Check top matching volumes for the issue
-	volumeLimit := maxVolumesToCheck
-	if len(volumes) < volumeLimit {
-		volumeLimit = len(volumes)
+	// Check top matching volumes for the issue, the likeliest first when
+	// the year or publisher is known
+	if hinted {
+		volumes = rankVolumes(volumes, newVolumeQuery(title, hint.Year, hint.Publisher))
 	}
+	candidates := volumes[:min(c.maxVolumes, len(volumes))]
+	found := c.lookupVolumeIssues(ctx, candidates, issueNumber)
 
-	for _, vol := range volumes[:volumeLimit] {
-		issues, err := c.getIssuesForVolume(ctx, vol.ID, issueNumber)
-		if err != nil {
-			continue // Don't fail entirely if one volume lookup fails
-		}
-
+	var allIssues []models.ComicVineIssue
+	seen := make(map[int]bool)
+	for i, issues := range found {
 		for _, issue := range issues {
 			if !seen[issue.ID] {
 				seen[issue.ID] = true
 				// Add volume info
-				issue.Volume = models.VolumeRef{
-					ID:   vol.ID,
-					Name: vol.Name,
-				}
-				if vol.Publisher.Name != "" {
-					issue.Volume.Publisher = vol.Publisher.Name
-				}
+				issue.Volume = models.VolumeRef{}
+				mergeVolume(&issue.Volume, &candidates[i])
 				allIssues = append(allIssues, issue)
 			}
 		}
@@ -167,6 +434,58 @@ func (c *Client) searchByVolumeAndIssue(ctx context.Context, title string, issue
 	return allIssues, nil
 }
 
+// aliasedIssues returns the issues numbered issueNumber of the volume a
+// series alias or VolumeHint names, skipping the volume search.
+func (c *Client) aliasedIssues(ctx context.Context, volumeID int, issueNumber string) ([]models.ComicVineIssue, error) {
+	vol, err := c.getVolume(ctx, volumeID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching aliased volume %d: %w", volumeID, err)
+	}
+	issues, err := c.getIssuesForVolume(ctx, vol, issueNumber)
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		issues[i].Volume = models.VolumeRef{}
+		mergeVolume(&issues[i].Volume, vol)
+	}
+	return issues, nil
+}
+
+// lookupVolumeIssues fetches the issues numbered issueNumber of each volume
+// with up to volumeLookupWorkers lookups at once, returning them in volume
+// order. The rate limiter still spaces out the requests, but responses and
+// stored or cached issue lists overlap. A volume whose lookup fails has no
+// issues rather than failing the search.
+func (c *Client) lookupVolumeIssues(ctx context.Context, volumes []models.ComicVineVolume, issueNumber string) [][]models.ComicVineIssue {
+	found := make([][]models.ComicVineIssue, len(volumes))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < min(volumeLookupWorkers, len(volumes)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				issues, err := c.getIssuesForVolume(ctx, &volumes[i], issueNumber)
+				if err != nil {
+					c.logger.Debug("volume issue lookup failed", "volume", volumes[i].ID, "error", err)
+					continue
+				}
+				found[i] = issues
+			}
+		}()
+	}
+
+	for i := range volumes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return found
+}
+
 // searchVolumes searches for volumes (comic series) by name
 func (c *Client) searchVolumes(ctx context.Context, name string) ([]models.ComicVineVolume, error) {
 	// Check cache first
@@ -177,9 +496,17 @@ func (c *Client) searchVolumes(ctx context.Context, name string) ([]models.Comic
 	}
 	c.cacheMutex.RUnlock()
 
-	// Respect rate limit
-	if err := c.waitRateLimit(ctx); err != nil {
-		return nil, err
+	if c.volumes != nil {
+		results, ok, err := c.volumes.CachedVolumeSearch(ctx, name, c.volumesSince())
+		if err != nil {
+			c.logger.Debug("reading stored volume search failed", "query", name, "error", err)
+		}
+		if ok {
+			c.cacheMutex.Lock()
+			c.searchCache[name] = results
+			c.cacheMutex.Unlock()
+			return results, nil
+		}
 	}
 
 	params := url.Values{}
@@ -188,109 +515,133 @@ func (c *Client) searchVolumes(ctx context.Context, name string) ([]models.Comic
 	params.Set(paramResources, "volume")
 	params.Set(paramQuery, name)
 	params.Set(paramLimit, fmt.Sprintf("%d", defaultSearchLimit))
-	params.Set(paramFieldList, "id,name,start_year,publisher")
-
-	reqURL := fmt.Sprintf("%s/search/?%s", c.baseURL, params.Encode())
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set(headerUserAgent, userAgentValue)
+	params.Set(paramFieldList, volumeFields)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
+	var volumes []models.ComicVineVolume
+	err := c.getPages(ctx, "/search/", params, c.maxPages, func(body []byte) (int, int, error) {
+		var result struct {
+			NumberOfTotalResults int                      `json:"number_of_total_results"`
+			Results              []models.ComicVineVolume `json:"results"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return 0, 0, err
+		}
+		volumes = append(volumes, result.Results...)
+		return len(result.Results), result.NumberOfTotalResults, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-	}
-
-	var result struct {
-		Results []models.ComicVineVolume `json:"results"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+		return nil, err
 	}
 
 	// Cache the result
 	c.cacheMutex.Lock()
-	c.searchCache[name] = result.Results
+	c.searchCache[name] = volumes
 	c.cacheMutex.Unlock()
+	if c.volumes != nil {
+		if err := c.volumes.SaveVolumeSearch(ctx, name, volumes); err != nil {
+			c.logger.Debug("storing volume search failed", "query", name, "error", err)
+		}
+	}
 
-	return result.Results, nil
+	return volumes, nil
 }
 
-// getIssuesForVolume gets issues for a specific volume, optionally filtered by issue number
-func (c *Client) getIssuesForVolume(ctx context.Context, volumeID int, issueNumber string) ([]models.ComicVineIssue, error) {
-	// Respect rate limit
-	if err := c.waitRateLimit(ctx); err != nil {
-		return nil, err
+// getIssuesForVolume gets issues for a specific volume, optionally filtered
+// by issue number. With a volume store, a stored issue list is filtered
+// instead, and a volume that fits one page has its whole list fetched and
+// stored.
+func (c *Client) getIssuesForVolume(ctx context.Context, vol *models.ComicVineVolume, issueNumber string) ([]models.ComicVineIssue, error) {
+	volumeID := vol.ID
+	if c.volumes != nil {
+		if issues, ok := c.storedIssues(ctx, volumeID); ok {
+			return filterIssues(issues, issueNumber), nil
+		}
+		if vol.CountOfIssues > 0 && vol.CountOfIssues <= defaultIssueLimit {
+			issues, err := c.listVolumeIssues(ctx, vol)
+			if err != nil {
+				return nil, err
+			}
+			return filterIssues(issues, issueNumber), nil
+		}
 	}
 
 	params := url.Values{}
 	params.Set(paramAPIKey, c.apiKey)
 	params.Set(paramFormat, formatJSON)
 	params.Set(paramLimit, fmt.Sprintf("%d", defaultIssueLimit))
-	params.Set(paramFieldList, "id,name,issue_number,cover_date,store_date,site_detail_url,volume,image")
+	params.Set(paramFieldList, volumeIssueFields)
 
 	// Filter by volume
 	filter := fmt.Sprintf("volume:%d", volumeID)
 	if issueNumber != "" {
-		// Normalize issue number for comparison
-		normalizedIssue := normalizeIssueNumber(issueNumber)
-		filter += fmt.Sprintf(",issue_number:%s", normalizedIssue)
+		filter += fmt.Sprintf(",issue_number:%s", models.NormalizeIssueNumber(issueNumber))
 	}
 	params.Set(paramFilter, filter)
 
-	reqURL := fmt.Sprintf("%s/issues/?%s", c.baseURL, params.Encode())
+	return c.getIssuePages(ctx, params, c.maxPages)
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+// ListVolumeIssues returns every issue of a volume, fetching as many pages
+// as the volume needs, or the issue list a volume store holds.
+func (c *Client) ListVolumeIssues(ctx context.Context, volumeID int) ([]models.ComicVineIssue, error) {
+	if c.volumes != nil {
+		if issues, ok := c.storedIssues(ctx, volumeID); ok {
+			return issues, nil
+		}
 	}
+	return c.listVolumeIssues(ctx, &models.ComicVineVolume{ID: volumeID})
+}
 
-	req.Header.Set(headerUserAgent, userAgentValue)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
+// listVolumeIssues fetches every issue of vol and stores the list when the
+// client has a volume store.
+func (c *Client) listVolumeIssues(ctx context.Context, vol *models.ComicVineVolume) ([]models.ComicVineIssue, error) {
+	params := url.Values{}
+	params.Set(paramAPIKey, c.apiKey)
+	params.Set(paramFormat, formatJSON)
+	params.Set(paramLimit, fmt.Sprintf("%d", defaultIssueLimit))
+	params.Set(paramFieldList, volumeIssueFields)
+	params.Set(paramFilter, fmt.Sprintf("volume:%d", vol.ID))
 
-	body, err := io.ReadAll(resp.Body)
+	// The whole list is stored, so it isn't cut at the page limit
+	issues, err := c.getIssuePages(ctx, params, 0)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	if c.volumes != nil && len(issues) > 0 {
+		stored := *vol
+		if stored.Name == "" {
+			// Issues name their volume when the caller had only its ID
+			stored.Name = issues[0].Volume.Name
+			stored.SiteDetailURL = issues[0].Volume.SiteURL
+		}
+		if err := c.volumes.SaveVolumeIssues(ctx, &stored, issues); err != nil {
+			c.logger.Debug("storing issue list failed", "volume", vol.ID, "error", err)
+		}
 	}
+	return issues, nil
+}
 
-	var result models.ComicVineResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+// getIssuePages fetches the pages of an /issues/ listing, up to maxPages
+// (0 for all).
+func (c *Client) getIssuePages(ctx context.Context, params url.Values, maxPages int) ([]models.ComicVineIssue, error) {
+	var issues []models.ComicVineIssue
+	err := c.getPages(ctx, "/issues/", params, maxPages, func(body []byte) (int, int, error) {
+		var result models.ComicVineResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			return 0, 0, err
+		}
+		issues = append(issues, result.Results...)
+		return len(result.Results), result.NumberOfTotalResults, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return result.Results, nil
+	return issues, nil
 }
 
 // searchIssuesDirectly searches issues directly (fallback method)
 func (c *Client) searchIssuesDirectly(ctx context.Context, title string, issueNumber string) ([]models.ComicVineIssue, error) {
-	// Respect rate limit
-	if err := c.waitRateLimit(ctx); err != nil {
-		return nil, err
-	}
-
 	// Build search query
 	query := title
 	if issueNumber != "" {
@@ -305,28 +656,9 @@ func (c *Client) searchIssuesDirectly(ctx context.Context, title string, issueNu
 	params.Set(paramLimit, fmt.Sprintf("%d", defaultSearchLimit))
 	params.Set(paramFieldList, "id,name,issue_number,cover_date,store_date,site_detail_url,volume,image")
 
-	reqURL := fmt.Sprintf("%s/search/?%s", c.baseURL, params.Encode())
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	body, err := c.get(ctx, "/search/", params)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set(headerUserAgent, userAgentValue)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var result models.ComicVineResponse
@@ -347,38 +679,27 @@ func (c *Client) getVolume(ctx context.Context, volumeID int) (*models.ComicVine
 	}
 	c.cacheMutex.RUnlock()
 
-	// Respect rate limit
-	if err := c.waitRateLimit(ctx); err != nil {
-		return nil, err
+	if c.volumes != nil {
+		vol, ok, err := c.volumes.CachedVolume(ctx, volumeID, c.volumesSince())
+		if err != nil {
+			c.logger.Debug("reading stored volume failed", "volume", volumeID, "error", err)
+		}
+		if ok {
+			c.cacheMutex.Lock()
+			c.volumeCache[volumeID] = vol
+			c.cacheMutex.Unlock()
+			return vol, nil
+		}
 	}
 
 	params := url.Values{}
 	params.Set(paramAPIKey, c.apiKey)
 	params.Set(paramFormat, formatJSON)
-	params.Set(paramFieldList, "id,name,start_year,publisher")
-
-	reqURL := fmt.Sprintf("%s/volume/%s%d/?%s", c.baseURL, volumeIDPrefix, volumeID, params.Encode())
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	req.Header.Set(headerUserAgent, userAgentValue)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("sending request: %w", err)
-	}
-	defer resp.Body.Close()
+	params.Set(paramFieldList, volumeFields)
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := c.get(ctx, fmt.Sprintf("/volume/%s%d/", volumeIDPrefix, volumeID), params)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var result models.ComicVineVolumeResponse
@@ -390,24 +711,65 @@ func (c *Client) getVolume(ctx context.Context, volumeID int) (*models.ComicVine
 	c.cacheMutex.Lock()
 	c.volumeCache[volumeID] = &result.Results
 	c.cacheMutex.Unlock()
+	if c.volumes != nil {
+		if err := c.volumes.SaveVolume(ctx, &result.Results); err != nil {
+			c.logger.Debug("storing volume failed", "volume", volumeID, "error", err)
+		}
+	}
 
 	return &result.Results, nil
 }
 
-// normalizeIssueNumber removes leading zeros and normalizes issue numbers
-func normalizeIssueNumber(issue string) string {
-	issue = strings.TrimSpace(issue)
-	issue = strings.TrimPrefix(issue, "#")
-	issue = strings.TrimLeft(issue, "0")
-	if issue == "" {
-		return "0"
+// GetIssue retrieves a single issue with its details: description, creator
+// credits, characters, teams, locations and story arcs, which search results
+// don't include.
+func (c *Client) GetIssue(ctx context.Context, issueID int) (*models.ComicVineIssue, error) {
+	params := url.Values{}
+	params.Set(paramAPIKey, c.apiKey)
+	params.Set(paramFormat, formatJSON)
+	params.Set(paramFieldList, issueDetailFields)
+
+	body, err := c.get(ctx, fmt.Sprintf("/issue/%s%d/", issueIDPrefix, issueID), params)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("issue %d: %w", issueID, ErrNotFound)
+		}
+		return nil, err
+	}
+
+	var result models.ComicVineIssueResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	issue := &result.Results
+	if ref := &issue.Volume; ref.ID > 0 && (ref.Publisher == "" || ref.StartYear == "") {
+		if vol, err := c.getVolume(ctx, ref.ID); err == nil && vol != nil {
+			mergeVolume(ref, vol)
+		}
 	}
-	return issue
+	return issue, nil
 }
 
-// Close cleans up the client resources
+// SearchSeries searches ComicVine volumes by name.
+func (c *Client) SearchSeries(ctx context.Context, name string) ([]models.ComicVineVolume, error) {
+	return c.searchVolumes(ctx, name)
+}
+
+// Name identifies the client as a metadata provider.
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// Close cleans up the client resources, keeping the request budget's window
+// for the next run.
 func (c *Client) Close() {
 	if c.rateLimiter != nil {
 		c.rateLimiter.Stop()
 	}
+	if c.budget != nil {
+		if err := c.budget.save(); err != nil {
+			c.logger.Debug("saving request budget failed", "error", err)
+		}
+	}
 }
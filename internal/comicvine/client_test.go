@@ -3,42 +3,22 @@ package comicvine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"comic-parser/internal/config"
+	"comic-parser/internal/logging"
 	"comic-parser/internal/models"
 )
 
-func TestNormalizeIssueNumber(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"1", "1"},
-		{"01", "1"},
-		{"001", "1"},
-		{"#1", "1"},
-		{"#001", "1"},
-		{"1.1", "1.1"},
-		{"  1  ", "1"},
-		{"0", "0"},
-		{"", "0"},
-	}
-
-	for _, tt := range tests {
-		t.Run(fmt.Sprintf("Input_%s", tt.input), func(t *testing.T) {
-			got := normalizeIssueNumber(tt.input)
-			if got != tt.expected {
-				t.Errorf("normalizeIssueNumber(%q) = %q, want %q", tt.input, got, tt.expected)
-			}
-		})
-	}
-}
-
 func TestNewClient(t *testing.T) {
 	cfg := &config.Config{
 		ComicVineAPIKey:     "test-key",
@@ -117,3 +97,776 @@ func TestSearchVolumes(t *testing.T) {
 		t.Errorf("Expected volume name 'Test Volume', got %s", results[0].Name)
 	}
 }
+
+func TestGetIssue(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/issue/4000-200/" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"status_code":1,"results":{"id":200,"issue_number":"1","volume":{"id":2,"name":"Saga"},"person_credits":[{"id":1,"name":"Brian K. Vaughan","role":"writer"},{"id":2,"name":"Fiona Staples","role":"artist, cover"}]}}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL}, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+	client.volumeCache[2] = &models.ComicVineVolume{ID: 2, Name: "Saga", StartYear: "2012", Publisher: models.PublisherRef{Name: "Image"}}
+
+	issue, err := client.GetIssue(context.Background(), 200)
+	if err != nil {
+		t.Fatalf("GetIssue failed: %v", err)
+	}
+	if issue.IssueNumber != "1" || issue.Volume.Publisher != "Image" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	credits := issue.Credits
+	if len(credits) != 2 || credits[1].Name != "Fiona Staples" || credits[1].Role != "artist, cover" {
+		t.Errorf("Unexpected credits: %+v", credits)
+	}
+}
+
+func TestListVolumeIssues(t *testing.T) {
+	var offsets []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/issues/" || query.Get("filter") != "volume:2" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		offsets = append(offsets, query.Get("offset"))
+		// Pages of two issues out of three
+		all := []models.ComicVineIssue{{ID: 1, IssueNumber: "1"}, {ID: 2, IssueNumber: "2"}, {ID: 3, IssueNumber: "3"}}
+		offset := len(offsets)*2 - 2
+		json.NewEncoder(w).Encode(models.ComicVineResponse{
+			StatusCode:           1,
+			NumberOfTotalResults: len(all),
+			Results:              all[offset:min(offset+2, len(all))],
+		})
+	}))
+	defer ts.Close()
+
+	client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL}, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+	issues, err := client.ListVolumeIssues(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("ListVolumeIssues failed: %v", err)
+	}
+	if len(issues) != 3 || issues[2].ID != 3 {
+		t.Errorf("Unexpected issues: %+v", issues)
+	}
+	if len(offsets) != 2 || offsets[0] != "0" || offsets[1] != "2" {
+		t.Errorf("Unexpected offsets: %v", offsets)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	var pages, offsets []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/search/":
+			// 25 volumes, 10 per page
+			pages = append(pages, query.Get("page"))
+			page := len(pages)
+			var results []models.ComicVineVolume
+			for id := page*10 - 9; id <= min(page*10, 25); id++ {
+				results = append(results, models.ComicVineVolume{ID: id, Name: "Batman"})
+			}
+			json.NewEncoder(w).Encode(struct {
+				NumberOfTotalResults int                      `json:"number_of_total_results"`
+				Results              []models.ComicVineVolume `json:"results"`
+			}{25, results})
+		case "/issues/":
+			// 250 issues, 100 per page
+			offsets = append(offsets, query.Get("offset"))
+			offset := 0
+			fmt.Sscan(query.Get("offset"), &offset)
+			var results []models.ComicVineIssue
+			for n := offset + 1; n <= min(offset+100, 250); n++ {
+				results = append(results, models.ComicVineIssue{ID: n, IssueNumber: fmt.Sprint(n)})
+			}
+			json.NewEncoder(w).Encode(models.ComicVineResponse{StatusCode: 1, NumberOfTotalResults: 250, Results: results})
+		}
+	}))
+	defer ts.Close()
+
+	newClient := func(maxPages int) *Client {
+		client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, ComicVineMaxPages: maxPages}, ts.Client())
+		client.rateLimiter.Stop()
+		client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+		return client
+	}
+	ctx := context.Background()
+
+	// By default only the first page is fetched
+	first := newClient(0)
+	defer first.Close()
+	volumes, err := first.searchVolumes(ctx, "Batman")
+	if err != nil {
+		t.Fatalf("searchVolumes failed: %v", err)
+	}
+	if len(volumes) != 10 || len(pages) != 1 {
+		t.Errorf("Expected 10 volumes from 1 page, got %d from %q", len(volumes), pages)
+	}
+
+	pages = nil
+	client := newClient(3)
+	defer client.Close()
+	volumes, err = client.searchVolumes(ctx, "Batman")
+	if err != nil {
+		t.Fatalf("searchVolumes failed: %v", err)
+	}
+	if len(volumes) != 25 || fmt.Sprint(pages) != "[ 2 3]" {
+		t.Errorf("Expected 25 volumes from pages [ 2 3], got %d from %q", len(volumes), pages)
+	}
+	issues, err := client.getIssuesForVolume(ctx, &models.ComicVineVolume{ID: 1}, "")
+	if err != nil {
+		t.Fatalf("getIssuesForVolume failed: %v", err)
+	}
+	if len(issues) != 250 || issues[249].IssueNumber != "250" || fmt.Sprint(offsets) != "[0 100 200]" {
+		t.Errorf("Expected 250 issues from offsets [0 100 200], got %d from %v", len(issues), offsets)
+	}
+
+	// The page limit caps the requests
+	offsets = nil
+	capped := newClient(2)
+	defer capped.Close()
+	issues, err = capped.getIssuesForVolume(ctx, &models.ComicVineVolume{ID: 1}, "")
+	if err != nil {
+		t.Fatalf("getIssuesForVolume failed: %v", err)
+	}
+	if len(issues) != 200 || len(offsets) != 2 {
+		t.Errorf("Expected 200 issues from 2 pages, got %d from %v", len(issues), offsets)
+	}
+}
+
+func TestBudget_CountsPages(t *testing.T) {
+	var searches int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 25 volumes, 10 per page
+		searches++
+		var results []models.ComicVineVolume
+		for id := searches*10 - 9; id <= min(searches*10, 25); id++ {
+			results = append(results, models.ComicVineVolume{ID: id, Name: "Batman"})
+		}
+		json.NewEncoder(w).Encode(struct {
+			NumberOfTotalResults int                      `json:"number_of_total_results"`
+			Results              []models.ComicVineVolume `json:"results"`
+		}{25, results})
+	}))
+	defer ts.Close()
+
+	for _, maxPages := range []int{0, 2, 3} {
+		searches = 0
+		client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, ComicVineMaxPages: maxPages, ComicVineHourlyLimit: 200}, ts.Client())
+		client.rateLimiter.Stop()
+		client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+		// A file's search is estimated at the pages it may follow
+		estimate := client.Budget().Plan(1).Requests["search"]
+		if _, err := client.searchVolumes(context.Background(), "Batman"); err != nil {
+			t.Fatalf("searchVolumes failed: %v", err)
+		}
+		taken := 200 - client.Budget().Remaining("search")
+		if taken != searches || estimate != searches {
+			t.Errorf("max pages %d: fetched %d pages, budget counted %d and estimated %d", maxPages, searches, taken, estimate)
+		}
+		client.Close()
+	}
+}
+
+func TestSearchIssues_VolumeLookups(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	var looked sync.Map
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/":
+			var volumes []models.ComicVineVolume
+			for id := 1; id <= 4; id++ {
+				volumes = append(volumes, models.ComicVineVolume{ID: id, Name: "Saga", StartYear: "2012", Publisher: models.PublisherRef{Name: "Image"}})
+			}
+			json.NewEncoder(w).Encode(struct {
+				Results []models.ComicVineVolume `json:"results"`
+			}{volumes})
+		case "/issues/":
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				if m := maxInFlight.Load(); n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			// Later volumes answer first
+			volume, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Query().Get("filter"), "volume:"), ",")
+			looked.Store(volume, true)
+			id := 0
+			fmt.Sscan(volume, &id)
+			time.Sleep(time.Duration(5-id) * 20 * time.Millisecond)
+			json.NewEncoder(w).Encode(models.ComicVineResponse{
+				Results: []models.ComicVineIssue{{ID: 100 + id, IssueNumber: "1"}},
+			})
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, ComicVineMaxVolumes: 3}
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+	issues, err := client.SearchIssues(context.Background(), "Saga", "1")
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+
+	var ids []int
+	for _, issue := range issues {
+		ids = append(ids, issue.ID)
+	}
+	if fmt.Sprint(ids) != "[101 102 103]" {
+		t.Errorf("Expected the issues of the first 3 volumes in volume order, got %v", ids)
+	}
+	if _, ok := looked.Load("4"); ok {
+		t.Error("Expected volume 4 not to be looked up")
+	}
+	if maxInFlight.Load() < 2 {
+		t.Errorf("Expected volume lookups to overlap, got at most %d at once", maxInFlight.Load())
+	}
+}
+
+func TestSearchIssues_VolumeHint(t *testing.T) {
+	var looked []string
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/":
+			json.NewEncoder(w).Encode(struct {
+				Results []models.ComicVineVolume `json:"results"`
+			}{[]models.ComicVineVolume{
+				{ID: 1, Name: "Batman", StartYear: "1940", Publisher: models.PublisherRef{Name: "DC Comics"}},
+				{ID: 2, Name: "Batman", StartYear: "2016", Publisher: models.PublisherRef{Name: "Panini"}},
+				{ID: 3, Name: "Batman", StartYear: "2011", Publisher: models.PublisherRef{Name: "DC Comics"}},
+				{ID: 4, Name: "Batman", StartYear: "2016", Publisher: models.PublisherRef{Name: "DC Comics"}},
+			}})
+		case "/issues/":
+			volume, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Query().Get("filter"), "volume:"), ",")
+			mu.Lock()
+			looked = append(looked, volume)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(models.ComicVineResponse{Results: []models.ComicVineIssue{{ID: 100, IssueNumber: "1"}}})
+		}
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, ComicVineMaxVolumes: 1}
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+	ctx := WithVolumeHint(context.Background(), VolumeHint{Year: "2017", Publisher: "DC"})
+	issues, err := client.SearchIssues(ctx, "Batman", "1")
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if fmt.Sprint(looked) != "[4]" {
+		t.Errorf("Expected only the 2016 DC volume looked up, got %v", looked)
+	}
+	if len(issues) != 1 || issues[0].Volume.StartYear != "2016" {
+		t.Errorf("Expected the issue of the 2016 volume, got %+v", issues)
+	}
+
+	// Without a hint, ComicVine's order is kept
+	looked = nil
+	if _, err := client.SearchIssues(context.Background(), "Batman", "1"); err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if fmt.Sprint(looked) != "[1]" {
+		t.Errorf("Expected the first volume looked up, got %v", looked)
+	}
+}
+
+func TestSearchIssues_SeriesAlias(t *testing.T) {
+	var searched bool
+	var filters []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/":
+			searched = true
+			json.NewEncoder(w).Encode(struct {
+				Results []models.ComicVineVolume `json:"results"`
+			}{})
+		case "/volume/4050-1234/":
+			json.NewEncoder(w).Encode(models.ComicVineVolumeResponse{Results: models.ComicVineVolume{
+				ID: 1234, Name: "2000 AD", StartYear: "1977", Publisher: models.PublisherRef{Name: "Rebellion"},
+			}})
+		case "/issues/":
+			filters = append(filters, r.URL.Query().Get("filter"))
+			json.NewEncoder(w).Encode(models.ComicVineResponse{Results: []models.ComicVineIssue{{ID: 100, IssueNumber: "2350"}}})
+		}
+	}))
+	defer ts.Close()
+
+	cfg := &config.Config{
+		ComicVineAPIKey:     "test-key",
+		ComicVineAPIBaseURL: ts.URL,
+		SeriesAliases:       map[string]int{"2000AD": 1234},
+	}
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+	issues, err := client.SearchIssues(context.Background(), "2000 AD", "2350")
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if searched {
+		t.Errorf("Expected the aliased series to skip the volume search")
+	}
+	if fmt.Sprint(filters) != "[volume:1234,issue_number:2350]" {
+		t.Errorf("Expected the issue looked up in the aliased volume, got %v", filters)
+	}
+	if len(issues) != 1 || issues[0].Volume.ID != 1234 || issues[0].Volume.Publisher != "Rebellion" {
+		t.Errorf("Expected the issue of the aliased volume, got %+v", issues)
+	}
+}
+
+func TestWaitRateLimit_Cancelled(t *testing.T) {
+	client := NewClient(&config.Config{ComicVineAPIKey: "test-key"}, http.DefaultClient)
+	defer client.Close()
+
+	// A tick is ready, but a cancelled wait must not take it
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 20 {
+		if err := client.waitRateLimit(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	}
+
+	// Waiters queued behind each other all return on cancellation
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(time.Hour)
+	ctx, cancel = context.WithCancel(context.Background())
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() { errs <- client.waitRateLimit(ctx) }()
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	for range 3 {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the waits to end with the context")
+		}
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"status_code":1,"results":[{"id":100,"name":"Saga","start_year":"2012"}]}`))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	newClient := func(key string) *Client {
+		client := NewClient(&config.Config{
+			ComicVineAPIKey:     key,
+			ComicVineAPIBaseURL: ts.URL,
+			CacheEnabled:        true,
+			CacheDir:            dir,
+			CacheTTLHours:       1,
+		}, ts.Client())
+		client.rateLimiter.Stop()
+		client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+		return client
+	}
+
+	first := newClient("key-1")
+	defer first.Close()
+	if _, err := first.searchVolumes(context.Background(), "Saga"); err != nil {
+		t.Fatalf("searchVolumes failed: %v", err)
+	}
+
+	// A new client (as in a later run) with a different key hits the disk cache
+	second := newClient("key-2")
+	defer second.Close()
+	vols, err := second.searchVolumes(context.Background(), "Saga")
+	if err != nil {
+		t.Fatalf("searchVolumes failed: %v", err)
+	}
+	if requests != 1 || len(vols) != 1 || vols[0].ID != 100 {
+		t.Errorf("Expected one request and a cached result, got %d requests and %+v", requests, vols)
+	}
+	if stats := second.CacheStats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("Unexpected cache stats: %+v", stats)
+	}
+
+	removed, err := ClearCache(dir)
+	if err != nil || removed != 1 {
+		t.Fatalf("ClearCache = %d, %v; want 1 entry removed", removed, err)
+	}
+	third := newClient("key-1")
+	defer third.Close()
+	if _, err := third.searchVolumes(context.Background(), "Saga"); err != nil {
+		t.Fatalf("searchVolumes failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a request after clearing the cache, got %d requests", requests)
+	}
+}
+
+// memoryVolumeStore is a VolumeStore in maps, ignoring expiry.
+type memoryVolumeStore struct {
+	searches map[string][]models.ComicVineVolume
+	volumes  map[int]*models.ComicVineVolume
+	issues   map[int][]models.ComicVineIssue
+}
+
+func newMemoryVolumeStore() *memoryVolumeStore {
+	return &memoryVolumeStore{
+		searches: make(map[string][]models.ComicVineVolume),
+		volumes:  make(map[int]*models.ComicVineVolume),
+		issues:   make(map[int][]models.ComicVineIssue),
+	}
+}
+
+func (m *memoryVolumeStore) CachedVolumeSearch(_ context.Context, query string, _ time.Time) ([]models.ComicVineVolume, bool, error) {
+	volumes, ok := m.searches[query]
+	return volumes, ok, nil
+}
+
+func (m *memoryVolumeStore) SaveVolumeSearch(_ context.Context, query string, volumes []models.ComicVineVolume) error {
+	m.searches[query] = volumes
+	return nil
+}
+
+func (m *memoryVolumeStore) CachedVolume(_ context.Context, id int, _ time.Time) (*models.ComicVineVolume, bool, error) {
+	vol, ok := m.volumes[id]
+	return vol, ok, nil
+}
+
+func (m *memoryVolumeStore) SaveVolume(_ context.Context, vol *models.ComicVineVolume) error {
+	m.volumes[vol.ID] = vol
+	return nil
+}
+
+func (m *memoryVolumeStore) CachedVolumeIssues(_ context.Context, volumeID int, _ time.Time) ([]models.ComicVineIssue, bool, error) {
+	issues, ok := m.issues[volumeID]
+	return issues, ok, nil
+}
+
+func (m *memoryVolumeStore) SaveVolumeIssues(_ context.Context, vol *models.ComicVineVolume, issues []models.ComicVineIssue) error {
+	m.issues[vol.ID] = issues
+	return nil
+}
+
+func TestVolumeStore(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/search/":
+			w.Write([]byte(`{"status_code":1,"results":[{"id":2,"name":"Saga","start_year":"2012","count_of_issues":3,"publisher":{"id":5,"name":"Image"}}]}`))
+		case "/issues/":
+			// A volume that fits one page is listed whole
+			if filter := r.URL.Query().Get("filter"); filter != "volume:2" {
+				t.Errorf("Unexpected filter %q", filter)
+			}
+			w.Write([]byte(`{"status_code":1,"number_of_total_results":3,"results":[
+				{"id":200,"issue_number":"1","volume":{"id":2,"name":"Saga"}},
+				{"id":201,"issue_number":"2","volume":{"id":2,"name":"Saga"}},
+				{"id":202,"issue_number":"3","volume":{"id":2,"name":"Saga"}}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	store := newMemoryVolumeStore()
+	newClient := func() *Client {
+		client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL}, ts.Client())
+		client.rateLimiter.Stop()
+		client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+		client.SetVolumeStore(store, 0)
+		return client
+	}
+
+	client := newClient()
+	defer client.Close()
+	issues, err := client.SearchIssues(context.Background(), "Saga", "1")
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != 200 || issues[0].Volume.Publisher != "Image" {
+		t.Errorf("Unexpected issues: %+v", issues)
+	}
+	if len(requests) != 2 || len(store.searches["Saga"]) != 1 || len(store.issues[2]) != 3 {
+		t.Fatalf("Expected a search and an issue list, stored; got requests %v", requests)
+	}
+
+	// A later run finds the series and the other issues in the store
+	requests = nil
+	later := newClient()
+	defer later.Close()
+	issues, err = later.SearchIssues(context.Background(), "Saga", "003")
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].ID != 202 {
+		t.Errorf("Expected issue 202, got %+v", issues)
+	}
+	issues, err = later.ListVolumeIssues(context.Background(), 2)
+	if err != nil || len(issues) != 3 {
+		t.Errorf("Expected the stored issue list, got %d issues, %v", len(issues), err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("Expected no requests with a stored series, got %v", requests)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"status_code":1,"results":{"id":200,"issue_number":"1"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, RetryAttempts: 3, RetryDelaySeconds: 1}, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+	var delays []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	ctx := WithRetryCount(context.Background())
+	issue, err := client.GetIssue(ctx, 200)
+	if err != nil {
+		t.Fatalf("GetIssue failed: %v", err)
+	}
+	if issue.ID != 200 || requests != 3 {
+		t.Errorf("Expected issue 200 on the third request, got %d after %d", issue.ID, requests)
+	}
+	if got := RetryCount(ctx); got != 2 {
+		t.Errorf("Expected 2 retries counted, got %d", got)
+	}
+	// Retry-After outweighs the first backoff; the second backoff of 2s
+	// loses up to half to jitter
+	if len(delays) != 2 || delays[0] != 7*time.Second || delays[1] < time.Second || delays[1] > 2*time.Second {
+		t.Errorf("Unexpected delays: %v", delays)
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/search/" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, RetryAttempts: 2}, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+
+	_, err := client.SearchSeries(context.Background(), "Saga")
+	if err == nil || requests != 3 {
+		t.Fatalf("Expected failure after 3 requests, got %v after %d", err, requests)
+	}
+
+	// Client errors other than 429 are not retried
+	requests = 0
+	_, err = client.GetIssue(context.Background(), 200)
+	if err == nil || requests != 1 {
+		t.Errorf("Expected failure after 1 request, got %v after %d", err, requests)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"":                              0,
+		"30":                            30 * time.Second,
+		"-5":                            0,
+		"soon":                          0,
+		"Wed, 01 May 2024 12:01:30 GMT": 90 * time.Second,
+		"Wed, 01 May 2024 11:00:00 GMT": 0,
+	}
+	for value, want := range tests {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+// fakeClock drives a budget's time, with sleeps advancing it.
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func (c *fakeClock) install(b *Budget) {
+	b.now = func() time.Time { return c.now }
+	b.sleep = func(ctx context.Context, d time.Duration) error {
+		c.now = c.now.Add(d)
+		c.slept += d
+		return nil
+	}
+}
+
+func TestBudget(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	b := newBudget(2, "", logging.Logger(logging.ComicVine))
+	clock.install(b)
+
+	for range 2 {
+		if err := b.take(context.Background(), "issues"); err != nil {
+			t.Fatalf("take failed: %v", err)
+		}
+	}
+	if clock.slept != 0 {
+		t.Errorf("Expected requests within the limit not to wait, waited %v", clock.slept)
+	}
+	// Other resources have their own limit
+	if wait := b.reserve("search"); wait != 0 {
+		t.Errorf("Expected a search to fit, got wait %v", wait)
+	}
+
+	clock.now = clock.now.Add(10 * time.Minute)
+	if err := b.take(context.Background(), "issues"); err != nil {
+		t.Fatalf("take failed: %v", err)
+	}
+	if clock.slept != 50*time.Minute {
+		t.Errorf("Expected to wait for the oldest request to leave the window, waited %v", clock.slept)
+	}
+}
+
+func TestBudget_SetLimit(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	b := newBudget(3, "", logging.Logger(logging.ComicVine))
+	clock.install(b)
+	for range 2 {
+		b.take(context.Background(), "issues")
+	}
+
+	b.SetLimit(2)
+	if left := b.Remaining("issues"); left != 0 {
+		t.Errorf("Expected requests in the window to count against the lower limit, %d left", left)
+	}
+	b.SetLimit(5)
+	if left := b.Remaining("issues"); left != 3 {
+		t.Errorf("Expected 3 requests left under the raised limit, got %d", left)
+	}
+}
+
+func TestBudget_Plan(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	b := newBudget(200, "", logging.Logger(logging.ComicVine))
+	clock.install(b)
+
+	plan := b.Plan(50)
+	if !plan.Fits() || plan.Requests["issues"] != 100 || plan.Remaining["issues"] != 200 {
+		t.Errorf("Expected 50 files to fit, got %+v", plan)
+	}
+
+	plan = b.Plan(250)
+	if plan.Fits() || plan.Requests["issues"] != 500 || plan.Wait != 2*time.Hour {
+		t.Errorf("Expected 250 files to wait 2h, got %+v", plan)
+	}
+
+	// A batch that won't fit spreads its requests over the window
+	b.take(context.Background(), "issues")
+	b.take(context.Background(), "issues")
+	if want := time.Hour / 200; clock.slept != want {
+		t.Errorf("Expected paced requests %v apart, waited %v", want, clock.slept)
+	}
+}
+
+func TestBudget_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), budgetFile)
+	clock := &fakeClock{now: time.Now()}
+	b := newBudget(5, path, logging.Logger(logging.ComicVine))
+	clock.install(b)
+	b.take(context.Background(), "issues")
+	b.take(context.Background(), "search")
+	if err := b.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	loaded := newBudget(5, path, logging.Logger(logging.ComicVine))
+	plan := loaded.Plan(1)
+	if plan.Remaining["issues"] != 4 || plan.Remaining["search"] != 4 || plan.Remaining["volume"] != 5 {
+		t.Errorf("Expected the saved window to be loaded, got %+v", plan.Remaining)
+	}
+}
+
+func TestRetry_HourlyLimit(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(statusRateLimited)
+			w.Write([]byte(`{"status_code":107,"error":"Rate limit exceeded"}`))
+			return
+		}
+		w.Write([]byte(`{"status_code":1,"results":{"id":200,"issue_number":"1"}}`))
+	}))
+	defer ts.Close()
+
+	client := NewClient(&config.Config{ComicVineAPIKey: "test-key", ComicVineAPIBaseURL: ts.URL, RetryAttempts: 0, ComicVineHourlyLimit: 200}, ts.Client())
+	defer client.Close()
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	clock.install(client.Budget())
+
+	ctx := WithRetryCount(context.Background())
+	issue, err := client.GetIssue(ctx, 200)
+	if err != nil {
+		t.Fatalf("Expected the refused request to wait and succeed, got %v", err)
+	}
+	if issue.ID != 200 || requests != 2 {
+		t.Errorf("Expected issue 200 on the second request, got %d after %d", issue.ID, requests)
+	}
+	if clock.slept != time.Hour {
+		t.Errorf("Expected to wait out the window, waited %v", clock.slept)
+	}
+	if got := RetryCount(ctx); got != 0 {
+		t.Errorf("Expected the wait not to count as a retry, got %d", got)
+	}
+}
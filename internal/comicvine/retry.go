@@ -0,0 +1,192 @@
+package comicvine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// maxBackoff caps the exponential backoff between retries, though a longer
+// Retry-After from the server is still honored
+const maxBackoff = time.Minute
+
+// transientError is a failed request worth retrying: a network error, a 429
+// or a 5xx response.
+type transientError struct {
+	err        error
+	retryAfter time.Duration // from the Retry-After header, 0 if absent
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// statusRateLimited is the status ComicVine answers requests over its
+// hourly limit with.
+const statusRateLimited = 420
+
+// rateLimitError is a request refused for going over the hourly limit.
+type rateLimitError struct {
+	err error
+}
+
+func (e *rateLimitError) Error() string { return e.err.Error() }
+func (e *rateLimitError) Unwrap() error { return e.err }
+
+type retriesKey struct{}
+
+// WithRetryCount returns a context that counts the retries of the ComicVine
+// requests made with it, so they can be attributed to one file. Read the
+// count with RetryCount.
+func WithRetryCount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retriesKey{}, new(atomic.Int64))
+}
+
+// RetryCount returns the number of retries made with a context from
+// WithRetryCount, or 0 for any other context.
+func RetryCount(ctx context.Context) int {
+	if n, ok := ctx.Value(retriesKey{}).(*atomic.Int64); ok {
+		return int(n.Load())
+	}
+	return 0
+}
+
+func countRetry(ctx context.Context) {
+	if n, ok := ctx.Value(retriesKey{}).(*atomic.Int64); ok {
+		n.Add(1)
+	}
+}
+
+// fetchWithRetry fetches an endpoint, retrying transient failures with
+// exponential backoff and jitter like the LLM client's retries. A
+// Retry-After header overrides the backoff when it asks for longer. With a
+// request budget, a request refused for going over the hourly limit waits
+// for the budget and is made again without counting as a retry.
+func (c *Client) fetchWithRetry(ctx context.Context, endpoint, reqURL string) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		body, err := c.fetch(ctx, endpoint, reqURL)
+		var limited *rateLimitError
+		if c.budget != nil && errors.As(err, &limited) {
+			c.budget.exhausted(resource(endpoint))
+			attempt--
+			continue
+		}
+		var transient *transientError
+		if err == nil || !errors.As(err, &transient) || attempt >= c.maxRetries {
+			if err != nil && attempt > 0 {
+				return nil, fmt.Errorf("failed after %d attempts: %w", attempt+1, err)
+			}
+			return body, err
+		}
+
+		delay := max(backoff(c.retryDelay, attempt), transient.retryAfter)
+		c.logger.Debug("request failed, retrying", "endpoint", endpoint, "error", err,
+			"retry", attempt+1, "max_retries", c.maxRetries, "delay", delay.Round(time.Millisecond))
+		countRetry(ctx)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fetch makes a single request, waiting for the request budget and the rate
+// limiter first.
+func (c *Client) fetch(ctx context.Context, endpoint, reqURL string) ([]byte, error) {
+	if c.budget != nil {
+		if err := c.budget.take(ctx, resource(endpoint)); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set(headerUserAgent, userAgentValue)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("sending request: %w", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &transientError{err: models.Fail(models.ErrProviderUnavailable, err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &transientError{err: models.Fail(models.ErrProviderUnavailable, fmt.Errorf("reading response: %w", err))}
+	}
+
+	statusErr := func() error {
+		err := fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return models.Fail(models.StatusFailure(resp.StatusCode), err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", endpoint, ErrNotFound)
+	case resp.StatusCode == statusRateLimited:
+		return nil, &rateLimitError{err: statusErr()}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, &transientError{
+			err:        statusErr(),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// backoff returns the delay before retry attempt+1: base * 2^attempt capped
+// at maxBackoff, with up to half of it taken off at random so concurrent
+// workers don't retry in lockstep.
+func backoff(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	d := min(base<<min(attempt, 16), maxBackoff)
+	return d - time.Duration(rand.Int64N(int64(d/2)+1))
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
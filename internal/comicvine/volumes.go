@@ -0,0 +1,85 @@
+package comicvine
+
+import (
+	"context"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// VolumeStore keeps volumes, volume searches and whole volume issue lists
+// between runs. Lookups report entries fetched before since as not found.
+type VolumeStore interface {
+	CachedVolumeSearch(ctx context.Context, query string, since time.Time) ([]models.ComicVineVolume, bool, error)
+	SaveVolumeSearch(ctx context.Context, query string, volumes []models.ComicVineVolume) error
+	CachedVolume(ctx context.Context, id int, since time.Time) (*models.ComicVineVolume, bool, error)
+	SaveVolume(ctx context.Context, vol *models.ComicVineVolume) error
+	CachedVolumeIssues(ctx context.Context, volumeID int, since time.Time) ([]models.ComicVineIssue, bool, error)
+	SaveVolumeIssues(ctx context.Context, vol *models.ComicVineVolume, issues []models.ComicVineIssue) error
+}
+
+// VolumeHint is what a search knows about the volume it looks for besides
+// its name, from the parsed filename.
+type VolumeHint struct {
+	Year      string
+	Publisher string
+
+	// VolumeID, when set, names the volume the issue is in, as a series
+	// alias does; the volume search is skipped
+	VolumeID int
+}
+
+type volumeHintKey struct{}
+
+// WithVolumeHint returns a context that has SearchIssues rank same-named
+// volumes by hint before looking up their issues, so "Batman (2016)" is
+// looked for in the 2016 volume first rather than the 1940 one. Volumes
+// beyond the lookup limit are then the unlikely ones.
+func WithVolumeHint(ctx context.Context, hint VolumeHint) context.Context {
+	return context.WithValue(ctx, volumeHintKey{}, hint)
+}
+
+// SetVolumeStore keeps the volumes, searches and issue lists the client
+// fetches in store for ttl (0 = forever), so later runs matching the same
+// series skip those requests. With a store, the issues of volumes that fit
+// one page are fetched whole and filtered locally, which costs the same
+// request as filtering by issue number and serves every later issue of the
+// volume.
+func (c *Client) SetVolumeStore(store VolumeStore, ttl time.Duration) {
+	c.volumes = store
+	c.volumeTTL = ttl
+}
+
+// volumesSince returns the oldest fetch time of a stored entry still in use.
+func (c *Client) volumesSince() time.Time {
+	if c.volumeTTL <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-c.volumeTTL)
+}
+
+// storedIssues returns the stored issue list of a volume, if any. Store
+// failures are logged and treated as a miss.
+func (c *Client) storedIssues(ctx context.Context, volumeID int) ([]models.ComicVineIssue, bool) {
+	issues, ok, err := c.volumes.CachedVolumeIssues(ctx, volumeID, c.volumesSince())
+	if err != nil {
+		c.logger.Debug("reading stored issue list failed", "volume", volumeID, "error", err)
+		return nil, false
+	}
+	return issues, ok
+}
+
+// filterIssues returns the issues numbered issueNumber, or all of them when
+// it is empty, like the API's issue_number filter.
+func filterIssues(issues []models.ComicVineIssue, issueNumber string) []models.ComicVineIssue {
+	if issueNumber == "" {
+		return issues
+	}
+	var matched []models.ComicVineIssue
+	for _, issue := range issues {
+		if models.SameIssueNumber(issue.IssueNumber, issueNumber) {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
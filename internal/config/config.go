@@ -4,8 +4,12 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+
+	"comic-parser/internal/keychain"
+	"comic-parser/internal/logging"
 )
 
 const (
@@ -14,6 +18,15 @@ const (
 	defaultAnthropicMaxTokens  = 1024
 	defaultAnthropicAPIBaseURL = "https://api.anthropic.com/v1"
 	defaultComicVineAPIBaseURL = "https://comicvine.gamespot.com/api"
+	defaultMetronAPIBaseURL    = "https://metron.cloud/api"
+	defaultTVDBAPIBaseURL      = "https://api4.thetvdb.com/v4"
+
+	// ComicVine allows 200 requests per resource and hour
+	defaultComicVineHourlyLimit = 200
+	// Candidate volumes a ComicVine search looks for the issue in
+	defaultComicVineMaxVolumes = 5
+	// Result pages a ComicVine search or issue lookup follows
+	defaultComicVineMaxPages = 1
 
 	// Default processing settings
 	defaultWorkerCount       = 3
@@ -21,8 +34,28 @@ const (
 	defaultRetryAttempts     = 3
 	defaultRetryDelaySeconds = 2
 
+	// Default parser chain: the free regex parser, then the LLM for the
+	// filenames it can't parse confidently
+	defaultParserChain = "regex,llm"
+
 	// Default cache settings
-	defaultCacheDir = ".cache"
+	defaultCacheDir         = ".cache"
+	defaultCacheTTLHours    = 7 * 24
+	defaultLLMCacheTTLHours = 30 * 24
+
+	// Default storage settings
+	defaultStorageBackend = "sqlite"
+
+	// LLM backends
+	defaultLLMProvider = "anthropic"
+	openAIProvider     = "openai"
+
+	// Metadata providers
+	defaultMetadataProvider = "comicvine"
+	metronProvider          = "metron"
+
+	// Default library layout used by organize
+	defaultOrganizeTemplate = "{Publisher}/{Series} ({StartYear})/{Series} #{Issue:000}{Ext}"
 
 	// Default output settings
 	defaultOutputFile   = "results.json"
@@ -31,22 +64,95 @@ const (
 	// Environment variable names
 	envAnthropicAPIKey = "ANTHROPIC_API_KEY"
 	envComicVineAPIKey = "COMICVINE_API_KEY"
+	envOpenAIAPIKey    = "OPENAI_API_KEY"
+	envMetronUsername  = "METRON_USERNAME"
+	envMetronPassword  = "METRON_PASSWORD"
+	envKomgaUsername   = "KOMGA_USERNAME"
+	envKomgaPassword   = "KOMGA_PASSWORD"
+	envKomgaAPIKey     = "KOMGA_API_KEY"
+	envNotifyURL       = "NOTIFY_URL"
+	envTVDBAPIKey      = "TVDB_API_KEY"
+	envTVDBPIN         = "TVDB_PIN"
 )
 
+// KeychainComicVineAccount is the keychain account of the ComicVine API key.
+const KeychainComicVineAccount = "comicvine_api_key"
+
+// openKeychain opens the OS keychain; tests replace it.
+var openKeychain = keychain.Open
+
 // Config holds all configuration for the application
 type Config struct {
 	// API Keys
 	AnthropicAPIKey string `json:"anthropic_api_key"`
 	ComicVineAPIKey string `json:"comicvine_api_key"`
 
+	// Keychain reads the ComicVine API key from the OS keychain, where
+	// `config set-comicvine-key -keychain` stores it. The environment still
+	// takes precedence; the config file's key is the fallback.
+	Keychain bool `json:"keychain,omitempty"`
+
 	// Anthropic settings
 	AnthropicModel      string `json:"anthropic_model"`
 	AnthropicMaxTokens  int    `json:"anthropic_max_tokens"`
 	AnthropicAPIBaseURL string `json:"anthropic_api_base_url"`
 
+	// LLM backend. LLMModel and LLMBaseURL override the Anthropic settings
+	// and are required for OpenAI-compatible servers such as Ollama or LM Studio.
+	LLMProvider  string `json:"llm_provider"` // anthropic (default) or openai
+	LLMModel     string `json:"llm_model,omitempty"`
+	LLMBaseURL   string `json:"llm_base_url,omitempty"`
+	OpenAIAPIKey string `json:"openai_api_key,omitempty"` // not needed by most local servers
+
 	// ComicVine settings
 	ComicVineAPIBaseURL string `json:"comicvine_api_base_url"`
 
+	// Requests per ComicVine resource and hour; requests over it wait for
+	// the window instead of failing (0 = not tracked)
+	ComicVineHourlyLimit int `json:"comicvine_hourly_limit"`
+
+	// Best-matching volumes of a search whose issues are fetched to find
+	// the file's issue; more finds issues of obscure volumes at one request
+	// per volume
+	ComicVineMaxVolumes int `json:"comicvine_max_volumes"`
+
+	// Result pages a volume search or issue lookup follows when ComicVine
+	// has more results than one page holds, such as the issues of a
+	// long-running series; each page is a request
+	ComicVineMaxPages int `json:"comicvine_max_pages"`
+
+	// ComicVine volume IDs of series whose filename name or numbering the
+	// volume search misses, such as "2000 AD" progs, keyed by the series
+	// name as parsed from filenames; searches for the series look the issue
+	// up in that volume only
+	SeriesAliases map[string]int `json:"series_aliases,omitempty"`
+
+	// Metadata provider used for searches
+	MetadataProvider string `json:"metadata_provider"` // comicvine (default), metron, or a registered provider
+
+	// Metron settings
+	MetronUsername   string `json:"metron_username,omitempty"`
+	MetronPassword   string `json:"metron_password,omitempty"`
+	MetronAPIBaseURL string `json:"metron_api_base_url"`
+
+	// TheTVDB settings used by the tv command. The PIN is only needed for
+	// subscriber API keys.
+	TVDBAPIKey     string `json:"tvdb_api_key,omitempty"`
+	TVDBPIN        string `json:"tvdb_pin,omitempty"`
+	TVDBAPIBaseURL string `json:"tvdb_api_base_url"`
+
+	// Komga server that push sends metadata to. An API key takes precedence
+	// over the username and password.
+	KomgaURL      string `json:"komga_url,omitempty"`
+	KomgaUsername string `json:"komga_username,omitempty"`
+	KomgaPassword string `json:"komga_password,omitempty"`
+	KomgaAPIKey   string `json:"komga_api_key,omitempty"`
+
+	// Webhook that receives a summary when a batch finishes or fails.
+	// Discord and Slack webhook URLs get a chat message, others the JSON
+	// summary itself.
+	NotifyURL string `json:"notify_url,omitempty"`
+
 	// Processing settings
 	WorkerCount       int    `json:"worker_count"`
 	RateLimitPerMin   int    `json:"rate_limit_per_min"`
@@ -54,31 +160,96 @@ type Config struct {
 	RetryDelaySeconds int    `json:"retry_delay_seconds"`
 	CacheEnabled      bool   `json:"cache_enabled"`
 	CacheDir          string `json:"cache_dir"`
+	CacheTTLHours     int    `json:"cache_ttl_hours"` // 0 keeps cached responses forever
+
+	// LLM responses are cached under cache_dir too, keyed on the prompt and
+	// model, while cache_enabled is set
+	LLMCache         bool `json:"llm_cache"`
+	LLMCacheTTLHours int  `json:"llm_cache_ttl_hours"` // 0 keeps cached responses forever
+
+	// ComicVine volumes, volume searches and issue lists are kept in the
+	// database for cache_ttl_hours too, while cache_enabled is set, so later
+	// runs matching the same series skip those requests
+	VolumeCache bool `json:"volume_cache"`
+
+	// Parsers tried in order when matching; a low confidence parse falls
+	// through to the next one. Comma-separated, e.g. "regex,llm".
+	ParserChain string `json:"parser_chain"`
+
+	// JSON file of filename patterns the regex parser tries first (default
+	// xander/patterns.json in the user's configuration directory, if present)
+	PatternsFile string `json:"patterns_file,omitempty"`
+
+	// Directory of parse.tmpl and match.tmpl prompt templates replacing the
+	// built-in ones (default xander/prompts in the user's configuration
+	// directory, if present)
+	PromptsDir string `json:"prompts_dir,omitempty"`
+
+	// Filenames the LLM parser sends per request, from the files being parsed
+	// at the same time (0 or 1 = one per request)
+	LLMBatchSize int `json:"llm_batch_size"`
+
+	// Past match corrections, from review and interactive mode, shown to the
+	// LLM selector as examples of the library's naming (0 = none)
+	MatchExamples int `json:"match_examples"`
+
+	// Storage settings; the -db flag supplies the backend's data source.
+	// Only the commands that identify files read the backend.
+	StorageBackend string `json:"storage_backend"` // sqlite (default), memory, or a registered backend
+
+	// Budget settings (0 means unlimited)
+	MaxLLMTokens int     `json:"max_llm_tokens"`
+	MaxLLMCost   float64 `json:"max_llm_cost"`
+
+	// Review mode: save high confidence matches and queue medium and low
+	// confidence ones for `review` instead of accepting them
+	ReviewQueue bool `json:"review_queue"`
+
+	// Library layout used by organize, relative to the library root
+	OrganizeTemplate string `json:"organize_template"`
 
 	// Output settings
 	OutputFile   string `json:"output_file"`
-	OutputFormat string `json:"output_format"` // json, csv
+	OutputFormat string `json:"output_format"` // json, jsonl, csv
 	Verbose      bool   `json:"verbose"`
 	Interactive  bool   `json:"interactive"`
+
+	// Log levels as -log-level takes them, e.g. "warn,comicvine=debug";
+	// the flag overrides it
+	LogLevel string `json:"log_level,omitempty"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		AnthropicModel:      defaultAnthropicModel,
-		AnthropicMaxTokens:  defaultAnthropicMaxTokens,
-		AnthropicAPIBaseURL: defaultAnthropicAPIBaseURL,
-		ComicVineAPIBaseURL: defaultComicVineAPIBaseURL,
-		WorkerCount:         defaultWorkerCount,
-		RateLimitPerMin:     defaultRateLimitPerMin,
-		RetryAttempts:       defaultRetryAttempts,
-		RetryDelaySeconds:   defaultRetryDelaySeconds,
-		CacheEnabled:        true,
-		CacheDir:            defaultCacheDir,
-		OutputFile:          defaultOutputFile,
-		OutputFormat:        defaultOutputFormat,
-		Verbose:             false,
-		Interactive:         false,
+		AnthropicModel:       defaultAnthropicModel,
+		AnthropicMaxTokens:   defaultAnthropicMaxTokens,
+		AnthropicAPIBaseURL:  defaultAnthropicAPIBaseURL,
+		LLMProvider:          defaultLLMProvider,
+		ComicVineAPIBaseURL:  defaultComicVineAPIBaseURL,
+		ComicVineHourlyLimit: defaultComicVineHourlyLimit,
+		ComicVineMaxVolumes:  defaultComicVineMaxVolumes,
+		ComicVineMaxPages:    defaultComicVineMaxPages,
+		MetadataProvider:     defaultMetadataProvider,
+		MetronAPIBaseURL:     defaultMetronAPIBaseURL,
+		TVDBAPIBaseURL:       defaultTVDBAPIBaseURL,
+		WorkerCount:          defaultWorkerCount,
+		RateLimitPerMin:      defaultRateLimitPerMin,
+		RetryAttempts:        defaultRetryAttempts,
+		RetryDelaySeconds:    defaultRetryDelaySeconds,
+		ParserChain:          defaultParserChain,
+		CacheEnabled:         true,
+		CacheDir:             defaultCacheDir,
+		CacheTTLHours:        defaultCacheTTLHours,
+		LLMCache:             true,
+		LLMCacheTTLHours:     defaultLLMCacheTTLHours,
+		VolumeCache:          true,
+		StorageBackend:       defaultStorageBackend,
+		OrganizeTemplate:     defaultOrganizeTemplate,
+		OutputFile:           defaultOutputFile,
+		OutputFormat:         defaultOutputFormat,
+		Verbose:              false,
+		Interactive:          false,
 	}
 }
 
@@ -101,27 +272,149 @@ func LoadConfig(path string) (*Config, error) {
 	return cfg, nil
 }
 
-// LoadFromEnv loads API keys from environment variables.
+// LoadFromEnv loads API keys and credentials from environment variables,
+// and with Keychain set the ComicVine API key from the OS keychain first.
 func (c *Config) LoadFromEnv() {
+	if c.Keychain {
+		c.loadFromKeychain()
+	}
 	if key := os.Getenv(envAnthropicAPIKey); key != "" {
 		c.AnthropicAPIKey = key
 	}
 	if key := os.Getenv(envComicVineAPIKey); key != "" {
 		c.ComicVineAPIKey = key
 	}
+	if key := os.Getenv(envOpenAIAPIKey); key != "" {
+		c.OpenAIAPIKey = key
+	}
+	if user := os.Getenv(envMetronUsername); user != "" {
+		c.MetronUsername = user
+	}
+	if password := os.Getenv(envMetronPassword); password != "" {
+		c.MetronPassword = password
+	}
+	if user := os.Getenv(envKomgaUsername); user != "" {
+		c.KomgaUsername = user
+	}
+	if password := os.Getenv(envKomgaPassword); password != "" {
+		c.KomgaPassword = password
+	}
+	if key := os.Getenv(envKomgaAPIKey); key != "" {
+		c.KomgaAPIKey = key
+	}
+	if webhook := os.Getenv(envNotifyURL); webhook != "" {
+		c.NotifyURL = webhook
+	}
+	if key := os.Getenv(envTVDBAPIKey); key != "" {
+		c.TVDBAPIKey = key
+	}
+	if pin := os.Getenv(envTVDBPIN); pin != "" {
+		c.TVDBPIN = pin
+	}
+}
+
+// loadFromKeychain replaces the ComicVine API key with the keychain's. When
+// the keychain is unavailable or has no key, the config file's is kept.
+func (c *Config) loadFromKeychain() {
+	logger := logging.Logger(logging.Config)
+	kc, err := openKeychain()
+	if err != nil {
+		logger.Warn("keychain unavailable, using the config file's keys", "error", err)
+		return
+	}
+	key, err := kc.Get(KeychainComicVineAccount)
+	switch {
+	case errors.Is(err, keychain.ErrNotFound):
+		logger.Debug("no ComicVine API key in the keychain")
+	case err != nil:
+		logger.Warn("reading the keychain failed, using the config file's keys", "error", err)
+	default:
+		c.ComicVineAPIKey = key
+	}
 }
 
 // Validate checks that required configuration is present.
 func (c *Config) Validate() error {
-	if c.AnthropicAPIKey == "" {
-		return fmt.Errorf("anthropic API key is required (set %s env var or in config)", envAnthropicAPIKey)
+	if err := c.ValidateLLM(); err != nil {
+		return err
+	}
+	if _, err := logging.ParseLevels(c.LogLevel); err != nil {
+		return fmt.Errorf("log_level: %w", err)
+	}
+	if c.usesComicVine() && c.ComicVineAPIKey == "" {
+		return fmt.Errorf("comicvine API key is required (set %s env var or in config)", envComicVineAPIKey)
+	}
+	if c.MetadataProvider == metronProvider && (c.MetronUsername == "" || c.MetronPassword == "") {
+		return fmt.Errorf("metron username and password are required (set %s and %s env vars or in config)", envMetronUsername, envMetronPassword)
 	}
+	return nil
+}
+
+// ValidateLLM checks that the LLM backend is configured.
+func (c *Config) ValidateLLM() error {
+	switch c.LLMProvider {
+	case "", defaultLLMProvider:
+		if c.AnthropicAPIKey == "" {
+			return fmt.Errorf("anthropic API key is required (set %s env var or in config)", envAnthropicAPIKey)
+		}
+	case openAIProvider:
+		if c.LLMModel == "" {
+			return fmt.Errorf("llm_model is required for the %s provider", openAIProvider)
+		}
+	default:
+		return fmt.Errorf("unknown llm_provider %q (must be %s or %s)", c.LLMProvider, defaultLLMProvider, openAIProvider)
+	}
+	return nil
+}
+
+// ValidateKomga checks that a Komga server and credentials are configured.
+func (c *Config) ValidateKomga() error {
+	if c.KomgaURL == "" {
+		return fmt.Errorf("komga_url is required")
+	}
+	if c.KomgaAPIKey == "" && (c.KomgaUsername == "" || c.KomgaPassword == "") {
+		return fmt.Errorf("komga API key or username and password are required (set %s, or %s and %s env vars or in config)", envKomgaAPIKey, envKomgaUsername, envKomgaPassword)
+	}
+	return nil
+}
+
+// ValidateComicVine checks that a ComicVine API key is configured.
+func (c *Config) ValidateComicVine() error {
 	if c.ComicVineAPIKey == "" {
 		return fmt.Errorf("comicvine API key is required (set %s env var or in config)", envComicVineAPIKey)
 	}
 	return nil
 }
 
+// ValidateTVDB checks that a TheTVDB API key is configured.
+func (c *Config) ValidateTVDB() error {
+	if c.TVDBAPIKey == "" {
+		return fmt.Errorf("tvdb API key is required (set %s env var or in config)", envTVDBAPIKey)
+	}
+	return nil
+}
+
+// usesComicVine reports whether ComicVine is the metadata provider.
+func (c *Config) usesComicVine() bool {
+	return c.MetadataProvider == "" || c.MetadataProvider == defaultMetadataProvider
+}
+
+// Redacted returns a copy of the configuration with API keys and passwords removed,
+// suitable for logging or persisting alongside run records.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.AnthropicAPIKey = ""
+	redacted.ComicVineAPIKey = ""
+	redacted.OpenAIAPIKey = ""
+	redacted.MetronPassword = ""
+	redacted.KomgaPassword = ""
+	redacted.KomgaAPIKey = ""
+	redacted.TVDBAPIKey = ""
+	redacted.TVDBPIN = ""
+	redacted.NotifyURL = "" // webhook URLs embed their token
+	return &redacted
+}
+
 // SaveConfig saves the configuration to a JSON file
 func (c *Config) SaveConfig(path string) error {
 	data, err := json.MarshalIndent(c, "", "  ")
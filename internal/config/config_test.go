@@ -1,8 +1,13 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"comic-parser/internal/keychain"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -17,6 +22,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.CacheEnabled != true {
 		t.Error("DefaultConfig().CacheEnabled = false; want true")
 	}
+	if cfg.ParserChain != defaultParserChain {
+		t.Errorf("DefaultConfig().ParserChain = %s; want %s", cfg.ParserChain, defaultParserChain)
+	}
 }
 
 func TestLoadFromEnv(t *testing.T) {
@@ -45,6 +53,60 @@ func TestLoadFromEnv(t *testing.T) {
 	}
 }
 
+// memKeychain is an in-memory keychain.Keychain.
+type memKeychain map[string]string
+
+func (m memKeychain) Get(account string) (string, error) {
+	if secret, ok := m[account]; ok {
+		return secret, nil
+	}
+	return "", keychain.ErrNotFound
+}
+
+func (m memKeychain) Set(account, secret string) error { m[account] = secret; return nil }
+func (m memKeychain) Delete(account string) error      { delete(m, account); return nil }
+
+func TestLoadFromKeychain(t *testing.T) {
+	t.Setenv(envComicVineAPIKey, "")
+	kc := memKeychain{KeychainComicVineAccount: "keychain-cv-key"}
+	oldOpen := openKeychain
+	openKeychain = func() (keychain.Keychain, error) { return kc, nil }
+	defer func() { openKeychain = oldOpen }()
+
+	cfg := DefaultConfig()
+	cfg.ComicVineAPIKey = "file-cv-key"
+	cfg.LoadFromEnv()
+	if cfg.ComicVineAPIKey != "file-cv-key" {
+		t.Errorf("Expected the file key without keychain set, got %s", cfg.ComicVineAPIKey)
+	}
+
+	cfg.Keychain = true
+	cfg.LoadFromEnv()
+	if cfg.ComicVineAPIKey != "keychain-cv-key" {
+		t.Errorf("Expected the keychain key, got %s", cfg.ComicVineAPIKey)
+	}
+
+	t.Setenv(envComicVineAPIKey, "env-cv-key")
+	cfg.LoadFromEnv()
+	if cfg.ComicVineAPIKey != "env-cv-key" {
+		t.Errorf("Expected the environment to override the keychain, got %s", cfg.ComicVineAPIKey)
+	}
+
+	// A missing entry or an unavailable keychain keeps the file's key
+	t.Setenv(envComicVineAPIKey, "")
+	delete(kc, KeychainComicVineAccount)
+	cfg.ComicVineAPIKey = "file-cv-key"
+	cfg.LoadFromEnv()
+	if cfg.ComicVineAPIKey != "file-cv-key" {
+		t.Errorf("Expected the file key without a keychain entry, got %s", cfg.ComicVineAPIKey)
+	}
+	openKeychain = func() (keychain.Keychain, error) { return nil, errors.New("locked") }
+	cfg.LoadFromEnv()
+	if cfg.ComicVineAPIKey != "file-cv-key" {
+		t.Errorf("Expected the file key with the keychain unavailable, got %s", cfg.ComicVineAPIKey)
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -75,6 +137,60 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "OpenAI Without Anthropic Key",
+			config: &Config{
+				LLMProvider:     "openai",
+				LLMModel:        "llama3.1",
+				ComicVineAPIKey: "key2",
+			},
+			wantErr: false,
+		},
+		{
+			name: "OpenAI Missing Model",
+			config: &Config{
+				LLMProvider:     "openai",
+				ComicVineAPIKey: "key2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown LLM Provider",
+			config: &Config{
+				AnthropicAPIKey: "key1",
+				ComicVineAPIKey: "key2",
+				LLMProvider:     "gemini",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Metron Without ComicVine Key",
+			config: &Config{
+				AnthropicAPIKey:  "key1",
+				MetadataProvider: "metron",
+				MetronUsername:   "user",
+				MetronPassword:   "pass",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid Log Level",
+			config: &Config{
+				AnthropicAPIKey: "key1",
+				ComicVineAPIKey: "key2",
+				LogLevel:        "warn,nosuch=debug",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Metron Missing Password",
+			config: &Config{
+				AnthropicAPIKey:  "key1",
+				MetadataProvider: "metron",
+				MetronUsername:   "user",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -86,3 +202,101 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateKomga(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{"API Key", &Config{KomgaURL: "http://komga:25600", KomgaAPIKey: "key"}, false},
+		{"Username And Password", &Config{KomgaURL: "http://komga:25600", KomgaUsername: "user", KomgaPassword: "pass"}, false},
+		{"Missing URL", &Config{KomgaAPIKey: "key"}, true},
+		{"Missing Password", &Config{KomgaURL: "http://komga:25600", KomgaUsername: "user"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.ValidateKomga()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKomga() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateTVDB(t *testing.T) {
+	if err := (&Config{}).ValidateTVDB(); err == nil {
+		t.Error("Expected an error without an API key")
+	}
+	if err := (&Config{TVDBAPIKey: "key"}).ValidateTVDB(); err != nil {
+		t.Errorf("ValidateTVDB() error = %v", err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	t.Setenv(envAnthropicAPIKey, "")
+	t.Setenv(envComicVineAPIKey, "")
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(cfg *Config) {
+		t.Helper()
+		if err := cfg.SaveConfig(path); err != nil {
+			t.Fatalf("SaveConfig failed: %v", err)
+		}
+	}
+
+	initial := DefaultConfig()
+	initial.AnthropicAPIKey = "a1"
+	initial.ComicVineAPIKey = "c1"
+	initial.Verbose = true
+	write(initial)
+
+	r := NewReloader(path, initial)
+	var notified int
+	r.OnChange(func(old, updated *Config) { notified++ })
+
+	if changes, err := r.Reload(); err != nil || len(changes) != 0 {
+		t.Fatalf("Reload of unchanged file = %v, %v", changes, err)
+	}
+
+	next := *initial
+	next.AnthropicAPIKey = "a2"
+	next.WorkerCount = 8
+	write(&next)
+
+	changes, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	joined := strings.Join(changes, "\n")
+	if !strings.Contains(joined, "worker_count: 3 -> 8") || !strings.Contains(joined, "anthropic_api_key: changed") {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+	if strings.Contains(joined, "a2") {
+		t.Errorf("Changes leak an API key: %v", changes)
+	}
+	if notified != 1 || r.Current().WorkerCount != 8 || !r.Current().Verbose {
+		t.Errorf("Reload not applied: notified=%d cfg=%+v", notified, r.Current())
+	}
+
+	// Invalid configurations are rejected and the current one is kept
+	invalid := next
+	invalid.ComicVineAPIKey = ""
+	write(&invalid)
+	if _, err := r.Reload(); err == nil {
+		t.Error("Expected invalid config to be rejected")
+	}
+	if r.Current().ComicVineAPIKey != "c1" {
+		t.Error("Invalid config was applied")
+	}
+
+	// Overrides from the command line are applied to reloads again
+	r.OnLoad(func(cfg *Config) { cfg.ComicVineAPIKey = "c1" })
+	if _, err := r.Reload(); err != nil {
+		t.Fatalf("Reload with override failed: %v", err)
+	}
+	if r.Current().ComicVineAPIKey != "c1" || r.Current().WorkerCount != 8 {
+		t.Errorf("Override not applied: %+v", r.Current())
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"syscall"
+
+	"comic-parser/internal/logging"
+)
+
+// secretFields are settings whose values are never logged
+var secretFields = map[string]bool{
+	"anthropic_api_key": true,
+	"comicvine_api_key": true,
+	"komga_api_key":     true,
+	"komga_password":    true,
+	"metron_password":   true,
+	"notify_url":        true,
+	"openai_api_key":    true,
+	"tvdb_api_key":      true,
+	"tvdb_pin":          true,
+}
+
+// Diff describes the settings that differ between old and updated, one
+// "name: old -> new" entry per setting. API keys are reported as changed
+// without revealing their values.
+func Diff(old, updated *Config) []string {
+	var changes []string
+
+	ov, nv := reflect.ValueOf(*old), reflect.ValueOf(*updated)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		a, b := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		if secretFields[name] {
+			changes = append(changes, name+": changed")
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, a, b))
+	}
+	return changes
+}
+
+// Reloader keeps the current configuration for long-running modes and
+// reloads it from disk on request or on SIGHUP.
+type Reloader struct {
+	path string
+
+	mu        sync.RWMutex
+	current   *Config
+	listeners []func(old, updated *Config)
+	adjust    func(*Config)
+
+	logger *slog.Logger
+}
+
+// NewReloader creates a Reloader for the config file at path, starting from initial.
+func NewReloader(path string, initial *Config) *Reloader {
+	return &Reloader{path: path, current: initial, logger: logging.Logger(logging.Config)}
+}
+
+// Current returns the active configuration. Callers must not modify it.
+func (r *Reloader) Current() *Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.current
+}
+
+// OnChange registers fn to be called after a reload changes the configuration.
+func (r *Reloader) OnChange(fn func(old, updated *Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// OnLoad registers fn to adjust each reloaded configuration before it is
+// validated, such as to apply command-line overrides again.
+func (r *Reloader) OnLoad(fn func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.adjust = fn
+}
+
+// Reload re-reads the config file and environment. The new configuration is
+// only applied if it validates; settings that only exist at runtime (verbose,
+// interactive) are carried over. It returns the list of changes.
+func (r *Reloader) Reload() ([]string, error) {
+	updated, err := LoadConfig(r.path)
+	if err != nil {
+		return nil, err
+	}
+	updated.LoadFromEnv()
+	r.mu.RLock()
+	adjust := r.adjust
+	r.mu.RUnlock()
+	if adjust != nil {
+		adjust(updated)
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration, keeping current settings: %w", err)
+	}
+
+	r.mu.Lock()
+	old := r.current
+	updated.Verbose = old.Verbose
+	updated.Interactive = old.Interactive
+	changes := Diff(old, updated)
+	if len(changes) == 0 {
+		r.mu.Unlock()
+		return nil, nil
+	}
+	r.current = updated
+	listeners := append([]func(old, updated *Config){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(old, updated)
+	}
+	return changes, nil
+}
+
+// WatchSignals reloads the configuration on every SIGHUP until ctx is done,
+// logging exactly what changed or why the reload was rejected.
+func (r *Reloader) WatchSignals(ctx context.Context) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			changes, err := r.Reload()
+			switch {
+			case err != nil:
+				r.logger.Error("config reload failed", "path", r.path, "error", err)
+			case len(changes) == 0:
+				r.logger.Info("config reloaded, no changes", "path", r.path)
+			default:
+				r.logger.Info("config reloaded", "path", r.path, "changes", strings.Join(changes, "; "))
+			}
+		}
+	}
+}
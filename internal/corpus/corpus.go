@@ -0,0 +1,228 @@
+// Package corpus defines a portable, versioned export format for parsed
+// filenames and verified matches, so corpora can be moved between machines
+// or shared for parser tuning. Exports never contain local paths or API keys.
+package corpus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+const (
+	// FormatName identifies corpus files.
+	FormatName = "comic-parser-corpus"
+	// Version is the current corpus format version. Imports reject newer versions.
+	Version = 1
+
+	// importedReasoning is stored as the match reasoning for imported matches
+	importedReasoning = "Imported from corpus"
+	// importedProvenance marks imported matches as not made locally
+	importedProvenance = "corpus"
+)
+
+// Corpus is the top-level export document.
+type Corpus struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Parses     []Parse   `json:"parses"`
+	Matches    []Match   `json:"matches"`
+}
+
+// Parse is a stored filename parse.
+type Parse struct {
+	Filename     string `json:"filename"`
+	Parser       string `json:"parser"`
+	Title        string `json:"title"`
+	IssueNumber  string `json:"issue_number"`
+	Year         string `json:"year,omitempty"`
+	Publisher    string `json:"publisher,omitempty"`
+	VolumeNumber string `json:"volume_number,omitempty"`
+	Confidence   string `json:"confidence"`
+	Notes        string `json:"notes,omitempty"`
+	Language     string `json:"language,omitempty"`
+}
+
+// Match is a filename resolved to a ComicVine issue.
+type Match struct {
+	Filename    string `json:"filename"`
+	Confidence  string `json:"confidence"`
+	ComicVineID int    `json:"comicvine_id"`
+	IssueName   string `json:"issue_name,omitempty"`
+	IssueNumber string `json:"issue_number"`
+	CoverDate   string `json:"cover_date,omitempty"`
+	URL         string `json:"url,omitempty"`
+	VolumeID    int    `json:"volume_id"`
+	VolumeName  string `json:"volume_name"`
+	Publisher   string `json:"publisher,omitempty"`
+}
+
+// ExportOptions controls which records are exported.
+type ExportOptions struct {
+	// MatchConfidences lists the match confidences to include. Empty means
+	// only "high" confidence matches, which are treated as verified.
+	MatchConfidences []string
+	// Language limits the export to original releases, translated ones or
+	// one language, as models.MatchesLanguage filters. Empty means all.
+	Language string
+}
+
+// Build assembles a corpus from the records in store.
+func Build(ctx context.Context, store *storage.Storage, opts ExportOptions) (*Corpus, error) {
+	confidences := opts.MatchConfidences
+	if len(confidences) == 0 {
+		confidences = []string{"high"}
+	}
+	allowed := make(map[string]bool, len(confidences))
+	for _, c := range confidences {
+		allowed[c] = true
+	}
+
+	c := &Corpus{
+		Format:     FormatName,
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		Parses:     []Parse{},
+		Matches:    []Match{},
+	}
+
+	records, err := store.ListParsedRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		p := r.Parsed
+		if !models.MatchesLanguage(p.Language, opts.Language) {
+			continue
+		}
+		c.Parses = append(c.Parses, Parse{
+			Filename:     filepath.Base(p.OriginalFilename),
+			Parser:       r.ParserName,
+			Title:        p.Title,
+			IssueNumber:  p.IssueNumber,
+			Year:         p.Year,
+			Publisher:    p.Publisher,
+			VolumeNumber: p.VolumeNumber,
+			Confidence:   p.Confidence,
+			Notes:        p.Notes,
+			Language:     p.Language,
+		})
+	}
+
+	results, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if !allowed[r.Match.MatchConfidence] || !models.MatchesLanguage(r.Match.ParsedInfo.Language, opts.Language) {
+			continue
+		}
+		issue := r.Match.SelectedIssue
+		c.Matches = append(c.Matches, Match{
+			Filename:    filepath.Base(r.Filename),
+			Confidence:  r.Match.MatchConfidence,
+			ComicVineID: issue.ID,
+			IssueName:   issue.Name,
+			IssueNumber: issue.IssueNumber,
+			CoverDate:   issue.CoverDate.String(),
+			URL:         issue.SiteDetailURL,
+			VolumeID:    issue.Volume.ID,
+			VolumeName:  issue.Volume.Name,
+			Publisher:   issue.Volume.Publisher,
+		})
+	}
+
+	return c, nil
+}
+
+// Export writes the corpus for store to w as JSON.
+func Export(ctx context.Context, store *storage.Storage, w io.Writer, opts ExportOptions) (*Corpus, error) {
+	c, err := Build(ctx, store, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return nil, fmt.Errorf("encoding corpus: %w", err)
+	}
+	return c, nil
+}
+
+// Read decodes and validates a corpus document.
+func Read(r io.Reader) (*Corpus, error) {
+	var c Corpus
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decoding corpus: %w", err)
+	}
+	if c.Format != FormatName {
+		return nil, fmt.Errorf("not a corpus file (format %q)", c.Format)
+	}
+	if c.Version < 1 || c.Version > Version {
+		return nil, fmt.Errorf("unsupported corpus version %d (supported: 1-%d)", c.Version, Version)
+	}
+	return &c, nil
+}
+
+// Import stores the parses and matches of c. Matches are written first so
+// that parse entries for the same filename take precedence.
+func Import(ctx context.Context, store *storage.Storage, c *Corpus) error {
+	for _, m := range c.Matches {
+		result := &models.ProcessingResult{
+			Filename:    m.Filename,
+			Success:     true,
+			ProcessedAt: c.ExportedAt,
+			Match: &models.MatchResult{
+				OriginalFilename: m.Filename,
+				ParsedInfo:       models.ParsedFilename{OriginalFilename: m.Filename},
+				MatchConfidence:  m.Confidence,
+				Reasoning:        importedReasoning,
+				Provenance:       importedProvenance,
+				ComicVineID:      m.ComicVineID,
+				ComicVineURL:     m.URL,
+				SelectedIssue: &models.ComicVineIssue{
+					ID:            m.ComicVineID,
+					Name:          m.IssueName,
+					IssueNumber:   m.IssueNumber,
+					CoverDate:     models.ParseDateLenient(m.CoverDate),
+					SiteDetailURL: m.URL,
+					Volume: models.VolumeRef{
+						ID:        m.VolumeID,
+						Name:      m.VolumeName,
+						Publisher: m.Publisher,
+					},
+				},
+			},
+		}
+		if err := store.SaveResult(ctx, result); err != nil {
+			return fmt.Errorf("importing match for %s: %w", m.Filename, err)
+		}
+	}
+
+	for _, p := range c.Parses {
+		parsed := &models.ParsedFilename{
+			OriginalFilename: p.Filename,
+			Title:            p.Title,
+			IssueNumber:      p.IssueNumber,
+			Year:             p.Year,
+			Publisher:        p.Publisher,
+			VolumeNumber:     p.VolumeNumber,
+			Confidence:       p.Confidence,
+			Notes:            p.Notes,
+			Language:         p.Language,
+		}
+		if err := store.SaveParsedFilename(ctx, parsed, p.Parser); err != nil {
+			return fmt.Errorf("importing parse for %s: %w", p.Filename, err)
+		}
+	}
+
+	return nil
+}
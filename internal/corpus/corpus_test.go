@@ -0,0 +1,276 @@
+package corpus
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+func newStore(t *testing.T) *storage.Storage {
+	t.Helper()
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "comics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := newStore(t)
+
+	parsed := &models.ParsedFilename{
+		OriginalFilename: "/home/user/comics/Saga 001 (2012).cbz",
+		Title:            "Saga",
+		IssueNumber:      "001",
+		Year:             "2012",
+		Confidence:       "high",
+	}
+	if err := src.SaveParsedFilename(ctx, parsed, "regex"); err != nil {
+		t.Fatalf("SaveParsedFilename: %v", err)
+	}
+
+	for _, m := range []struct {
+		filename   string
+		confidence string
+		id         int
+	}{
+		{"/home/user/comics/Saga 002 (2012).cbz", "high", 2},
+		{"/home/user/comics/Saga 003 (2012).cbz", "low", 3},
+	} {
+		result := &models.ProcessingResult{
+			Filename:    m.filename,
+			Success:     true,
+			ProcessedAt: time.Now(),
+			Match: &models.MatchResult{
+				ParsedInfo:      models.ParsedFilename{OriginalFilename: m.filename, Title: "Saga"},
+				MatchConfidence: m.confidence,
+				SelectedIssue: &models.ComicVineIssue{
+					ID:          m.id,
+					IssueNumber: "2",
+					Volume:      models.VolumeRef{ID: 100, Name: "Saga", Publisher: "Image"},
+				},
+			},
+		}
+		if err := src.SaveResult(ctx, result); err != nil {
+			t.Fatalf("SaveResult: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	c, err := Export(ctx, src, &buf, ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if strings.Contains(buf.String(), "/home/user") {
+		t.Errorf("export contains local paths:\n%s", buf.String())
+	}
+	if len(c.Matches) != 1 || c.Matches[0].ComicVineID != 2 {
+		t.Errorf("expected only the high confidence match, got %+v", c.Matches)
+	}
+
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	dst := newStore(t)
+	if err := Import(ctx, dst, read); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	got, err := Build(ctx, dst, ExportOptions{})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(got.Matches) != 1 || got.Matches[0] != c.Matches[0] {
+		t.Errorf("matches after round trip = %+v, want %+v", got.Matches, c.Matches)
+	}
+
+	var found bool
+	for _, p := range got.Parses {
+		if p.Filename == "Saga 001 (2012).cbz" {
+			found = true
+			if p.Parser != "regex" || p.Year != "2012" {
+				t.Errorf("unexpected parse after round trip: %+v", p)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("parse missing after round trip: %+v", got.Parses)
+	}
+}
+
+func TestBuildLanguage(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	for _, p := range []*models.ParsedFilename{
+		{OriginalFilename: "Saga 001 (2012).cbz", Title: "Saga", IssueNumber: "1", Confidence: "high"},
+		{OriginalFilename: "Saga 001 (2012) (French).cbz", Title: "Saga", IssueNumber: "1", Confidence: "high", Language: "fr"},
+		{OriginalFilename: "Saga 001 (2012) [RUS].cbz", Title: "Saga", IssueNumber: "1", Confidence: "high", Language: "ru"},
+	} {
+		if err := store.SaveParsedFilename(ctx, p, "regex"); err != nil {
+			t.Fatalf("SaveParsedFilename: %v", err)
+		}
+	}
+
+	tests := map[string][]string{
+		"":           {"Saga 001 (2012) (French).cbz", "Saga 001 (2012) [RUS].cbz", "Saga 001 (2012).cbz"},
+		"original":   {"Saga 001 (2012).cbz"},
+		"translated": {"Saga 001 (2012) (French).cbz", "Saga 001 (2012) [RUS].cbz"},
+		"fr":         {"Saga 001 (2012) (French).cbz"},
+	}
+	for language, want := range tests {
+		c, err := Build(ctx, store, ExportOptions{Language: language})
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		var got []string
+		for _, p := range c.Parses {
+			got = append(got, p.Filename)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("Build(language %q) parses = %v, want %v", language, got, want)
+		}
+	}
+}
+
+func TestReadRejectsUnsupported(t *testing.T) {
+	tests := map[string]string{
+		"wrong format":  `{"format":"other","version":1}`,
+		"newer version": `{"format":"comic-parser-corpus","version":99}`,
+		"invalid json":  `{`,
+	}
+	for name, input := range tests {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestFromTable(t *testing.T) {
+	csvData := "Series Name,Number,Year,Publisher,Comic Vine ID,Volume ID,Cover Date\n" +
+		"Saga,1,2012,Image,1001,100,2012-03-14\n" +
+		"Saga,#2,2012,Image,,,\n" +
+		",3,2012,Image,,,\n" +
+		"Saga,4,twelve,Image,,,\n" +
+		"Saga,5,2012,Image,abc,100,\n" +
+		"Saga,6,2012,Image,1006,,\n" +
+		"Saga,1,2012,Image,,,\n"
+	table, err := ReadCSV(strings.NewReader("\ufeff" + csvData))
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if table.Columns[0] != "Series Name" || len(table.Rows) != 7 {
+		t.Fatalf("unexpected table %+v", table)
+	}
+
+	explicit, err := ParseMapping("Series=Series Name, issue=Number, comicvine_id=Comic Vine ID")
+	if err != nil {
+		t.Fatalf("ParseMapping: %v", err)
+	}
+	mapping := GuessMapping(table.Columns).Merge(explicit)
+	want := "series=Series Name,issue=Number,year=Year,publisher=Publisher,cover_date=Cover Date,comicvine_id=Comic Vine ID,volume_id=Volume ID"
+	if mapping.String() != want {
+		t.Errorf("mapping = %s, want %s", mapping, want)
+	}
+	if err := mapping.Validate(table.Columns); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	c, problems := FromTable(table, mapping)
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Error())
+	}
+	wantProblems := []string{
+		"row 3: missing series",
+		`row 4: year "twelve" is not a year`,
+		`row 5: comicvine_id "abc" is not an ID`,
+		"row 6: a match needs both comicvine_id and volume_id",
+		`row 7: "Saga #1 (2012)" is already imported from row 1`,
+	}
+	if strings.Join(got, "\n") != strings.Join(wantProblems, "\n") {
+		t.Errorf("problems = %q, want %q", got, wantProblems)
+	}
+	if len(c.Parses) != 2 || c.Parses[1].Filename != "Saga #2 (2012)" || c.Parses[1].IssueNumber != "2" || c.Parses[1].Parser != "import" {
+		t.Errorf("unexpected parses %+v", c.Parses)
+	}
+	if len(c.Matches) != 1 || c.Matches[0].ComicVineID != 1001 || c.Matches[0].VolumeID != 100 || c.Matches[0].Confidence != "high" {
+		t.Errorf("unexpected matches %+v", c.Matches)
+	}
+
+	store := newStore(t)
+	if err := Import(context.Background(), store, c); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	matched, err := store.ListMatchedResults(context.Background())
+	if err != nil || len(matched) != 1 || matched[0].Match.SelectedIssue.Volume.Name != "Saga" {
+		t.Errorf("expected the imported match, got %+v, %v", matched, err)
+	}
+
+	if err := (Mapping{FieldSeries: "Series Name"}).Validate(table.Columns); err == nil {
+		t.Error("expected an error without an issue column")
+	}
+	if err := (Mapping{FieldSeries: "Title", FieldIssue: "Number"}).Validate(table.Columns); err == nil {
+		t.Error("expected an error for a missing column")
+	}
+	if _, err := ParseMapping("writer=Author"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestReadJSONTable(t *testing.T) {
+	table, err := ReadJSONTable(strings.NewReader(`[
+		{"series": "Bone", "issue": 1, "year": null},
+		{"series": "Bone", "issue": "2", "owned": true}
+	]`))
+	if err != nil {
+		t.Fatalf("ReadJSONTable: %v", err)
+	}
+	if strings.Join(table.Columns, ",") != "issue,owned,series,year" {
+		t.Errorf("unexpected columns %v", table.Columns)
+	}
+	if table.Rows[0]["issue"] != "1" || table.Rows[0]["year"] != "" || table.Rows[1]["owned"] != "true" {
+		t.Errorf("unexpected rows %+v", table.Rows)
+	}
+	if _, err := ReadJSONTable(strings.NewReader(`[{"series": {"name": "Bone"}}]`)); err == nil {
+		t.Error("expected an error for a nested value")
+	}
+	if !IsCorpus([]byte(` {"format": "comic-parser-corpus"}`)) || IsCorpus([]byte(`[{}]`)) {
+		t.Error("IsCorpus misclassified its input")
+	}
+}
+
+func TestPromptMapping(t *testing.T) {
+	columns := []string{"Title", "No.", "Year", "Publisher"}
+	guess := GuessMapping(columns)
+	// series: a missing column is asked again; issue: typed; year: default;
+	// publisher and title (guessed as Title): skipped; the rest keep their
+	// empty defaults
+	input := "Name\nTitle\nNo.\n\n-\n\n-\n" + strings.Repeat("\n", len(Fields)-6)
+	var out bytes.Buffer
+	m, err := PromptMapping(strings.NewReader(input), &out, columns, guess)
+	if err != nil {
+		t.Fatalf("PromptMapping: %v", err)
+	}
+	if m.String() != "series=Title,issue=No.,year=Year" {
+		t.Errorf("mapping = %s", m)
+	}
+	if !strings.Contains(out.String(), `No column "Name"`) {
+		t.Errorf("expected a complaint about the missing column:\n%s", out.String())
+	}
+	if _, err := PromptMapping(strings.NewReader("Title\n"), &out, columns, guess); err == nil {
+		t.Error("expected an error when input ends early")
+	}
+}
@@ -0,0 +1,390 @@
+package corpus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// Fields a table column can be mapped to. Series and issue are required;
+// rows with a ComicVine issue and volume ID also become matches.
+const (
+	FieldFilename    = "filename"
+	FieldSeries      = "series"
+	FieldIssue       = "issue"
+	FieldYear        = "year"
+	FieldPublisher   = "publisher"
+	FieldVolume      = "volume"
+	FieldTitle       = "title"
+	FieldCoverDate   = "cover_date"
+	FieldComicVineID = "comicvine_id"
+	FieldVolumeID    = "volume_id"
+	FieldURL         = "url"
+	FieldConfidence  = "confidence"
+)
+
+// Fields lists the fields a table column can be mapped to, in prompt order.
+var Fields = []string{
+	FieldSeries, FieldIssue, FieldYear, FieldPublisher, FieldVolume, FieldTitle,
+	FieldFilename, FieldCoverDate, FieldComicVineID, FieldVolumeID, FieldURL, FieldConfidence,
+}
+
+// tableParser is the parser name stored with parses imported from a table
+const tableParser = "import"
+
+// Table is a spreadsheet read from CSV or JSON, with every value as text.
+type Table struct {
+	Columns []string
+	Rows    []map[string]string
+}
+
+// ReadCSV reads a CSV table whose first row names the columns.
+func ReadCSV(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("reading csv: no header row")
+	}
+
+	t := &Table{}
+	for _, column := range records[0] {
+		t.Columns = append(t.Columns, strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+	}
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(t.Columns))
+		for i, column := range t.Columns {
+			if i < len(record) {
+				row[column] = strings.TrimSpace(record[i])
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t, nil
+}
+
+// ReadJSONTable reads a JSON array of flat objects. Numbers and booleans are
+// kept as written; nested values are not supported.
+func ReadJSONTable(r io.Reader) (*Table, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("decoding json table: %w", err)
+	}
+
+	t := &Table{}
+	seen := make(map[string]bool)
+	for i, object := range objects {
+		row := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				row[key] = strings.TrimSpace(v)
+			case json.Number, bool:
+				row[key] = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("decoding json table: row %d: %q is not a flat value", i+1, key)
+			}
+			if !seen[key] {
+				seen[key] = true
+				t.Columns = append(t.Columns, key)
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	sort.Strings(t.Columns)
+	return t, nil
+}
+
+// IsCorpus reports whether data holds a corpus document, as opposed to a
+// JSON table: a corpus is an object, a table an array.
+func IsCorpus(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// Mapping maps fields to the table columns holding them.
+type Mapping map[string]string
+
+// ParseMapping parses a mapping such as "series=Series Name,issue=Number".
+// Field names are case-insensitive.
+func ParseMapping(spec string) (Mapping, error) {
+	m := make(Mapping)
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("mapping %q: want field=column", pair)
+		}
+		if !knownField(field) {
+			return nil, fmt.Errorf("mapping %q: unknown field %q (fields: %s)", pair, field, strings.Join(Fields, ", "))
+		}
+		m[field] = column
+	}
+	return m, nil
+}
+
+// GuessMapping maps each field to the column of the same name, ignoring case,
+// spaces, dashes and underscores, so "Cover Date" maps to cover_date.
+func GuessMapping(columns []string) Mapping {
+	m := make(Mapping)
+	for _, field := range Fields {
+		for _, column := range columns {
+			if foldColumn(column) == foldColumn(field) {
+				m[field] = column
+				break
+			}
+		}
+	}
+	return m
+}
+
+// Merge returns m with the fields of other added or replaced.
+func (m Mapping) Merge(other Mapping) Mapping {
+	merged := make(Mapping, len(m)+len(other))
+	for field, column := range m {
+		merged[field] = column
+	}
+	for field, column := range other {
+		merged[field] = column
+	}
+	return merged
+}
+
+// Validate checks that every mapped column is in columns and that the
+// required fields are mapped.
+func (m Mapping) Validate(columns []string) error {
+	have := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		have[column] = true
+	}
+	for _, field := range Fields {
+		if column, ok := m[field]; ok && !have[column] {
+			return fmt.Errorf("field %s is mapped to missing column %q (columns: %s)", field, column, strings.Join(columns, ", "))
+		}
+	}
+	for _, field := range []string{FieldSeries, FieldIssue} {
+		if m[field] == "" {
+			return fmt.Errorf("no column is mapped to %s (use -map %s=<column>)", field, field)
+		}
+	}
+	return nil
+}
+
+// String formats m like ParseMapping's input, in field order.
+func (m Mapping) String() string {
+	var pairs []string
+	for _, field := range Fields {
+		if column, ok := m[field]; ok {
+			pairs = append(pairs, field+"="+column)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// PromptMapping asks for the column of each field on out, reading answers
+// from in and offering the columns of guess as defaults. An empty answer
+// keeps the default and "-" leaves the field unmapped.
+func PromptMapping(in io.Reader, out io.Writer, columns []string, guess Mapping) (Mapping, error) {
+	fmt.Fprintf(out, "Columns: %s\n", strings.Join(columns, ", "))
+	fmt.Fprintln(out, "Enter the column of each field; enter keeps the default in brackets, - skips the field.")
+
+	have := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		have[column] = true
+	}
+	reader := bufio.NewReader(in)
+	m := make(Mapping)
+	for _, field := range Fields {
+		for {
+			fmt.Fprintf(out, "%s [%s]: ", field, guess[field])
+			input, err := reader.ReadString('\n')
+			if err != nil && input == "" {
+				if err == io.EOF {
+					return nil, fmt.Errorf("mapping prompt: input ended")
+				}
+				return nil, fmt.Errorf("mapping prompt: %w", err)
+			}
+			answer := strings.TrimSpace(input)
+			switch {
+			case answer == "":
+				answer = guess[field]
+			case answer == "-":
+				answer = ""
+			case !have[answer]:
+				fmt.Fprintf(out, "No column %q.\n", answer)
+				continue
+			}
+			if answer != "" {
+				m[field] = answer
+			}
+			break
+		}
+	}
+	return m, nil
+}
+
+// RowError reports a table row that could not be imported. Row counts data
+// rows from 1.
+type RowError struct {
+	Row int
+	Err string
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Err)
+}
+
+// FromTable converts the rows of t into a corpus using m, which must be
+// valid for t. Every valid row becomes a parse, stored under its filename or,
+// without one, under a name built from series, issue and year; rows with a
+// ComicVine issue and volume ID also become matches, with high confidence
+// unless a confidence is mapped. Invalid rows are left out and reported.
+func FromTable(t *Table, m Mapping) (*Corpus, []RowError) {
+	c := &Corpus{
+		Format:     FormatName,
+		Version:    Version,
+		ExportedAt: time.Now().UTC(),
+		Parses:     []Parse{},
+		Matches:    []Match{},
+	}
+	var problems []RowError
+	rows := make(map[string]int)
+	for i, row := range t.Rows {
+		n := i + 1
+		get := func(field string) string {
+			if column, ok := m[field]; ok {
+				return row[column]
+			}
+			return ""
+		}
+
+		series, issue, year := get(FieldSeries), strings.TrimPrefix(get(FieldIssue), "#"), get(FieldYear)
+		var missing []string
+		if series == "" {
+			missing = append(missing, FieldSeries)
+		}
+		if issue == "" {
+			missing = append(missing, FieldIssue)
+		}
+		if len(missing) > 0 {
+			problems = append(problems, RowError{n, "missing " + strings.Join(missing, " and ")})
+			continue
+		}
+		if year != "" {
+			if y, err := strconv.Atoi(year); err != nil || y < 1800 || y > 2200 {
+				problems = append(problems, RowError{n, fmt.Sprintf("year %q is not a year", year)})
+				continue
+			}
+		}
+		ids := make(map[string]int)
+		var bad string
+		for _, field := range []string{FieldComicVineID, FieldVolumeID} {
+			value := get(field)
+			if value == "" {
+				continue
+			}
+			id, err := strconv.Atoi(value)
+			if err != nil || id <= 0 {
+				bad = fmt.Sprintf("%s %q is not an ID", field, value)
+				break
+			}
+			ids[field] = id
+		}
+		if bad != "" {
+			problems = append(problems, RowError{n, bad})
+			continue
+		}
+		if date := get(FieldCoverDate); date != "" && models.ParseDateLenient(date).IsZero() {
+			problems = append(problems, RowError{n, fmt.Sprintf("cover_date %q is not a date", date)})
+			continue
+		}
+		if (ids[FieldComicVineID] == 0) != (ids[FieldVolumeID] == 0) {
+			problems = append(problems, RowError{n, "a match needs both comicvine_id and volume_id"})
+			continue
+		}
+
+		filename := get(FieldFilename)
+		if filename == "" {
+			filename = fmt.Sprintf("%s #%s", series, issue)
+			if year != "" {
+				filename += " (" + year + ")"
+			}
+		}
+		if first, ok := rows[filename]; ok {
+			problems = append(problems, RowError{n, fmt.Sprintf("%q is already imported from row %d", filename, first)})
+			continue
+		}
+		rows[filename] = n
+
+		c.Parses = append(c.Parses, Parse{
+			Filename:     filename,
+			Parser:       tableParser,
+			Title:        series,
+			IssueNumber:  issue,
+			Year:         year,
+			Publisher:    get(FieldPublisher),
+			VolumeNumber: get(FieldVolume),
+			Confidence:   "high",
+		})
+		if ids[FieldComicVineID] == 0 {
+			continue
+		}
+		confidence := strings.ToLower(get(FieldConfidence))
+		if confidence == "" {
+			confidence = "high"
+		}
+		c.Matches = append(c.Matches, Match{
+			Filename:    filename,
+			Confidence:  confidence,
+			ComicVineID: ids[FieldComicVineID],
+			IssueName:   get(FieldTitle),
+			IssueNumber: issue,
+			CoverDate:   get(FieldCoverDate),
+			URL:         get(FieldURL),
+			VolumeID:    ids[FieldVolumeID],
+			VolumeName:  series,
+			Publisher:   get(FieldPublisher),
+		})
+	}
+	return c, problems
+}
+
+func knownField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// foldColumn lowercases a column name and drops spaces, dashes and
+// underscores.
+func foldColumn(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
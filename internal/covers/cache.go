@@ -0,0 +1,188 @@
+// Package covers downloads ComicVine cover images into a local cache keyed by
+// ComicVine issue ID, so previews work offline and without refetching.
+package covers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+const (
+	// cacheSubdir keeps covers apart from other users of the cache dir
+	cacheSubdir = "covers"
+
+	userAgentValue  = "ComicParser/1.0"
+	headerUserAgent = "User-Agent"
+
+	// defaultExt is used when an image URL has no recognizable extension
+	defaultExt = ".jpg"
+)
+
+// Size is one of the cover sizes ComicVine provides.
+type Size string
+
+// Cover sizes, matching the URLs of models.ImageRef
+const (
+	Small  Size = "small"
+	Medium Size = "medium"
+	Large  Size = "large"
+)
+
+// Sizes lists every cover size, smallest first.
+var Sizes = []Size{Small, Medium, Large}
+
+// imageExts are the extensions kept from image URLs
+var imageExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
+
+// HTTPClient defines the interface for making HTTP requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Cache stores cover images on disk as <dir>/covers/<issue ID>/<size><ext>.
+type Cache struct {
+	dir        string
+	httpClient HTTPClient
+}
+
+// NewCache creates a cover cache under dir, typically the configured cache
+// directory.
+func NewCache(dir string, httpClient HTTPClient) *Cache {
+	return &Cache{dir: filepath.Join(dir, cacheSubdir), httpClient: httpClient}
+}
+
+// Lookup returns the path of the cached cover of an issue in the given size.
+func (c *Cache) Lookup(issueID int, size Size) (string, bool) {
+	matches, _ := filepath.Glob(filepath.Join(c.issueDir(issueID), string(size)+".*"))
+	for _, match := range matches {
+		if imageExts[filepath.Ext(match)] {
+			return match, true
+		}
+	}
+	return "", false
+}
+
+// Download fetches the covers of issue that are not cached yet and reports
+// how many it downloaded. Sizes without a URL are skipped.
+func (c *Cache) Download(ctx context.Context, issue *models.ComicVineIssue) (int, error) {
+	downloaded := 0
+	for _, size := range Sizes {
+		imageURL := sizeURL(issue.Image, size)
+		if imageURL == "" {
+			continue
+		}
+		if _, ok := c.Lookup(issue.ID, size); ok {
+			continue
+		}
+		if err := c.fetch(ctx, imageURL, filepath.Join(c.issueDir(issue.ID), string(size)+urlExt(imageURL))); err != nil {
+			return downloaded, fmt.Errorf("downloading %s cover of issue %d: %w", size, issue.ID, err)
+		}
+		downloaded++
+	}
+	return downloaded, nil
+}
+
+// Prune deletes the cached covers of every issue keep reports false for and
+// returns how many issues' covers were (or, with dryRun, would be) removed.
+// A missing cache is not an error.
+func (c *Cache) Prune(keep func(issueID int) bool, dryRun bool) (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		id, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() || keep(id) {
+			continue
+		}
+		if !dryRun {
+			if err := os.RemoveAll(filepath.Join(c.dir, entry.Name())); err != nil {
+				return removed, err
+			}
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (c *Cache) issueDir(issueID int) string {
+	return filepath.Join(c.dir, strconv.Itoa(issueID))
+}
+
+// fetch downloads imageURL to dest. The write goes through a temp file so
+// readers never see a partial image.
+func (c *Cache) fetch(ctx context.Context, imageURL, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set(headerUserAgent, userAgentValue)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("image server returned status %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// sizeURL returns the URL of the cover in the given size.
+func sizeURL(image models.ImageRef, size Size) string {
+	switch size {
+	case Small:
+		return image.SmallURL
+	case Medium:
+		return image.MediumURL
+	case Large:
+		return image.LargeURL
+	}
+	return ""
+}
+
+// urlExt returns the image extension of imageURL's path.
+func urlExt(imageURL string) string {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return defaultExt
+	}
+	if ext := strings.ToLower(path.Ext(u.Path)); imageExts[ext] {
+		return ext
+	}
+	return defaultExt
+}
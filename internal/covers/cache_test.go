@@ -0,0 +1,95 @@
+package covers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"comic-parser/internal/models"
+)
+
+func TestCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get(headerUserAgent) == "" {
+			t.Error("Expected a User-Agent header")
+		}
+		if r.URL.Path == "/missing.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("image " + r.URL.Path))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	cache := NewCache(dir, ts.Client())
+	ctx := context.Background()
+
+	issue := &models.ComicVineIssue{
+		ID: 42,
+		Image: models.ImageRef{
+			SmallURL:  ts.URL + "/scale_small/42.jpg",
+			MediumURL: ts.URL + "/scale_medium/42.PNG?api=1",
+			LargeURL:  ts.URL + "/scale_large/42",
+		},
+	}
+	n, err := cache.Download(ctx, issue)
+	if err != nil || n != 3 {
+		t.Fatalf("Download() = %d, %v; want 3 images", n, err)
+	}
+
+	for size, want := range map[Size]string{Small: "small.jpg", Medium: "medium.png", Large: "large.jpg"} {
+		path, ok := cache.Lookup(42, size)
+		if !ok || filepath.Base(path) != want {
+			t.Errorf("Lookup(42, %s) = %q, %v; want %s", size, path, ok, want)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "covers", "42", "small.jpg")); string(data) != "image /scale_small/42.jpg" {
+		t.Errorf("Unexpected cached image %q", data)
+	}
+
+	// Cached sizes are not fetched again
+	if n, err := cache.Download(ctx, issue); err != nil || n != 0 || requests != 3 {
+		t.Errorf("Expected no new downloads, got %d (%v) after %d requests", n, err, requests)
+	}
+
+	if _, err := cache.Download(ctx, &models.ComicVineIssue{ID: 7, Image: models.ImageRef{SmallURL: ts.URL + "/missing.jpg"}}); err == nil {
+		t.Error("Expected an error for a missing image")
+	}
+	if _, ok := cache.Lookup(7, Small); ok {
+		t.Error("Expected no cached cover after a failed download")
+	}
+
+	other := &models.ComicVineIssue{ID: 43, Image: models.ImageRef{SmallURL: ts.URL + "/43.jpg"}}
+	if _, err := cache.Download(ctx, other); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	keep := func(id int) bool { return id == 42 }
+	if removed, err := cache.Prune(keep, true); err != nil || removed != 1 {
+		t.Errorf("Prune(dry run) = %d, %v; want 1", removed, err)
+	}
+	if _, ok := cache.Lookup(43, Small); !ok {
+		t.Error("Expected a dry run to keep the covers")
+	}
+	if removed, err := cache.Prune(keep, false); err != nil || removed != 1 {
+		t.Errorf("Prune() = %d, %v; want 1", removed, err)
+	}
+	if _, ok := cache.Lookup(43, Small); ok {
+		t.Error("Expected the unreferenced covers to be removed")
+	}
+	if _, ok := cache.Lookup(42, Small); !ok {
+		t.Error("Expected the referenced covers to be kept")
+	}
+}
+
+func TestPruneMissingCache(t *testing.T) {
+	cache := NewCache(filepath.Join(t.TempDir(), "none"), http.DefaultClient)
+	if removed, err := cache.Prune(func(int) bool { return false }, false); err != nil || removed != 0 {
+		t.Errorf("Prune() = %d, %v; want 0, nil", removed, err)
+	}
+}
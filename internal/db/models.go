@@ -9,26 +9,157 @@ import (
 	"time"
 )
 
+type ArchiveCheck struct {
+	Path      string
+	PageCount int64
+	Health    string
+	Problems  sql.NullString
+	CheckedAt time.Time
+}
+
+type BatchCheckpoint struct {
+	RunID     int64
+	Filename  string
+	State     string
+	Error     sql.NullString
+	UpdatedAt time.Time
+}
+
+type BatchRun struct {
+	ID              int64
+	StartedAt       time.Time
+	FinishedAt      sql.NullTime
+	Mode            string
+	InputSource     string
+	ParserName      sql.NullString
+	Total           int64
+	Processed       int64
+	Successful      int64
+	Failed          int64
+	Skipped         int64
+	Settings        sql.NullString
+	LlmInputTokens  int64
+	LlmOutputTokens int64
+	LlmCost         float64
+}
+
+type Collection struct {
+	ID                int64
+	Filename          string
+	Path              sql.NullString
+	Success           bool
+	Error             sql.NullString
+	Format            sql.NullString
+	VolumeName        sql.NullString
+	ComicvineVolumeID sql.NullInt64
+	MatchConfidence   sql.NullString
+	Parsed            sql.NullString
+	Volume            sql.NullString
+	ProcessedAt       time.Time
+}
+
 type ComicVineIssue struct {
-	ID             int64
-	VolumeID       int64
-	Name           sql.NullString
-	IssueNumber    sql.NullString
-	CoverDate      sql.NullString
-	StoreDate      sql.NullString
-	Description    sql.NullString
-	SiteDetailUrl  sql.NullString
-	ImageSmallUrl  sql.NullString
-	ImageMediumUrl sql.NullString
-	ImageLargeUrl  sql.NullString
+	ID               int64
+	VolumeID         int64
+	Name             sql.NullString
+	IssueNumber      sql.NullString
+	CoverDate        sql.NullString
+	StoreDate        sql.NullString
+	Description      sql.NullString
+	SiteDetailUrl    sql.NullString
+	ImageSmallUrl    sql.NullString
+	ImageMediumUrl   sql.NullString
+	ImageLargeUrl    sql.NullString
+	Credits          sql.NullString
+	Characters       sql.NullString
+	Teams            sql.NullString
+	Locations        sql.NullString
+	StoryArcs        sql.NullString
+	DetailsFetchedAt sql.NullTime
 }
 
 type ComicVineVolume struct {
-	ID            int64
-	Name          string
-	StartYear     sql.NullString
-	PublisherName sql.NullString
-	SiteDetailUrl sql.NullString
+	ID              int64
+	Name            string
+	StartYear       sql.NullString
+	PublisherName   sql.NullString
+	SiteDetailUrl   sql.NullString
+	PublisherID     sql.NullInt64
+	CountOfIssues   sql.NullInt64
+	FetchedAt       sql.NullTime
+	IssuesFetchedAt sql.NullTime
+}
+
+type ComicvineMapping struct {
+	Kind        string
+	Key         string
+	ComicvineID int64
+	Source      string
+	ImportedAt  time.Time
+}
+
+type Episode struct {
+	ID              int64
+	Filename        string
+	Path            sql.NullString
+	Success         bool
+	Error           sql.NullString
+	SeriesName      sql.NullString
+	TvdbSeriesID    sql.NullInt64
+	TvdbEpisodeID   sql.NullInt64
+	MatchConfidence sql.NullString
+	Parsed          sql.NullString
+	Episode         sql.NullString
+	ProcessedAt     time.Time
+}
+
+type FileMove struct {
+	ID          int64
+	OperationID int64
+	Filename    string
+	OldPath     string
+	NewPath     string
+	MovedAt     time.Time
+	UndoneAt    sql.NullTime
+}
+
+type IgnoreRule struct {
+	Pattern   string
+	CreatedAt time.Time
+}
+
+type LlmUsage struct {
+	ID           int64
+	RunID        sql.NullInt64
+	Filename     string
+	Kind         string
+	Provider     string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+	CreatedAt    time.Time
+}
+
+type MatchCorrection struct {
+	ID                   int64
+	Filename             string
+	Title                string
+	IssueNumber          string
+	Year                 string
+	OriginalComicvineID  sql.NullInt64
+	ComicvineID          sql.NullInt64
+	VolumeName           string
+	VolumeStartYear      string
+	CorrectedIssueNumber string
+	Source               string
+	CreatedAt            time.Time
+}
+
+type MatchQueue struct {
+	ID       int64
+	Filename string
+	QueuedAt time.Time
 }
 
 type ParsedFilename struct {
@@ -43,6 +174,12 @@ type ParsedFilename struct {
 	VolumeNumber       sql.NullString
 	Confidence         string
 	Notes              sql.NullString
+	RunID              sql.NullInt64
+	Special            sql.NullString
+	Path               sql.NullString
+	Chapter            sql.NullString
+	IssueType          sql.NullString
+	Language           sql.NullString
 }
 
 type ProcessingResult struct {
@@ -56,4 +193,99 @@ type ProcessingResult struct {
 	Reasoning        sql.NullString
 	ComicvineID      sql.NullInt64
 	ComicvineUrl     sql.NullString
+	RunID            sql.NullInt64
+	Provenance       sql.NullString
+	Path             sql.NullString
+	SourceFilename   sql.NullString
+	CreatedAt        sql.NullTime
+	DeletedAt        sql.NullTime
+	ErrorCode        sql.NullString
+	FileHash         sql.NullString
+	FileSize         sql.NullInt64
+}
+
+type ReadingList struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+type ReadingListEntry struct {
+	ListID      int64
+	Position    int64
+	ComicvineID int64
+}
+
+type RecordEdit struct {
+	ID       int64
+	Filename string
+	Field    string
+	OldValue string
+	NewValue string
+	Source   string
+	EditedAt time.Time
+}
+
+type ReviewQueue struct {
+	ID         int64
+	RunID      sql.NullInt64
+	Filename   string
+	Result     string
+	Candidates string
+	Status     string
+	QueuedAt   time.Time
+	ReviewedAt sql.NullTime
+}
+
+type RunProgress struct {
+	RunID     int64
+	Snapshot  string
+	UpdatedAt time.Time
+}
+
+type RunResult struct {
+	RunID           int64
+	Filename        string
+	Success         bool
+	Error           sql.NullString
+	Title           sql.NullString
+	IssueNumber     sql.NullString
+	Year            sql.NullString
+	MatchConfidence sql.NullString
+	ComicvineID     sql.NullInt64
+	ErrorCode       sql.NullString
+}
+
+type SearchDocument struct {
+	ID          int64
+	Filename    string
+	Series      string
+	Title       string
+	Description string
+}
+
+type SearchSource struct {
+	Filename    string
+	Series      interface{}
+	Title       interface{}
+	Description interface{}
+}
+
+type SeriesAlias struct {
+	Key       string
+	Name      string
+	Series    string
+	VolumeID  sql.NullInt64
+	CreatedAt time.Time
+}
+
+type VolumeSearch struct {
+	Query     string
+	FetchedAt time.Time
+}
+
+type VolumeSearchResult struct {
+	Query    string
+	Position int64
+	VolumeID int64
 }
@@ -1,13 +1,15 @@
 -- name: UpsertVolume :exec
 INSERT INTO comic_vine_volumes (
-    id, name, start_year, publisher_name, site_detail_url
+    id, name, start_year, publisher_name, site_detail_url, publisher_id, count_of_issues
 ) VALUES (
-    ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT(id) DO UPDATE SET
     name = excluded.name,
-    start_year = excluded.start_year,
-    publisher_name = excluded.publisher_name,
-    site_detail_url = excluded.site_detail_url;
+    start_year = COALESCE(excluded.start_year, comic_vine_volumes.start_year),
+    publisher_name = COALESCE(excluded.publisher_name, comic_vine_volumes.publisher_name),
+    site_detail_url = COALESCE(excluded.site_detail_url, comic_vine_volumes.site_detail_url),
+    publisher_id = COALESCE(excluded.publisher_id, comic_vine_volumes.publisher_id),
+    count_of_issues = COALESCE(excluded.count_of_issues, comic_vine_volumes.count_of_issues);
 
 -- name: UpsertIssue :exec
 INSERT INTO comic_vine_issues (
@@ -21,7 +23,7 @@ INSERT INTO comic_vine_issues (
     issue_number = excluded.issue_number,
     cover_date = excluded.cover_date,
     store_date = excluded.store_date,
-    description = excluded.description,
+    description = COALESCE(excluded.description, comic_vine_issues.description),
     site_detail_url = excluded.site_detail_url,
     image_small_url = excluded.image_small_url,
     image_medium_url = excluded.image_medium_url,
@@ -29,19 +31,28 @@ INSERT INTO comic_vine_issues (
 
 -- name: UpsertProcessingResult :one
 INSERT INTO processing_results (
-    filename, success, error, processed_at, processing_time_ms,
-    match_confidence, reasoning, comicvine_id, comicvine_url
+    filename, success, error, error_code, processed_at, processing_time_ms,
+    match_confidence, reasoning, comicvine_id, comicvine_url, run_id, provenance, path,
+    source_filename, created_at, file_hash, file_size
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT(filename) DO UPDATE SET
     success = excluded.success,
     error = excluded.error,
+    error_code = excluded.error_code,
     processed_at = excluded.processed_at,
     processing_time_ms = excluded.processing_time_ms,
     match_confidence = excluded.match_confidence,
     reasoning = excluded.reasoning,
     comicvine_id = excluded.comicvine_id,
-    comicvine_url = excluded.comicvine_url
+    comicvine_url = excluded.comicvine_url,
+    run_id = excluded.run_id,
+    provenance = excluded.provenance,
+    path = excluded.path,
+    source_filename = excluded.source_filename,
+    file_hash = COALESCE(excluded.file_hash, file_hash),
+    file_size = COALESCE(excluded.file_size, file_size),
+    deleted_at = NULL
 RETURNING id;
 
 -- name: DeleteParsedFilenamesByResultID :exec
@@ -50,9 +61,10 @@ DELETE FROM parsed_filenames WHERE processing_result_id = ?;
 -- name: CreateParsedFilename :exec
 INSERT INTO parsed_filenames (
     processing_result_id, parser_name, original_filename, title, issue_number, year,
-    publisher, volume_number, confidence, notes
+    publisher, volume_number, confidence, notes, run_id, special, path, chapter,
+    issue_type, language
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT(original_filename, parser_name) DO UPDATE SET
     processing_result_id = excluded.processing_result_id,
     title = excluded.title,
@@ -61,10 +73,581 @@ INSERT INTO parsed_filenames (
     publisher = excluded.publisher,
     volume_number = excluded.volume_number,
     confidence = excluded.confidence,
-    notes = excluded.notes;
+    notes = excluded.notes,
+    run_id = excluded.run_id,
+    special = excluded.special,
+    path = excluded.path,
+    chapter = excluded.chapter,
+    issue_type = excluded.issue_type,
+    language = excluded.language;
 
 -- name: GetProcessingResult :one
 SELECT * FROM processing_results WHERE filename = ?;
 
 -- name: ListParsedFilenames :many
-SELECT * FROM parsed_filenames ORDER BY id DESC;
+SELECT * FROM parsed_filenames
+WHERE original_filename NOT IN (SELECT filename FROM processing_results WHERE deleted_at IS NOT NULL)
+ORDER BY id DESC;
+
+-- name: CreateBatchRun :one
+INSERT INTO batch_runs (
+    started_at, mode, input_source, parser_name, total, settings
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+RETURNING id;
+
+-- name: FinishBatchRun :exec
+UPDATE batch_runs SET
+    finished_at = ?,
+    processed = ?,
+    successful = ?,
+    failed = ?,
+    skipped = ?,
+    llm_input_tokens = ?,
+    llm_output_tokens = ?,
+    llm_cost = ?
+WHERE id = ?;
+
+-- name: ReopenBatchRun :exec
+UPDATE batch_runs SET finished_at = NULL WHERE id = ?;
+
+-- name: ListUnfinishedBatchRuns :many
+SELECT * FROM batch_runs WHERE finished_at IS NULL ORDER BY id DESC;
+
+-- name: GetBatchRun :one
+SELECT * FROM batch_runs WHERE id = ?;
+
+-- name: ListBatchRuns :many
+SELECT * FROM batch_runs ORDER BY id DESC;
+
+-- name: CountParsedFilenamesByRun :one
+SELECT count(*) FROM parsed_filenames WHERE run_id = ?;
+
+-- name: CountProcessingResultsByRun :one
+SELECT count(*) FROM processing_results WHERE run_id = ?;
+
+-- name: UpsertRunResult :exec
+INSERT INTO run_results (
+    run_id, filename, success, error, error_code, title, issue_number, year,
+    match_confidence, comicvine_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(run_id, filename) DO UPDATE SET
+    success = excluded.success,
+    error = excluded.error,
+    error_code = excluded.error_code,
+    title = excluded.title,
+    issue_number = excluded.issue_number,
+    year = excluded.year,
+    match_confidence = excluded.match_confidence,
+    comicvine_id = excluded.comicvine_id;
+
+-- name: ListRunResults :many
+SELECT * FROM run_results WHERE run_id = ? ORDER BY filename;
+
+-- name: ListKnownFilenames :many
+SELECT original_filename AS filename FROM parsed_filenames
+WHERE original_filename NOT IN (SELECT filename FROM processing_results WHERE deleted_at IS NOT NULL)
+UNION
+SELECT filename FROM processing_results WHERE deleted_at IS NULL
+ORDER BY filename;
+
+-- name: DeleteParsedFilenamesByFilename :exec
+DELETE FROM parsed_filenames WHERE original_filename = ?;
+
+-- name: DeleteProcessingResultByFilename :exec
+DELETE FROM processing_results WHERE filename = ?;
+
+-- name: RenameParsedFilenames :exec
+UPDATE OR REPLACE parsed_filenames SET original_filename = sqlc.arg(new_filename), path = sqlc.narg(new_path) WHERE original_filename = sqlc.arg(old_filename);
+
+-- name: RenameProcessingResult :exec
+UPDATE OR REPLACE processing_results SET filename = sqlc.arg(new_filename), path = sqlc.narg(new_path) WHERE filename = sqlc.arg(old_filename);
+
+-- name: DeleteOrphanedIssues :execrows
+DELETE FROM comic_vine_issues
+WHERE id NOT IN (
+    SELECT comicvine_id FROM processing_results WHERE comicvine_id IS NOT NULL
+)
+AND id NOT IN (SELECT comicvine_id FROM comicvine_mappings)
+AND id NOT IN (SELECT comicvine_id FROM reading_list_entries);
+
+-- name: DeleteOrphanedVolumes :execrows
+DELETE FROM comic_vine_volumes
+WHERE id NOT IN (SELECT volume_id FROM comic_vine_issues);
+
+-- name: ListMatchedResults :many
+SELECT
+    pr.filename, pr.path, pr.processed_at, pr.match_confidence, pr.reasoning, pr.provenance,
+    pr.source_filename,
+    i.id AS issue_id, i.name AS issue_name, i.issue_number, i.cover_date, i.store_date,
+    i.site_detail_url, i.image_small_url, i.image_medium_url, i.image_large_url,
+    i.description, i.credits, i.characters, i.teams, i.locations, i.story_arcs,
+    v.id AS volume_id, v.name AS volume_name, v.publisher_name, v.site_detail_url AS volume_url,
+    v.start_year, v.publisher_id, v.count_of_issues, pf.language
+FROM processing_results pr
+JOIN comic_vine_issues i ON i.id = pr.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+LEFT JOIN parsed_filenames pf ON pf.processing_result_id = pr.id
+WHERE pr.success = 1 AND pr.deleted_at IS NULL
+ORDER BY pr.filename;
+
+-- name: UpsertMapping :exec
+INSERT INTO comicvine_mappings (
+    kind, key, comicvine_id, source, imported_at
+) VALUES (
+    ?, ?, ?, ?, ?
+) ON CONFLICT(kind, key) DO UPDATE SET
+    comicvine_id = excluded.comicvine_id,
+    source = excluded.source,
+    imported_at = excluded.imported_at;
+
+-- name: ListMappings :many
+SELECT
+    m.kind, m.key, m.source, m.imported_at,
+    i.id AS issue_id, i.name AS issue_name, i.issue_number, i.cover_date, i.store_date,
+    i.site_detail_url, i.image_small_url, i.image_medium_url, i.image_large_url,
+    v.id AS volume_id, v.name AS volume_name, v.publisher_name, v.site_detail_url AS volume_url,
+    v.start_year, v.publisher_id, v.count_of_issues
+FROM comicvine_mappings m
+JOIN comic_vine_issues i ON i.id = m.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+ORDER BY m.kind, m.key;
+
+-- name: UpsertCheckpoint :exec
+INSERT INTO batch_checkpoints (
+    run_id, filename, state, error, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?
+) ON CONFLICT(run_id, filename) DO UPDATE SET
+    state = excluded.state,
+    error = excluded.error,
+    updated_at = excluded.updated_at;
+
+-- name: ListPendingCheckpoints :many
+SELECT filename FROM batch_checkpoints
+WHERE run_id = ? AND state != 'done'
+ORDER BY rowid;
+
+-- name: CountCheckpointsByState :many
+SELECT state, count(*) AS count FROM batch_checkpoints
+WHERE run_id = ?
+GROUP BY state;
+
+-- name: FindResumableRun :one
+SELECT * FROM batch_runs
+WHERE mode = ? AND input_source = ?
+  AND EXISTS (
+    SELECT 1 FROM batch_checkpoints c
+    WHERE c.run_id = batch_runs.id AND c.state != 'done'
+  )
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: NextMoveOperation :one
+SELECT CAST(COALESCE(MAX(operation_id), 0) + 1 AS INTEGER) AS operation_id FROM file_moves;
+
+-- name: CreateFileMove :exec
+INSERT INTO file_moves (
+    operation_id, filename, old_path, new_path, moved_at
+) VALUES (
+    ?, ?, ?, ?, ?
+);
+
+-- name: ListLastMoveOperation :many
+SELECT * FROM file_moves
+WHERE undone_at IS NULL
+  AND operation_id = (SELECT MAX(operation_id) FROM file_moves WHERE undone_at IS NULL)
+ORDER BY id DESC;
+
+-- name: MarkFileMoveUndone :exec
+UPDATE file_moves SET undone_at = ? WHERE id = ?;
+
+-- name: CreateLLMUsage :exec
+INSERT INTO llm_usage (
+    run_id, filename, kind, provider, model, input_tokens, output_tokens, cost, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: SummarizeUsageByRun :many
+SELECT
+    u.run_id,
+    CAST(COALESCE(r.mode, '') AS TEXT) AS mode,
+    CAST(COALESCE(r.input_source, '') AS TEXT) AS input_source,
+    COUNT(*) AS calls,
+    CAST(SUM(u.input_tokens) AS INTEGER) AS input_tokens,
+    CAST(SUM(u.output_tokens) AS INTEGER) AS output_tokens,
+    CAST(SUM(u.cost) AS REAL) AS cost
+FROM llm_usage u
+LEFT JOIN batch_runs r ON r.id = u.run_id
+GROUP BY u.run_id
+ORDER BY u.run_id;
+
+-- name: SummarizeUsageByModel :many
+SELECT
+    provider, model, kind,
+    COUNT(*) AS calls,
+    CAST(SUM(input_tokens) AS INTEGER) AS input_tokens,
+    CAST(SUM(output_tokens) AS INTEGER) AS output_tokens,
+    CAST(SUM(cost) AS REAL) AS cost
+FROM llm_usage
+WHERE sqlc.arg(run_id) = 0 OR run_id = sqlc.arg(run_id)
+GROUP BY provider, model, kind
+ORDER BY provider, model, kind;
+
+-- name: UpsertReviewItem :exec
+INSERT INTO review_queue (
+    run_id, filename, result, candidates, status, queued_at
+) VALUES (
+    ?, ?, ?, ?, 'pending', ?
+) ON CONFLICT(filename) DO UPDATE SET
+    run_id = excluded.run_id,
+    result = excluded.result,
+    candidates = excluded.candidates,
+    status = 'pending',
+    queued_at = excluded.queued_at,
+    reviewed_at = NULL;
+
+-- name: ListPendingReviews :many
+SELECT * FROM review_queue
+WHERE status = 'pending'
+ORDER BY id;
+
+-- name: GetReviewItemByFilename :one
+SELECT * FROM review_queue WHERE filename = ?;
+
+-- name: ResolveReviewItem :exec
+UPDATE review_queue SET status = ?, result = ?, reviewed_at = ? WHERE id = ?;
+
+-- name: CountSearchDocuments :one
+SELECT count(*) FROM search_documents;
+
+-- name: IndexSearchDocuments :exec
+INSERT INTO search_documents (filename, series, title, description)
+SELECT filename, series, title, description FROM search_sources
+WHERE filename NOT IN (SELECT filename FROM search_documents);
+
+-- name: SearchDocuments :many
+SELECT * FROM search_documents
+WHERE filename LIKE sqlc.arg(pattern) ESCAPE '\'
+   OR series LIKE sqlc.arg(pattern) ESCAPE '\'
+   OR title LIKE sqlc.arg(pattern) ESCAPE '\'
+   OR description LIKE sqlc.arg(pattern) ESCAPE '\'
+ORDER BY filename;
+
+-- name: UpsertEpisode :exec
+INSERT INTO episodes (
+    filename, path, success, error, series_name, tvdb_series_id,
+    tvdb_episode_id, match_confidence, parsed, episode, processed_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(filename) DO UPDATE SET
+    path = excluded.path,
+    success = excluded.success,
+    error = excluded.error,
+    series_name = excluded.series_name,
+    tvdb_series_id = excluded.tvdb_series_id,
+    tvdb_episode_id = excluded.tvdb_episode_id,
+    match_confidence = excluded.match_confidence,
+    parsed = excluded.parsed,
+    episode = excluded.episode,
+    processed_at = excluded.processed_at;
+
+-- name: ListEpisodes :many
+SELECT * FROM episodes ORDER BY filename;
+
+-- name: UpsertCollection :exec
+INSERT INTO collections (
+    filename, path, success, error, format, volume_name,
+    comicvine_volume_id, match_confidence, parsed, volume, processed_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(filename) DO UPDATE SET
+    path = excluded.path,
+    success = excluded.success,
+    error = excluded.error,
+    format = excluded.format,
+    volume_name = excluded.volume_name,
+    comicvine_volume_id = excluded.comicvine_volume_id,
+    match_confidence = excluded.match_confidence,
+    parsed = excluded.parsed,
+    volume = excluded.volume,
+    processed_at = excluded.processed_at;
+
+-- name: ListCollections :many
+SELECT * FROM collections ORDER BY filename;
+
+-- name: CreateReadingList :one
+INSERT INTO reading_lists (name, created_at) VALUES (?, ?) RETURNING id;
+
+-- name: GetReadingList :one
+SELECT id, name, created_at FROM reading_lists WHERE name = ?;
+
+-- name: ListReadingLists :many
+SELECT rl.id, rl.name, rl.created_at, COUNT(e.comicvine_id) AS entry_count
+FROM reading_lists rl
+LEFT JOIN reading_list_entries e ON e.list_id = rl.id
+GROUP BY rl.id
+ORDER BY rl.name;
+
+-- name: DeleteReadingList :execrows
+DELETE FROM reading_lists WHERE name = ?;
+
+-- name: CreateReadingListEntry :exec
+INSERT INTO reading_list_entries (list_id, position, comicvine_id) VALUES (?, ?, ?);
+
+-- name: ListReadingListEntries :many
+SELECT
+    e.position,
+    i.id AS issue_id, i.name AS issue_name, i.issue_number, i.cover_date, i.site_detail_url,
+    v.id AS volume_id, v.name AS volume_name, v.start_year, v.publisher_name
+FROM reading_list_entries e
+JOIN comic_vine_issues i ON i.id = e.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE e.list_id = ?
+ORDER BY e.position;
+
+-- name: DeleteReadingListEntries :exec
+DELETE FROM reading_list_entries WHERE list_id = ?;
+
+-- name: UpsertSeriesAlias :exec
+INSERT INTO series_aliases (key, name, series, volume_id, created_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(key) DO UPDATE SET
+    name = excluded.name,
+    series = excluded.series,
+    volume_id = excluded.volume_id,
+    created_at = excluded.created_at;
+
+-- name: GetSeriesAlias :one
+SELECT * FROM series_aliases WHERE key = ?;
+
+-- name: ListSeriesAliases :many
+SELECT * FROM series_aliases ORDER BY name;
+
+-- name: DeleteSeriesAlias :execrows
+DELETE FROM series_aliases WHERE key = ?;
+
+-- name: CreateIgnoreRule :exec
+INSERT INTO ignore_rules (pattern, created_at) VALUES (?, ?)
+ON CONFLICT(pattern) DO NOTHING;
+
+-- name: ListIgnoreRules :many
+SELECT * FROM ignore_rules ORDER BY pattern;
+
+-- name: DeleteIgnoreRule :execrows
+DELETE FROM ignore_rules WHERE pattern = ?;
+
+-- name: GetResultComicVineID :one
+SELECT comicvine_id FROM processing_results WHERE id = ? AND deleted_at IS NULL;
+
+-- name: CountIssues :one
+SELECT COUNT(*) FROM comic_vine_issues WHERE id = ?;
+
+-- name: ListRematchCandidates :many
+SELECT filename, source_filename, success, match_confidence FROM processing_results
+WHERE (success = 0 OR match_confidence IN ('none', 'low')) AND deleted_at IS NULL
+ORDER BY filename;
+
+-- name: GetReviewItemResult :one
+SELECT result FROM review_queue WHERE id = ?;
+
+-- name: CreateMatchCorrection :exec
+INSERT INTO match_corrections (
+    filename, title, issue_number, year, original_comicvine_id, comicvine_id,
+    volume_name, volume_start_year, corrected_issue_number, source, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListMatchCorrections :many
+SELECT * FROM match_corrections
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: UpsertFetchedVolume :exec
+INSERT INTO comic_vine_volumes (
+    id, name, start_year, publisher_name, site_detail_url, publisher_id, count_of_issues, fetched_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(id) DO UPDATE SET
+    name = excluded.name,
+    start_year = COALESCE(excluded.start_year, comic_vine_volumes.start_year),
+    publisher_name = COALESCE(excluded.publisher_name, comic_vine_volumes.publisher_name),
+    site_detail_url = COALESCE(excluded.site_detail_url, comic_vine_volumes.site_detail_url),
+    publisher_id = COALESCE(excluded.publisher_id, comic_vine_volumes.publisher_id),
+    count_of_issues = COALESCE(excluded.count_of_issues, comic_vine_volumes.count_of_issues),
+    fetched_at = excluded.fetched_at;
+
+-- name: GetFetchedVolume :one
+SELECT id, name, start_year, publisher_name, site_detail_url, publisher_id, count_of_issues, fetched_at
+FROM comic_vine_volumes
+WHERE id = ? AND fetched_at IS NOT NULL;
+
+-- name: UpsertVolumeSearch :exec
+INSERT INTO volume_searches (query, fetched_at) VALUES (?, ?)
+ON CONFLICT(query) DO UPDATE SET fetched_at = excluded.fetched_at;
+
+-- name: GetVolumeSearch :one
+SELECT fetched_at FROM volume_searches WHERE query = ?;
+
+-- name: DeleteVolumeSearchResults :exec
+DELETE FROM volume_search_results WHERE query = ?;
+
+-- name: CreateVolumeSearchResult :exec
+INSERT INTO volume_search_results (query, position, volume_id) VALUES (?, ?, ?);
+
+-- name: ListVolumeSearchResults :many
+SELECT v.id, v.name, v.start_year, v.publisher_name, v.site_detail_url, v.publisher_id, v.count_of_issues
+FROM volume_search_results r
+JOIN comic_vine_volumes v ON v.id = r.volume_id
+WHERE r.query = ?
+ORDER BY r.position;
+
+-- name: DeleteVolumeSearches :exec
+DELETE FROM volume_searches;
+
+-- name: UpsertListedIssue :exec
+INSERT INTO comic_vine_issues (
+    id, volume_id, name, issue_number, cover_date, store_date,
+    site_detail_url, image_small_url, image_medium_url, image_large_url
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(id) DO UPDATE SET
+    volume_id = excluded.volume_id,
+    name = excluded.name,
+    issue_number = excluded.issue_number,
+    cover_date = excluded.cover_date,
+    store_date = excluded.store_date,
+    site_detail_url = excluded.site_detail_url,
+    image_small_url = COALESCE(excluded.image_small_url, comic_vine_issues.image_small_url),
+    image_medium_url = COALESCE(excluded.image_medium_url, comic_vine_issues.image_medium_url),
+    image_large_url = COALESCE(excluded.image_large_url, comic_vine_issues.image_large_url);
+
+-- name: SetVolumeIssuesFetchedAt :exec
+UPDATE comic_vine_volumes SET issues_fetched_at = ? WHERE id = ?;
+
+-- name: GetVolumeIssuesFetchedAt :one
+SELECT issues_fetched_at FROM comic_vine_volumes WHERE id = ?;
+
+-- name: ResetVolumeIssueLists :exec
+UPDATE comic_vine_volumes SET issues_fetched_at = NULL WHERE issues_fetched_at IS NOT NULL;
+
+-- name: ListVolumeIssues :many
+SELECT
+    i.id, i.name, i.issue_number, i.cover_date, i.store_date, i.site_detail_url,
+    i.image_small_url, i.image_medium_url, i.image_large_url,
+    v.name AS volume_name, v.site_detail_url AS volume_url
+FROM comic_vine_issues i
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE i.volume_id = ?
+ORDER BY i.id;
+
+-- name: SetIssueDetails :exec
+UPDATE comic_vine_issues SET
+    description = ?,
+    credits = ?,
+    characters = ?,
+    teams = ?,
+    locations = ?,
+    story_arcs = ?,
+    details_fetched_at = ?
+WHERE id = ?;
+
+-- name: ListMatchedIssueDetails :many
+SELECT DISTINCT i.id, i.details_fetched_at
+FROM comic_vine_issues i
+JOIN processing_results pr ON pr.comicvine_id = i.id
+WHERE pr.success = 1 AND pr.deleted_at IS NULL
+ORDER BY i.id;
+
+-- name: ListResultFacts :many
+SELECT pr.filename, pr.success, pr.match_confidence, pr.comicvine_id, pr.created_at, i.cover_date, v.publisher_name
+FROM processing_results pr
+LEFT JOIN comic_vine_issues i ON i.id = pr.comicvine_id
+LEFT JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE pr.deleted_at IS NULL;
+
+-- name: GetResultRef :one
+SELECT id, filename, deleted_at FROM processing_results WHERE id = ?;
+
+-- name: ListResultRefsBySeries :many
+SELECT pr.id, pr.filename, pr.deleted_at, i.issue_number
+FROM processing_results pr
+JOIN comic_vine_issues i ON i.id = pr.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE v.name = ? COLLATE NOCASE
+ORDER BY pr.filename;
+
+-- name: ListDeletedResults :many
+SELECT id, filename, deleted_at FROM processing_results
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at, filename;
+
+-- name: SetResultDeletedAt :execrows
+UPDATE processing_results SET deleted_at = ? WHERE id = ?;
+
+-- name: ListFileHashes :many
+SELECT filename, path, file_hash, file_size FROM processing_results
+WHERE file_hash IS NOT NULL AND deleted_at IS NULL
+ORDER BY filename;
+
+-- name: ListFilenamesByHash :many
+SELECT filename FROM processing_results
+WHERE file_hash = sqlc.arg(file_hash) AND filename != sqlc.arg(filename) AND deleted_at IS NULL
+ORDER BY filename;
+
+-- name: GetLatestParsedFilename :one
+SELECT * FROM parsed_filenames WHERE original_filename = ? ORDER BY id DESC LIMIT 1;
+
+-- name: UpdateParsedFields :exec
+UPDATE parsed_filenames SET
+    title = ?,
+    issue_number = ?,
+    year = ?,
+    publisher = ?,
+    volume_number = ?
+WHERE id = ?;
+
+-- name: CreateRecordEdit :exec
+INSERT INTO record_edits (
+    filename, field, old_value, new_value, source, edited_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListRecordEdits :many
+SELECT * FROM record_edits WHERE filename = ? ORDER BY id;
+
+-- name: EnqueueMatch :exec
+INSERT INTO match_queue (filename, queued_at) VALUES (?, ?)
+ON CONFLICT(filename) DO NOTHING;
+
+-- name: ListMatchQueue :many
+SELECT * FROM match_queue ORDER BY queued_at, id;
+
+-- name: DeleteMatchQueueEntry :exec
+DELETE FROM match_queue WHERE filename = ?;
+
+-- name: UpsertRunProgress :exec
+INSERT INTO run_progress (run_id, snapshot, updated_at) VALUES (?, ?, ?)
+ON CONFLICT(run_id) DO UPDATE SET
+    snapshot = excluded.snapshot,
+    updated_at = excluded.updated_at;
+
+-- name: GetRunProgress :one
+SELECT snapshot FROM run_progress WHERE run_id = ?;
+
+-- name: UpsertArchiveCheck :exec
+INSERT INTO archive_checks (path, page_count, health, problems, checked_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(path) DO UPDATE SET
+    page_count = excluded.page_count,
+    health = excluded.health,
+    problems = excluded.problems,
+    checked_at = excluded.checked_at;
+
+-- name: ListArchiveChecks :many
+SELECT * FROM archive_checks ORDER BY path;
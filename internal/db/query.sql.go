@@ -11,12 +11,240 @@ import (
 	"time"
 )
 
+const countCheckpointsByState = `-- name: CountCheckpointsByState :many
+SELECT state, count(*) AS count FROM batch_checkpoints
+WHERE run_id = ?
+GROUP BY state
+`
+
+type CountCheckpointsByStateRow struct {
+	State string
+	Count int64
+}
+
+func (q *Queries) CountCheckpointsByState(ctx context.Context, runID int64) ([]CountCheckpointsByStateRow, error) {
+	rows, err := q.db.QueryContext(ctx, countCheckpointsByState, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountCheckpointsByStateRow
+	for rows.Next() {
+		var i CountCheckpointsByStateRow
+		if err := rows.Scan(&i.State, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countIssues = `-- name: CountIssues :one
+SELECT COUNT(*) FROM comic_vine_issues WHERE id = ?
+`
+
+func (q *Queries) CountIssues(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countIssues, id)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countParsedFilenamesByRun = `-- name: CountParsedFilenamesByRun :one
+SELECT count(*) FROM parsed_filenames WHERE run_id = ?
+`
+
+func (q *Queries) CountParsedFilenamesByRun(ctx context.Context, runID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countParsedFilenamesByRun, runID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countProcessingResultsByRun = `-- name: CountProcessingResultsByRun :one
+SELECT count(*) FROM processing_results WHERE run_id = ?
+`
+
+func (q *Queries) CountProcessingResultsByRun(ctx context.Context, runID sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countProcessingResultsByRun, runID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchDocuments = `-- name: CountSearchDocuments :one
+SELECT count(*) FROM search_documents
+`
+
+func (q *Queries) CountSearchDocuments(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSearchDocuments)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBatchRun = `-- name: CreateBatchRun :one
+INSERT INTO batch_runs (
+    started_at, mode, input_source, parser_name, total, settings
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+RETURNING id
+`
+
+type CreateBatchRunParams struct {
+	StartedAt   time.Time
+	Mode        string
+	InputSource string
+	ParserName  sql.NullString
+	Total       int64
+	Settings    sql.NullString
+}
+
+func (q *Queries) CreateBatchRun(ctx context.Context, arg CreateBatchRunParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, createBatchRun,
+		arg.StartedAt,
+		arg.Mode,
+		arg.InputSource,
+		arg.ParserName,
+		arg.Total,
+		arg.Settings,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createFileMove = `-- name: CreateFileMove :exec
+INSERT INTO file_moves (
+    operation_id, filename, old_path, new_path, moved_at
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+`
+
+type CreateFileMoveParams struct {
+	OperationID int64
+	Filename    string
+	OldPath     string
+	NewPath     string
+	MovedAt     time.Time
+}
+
+func (q *Queries) CreateFileMove(ctx context.Context, arg CreateFileMoveParams) error {
+	_, err := q.db.ExecContext(ctx, createFileMove,
+		arg.OperationID,
+		arg.Filename,
+		arg.OldPath,
+		arg.NewPath,
+		arg.MovedAt,
+	)
+	return err
+}
+
+const createIgnoreRule = `-- name: CreateIgnoreRule :exec
+INSERT INTO ignore_rules (pattern, created_at) VALUES (?, ?)
+ON CONFLICT(pattern) DO NOTHING
+`
+
+type CreateIgnoreRuleParams struct {
+	Pattern   string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateIgnoreRule(ctx context.Context, arg CreateIgnoreRuleParams) error {
+	_, err := q.db.ExecContext(ctx, createIgnoreRule, arg.Pattern, arg.CreatedAt)
+	return err
+}
+
+const createLLMUsage = `-- name: CreateLLMUsage :exec
+INSERT INTO llm_usage (
+    run_id, filename, kind, provider, model, input_tokens, output_tokens, cost, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateLLMUsageParams struct {
+	RunID        sql.NullInt64
+	Filename     string
+	Kind         string
+	Provider     string
+	Model        string
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+	CreatedAt    time.Time
+}
+
+func (q *Queries) CreateLLMUsage(ctx context.Context, arg CreateLLMUsageParams) error {
+	_, err := q.db.ExecContext(ctx, createLLMUsage,
+		arg.RunID,
+		arg.Filename,
+		arg.Kind,
+		arg.Provider,
+		arg.Model,
+		arg.InputTokens,
+		arg.OutputTokens,
+		arg.Cost,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const createMatchCorrection = `-- name: CreateMatchCorrection :exec
+INSERT INTO match_corrections (
+    filename, title, issue_number, year, original_comicvine_id, comicvine_id,
+    volume_name, volume_start_year, corrected_issue_number, source, created_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateMatchCorrectionParams struct {
+	Filename             string
+	Title                string
+	IssueNumber          string
+	Year                 string
+	OriginalComicvineID  sql.NullInt64
+	ComicvineID          sql.NullInt64
+	VolumeName           string
+	VolumeStartYear      string
+	CorrectedIssueNumber string
+	Source               string
+	CreatedAt            time.Time
+}
+
+func (q *Queries) CreateMatchCorrection(ctx context.Context, arg CreateMatchCorrectionParams) error {
+	_, err := q.db.ExecContext(ctx, createMatchCorrection,
+		arg.Filename,
+		arg.Title,
+		arg.IssueNumber,
+		arg.Year,
+		arg.OriginalComicvineID,
+		arg.ComicvineID,
+		arg.VolumeName,
+		arg.VolumeStartYear,
+		arg.CorrectedIssueNumber,
+		arg.Source,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const createParsedFilename = `-- name: CreateParsedFilename :exec
 INSERT INTO parsed_filenames (
     processing_result_id, parser_name, original_filename, title, issue_number, year,
-    publisher, volume_number, confidence, notes
+    publisher, volume_number, confidence, notes, run_id, special, path, chapter,
+    issue_type, language
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT(original_filename, parser_name) DO UPDATE SET
     processing_result_id = excluded.processing_result_id,
     title = excluded.title,
@@ -25,67 +253,2402 @@ INSERT INTO parsed_filenames (
     publisher = excluded.publisher,
     volume_number = excluded.volume_number,
     confidence = excluded.confidence,
-    notes = excluded.notes
+    notes = excluded.notes,
+    run_id = excluded.run_id,
+    special = excluded.special,
+    path = excluded.path,
+    chapter = excluded.chapter,
+    issue_type = excluded.issue_type,
+    language = excluded.language
+`
+
+type CreateParsedFilenameParams struct {
+	ProcessingResultID sql.NullInt64
+	ParserName         string
+	OriginalFilename   string
+	Title              string
+	IssueNumber        string
+	Year               sql.NullString
+	Publisher          sql.NullString
+	VolumeNumber       sql.NullString
+	Confidence         string
+	Notes              sql.NullString
+	RunID              sql.NullInt64
+	Special            sql.NullString
+	Path               sql.NullString
+	Chapter            sql.NullString
+	IssueType          sql.NullString
+	Language           sql.NullString
+}
+
+func (q *Queries) CreateParsedFilename(ctx context.Context, arg CreateParsedFilenameParams) error {
+	_, err := q.db.ExecContext(ctx, createParsedFilename,
+		arg.ProcessingResultID,
+		arg.ParserName,
+		arg.OriginalFilename,
+		arg.Title,
+		arg.IssueNumber,
+		arg.Year,
+		arg.Publisher,
+		arg.VolumeNumber,
+		arg.Confidence,
+		arg.Notes,
+		arg.RunID,
+		arg.Special,
+		arg.Path,
+		arg.Chapter,
+		arg.IssueType,
+		arg.Language,
+	)
+	return err
+}
+
+const createReadingList = `-- name: CreateReadingList :one
+INSERT INTO reading_lists (name, created_at) VALUES (?, ?) RETURNING id
+`
+
+type CreateReadingListParams struct {
+	Name      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) CreateReadingList(ctx context.Context, arg CreateReadingListParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, createReadingList, arg.Name, arg.CreatedAt)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createReadingListEntry = `-- name: CreateReadingListEntry :exec
+INSERT INTO reading_list_entries (list_id, position, comicvine_id) VALUES (?, ?, ?)
+`
+
+type CreateReadingListEntryParams struct {
+	ListID      int64
+	Position    int64
+	ComicvineID int64
+}
+
+func (q *Queries) CreateReadingListEntry(ctx context.Context, arg CreateReadingListEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createReadingListEntry, arg.ListID, arg.Position, arg.ComicvineID)
+	return err
+}
+
+const createRecordEdit = `-- name: CreateRecordEdit :exec
+INSERT INTO record_edits (
+    filename, field, old_value, new_value, source, edited_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateRecordEditParams struct {
+	Filename string
+	Field    string
+	OldValue string
+	NewValue string
+	Source   string
+	EditedAt time.Time
+}
+
+func (q *Queries) CreateRecordEdit(ctx context.Context, arg CreateRecordEditParams) error {
+	_, err := q.db.ExecContext(ctx, createRecordEdit,
+		arg.Filename,
+		arg.Field,
+		arg.OldValue,
+		arg.NewValue,
+		arg.Source,
+		arg.EditedAt,
+	)
+	return err
+}
+
+const createVolumeSearchResult = `-- name: CreateVolumeSearchResult :exec
+INSERT INTO volume_search_results (query, position, volume_id) VALUES (?, ?, ?)
+`
+
+type CreateVolumeSearchResultParams struct {
+	Query    string
+	Position int64
+	VolumeID int64
+}
+
+func (q *Queries) CreateVolumeSearchResult(ctx context.Context, arg CreateVolumeSearchResultParams) error {
+	_, err := q.db.ExecContext(ctx, createVolumeSearchResult, arg.Query, arg.Position, arg.VolumeID)
+	return err
+}
+
+const deleteIgnoreRule = `-- name: DeleteIgnoreRule :execrows
+DELETE FROM ignore_rules WHERE pattern = ?
+`
+
+func (q *Queries) DeleteIgnoreRule(ctx context.Context, pattern string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteIgnoreRule, pattern)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMatchQueueEntry = `-- name: DeleteMatchQueueEntry :exec
+DELETE FROM match_queue WHERE filename = ?
+`
+
+func (q *Queries) DeleteMatchQueueEntry(ctx context.Context, filename string) error {
+	_, err := q.db.ExecContext(ctx, deleteMatchQueueEntry, filename)
+	return err
+}
+
+const deleteOrphanedIssues = `-- name: DeleteOrphanedIssues :execrows
+DELETE FROM comic_vine_issues
+WHERE id NOT IN (
+    SELECT comicvine_id FROM processing_results WHERE comicvine_id IS NOT NULL
+)
+AND id NOT IN (SELECT comicvine_id FROM comicvine_mappings)
+AND id NOT IN (SELECT comicvine_id FROM reading_list_entries)
+`
+
+func (q *Queries) DeleteOrphanedIssues(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrphanedIssues)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOrphanedVolumes = `-- name: DeleteOrphanedVolumes :execrows
+DELETE FROM comic_vine_volumes
+WHERE id NOT IN (SELECT volume_id FROM comic_vine_issues)
+`
+
+func (q *Queries) DeleteOrphanedVolumes(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrphanedVolumes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteParsedFilenamesByFilename = `-- name: DeleteParsedFilenamesByFilename :exec
+DELETE FROM parsed_filenames WHERE original_filename = ?
+`
+
+func (q *Queries) DeleteParsedFilenamesByFilename(ctx context.Context, originalFilename string) error {
+	_, err := q.db.ExecContext(ctx, deleteParsedFilenamesByFilename, originalFilename)
+	return err
+}
+
+const deleteParsedFilenamesByResultID = `-- name: DeleteParsedFilenamesByResultID :exec
+DELETE FROM parsed_filenames WHERE processing_result_id = ?
+`
+
+func (q *Queries) DeleteParsedFilenamesByResultID(ctx context.Context, processingResultID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, deleteParsedFilenamesByResultID, processingResultID)
+	return err
+}
+
+const deleteProcessingResultByFilename = `-- name: DeleteProcessingResultByFilename :exec
+DELETE FROM processing_results WHERE filename = ?
+`
+
+func (q *Queries) DeleteProcessingResultByFilename(ctx context.Context, filename string) error {
+	_, err := q.db.ExecContext(ctx, deleteProcessingResultByFilename, filename)
+	return err
+}
+
+const deleteReadingList = `-- name: DeleteReadingList :execrows
+DELETE FROM reading_lists WHERE name = ?
+`
+
+func (q *Queries) DeleteReadingList(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteReadingList, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteReadingListEntries = `-- name: DeleteReadingListEntries :exec
+DELETE FROM reading_list_entries WHERE list_id = ?
+`
+
+func (q *Queries) DeleteReadingListEntries(ctx context.Context, listID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteReadingListEntries, listID)
+	return err
+}
+
+const deleteSeriesAlias = `-- name: DeleteSeriesAlias :execrows
+DELETE FROM series_aliases WHERE key = ?
+`
+
+func (q *Queries) DeleteSeriesAlias(ctx context.Context, key string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSeriesAlias, key)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteVolumeSearchResults = `-- name: DeleteVolumeSearchResults :exec
+DELETE FROM volume_search_results WHERE query = ?
+`
+
+func (q *Queries) DeleteVolumeSearchResults(ctx context.Context, query string) error {
+	_, err := q.db.ExecContext(ctx, deleteVolumeSearchResults, query)
+	return err
+}
+
+const deleteVolumeSearches = `-- name: DeleteVolumeSearches :exec
+DELETE FROM volume_searches
+`
+
+func (q *Queries) DeleteVolumeSearches(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteVolumeSearches)
+	return err
+}
+
+const enqueueMatch = `-- name: EnqueueMatch :exec
+INSERT INTO match_queue (filename, queued_at) VALUES (?, ?)
+ON CONFLICT(filename) DO NOTHING
+`
+
+type EnqueueMatchParams struct {
+	Filename string
+	QueuedAt time.Time
+}
+
+func (q *Queries) EnqueueMatch(ctx context.Context, arg EnqueueMatchParams) error {
+	_, err := q.db.ExecContext(ctx, enqueueMatch, arg.Filename, arg.QueuedAt)
+	return err
+}
+
+const findResumableRun = `-- name: FindResumableRun :one
+SELECT id, started_at, finished_at, mode, input_source, parser_name, total, processed, successful, failed, skipped, settings, llm_input_tokens, llm_output_tokens, llm_cost FROM batch_runs
+WHERE mode = ? AND input_source = ?
+  AND EXISTS (
+    SELECT 1 FROM batch_checkpoints c
+    WHERE c.run_id = batch_runs.id AND c.state != 'done'
+  )
+ORDER BY id DESC
+LIMIT 1
+`
+
+type FindResumableRunParams struct {
+	Mode        string
+	InputSource string
+}
+
+func (q *Queries) FindResumableRun(ctx context.Context, arg FindResumableRunParams) (BatchRun, error) {
+	row := q.db.QueryRowContext(ctx, findResumableRun, arg.Mode, arg.InputSource)
+	var i BatchRun
+	err := row.Scan(
+		&i.ID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.Mode,
+		&i.InputSource,
+		&i.ParserName,
+		&i.Total,
+		&i.Processed,
+		&i.Successful,
+		&i.Failed,
+		&i.Skipped,
+		&i.Settings,
+		&i.LlmInputTokens,
+		&i.LlmOutputTokens,
+		&i.LlmCost,
+	)
+	return i, err
+}
+
+const finishBatchRun = `-- name: FinishBatchRun :exec
+UPDATE batch_runs SET
+    finished_at = ?,
+    processed = ?,
+    successful = ?,
+    failed = ?,
+    skipped = ?,
+    llm_input_tokens = ?,
+    llm_output_tokens = ?,
+    llm_cost = ?
+WHERE id = ?
+`
+
+type FinishBatchRunParams struct {
+	FinishedAt      sql.NullTime
+	Processed       int64
+	Successful      int64
+	Failed          int64
+	Skipped         int64
+	LlmInputTokens  int64
+	LlmOutputTokens int64
+	LlmCost         float64
+	ID              int64
+}
+
+func (q *Queries) FinishBatchRun(ctx context.Context, arg FinishBatchRunParams) error {
+	_, err := q.db.ExecContext(ctx, finishBatchRun,
+		arg.FinishedAt,
+		arg.Processed,
+		arg.Successful,
+		arg.Failed,
+		arg.Skipped,
+		arg.LlmInputTokens,
+		arg.LlmOutputTokens,
+		arg.LlmCost,
+		arg.ID,
+	)
+	return err
+}
+
+const getBatchRun = `-- name: GetBatchRun :one
+SELECT id, started_at, finished_at, mode, input_source, parser_name, total, processed, successful, failed, skipped, settings, llm_input_tokens, llm_output_tokens, llm_cost FROM batch_runs WHERE id = ?
+`
+
+func (q *Queries) GetBatchRun(ctx context.Context, id int64) (BatchRun, error) {
+	row := q.db.QueryRowContext(ctx, getBatchRun, id)
+	var i BatchRun
+	err := row.Scan(
+		&i.ID,
+		&i.StartedAt,
+		&i.FinishedAt,
+		&i.Mode,
+		&i.InputSource,
+		&i.ParserName,
+		&i.Total,
+		&i.Processed,
+		&i.Successful,
+		&i.Failed,
+		&i.Skipped,
+		&i.Settings,
+		&i.LlmInputTokens,
+		&i.LlmOutputTokens,
+		&i.LlmCost,
+	)
+	return i, err
+}
+
+const getFetchedVolume = `-- name: GetFetchedVolume :one
+SELECT id, name, start_year, publisher_name, site_detail_url, publisher_id, count_of_issues, fetched_at
+FROM comic_vine_volumes
+WHERE id = ? AND fetched_at IS NOT NULL
+`
+
+type GetFetchedVolumeRow struct {
+	ID            int64
+	Name          string
+	StartYear     sql.NullString
+	PublisherName sql.NullString
+	SiteDetailUrl sql.NullString
+	PublisherID   sql.NullInt64
+	CountOfIssues sql.NullInt64
+	FetchedAt     sql.NullTime
+}
+
+func (q *Queries) GetFetchedVolume(ctx context.Context, id int64) (GetFetchedVolumeRow, error) {
+	row := q.db.QueryRowContext(ctx, getFetchedVolume, id)
+	var i GetFetchedVolumeRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.StartYear,
+		&i.PublisherName,
+		&i.SiteDetailUrl,
+		&i.PublisherID,
+		&i.CountOfIssues,
+		&i.FetchedAt,
+	)
+	return i, err
+}
+
+const getLatestParsedFilename = `-- name: GetLatestParsedFilename :one
+SELECT id, processing_result_id, parser_name, original_filename, title, issue_number, year, publisher, volume_number, confidence, notes, run_id, special, path, chapter, issue_type, language FROM parsed_filenames WHERE original_filename = ? ORDER BY id DESC LIMIT 1
+`
+
+func (q *Queries) GetLatestParsedFilename(ctx context.Context, originalFilename string) (ParsedFilename, error) {
+	row := q.db.QueryRowContext(ctx, getLatestParsedFilename, originalFilename)
+	var i ParsedFilename
+	err := row.Scan(
+		&i.ID,
+		&i.ProcessingResultID,
+		&i.ParserName,
+		&i.OriginalFilename,
+		&i.Title,
+		&i.IssueNumber,
+		&i.Year,
+		&i.Publisher,
+		&i.VolumeNumber,
+		&i.Confidence,
+		&i.Notes,
+		&i.RunID,
+		&i.Special,
+		&i.Path,
+		&i.Chapter,
+		&i.IssueType,
+		&i.Language,
+	)
+	return i, err
+}
+
+const getProcessingResult = `-- name: GetProcessingResult :one
+SELECT id, filename, success, error, processed_at, processing_time_ms, match_confidence, reasoning, comicvine_id, comicvine_url, run_id, provenance, path, source_filename, created_at, deleted_at, error_code, file_hash, file_size FROM processing_results WHERE filename = ?
+`
+
+func (q *Queries) GetProcessingResult(ctx context.Context, filename string) (ProcessingResult, error) {
+	row := q.db.QueryRowContext(ctx, getProcessingResult, filename)
+	var i ProcessingResult
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.Success,
+		&i.Error,
+		&i.ProcessedAt,
+		&i.ProcessingTimeMs,
+		&i.MatchConfidence,
+		&i.Reasoning,
+		&i.ComicvineID,
+		&i.ComicvineUrl,
+		&i.RunID,
+		&i.Provenance,
+		&i.Path,
+		&i.SourceFilename,
+		&i.CreatedAt,
+		&i.DeletedAt,
+		&i.ErrorCode,
+		&i.FileHash,
+		&i.FileSize,
+	)
+	return i, err
+}
+
+const getReadingList = `-- name: GetReadingList :one
+SELECT id, name, created_at FROM reading_lists WHERE name = ?
+`
+
+func (q *Queries) GetReadingList(ctx context.Context, name string) (ReadingList, error) {
+	row := q.db.QueryRowContext(ctx, getReadingList, name)
+	var i ReadingList
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
+const getResultComicVineID = `-- name: GetResultComicVineID :one
+SELECT comicvine_id FROM processing_results WHERE id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) GetResultComicVineID(ctx context.Context, id int64) (sql.NullInt64, error) {
+	row := q.db.QueryRowContext(ctx, getResultComicVineID, id)
+	var comicvine_id sql.NullInt64
+	err := row.Scan(&comicvine_id)
+	return comicvine_id, err
+}
+
+const getResultRef = `-- name: GetResultRef :one
+SELECT id, filename, deleted_at FROM processing_results WHERE id = ?
+`
+
+type GetResultRefRow struct {
+	ID        int64
+	Filename  string
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) GetResultRef(ctx context.Context, id int64) (GetResultRefRow, error) {
+	row := q.db.QueryRowContext(ctx, getResultRef, id)
+	var i GetResultRefRow
+	err := row.Scan(&i.ID, &i.Filename, &i.DeletedAt)
+	return i, err
+}
+
+const getReviewItemByFilename = `-- name: GetReviewItemByFilename :one
+SELECT id, run_id, filename, result, candidates, status, queued_at, reviewed_at FROM review_queue WHERE filename = ?
+`
+
+func (q *Queries) GetReviewItemByFilename(ctx context.Context, filename string) (ReviewQueue, error) {
+	row := q.db.QueryRowContext(ctx, getReviewItemByFilename, filename)
+	var i ReviewQueue
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.Filename,
+		&i.Result,
+		&i.Candidates,
+		&i.Status,
+		&i.QueuedAt,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const getReviewItemResult = `-- name: GetReviewItemResult :one
+SELECT result FROM review_queue WHERE id = ?
+`
+
+func (q *Queries) GetReviewItemResult(ctx context.Context, id int64) (string, error) {
+	row := q.db.QueryRowContext(ctx, getReviewItemResult, id)
+	var result string
+	err := row.Scan(&result)
+	return result, err
+}
+
+const getRunProgress = `-- name: GetRunProgress :one
+SELECT snapshot FROM run_progress WHERE run_id = ?
+`
+
+func (q *Queries) GetRunProgress(ctx context.Context, runID int64) (string, error) {
+	row := q.db.QueryRowContext(ctx, getRunProgress, runID)
+	var snapshot string
+	err := row.Scan(&snapshot)
+	return snapshot, err
+}
+
+const getSeriesAlias = `-- name: GetSeriesAlias :one
+SELECT key, name, series, volume_id, created_at FROM series_aliases WHERE key = ?
+`
+
+func (q *Queries) GetSeriesAlias(ctx context.Context, key string) (SeriesAlias, error) {
+	row := q.db.QueryRowContext(ctx, getSeriesAlias, key)
+	var i SeriesAlias
+	err := row.Scan(
+		&i.Key,
+		&i.Name,
+		&i.Series,
+		&i.VolumeID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getVolumeIssuesFetchedAt = `-- name: GetVolumeIssuesFetchedAt :one
+SELECT issues_fetched_at FROM comic_vine_volumes WHERE id = ?
+`
+
+func (q *Queries) GetVolumeIssuesFetchedAt(ctx context.Context, id int64) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, getVolumeIssuesFetchedAt, id)
+	var issues_fetched_at sql.NullTime
+	err := row.Scan(&issues_fetched_at)
+	return issues_fetched_at, err
+}
+
+const getVolumeSearch = `-- name: GetVolumeSearch :one
+SELECT fetched_at FROM volume_searches WHERE query = ?
+`
+
+func (q *Queries) GetVolumeSearch(ctx context.Context, query string) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getVolumeSearch, query)
+	var fetched_at time.Time
+	err := row.Scan(&fetched_at)
+	return fetched_at, err
+}
+
+const indexSearchDocuments = `-- name: IndexSearchDocuments :exec
+INSERT INTO search_documents (filename, series, title, description)
+SELECT filename, series, title, description FROM search_sources
+WHERE filename NOT IN (SELECT filename FROM search_documents)
+`
+
+func (q *Queries) IndexSearchDocuments(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, indexSearchDocuments)
+	return err
+}
+
+const listArchiveChecks = `-- name: ListArchiveChecks :many
+SELECT path, page_count, health, problems, checked_at FROM archive_checks ORDER BY path
+`
+
+func (q *Queries) ListArchiveChecks(ctx context.Context) ([]ArchiveCheck, error) {
+	rows, err := q.db.QueryContext(ctx, listArchiveChecks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ArchiveCheck
+	for rows.Next() {
+		var i ArchiveCheck
+		if err := rows.Scan(
+			&i.Path,
+			&i.PageCount,
+			&i.Health,
+			&i.Problems,
+			&i.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBatchRuns = `-- name: ListBatchRuns :many
+SELECT id, started_at, finished_at, mode, input_source, parser_name, total, processed, successful, failed, skipped, settings, llm_input_tokens, llm_output_tokens, llm_cost FROM batch_runs ORDER BY id DESC
+`
+
+func (q *Queries) ListBatchRuns(ctx context.Context) ([]BatchRun, error) {
+	rows, err := q.db.QueryContext(ctx, listBatchRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BatchRun
+	for rows.Next() {
+		var i BatchRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Mode,
+			&i.InputSource,
+			&i.ParserName,
+			&i.Total,
+			&i.Processed,
+			&i.Successful,
+			&i.Failed,
+			&i.Skipped,
+			&i.Settings,
+			&i.LlmInputTokens,
+			&i.LlmOutputTokens,
+			&i.LlmCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCollections = `-- name: ListCollections :many
+SELECT id, filename, path, success, error, format, volume_name, comicvine_volume_id, match_confidence, parsed, volume, processed_at FROM collections ORDER BY filename
+`
+
+func (q *Queries) ListCollections(ctx context.Context) ([]Collection, error) {
+	rows, err := q.db.QueryContext(ctx, listCollections)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Collection
+	for rows.Next() {
+		var i Collection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.Path,
+			&i.Success,
+			&i.Error,
+			&i.Format,
+			&i.VolumeName,
+			&i.ComicvineVolumeID,
+			&i.MatchConfidence,
+			&i.Parsed,
+			&i.Volume,
+			&i.ProcessedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedResults = `-- name: ListDeletedResults :many
+SELECT id, filename, deleted_at FROM processing_results
+WHERE deleted_at IS NOT NULL
+ORDER BY deleted_at, filename
+`
+
+type ListDeletedResultsRow struct {
+	ID        int64
+	Filename  string
+	DeletedAt sql.NullTime
+}
+
+func (q *Queries) ListDeletedResults(ctx context.Context) ([]ListDeletedResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDeletedResultsRow
+	for rows.Next() {
+		var i ListDeletedResultsRow
+		if err := rows.Scan(&i.ID, &i.Filename, &i.DeletedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEpisodes = `-- name: ListEpisodes :many
+SELECT id, filename, path, success, error, series_name, tvdb_series_id, tvdb_episode_id, match_confidence, parsed, episode, processed_at FROM episodes ORDER BY filename
+`
+
+func (q *Queries) ListEpisodes(ctx context.Context) ([]Episode, error) {
+	rows, err := q.db.QueryContext(ctx, listEpisodes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Episode
+	for rows.Next() {
+		var i Episode
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.Path,
+			&i.Success,
+			&i.Error,
+			&i.SeriesName,
+			&i.TvdbSeriesID,
+			&i.TvdbEpisodeID,
+			&i.MatchConfidence,
+			&i.Parsed,
+			&i.Episode,
+			&i.ProcessedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFileHashes = `-- name: ListFileHashes :many
+SELECT filename, path, file_hash, file_size FROM processing_results
+WHERE file_hash IS NOT NULL AND deleted_at IS NULL
+ORDER BY filename
+`
+
+type ListFileHashesRow struct {
+	Filename string
+	Path     sql.NullString
+	FileHash sql.NullString
+	FileSize sql.NullInt64
+}
+
+func (q *Queries) ListFileHashes(ctx context.Context) ([]ListFileHashesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFileHashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFileHashesRow
+	for rows.Next() {
+		var i ListFileHashesRow
+		if err := rows.Scan(
+			&i.Filename,
+			&i.Path,
+			&i.FileHash,
+			&i.FileSize,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFilenamesByHash = `-- name: ListFilenamesByHash :many
+SELECT filename FROM processing_results
+WHERE file_hash = ?1 AND filename != ?2 AND deleted_at IS NULL
+ORDER BY filename
+`
+
+type ListFilenamesByHashParams struct {
+	FileHash sql.NullString
+	Filename string
+}
+
+func (q *Queries) ListFilenamesByHash(ctx context.Context, arg ListFilenamesByHashParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listFilenamesByHash, arg.FileHash, arg.Filename)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, err
+		}
+		items = append(items, filename)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listIgnoreRules = `-- name: ListIgnoreRules :many
+SELECT pattern, created_at FROM ignore_rules ORDER BY pattern
+`
+
+func (q *Queries) ListIgnoreRules(ctx context.Context) ([]IgnoreRule, error) {
+	rows, err := q.db.QueryContext(ctx, listIgnoreRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []IgnoreRule
+	for rows.Next() {
+		var i IgnoreRule
+		if err := rows.Scan(&i.Pattern, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKnownFilenames = `-- name: ListKnownFilenames :many
+SELECT original_filename AS filename FROM parsed_filenames
+WHERE original_filename NOT IN (SELECT filename FROM processing_results WHERE deleted_at IS NOT NULL)
+UNION
+SELECT filename FROM processing_results WHERE deleted_at IS NULL
+ORDER BY filename
+`
+
+func (q *Queries) ListKnownFilenames(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listKnownFilenames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, err
+		}
+		items = append(items, filename)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLastMoveOperation = `-- name: ListLastMoveOperation :many
+SELECT id, operation_id, filename, old_path, new_path, moved_at, undone_at FROM file_moves
+WHERE undone_at IS NULL
+  AND operation_id = (SELECT MAX(operation_id) FROM file_moves WHERE undone_at IS NULL)
+ORDER BY id DESC
+`
+
+func (q *Queries) ListLastMoveOperation(ctx context.Context) ([]FileMove, error) {
+	rows, err := q.db.QueryContext(ctx, listLastMoveOperation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FileMove
+	for rows.Next() {
+		var i FileMove
+		if err := rows.Scan(
+			&i.ID,
+			&i.OperationID,
+			&i.Filename,
+			&i.OldPath,
+			&i.NewPath,
+			&i.MovedAt,
+			&i.UndoneAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMappings = `-- name: ListMappings :many
+SELECT
+    m.kind, m.key, m.source, m.imported_at,
+    i.id AS issue_id, i.name AS issue_name, i.issue_number, i.cover_date, i.store_date,
+    i.site_detail_url, i.image_small_url, i.image_medium_url, i.image_large_url,
+    v.id AS volume_id, v.name AS volume_name, v.publisher_name, v.site_detail_url AS volume_url,
+    v.start_year, v.publisher_id, v.count_of_issues
+FROM comicvine_mappings m
+JOIN comic_vine_issues i ON i.id = m.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+ORDER BY m.kind, m.key
+`
+
+type ListMappingsRow struct {
+	Kind           string
+	Key            string
+	Source         string
+	ImportedAt     time.Time
+	IssueID        int64
+	IssueName      sql.NullString
+	IssueNumber    sql.NullString
+	CoverDate      sql.NullString
+	StoreDate      sql.NullString
+	SiteDetailUrl  sql.NullString
+	ImageSmallUrl  sql.NullString
+	ImageMediumUrl sql.NullString
+	ImageLargeUrl  sql.NullString
+	VolumeID       int64
+	VolumeName     string
+	PublisherName  sql.NullString
+	VolumeUrl      sql.NullString
+	StartYear      sql.NullString
+	PublisherID    sql.NullInt64
+	CountOfIssues  sql.NullInt64
+}
+
+func (q *Queries) ListMappings(ctx context.Context) ([]ListMappingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMappings)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMappingsRow
+	for rows.Next() {
+		var i ListMappingsRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Key,
+			&i.Source,
+			&i.ImportedAt,
+			&i.IssueID,
+			&i.IssueName,
+			&i.IssueNumber,
+			&i.CoverDate,
+			&i.StoreDate,
+			&i.SiteDetailUrl,
+			&i.ImageSmallUrl,
+			&i.ImageMediumUrl,
+			&i.ImageLargeUrl,
+			&i.VolumeID,
+			&i.VolumeName,
+			&i.PublisherName,
+			&i.VolumeUrl,
+			&i.StartYear,
+			&i.PublisherID,
+			&i.CountOfIssues,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchCorrections = `-- name: ListMatchCorrections :many
+SELECT id, filename, title, issue_number, year, original_comicvine_id, comicvine_id, volume_name, volume_start_year, corrected_issue_number, source, created_at FROM match_corrections
+ORDER BY id DESC
+LIMIT ?
+`
+
+func (q *Queries) ListMatchCorrections(ctx context.Context, limit int64) ([]MatchCorrection, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchCorrections, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchCorrection
+	for rows.Next() {
+		var i MatchCorrection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.Title,
+			&i.IssueNumber,
+			&i.Year,
+			&i.OriginalComicvineID,
+			&i.ComicvineID,
+			&i.VolumeName,
+			&i.VolumeStartYear,
+			&i.CorrectedIssueNumber,
+			&i.Source,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchQueue = `-- name: ListMatchQueue :many
+SELECT id, filename, queued_at FROM match_queue ORDER BY queued_at, id
+`
+
+func (q *Queries) ListMatchQueue(ctx context.Context) ([]MatchQueue, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchQueue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MatchQueue
+	for rows.Next() {
+		var i MatchQueue
+		if err := rows.Scan(&i.ID, &i.Filename, &i.QueuedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchedIssueDetails = `-- name: ListMatchedIssueDetails :many
+SELECT DISTINCT i.id, i.details_fetched_at
+FROM comic_vine_issues i
+JOIN processing_results pr ON pr.comicvine_id = i.id
+WHERE pr.success = 1 AND pr.deleted_at IS NULL
+ORDER BY i.id
+`
+
+type ListMatchedIssueDetailsRow struct {
+	ID               int64
+	DetailsFetchedAt sql.NullTime
+}
+
+func (q *Queries) ListMatchedIssueDetails(ctx context.Context) ([]ListMatchedIssueDetailsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchedIssueDetails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMatchedIssueDetailsRow
+	for rows.Next() {
+		var i ListMatchedIssueDetailsRow
+		if err := rows.Scan(&i.ID, &i.DetailsFetchedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchedResults = `-- name: ListMatchedResults :many
+SELECT
+    pr.filename, pr.path, pr.processed_at, pr.match_confidence, pr.reasoning, pr.provenance,
+    pr.source_filename,
+    i.id AS issue_id, i.name AS issue_name, i.issue_number, i.cover_date, i.store_date,
+    i.site_detail_url, i.image_small_url, i.image_medium_url, i.image_large_url,
+    i.description, i.credits, i.characters, i.teams, i.locations, i.story_arcs,
+    v.id AS volume_id, v.name AS volume_name, v.publisher_name, v.site_detail_url AS volume_url,
+    v.start_year, v.publisher_id, v.count_of_issues, pf.language
+FROM processing_results pr
+JOIN comic_vine_issues i ON i.id = pr.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+LEFT JOIN parsed_filenames pf ON pf.processing_result_id = pr.id
+WHERE pr.success = 1 AND pr.deleted_at IS NULL
+ORDER BY pr.filename
+`
+
+type ListMatchedResultsRow struct {
+	Filename        string
+	Path            sql.NullString
+	ProcessedAt     time.Time
+	MatchConfidence sql.NullString
+	Reasoning       sql.NullString
+	Provenance      sql.NullString
+	SourceFilename  sql.NullString
+	IssueID         int64
+	IssueName       sql.NullString
+	IssueNumber     sql.NullString
+	CoverDate       sql.NullString
+	StoreDate       sql.NullString
+	SiteDetailUrl   sql.NullString
+	ImageSmallUrl   sql.NullString
+	ImageMediumUrl  sql.NullString
+	ImageLargeUrl   sql.NullString
+	Description     sql.NullString
+	Credits         sql.NullString
+	Characters      sql.NullString
+	Teams           sql.NullString
+	Locations       sql.NullString
+	StoryArcs       sql.NullString
+	VolumeID        int64
+	VolumeName      string
+	PublisherName   sql.NullString
+	VolumeUrl       sql.NullString
+	StartYear       sql.NullString
+	PublisherID     sql.NullInt64
+	CountOfIssues   sql.NullInt64
+	Language        sql.NullString
+}
+
+func (q *Queries) ListMatchedResults(ctx context.Context) ([]ListMatchedResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMatchedResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMatchedResultsRow
+	for rows.Next() {
+		var i ListMatchedResultsRow
+		if err := rows.Scan(
+			&i.Filename,
+			&i.Path,
+			&i.ProcessedAt,
+			&i.MatchConfidence,
+			&i.Reasoning,
+			&i.Provenance,
+			&i.SourceFilename,
+			&i.IssueID,
+			&i.IssueName,
+			&i.IssueNumber,
+			&i.CoverDate,
+			&i.StoreDate,
+			&i.SiteDetailUrl,
+			&i.ImageSmallUrl,
+			&i.ImageMediumUrl,
+			&i.ImageLargeUrl,
+			&i.Description,
+			&i.Credits,
+			&i.Characters,
+			&i.Teams,
+			&i.Locations,
+			&i.StoryArcs,
+			&i.VolumeID,
+			&i.VolumeName,
+			&i.PublisherName,
+			&i.VolumeUrl,
+			&i.StartYear,
+			&i.PublisherID,
+			&i.CountOfIssues,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listParsedFilenames = `-- name: ListParsedFilenames :many
+SELECT id, processing_result_id, parser_name, original_filename, title, issue_number, year, publisher, volume_number, confidence, notes, run_id, special, path, chapter, issue_type, language FROM parsed_filenames
+WHERE original_filename NOT IN (SELECT filename FROM processing_results WHERE deleted_at IS NOT NULL)
+ORDER BY id DESC
+`
+
+func (q *Queries) ListParsedFilenames(ctx context.Context) ([]ParsedFilename, error) {
+	rows, err := q.db.QueryContext(ctx, listParsedFilenames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParsedFilename
+	for rows.Next() {
+		var i ParsedFilename
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProcessingResultID,
+			&i.ParserName,
+			&i.OriginalFilename,
+			&i.Title,
+			&i.IssueNumber,
+			&i.Year,
+			&i.Publisher,
+			&i.VolumeNumber,
+			&i.Confidence,
+			&i.Notes,
+			&i.RunID,
+			&i.Special,
+			&i.Path,
+			&i.Chapter,
+			&i.IssueType,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingCheckpoints = `-- name: ListPendingCheckpoints :many
+SELECT filename FROM batch_checkpoints
+WHERE run_id = ? AND state != 'done'
+ORDER BY rowid
+`
+
+func (q *Queries) ListPendingCheckpoints(ctx context.Context, runID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingCheckpoints, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var filename string
+		if err := rows.Scan(&filename); err != nil {
+			return nil, err
+		}
+		items = append(items, filename)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingReviews = `-- name: ListPendingReviews :many
+SELECT id, run_id, filename, result, candidates, status, queued_at, reviewed_at FROM review_queue
+WHERE status = 'pending'
+ORDER BY id
+`
+
+func (q *Queries) ListPendingReviews(ctx context.Context) ([]ReviewQueue, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingReviews)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReviewQueue
+	for rows.Next() {
+		var i ReviewQueue
+		if err := rows.Scan(
+			&i.ID,
+			&i.RunID,
+			&i.Filename,
+			&i.Result,
+			&i.Candidates,
+			&i.Status,
+			&i.QueuedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReadingListEntries = `-- name: ListReadingListEntries :many
+SELECT
+    e.position,
+    i.id AS issue_id, i.name AS issue_name, i.issue_number, i.cover_date, i.site_detail_url,
+    v.id AS volume_id, v.name AS volume_name, v.start_year, v.publisher_name
+FROM reading_list_entries e
+JOIN comic_vine_issues i ON i.id = e.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE e.list_id = ?
+ORDER BY e.position
+`
+
+type ListReadingListEntriesRow struct {
+	Position      int64
+	IssueID       int64
+	IssueName     sql.NullString
+	IssueNumber   sql.NullString
+	CoverDate     sql.NullString
+	SiteDetailUrl sql.NullString
+	VolumeID      int64
+	VolumeName    string
+	StartYear     sql.NullString
+	PublisherName sql.NullString
+}
+
+func (q *Queries) ListReadingListEntries(ctx context.Context, listID int64) ([]ListReadingListEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReadingListEntries, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReadingListEntriesRow
+	for rows.Next() {
+		var i ListReadingListEntriesRow
+		if err := rows.Scan(
+			&i.Position,
+			&i.IssueID,
+			&i.IssueName,
+			&i.IssueNumber,
+			&i.CoverDate,
+			&i.SiteDetailUrl,
+			&i.VolumeID,
+			&i.VolumeName,
+			&i.StartYear,
+			&i.PublisherName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReadingLists = `-- name: ListReadingLists :many
+SELECT rl.id, rl.name, rl.created_at, COUNT(e.comicvine_id) AS entry_count
+FROM reading_lists rl
+LEFT JOIN reading_list_entries e ON e.list_id = rl.id
+GROUP BY rl.id
+ORDER BY rl.name
+`
+
+type ListReadingListsRow struct {
+	ID         int64
+	Name       string
+	CreatedAt  time.Time
+	EntryCount int64
+}
+
+func (q *Queries) ListReadingLists(ctx context.Context) ([]ListReadingListsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReadingLists)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReadingListsRow
+	for rows.Next() {
+		var i ListReadingListsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.EntryCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecordEdits = `-- name: ListRecordEdits :many
+SELECT id, filename, field, old_value, new_value, source, edited_at FROM record_edits WHERE filename = ? ORDER BY id
+`
+
+func (q *Queries) ListRecordEdits(ctx context.Context, filename string) ([]RecordEdit, error) {
+	rows, err := q.db.QueryContext(ctx, listRecordEdits, filename)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecordEdit
+	for rows.Next() {
+		var i RecordEdit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.Field,
+			&i.OldValue,
+			&i.NewValue,
+			&i.Source,
+			&i.EditedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRematchCandidates = `-- name: ListRematchCandidates :many
+SELECT filename, source_filename, success, match_confidence FROM processing_results
+WHERE (success = 0 OR match_confidence IN ('none', 'low')) AND deleted_at IS NULL
+ORDER BY filename
+`
+
+type ListRematchCandidatesRow struct {
+	Filename        string
+	SourceFilename  sql.NullString
+	Success         bool
+	MatchConfidence sql.NullString
+}
+
+func (q *Queries) ListRematchCandidates(ctx context.Context) ([]ListRematchCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRematchCandidates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRematchCandidatesRow
+	for rows.Next() {
+		var i ListRematchCandidatesRow
+		if err := rows.Scan(
+			&i.Filename,
+			&i.SourceFilename,
+			&i.Success,
+			&i.MatchConfidence,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResultFacts = `-- name: ListResultFacts :many
+SELECT pr.filename, pr.success, pr.match_confidence, pr.comicvine_id, pr.created_at, i.cover_date, v.publisher_name
+FROM processing_results pr
+LEFT JOIN comic_vine_issues i ON i.id = pr.comicvine_id
+LEFT JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE pr.deleted_at IS NULL
+`
+
+type ListResultFactsRow struct {
+	Filename        string
+	Success         bool
+	MatchConfidence sql.NullString
+	ComicvineID     sql.NullInt64
+	CreatedAt       sql.NullTime
+	CoverDate       sql.NullString
+	PublisherName   sql.NullString
+}
+
+func (q *Queries) ListResultFacts(ctx context.Context) ([]ListResultFactsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listResultFacts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListResultFactsRow
+	for rows.Next() {
+		var i ListResultFactsRow
+		if err := rows.Scan(
+			&i.Filename,
+			&i.Success,
+			&i.MatchConfidence,
+			&i.ComicvineID,
+			&i.CreatedAt,
+			&i.CoverDate,
+			&i.PublisherName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listResultRefsBySeries = `-- name: ListResultRefsBySeries :many
+SELECT pr.id, pr.filename, pr.deleted_at, i.issue_number
+FROM processing_results pr
+JOIN comic_vine_issues i ON i.id = pr.comicvine_id
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE v.name = ? COLLATE NOCASE
+ORDER BY pr.filename
+`
+
+type ListResultRefsBySeriesRow struct {
+	ID          int64
+	Filename    string
+	DeletedAt   sql.NullTime
+	IssueNumber sql.NullString
+}
+
+func (q *Queries) ListResultRefsBySeries(ctx context.Context, name string) ([]ListResultRefsBySeriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listResultRefsBySeries, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListResultRefsBySeriesRow
+	for rows.Next() {
+		var i ListResultRefsBySeriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.DeletedAt,
+			&i.IssueNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRunResults = `-- name: ListRunResults :many
+SELECT run_id, filename, success, error, title, issue_number, year, match_confidence, comicvine_id, error_code FROM run_results WHERE run_id = ? ORDER BY filename
+`
+
+func (q *Queries) ListRunResults(ctx context.Context, runID int64) ([]RunResult, error) {
+	rows, err := q.db.QueryContext(ctx, listRunResults, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RunResult
+	for rows.Next() {
+		var i RunResult
+		if err := rows.Scan(
+			&i.RunID,
+			&i.Filename,
+			&i.Success,
+			&i.Error,
+			&i.Title,
+			&i.IssueNumber,
+			&i.Year,
+			&i.MatchConfidence,
+			&i.ComicvineID,
+			&i.ErrorCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSeriesAliases = `-- name: ListSeriesAliases :many
+SELECT key, name, series, volume_id, created_at FROM series_aliases ORDER BY name
+`
+
+func (q *Queries) ListSeriesAliases(ctx context.Context) ([]SeriesAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listSeriesAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SeriesAlias
+	for rows.Next() {
+		var i SeriesAlias
+		if err := rows.Scan(
+			&i.Key,
+			&i.Name,
+			&i.Series,
+			&i.VolumeID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnfinishedBatchRuns = `-- name: ListUnfinishedBatchRuns :many
+SELECT id, started_at, finished_at, mode, input_source, parser_name, total, processed, successful, failed, skipped, settings, llm_input_tokens, llm_output_tokens, llm_cost FROM batch_runs WHERE finished_at IS NULL ORDER BY id DESC
+`
+
+func (q *Queries) ListUnfinishedBatchRuns(ctx context.Context) ([]BatchRun, error) {
+	rows, err := q.db.QueryContext(ctx, listUnfinishedBatchRuns)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BatchRun
+	for rows.Next() {
+		var i BatchRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartedAt,
+			&i.FinishedAt,
+			&i.Mode,
+			&i.InputSource,
+			&i.ParserName,
+			&i.Total,
+			&i.Processed,
+			&i.Successful,
+			&i.Failed,
+			&i.Skipped,
+			&i.Settings,
+			&i.LlmInputTokens,
+			&i.LlmOutputTokens,
+			&i.LlmCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVolumeIssues = `-- name: ListVolumeIssues :many
+SELECT
+    i.id, i.name, i.issue_number, i.cover_date, i.store_date, i.site_detail_url,
+    i.image_small_url, i.image_medium_url, i.image_large_url,
+    v.name AS volume_name, v.site_detail_url AS volume_url
+FROM comic_vine_issues i
+JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE i.volume_id = ?
+ORDER BY i.id
+`
+
+type ListVolumeIssuesRow struct {
+	ID             int64
+	Name           sql.NullString
+	IssueNumber    sql.NullString
+	CoverDate      sql.NullString
+	StoreDate      sql.NullString
+	SiteDetailUrl  sql.NullString
+	ImageSmallUrl  sql.NullString
+	ImageMediumUrl sql.NullString
+	ImageLargeUrl  sql.NullString
+	VolumeName     string
+	VolumeUrl      sql.NullString
+}
+
+func (q *Queries) ListVolumeIssues(ctx context.Context, volumeID int64) ([]ListVolumeIssuesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVolumeIssues, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListVolumeIssuesRow
+	for rows.Next() {
+		var i ListVolumeIssuesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.IssueNumber,
+			&i.CoverDate,
+			&i.StoreDate,
+			&i.SiteDetailUrl,
+			&i.ImageSmallUrl,
+			&i.ImageMediumUrl,
+			&i.ImageLargeUrl,
+			&i.VolumeName,
+			&i.VolumeUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVolumeSearchResults = `-- name: ListVolumeSearchResults :many
+SELECT v.id, v.name, v.start_year, v.publisher_name, v.site_detail_url, v.publisher_id, v.count_of_issues
+FROM volume_search_results r
+JOIN comic_vine_volumes v ON v.id = r.volume_id
+WHERE r.query = ?
+ORDER BY r.position
+`
+
+type ListVolumeSearchResultsRow struct {
+	ID            int64
+	Name          string
+	StartYear     sql.NullString
+	PublisherName sql.NullString
+	SiteDetailUrl sql.NullString
+	PublisherID   sql.NullInt64
+	CountOfIssues sql.NullInt64
+}
+
+func (q *Queries) ListVolumeSearchResults(ctx context.Context, query string) ([]ListVolumeSearchResultsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listVolumeSearchResults, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListVolumeSearchResultsRow
+	for rows.Next() {
+		var i ListVolumeSearchResultsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.StartYear,
+			&i.PublisherName,
+			&i.SiteDetailUrl,
+			&i.PublisherID,
+			&i.CountOfIssues,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFileMoveUndone = `-- name: MarkFileMoveUndone :exec
+UPDATE file_moves SET undone_at = ? WHERE id = ?
+`
+
+type MarkFileMoveUndoneParams struct {
+	UndoneAt sql.NullTime
+	ID       int64
+}
+
+func (q *Queries) MarkFileMoveUndone(ctx context.Context, arg MarkFileMoveUndoneParams) error {
+	_, err := q.db.ExecContext(ctx, markFileMoveUndone, arg.UndoneAt, arg.ID)
+	return err
+}
+
+const nextMoveOperation = `-- name: NextMoveOperation :one
+SELECT CAST(COALESCE(MAX(operation_id), 0) + 1 AS INTEGER) AS operation_id FROM file_moves
+`
+
+func (q *Queries) NextMoveOperation(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, nextMoveOperation)
+	var operation_id int64
+	err := row.Scan(&operation_id)
+	return operation_id, err
+}
+
+const renameParsedFilenames = `-- name: RenameParsedFilenames :exec
+UPDATE OR REPLACE parsed_filenames SET original_filename = ?1, path = ?2 WHERE original_filename = ?3
+`
+
+type RenameParsedFilenamesParams struct {
+	NewFilename string
+	NewPath     sql.NullString
+	OldFilename string
+}
+
+func (q *Queries) RenameParsedFilenames(ctx context.Context, arg RenameParsedFilenamesParams) error {
+	_, err := q.db.ExecContext(ctx, renameParsedFilenames, arg.NewFilename, arg.NewPath, arg.OldFilename)
+	return err
+}
+
+const renameProcessingResult = `-- name: RenameProcessingResult :exec
+UPDATE OR REPLACE processing_results SET filename = ?1, path = ?2 WHERE filename = ?3
+`
+
+type RenameProcessingResultParams struct {
+	NewFilename string
+	NewPath     sql.NullString
+	OldFilename string
+}
+
+func (q *Queries) RenameProcessingResult(ctx context.Context, arg RenameProcessingResultParams) error {
+	_, err := q.db.ExecContext(ctx, renameProcessingResult, arg.NewFilename, arg.NewPath, arg.OldFilename)
+	return err
+}
+
+const reopenBatchRun = `-- name: ReopenBatchRun :exec
+UPDATE batch_runs SET finished_at = NULL WHERE id = ?
+`
+
+func (q *Queries) ReopenBatchRun(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, reopenBatchRun, id)
+	return err
+}
+
+const resetVolumeIssueLists = `-- name: ResetVolumeIssueLists :exec
+UPDATE comic_vine_volumes SET issues_fetched_at = NULL WHERE issues_fetched_at IS NOT NULL
+`
+
+func (q *Queries) ResetVolumeIssueLists(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, resetVolumeIssueLists)
+	return err
+}
+
+const resolveReviewItem = `-- name: ResolveReviewItem :exec
+UPDATE review_queue SET status = ?, result = ?, reviewed_at = ? WHERE id = ?
+`
+
+type ResolveReviewItemParams struct {
+	Status     string
+	Result     string
+	ReviewedAt sql.NullTime
+	ID         int64
+}
+
+func (q *Queries) ResolveReviewItem(ctx context.Context, arg ResolveReviewItemParams) error {
+	_, err := q.db.ExecContext(ctx, resolveReviewItem,
+		arg.Status,
+		arg.Result,
+		arg.ReviewedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const searchDocuments = `-- name: SearchDocuments :many
+SELECT id, filename, series, title, description FROM search_documents
+WHERE filename LIKE ?1 ESCAPE '\'
+   OR series LIKE ?1 ESCAPE '\'
+   OR title LIKE ?1 ESCAPE '\'
+   OR description LIKE ?1 ESCAPE '\'
+ORDER BY filename
+`
+
+func (q *Queries) SearchDocuments(ctx context.Context, pattern string) ([]SearchDocument, error) {
+	rows, err := q.db.QueryContext(ctx, searchDocuments, pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchDocument
+	for rows.Next() {
+		var i SearchDocument
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.Series,
+			&i.Title,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setIssueDetails = `-- name: SetIssueDetails :exec
+UPDATE comic_vine_issues SET
+    description = ?,
+    credits = ?,
+    characters = ?,
+    teams = ?,
+    locations = ?,
+    story_arcs = ?,
+    details_fetched_at = ?
+WHERE id = ?
+`
+
+type SetIssueDetailsParams struct {
+	Description      sql.NullString
+	Credits          sql.NullString
+	Characters       sql.NullString
+	Teams            sql.NullString
+	Locations        sql.NullString
+	StoryArcs        sql.NullString
+	DetailsFetchedAt sql.NullTime
+	ID               int64
+}
+
+func (q *Queries) SetIssueDetails(ctx context.Context, arg SetIssueDetailsParams) error {
+	_, err := q.db.ExecContext(ctx, setIssueDetails,
+		arg.Description,
+		arg.Credits,
+		arg.Characters,
+		arg.Teams,
+		arg.Locations,
+		arg.StoryArcs,
+		arg.DetailsFetchedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const setResultDeletedAt = `-- name: SetResultDeletedAt :execrows
+UPDATE processing_results SET deleted_at = ? WHERE id = ?
+`
+
+type SetResultDeletedAtParams struct {
+	DeletedAt sql.NullTime
+	ID        int64
+}
+
+func (q *Queries) SetResultDeletedAt(ctx context.Context, arg SetResultDeletedAtParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setResultDeletedAt, arg.DeletedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setVolumeIssuesFetchedAt = `-- name: SetVolumeIssuesFetchedAt :exec
+UPDATE comic_vine_volumes SET issues_fetched_at = ? WHERE id = ?
+`
+
+type SetVolumeIssuesFetchedAtParams struct {
+	IssuesFetchedAt sql.NullTime
+	ID              int64
+}
+
+func (q *Queries) SetVolumeIssuesFetchedAt(ctx context.Context, arg SetVolumeIssuesFetchedAtParams) error {
+	_, err := q.db.ExecContext(ctx, setVolumeIssuesFetchedAt, arg.IssuesFetchedAt, arg.ID)
+	return err
+}
+
+const summarizeUsageByModel = `-- name: SummarizeUsageByModel :many
+SELECT
+    provider, model, kind,
+    COUNT(*) AS calls,
+    CAST(SUM(input_tokens) AS INTEGER) AS input_tokens,
+    CAST(SUM(output_tokens) AS INTEGER) AS output_tokens,
+    CAST(SUM(cost) AS REAL) AS cost
+FROM llm_usage
+WHERE ?1 = 0 OR run_id = ?1
+GROUP BY provider, model, kind
+ORDER BY provider, model, kind
+`
+
+type SummarizeUsageByModelRow struct {
+	Provider     string
+	Model        string
+	Kind         string
+	Calls        int64
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+}
+
+func (q *Queries) SummarizeUsageByModel(ctx context.Context, runID interface{}) ([]SummarizeUsageByModelRow, error) {
+	rows, err := q.db.QueryContext(ctx, summarizeUsageByModel, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SummarizeUsageByModelRow
+	for rows.Next() {
+		var i SummarizeUsageByModelRow
+		if err := rows.Scan(
+			&i.Provider,
+			&i.Model,
+			&i.Kind,
+			&i.Calls,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const summarizeUsageByRun = `-- name: SummarizeUsageByRun :many
+SELECT
+    u.run_id,
+    CAST(COALESCE(r.mode, '') AS TEXT) AS mode,
+    CAST(COALESCE(r.input_source, '') AS TEXT) AS input_source,
+    COUNT(*) AS calls,
+    CAST(SUM(u.input_tokens) AS INTEGER) AS input_tokens,
+    CAST(SUM(u.output_tokens) AS INTEGER) AS output_tokens,
+    CAST(SUM(u.cost) AS REAL) AS cost
+FROM llm_usage u
+LEFT JOIN batch_runs r ON r.id = u.run_id
+GROUP BY u.run_id
+ORDER BY u.run_id
+`
+
+type SummarizeUsageByRunRow struct {
+	RunID        sql.NullInt64
+	Mode         string
+	InputSource  string
+	Calls        int64
+	InputTokens  int64
+	OutputTokens int64
+	Cost         float64
+}
+
+func (q *Queries) SummarizeUsageByRun(ctx context.Context) ([]SummarizeUsageByRunRow, error) {
+	rows, err := q.db.QueryContext(ctx, summarizeUsageByRun)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SummarizeUsageByRunRow
+	for rows.Next() {
+		var i SummarizeUsageByRunRow
+		if err := rows.Scan(
+			&i.RunID,
+			&i.Mode,
+			&i.InputSource,
+			&i.Calls,
+			&i.InputTokens,
+			&i.OutputTokens,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateParsedFields = `-- name: UpdateParsedFields :exec
+UPDATE parsed_filenames SET
+    title = ?,
+    issue_number = ?,
+    year = ?,
+    publisher = ?,
+    volume_number = ?
+WHERE id = ?
 `
 
-type CreateParsedFilenameParams struct {
-	ProcessingResultID sql.NullInt64
-	ParserName         string
-	OriginalFilename   string
-	Title              string
-	IssueNumber        string
-	Year               sql.NullString
-	Publisher          sql.NullString
-	VolumeNumber       sql.NullString
-	Confidence         string
-	Notes              sql.NullString
+type UpdateParsedFieldsParams struct {
+	Title        string
+	IssueNumber  string
+	Year         sql.NullString
+	Publisher    sql.NullString
+	VolumeNumber sql.NullString
+	ID           int64
 }
 
-func (q *Queries) CreateParsedFilename(ctx context.Context, arg CreateParsedFilenameParams) error {
-	_, err := q.db.ExecContext(ctx, createParsedFilename,
-		arg.ProcessingResultID,
-		arg.ParserName,
-		arg.OriginalFilename,
+func (q *Queries) UpdateParsedFields(ctx context.Context, arg UpdateParsedFieldsParams) error {
+	_, err := q.db.ExecContext(ctx, updateParsedFields,
 		arg.Title,
 		arg.IssueNumber,
 		arg.Year,
 		arg.Publisher,
 		arg.VolumeNumber,
-		arg.Confidence,
-		arg.Notes,
+		arg.ID,
 	)
 	return err
 }
 
-const deleteParsedFilenamesByResultID = `-- name: DeleteParsedFilenamesByResultID :exec
-DELETE FROM parsed_filenames WHERE processing_result_id = ?
+const upsertArchiveCheck = `-- name: UpsertArchiveCheck :exec
+INSERT INTO archive_checks (path, page_count, health, problems, checked_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(path) DO UPDATE SET
+    page_count = excluded.page_count,
+    health = excluded.health,
+    problems = excluded.problems,
+    checked_at = excluded.checked_at
 `
 
-func (q *Queries) DeleteParsedFilenamesByResultID(ctx context.Context, processingResultID int64) error {
-	_, err := q.db.ExecContext(ctx, deleteParsedFilenamesByResultID, processingResultID)
+type UpsertArchiveCheckParams struct {
+	Path      string
+	PageCount int64
+	Health    string
+	Problems  sql.NullString
+	CheckedAt time.Time
+}
+
+func (q *Queries) UpsertArchiveCheck(ctx context.Context, arg UpsertArchiveCheckParams) error {
+	_, err := q.db.ExecContext(ctx, upsertArchiveCheck,
+		arg.Path,
+		arg.PageCount,
+		arg.Health,
+		arg.Problems,
+		arg.CheckedAt,
+	)
 	return err
 }
 
-const getProcessingResult = `-- name: GetProcessingResult :one
-SELECT id, filename, success, error, processed_at, processing_time_ms, match_confidence, reasoning, comicvine_id, comicvine_url FROM processing_results WHERE filename = ?
+const upsertCheckpoint = `-- name: UpsertCheckpoint :exec
+INSERT INTO batch_checkpoints (
+    run_id, filename, state, error, updated_at
+) VALUES (
+    ?, ?, ?, ?, ?
+) ON CONFLICT(run_id, filename) DO UPDATE SET
+    state = excluded.state,
+    error = excluded.error,
+    updated_at = excluded.updated_at
 `
 
-func (q *Queries) GetProcessingResult(ctx context.Context, filename string) (ProcessingResult, error) {
-	row := q.db.QueryRowContext(ctx, getProcessingResult, filename)
-	var i ProcessingResult
-	err := row.Scan(
-		&i.ID,
-		&i.Filename,
-		&i.Success,
-		&i.Error,
-		&i.ProcessedAt,
-		&i.ProcessingTimeMs,
-		&i.MatchConfidence,
-		&i.Reasoning,
-		&i.ComicvineID,
-		&i.ComicvineUrl,
+type UpsertCheckpointParams struct {
+	RunID     int64
+	Filename  string
+	State     string
+	Error     sql.NullString
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpsertCheckpoint(ctx context.Context, arg UpsertCheckpointParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCheckpoint,
+		arg.RunID,
+		arg.Filename,
+		arg.State,
+		arg.Error,
+		arg.UpdatedAt,
 	)
-	return i, err
+	return err
+}
+
+const upsertCollection = `-- name: UpsertCollection :exec
+INSERT INTO collections (
+    filename, path, success, error, format, volume_name,
+    comicvine_volume_id, match_confidence, parsed, volume, processed_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(filename) DO UPDATE SET
+    path = excluded.path,
+    success = excluded.success,
+    error = excluded.error,
+    format = excluded.format,
+    volume_name = excluded.volume_name,
+    comicvine_volume_id = excluded.comicvine_volume_id,
+    match_confidence = excluded.match_confidence,
+    parsed = excluded.parsed,
+    volume = excluded.volume,
+    processed_at = excluded.processed_at
+`
+
+type UpsertCollectionParams struct {
+	Filename          string
+	Path              sql.NullString
+	Success           bool
+	Error             sql.NullString
+	Format            sql.NullString
+	VolumeName        sql.NullString
+	ComicvineVolumeID sql.NullInt64
+	MatchConfidence   sql.NullString
+	Parsed            sql.NullString
+	Volume            sql.NullString
+	ProcessedAt       time.Time
+}
+
+func (q *Queries) UpsertCollection(ctx context.Context, arg UpsertCollectionParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCollection,
+		arg.Filename,
+		arg.Path,
+		arg.Success,
+		arg.Error,
+		arg.Format,
+		arg.VolumeName,
+		arg.ComicvineVolumeID,
+		arg.MatchConfidence,
+		arg.Parsed,
+		arg.Volume,
+		arg.ProcessedAt,
+	)
+	return err
+}
+
+const upsertEpisode = `-- name: UpsertEpisode :exec
+INSERT INTO episodes (
+    filename, path, success, error, series_name, tvdb_series_id,
+    tvdb_episode_id, match_confidence, parsed, episode, processed_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(filename) DO UPDATE SET
+    path = excluded.path,
+    success = excluded.success,
+    error = excluded.error,
+    series_name = excluded.series_name,
+    tvdb_series_id = excluded.tvdb_series_id,
+    tvdb_episode_id = excluded.tvdb_episode_id,
+    match_confidence = excluded.match_confidence,
+    parsed = excluded.parsed,
+    episode = excluded.episode,
+    processed_at = excluded.processed_at
+`
+
+type UpsertEpisodeParams struct {
+	Filename        string
+	Path            sql.NullString
+	Success         bool
+	Error           sql.NullString
+	SeriesName      sql.NullString
+	TvdbSeriesID    sql.NullInt64
+	TvdbEpisodeID   sql.NullInt64
+	MatchConfidence sql.NullString
+	Parsed          sql.NullString
+	Episode         sql.NullString
+	ProcessedAt     time.Time
+}
+
+func (q *Queries) UpsertEpisode(ctx context.Context, arg UpsertEpisodeParams) error {
+	_, err := q.db.ExecContext(ctx, upsertEpisode,
+		arg.Filename,
+		arg.Path,
+		arg.Success,
+		arg.Error,
+		arg.SeriesName,
+		arg.TvdbSeriesID,
+		arg.TvdbEpisodeID,
+		arg.MatchConfidence,
+		arg.Parsed,
+		arg.Episode,
+		arg.ProcessedAt,
+	)
+	return err
+}
+
+const upsertFetchedVolume = `-- name: UpsertFetchedVolume :exec
+INSERT INTO comic_vine_volumes (
+    id, name, start_year, publisher_name, site_detail_url, publisher_id, count_of_issues, fetched_at
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(id) DO UPDATE SET
+    name = excluded.name,
+    start_year = COALESCE(excluded.start_year, comic_vine_volumes.start_year),
+    publisher_name = COALESCE(excluded.publisher_name, comic_vine_volumes.publisher_name),
+    site_detail_url = COALESCE(excluded.site_detail_url, comic_vine_volumes.site_detail_url),
+    publisher_id = COALESCE(excluded.publisher_id, comic_vine_volumes.publisher_id),
+    count_of_issues = COALESCE(excluded.count_of_issues, comic_vine_volumes.count_of_issues),
+    fetched_at = excluded.fetched_at
+`
+
+type UpsertFetchedVolumeParams struct {
+	ID            int64
+	Name          string
+	StartYear     sql.NullString
+	PublisherName sql.NullString
+	SiteDetailUrl sql.NullString
+	PublisherID   sql.NullInt64
+	CountOfIssues sql.NullInt64
+	FetchedAt     sql.NullTime
+}
+
+func (q *Queries) UpsertFetchedVolume(ctx context.Context, arg UpsertFetchedVolumeParams) error {
+	_, err := q.db.ExecContext(ctx, upsertFetchedVolume,
+		arg.ID,
+		arg.Name,
+		arg.StartYear,
+		arg.PublisherName,
+		arg.SiteDetailUrl,
+		arg.PublisherID,
+		arg.CountOfIssues,
+		arg.FetchedAt,
+	)
+	return err
 }
 
 const upsertIssue = `-- name: UpsertIssue :exec
@@ -100,7 +2663,7 @@ INSERT INTO comic_vine_issues (
     issue_number = excluded.issue_number,
     cover_date = excluded.cover_date,
     store_date = excluded.store_date,
-    description = excluded.description,
+    description = COALESCE(excluded.description, comic_vine_issues.description),
     site_detail_url = excluded.site_detail_url,
     image_small_url = excluded.image_small_url,
     image_medium_url = excluded.image_medium_url,
@@ -138,21 +2701,107 @@ func (q *Queries) UpsertIssue(ctx context.Context, arg UpsertIssueParams) error
 	return err
 }
 
+const upsertListedIssue = `-- name: UpsertListedIssue :exec
+INSERT INTO comic_vine_issues (
+    id, volume_id, name, issue_number, cover_date, store_date,
+    site_detail_url, image_small_url, image_medium_url, image_large_url
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(id) DO UPDATE SET
+    volume_id = excluded.volume_id,
+    name = excluded.name,
+    issue_number = excluded.issue_number,
+    cover_date = excluded.cover_date,
+    store_date = excluded.store_date,
+    site_detail_url = excluded.site_detail_url,
+    image_small_url = COALESCE(excluded.image_small_url, comic_vine_issues.image_small_url),
+    image_medium_url = COALESCE(excluded.image_medium_url, comic_vine_issues.image_medium_url),
+    image_large_url = COALESCE(excluded.image_large_url, comic_vine_issues.image_large_url)
+`
+
+type UpsertListedIssueParams struct {
+	ID             int64
+	VolumeID       int64
+	Name           sql.NullString
+	IssueNumber    sql.NullString
+	CoverDate      sql.NullString
+	StoreDate      sql.NullString
+	SiteDetailUrl  sql.NullString
+	ImageSmallUrl  sql.NullString
+	ImageMediumUrl sql.NullString
+	ImageLargeUrl  sql.NullString
+}
+
+func (q *Queries) UpsertListedIssue(ctx context.Context, arg UpsertListedIssueParams) error {
+	_, err := q.db.ExecContext(ctx, upsertListedIssue,
+		arg.ID,
+		arg.VolumeID,
+		arg.Name,
+		arg.IssueNumber,
+		arg.CoverDate,
+		arg.StoreDate,
+		arg.SiteDetailUrl,
+		arg.ImageSmallUrl,
+		arg.ImageMediumUrl,
+		arg.ImageLargeUrl,
+	)
+	return err
+}
+
+const upsertMapping = `-- name: UpsertMapping :exec
+INSERT INTO comicvine_mappings (
+    kind, key, comicvine_id, source, imported_at
+) VALUES (
+    ?, ?, ?, ?, ?
+) ON CONFLICT(kind, key) DO UPDATE SET
+    comicvine_id = excluded.comicvine_id,
+    source = excluded.source,
+    imported_at = excluded.imported_at
+`
+
+type UpsertMappingParams struct {
+	Kind        string
+	Key         string
+	ComicvineID int64
+	Source      string
+	ImportedAt  time.Time
+}
+
+func (q *Queries) UpsertMapping(ctx context.Context, arg UpsertMappingParams) error {
+	_, err := q.db.ExecContext(ctx, upsertMapping,
+		arg.Kind,
+		arg.Key,
+		arg.ComicvineID,
+		arg.Source,
+		arg.ImportedAt,
+	)
+	return err
+}
+
 const upsertProcessingResult = `-- name: UpsertProcessingResult :one
 INSERT INTO processing_results (
-    filename, success, error, processed_at, processing_time_ms,
-    match_confidence, reasoning, comicvine_id, comicvine_url
+    filename, success, error, error_code, processed_at, processing_time_ms,
+    match_confidence, reasoning, comicvine_id, comicvine_url, run_id, provenance, path,
+    source_filename, created_at, file_hash, file_size
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT(filename) DO UPDATE SET
     success = excluded.success,
     error = excluded.error,
+    error_code = excluded.error_code,
     processed_at = excluded.processed_at,
     processing_time_ms = excluded.processing_time_ms,
     match_confidence = excluded.match_confidence,
     reasoning = excluded.reasoning,
     comicvine_id = excluded.comicvine_id,
-    comicvine_url = excluded.comicvine_url
+    comicvine_url = excluded.comicvine_url,
+    run_id = excluded.run_id,
+    provenance = excluded.provenance,
+    path = excluded.path,
+    source_filename = excluded.source_filename,
+    file_hash = COALESCE(excluded.file_hash, file_hash),
+    file_size = COALESCE(excluded.file_size, file_size),
+    deleted_at = NULL
 RETURNING id
 `
 
@@ -160,12 +2809,20 @@ type UpsertProcessingResultParams struct {
 	Filename         string
 	Success          bool
 	Error            sql.NullString
+	ErrorCode        sql.NullString
 	ProcessedAt      time.Time
 	ProcessingTimeMs int64
 	MatchConfidence  sql.NullString
 	Reasoning        sql.NullString
 	ComicvineID      sql.NullInt64
 	ComicvineUrl     sql.NullString
+	RunID            sql.NullInt64
+	Provenance       sql.NullString
+	Path             sql.NullString
+	SourceFilename   sql.NullString
+	CreatedAt        sql.NullTime
+	FileHash         sql.NullString
+	FileSize         sql.NullInt64
 }
 
 func (q *Queries) UpsertProcessingResult(ctx context.Context, arg UpsertProcessingResultParams) (int64, error) {
@@ -173,28 +2830,164 @@ func (q *Queries) UpsertProcessingResult(ctx context.Context, arg UpsertProcessi
 		arg.Filename,
 		arg.Success,
 		arg.Error,
+		arg.ErrorCode,
 		arg.ProcessedAt,
 		arg.ProcessingTimeMs,
 		arg.MatchConfidence,
 		arg.Reasoning,
 		arg.ComicvineID,
 		arg.ComicvineUrl,
+		arg.RunID,
+		arg.Provenance,
+		arg.Path,
+		arg.SourceFilename,
+		arg.CreatedAt,
+		arg.FileHash,
+		arg.FileSize,
 	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
+const upsertReviewItem = `-- name: UpsertReviewItem :exec
+INSERT INTO review_queue (
+    run_id, filename, result, candidates, status, queued_at
+) VALUES (
+    ?, ?, ?, ?, 'pending', ?
+) ON CONFLICT(filename) DO UPDATE SET
+    run_id = excluded.run_id,
+    result = excluded.result,
+    candidates = excluded.candidates,
+    status = 'pending',
+    queued_at = excluded.queued_at,
+    reviewed_at = NULL
+`
+
+type UpsertReviewItemParams struct {
+	RunID      sql.NullInt64
+	Filename   string
+	Result     string
+	Candidates string
+	QueuedAt   time.Time
+}
+
+func (q *Queries) UpsertReviewItem(ctx context.Context, arg UpsertReviewItemParams) error {
+	_, err := q.db.ExecContext(ctx, upsertReviewItem,
+		arg.RunID,
+		arg.Filename,
+		arg.Result,
+		arg.Candidates,
+		arg.QueuedAt,
+	)
+	return err
+}
+
+const upsertRunProgress = `-- name: UpsertRunProgress :exec
+INSERT INTO run_progress (run_id, snapshot, updated_at) VALUES (?, ?, ?)
+ON CONFLICT(run_id) DO UPDATE SET
+    snapshot = excluded.snapshot,
+    updated_at = excluded.updated_at
+`
+
+type UpsertRunProgressParams struct {
+	RunID     int64
+	Snapshot  string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpsertRunProgress(ctx context.Context, arg UpsertRunProgressParams) error {
+	_, err := q.db.ExecContext(ctx, upsertRunProgress, arg.RunID, arg.Snapshot, arg.UpdatedAt)
+	return err
+}
+
+const upsertRunResult = `-- name: UpsertRunResult :exec
+INSERT INTO run_results (
+    run_id, filename, success, error, error_code, title, issue_number, year,
+    match_confidence, comicvine_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+) ON CONFLICT(run_id, filename) DO UPDATE SET
+    success = excluded.success,
+    error = excluded.error,
+    error_code = excluded.error_code,
+    title = excluded.title,
+    issue_number = excluded.issue_number,
+    year = excluded.year,
+    match_confidence = excluded.match_confidence,
+    comicvine_id = excluded.comicvine_id
+`
+
+type UpsertRunResultParams struct {
+	RunID           int64
+	Filename        string
+	Success         bool
+	Error           sql.NullString
+	ErrorCode       sql.NullString
+	Title           sql.NullString
+	IssueNumber     sql.NullString
+	Year            sql.NullString
+	MatchConfidence sql.NullString
+	ComicvineID     sql.NullInt64
+}
+
+func (q *Queries) UpsertRunResult(ctx context.Context, arg UpsertRunResultParams) error {
+	_, err := q.db.ExecContext(ctx, upsertRunResult,
+		arg.RunID,
+		arg.Filename,
+		arg.Success,
+		arg.Error,
+		arg.ErrorCode,
+		arg.Title,
+		arg.IssueNumber,
+		arg.Year,
+		arg.MatchConfidence,
+		arg.ComicvineID,
+	)
+	return err
+}
+
+const upsertSeriesAlias = `-- name: UpsertSeriesAlias :exec
+INSERT INTO series_aliases (key, name, series, volume_id, created_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(key) DO UPDATE SET
+    name = excluded.name,
+    series = excluded.series,
+    volume_id = excluded.volume_id,
+    created_at = excluded.created_at
+`
+
+type UpsertSeriesAliasParams struct {
+	Key       string
+	Name      string
+	Series    string
+	VolumeID  sql.NullInt64
+	CreatedAt time.Time
+}
+
+func (q *Queries) UpsertSeriesAlias(ctx context.Context, arg UpsertSeriesAliasParams) error {
+	_, err := q.db.ExecContext(ctx, upsertSeriesAlias,
+		arg.Key,
+		arg.Name,
+		arg.Series,
+		arg.VolumeID,
+		arg.CreatedAt,
+	)
+	return err
+}
+
 const upsertVolume = `-- name: UpsertVolume :exec
 INSERT INTO comic_vine_volumes (
-    id, name, start_year, publisher_name, site_detail_url
+    id, name, start_year, publisher_name, site_detail_url, publisher_id, count_of_issues
 ) VALUES (
-    ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT(id) DO UPDATE SET
     name = excluded.name,
-    start_year = excluded.start_year,
-    publisher_name = excluded.publisher_name,
-    site_detail_url = excluded.site_detail_url
+    start_year = COALESCE(excluded.start_year, comic_vine_volumes.start_year),
+    publisher_name = COALESCE(excluded.publisher_name, comic_vine_volumes.publisher_name),
+    site_detail_url = COALESCE(excluded.site_detail_url, comic_vine_volumes.site_detail_url),
+    publisher_id = COALESCE(excluded.publisher_id, comic_vine_volumes.publisher_id),
+    count_of_issues = COALESCE(excluded.count_of_issues, comic_vine_volumes.count_of_issues)
 `
 
 type UpsertVolumeParams struct {
@@ -203,6 +2996,8 @@ type UpsertVolumeParams struct {
 	StartYear     sql.NullString
 	PublisherName sql.NullString
 	SiteDetailUrl sql.NullString
+	PublisherID   sql.NullInt64
+	CountOfIssues sql.NullInt64
 }
 
 func (q *Queries) UpsertVolume(ctx context.Context, arg UpsertVolumeParams) error {
@@ -212,45 +3007,23 @@ func (q *Queries) UpsertVolume(ctx context.Context, arg UpsertVolumeParams) erro
 		arg.StartYear,
 		arg.PublisherName,
 		arg.SiteDetailUrl,
+		arg.PublisherID,
+		arg.CountOfIssues,
 	)
 	return err
 }
 
-const listParsedFilenames = `-- name: ListParsedFilenames :many
-SELECT id, processing_result_id, parser_name, original_filename, title, issue_number, year, publisher, volume_number, confidence, notes FROM parsed_filenames ORDER BY id DESC
+const upsertVolumeSearch = `-- name: UpsertVolumeSearch :exec
+INSERT INTO volume_searches (query, fetched_at) VALUES (?, ?)
+ON CONFLICT(query) DO UPDATE SET fetched_at = excluded.fetched_at
 `
 
-func (q *Queries) ListParsedFilenames(ctx context.Context) ([]ParsedFilename, error) {
-	rows, err := q.db.QueryContext(ctx, listParsedFilenames)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ParsedFilename
-	for rows.Next() {
-		var i ParsedFilename
-		if err := rows.Scan(
-			&i.ID,
-			&i.ProcessingResultID,
-			&i.ParserName,
-			&i.OriginalFilename,
-			&i.Title,
-			&i.IssueNumber,
-			&i.Year,
-			&i.Publisher,
-			&i.VolumeNumber,
-			&i.Confidence,
-			&i.Notes,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type UpsertVolumeSearchParams struct {
+	Query     string
+	FetchedAt time.Time
+}
+
+func (q *Queries) UpsertVolumeSearch(ctx context.Context, arg UpsertVolumeSearchParams) error {
+	_, err := q.db.ExecContext(ctx, upsertVolumeSearch, arg.Query, arg.FetchedAt)
+	return err
 }
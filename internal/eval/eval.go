@@ -0,0 +1,260 @@
+// Package eval measures filename parsers against a golden set of labeled
+// filenames, so prompt and regex changes can be checked before a batch.
+package eval
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+)
+
+// Fields scored by an evaluation, in report order.
+const (
+	FieldTitle = "title"
+	FieldIssue = "issue"
+	FieldYear  = "year"
+)
+
+var allFields = []string{FieldTitle, FieldIssue, FieldYear}
+
+// Case is a labeled filename of a golden set.
+type Case struct {
+	Filename string
+	Expected map[string]string // expected value by field; only labeled fields are scored
+}
+
+// LoadGolden reads a golden set from CSV. The header names the columns:
+// filename is required, and any of title, issue and year are scored. An
+// empty cell in a scored column expects the parser to leave the field empty.
+func LoadGolden(r io.Reader) ([]Case, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	filenameCol, ok := columns["filename"]
+	if !ok {
+		return nil, errors.New("golden set has no filename column")
+	}
+	var fields []string
+	for _, field := range allFields {
+		if _, ok := columns[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("golden set has no title, issue or year column")
+	}
+
+	var cases []Case
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(record[filenameCol]) == "" {
+			continue
+		}
+		c := Case{Filename: record[filenameCol], Expected: make(map[string]string, len(fields))}
+		for _, field := range fields {
+			c.Expected[field] = strings.TrimSpace(record[columns[field]])
+		}
+		cases = append(cases, c)
+	}
+	if len(cases) == 0 {
+		return nil, errors.New("golden set has no filenames")
+	}
+	return cases, nil
+}
+
+// Mismatch is a field a parser got wrong.
+type Mismatch struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Got      string `json:"got"`
+}
+
+// CaseResult is the outcome of parsing one golden filename.
+type CaseResult struct {
+	Filename   string     `json:"filename"`
+	Error      string     `json:"error,omitempty"`
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+}
+
+// Correct reports whether every labeled field was parsed right.
+func (r CaseResult) Correct() bool {
+	return r.Error == "" && len(r.Mismatches) == 0
+}
+
+// FieldScore counts the correct parses of a field.
+type FieldScore struct {
+	Field   string  `json:"field"`
+	Correct int     `json:"correct"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// Report is the evaluation of one parser.
+type Report struct {
+	Parser  string       `json:"parser"`
+	Total   int          `json:"total"`
+	Correct int          `json:"correct"` // filenames with every field right
+	Percent float64      `json:"percent"`
+	Errors  int          `json:"errors"`
+	Fields  []FieldScore `json:"fields"`
+	Results []CaseResult `json:"results"` // in golden set order
+}
+
+// Run parses every case with p, using up to workers goroutines, and scores
+// the parses. A parse error counts every labeled field of the case as
+// wrong. Only a cancelled context stops the run.
+func Run(ctx context.Context, name string, p parser.Parser, cases []Case, workers int) (*Report, error) {
+	results := make([]CaseResult, len(cases))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = evaluate(ctx, p, cases[i])
+			}
+		}()
+	}
+	for i := range cases {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Parser: name, Total: len(cases), Results: results}
+	scores := make(map[string]*FieldScore)
+	for _, field := range allFields {
+		scores[field] = &FieldScore{Field: field}
+	}
+	for i, r := range results {
+		wrong := make(map[string]bool)
+		for _, m := range r.Mismatches {
+			wrong[m.Field] = true
+		}
+		for field := range cases[i].Expected {
+			scores[field].Total++
+			if r.Error == "" && !wrong[field] {
+				scores[field].Correct++
+			}
+		}
+		if r.Error != "" {
+			report.Errors++
+		}
+		if r.Correct() {
+			report.Correct++
+		}
+	}
+	report.Percent = percent(report.Correct, report.Total)
+	for _, field := range allFields {
+		if s := scores[field]; s.Total > 0 {
+			s.Percent = percent(s.Correct, s.Total)
+			report.Fields = append(report.Fields, *s)
+		}
+	}
+	return report, nil
+}
+
+// evaluate parses one case and compares the labeled fields.
+func evaluate(ctx context.Context, p parser.Parser, c Case) CaseResult {
+	result := CaseResult{Filename: c.Filename}
+	parsed, err := p.Parse(ctx, &models.ParsedFilename{OriginalFilename: c.Filename})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	got := map[string]string{
+		FieldTitle: parsed.Title,
+		FieldIssue: parsed.IssueNumber,
+		FieldYear:  parsed.Year,
+	}
+	for _, field := range allFields {
+		want, ok := c.Expected[field]
+		if ok && !equal(field, want, got[field]) {
+			result.Mismatches = append(result.Mismatches, Mismatch{Field: field, Expected: want, Got: got[field]})
+		}
+	}
+	return result
+}
+
+// equal compares a parsed field with its label, ignoring case and spacing
+// in titles and zero padding in issue numbers.
+func equal(field, want, got string) bool {
+	switch field {
+	case FieldTitle:
+		return strings.EqualFold(strings.Join(strings.Fields(want), " "), strings.Join(strings.Fields(got), " "))
+	case FieldIssue:
+		return normalizeIssue(want) == normalizeIssue(got)
+	}
+	return strings.TrimSpace(want) == strings.TrimSpace(got)
+}
+
+// normalizeIssue drops the "#" and zero padding of an issue number,
+// keeping a lone "0".
+func normalizeIssue(issue string) string {
+	issue = strings.TrimPrefix(strings.TrimSpace(issue), "#")
+	if issue == "" {
+		return ""
+	}
+	issue = strings.TrimLeft(issue, "0")
+	if issue == "" || issue[0] == '.' {
+		issue = "0" + issue
+	}
+	return issue
+}
+
+// Disagreement is a filename that exactly one of two parsers got right.
+type Disagreement struct {
+	Filename string `json:"filename"`
+	Right    string `json:"right"` // the parser that got every field right
+	Wrong    string `json:"wrong"`
+}
+
+// Compare lists the filenames that one of two reports over the same golden
+// set got right and the other didn't.
+func Compare(a, b *Report) []Disagreement {
+	var diffs []Disagreement
+	for i := range a.Results {
+		switch ra, rb := a.Results[i].Correct(), b.Results[i].Correct(); {
+		case ra && !rb:
+			diffs = append(diffs, Disagreement{Filename: a.Results[i].Filename, Right: a.Parser, Wrong: b.Parser})
+		case rb && !ra:
+			diffs = append(diffs, Disagreement{Filename: a.Results[i].Filename, Right: b.Parser, Wrong: a.Parser})
+		}
+	}
+	return diffs
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
@@ -0,0 +1,99 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"comic-parser/internal/models"
+)
+
+// stubParser parses filenames from a fixed table, failing for the rest.
+type stubParser map[string]models.ParsedFilename
+
+func (s stubParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	parsed, ok := s[input.OriginalFilename]
+	if !ok {
+		return nil, errors.New("no parse")
+	}
+	return &parsed, nil
+}
+
+const golden = `filename,title,issue,year
+Saga 001 (2012).cbz,Saga,1,2012
+"Batman, Vol. 3 050.cbz",Batman,50,
+X-Men 1.cbz,X-Men,1,1991
+`
+
+func TestLoadGolden(t *testing.T) {
+	cases, err := LoadGolden(strings.NewReader(golden))
+	if err != nil {
+		t.Fatalf("LoadGolden failed: %v", err)
+	}
+	if len(cases) != 3 || cases[1].Filename != "Batman, Vol. 3 050.cbz" ||
+		!reflect.DeepEqual(cases[1].Expected, map[string]string{FieldTitle: "Batman", FieldIssue: "50", FieldYear: ""}) {
+		t.Errorf("Unexpected cases %+v", cases)
+	}
+
+	// Only labeled fields are scored
+	cases, err = LoadGolden(strings.NewReader("Filename,Issue\nSaga 001.cbz,1\n"))
+	if err != nil || len(cases[0].Expected) != 1 || cases[0].Expected[FieldIssue] != "1" {
+		t.Errorf("LoadGolden = %+v, %v", cases, err)
+	}
+
+	for _, bad := range []string{"", "title,issue\nSaga,1\n", "filename,notes\na.cbz,x\n", "filename,title\n"} {
+		if _, err := LoadGolden(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadGolden(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRunAndCompare(t *testing.T) {
+	cases, err := LoadGolden(strings.NewReader(golden))
+	if err != nil {
+		t.Fatalf("LoadGolden failed: %v", err)
+	}
+	ctx := context.Background()
+
+	regex, err := Run(ctx, "regex", stubParser{
+		"Saga 001 (2012).cbz":    {Title: "saga ", IssueNumber: "001", Year: "2012"},
+		"Batman, Vol. 3 050.cbz": {Title: "Batman, Vol. 3", IssueNumber: "50"},
+	}, cases, 2)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if regex.Correct != 1 || regex.Errors != 1 || regex.Percent < 33 || regex.Percent > 34 {
+		t.Errorf("Unexpected report %+v", regex)
+	}
+	wantFields := []FieldScore{
+		{Field: FieldTitle, Correct: 1, Total: 3, Percent: 100.0 / 3},
+		{Field: FieldIssue, Correct: 2, Total: 3, Percent: 200.0 / 3},
+		{Field: FieldYear, Correct: 2, Total: 3, Percent: 200.0 / 3},
+	}
+	if !reflect.DeepEqual(regex.Fields, wantFields) {
+		t.Errorf("Fields = %+v, want %+v", regex.Fields, wantFields)
+	}
+	wantMismatch := []Mismatch{{Field: FieldTitle, Expected: "Batman", Got: "Batman, Vol. 3"}}
+	if !reflect.DeepEqual(regex.Results[1].Mismatches, wantMismatch) || regex.Results[2].Error == "" {
+		t.Errorf("Unexpected results %+v", regex.Results)
+	}
+
+	llm, err := Run(ctx, "llm", stubParser{
+		"Saga 001 (2012).cbz":    {Title: "Saga", IssueNumber: "2", Year: "2012"},
+		"Batman, Vol. 3 050.cbz": {Title: "Batman", IssueNumber: "50"},
+		"X-Men 1.cbz":            {Title: "X-Men", IssueNumber: "1", Year: "1991"},
+	}, cases, 1)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := []Disagreement{
+		{Filename: "Saga 001 (2012).cbz", Right: "regex", Wrong: "llm"},
+		{Filename: "Batman, Vol. 3 050.cbz", Right: "llm", Wrong: "regex"},
+		{Filename: "X-Men 1.cbz", Right: "llm", Wrong: "regex"},
+	}
+	if got := Compare(regex, llm); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare = %+v, want %+v", got, want)
+	}
+}
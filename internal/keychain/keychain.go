@@ -0,0 +1,173 @@
+// Package keychain keeps secrets such as API keys in the operating system's
+// credential store instead of the config file: the login keychain on macOS,
+// through the security tool, and the Secret Service (GNOME Keyring, KWallet)
+// on Linux and BSD, through libsecret's secret-tool.
+package keychain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Service names the entries comic-parser stores; each secret is an account
+// under it.
+const Service = "comic-parser"
+
+// timeout bounds a keychain command, which may wait on an unlock prompt.
+const timeout = 30 * time.Second
+
+var (
+	// ErrNotFound is returned by Get when no secret is stored for an account.
+	ErrNotFound = errors.New("keychain: secret not found")
+	// ErrUnsupported is returned by Open on systems without a supported
+	// credential store.
+	ErrUnsupported = errors.New("keychain: no supported credential store on " + runtime.GOOS)
+)
+
+// Keychain stores secrets by account name.
+type Keychain interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// runner runs a command with stdin and returns its standard output. Tests
+// replace it.
+type runner func(stdin string, name string, args ...string) (string, error)
+
+// Open returns the credential store of this system.
+func Open() (Keychain, error) {
+	return open(runtime.GOOS, exec.LookPath, run)
+}
+
+func open(goos string, lookPath func(string) (string, error), r runner) (Keychain, error) {
+	var tool string
+	var kc Keychain
+	switch goos {
+	case "darwin":
+		tool, kc = "security", macKeychain{run: r}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		tool, kc = "secret-tool", secretService{run: r}
+	default:
+		return nil, ErrUnsupported
+	}
+	if _, err := lookPath(tool); err != nil {
+		return nil, fmt.Errorf("keychain: %s not found; install it or keep keys in the config file", tool)
+	}
+	return kc, nil
+}
+
+// exitCoder is implemented by *exec.ExitError.
+type exitCoder interface{ ExitCode() int }
+
+func run(stdin string, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// exitCode returns the exit code of a failed command, or -1.
+func exitCode(err error) int {
+	var exit exitCoder
+	if errors.As(err, &exit) {
+		return exit.ExitCode()
+	}
+	return -1
+}
+
+// macKeychain stores generic passwords in the login keychain.
+type macKeychain struct {
+	run runner
+}
+
+// errSecItemNotFound is security's exit code for a missing item
+const errSecItemNotFound = 44
+
+func (k macKeychain) Get(account string) (string, error) {
+	out, err := k.run("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	if exitCode(err) == errSecItemNotFound {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keychain: read %s: %w", account, err)
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (k macKeychain) Set(account, secret string) error {
+	// security only takes the password as an argument, so the command goes
+	// to its interactive mode on stdin to keep the secret out of the process
+	// list. -U updates an existing item
+	if strings.ContainsAny(account+secret, "\r\n") {
+		return fmt.Errorf("keychain: store %s: line breaks are not allowed", account)
+	}
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		securityQuote(Service), securityQuote(account), securityQuote(secret))
+	if _, err := k.run(command, "security", "-i"); err != nil {
+		return fmt.Errorf("keychain: store %s: %w", account, err)
+	}
+	return nil
+}
+
+// securityQuote quotes s as one argument of an interactive security command.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (k macKeychain) Delete(account string) error {
+	_, err := k.run("", "security", "delete-generic-password", "-s", Service, "-a", account)
+	if err != nil && exitCode(err) != errSecItemNotFound {
+		return fmt.Errorf("keychain: delete %s: %w", account, err)
+	}
+	return nil
+}
+
+// secretService stores secrets with the Secret Service API through
+// secret-tool, which takes the secret on stdin.
+type secretService struct {
+	run runner
+}
+
+func (k secretService) Get(account string) (string, error) {
+	out, err := k.run("", "secret-tool", "lookup", "service", Service, "account", account)
+	// secret-tool exits 1 without output for a missing secret
+	if exitCode(err) == 1 || (err == nil && out == "") {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keychain: read %s: %w", account, err)
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (k secretService) Set(account, secret string) error {
+	label := fmt.Sprintf("%s %s", Service, account)
+	if _, err := k.run(secret, "secret-tool", "store", "--label", label, "service", Service, "account", account); err != nil {
+		return fmt.Errorf("keychain: store %s: %w", account, err)
+	}
+	return nil
+}
+
+func (k secretService) Delete(account string) error {
+	_, err := k.run("", "secret-tool", "clear", "service", Service, "account", account)
+	if err != nil && exitCode(err) != 1 {
+		return fmt.Errorf("keychain: delete %s: %w", account, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package keychain
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type exitError int
+
+func (e exitError) Error() string { return "exit status" }
+func (e exitError) ExitCode() int { return int(e) }
+
+// fakeRunner records commands and keeps secrets by account.
+type fakeRunner struct {
+	calls   [][]string
+	stdin   []string
+	secrets map[string]string
+	missing int // exit code for a missing secret
+}
+
+func (f *fakeRunner) run(stdin string, name string, args ...string) (string, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	f.stdin = append(f.stdin, stdin)
+	switch args[0] {
+	case "-i":
+		// add-generic-password -U -s "service" -a "account" -w "secret"
+		fields := strings.Fields(stdin)
+		f.secrets[strings.Trim(fields[5], `"`)] = strings.Trim(fields[7], `"`)
+	case "store":
+		f.secrets[args[6]] = stdin
+	case "find-generic-password", "lookup", "delete-generic-password", "clear":
+		account := args[4]
+		secret, ok := f.secrets[account]
+		if !ok {
+			return "", exitError(f.missing)
+		}
+		if args[0] == "delete-generic-password" || args[0] == "clear" {
+			delete(f.secrets, account)
+			return "", nil
+		}
+		return secret + "\n", nil
+	}
+	return "", nil
+}
+
+func TestKeychains(t *testing.T) {
+	tests := []struct {
+		goos    string
+		missing int
+		set     []string
+		stdin   string
+	}{
+		{"darwin", errSecItemNotFound, []string{"security", "-i"}, `add-generic-password -U -s "comic-parser" -a "key" -w "s3cret"` + "\n"},
+		{"linux", 1, []string{"secret-tool", "store", "--label", "comic-parser key", "service", Service, "account", "key"}, "s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			f := &fakeRunner{secrets: map[string]string{}, missing: tt.missing}
+			found := func(string) (string, error) { return "/usr/bin/tool", nil }
+			kc, err := open(tt.goos, found, f.run)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+
+			if _, err := kc.Get("key"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound before Set, got %v", err)
+			}
+			if err := kc.Set("key", "s3cret"); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if got := f.calls[len(f.calls)-1]; !reflect.DeepEqual(got, tt.set) {
+				t.Errorf("Set ran %v, want %v", got, tt.set)
+			}
+			if got := f.stdin[len(f.stdin)-1]; got != tt.stdin {
+				t.Errorf("Set wrote %q to stdin, want %q", got, tt.stdin)
+			}
+			if got, err := kc.Get("key"); err != nil || got != "s3cret" {
+				t.Errorf("Get = %q, %v; want s3cret", got, err)
+			}
+			if err := kc.Delete("key"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if err := kc.Delete("key"); err != nil {
+				t.Errorf("Expected deleting a missing secret to succeed, got %v", err)
+			}
+			if _, err := kc.Get("key"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMacKeychain_SetQuoting(t *testing.T) {
+	var stdin string
+	kc := macKeychain{run: func(in string, name string, args ...string) (string, error) {
+		stdin = in
+		return "", nil
+	}}
+	if err := kc.Set("key", `a "b" \c`); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if want := `-w "a \"b\" \\c"` + "\n"; !strings.HasSuffix(stdin, want) {
+		t.Errorf("Set wrote %q, want it to end with %q", stdin, want)
+	}
+	if err := kc.Set("key", "a\nb"); err == nil {
+		t.Error("Expected a secret with a line break to be refused")
+	}
+}
+
+func TestGetError(t *testing.T) {
+	failing := func(string, string, ...string) (string, error) { return "", exitError(51) }
+	kc := macKeychain{run: failing}
+	if _, err := kc.Get("key"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a read error, got %v", err)
+	}
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	found := func(string) (string, error) { return "/usr/bin/tool", nil }
+	if _, err := open("windows", found, nil); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported on windows, got %v", err)
+	}
+
+	missing := func(string) (string, error) { return "", errors.New("not found") }
+	_, err := open("linux", missing, nil)
+	if err == nil || !strings.Contains(err.Error(), "secret-tool") {
+		t.Errorf("Expected an error naming secret-tool, got %v", err)
+	}
+}
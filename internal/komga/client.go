@@ -0,0 +1,271 @@
+// Package komga provides a small client for the Komga comic server's REST
+// API, used to push matched metadata onto books already in a Komga library.
+package komga
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+const (
+	userAgentValue  = "ComicParser/1.0"
+	headerUserAgent = "User-Agent"
+	headerAPIKey    = "X-API-Key"
+
+	// pageSize is the number of books requested per page when listing
+	pageSize = 500
+
+	// linkLabel labels the ComicVine page in pushed book links
+	linkLabel = "ComicVine"
+)
+
+// ErrNotFound is returned when Komga has no such resource.
+var ErrNotFound = errors.New("komga: not found")
+
+// HTTPClient defines the interface for making HTTP requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a Komga API client. It authenticates with an API key when one
+// is configured, otherwise with the username and password.
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	apiKey     string
+	httpClient HTTPClient
+}
+
+// NewClient creates a Komga client for the server at cfg.KomgaURL.
+func NewClient(cfg *config.Config, httpClient HTTPClient) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.KomgaURL, "/"),
+		username:   cfg.KomgaUsername,
+		password:   cfg.KomgaPassword,
+		apiKey:     cfg.KomgaAPIKey,
+		httpClient: httpClient,
+	}
+}
+
+// Book is a book in a Komga library. URL is the file's path on the server.
+type Book struct {
+	ID        string `json:"id"`
+	SeriesID  string `json:"seriesId"`
+	LibraryID string `json:"libraryId"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+}
+
+// Filename returns the base name of the book's file.
+func (b Book) Filename() string {
+	// Komga may run on Windows, so split on either separator
+	return b.URL[strings.LastIndexAny(b.URL, `/\`)+1:]
+}
+
+// Author is a credited creator. Role is one of Komga's lower-case roles,
+// e.g. "writer" or "penciller".
+type Author struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// Link is a labelled web link shown with a book.
+type Link struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// BookMetadata is a partial update of a book's metadata. Empty fields are
+// left unchanged on the server.
+type BookMetadata struct {
+	Title       string   `json:"title,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Number      string   `json:"number,omitempty"`
+	NumberSort  *float64 `json:"numberSort,omitempty"`
+	ReleaseDate string   `json:"releaseDate,omitempty"` // YYYY-MM-DD
+	Authors     []Author `json:"authors,omitempty"`
+	Links       []Link   `json:"links,omitempty"`
+}
+
+// SeriesMetadata is a partial update of a series' metadata. Empty fields are
+// left unchanged on the server.
+type SeriesMetadata struct {
+	Title     string `json:"title,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+}
+
+// ListBooks returns every book on the server, or only those of the library
+// with libraryID when it is not empty.
+func (c *Client) ListBooks(ctx context.Context, libraryID string) ([]Book, error) {
+	var books []Book
+	for page := 0; ; page++ {
+		params := url.Values{}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("size", strconv.Itoa(pageSize))
+		if libraryID != "" {
+			params.Set("library_id", libraryID)
+		}
+
+		var result struct {
+			Content []Book `json:"content"`
+			Last    bool   `json:"last"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/api/v1/books?"+params.Encode(), nil, &result); err != nil {
+			return nil, fmt.Errorf("listing books: %w", err)
+		}
+		books = append(books, result.Content...)
+		if result.Last || len(result.Content) == 0 {
+			return books, nil
+		}
+	}
+}
+
+// UpdateBookMetadata applies metadata to the book with the given ID.
+func (c *Client) UpdateBookMetadata(ctx context.Context, bookID string, metadata *BookMetadata) error {
+	if err := c.do(ctx, http.MethodPatch, "/api/v1/books/"+url.PathEscape(bookID)+"/metadata", metadata, nil); err != nil {
+		return fmt.Errorf("updating book %s: %w", bookID, err)
+	}
+	return nil
+}
+
+// UpdateSeriesMetadata applies metadata to the series with the given ID.
+func (c *Client) UpdateSeriesMetadata(ctx context.Context, seriesID string, metadata *SeriesMetadata) error {
+	if err := c.do(ctx, http.MethodPatch, "/api/v1/series/"+url.PathEscape(seriesID)+"/metadata", metadata, nil); err != nil {
+		return fmt.Errorf("updating series %s: %w", seriesID, err)
+	}
+	return nil
+}
+
+// BookMetadataFor builds the book metadata of a matched issue: its name,
+// description, number, cover date, credits, and links to the ComicVine page
+// and cover image.
+func BookMetadataFor(issue *models.ComicVineIssue) *BookMetadata {
+	metadata := &BookMetadata{
+		Title:   issue.Name,
+		Summary: issue.Description,
+		Number:  issue.IssueNumber,
+	}
+	if n, err := strconv.ParseFloat(issue.IssueNumber, 64); err == nil {
+		metadata.NumberSort = &n
+	}
+	if date := issue.CoverDate; date.Year > 0 {
+		month, day := max(date.Month, 1), max(date.Day, 1)
+		metadata.ReleaseDate = fmt.Sprintf("%04d-%02d-%02d", date.Year, month, day)
+	}
+	for _, credit := range issue.Credits {
+		for _, role := range strings.Split(credit.Role, ",") {
+			for _, komgaRole := range authorRoles(strings.ToLower(strings.TrimSpace(role))) {
+				author := Author{Name: credit.Name, Role: komgaRole}
+				if !slices.Contains(metadata.Authors, author) {
+					metadata.Authors = append(metadata.Authors, author)
+				}
+			}
+		}
+	}
+	if issue.SiteDetailURL != "" {
+		metadata.Links = append(metadata.Links, Link{Label: linkLabel, URL: issue.SiteDetailURL})
+	}
+	if cover := coverURL(issue.Image); cover != "" {
+		metadata.Links = append(metadata.Links, Link{Label: "Cover", URL: cover})
+	}
+	return metadata
+}
+
+// SeriesMetadataFor builds the series metadata of a matched issue's volume.
+func SeriesMetadataFor(issue *models.ComicVineIssue) *SeriesMetadata {
+	return &SeriesMetadata{
+		Title:     issue.Volume.Name,
+		Publisher: issue.Volume.Publisher,
+	}
+}
+
+// authorRoles returns the Komga roles a ComicVine credit role maps to.
+func authorRoles(role string) []string {
+	switch role {
+	case "writer", "inker", "colorist", "letterer", "cover", "editor":
+		return []string{role}
+	case "penciler", "penciller":
+		return []string{"penciller"}
+	case "artist":
+		return []string{"penciller", "inker"}
+	}
+	return nil
+}
+
+// coverURL picks the largest available cover image.
+func coverURL(image models.ImageRef) string {
+	for _, u := range []string{image.LargeURL, image.MediumURL, image.SmallURL} {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// do sends an authenticated request with body encoded as JSON and decodes
+// the response into out when it is not nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if c.apiKey != "" {
+		req.Header.Set(headerAPIKey, c.apiKey)
+	} else {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	req.Header.Set(headerUserAgent, userAgentValue)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", path, ErrNotFound)
+	case resp.StatusCode == http.StatusUnauthorized:
+		return errors.New("komga: invalid credentials")
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package komga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+func newTestClient(t *testing.T, cfg *config.Config, handler http.HandlerFunc) *Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	cfg.KomgaURL = ts.URL + "/"
+	return NewClient(cfg, ts.Client())
+}
+
+func TestListBooks(t *testing.T) {
+	client := newTestClient(t, &config.Config{KomgaUsername: "user", KomgaPassword: "pass"}, func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("Unexpected basic auth %q/%q", user, pass)
+		}
+		if r.URL.Path != "/api/v1/books" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if lib := r.URL.Query().Get("library_id"); lib != "lib1" {
+			t.Errorf("Expected library_id lib1, got %q", lib)
+		}
+		switch r.URL.Query().Get("page") {
+		case "0":
+			w.Write([]byte(`{"content":[{"id":"b1","seriesId":"s1","libraryId":"lib1","name":"Saga 001","url":"/comics/Saga/Saga 001.cbz"}],"last":false}`))
+		case "1":
+			w.Write([]byte(`{"content":[{"id":"b2","seriesId":"s1","libraryId":"lib1","name":"Saga 002","url":"D:\\comics\\Saga 002.cbz"}],"last":true}`))
+		default:
+			t.Errorf("Unexpected page %s", r.URL.Query().Get("page"))
+		}
+	})
+
+	books, err := client.ListBooks(context.Background(), "lib1")
+	if err != nil {
+		t.Fatalf("ListBooks failed: %v", err)
+	}
+	if len(books) != 2 {
+		t.Fatalf("Expected 2 books across pages, got %d", len(books))
+	}
+	if books[0].Filename() != "Saga 001.cbz" || books[1].Filename() != "Saga 002.cbz" {
+		t.Errorf("Unexpected file names %q, %q", books[0].Filename(), books[1].Filename())
+	}
+}
+
+func TestUpdateBookMetadata(t *testing.T) {
+	var got BookMetadata
+	client := newTestClient(t, &config.Config{KomgaAPIKey: "secret"}, func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get(headerAPIKey); key != "secret" {
+			t.Errorf("Expected API key header, got %q", key)
+		}
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Error("Expected no basic auth with an API key")
+		}
+		if r.URL.Path != "/api/v1/books/b1/metadata" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPatch {
+			t.Errorf("Expected PATCH, got %s", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Decoding body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	metadata := &BookMetadata{Title: "Chapter One", Number: "1"}
+	if err := client.UpdateBookMetadata(context.Background(), "b1", metadata); err != nil {
+		t.Fatalf("UpdateBookMetadata failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, *metadata) {
+		t.Errorf("Server got %+v, want %+v", got, *metadata)
+	}
+
+	if err := client.UpdateSeriesMetadata(context.Background(), "missing", &SeriesMetadata{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing series, got %v", err)
+	}
+}
+
+func TestBookMetadataFor(t *testing.T) {
+	issue := &models.ComicVineIssue{
+		ID:            1,
+		Name:          "Chapter One",
+		IssueNumber:   "1",
+		CoverDate:     models.Date{Year: 2012, Month: 3},
+		Description:   "Alana and Marko flee.",
+		SiteDetailURL: "https://comicvine.gamespot.com/saga-1/4000-1/",
+		Image:         models.ImageRef{SmallURL: "https://example.com/small.jpg", LargeURL: "https://example.com/large.jpg"},
+		Volume:        models.VolumeRef{Name: "Saga", Publisher: "Image"},
+		Credits: []models.Credit{
+			{Name: "Brian K. Vaughan", Role: "writer"},
+			{Name: "Fiona Staples", Role: "artist, cover"},
+		},
+	}
+
+	metadata := BookMetadataFor(issue)
+	if metadata.Title != "Chapter One" || metadata.Summary != "Alana and Marko flee." || metadata.Number != "1" {
+		t.Errorf("Unexpected metadata %+v", metadata)
+	}
+	if metadata.NumberSort == nil || *metadata.NumberSort != 1 {
+		t.Errorf("Expected numberSort 1, got %v", metadata.NumberSort)
+	}
+	if metadata.ReleaseDate != "2012-03-01" {
+		t.Errorf("Expected release date 2012-03-01, got %q", metadata.ReleaseDate)
+	}
+	wantAuthors := []Author{
+		{"Brian K. Vaughan", "writer"},
+		{"Fiona Staples", "penciller"},
+		{"Fiona Staples", "inker"},
+		{"Fiona Staples", "cover"},
+	}
+	if !reflect.DeepEqual(metadata.Authors, wantAuthors) {
+		t.Errorf("Authors = %v, want %v", metadata.Authors, wantAuthors)
+	}
+	wantLinks := []Link{
+		{"ComicVine", "https://comicvine.gamespot.com/saga-1/4000-1/"},
+		{"Cover", "https://example.com/large.jpg"},
+	}
+	if !reflect.DeepEqual(metadata.Links, wantLinks) {
+		t.Errorf("Links = %v, want %v", metadata.Links, wantLinks)
+	}
+
+	if series := SeriesMetadataFor(issue); series.Title != "Saga" || series.Publisher != "Image" {
+		t.Errorf("Unexpected series metadata %+v", series)
+	}
+}
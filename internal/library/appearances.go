@@ -0,0 +1,96 @@
+package library
+
+import (
+	"sort"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+// Appearance is a stored file whose matched issue credits a creator or
+// features a character.
+type Appearance struct {
+	Filename    string `json:"filename"`
+	Path        string `json:"path,omitempty"`
+	ComicVineID int    `json:"comicvine_id"`
+	Series      string `json:"series"`
+	IssueNumber string `json:"issue_number"`
+	CoverDate   string `json:"cover_date,omitempty"`
+	Name        string `json:"name"`           // the credited name that matched
+	Role        string `json:"role,omitempty"` // creators only
+}
+
+// FindCreatorAppearances returns the stored files whose matched issue
+// credits a creator whose name contains name, ignoring case. Issues are only
+// credited once their details were fetched; see `comic-parser enrich`.
+func FindCreatorAppearances(matched []*models.ProcessingResult, name string) []Appearance {
+	return findAppearances(matched, name, func(issue *models.ComicVineIssue) (names, roles []string) {
+		for _, c := range issue.Credits {
+			names = append(names, c.Name)
+			roles = append(roles, c.Role)
+		}
+		return names, roles
+	})
+}
+
+// FindCharacterAppearances returns the stored files whose matched issue
+// features a character whose name contains name, ignoring case.
+func FindCharacterAppearances(matched []*models.ProcessingResult, name string) []Appearance {
+	return findAppearances(matched, name, func(issue *models.ComicVineIssue) (names, roles []string) {
+		for _, c := range issue.Characters {
+			names = append(names, c.Name)
+		}
+		return names, nil
+	})
+}
+
+// findAppearances returns an appearance per file and matching name listed by
+// credits, sorted by series and issue number.
+func findAppearances(matched []*models.ProcessingResult, name string, credits func(*models.ComicVineIssue) (names, roles []string)) []Appearance {
+	want := foldName(name)
+	if want == "" {
+		return nil
+	}
+
+	var result []Appearance
+	for _, r := range matched {
+		if r.Match == nil || r.Match.SelectedIssue == nil {
+			continue
+		}
+		issue := r.Match.SelectedIssue
+		names, roles := credits(issue)
+		for i, n := range names {
+			if !strings.Contains(foldName(n), want) {
+				continue
+			}
+			a := Appearance{
+				Filename:    r.Filename,
+				Path:        r.Path,
+				ComicVineID: issue.ID,
+				Series:      issue.Volume.Name,
+				IssueNumber: issue.IssueNumber,
+				Name:        n,
+			}
+			if !issue.CoverDate.IsZero() {
+				a.CoverDate = issue.CoverDate.String()
+			}
+			if i < len(roles) {
+				a.Role = roles[i]
+			}
+			result = append(result, a)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if sa, sb := strings.ToLower(a.Series), strings.ToLower(b.Series); sa != sb {
+			return sa < sb
+		}
+		return issueLess(a.IssueNumber, b.IssueNumber)
+	})
+	return result
+}
+
+// foldName lowercases a name and collapses its whitespace
+func foldName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
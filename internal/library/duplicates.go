@@ -0,0 +1,193 @@
+package library
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+// Release tags that say how a file was made, best first. Digital releases
+// are the publisher's own pages; c2c scans include every cover.
+const (
+	TagDigital = "digital"
+	TagC2C     = "c2c"
+)
+
+// tagRe finds the parenthesized or bracketed tags of a filename
+var tagRe = regexp.MustCompile(`[(\[]([^)\]]+)[)\]]`)
+
+// DuplicateFile is one of the stored files of a DuplicateGroup.
+type DuplicateFile struct {
+	Filename string   `json:"filename"`
+	Path     string   `json:"path,omitempty"`
+	Size     int64    `json:"size,omitempty"` // 0 when the file isn't available locally
+	Tags     []string `json:"tags,omitempty"` // release tags, see Tag* constants
+	Keep     bool     `json:"keep"`           // the copy FindDuplicates recommends keeping
+}
+
+// DuplicateGroup is a set of stored files that resolve to the same issue.
+type DuplicateGroup struct {
+	// Key identifies the issue: its ComicVine ID for matched files, or the
+	// parsed series and issue number for unmatched ones.
+	Key         string          `json:"key"`
+	ComicVineID int             `json:"comicvine_id,omitempty"`
+	Series      string          `json:"series"`
+	IssueNumber string          `json:"issue_number"`
+	Files       []DuplicateFile `json:"files"`
+}
+
+// FindDuplicates groups stored files resolving to the same issue: matched
+// results by their ComicVine issue, and parses of files without a match by
+// series and issue number. Only groups of two or more files are returned,
+// sorted by series and issue. Within a group, files are ordered best first
+// and the first is marked to keep: a digital release beats a c2c scan,
+// which beats any other, and a larger file wins among equals. Sizes are
+// read from the files' paths when they exist locally.
+//
+// The later issues of multi-issue files are skipped, since the file is
+// represented by its first issue.
+func FindDuplicates(matched []*models.ProcessingResult, parsed []*models.ParsedFilename) []DuplicateGroup {
+	groups := make(map[string]*DuplicateGroup)
+	var keys []string
+	add := func(key string, group DuplicateGroup, filename, path string) {
+		g, ok := groups[key]
+		if !ok {
+			group.Key = key
+			g = &group
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.Files = append(g.Files, duplicateFile(filename, path))
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range matched {
+		seen[r.Filename] = true
+		if r.LaterIssue() || r.Match == nil || r.Match.SelectedIssue == nil {
+			continue
+		}
+		issue := r.Match.SelectedIssue
+		add(fmt.Sprintf("comicvine:%d", issue.ID), DuplicateGroup{
+			ComicVineID: issue.ID,
+			Series:      issue.Volume.Name,
+			IssueNumber: issue.IssueNumber,
+		}, r.Filename, r.Path)
+	}
+	for _, p := range parsed {
+		// A file may have been parsed by several parsers
+		if seen[p.OriginalFilename] || p.Title == "" || p.IssueNumber == "" {
+			continue
+		}
+		seen[p.OriginalFilename] = true
+		key := "parsed:" + strings.ToLower(strings.Join(strings.Fields(p.Title), " ")) + " #" + normalizeIssue(p.IssueNumber)
+		add(key, DuplicateGroup{Series: p.Title, IssueNumber: p.IssueNumber}, p.OriginalFilename, p.Path)
+	}
+
+	var result []DuplicateGroup
+	for _, key := range keys {
+		g := groups[key]
+		if len(g.Files) < 2 {
+			continue
+		}
+		rankFiles(g.Files)
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if a, b := strings.ToLower(result[i].Series), strings.ToLower(result[j].Series); a != b {
+			return a < b
+		}
+		return result[i].IssueNumber < result[j].IssueNumber
+	})
+	return result
+}
+
+// FindIdenticalFiles groups stored files with the same content hash,
+// whatever their names, such as a file downloaded again under a new name.
+// Results without a hash are ignored. Only groups of two or more files are
+// returned, in the order their hashes first appear; the Key of a group is
+// "sha256:" and the hash. Files are ordered and marked to keep as in
+// FindDuplicates.
+func FindIdenticalFiles(hashed []*models.ProcessingResult) []DuplicateGroup {
+	groups := make(map[string]*DuplicateGroup)
+	var keys []string
+	for _, r := range hashed {
+		if r.FileHash == "" {
+			continue
+		}
+		key := "sha256:" + r.FileHash
+		g, ok := groups[key]
+		if !ok {
+			g = &DuplicateGroup{Key: key}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		f := duplicateFile(r.Filename, "")
+		f.Path, f.Size = r.Path, r.FileSize
+		g.Files = append(g.Files, f)
+	}
+
+	var result []DuplicateGroup
+	for _, key := range keys {
+		if g := groups[key]; len(g.Files) > 1 {
+			rankFiles(g.Files)
+			result = append(result, *g)
+		}
+	}
+	return result
+}
+
+// rankFiles orders the files of a group best first and marks the first to
+// keep.
+func rankFiles(files []DuplicateFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if ra, rb := tagRank(a.Tags), tagRank(b.Tags); ra != rb {
+			return ra > rb
+		}
+		return a.Size > b.Size
+	})
+	files[0].Keep = true
+}
+
+func duplicateFile(filename, path string) DuplicateFile {
+	f := DuplicateFile{Filename: filename, Path: path}
+	for _, m := range tagRe.FindAllStringSubmatch(filename, -1) {
+		tag := strings.ToLower(strings.TrimSpace(m[1]))
+		switch {
+		case strings.Contains(tag, TagDigital):
+			f.Tags = append(f.Tags, TagDigital)
+		case tag == TagC2C:
+			f.Tags = append(f.Tags, TagC2C)
+		}
+	}
+	if path != "" {
+		if info, err := os.Stat(path); err == nil {
+			f.Size = info.Size()
+		}
+	}
+	return f
+}
+
+// tagRank orders files by their best release tag
+func tagRank(tags []string) int {
+	rank := 0
+	for _, tag := range tags {
+		switch tag {
+		case TagDigital:
+			rank = max(rank, 2)
+		case TagC2C:
+			rank = max(rank, 1)
+		}
+	}
+	return rank
+}
+
+// normalizeIssue returns the key an issue number groups by, so "001", "1"
+// and the variant "1A" group together.
+func normalizeIssue(issue string) string {
+	return strings.ToLower(models.NormalizeIssueNumber(issue))
+}
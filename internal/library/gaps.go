@@ -0,0 +1,43 @@
+package library
+
+import (
+	"sort"
+	"strconv"
+
+	"comic-parser/internal/models"
+)
+
+// MissingIssues returns the issues of a volume whose numbers are not among
+// have, in issue number order. Numbers compare without their zero padding,
+// so having "001" covers issue "1".
+func MissingIssues(volume []models.ComicVineIssue, have []string) []models.ComicVineIssue {
+	present := make(map[string]bool, len(have))
+	for _, n := range have {
+		present[normalizeIssue(n)] = true
+	}
+
+	var missing []models.ComicVineIssue
+	for _, issue := range volume {
+		if !present[normalizeIssue(issue.IssueNumber)] {
+			missing = append(missing, issue)
+		}
+	}
+	sort.SliceStable(missing, func(i, j int) bool {
+		return issueLess(missing[i].IssueNumber, missing[j].IssueNumber)
+	})
+	return missing
+}
+
+// issueLess orders issue numbers numerically, with non-numeric ones like
+// "1.MU" after the numbers in string order.
+func issueLess(a, b string) bool {
+	na, errA := strconv.ParseFloat(normalizeIssue(a), 64)
+	nb, errB := strconv.ParseFloat(normalizeIssue(b), 64)
+	switch {
+	case errA == nil && errB == nil:
+		return na < nb
+	case errA == nil || errB == nil:
+		return errA == nil
+	}
+	return a < b
+}
@@ -0,0 +1,51 @@
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+
+	"comic-parser/internal/models"
+)
+
+// Outcomes of VerifyFile
+const (
+	FileUnchanged = "unchanged"
+	FileChanged   = "changed"
+	FileMissing   = "missing"
+)
+
+// HashFile returns the hex SHA-256 of the file at path and its size.
+func HashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// VerifyFile hashes the file of a stored result again and reports whether
+// it is unchanged, changed or missing since it was processed.
+func VerifyFile(r *models.ProcessingResult) (string, error) {
+	hash, size, err := HashFile(r.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return FileMissing, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if hash != r.FileHash || size != r.FileSize {
+		return FileChanged, nil
+	}
+	return FileUnchanged, nil
+}
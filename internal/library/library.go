@@ -0,0 +1,234 @@
+// Package library inspects comic collections on disk and compares them with
+// the records stored in the database.
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// archiveExtensions lists the comic archive formats recognized on disk.
+var archiveExtensions = map[string]bool{
+	".cbz": true,
+	".cbr": true,
+	".cb7": true,
+	".cbt": true,
+}
+
+// IsComicArchive reports whether path has a comic archive extension.
+func IsComicArchive(path string) bool {
+	return archiveExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// Walk returns the absolute paths of all comic archives under root, sorted.
+func Walk(root string) ([]string, error) {
+	return Scan(root, ScanOptions{Recursive: true})
+}
+
+// ScanOptions controls which archives Scan discovers.
+type ScanOptions struct {
+	// Recursive descends into subdirectories of the root.
+	Recursive bool
+	// Include, when non-empty, keeps only files matching one of these glob
+	// patterns.
+	Include []string
+	// Exclude skips files, and directories, matching any of these glob
+	// patterns. Exclusions win over inclusions.
+	Exclude []string
+	// Match, when set, selects the files to discover instead of
+	// IsComicArchive, e.g. to scan for other kinds of media.
+	Match func(path string) bool
+}
+
+// Scan returns the absolute paths of the comic archives under root that
+// pass opts, sorted. Patterns use filepath.Match syntax and are matched
+// against both the base name and the slash-separated path relative to root.
+func Scan(root string, opts ScanOptions) ([]string, error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	match := opts.Match
+	if match == nil {
+		match = IsComicArchive
+	}
+
+	var files []string
+	err = filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == absRoot {
+			return nil
+		}
+
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if !opts.Recursive || matchAny(opts.Exclude, d.Name(), rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !match(path) || matchAny(opts.Exclude, d.Name(), rel) {
+			return nil
+		}
+		if len(opts.Include) > 0 && !matchAny(opts.Include, d.Name(), rel) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+func matchAny(patterns []string, name, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalPath returns the absolute path of name if it is a file on disk, or
+// "" otherwise (for example when processing a bare list of filenames).
+func LocalPath(name string) string {
+	info, err := os.Stat(name)
+	if err != nil || info.IsDir() {
+		return ""
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return ""
+	}
+	return abs
+}
+
+// Move describes a stored record whose file now lives at a different path.
+type Move struct {
+	Stored  string `json:"stored"`
+	Current string `json:"current"`
+}
+
+// Report is the result of reconciling a library root against stored records.
+type Report struct {
+	// Unprocessed lists files on disk that have no stored record.
+	Unprocessed []string `json:"unprocessed"`
+	// Missing lists stored names with no matching file under the root.
+	Missing []string `json:"missing"`
+	// Moved lists stored paths that no longer exist but whose filename
+	// appears exactly once elsewhere under the root.
+	Moved []Move `json:"moved"`
+}
+
+// Reconcile compares the archives found under root (as returned by Walk)
+// with the stored names. Stored names may be bare filenames or paths; paths
+// outside root are ignored since they belong to another library.
+func Reconcile(root string, files []string, stored []string) (*Report, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	byBase := make(map[string][]string)
+	for _, f := range files {
+		base := filepath.Base(f)
+		byBase[base] = append(byBase[base], f)
+	}
+
+	seen := make(map[string]bool, len(files))
+	report := &Report{}
+
+	for _, name := range stored {
+		base := filepath.Base(name)
+
+		// Bare filename: any file with that name counts
+		if base == name {
+			candidates := byBase[base]
+			if len(candidates) == 0 {
+				report.Missing = append(report.Missing, name)
+			}
+			for _, c := range candidates {
+				seen[c] = true
+			}
+			continue
+		}
+
+		path, err := filepath.Abs(name)
+		if err != nil {
+			return nil, err
+		}
+		if !isWithin(absRoot, path) {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			seen[path] = true
+			continue
+		}
+
+		candidates := byBase[base]
+		if len(candidates) == 1 {
+			report.Moved = append(report.Moved, Move{Stored: name, Current: candidates[0]})
+			seen[candidates[0]] = true
+			continue
+		}
+		report.Missing = append(report.Missing, name)
+	}
+
+	for _, f := range files {
+		if !seen[f] {
+			report.Unprocessed = append(report.Unprocessed, f)
+		}
+	}
+
+	return report, nil
+}
+
+// MissingPaths returns the stored names that are paths to files that no
+// longer exist. Bare filenames are skipped since they can't be checked
+// without a library root.
+func MissingPaths(stored []string) []string {
+	var missing []string
+	for _, name := range stored {
+		if filepath.Base(name) == name {
+			continue
+		}
+		if _, err := os.Stat(name); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
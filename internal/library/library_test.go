@@ -0,0 +1,507 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "a.cbz"))
+	touch(t, filepath.Join(root, "sub", "b.CBR"))
+	touch(t, filepath.Join(root, "notes.txt"))
+
+	files, err := Walk(root)
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 archives, got %v", files)
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "a.cbz"))
+	touch(t, filepath.Join(root, "a (Preview).cbz"))
+	touch(t, filepath.Join(root, "sub", "b.cbr"))
+	touch(t, filepath.Join(root, "skip", "c.cbz"))
+
+	tests := []struct {
+		name string
+		opts ScanOptions
+		want []string
+	}{
+		{"non-recursive", ScanOptions{}, []string{"a (Preview).cbz", "a.cbz"}},
+		{"recursive", ScanOptions{Recursive: true}, []string{"a (Preview).cbz", "a.cbz", "skip/c.cbz", "sub/b.cbr"}},
+		{"include", ScanOptions{Recursive: true, Include: []string{"*.cbr"}}, []string{"sub/b.cbr"}},
+		{"exclude file", ScanOptions{Recursive: true, Exclude: []string{"*Preview*"}}, []string{"a.cbz", "skip/c.cbz", "sub/b.cbr"}},
+		{"exclude dir", ScanOptions{Recursive: true, Exclude: []string{"skip"}}, []string{"a (Preview).cbz", "a.cbz", "sub/b.cbr"}},
+		{"relative pattern", ScanOptions{Recursive: true, Include: []string{"sub/*"}}, []string{"sub/b.cbr"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := Scan(root, tt.opts)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			var got []string
+			for _, f := range files {
+				rel, _ := filepath.Rel(root, f)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Scan = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Scan(root, ScanOptions{Include: []string{"["}}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	root := t.TempDir()
+	present := filepath.Join(root, "present.cbz")
+	moved := filepath.Join(root, "new", "moved.cbz")
+	fresh := filepath.Join(root, "fresh.cbz")
+	bare := filepath.Join(root, "bare.cbz")
+	for _, f := range []string{present, moved, fresh, bare} {
+		touch(t, f)
+	}
+
+	files, err := Walk(root)
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	stored := []string{
+		present,
+		filepath.Join(root, "old", "moved.cbz"),
+		filepath.Join(root, "gone.cbz"),
+		"bare.cbz",
+		"vanished.cbz",
+		"/elsewhere/other-library.cbz",
+	}
+
+	report, err := Reconcile(root, files, stored)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if len(report.Unprocessed) != 1 || report.Unprocessed[0] != fresh {
+		t.Errorf("Expected only %s unprocessed, got %v", fresh, report.Unprocessed)
+	}
+	if len(report.Moved) != 1 || report.Moved[0].Current != moved {
+		t.Errorf("Expected moved.cbz to be detected as moved, got %+v", report.Moved)
+	}
+	if len(report.Missing) != 2 {
+		t.Errorf("Expected 2 missing (gone.cbz, vanished.cbz), got %v", report.Missing)
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	issue := &models.ComicVineIssue{
+		ID:          42,
+		IssueNumber: "1",
+		Volume:      models.VolumeRef{Name: "Batman: Year One", Publisher: "DC Comics", StartYear: "1987"},
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"{Publisher}/{Series} ({StartYear})/{Series} #{Issue:000}.cbz", "DC Comics/Batman - Year One (1987)/Batman - Year One #001.cbz"},
+		{"{Series} #{Issue:00}{Ext}", "Batman - Year One #01.cbr"},
+		{"{Series} ({Year}) [{ID}]{Ext}", "Batman - Year One [42].cbr"},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.pattern)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q): %v", tt.pattern, err)
+		}
+		if got := tmpl.Render(issue, "/in/file.CBR"); got != filepath.FromSlash(tt.want) {
+			t.Errorf("Render(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+
+	if _, err := ParseTemplate("{Series}/{Nope}"); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if got := padNumber("12.5AU", 3); got != "012.5AU" {
+		t.Errorf("padNumber = %q, want 012.5AU", got)
+	}
+}
+
+func TestPlanMoves(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"in/saga1.cbz", "in/saga1-dupe.cbz", "in/saga2.cbz", "in/saga3.cbz", "Saga #003.cbz"} {
+		touch(t, filepath.Join(root, name))
+	}
+	issue := func(number string) *models.ComicVineIssue {
+		return &models.ComicVineIssue{IssueNumber: number, Volume: models.VolumeRef{Name: "Saga"}}
+	}
+	items := []OrganizeItem{
+		{Filename: "saga1.cbz", Path: filepath.Join(root, "in/saga1.cbz"), Issue: issue("1")},
+		{Filename: "saga1-dupe.cbz", Path: filepath.Join(root, "in/saga1-dupe.cbz"), Issue: issue("1")},
+		{Filename: "saga2.cbz", Path: filepath.Join(root, "in/saga2.cbz"), Issue: issue("2")},
+		{Filename: "saga3.cbz", Path: filepath.Join(root, "in/saga3.cbz"), Issue: issue("3")},
+	}
+
+	tmpl, _ := ParseTemplate("{Series} #{Issue:000}{Ext}")
+	moves, skipped, err := PlanMoves(root, tmpl, items)
+	if err != nil {
+		t.Fatalf("PlanMoves failed: %v", err)
+	}
+	if len(moves) != 1 || moves[0].To != filepath.Join(root, "Saga #002.cbz") {
+		t.Fatalf("Expected only saga2 to move, got %+v", moves)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("Expected the colliding pair and the existing target skipped, got %+v", skipped)
+	}
+
+	if err := MoveFile(moves[0].From, moves[0].To); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if err := MoveFile(filepath.Join(root, "in/saga3.cbz"), filepath.Join(root, "Saga #003.cbz")); err == nil {
+		t.Error("Expected MoveFile to refuse replacing an existing file")
+	}
+}
+
+func TestMoveFile_AcrossFilesystems(t *testing.T) {
+	rename = func(from, to string) error {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EXDEV}
+	}
+	defer func() { rename = os.Rename }()
+
+	root := t.TempDir()
+	from := filepath.Join(root, "in/saga1.cbz")
+	to := filepath.Join(root, "out/Saga #001.cbz")
+	touch(t, from)
+	if err := os.WriteFile(from, []byte("pages"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(from, 0640); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(from, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MoveFile(from, to); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if exists(from) {
+		t.Error("Expected the source to be removed")
+	}
+	data, err := os.ReadFile(to)
+	if err != nil || string(data) != "pages" {
+		t.Fatalf("Expected the copied contents, got %q, %v", data, err)
+	}
+	info, err := os.Stat(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 || !info.ModTime().Equal(modified) {
+		t.Errorf("Expected mode and time kept, got %v %v", info.Mode().Perm(), info.ModTime())
+	}
+
+	// Other rename failures are returned as they are
+	rename = func(from, to string) error {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EACCES}
+	}
+	touch(t, from)
+	if err := MoveFile(from, filepath.Join(root, "out/Saga #002.cbz")); !errors.Is(err, syscall.EACCES) {
+		t.Errorf("Expected the rename error, got %v", err)
+	}
+}
+
+func TestConfirmMove_RollsBackAcrossFilesystems(t *testing.T) {
+	rename = func(from, to string) error {
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: syscall.EXDEV}
+	}
+	defer func() { rename = os.Rename }()
+
+	root := t.TempDir()
+	from := filepath.Join(root, "in/saga1.cbz")
+	to := filepath.Join(root, "out/Saga #001.cbz")
+	touch(t, from)
+	if err := os.WriteFile(from, []byte("pages"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveFile(from, to); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+
+	// A failed record moves the file back, copying across filesystems
+	recordErr := errors.New("database is locked")
+	if err := ConfirmMove(from, to, func() error { return recordErr }); !errors.Is(err, recordErr) {
+		t.Fatalf("Expected the record error, got %v", err)
+	}
+	if exists(to) {
+		t.Error("Expected the moved file to be moved back")
+	}
+	if data, err := os.ReadFile(from); err != nil || string(data) != "pages" {
+		t.Errorf("Expected the file back in place, got %q, %v", data, err)
+	}
+
+	// A recorded move stays
+	if err := MoveFile(from, to); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if err := ConfirmMove(from, to, func() error { return nil }); err != nil || !exists(to) {
+		t.Errorf("Expected the move kept, got %v", err)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	touch(t, filepath.Join(root, "old.cbz"))
+
+	w := NewWatcher(root, ScanOptions{}, 3*time.Second)
+	if err := w.SkipExisting(); err != nil {
+		t.Fatalf("SkipExisting failed: %v", err)
+	}
+
+	poll := func(now time.Time) []string {
+		t.Helper()
+		ready, err := w.Poll(now)
+		if err != nil {
+			t.Fatalf("Poll failed: %v", err)
+		}
+		var names []string
+		for _, path := range ready {
+			names = append(names, filepath.Base(path))
+		}
+		return names
+	}
+
+	start := time.Now()
+	path := filepath.Join(root, "new.cbz")
+	touch(t, path)
+	if got := poll(start); len(got) != 0 {
+		t.Fatalf("Expected a new file to wait, got %v", got)
+	}
+
+	// Still being written: the settle time starts over
+	if err := os.WriteFile(path, []byte("more"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := poll(start.Add(2 * time.Second)); len(got) != 0 {
+		t.Fatalf("Expected a growing file to wait, got %v", got)
+	}
+	if got := poll(start.Add(4 * time.Second)); len(got) != 0 {
+		t.Fatalf("Expected the file to wait for the settle time, got %v", got)
+	}
+	if got := poll(start.Add(5 * time.Second)); strings.Join(got, "|") != "new.cbz" {
+		t.Fatalf("Expected new.cbz ready, got %v", got)
+	}
+	if got := poll(start.Add(10 * time.Second)); len(got) != 0 {
+		t.Fatalf("Expected new.cbz reported once, got %v", got)
+	}
+
+	// Ignored files are never reported; removed ones are forgotten
+	moved := filepath.Join(root, "moved.cbz")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+	w.Ignore(moved)
+	if got := poll(start.Add(20 * time.Second)); len(got) != 0 {
+		t.Fatalf("Expected the ignored file skipped, got %v", got)
+	}
+	if err := os.Rename(moved, path); err != nil {
+		t.Fatal(err)
+	}
+	poll(start.Add(30 * time.Second))
+	if got := poll(start.Add(40 * time.Second)); strings.Join(got, "|") != "new.cbz" {
+		t.Fatalf("Expected the returning file reported again, got %v", got)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	big := filepath.Join(dir, "Saga 001 (2012) (c2c).cbz")
+	small := filepath.Join(dir, "Saga 001 (2012).cbz")
+	if err := os.WriteFile(big, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(small, make([]byte, 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	saga := &models.ComicVineIssue{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{Name: "Saga"}}
+	matched := func(filename, path string, issue *models.ComicVineIssue) *models.ProcessingResult {
+		return &models.ProcessingResult{Filename: filename, Path: path, Match: &models.MatchResult{SelectedIssue: issue}}
+	}
+	results := []*models.ProcessingResult{
+		matched("Saga 001 (2012).cbz", small, saga),
+		matched("Saga 001 (2012) (c2c).cbz", big, saga),
+		matched("Saga 1 (2012) (Digital) (Zone-Empire).cbr", "", saga),
+		matched("Saga 002 (2012).cbz", "", &models.ComicVineIssue{ID: 2, IssueNumber: "2", Volume: models.VolumeRef{Name: "Saga"}}),
+		// The later issues of a multi-issue file don't count
+		{Filename: "Saga 001-002.cbz #2", SourceFilename: "Saga 001-002.cbz", Match: &models.MatchResult{SelectedIssue: saga}},
+	}
+	parsed := []*models.ParsedFilename{
+		{OriginalFilename: "Saga 001 (2012).cbz", Title: "Saga", IssueNumber: "1"},
+		{OriginalFilename: "Bone 01.cbz", Title: "Bone", IssueNumber: "01"},
+		{OriginalFilename: "bone 1 (c2c).cbz", Title: "bone", IssueNumber: "1"},
+		{OriginalFilename: "bone 1 (c2c).cbz", Title: "Bone", IssueNumber: "1"},
+		{OriginalFilename: "Bone 2.cbz", Title: "Bone", IssueNumber: "2"},
+	}
+
+	groups := FindDuplicates(results, parsed)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", groups)
+	}
+
+	bone := groups[0]
+	if bone.Key != "parsed:bone #1" || bone.ComicVineID != 0 || len(bone.Files) != 2 {
+		t.Errorf("Unexpected unmatched group %+v", bone)
+	}
+	if !bone.Files[0].Keep || bone.Files[0].Filename != "bone 1 (c2c).cbz" || bone.Files[1].Keep {
+		t.Errorf("Expected the c2c copy kept, got %+v", bone.Files)
+	}
+
+	sagaGroup := groups[1]
+	var names []string
+	for _, f := range sagaGroup.Files {
+		names = append(names, f.Filename)
+	}
+	want := []string{"Saga 1 (2012) (Digital) (Zone-Empire).cbr", "Saga 001 (2012) (c2c).cbz", "Saga 001 (2012).cbz"}
+	if sagaGroup.ComicVineID != 1 || strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected matched group %+v", sagaGroup)
+	}
+	if !sagaGroup.Files[0].Keep || sagaGroup.Files[1].Keep || sagaGroup.Files[1].Size != 2048 || sagaGroup.Files[2].Size != 1024 {
+		t.Errorf("Unexpected files %+v", sagaGroup.Files)
+	}
+}
+
+func TestFindIdenticalFiles(t *testing.T) {
+	hashed := []*models.ProcessingResult{
+		{Filename: "Saga 001.cbz", FileHash: "aa", FileSize: 10},
+		{Filename: "Bone 001.cbz", FileHash: "bb", FileSize: 20},
+		{Filename: "saga-1 (Digital).cbz", FileHash: "aa", FileSize: 10},
+		{Filename: "Unhashed.cbz"},
+	}
+	groups := FindIdenticalFiles(hashed)
+	if len(groups) != 1 || groups[0].Key != "sha256:aa" || len(groups[0].Files) != 2 {
+		t.Fatalf("Expected one group of the two Saga copies, got %+v", groups)
+	}
+	if f := groups[0].Files[0]; f.Filename != "saga-1 (Digital).cbz" || !f.Keep || f.Size != 10 {
+		t.Errorf("Expected the digital copy kept, got %+v", groups[0].Files)
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Saga 001.cbz")
+	if err := os.WriteFile(path, []byte("pages"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hash, size, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("HashFile failed: %v", err)
+	}
+	if hash != "bfa062de040f55a15ce910800757061ec3d2fc31d6b7c72d9fa02b75a9ad1133" || size != 5 {
+		t.Errorf("HashFile = %s, %d", hash, size)
+	}
+
+	stored := &models.ProcessingResult{Filename: "Saga 001.cbz", Path: path, FileHash: hash, FileSize: size}
+	check := func(want string) {
+		t.Helper()
+		if got, err := VerifyFile(stored); err != nil || got != want {
+			t.Errorf("VerifyFile = %q, %v; want %q", got, err, want)
+		}
+	}
+	check(FileUnchanged)
+	if err := os.WriteFile(path, []byte("pagez"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check(FileChanged)
+	os.Remove(path)
+	check(FileMissing)
+}
+
+func TestFindAppearances(t *testing.T) {
+	issue := func(id int, series, number string, credits []models.Credit, characters ...string) *models.ProcessingResult {
+		i := &models.ComicVineIssue{ID: id, IssueNumber: number, Volume: models.VolumeRef{Name: series}, Credits: credits}
+		for _, c := range characters {
+			i.Characters = append(i.Characters, models.ResourceRef{Name: c})
+		}
+		return &models.ProcessingResult{Filename: fmt.Sprintf("%s %s.cbz", series, number), Match: &models.MatchResult{SelectedIssue: i}}
+	}
+	bkv := models.Credit{ID: 1, Name: "Brian K. Vaughan", Role: "writer"}
+	matched := []*models.ProcessingResult{
+		issue(12, "Y: The Last Man", "10", []models.Credit{bkv}, "Yorick Brown"),
+		issue(11, "Y: The Last Man", "2", []models.Credit{bkv}, "Yorick Brown"),
+		issue(20, "Saga", "1", []models.Credit{bkv, {ID: 2, Name: "Fiona Staples", Role: "artist, cover"}}, "Alana", "Marko"),
+		issue(30, "Amazing Spider-Man", "1", nil, "Spider-Man", "Mary Jane Watson"),
+		{Filename: "Unmatched 1.cbz"},
+	}
+
+	creators := FindCreatorAppearances(matched, "  brian k.  VAUGHAN ")
+	var got []string
+	for _, a := range creators {
+		got = append(got, a.Series+" #"+a.IssueNumber)
+	}
+	want := []string{"Saga #1", "Y: The Last Man #2", "Y: The Last Man #10"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if creators[0].Role != "writer" || creators[0].Name != "Brian K. Vaughan" || creators[0].ComicVineID != 20 {
+		t.Errorf("Unexpected appearance %+v", creators[0])
+	}
+
+	if staples := FindCreatorAppearances(matched, "staples"); len(staples) != 1 || staples[0].Role != "artist, cover" {
+		t.Errorf("Expected Fiona Staples on Saga #1, got %+v", staples)
+	}
+	if spidey := FindCharacterAppearances(matched, "Spider-Man"); len(spidey) != 1 || spidey[0].Filename != "Amazing Spider-Man 1.cbz" || spidey[0].Role != "" {
+		t.Errorf("Expected Spider-Man in one file, got %+v", spidey)
+	}
+	if none := FindCharacterAppearances(matched, " "); none != nil {
+		t.Errorf("Expected a blank name to match nothing, got %+v", none)
+	}
+}
+
+func TestMissingIssues(t *testing.T) {
+	volume := []models.ComicVineIssue{
+		{ID: 10, IssueNumber: "10"},
+		{ID: 1, IssueNumber: "1"},
+		{ID: 2, IssueNumber: "2"},
+		{ID: 3, IssueNumber: "1.MU"},
+		{ID: 4, IssueNumber: "3"},
+		{ID: 5, IssueNumber: "0"},
+	}
+
+	missing := MissingIssues(volume, []string{"001", "3", "#0"})
+	var got []string
+	for _, issue := range missing {
+		got = append(got, issue.IssueNumber)
+	}
+	if want := []string{"2", "10", "1.MU"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("MissingIssues = %v, want %v", got, want)
+	}
+	if missing := MissingIssues(volume[:2], []string{"1", "10"}); len(missing) != 0 {
+		t.Errorf("Expected a complete run, got %+v", missing)
+	}
+}
@@ -0,0 +1,287 @@
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// templateFields are the placeholders a Template may use
+var templateFields = map[string]bool{
+	"Publisher": true,
+	"Series":    true,
+	"StartYear": true,
+	"Issue":     true,
+	"Year":      true,
+	"Title":     true,
+	"ID":        true,
+	"Ext":       true,
+}
+
+var (
+	placeholder = regexp.MustCompile(`\{(\w+)(?::(0+))?\}`)
+	// emptyGroups matches brackets left empty by a missing field, e.g. "Saga ()"
+	emptyGroups = regexp.MustCompile(`\s*(\(\s*\)|\[\s*\])`)
+	multiSpace  = regexp.MustCompile(`\s{2,}`)
+	// leadingNumber splits an issue number like "12.5AU" into "12" and ".5AU"
+	leadingNumber = regexp.MustCompile(`^(\d+)(.*)$`)
+)
+
+// Template renders library-relative paths from matched issues. Placeholders
+// are {Publisher}, {Series}, {StartYear}, {Issue}, {Year} (cover year),
+// {Title} (issue name), {ID} (ComicVine ID) and {Ext} (the file's own
+// extension). A zero suffix pads numbers: {Issue:000} renders #1 as 001.
+type Template struct {
+	pattern string
+}
+
+// ParseTemplate validates a path template.
+func ParseTemplate(pattern string) (*Template, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, errors.New("empty template")
+	}
+	if filepath.IsAbs(pattern) {
+		return nil, fmt.Errorf("template %q must be relative to the library root", pattern)
+	}
+	for _, m := range placeholder.FindAllStringSubmatch(pattern, -1) {
+		if !templateFields[m[1]] {
+			return nil, fmt.Errorf("unknown template field {%s}", m[1])
+		}
+	}
+	return &Template{pattern: pattern}, nil
+}
+
+// Render returns the relative path for issue, stored as file src. Field
+// values are cleaned so they can't add directories or use characters that
+// are invalid in filenames.
+func (t *Template) Render(issue *models.ComicVineIssue, src string) string {
+	fields := map[string]string{
+		"Publisher": issue.Volume.Publisher,
+		"Series":    issue.Volume.Name,
+		"StartYear": issue.Volume.StartYear,
+		"Issue":     issue.IssueNumber,
+		"Title":     issue.Name,
+		"Ext":       strings.ToLower(filepath.Ext(src)),
+	}
+	if issue.CoverDate.Year > 0 {
+		fields["Year"] = strconv.Itoa(issue.CoverDate.Year)
+	}
+	if issue.ID > 0 {
+		fields["ID"] = strconv.Itoa(issue.ID)
+	}
+
+	rendered := placeholder.ReplaceAllStringFunc(t.pattern, func(s string) string {
+		m := placeholder.FindStringSubmatch(s)
+		value := fields[m[1]]
+		if m[1] != "Ext" {
+			value = cleanComponent(value)
+		}
+		if m[2] != "" {
+			value = padNumber(value, len(m[2]))
+		}
+		return value
+	})
+
+	parts := strings.Split(filepath.ToSlash(rendered), "/")
+	kept := parts[:0]
+	for _, part := range parts {
+		part = emptyGroups.ReplaceAllString(part, "")
+		part = strings.TrimSpace(multiSpace.ReplaceAllString(part, " "))
+		if part != "" && part != "." && part != ".." {
+			kept = append(kept, part)
+		}
+	}
+	return filepath.Join(kept...)
+}
+
+// cleanComponent makes a field value safe to use inside a path component.
+func cleanComponent(s string) string {
+	s = strings.NewReplacer("/", "-", "\\", "-", ":", " -").Replace(s)
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`*?"<>|`, r) || r < ' ' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimRight(strings.TrimSpace(s), ".")
+}
+
+// padNumber zero-pads the leading integer of s to width digits.
+func padNumber(s string, width int) string {
+	m := leadingNumber.FindStringSubmatch(s)
+	if m == nil {
+		return s
+	}
+	digits := m[1]
+	for len(digits) < width {
+		digits = "0" + digits
+	}
+	return digits + m[2]
+}
+
+// OrganizeItem is a matched file to place in the library.
+type OrganizeItem struct {
+	// Filename is the name the file's records are stored under.
+	Filename string
+	// Path is the file's current location.
+	Path  string
+	Issue *models.ComicVineIssue
+}
+
+// PlannedMove is a rename organize would make. Reason is set when the move
+// was skipped.
+type PlannedMove struct {
+	Filename string
+	From     string
+	To       string
+	Reason   string
+}
+
+// PlanMoves renders the target of every item under root and reports which
+// moves can be made. Files already in place, targets that exist on disk and
+// targets claimed by more than one file are returned as skipped with a
+// reason; colliding files are all skipped so nothing is overwritten.
+func PlanMoves(root string, tmpl *Template, items []OrganizeItem) (moves, skipped []PlannedMove, err error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	planned := make([]PlannedMove, 0, len(items))
+	claims := make(map[string][]int)
+	for _, item := range items {
+		from, err := filepath.Abs(item.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		to := filepath.Join(absRoot, tmpl.Render(item.Issue, from))
+		planned = append(planned, PlannedMove{Filename: item.Filename, From: from, To: to})
+		key := strings.ToLower(to) // case-insensitive filesystems
+		claims[key] = append(claims[key], len(planned)-1)
+	}
+
+	for i := range planned {
+		m := &planned[i]
+		switch others := claims[strings.ToLower(m.To)]; {
+		case m.From == m.To:
+			m.Reason = "already organized"
+		case len(others) > 1:
+			m.Reason = fmt.Sprintf("collides with %d other file(s) for %s", len(others)-1, m.To)
+		case exists(m.To) && !sameFile(m.From, m.To):
+			m.Reason = "target exists: " + m.To
+		}
+		if m.Reason != "" {
+			skipped = append(skipped, *m)
+		} else {
+			moves = append(moves, *m)
+		}
+	}
+
+	sort.Slice(moves, func(i, j int) bool { return moves[i].To < moves[j].To })
+	return moves, skipped, nil
+}
+
+// rename is os.Rename, replaced in tests to move across filesystems.
+var rename = os.Rename
+
+// MoveFile renames from to to, creating the target's directories. It
+// refuses to replace an existing file. A target on another filesystem,
+// which can't be renamed to, gets a synced copy before from is removed.
+func MoveFile(from, to string) error {
+	if exists(to) && !sameFile(from, to) {
+		return fmt.Errorf("target exists: %s", to)
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		return err
+	}
+	err := rename(from, to)
+	var linkErr *os.LinkError
+	if errors.As(err, &linkErr) && errors.Is(linkErr.Err, syscall.EXDEV) {
+		return copyFile(from, to)
+	}
+	return err
+}
+
+// ConfirmMove calls record for a file MoveFile moved from from to to, and
+// moves the file back when record fails so the disk keeps matching what was
+// recorded. The move back goes through MoveFile too, so a move across
+// filesystems is undone by copying.
+func ConfirmMove(from, to string, record func() error) error {
+	err := record()
+	if err == nil {
+		return nil
+	}
+	if rbErr := MoveFile(to, from); rbErr != nil {
+		return fmt.Errorf("%w (and moving %s back failed: %v)", err, to, rbErr)
+	}
+	return err
+}
+
+// copyFile moves from to to by copying it, keeping its mode and
+// modification time, and removing from once the copy is on disk. A failed
+// copy is removed and from is left in place.
+func copyFile(from, to string) (err error) {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(to)
+		}
+	}()
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("copying %s: %w", from, err)
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return fmt.Errorf("syncing %s: %w", to, err)
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(to, time.Time{}, info.ModTime()); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// sameFile reports whether a and b are the same file, as with a case-only
+// rename on a case-insensitive filesystem.
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(ai, bi)
+}
@@ -0,0 +1,136 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Watcher reports comic archives as they appear under a directory. It polls
+// rather than relying on filesystem notifications, which also works on
+// network shares, and holds each new archive back until its size and
+// modification time stop changing, so files still being copied or
+// downloaded are not picked up half-written.
+type Watcher struct {
+	root    string
+	opts    ScanOptions
+	settle  time.Duration
+	seen    map[string]bool
+	pending map[string]pendingFile
+}
+
+// pendingFile is a new archive waiting to settle.
+type pendingFile struct {
+	size    int64
+	modTime time.Time
+	since   time.Time // when the size or modification time last changed
+}
+
+// NewWatcher creates a Watcher for the archives under root that pass opts.
+// An archive is ready once it has been unchanged for settle.
+func NewWatcher(root string, opts ScanOptions, settle time.Duration) *Watcher {
+	return &Watcher{
+		root:    root,
+		opts:    opts,
+		settle:  settle,
+		seen:    make(map[string]bool),
+		pending: make(map[string]pendingFile),
+	}
+}
+
+// SkipExisting marks the archives already under root as seen, so only the
+// ones that appear afterwards are reported.
+func (w *Watcher) SkipExisting() error {
+	files, err := Scan(w.root, w.opts)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		w.seen[path] = true
+	}
+	return nil
+}
+
+// Ignore marks path as seen, for example when a processed archive is moved
+// to another place under root.
+func (w *Watcher) Ignore(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		w.seen[abs] = true
+	}
+}
+
+// Poll scans root once and returns the new archives that have been
+// unchanged for the settle duration as of now. An archive has to be seen by
+// two polls to be ready, and each one is returned once; archives that
+// disappear are forgotten, so one that comes back is reported again.
+func (w *Watcher) Poll(now time.Time) ([]string, error) {
+	files, err := Scan(w.root, w.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	present := make(map[string]bool, len(files))
+	var ready []string
+	for _, path := range files {
+		present[path] = true
+		if w.seen[path] {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			// Removed since the scan
+			continue
+		}
+		p, ok := w.pending[path]
+		if !ok || p.size != info.Size() || !p.modTime.Equal(info.ModTime()) {
+			w.pending[path] = pendingFile{size: info.Size(), modTime: info.ModTime(), since: now}
+			continue
+		}
+		if now.Sub(p.since) >= w.settle {
+			delete(w.pending, path)
+			w.seen[path] = true
+			ready = append(ready, path)
+		}
+	}
+
+	for path := range w.seen {
+		if !present[path] {
+			delete(w.seen, path)
+		}
+	}
+	for path := range w.pending {
+		if !present[path] {
+			delete(w.pending, path)
+		}
+	}
+	return ready, nil
+}
+
+// Watch polls root every interval until ctx is done, passing each ready
+// archive to handle in turn. It stops early if a scan or handle fails.
+func (w *Watcher) Watch(ctx context.Context, interval time.Duration, handle func(path string) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ready, err := w.Poll(time.Now())
+		if err != nil {
+			return err
+		}
+		for _, path := range ready {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := handle(path); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned when the run's configured LLM spend budget
+// has been used up. Callers should stop issuing LLM requests for the run.
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+const tokensPerMillion = 1_000_000
+
+// modelPrice holds USD prices per million input and output tokens.
+type modelPrice struct {
+	input  float64
+	output float64
+}
+
+// modelPricing maps model name prefixes to their published per-token prices.
+// Unknown models fall back to defaultModelPrice.
+var modelPricing = []struct {
+	prefix string
+	price  modelPrice
+}{
+	{"claude-3-5-haiku", modelPrice{input: 0.80, output: 4}},
+	{"claude-3-haiku", modelPrice{input: 0.25, output: 1.25}},
+	{"claude-3-opus", modelPrice{input: 15, output: 75}},
+	{"claude-opus-4", modelPrice{input: 15, output: 75}},
+	{"claude-3-5-sonnet", modelPrice{input: 3, output: 15}},
+	{"claude-3-7-sonnet", modelPrice{input: 3, output: 15}},
+	{"claude-sonnet-4", modelPrice{input: 3, output: 15}},
+}
+
+// defaultModelPrice is used for models missing from modelPricing (Sonnet pricing).
+var defaultModelPrice = modelPrice{input: 3, output: 15}
+
+func priceForModel(model string) modelPrice {
+	for _, p := range modelPricing {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price
+		}
+	}
+	return defaultModelPrice
+}
+
+// Budget tracks cumulative token usage for a run and enforces optional
+// token and cost limits. A zero limit means unlimited.
+// The check happens before each request, so the final request of a run
+// may overshoot the limit slightly.
+type Budget struct {
+	maxTokens int
+	maxCost   float64
+	price     modelPrice
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewBudget creates a budget for the given model.
+func NewBudget(model string, maxTokens int, maxCost float64) *Budget {
+	return &Budget{
+		maxTokens: maxTokens,
+		maxCost:   maxCost,
+		price:     priceForModel(model),
+	}
+}
+
+// Record adds the usage of a completed request to the running totals.
+func (b *Budget) Record(u Usage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage.InputTokens += u.InputTokens
+	b.usage.OutputTokens += u.OutputTokens
+}
+
+// Usage returns the cumulative token usage.
+func (b *Budget) Usage() Usage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usage
+}
+
+// Cost returns the estimated cumulative cost in USD.
+func (b *Budget) Cost() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.costLocked()
+}
+
+func (b *Budget) costLocked() float64 {
+	return b.price.cost(b.usage)
+}
+
+// CostOf returns the estimated cost in USD of usage at the budget's model price.
+func (b *Budget) CostOf(u Usage) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.price.cost(u)
+}
+
+// setLimits changes the model price and the limits of the budget.
+func (b *Budget) setLimits(price modelPrice, maxTokens int, maxCost float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.price = price
+	b.maxTokens = maxTokens
+	b.maxCost = maxCost
+}
+
+func (p modelPrice) cost(u Usage) float64 {
+	return float64(u.InputTokens)*p.input/tokensPerMillion +
+		float64(u.OutputTokens)*p.output/tokensPerMillion
+}
+
+// Exceeded reports whether either configured limit has been reached.
+func (b *Budget) Exceeded() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.maxTokens > 0 && b.usage.InputTokens+b.usage.OutputTokens >= b.maxTokens {
+		return true
+	}
+	if b.maxCost > 0 && b.costLocked() >= b.maxCost {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// cacheSubdir keeps LLM responses apart from other users of the cache dir
+const cacheSubdir = "llm"
+
+// CacheStats counts response cache lookups and what the hits saved.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Saved  Usage   // tokens the cached responses took when they were made
+	Cost   float64 // estimated cost of Saved, in USD
+}
+
+// cacheEntry is a cached structured response with the tokens it took.
+type cacheEntry struct {
+	Response string `json:"response"`
+	Usage    Usage  `json:"usage"`
+}
+
+// diskCache stores structured responses on disk, one file per request.
+// Entries older than ttl are treated as missing.
+type diskCache struct {
+	dir    string
+	ttl    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	mu    sync.Mutex
+	saved Usage
+}
+
+func newDiskCache(dir string, ttl time.Duration) *diskCache {
+	return &diskCache{dir: filepath.Join(dir, cacheSubdir), ttl: ttl}
+}
+
+// cacheKey identifies a structured request by provider, model, schema and
+// prompt, so a changed prompt template or model misses the cache.
+func (c *Client) cacheKey(prompt string, schema Schema) string {
+	schemaJSON, _ := json.Marshal(schema.JSONSchema())
+	ep := c.ep.Load()
+	h := sha256.New()
+	for _, part := range []string{ep.provider, ep.model, schema.Name, string(schemaJSON), prompt} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (dc *diskCache) path(key string) string {
+	return filepath.Join(dc.dir, key[:2], key+".json")
+}
+
+// get returns the cached entry for key if it exists and has not expired.
+func (dc *diskCache) get(key string) (cacheEntry, bool) {
+	path := dc.path(key)
+	info, err := os.Stat(path)
+	if err == nil && (dc.ttl <= 0 || time.Since(info.ModTime()) < dc.ttl) {
+		var entry cacheEntry
+		if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &entry) == nil {
+			dc.hits.Add(1)
+			dc.mu.Lock()
+			dc.saved.InputTokens += entry.Usage.InputTokens
+			dc.saved.OutputTokens += entry.Usage.OutputTokens
+			dc.mu.Unlock()
+			return entry, true
+		}
+	}
+	dc.misses.Add(1)
+	return cacheEntry{}, false
+}
+
+// set stores entry under key. The write goes through a temp file so
+// concurrent readers never see a partial response.
+func (dc *diskCache) set(key string, entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path := dc.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// usageSinkKey carries the Usage that record adds a request's tokens to.
+type usageSinkKey struct{}
+
+// withUsageSink returns a context that collects the tokens of the requests
+// made with it in u.
+func withUsageSink(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageSinkKey{}, u)
+}
+
+// completeJSONCached is CompleteJSON served from the response cache when
+// possible. Only valid responses are cached; a failed cache write just
+// means the next run asks again.
+func (c *Client) completeJSONCached(ctx context.Context, prompt string, schema Schema) (string, error) {
+	key := c.cacheKey(prompt, schema)
+	if entry, ok := c.cache.get(key); ok {
+		c.logger.DebugContext(ctx, "LLM response served from cache", "file", filenameFrom(ctx), "kind", schema.Name)
+		return entry.Response, nil
+	}
+
+	var usage Usage
+	result, err := c.completeJSON(withUsageSink(ctx, &usage), prompt, schema)
+	if err != nil {
+		return "", err
+	}
+	if err := c.cache.set(key, cacheEntry{Response: result, Usage: usage}); err != nil {
+		c.logger.DebugContext(ctx, "caching LLM response failed", "error", err)
+	}
+	return result, nil
+}
+
+// CacheStats reports response cache hits and misses and the tokens the
+// hits saved. It is zero when the cache is disabled.
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	c.cache.mu.Lock()
+	saved := c.cache.saved
+	c.cache.mu.Unlock()
+	return CacheStats{
+		Hits:   c.cache.hits.Load(),
+		Misses: c.cache.misses.Load(),
+		Saved:  saved,
+		Cost:   c.budget.CostOf(saved),
+	}
+}
+
+// ClearCache deletes the cached LLM responses under dir and reports how
+// many were removed. A missing cache is not an error.
+func ClearCache(dir string) (int, error) {
+	root := filepath.Join(dir, cacheSubdir)
+	removed := 0
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".json" {
+			removed++
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return removed, os.RemoveAll(root)
+}
@@ -0,0 +1,64 @@
+package llm
+
+import "context"
+
+// KindText is the Call kind of plain completions. Structured completions
+// use the name of their Schema.
+const KindText = "text"
+
+// Call describes one completed LLM request.
+type Call struct {
+	Filename string // set with WithFilename, empty otherwise
+	Kind     string
+	Provider string
+	Model    string
+	Usage    Usage
+	Cost     float64 // estimated, in USD
+}
+
+type filenameKey struct{}
+
+// WithFilename returns a context that attributes the LLM calls made with it
+// to filename.
+func WithFilename(ctx context.Context, filename string) context.Context {
+	return context.WithValue(ctx, filenameKey{}, filename)
+}
+
+func filenameFrom(ctx context.Context) string {
+	filename, _ := ctx.Value(filenameKey{}).(string)
+	return filename
+}
+
+// SetUsageHook registers a function called after every request the API
+// answered, from the goroutine that made it. It must be set before the
+// client is used concurrently.
+func (c *Client) SetUsageHook(hook func(ctx context.Context, call Call)) {
+	c.usageHook = hook
+}
+
+// Calls returns the number of requests the API has answered.
+func (c *Client) Calls() int {
+	return int(c.calls.Load())
+}
+
+// record adds the usage of a completed request to the budget and reports
+// it to the usage hook.
+func (c *Client) record(ctx context.Context, ep *endpoint, kind string, u Usage) {
+	c.budget.Record(u)
+	c.calls.Add(1)
+	if sink, ok := ctx.Value(usageSinkKey{}).(*Usage); ok {
+		sink.InputTokens += u.InputTokens
+		sink.OutputTokens += u.OutputTokens
+	}
+	if c.usageHook == nil {
+		return
+	}
+	c.usageHook(ctx, Call{
+		Filename: filenameFrom(ctx),
+		Kind:     kind,
+		Provider: ep.provider,
+		Model:    ep.model,
+		Usage:    u,
+		Cost:     c.budget.CostOf(u),
+	})
+}
@@ -1,19 +1,25 @@
-// Package llm provides an Anthropic API client for LLM interactions.
-// It handles communication with Claude models for parsing and matching operations.
+// Package llm provides an LLM client for parsing and matching operations.
+// It talks to the Anthropic API by default, or to any OpenAI-compatible
+// chat completions endpoint (OpenAI, Ollama, LM Studio, ...).
 package llm
 
 import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"comic-parser/internal/config"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
 )
 
 const (
@@ -24,19 +30,34 @@ const (
 	headerVersion    = "anthropic-version"
 )
 
+// Supported LLM providers, selected by the llm_provider setting.
+const (
+	ProviderAnthropic = "anthropic"
+	ProviderOpenAI    = "openai"
+)
+
 // HTTPClient defines the interface for making HTTP requests
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// Client is an Anthropic API client.
+// Client is an LLM API client.
 type Client struct {
-	apiKey      string
-	baseURL     string
-	model       string
-	maxTokens   int
+	ep          atomic.Pointer[endpoint]
 	httpClient  HTTPClient
 	rateLimiter *time.Ticker
+	budget      *Budget
+
+	// structuredUnsupported is set once the API rejects a structured request
+	structuredUnsupported atomic.Bool
+
+	calls     atomic.Int64
+	usageHook func(ctx context.Context, call Call)
+
+	// Response cache shared across runs; nil when disabled
+	cache *diskCache
+
+	logger *slog.Logger
 }
 
 // Message represents a message in the conversation
@@ -50,12 +71,30 @@ type Request struct {
 	Model     string    `json:"model"`
 	MaxTokens int       `json:"max_tokens"`
 	Messages  []Message `json:"messages"`
+
+	Tools      []Tool      `json:"tools,omitempty"`
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// Tool describes a tool the model may call
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+// ToolChoice forces the model to call a specific tool
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
 // ContentBlock represents a content block in the response
 type ContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`  // tool_use blocks
+	Input json.RawMessage `json:"input,omitempty"` // tool_use blocks
 }
 
 // Response represents an Anthropic API response
@@ -85,23 +124,129 @@ type ErrorResponse struct {
 	} `json:"error"`
 }
 
-// NewClient creates a new Anthropic API client.
-func NewClient(cfg *config.Config, httpClient HTTPClient) *Client {
-	// Calculate rate limit interval
+// APIError is returned when the API responds with a non-200 status.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error (status %d): %s - %s", e.StatusCode, e.Type, e.Message)
+}
+
+// Is reports a rate limit or server error status as models.ErrRateLimited
+// or models.ErrProviderUnavailable.
+func (e *APIError) Is(target error) bool {
+	cause := models.StatusFailure(e.StatusCode)
+	return cause != nil && cause == target
+}
+
+// permanent reports whether the status says the same request will fail
+// again, as a bad key or a malformed request does.
+func (e *APIError) permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusRequestTimeout && e.StatusCode != http.StatusTooManyRequests
+}
+
+// endpoint is the API a client sends requests to and how. Reconfigure
+// replaces it while requests are in flight, so a request reads it once.
+type endpoint struct {
+	provider  string
+	apiKey    string
+	baseURL   string
+	model     string
+	maxTokens int
+}
+
+// newEndpoint returns the endpoint cfg configures. llm_model and
+// llm_base_url override the provider's defaults.
+func newEndpoint(cfg *config.Config) *endpoint {
+	provider := cfg.LLMProvider
+	if provider == "" {
+		provider = ProviderAnthropic
+	}
+	apiKey, baseURL, model := cfg.AnthropicAPIKey, cfg.AnthropicAPIBaseURL, cfg.AnthropicModel
+	if provider == ProviderOpenAI {
+		apiKey, baseURL, model = cfg.OpenAIAPIKey, defaultOpenAIBaseURL, ""
+	}
+	if cfg.LLMBaseURL != "" {
+		baseURL = cfg.LLMBaseURL
+	}
+	if cfg.LLMModel != "" {
+		model = cfg.LLMModel
+	}
+	return &endpoint{
+		provider:  provider,
+		apiKey:    apiKey,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		model:     model,
+		maxTokens: cfg.AnthropicMaxTokens,
+	}
+}
+
+// price returns the per-token price of the endpoint's model.
+func (ep *endpoint) price() modelPrice {
+	if ep.provider == ProviderOpenAI {
+		return openAIPriceForModel(ep.model)
+	}
+	return priceForModel(ep.model)
+}
+
+// rateInterval returns the time between requests cfg allows.
+func rateInterval(cfg *config.Config) time.Duration {
 	limit := cfg.RateLimitPerMin
 	if limit <= 0 {
 		limit = 30 // Safe default
 	}
-	interval := time.Minute / time.Duration(limit)
+	return time.Minute / time.Duration(limit)
+}
+
+// NewClient creates a new LLM client for the configured provider.
+func NewClient(cfg *config.Config, httpClient HTTPClient) *Client {
+	ep := newEndpoint(cfg)
+	budget := NewBudget(ep.model, cfg.MaxLLMTokens, cfg.MaxLLMCost)
+	budget.price = ep.price()
 
-	return &Client{
-		apiKey:      cfg.AnthropicAPIKey,
-		baseURL:     cfg.AnthropicAPIBaseURL,
-		model:       cfg.AnthropicModel,
-		maxTokens:   cfg.AnthropicMaxTokens,
+	c := &Client{
 		httpClient:  httpClient,
-		rateLimiter: time.NewTicker(interval),
+		rateLimiter: time.NewTicker(rateInterval(cfg)),
+		budget:      budget,
+		logger:      logging.Logger(logging.LLM),
+	}
+	c.ep.Store(ep)
+	if cfg.CacheEnabled && cfg.LLMCache && cfg.CacheDir != "" {
+		c.cache = newDiskCache(cfg.CacheDir, time.Duration(cfg.LLMCacheTTLHours)*time.Hour)
 	}
+	return c
+}
+
+// Reconfigure applies the provider, keys, model, rate limit and budget
+// limits of cfg to later requests, as when a long-running command reloads
+// its configuration. Usage so far still counts against the new budget.
+func (c *Client) Reconfigure(cfg *config.Config) {
+	ep := newEndpoint(cfg)
+	c.budget.setLimits(ep.price(), cfg.MaxLLMTokens, cfg.MaxLLMCost)
+	if c.rateLimiter != nil {
+		c.rateLimiter.Reset(rateInterval(cfg))
+	}
+	if old := c.ep.Swap(ep); old.provider != ep.provider || old.baseURL != ep.baseURL || old.model != ep.model {
+		// The new model or server may support structured output
+		c.structuredUnsupported.Store(false)
+	}
+}
+
+// Provider returns the name of the LLM provider the client talks to.
+func (c *Client) Provider() string {
+	return c.ep.Load().provider
+}
+
+// Model returns the model the client requests completions from.
+func (c *Client) Model() string {
+	return c.ep.Load().model
 }
 
 // Close cleans up client resources.
@@ -111,45 +256,207 @@ func (c *Client) Close() {
 	}
 }
 
-// Complete sends a completion request to the Anthropic API
+// Usage returns the cumulative token usage of all requests made by this client.
+func (c *Client) Usage() Usage {
+	return c.budget.Usage()
+}
+
+// EstimatedCost returns the estimated cost in USD of all requests made by this client.
+func (c *Client) EstimatedCost() float64 {
+	return c.budget.Cost()
+}
+
+// Complete sends a completion request to the LLM API.
+// It returns ErrBudgetExceeded without contacting the API once the run's
+// token or cost budget has been used up.
 func (c *Client) Complete(ctx context.Context, prompt string) (string, error) {
-	// Respect rate limit
-	if c.rateLimiter != nil {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		case <-c.rateLimiter.C:
-			// Proceed
+	return c.complete(ctx, KindText, prompt)
+}
+
+// complete sends a plain completion request, recording its usage as kind.
+func (c *Client) complete(ctx context.Context, kind, prompt string) (string, error) {
+	if err := c.wait(ctx); err != nil {
+		return "", err
+	}
+
+	ep := c.ep.Load()
+	messages := []Message{
+		{Role: "user", Content: prompt},
+	}
+	if ep.provider == ProviderOpenAI {
+		resp, err := c.doOpenAIRequest(ctx, ep, kind, openAIRequest{
+			Model:     ep.model,
+			MaxTokens: ep.maxTokens,
+			Messages:  messages,
+		})
+		if err != nil {
+			return "", err
 		}
+		return resp.text()
 	}
 
 	req := Request{
-		Model:     c.model,
-		MaxTokens: c.maxTokens,
-		Messages: []Message{
-			{Role: "user", Content: prompt},
-		},
+		Model:     ep.model,
+		MaxTokens: ep.maxTokens,
+		Messages:  messages,
+	}
+
+	resp, err := c.doRequest(ctx, ep, kind, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.text()
+}
+
+// CompleteJSON asks for a response matching schema and returns it as a JSON
+// object. Anthropic models answer through a forced tool call and OpenAI
+// models through a JSON schema response format. When the API rejects the
+// structured request, the client falls back to a plain completion with
+// ExtractJSON for the rest of its life. Responses that don't match the
+// schema are returned as errors. With the response cache on, a request made
+// before is answered from the cache without calling the API.
+func (c *Client) CompleteJSON(ctx context.Context, prompt string, schema Schema) (string, error) {
+	if c.cache != nil {
+		return c.completeJSONCached(ctx, prompt, schema)
+	}
+	return c.completeJSON(ctx, prompt, schema)
+}
+
+// completeJSON is CompleteJSON without the cache.
+func (c *Client) completeJSON(ctx context.Context, prompt string, schema Schema) (string, error) {
+	if c.structuredUnsupported.Load() {
+		return c.completeJSONText(ctx, prompt, schema)
+	}
+
+	if err := c.wait(ctx); err != nil {
+		return "", err
+	}
+
+	result, err := c.completeStructured(ctx, prompt, schema)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		// The model or server doesn't support tools or response formats
+		c.logger.Info("structured output unsupported, falling back to text", "model", c.Model(), "error", err)
+		c.structuredUnsupported.Store(true)
+		return c.completeJSONText(ctx, prompt, schema)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := schema.Validate([]byte(result)); err != nil {
+		return "", fmt.Errorf("invalid structured response: %w", err)
+	}
+	return result, nil
+}
+
+// completeStructured sends prompt with schema attached and returns the
+// JSON the model produced.
+func (c *Client) completeStructured(ctx context.Context, prompt string, schema Schema) (string, error) {
+	ep := c.ep.Load()
+	messages := []Message{
+		{Role: "user", Content: prompt},
+	}
+
+	if ep.provider == ProviderOpenAI {
+		resp, err := c.doOpenAIRequest(ctx, ep, schema.Name, openAIRequest{
+			Model:     ep.model,
+			MaxTokens: ep.maxTokens,
+			Messages:  messages,
+			ResponseFormat: &openAIResponseFormat{
+				Type: "json_schema",
+				JSONSchema: openAIJSONSchema{
+					Name:        schema.Name,
+					Description: schema.Description,
+					Schema:      schema.JSONSchema(),
+				},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		text, err := resp.text()
+		if err != nil {
+			return "", err
+		}
+		return ExtractJSON(text), nil
+	}
+
+	resp, err := c.doRequest(ctx, ep, schema.Name, Request{
+		Model:     ep.model,
+		MaxTokens: ep.maxTokens,
+		Messages:  messages,
+		Tools: []Tool{{
+			Name:        schema.Name,
+			Description: schema.Description,
+			InputSchema: schema.JSONSchema(),
+		}},
+		ToolChoice: &ToolChoice{Type: "tool", Name: schema.Name},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, block := range resp.Content {
+		if block.Type == "tool_use" && block.Name == schema.Name {
+			return string(block.Input), nil
+		}
+	}
+	// Some models answer in text even when a tool is forced
+	text, err := resp.text()
+	if err != nil {
+		return "", err
 	}
+	return ExtractJSON(text), nil
+}
 
-	return c.doRequest(ctx, req)
+// completeJSONText is the CompleteJSON fallback for models without
+// structured output support.
+func (c *Client) completeJSONText(ctx context.Context, prompt string, schema Schema) (string, error) {
+	response, err := c.complete(ctx, schema.Name, prompt)
+	if err != nil {
+		return "", err
+	}
+	result := ExtractJSON(response)
+	if err := schema.Validate([]byte(result)); err != nil {
+		return "", fmt.Errorf("invalid response: %w (response: %s)", err, response)
+	}
+	return result, nil
 }
 
 // CompleteWithRetry sends a completion request with retry logic
 func (c *Client) CompleteWithRetry(ctx context.Context, prompt string, maxRetries int, delay time.Duration) (string, error) {
+	return c.withRetry(ctx, maxRetries, delay, func() (string, error) {
+		return c.Complete(ctx, prompt)
+	})
+}
+
+// CompleteJSONWithRetry is CompleteJSON with the retry logic of
+// CompleteWithRetry. Responses that don't match the schema are retried too.
+func (c *Client) CompleteJSONWithRetry(ctx context.Context, prompt string, schema Schema, maxRetries int, delay time.Duration) (string, error) {
+	return c.withRetry(ctx, maxRetries, delay, func() (string, error) {
+		return c.CompleteJSON(ctx, prompt, schema)
+	})
+}
+
+func (c *Client) withRetry(ctx context.Context, maxRetries int, delay time.Duration, complete func() (string, error)) (string, error) {
 	var lastErr error
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
 			// Exponential backoff: delay * 2^(attempt-1)
 			backoff := delay * time.Duration(math.Pow(2, float64(attempt-1)))
+			c.logger.DebugContext(ctx, "request failed, retrying", "file", filenameFrom(ctx), "error", lastErr,
+				"retry", attempt, "max_retries", maxRetries, "delay", backoff)
 
+			timer := time.NewTimer(backoff)
 			select {
 			case <-ctx.Done():
+				timer.Stop()
 				return "", ctx.Err()
-			case <-time.After(backoff):
+			case <-timer.C:
 			}
 		}
 
-		result, err := c.Complete(ctx, prompt)
+		result, err := complete()
 		if err == nil {
 			return result, nil
 		}
@@ -157,8 +464,11 @@ func (c *Client) CompleteWithRetry(ctx context.Context, prompt string, maxRetrie
 		lastErr = err
 
 		// Don't retry on certain errors
-		if strings.Contains(err.Error(), "invalid_api_key") ||
-			strings.Contains(err.Error(), "authentication") {
+		if errors.Is(err, ErrBudgetExceeded) {
+			return "", err
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.permanent() {
 			return "", err
 		}
 	}
@@ -166,53 +476,90 @@ func (c *Client) CompleteWithRetry(ctx context.Context, prompt string, maxRetrie
 	return "", fmt.Errorf("failed after %d attempts: %w", maxRetries+1, lastErr)
 }
 
-func (c *Client) doRequest(ctx context.Context, req Request) (string, error) {
+// wait checks the budget and waits for the rate limiter.
+func (c *Client) wait(ctx context.Context) error {
+	if c.budget.Exceeded() {
+		return ErrBudgetExceeded
+	}
+
+	// Respect rate limit; a cancelled ctx never takes a tick
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.rateLimiter != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.rateLimiter.C:
+			// Proceed
+		}
+	}
+	return nil
+}
+
+func (c *Client) doRequest(ctx context.Context, ep *endpoint, kind string, req Request) (*Response, error) {
 	body, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("marshaling request: %w", err)
+		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/messages", bytes.NewReader(body))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", ep.baseURL+"/messages", bytes.NewReader(body))
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", contentTypeJSON)
-	httpReq.Header.Set(headerAPIKey, c.apiKey)
+	httpReq.Header.Set(headerAPIKey, ep.apiKey)
 	httpReq.Header.Set(headerVersion, anthropicVersion)
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("sending request: %w", err)
+		return nil, sendError(ctx, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
 		var errResp ErrorResponse
 		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
-			return "", fmt.Errorf("API error (status %d): %s - %s",
-				resp.StatusCode, errResp.Error.Type, errResp.Error.Message)
+			apiErr.Type, apiErr.Message = errResp.Error.Type, errResp.Error.Message
 		}
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, apiErr
 	}
 
 	var apiResp Response
 	if err := json.Unmarshal(respBody, &apiResp); err != nil {
-		return "", fmt.Errorf("parsing response: %w", err)
+		return nil, fmt.Errorf("parsing response: %w", err)
 	}
 
-	if len(apiResp.Content) == 0 {
+	c.record(ctx, ep, kind, apiResp.Usage)
+
+	return &apiResp, nil
+}
+
+// sendError wraps an error sending a request, marking it as the provider
+// being unavailable unless ctx was cancelled.
+func sendError(ctx context.Context, err error) error {
+	err = fmt.Errorf("sending request: %w", err)
+	if ctx.Err() != nil {
+		return err
+	}
+	return models.Fail(models.ErrProviderUnavailable, err)
+}
+
+// text concatenates the text blocks of the response.
+func (r *Response) text() (string, error) {
+	if len(r.Content) == 0 {
 		return "", fmt.Errorf("empty response content")
 	}
 
-	// Concatenate all text blocks
 	var result strings.Builder
-	for _, block := range apiResp.Content {
+	for _, block := range r.Content {
 		if block.Type == "text" {
 			result.WriteString(block.Text)
 		}
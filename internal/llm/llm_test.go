@@ -1,7 +1,16 @@
 package llm
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"comic-parser/internal/config"
 )
 
 func TestExtractJSON(t *testing.T) {
@@ -56,3 +65,456 @@ func TestExtractJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_BudgetExceeded(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":80,"output_tokens":40}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.AnthropicAPIBaseURL = ts.URL
+	cfg.RateLimitPerMin = 60000
+	cfg.MaxLLMTokens = 100
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.Complete(ctx, "first"); err != nil {
+		t.Fatalf("first Complete failed: %v", err)
+	}
+
+	usage := client.Usage()
+	if usage.InputTokens != 80 || usage.OutputTokens != 40 {
+		t.Errorf("Usage() = %+v, want 80 in / 40 out", usage)
+	}
+
+	_, err := client.CompleteWithRetry(ctx, "second", 3, 0)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 API call, got %d", calls)
+	}
+}
+
+func TestClient_Reconfigure(t *testing.T) {
+	var gotModel, gotKey string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		gotModel, gotKey = req.Model, r.Header.Get(headerAPIKey)
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":80,"output_tokens":40}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.AnthropicAPIKey = "old-key"
+	cfg.AnthropicAPIBaseURL = ts.URL
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.Complete(ctx, "first"); err != nil {
+		t.Fatalf("first Complete failed: %v", err)
+	}
+
+	updated := *cfg
+	updated.AnthropicAPIKey = "new-key"
+	updated.LLMModel = "claude-3-haiku-20240307"
+	updated.MaxLLMTokens = 100
+	client.Reconfigure(&updated)
+
+	if client.Model() != updated.LLMModel {
+		t.Errorf("Model() = %q, want %q", client.Model(), updated.LLMModel)
+	}
+	if _, err := client.Complete(ctx, "second"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded after lowering the budget, got %v", err)
+	}
+
+	updated.MaxLLMTokens = 0
+	client.Reconfigure(&updated)
+	if _, err := client.Complete(ctx, "third"); err != nil {
+		t.Fatalf("Complete after reconfiguring failed: %v", err)
+	}
+	if gotModel != updated.LLMModel || gotKey != "new-key" {
+		t.Errorf("request used model %q and key %q, want %q and new-key", gotModel, gotKey, updated.LLMModel)
+	}
+}
+
+func TestClient_Cancelled(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.AnthropicAPIBaseURL = ts.URL
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	// A tick is ready, but a cancelled request must not take it
+	time.Sleep(5 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range 20 {
+		if _, err := client.Complete(ctx, "prompt"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("expected no API calls, got %d", calls)
+	}
+
+	// Retries stop waiting as soon as ctx is cancelled
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := client.withRetry(ctx, 3, time.Hour, func() (string, error) {
+		return "", errors.New("overloaded")
+	})
+	if !errors.Is(err, context.Canceled) || time.Since(start) > time.Second {
+		t.Errorf("expected a prompt context.Canceled, got %v after %s", err, time.Since(start))
+	}
+}
+
+func TestBudget_Cost(t *testing.T) {
+	b := NewBudget("claude-sonnet-4-20250514", 0, 0.01)
+	if b.Exceeded() {
+		t.Fatal("new budget should not be exceeded")
+	}
+
+	b.Record(Usage{InputTokens: 1000, OutputTokens: 1000})
+	// 1000 * $3/M + 1000 * $15/M = $0.018
+	if got := b.Cost(); got < 0.0179 || got > 0.0181 {
+		t.Errorf("Cost() = %f, want 0.018", got)
+	}
+	if !b.Exceeded() {
+		t.Error("expected budget to be exceeded")
+	}
+
+	unlimited := NewBudget("unknown-model", 0, 0)
+	unlimited.Record(Usage{InputTokens: 1e9})
+	if unlimited.Exceeded() {
+		t.Error("budget with no limits should never be exceeded")
+	}
+}
+
+func TestClient_OpenAI(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("expected no Authorization header without a key, got %q", got)
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.Model != "llama3.1" || len(req.Messages) != 1 || req.Messages[0].Content != "hello" {
+			t.Errorf("unexpected request %+v", req)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.LLMProvider = ProviderOpenAI
+	cfg.LLMModel = "llama3.1"
+	cfg.LLMBaseURL = ts.URL + "/v1/"
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	got, err := client.Complete(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if got != "hi" {
+		t.Errorf("Complete() = %q, want %q", got, "hi")
+	}
+	if usage := client.Usage(); usage.InputTokens != 12 || usage.OutputTokens != 3 {
+		t.Errorf("Usage() = %+v, want 12 in / 3 out", usage)
+	}
+	if cost := client.EstimatedCost(); cost != 0 {
+		t.Errorf("EstimatedCost() = %f, want 0 for a local model", cost)
+	}
+}
+
+func TestClient_OpenAIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer bad-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.LLMProvider = ProviderOpenAI
+	cfg.LLMModel = "gpt-4o-mini"
+	cfg.LLMBaseURL = ts.URL
+	cfg.OpenAIAPIKey = "bad-key"
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	_, err := client.CompleteWithRetry(context.Background(), "hello", 3, 0)
+	if err == nil || !strings.Contains(err.Error(), "invalid_api_key") {
+		t.Fatalf("expected invalid_api_key error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "attempts") {
+		t.Errorf("authentication errors should not be retried: %v", err)
+	}
+}
+
+var testSchema = Schema{
+	Name: "answer",
+	Properties: map[string]Property{
+		"index":      {Type: "integer"},
+		"confidence": {Type: "string", Enum: []string{"high", "low"}},
+		"note":       {Type: "string"},
+	},
+	Required: []string{"index", "confidence"},
+}
+
+func TestSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid", `{"index": 2, "confidence": "high", "note": "x"}`, false},
+		{"null optional", `{"index": -1, "confidence": "low", "note": null}`, false},
+		{"extra field", `{"index": 0, "confidence": "low", "other": [1]}`, false},
+		{"missing required", `{"confidence": "low"}`, true},
+		{"null required", `{"index": null, "confidence": "low"}`, true},
+		{"wrong type", `{"index": "2", "confidence": "high"}`, true},
+		{"not an integer", `{"index": 1.5, "confidence": "high"}`, true},
+		{"not in enum", `{"index": 1, "confidence": "maybe"}`, true},
+		{"not an object", `[1, 2]`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testSchema.Validate([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateArray(t *testing.T) {
+	schema := Schema{
+		Name:       "answers",
+		Properties: map[string]Property{"answers": {Type: "array", Items: &testSchema}},
+		Required:   []string{"answers"},
+	}
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid", `{"answers": [{"index": 1, "confidence": "high"}, {"index": 2, "confidence": "low"}]}`, false},
+		{"empty", `{"answers": []}`, false},
+		{"not an array", `{"answers": {"index": 1, "confidence": "high"}}`, true},
+		{"invalid item", `{"answers": [{"index": 1, "confidence": "high"}, {"index": 2}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	data, err := json.Marshal(schema.JSONSchema())
+	if err != nil {
+		t.Fatalf("encoding the schema: %v", err)
+	}
+	if !strings.Contains(string(data), `"answers":{"type":"array","items":{"properties":`) {
+		t.Errorf("JSON schema has no array items: %s", data)
+	}
+}
+
+func TestClient_CompleteJSON(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Name != "answer" || req.ToolChoice == nil || req.ToolChoice.Name != "answer" {
+			t.Errorf("expected a forced answer tool, got %+v / %+v", req.Tools, req.ToolChoice)
+		}
+		w.Write([]byte(`{"content":[{"type":"tool_use","name":"answer","input":{"index":3,"confidence":"high"}}],"usage":{"input_tokens":10,"output_tokens":5}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.AnthropicAPIBaseURL = ts.URL
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	got, err := client.CompleteJSON(context.Background(), "pick one", testSchema)
+	if err != nil {
+		t.Fatalf("CompleteJSON failed: %v", err)
+	}
+	if got != `{"index":3,"confidence":"high"}` {
+		t.Errorf("CompleteJSON() = %s", got)
+	}
+}
+
+func TestClient_CompleteJSON_Fallback(t *testing.T) {
+	var structured, plain int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.ResponseFormat != nil {
+			structured++
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"message":"response_format is not supported","type":"invalid_request_error"}}`))
+			return
+		}
+		plain++
+		w.Write([]byte("{\"choices\":[{\"message\":{\"role\":\"assistant\",\"content\":\"Sure:\\n```json\\n{\\\"index\\\": 1, \\\"confidence\\\": \\\"low\\\"}\\n```\"}}]}"))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.LLMProvider = ProviderOpenAI
+	cfg.LLMModel = "old-local-model"
+	cfg.LLMBaseURL = ts.URL
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		got, err := client.CompleteJSON(context.Background(), "pick one", testSchema)
+		if err != nil {
+			t.Fatalf("CompleteJSON failed: %v", err)
+		}
+		if got != `{"index": 1, "confidence": "low"}` {
+			t.Errorf("CompleteJSON() = %s", got)
+		}
+	}
+	if structured != 1 || plain != 2 {
+		t.Errorf("expected 1 structured and 2 plain requests, got %d and %d", structured, plain)
+	}
+}
+
+func TestClient_UsageHook(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"tool_use","name":"answer","input":{"index":0,"confidence":"high"}}],"usage":{"input_tokens":1000,"output_tokens":100}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.LLMCache = false
+	cfg.AnthropicAPIBaseURL = ts.URL
+	cfg.LLMModel = "claude-sonnet-4-20250514"
+	cfg.RateLimitPerMin = 60000
+
+	client := NewClient(cfg, ts.Client())
+	defer client.Close()
+
+	var calls []Call
+	client.SetUsageHook(func(ctx context.Context, call Call) {
+		calls = append(calls, call)
+	})
+
+	ctx := WithFilename(context.Background(), "Saga 001.cbz")
+	if _, err := client.CompleteJSON(ctx, "pick one", testSchema); err != nil {
+		t.Fatalf("CompleteJSON failed: %v", err)
+	}
+
+	if len(calls) != 1 || client.Calls() != 1 {
+		t.Fatalf("expected 1 recorded call, got %d (Calls() = %d)", len(calls), client.Calls())
+	}
+	call := calls[0]
+	if call.Filename != "Saga 001.cbz" || call.Kind != "answer" || call.Provider != ProviderAnthropic || call.Model != cfg.LLMModel {
+		t.Errorf("unexpected call %+v", call)
+	}
+	// 1000 * $3/M + 100 * $15/M = $0.0045
+	if call.Usage.InputTokens != 1000 || call.Cost < 0.00449 || call.Cost > 0.00451 {
+		t.Errorf("unexpected usage %+v / cost %f", call.Usage, call.Cost)
+	}
+}
+
+func TestClient_ResponseCache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"content":[{"type":"tool_use","name":"answer","input":{"index":0,"confidence":"high"}}],"usage":{"input_tokens":1000,"output_tokens":100}}`))
+	}))
+	defer ts.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.CacheDir = t.TempDir()
+	cfg.AnthropicAPIBaseURL = ts.URL
+	cfg.LLMModel = "claude-sonnet-4-20250514"
+	cfg.RateLimitPerMin = 60000
+
+	complete := func(cfg *config.Config, prompt string) *Client {
+		t.Helper()
+		client := NewClient(cfg, ts.Client())
+		defer client.Close()
+		got, err := client.CompleteJSON(context.Background(), prompt, testSchema)
+		if err != nil || got != `{"index":0,"confidence":"high"}` {
+			t.Fatalf("CompleteJSON = %s, %v", got, err)
+		}
+		return client
+	}
+
+	complete(cfg, "pick one")
+	client := complete(cfg, "pick one")
+	stats := client.CacheStats()
+	if requests != 1 || client.Calls() != 0 || stats.Hits != 1 || stats.Misses != 0 || stats.Saved.InputTokens != 1000 || stats.Cost == 0 {
+		t.Errorf("expected the second run to be served from the cache, got %d requests, stats %+v", requests, stats)
+	}
+
+	// Another prompt or model misses the cache
+	complete(cfg, "pick another")
+	other := *cfg
+	other.LLMModel = "claude-haiku-4-5"
+	complete(&other, "pick one")
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	other = *cfg
+	other.LLMCache = false
+	if client := complete(&other, "pick one"); requests != 4 || client.CacheStats() != (CacheStats{}) {
+		t.Errorf("expected the disabled cache to be bypassed, got %d requests", requests)
+	}
+
+	if removed, err := ClearCache(cfg.CacheDir); err != nil || removed != 3 {
+		t.Errorf("ClearCache = %d, %v; want 3 entries removed", removed, err)
+	}
+}
@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultOpenAIBaseURL is used by the openai provider when llm_base_url is
+// not set. Local servers expose the same API, e.g. Ollama at
+// http://localhost:11434/v1 and LM Studio at http://localhost:1234/v1.
+const defaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+// openAIPricing maps OpenAI model name prefixes to their published prices.
+// More specific prefixes come first. Models not listed, which is every
+// model served locally, cost nothing.
+var openAIPricing = []struct {
+	prefix string
+	price  modelPrice
+}{
+	{"gpt-4o-mini", modelPrice{input: 0.15, output: 0.60}},
+	{"gpt-4o", modelPrice{input: 2.50, output: 10}},
+	{"gpt-4.1-nano", modelPrice{input: 0.10, output: 0.40}},
+	{"gpt-4.1-mini", modelPrice{input: 0.40, output: 1.60}},
+	{"gpt-4.1", modelPrice{input: 2, output: 8}},
+}
+
+func openAIPriceForModel(model string) modelPrice {
+	for _, p := range openAIPricing {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price
+		}
+	}
+	return modelPrice{}
+}
+
+// openAIRequest is an OpenAI chat completions request
+type openAIRequest struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens,omitempty"`
+	Messages  []Message `json:"messages"`
+
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// openAIResponseFormat constrains the response to a JSON schema
+type openAIResponseFormat struct {
+	Type       string           `json:"type"`
+	JSONSchema openAIJSONSchema `json:"json_schema"`
+}
+
+type openAIJSONSchema struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Schema      map[string]any `json:"schema"`
+}
+
+// openAIResponse is an OpenAI chat completions response
+type openAIResponse struct {
+	Choices []struct {
+		Message      Message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// openAIErrorResponse is an error from an OpenAI-compatible API
+type openAIErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+	} `json:"error"`
+}
+
+func (c *Client) doOpenAIRequest(ctx context.Context, ep *endpoint, kind string, req openAIRequest) (*openAIResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", ep.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", contentTypeJSON)
+	if ep.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+ep.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, sendError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+		var errResp openAIErrorResponse
+		if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Error.Message != "" {
+			apiErr.Type, apiErr.Message = errResp.Error.Type, errResp.Error.Message
+			if code, ok := errResp.Error.Code.(string); ok && code != "" {
+				apiErr.Type = code
+			}
+		}
+		return nil, apiErr
+	}
+
+	var apiResp openAIResponse
+	if err := json.Unmarshal(respBody, &apiResp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	c.record(ctx, ep, kind, Usage{
+		InputTokens:  apiResp.Usage.PromptTokens,
+		OutputTokens: apiResp.Usage.CompletionTokens,
+	})
+
+	return &apiResp, nil
+}
+
+// text returns the content of the first choice.
+func (r *openAIResponse) text() (string, error) {
+	if len(r.Choices) == 0 {
+		return "", fmt.Errorf("empty response content")
+	}
+	return r.Choices[0].Message.Content, nil
+}
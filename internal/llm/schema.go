@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Schema describes the JSON object a structured completion must return. Its
+// fields are scalars or arrays of objects described by a nested Schema. It
+// is sent to the API as a tool (Anthropic) or a JSON schema response format
+// (OpenAI), and responses are checked against it.
+type Schema struct {
+	Name        string
+	Description string
+	Properties  map[string]Property
+	Required    []string
+}
+
+// Property describes one field of a Schema.
+type Property struct {
+	Type        string   `json:"type"` // string, integer, number, boolean or array
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"` // allowed values of a string
+	Items       *Schema  `json:"-"`              // the objects of an array
+}
+
+// MarshalJSON encodes the property as a JSON Schema, with the items of an
+// array as an object schema.
+func (p Property) MarshalJSON() ([]byte, error) {
+	type property Property
+	v := struct {
+		property
+		Items map[string]any `json:"items,omitempty"`
+	}{property: property(p)}
+	if p.Items != nil {
+		v.Items = p.Items.JSONSchema()
+	}
+	return json.Marshal(v)
+}
+
+// JSONSchema returns the schema as a JSON Schema object.
+func (s Schema) JSONSchema() map[string]any {
+	required := s.Required
+	if required == nil {
+		required = []string{}
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": s.Properties,
+		"required":   required,
+	}
+}
+
+// Validate checks that data is a JSON object with every required field
+// and that the fields it has are of the declared types. Null counts as
+// absent, and fields not in the schema are ignored.
+func (s Schema) Validate(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%s: not a JSON object: %w", s.Name, err)
+	}
+
+	for _, name := range s.Required {
+		if v, ok := fields[name]; !ok || string(v) == "null" {
+			return fmt.Errorf("%s: missing required field %q", s.Name, name)
+		}
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v, ok := fields[name]
+		if !ok || string(v) == "null" {
+			continue
+		}
+		if err := s.Properties[name].check(v); err != nil {
+			return fmt.Errorf("%s: field %q: %w", s.Name, name, err)
+		}
+	}
+	return nil
+}
+
+func (p Property) check(v json.RawMessage) error {
+	switch p.Type {
+	case "string":
+		var str string
+		if err := json.Unmarshal(v, &str); err != nil {
+			return fmt.Errorf("want a string, got %s", v)
+		}
+		if len(p.Enum) > 0 && !slices.Contains(p.Enum, str) {
+			return fmt.Errorf("%q is not one of %v", str, p.Enum)
+		}
+	case "integer", "number":
+		var n float64
+		if err := json.Unmarshal(v, &n); err != nil {
+			return fmt.Errorf("want a number, got %s", v)
+		}
+		if p.Type == "integer" && n != math.Trunc(n) {
+			return fmt.Errorf("want an integer, got %s", v)
+		}
+	case "boolean":
+		var b bool
+		if err := json.Unmarshal(v, &b); err != nil {
+			return fmt.Errorf("want a boolean, got %s", v)
+		}
+	case "array":
+		var items []json.RawMessage
+		if err := json.Unmarshal(v, &items); err != nil {
+			return fmt.Errorf("want an array, got %s", v)
+		}
+		if p.Items == nil {
+			break
+		}
+		for i, item := range items {
+			if err := p.Items.Validate(item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package logging sets up structured logging with log/slog. Each subsystem
+// logs through its own logger, whose level can be set separately, and
+// output is either human-readable text or JSON for log tooling.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Subsystems that log through Logger
+const (
+	ComicVine = "comicvine"
+	Config    = "config"
+	LLM       = "llm"
+	Parser    = "parser"
+	Processor = "processor"
+	Server    = "server"
+	Storage   = "storage"
+)
+
+var subsystems = []string{ComicVine, Config, LLM, Parser, Processor, Server, Storage}
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// subsystemKey is the attribute naming the subsystem of a record
+const subsystemKey = "subsystem"
+
+// Options configures Setup.
+type Options struct {
+	Format string // text (default) or json
+	Levels Levels
+}
+
+// Levels is the minimum level logged by default and by each subsystem.
+type Levels struct {
+	Default    slog.Level
+	Subsystems map[string]slog.Level
+}
+
+// level returns the minimum level logged by subsystem.
+func (l Levels) level(subsystem string) slog.Level {
+	if level, ok := l.Subsystems[subsystem]; ok {
+		return level
+	}
+	return l.Default
+}
+
+// lowest returns the lowest level logged by any subsystem.
+func (l Levels) lowest() slog.Level {
+	lowest := l.Default
+	for _, level := range l.Subsystems {
+		lowest = min(lowest, level)
+	}
+	return lowest
+}
+
+// ParseLevels parses a level spec such as "info" or
+// "warn,comicvine=debug,storage=error": a default level followed by
+// per-subsystem overrides, in any order. An empty spec is "info".
+func ParseLevels(spec string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, scoped := strings.Cut(part, "=")
+		if !scoped {
+			value = name
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return Levels{}, fmt.Errorf("invalid log level %q (want debug, info, warn or error)", value)
+		}
+		if !scoped {
+			levels.Default = level
+			continue
+		}
+		if !isSubsystem(name) {
+			return Levels{}, fmt.Errorf("unknown log subsystem %q (want one of %s)", name, strings.Join(subsystems, ", "))
+		}
+		if levels.Subsystems == nil {
+			levels.Subsystems = make(map[string]slog.Level)
+		}
+		levels.Subsystems[name] = level
+	}
+	return levels, nil
+}
+
+func isSubsystem(name string) bool {
+	for _, s := range subsystems {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// state is the configuration installed by Setup.
+type state struct {
+	handler slog.Handler
+	format  string
+	levels  Levels
+}
+
+var (
+	current atomic.Pointer[state]
+	output  = &swapWriter{w: os.Stderr}
+)
+
+// Setup installs the logging configuration for every logger from Logger and
+// makes it the slog and log package default. Records are written to stderr
+// until SetOutput redirects them.
+func Setup(opts Options) error {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Levels.lowest()}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(output, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	default:
+		return fmt.Errorf("unknown log format %q (want text or json)", opts.Format)
+	}
+
+	current.Store(&state{handler: handler, format: opts.Format, levels: opts.Levels})
+	slog.SetDefault(slog.New(&subsystemHandler{}))
+	return nil
+}
+
+// SetLevels changes the levels logged, keeping the format Setup installed,
+// as when a long-running command reloads its configuration.
+func SetLevels(levels Levels) error {
+	var format string
+	if s := current.Load(); s != nil {
+		format = s.format
+	}
+	return Setup(Options{Format: format, Levels: levels})
+}
+
+// SetOutput redirects log records to w and returns the previous output, so
+// it can be restored.
+func SetOutput(w io.Writer) io.Writer {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	prev := output.w
+	output.w = w
+	return prev
+}
+
+// swapWriter is an io.Writer whose destination can change while in use.
+type swapWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *swapWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Logger returns the logger of a subsystem. Records carry a "subsystem"
+// attribute and are filtered by the subsystem's level. Before Setup is
+// called, records go to the default slog handler, so applications using the
+// packages as a library keep control of logging.
+func Logger(subsystem string) *slog.Logger {
+	return slog.New(&subsystemHandler{subsystem: subsystem})
+}
+
+// subsystemHandler resolves the handler installed by Setup when a record is
+// logged, so loggers created before Setup still follow it.
+type subsystemHandler struct {
+	subsystem string
+	ops       []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls, in order
+}
+
+func (h *subsystemHandler) resolve() (slog.Handler, slog.Level) {
+	st := current.Load()
+	if st == nil {
+		return slog.Default().Handler(), slog.LevelDebug
+	}
+	return st.handler, st.levels.level(h.subsystem)
+}
+
+func (h *subsystemHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	handler, min := h.resolve()
+	return level >= min && handler.Enabled(ctx, level)
+}
+
+func (h *subsystemHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler, _ := h.resolve()
+	if h.subsystem != "" {
+		handler = handler.WithAttrs([]slog.Attr{slog.String(subsystemKey, h.subsystem)})
+	}
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *subsystemHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &subsystemHandler{subsystem: h.subsystem, ops: append(ops, op)}
+}
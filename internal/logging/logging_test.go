@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn, comicvine=debug,storage=ERROR")
+	if err != nil {
+		t.Fatalf("ParseLevels failed: %v", err)
+	}
+	if levels.Default != slog.LevelWarn || levels.level(ComicVine) != slog.LevelDebug ||
+		levels.level(Storage) != slog.LevelError || levels.level(LLM) != slog.LevelWarn {
+		t.Errorf("Unexpected levels: %+v", levels)
+	}
+	if levels.lowest() != slog.LevelDebug {
+		t.Errorf("Expected debug as the lowest level, got %s", levels.lowest())
+	}
+
+	if levels, err := ParseLevels(""); err != nil || levels.Default != slog.LevelInfo {
+		t.Errorf("Expected info by default, got %+v, %v", levels, err)
+	}
+	for _, spec := range []string{"loud", "nosuch=debug", "llm=loud"} {
+		if _, err := ParseLevels(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	// Setup replaces the slog default, which redirects the log package
+	logOutput, logFlags := log.Writer(), log.Flags()
+	defer log.SetOutput(logOutput)
+	defer log.SetFlags(logFlags)
+	defer slog.SetDefault(slog.Default())
+	defer current.Store(nil)
+
+	var buf bytes.Buffer
+	defer SetOutput(SetOutput(&buf))
+
+	levels, _ := ParseLevels("warn,processor=debug")
+	if err := Setup(Options{Format: FormatJSON, Levels: levels}); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	// Loggers follow the configuration whenever they were created
+	processor := Logger(Processor).With("file", "Saga 001.cbz")
+	processor.Debug("parsed", "title", "Saga")
+	Logger(ComicVine).Info("searched")
+	Logger(ComicVine).Warn("retrying")
+	log.Print("from the log package")
+	slog.Info("below the default level")
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON lines, got %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records, got %d: %s", len(records), buf.String())
+	}
+	if r := records[0]; r["msg"] != "parsed" || r["level"] != "DEBUG" || r["subsystem"] != Processor || r["file"] != "Saga 001.cbz" || r["title"] != "Saga" {
+		t.Errorf("Unexpected processor record: %v", r)
+	}
+	if r := records[1]; r["msg"] != "retrying" || r["subsystem"] != ComicVine {
+		t.Errorf("Unexpected comicvine record: %v", r)
+	}
+
+	// Changed levels apply to existing loggers and keep the format
+	buf.Reset()
+	quiet, _ := ParseLevels("error")
+	if err := SetLevels(quiet); err != nil {
+		t.Fatalf("SetLevels failed: %v", err)
+	}
+	processor.Warn("skipped")
+	Logger(Storage).Error("write failed")
+	if out := buf.String(); strings.Contains(out, "skipped") || !strings.HasPrefix(out, "{") || !strings.Contains(out, "write failed") {
+		t.Errorf("Expected only the error record, as JSON, got %q", out)
+	}
+
+	if err := Setup(Options{Format: "xml"}); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,231 @@
+// Package mapping imports shared filename/hash to ComicVine mapping files and
+// resolves files against them, so well-known releases skip parsing and
+// matching entirely.
+package mapping
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+const (
+	// FormatName identifies mapping files.
+	FormatName = "comic-parser-mappings"
+	// Version is the current mapping file version. Imports reject newer versions.
+	Version = 1
+
+	// ProvenancePrefix marks matches that came from a mapping file.
+	ProvenancePrefix = "mapping:"
+
+	// mappedConfidence is the match confidence assigned to mapped files
+	mappedConfidence = "high"
+)
+
+// File is a shareable mapping document.
+type File struct {
+	Format   string  `json:"format"`
+	Version  int     `json:"version"`
+	Source   string  `json:"source"`
+	Mappings []Entry `json:"mappings"`
+}
+
+// Entry maps one release to a ComicVine issue. Exactly one of Filename,
+// Pattern or SHA256 must be set.
+type Entry struct {
+	Filename    string `json:"filename,omitempty"`
+	Pattern     string `json:"pattern,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+	ComicVineID int    `json:"comicvine_id"`
+	IssueName   string `json:"issue_name,omitempty"`
+	IssueNumber string `json:"issue_number"`
+	CoverDate   string `json:"cover_date,omitempty"`
+	URL         string `json:"url,omitempty"`
+	VolumeID    int    `json:"volume_id"`
+	VolumeName  string `json:"volume_name"`
+	Publisher   string `json:"publisher,omitempty"`
+}
+
+// Read decodes and validates a mapping file. sourceOverride, when set,
+// replaces the source named in the file.
+func Read(r io.Reader, sourceOverride string) ([]models.Mapping, error) {
+	var f File
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("decoding mapping file: %w", err)
+	}
+	if f.Format != FormatName {
+		return nil, fmt.Errorf("not a mapping file (format %q)", f.Format)
+	}
+	if f.Version < 1 || f.Version > Version {
+		return nil, fmt.Errorf("unsupported mapping file version %d (supported: 1-%d)", f.Version, Version)
+	}
+
+	source := f.Source
+	if sourceOverride != "" {
+		source = sourceOverride
+	}
+	if source == "" {
+		return nil, errors.New("mapping file has no source; pass one explicitly")
+	}
+
+	now := time.Now()
+	mappings := make([]models.Mapping, 0, len(f.Mappings))
+	for i, e := range f.Mappings {
+		kind, key, err := e.key()
+		if err != nil {
+			return nil, fmt.Errorf("mapping %d: %w", i+1, err)
+		}
+		if e.ComicVineID == 0 || e.VolumeID == 0 || e.VolumeName == "" {
+			return nil, fmt.Errorf("mapping %d: comicvine_id, volume_id and volume_name are required", i+1)
+		}
+		mappings = append(mappings, models.Mapping{
+			Kind:       kind,
+			Key:        key,
+			Source:     source,
+			ImportedAt: now,
+			Issue: models.ComicVineIssue{
+				ID:            e.ComicVineID,
+				Name:          e.IssueName,
+				IssueNumber:   e.IssueNumber,
+				CoverDate:     models.ParseDateLenient(e.CoverDate),
+				SiteDetailURL: e.URL,
+				Volume: models.VolumeRef{
+					ID:        e.VolumeID,
+					Name:      e.VolumeName,
+					Publisher: e.Publisher,
+				},
+			},
+		})
+	}
+	return mappings, nil
+}
+
+func (e Entry) key() (kind, key string, err error) {
+	set := 0
+	if e.Filename != "" {
+		kind, key = models.MappingKindFilename, e.Filename
+		set++
+	}
+	if e.Pattern != "" {
+		if _, err := filepath.Match(e.Pattern, ""); err != nil {
+			return "", "", fmt.Errorf("invalid pattern %q: %w", e.Pattern, err)
+		}
+		kind, key = models.MappingKindPattern, e.Pattern
+		set++
+	}
+	if e.SHA256 != "" {
+		if _, err := hex.DecodeString(e.SHA256); err != nil || len(e.SHA256) != sha256.Size*2 {
+			return "", "", fmt.Errorf("invalid sha256 %q", e.SHA256)
+		}
+		kind, key = models.MappingKindSHA256, strings.ToLower(e.SHA256)
+		set++
+	}
+	if set != 1 {
+		return "", "", errors.New("exactly one of filename, pattern or sha256 is required")
+	}
+	return kind, key, nil
+}
+
+// Set resolves files against a collection of mappings.
+type Set struct {
+	filenames map[string]*models.Mapping
+	patterns  []*models.Mapping
+	hashes    map[string]*models.Mapping
+}
+
+// NewSet indexes mappings for lookup.
+func NewSet(mappings []models.Mapping) *Set {
+	s := &Set{
+		filenames: make(map[string]*models.Mapping),
+		hashes:    make(map[string]*models.Mapping),
+	}
+	for i := range mappings {
+		m := &mappings[i]
+		switch m.Kind {
+		case models.MappingKindFilename:
+			s.filenames[m.Key] = m
+		case models.MappingKindPattern:
+			s.patterns = append(s.patterns, m)
+		case models.MappingKindSHA256:
+			s.hashes[m.Key] = m
+		}
+	}
+	return s
+}
+
+// Len returns the number of mappings in the set.
+func (s *Set) Len() int {
+	return len(s.filenames) + len(s.patterns) + len(s.hashes)
+}
+
+// Lookup returns the mapping for filename, or nil if none applies. Exact
+// filenames win over patterns, which win over content hashes. Hashes are only
+// computed when the set has hash mappings and the file exists locally.
+func (s *Set) Lookup(filename string) (*models.Mapping, error) {
+	base := filepath.Base(filename)
+	if m, ok := s.filenames[base]; ok {
+		return m, nil
+	}
+	for _, m := range s.patterns {
+		if ok, _ := filepath.Match(m.Key, base); ok {
+			return m, nil
+		}
+	}
+
+	if len(s.hashes) == 0 {
+		return nil, nil
+	}
+	sum, err := hashFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.hashes[sum], nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Result builds the match result for a file resolved by m.
+func Result(filename string, m *models.Mapping) *models.MatchResult {
+	issue := m.Issue
+	return &models.MatchResult{
+		OriginalFilename: filename,
+		ParsedInfo: models.ParsedFilename{
+			OriginalFilename: filename,
+			Title:            issue.Volume.Name,
+			IssueNumber:      issue.IssueNumber,
+			Publisher:        issue.Volume.Publisher,
+			Confidence:       mappedConfidence,
+		},
+		SelectedIssue:   &issue,
+		MatchConfidence: mappedConfidence,
+		Reasoning:       fmt.Sprintf("Mapped by %s %q from %s", m.Kind, m.Key, m.Source),
+		ComicVineID:     issue.ID,
+		ComicVineURL:    issue.SiteDetailURL,
+		Provenance:      ProvenancePrefix + m.Source,
+	}
+}
@@ -0,0 +1,87 @@
+package mapping
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testFile = `{
+  "format": "comic-parser-mappings",
+  "version": 1,
+  "source": "community",
+  "mappings": [
+    {"filename": "Saga 001 (2012) (Digital).cbz", "comicvine_id": 1, "issue_number": "1", "volume_id": 10, "volume_name": "Saga"},
+    {"pattern": "Saga 00[2-3]*", "comicvine_id": 2, "issue_number": "2", "volume_id": 10, "volume_name": "Saga"},
+    {"sha256": "%s", "comicvine_id": 3, "issue_number": "3", "volume_id": 10, "volume_name": "Saga"}
+  ]
+}`
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	hashed := filepath.Join(dir, "renamed.cbz")
+	content := []byte("comic archive")
+	if err := os.WriteFile(hashed, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+
+	mappings, err := Read(strings.NewReader(strings.Replace(testFile, "%s", hex.EncodeToString(sum[:]), 1)), "")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	set := NewSet(mappings)
+	if set.Len() != 3 {
+		t.Fatalf("expected 3 mappings, got %d", set.Len())
+	}
+
+	tests := []struct {
+		filename string
+		wantID   int
+	}{
+		{"/library/Saga 001 (2012) (Digital).cbz", 1},
+		{"Saga 003 (2012).cbr", 2},
+		{hashed, 3},
+		{"Saga 004 (2012).cbz", 0},
+		{filepath.Join(dir, "missing.cbz"), 0},
+	}
+	for _, tt := range tests {
+		m, err := set.Lookup(tt.filename)
+		if err != nil {
+			t.Errorf("Lookup(%q): %v", tt.filename, err)
+			continue
+		}
+		got := 0
+		if m != nil {
+			got = m.Issue.ID
+		}
+		if got != tt.wantID {
+			t.Errorf("Lookup(%q) = %d, want %d", tt.filename, got, tt.wantID)
+		}
+	}
+
+	m, _ := set.Lookup("Saga 002.cbz")
+	result := Result("Saga 002.cbz", m)
+	if result.Provenance != "mapping:community" || result.ComicVineID != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestReadRejectsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"wrong format":   `{"format":"other","version":1,"source":"x"}`,
+		"newer version":  `{"format":"comic-parser-mappings","version":2,"source":"x"}`,
+		"missing source": `{"format":"comic-parser-mappings","version":1,"mappings":[]}`,
+		"two keys":       `{"format":"comic-parser-mappings","version":1,"source":"x","mappings":[{"filename":"a","pattern":"b","comicvine_id":1,"volume_id":1,"volume_name":"v"}]}`,
+		"no issue":       `{"format":"comic-parser-mappings","version":1,"source":"x","mappings":[{"filename":"a"}]}`,
+		"bad hash":       `{"format":"comic-parser-mappings","version":1,"source":"x","mappings":[{"sha256":"zz","comicvine_id":1,"volume_id":1,"volume_name":"v"}]}`,
+	}
+	for name, input := range tests {
+		if _, err := Read(strings.NewReader(input), ""); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
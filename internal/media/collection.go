@@ -0,0 +1,197 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/library"
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/storage"
+)
+
+// collectionType matches collected editions (TPB, HC, Omnibus) to the
+// ComicVine volume that collects them, instead of to an issue.
+type collectionType struct {
+	client *comicvine.Client
+}
+
+// collectionMatch is the Match of a collection item
+type collectionMatch struct {
+	volume     *models.ComicVineVolume
+	confidence string
+}
+
+func newCollectionType(cfg *config.Config, httpClient *http.Client) (Type, error) {
+	if err := cfg.ValidateComicVine(); err != nil {
+		return nil, err
+	}
+	return &collectionType{client: comicvine.NewClient(cfg, httpClient)}, nil
+}
+
+func (c *collectionType) Name() string {
+	return Collection
+}
+
+func (c *collectionType) Matches(path string) bool {
+	if !library.IsComicArchive(path) {
+		return false
+	}
+	_, ok := parser.ParseCollection(path)
+	return ok
+}
+
+// Parse reads the title, format and volume number from the filename.
+func (c *collectionType) Parse(ctx context.Context, item *Item) error {
+	parsed, ok := parser.ParseCollection(item.Filename)
+	if !ok {
+		return models.Fail(models.ErrParseFailed, errors.New("parsing filename: not a TPB, HC or Omnibus"))
+	}
+	item.Parsed = parsed
+	return nil
+}
+
+// Search finds the ComicVine volumes named like the collection.
+func (c *collectionType) Search(ctx context.Context, item *Item) error {
+	parsed := item.Parsed.(*models.ParsedCollection)
+	volumes, err := c.client.SearchSeries(ctx, parsed.Title)
+	if err != nil {
+		return fmt.Errorf("searching comicvine: %w", err)
+	}
+	item.Candidates = volumes
+	return nil
+}
+
+// Select picks the volume that best matches the collection.
+func (c *collectionType) Select(ctx context.Context, item *Item) error {
+	vol, confidence := selectCollection(item.Parsed.(*models.ParsedCollection), item.Candidates.([]models.ComicVineVolume))
+	if vol != nil {
+		item.Match = &collectionMatch{volume: vol, confidence: confidence}
+	}
+	return nil
+}
+
+func (c *collectionType) Result(ctx context.Context, item *Item, failed error) Result {
+	result := &models.CollectionResult{
+		Filename:    item.Filename,
+		Path:        item.Path,
+		ProcessedAt: item.Started,
+	}
+	result.Parsed, _ = item.Parsed.(*models.ParsedCollection)
+
+	match, _ := item.Match.(*collectionMatch)
+	switch {
+	case failed != nil:
+		result.Error = failed.Error()
+		result.ErrorCode = models.ErrorCodeOf(failed)
+	case match == nil:
+		result.Error = "no matching volume found"
+		result.ErrorCode = models.ErrorCodeNoMatch
+	default:
+		result.Success = true
+		result.Volume = match.volume
+		result.Confidence = match.confidence
+	}
+	if result.ProcessedAt.IsZero() {
+		result.ProcessedAt = time.Now()
+	}
+	return result
+}
+
+func (c *collectionType) Store(ctx context.Context, store *storage.Storage, result Result) error {
+	collection, ok := result.(*models.CollectionResult)
+	if !ok {
+		return errors.New("media: collection can only store collection results")
+	}
+	return store.SaveCollection(ctx, collection)
+}
+
+// formatWords are how ComicVine volume names spell out collected formats
+var formatWords = map[string][]string{
+	models.FormatTPB:     {"tpb", "trade paperback"},
+	models.FormatHC:      {"hc", "hardcover"},
+	models.FormatOmnibus: {"omnibus"},
+}
+
+// selectCollection picks the volume collecting parsed, with its confidence.
+// Volumes must be named like the title, optionally followed by the format
+// ("Daredevil by Frank Miller Omnibus"). Naming the format and starting in
+// the edition's year count in a volume's favour; a volume with fewer issues
+// than the collection's volume number can't be the run it belongs to. It
+// returns nil when no volume is named like the title.
+func selectCollection(parsed *models.ParsedCollection, volumes []models.ComicVineVolume) (*models.ComicVineVolume, string) {
+	want := normalizeTitle(parsed.Title)
+	wantYear, hasYear := models.ParseYear(parsed.Year)
+	volumeNumber, _ := strconv.Atoi(parsed.VolumeNumber)
+
+	var best *models.ComicVineVolume
+	var bestScore int
+	var exact, yearMatch bool
+	for i := range volumes {
+		vol := &volumes[i]
+		if volumeNumber > 0 && vol.CountOfIssues > 0 && vol.CountOfIssues < volumeNumber {
+			continue
+		}
+
+		name := normalizeTitle(vol.Name)
+		namesFormat := false
+		for _, word := range formatWords[parsed.Format] {
+			if trimmed, ok := strings.CutSuffix(name, " "+word); ok {
+				name, namesFormat = trimmed, true
+				break
+			}
+		}
+
+		score := 0
+		switch {
+		case name == want:
+			score += 100
+		case strings.Contains(name, want):
+			score += 50
+		default:
+			continue
+		}
+		if namesFormat {
+			score += 10
+		}
+		sameYear := false
+		if start, ok := models.ParseYear(vol.StartYear); ok && hasYear {
+			gap := wantYear - start
+			if gap < 0 {
+				gap = -gap
+			}
+			sameYear = gap <= 1
+			score += 20 - min(gap*5, 40)
+		}
+
+		if best == nil || score > bestScore {
+			best, bestScore = vol, score
+			exact, yearMatch = name == want, sameYear
+		}
+	}
+
+	switch {
+	case best == nil:
+		return nil, ""
+	case exact && (yearMatch || !hasYear):
+		return best, "high"
+	case exact:
+		return best, "medium"
+	}
+	return best, "low"
+}
+
+// normalizeTitle lowercases a title and reduces punctuation to single
+// spaces, so "Batman - Year One" and "Batman: Year One" compare equal.
+func normalizeTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), " ")
+}
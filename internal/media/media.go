@@ -0,0 +1,118 @@
+// Package media defines the kinds of media the pipeline identifies. A Type
+// parses a filename, searches its metadata source, selects a match among
+// the candidates and stores the outcome; the processor runs those steps for
+// every type with the same workers, checkpoints, budget and progress.
+package media
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+// Built-in media type names
+const (
+	Comic      = "comic"
+	TV         = "tv"
+	Collection = "collection"
+)
+
+// Item carries one file through the steps of a Type. Each step fills in the
+// fields the next one reads; their values belong to the type.
+type Item struct {
+	Filename string
+	Path     string // absolute path when the file exists locally
+
+	Parsed     any // set by Parse
+	Candidates any // set by Search
+	// Match is set by Select, or by Parse when the file is known without
+	// searching, in which case Search and Select are skipped.
+	Match any
+
+	// Started and Timings are filled in by the processor as the steps run
+	Started time.Time
+	Timings models.StageTimings
+}
+
+// Result is the outcome of identifying one file, such as a
+// *models.ProcessingResult for comics.
+type Result interface {
+	Succeeded() bool
+	// FailureReason describes why the file could not be identified.
+	FailureReason() string
+}
+
+// Type is a kind of media. Step errors should describe the step, e.g.
+// "parsing filename: ...", since they end up in the result as they are.
+type Type interface {
+	Name() string
+	// Matches reports whether path is a file of this type, for scanning
+	// directories.
+	Matches(path string) bool
+	Parse(ctx context.Context, item *Item) error
+	Search(ctx context.Context, item *Item) error
+	Select(ctx context.Context, item *Item) error
+	// Result builds the outcome of item. failed is the error of the step
+	// that stopped it, or nil when every step succeeded.
+	Result(ctx context.Context, item *Item, failed error) Result
+	// Store saves a result built by Result.
+	Store(ctx context.Context, store *storage.Storage, result Result) error
+}
+
+// Factory creates a media type from the configuration.
+type Factory func(cfg *config.Config, httpClient *http.Client) (Type, error)
+
+var (
+	typesMu sync.RWMutex
+	types   = map[string]Factory{
+		TV:         newTVType,
+		Collection: newCollectionType,
+	}
+)
+
+// Register makes a media type available by name. Like database/sql
+// drivers, it panics if the name is registered twice or factory is nil.
+// The comic type is built by the processor from its parser, provider and
+// selector, so it can't be replaced.
+func Register(name string, factory Factory) {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	if factory == nil {
+		panic("media: Register factory is nil")
+	}
+	if _, dup := types[name]; dup || name == Comic {
+		panic("media: Register called twice for type " + name)
+	}
+	types[name] = factory
+}
+
+// Types returns the sorted names of the media types, including comic.
+func Types() []string {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	names := []string{Comic}
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the named media type. Comics are built by the processor.
+func New(name string, cfg *config.Config, httpClient *http.Client) (Type, error) {
+	typesMu.RLock()
+	factory := types[name]
+	typesMu.RUnlock()
+	if factory == nil {
+		return nil, fmt.Errorf("unknown media type %q (available: %s)", name, strings.Join(Types(), ", "))
+	}
+	return factory(cfg, httpClient)
+}
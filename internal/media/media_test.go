@@ -0,0 +1,110 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+func TestNew(t *testing.T) {
+	if got := Types(); !reflect.DeepEqual(got, []string{Collection, Comic, TV}) {
+		t.Errorf("Types() = %v", got)
+	}
+
+	if _, err := New("film", config.DefaultConfig(), http.DefaultClient); err == nil || !strings.Contains(err.Error(), "collection, comic, tv") {
+		t.Errorf("Expected an unknown type error listing the types, got %v", err)
+	}
+	if _, err := New(TV, config.DefaultConfig(), http.DefaultClient); err == nil {
+		t.Error("Expected the tv type to need a TheTVDB API key")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.TVDBAPIKey = "key"
+	mt, err := New(TV, cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("New(tv): %v", err)
+	}
+	if mt.Name() != TV || !mt.Matches("Show.S01E01.mkv") || mt.Matches("Saga 001.cbz") {
+		t.Errorf("Unexpected tv type %s", mt.Name())
+	}
+
+	// Unparseable files fail without reaching TheTVDB
+	item := &Item{Filename: "notes.txt"}
+	err = mt.Parse(context.Background(), item)
+	result := mt.Result(context.Background(), item, err).(*models.EpisodeResult)
+	if result.Succeeded() || !strings.HasPrefix(result.FailureReason(), "parsing filename:") {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering comic to panic")
+		}
+	}()
+	Register(Comic, newTVType)
+}
+
+func TestCollectionType(t *testing.T) {
+	if _, err := New(Collection, config.DefaultConfig(), http.DefaultClient); err == nil {
+		t.Error("Expected the collection type to need a ComicVine API key")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ComicVineAPIKey = "key"
+	mt, err := New(Collection, cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("New(collection): %v", err)
+	}
+	if !mt.Matches("/comics/Saga Vol. 01 (2012) (TPB).cbz") || mt.Matches("Saga 001.cbz") || mt.Matches("Saga Vol. 01 (TPB).txt") {
+		t.Error("Unexpected collection type matches")
+	}
+
+	item := &Item{Filename: "Saga 001.cbz"}
+	err = mt.Parse(context.Background(), item)
+	result := mt.Result(context.Background(), item, err).(*models.CollectionResult)
+	if result.Succeeded() || !strings.HasPrefix(result.FailureReason(), "parsing filename:") {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
+
+func TestSelectCollection(t *testing.T) {
+	volumes := []models.ComicVineVolume{
+		{ID: 1, Name: "Saga", StartYear: "2012", CountOfIssues: 66},
+		{ID: 2, Name: "Saga", StartYear: "2014", CountOfIssues: 9},
+		{ID: 3, Name: "Saga Deluxe Edition", StartYear: "2014", CountOfIssues: 3},
+		{ID: 4, Name: "Daredevil by Frank Miller Omnibus", StartYear: "2007", CountOfIssues: 1},
+		{ID: 5, Name: "Daredevil", StartYear: "2007", CountOfIssues: 1},
+	}
+
+	tests := []struct {
+		parsed         models.ParsedCollection
+		wantID         int
+		wantConfidence string
+	}{
+		{models.ParsedCollection{Title: "Saga", Format: models.FormatTPB, VolumeNumber: "1", Year: "2014"}, 2, "high"},
+		{models.ParsedCollection{Title: "Saga", Format: models.FormatTPB, VolumeNumber: "4", Year: "2014"}, 2, "high"},
+		// Only the 66 issue volume is long enough for a tenth collection
+		{models.ParsedCollection{Title: "Saga", Format: models.FormatTPB, VolumeNumber: "10", Year: "2014"}, 1, "medium"},
+		{models.ParsedCollection{Title: "Daredevil by Frank Miller", Format: models.FormatOmnibus, Year: "2007"}, 4, "high"},
+		{models.ParsedCollection{Title: "Saga: Deluxe", Format: models.FormatHC}, 3, "low"},
+		{models.ParsedCollection{Title: "Hellboy", Format: models.FormatTPB}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		vol, confidence := selectCollection(&tt.parsed, volumes)
+		id := 0
+		if vol != nil {
+			id = vol.ID
+		}
+		if id != tt.wantID || confidence != tt.wantConfidence {
+			t.Errorf("selectCollection(%+v) = %d, %q; want %d, %q", tt.parsed, id, confidence, tt.wantID, tt.wantConfidence)
+		}
+	}
+}
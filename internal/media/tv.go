@@ -0,0 +1,110 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/storage"
+	"comic-parser/internal/tvdb"
+)
+
+// tvType matches TV episode files against TheTVDB.
+type tvType struct {
+	client *tvdb.Client
+}
+
+// episodeMatch is the Match of a TV item
+type episodeMatch struct {
+	episode    *models.TVEpisode
+	confidence string
+}
+
+func newTVType(cfg *config.Config, httpClient *http.Client) (Type, error) {
+	if err := cfg.ValidateTVDB(); err != nil {
+		return nil, err
+	}
+	return &tvType{client: tvdb.NewClient(cfg, httpClient)}, nil
+}
+
+func (t *tvType) Name() string {
+	return TV
+}
+
+func (t *tvType) Matches(path string) bool {
+	return parser.IsVideoFile(path)
+}
+
+// Parse reads the series and numbering from the filename.
+func (t *tvType) Parse(ctx context.Context, item *Item) error {
+	parsed, err := parser.ParseEpisode(item.Filename)
+	if err != nil {
+		return models.Fail(models.ErrParseFailed, fmt.Errorf("parsing filename: %w", err))
+	}
+	item.Parsed = parsed
+	return nil
+}
+
+// Search finds the series named like the parsed one.
+func (t *tvType) Search(ctx context.Context, item *Item) error {
+	parsed := item.Parsed.(*models.ParsedEpisode)
+	series, err := t.client.SearchSeries(ctx, parsed.Series, parsed.Year)
+	if err != nil {
+		return fmt.Errorf("searching tvdb: %w", err)
+	}
+	item.Candidates = series
+	return nil
+}
+
+// Select looks the episode up in the series found.
+func (t *tvType) Select(ctx context.Context, item *Item) error {
+	parsed := item.Parsed.(*models.ParsedEpisode)
+	ep, confidence, err := t.client.SelectEpisode(ctx, item.Candidates.([]tvdb.Series), parsed)
+	if err != nil {
+		return fmt.Errorf("finding episode: %w", err)
+	}
+	if ep != nil {
+		item.Match = &episodeMatch{episode: ep, confidence: confidence}
+	}
+	return nil
+}
+
+func (t *tvType) Result(ctx context.Context, item *Item, failed error) Result {
+	result := &models.EpisodeResult{
+		Filename:    item.Filename,
+		Path:        item.Path,
+		ProcessedAt: item.Started,
+	}
+	result.Parsed, _ = item.Parsed.(*models.ParsedEpisode)
+
+	match, _ := item.Match.(*episodeMatch)
+	switch {
+	case failed != nil:
+		result.Error = failed.Error()
+		result.ErrorCode = models.ErrorCodeOf(failed)
+	case match == nil:
+		result.Error = "no matching episode found"
+		result.ErrorCode = models.ErrorCodeNoMatch
+	default:
+		result.Success = true
+		result.Episode = match.episode
+		result.Confidence = match.confidence
+	}
+	if result.ProcessedAt.IsZero() {
+		result.ProcessedAt = time.Now()
+	}
+	return result
+}
+
+func (t *tvType) Store(ctx context.Context, store *storage.Storage, result Result) error {
+	episode, ok := result.(*models.EpisodeResult)
+	if !ok {
+		return errors.New("media: tv can only store episode results")
+	}
+	return store.SaveEpisode(ctx, episode)
+}
@@ -0,0 +1,360 @@
+// Package metron provides a client for the Metron (metron.cloud) comic
+// database API. Results are mapped into the same models as ComicVine so the
+// selector and storage work unchanged.
+package metron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+const (
+	// ProviderName is the metadata provider name of this client
+	ProviderName = "metron"
+
+	userAgentValue  = "ComicParser/1.0"
+	headerUserAgent = "User-Agent"
+
+	// Metron allows 30 requests per minute; stay just under it
+	rateInterval = 2100 * time.Millisecond
+
+	// maxSeriesToCheck limits how many matching series are searched for issues
+	maxSeriesToCheck = 5
+)
+
+// ErrNotFound is returned when Metron has no such resource.
+var ErrNotFound = errors.New("metron: not found")
+
+// displayYear matches the " (2012)" suffix of Metron series display names
+var displayYear = regexp.MustCompile(`\s*\(\d{4}\)$`)
+
+// HTTPClient defines the interface for making HTTP requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a Metron API client.
+type Client struct {
+	username   string
+	password   string
+	baseURL    string
+	httpClient HTTPClient
+
+	// Rate limiting
+	rateLimiter *time.Ticker
+
+	// Series details are shared by every issue of the series
+	seriesCache map[int]*series
+	cacheMutex  sync.RWMutex
+}
+
+// NewClient creates a new Metron API client authenticated with the
+// configured username and password.
+func NewClient(cfg *config.Config, httpClient HTTPClient) *Client {
+	return &Client{
+		username:    cfg.MetronUsername,
+		password:    cfg.MetronPassword,
+		baseURL:     strings.TrimSuffix(cfg.MetronAPIBaseURL, "/"),
+		httpClient:  httpClient,
+		rateLimiter: time.NewTicker(rateInterval),
+		seriesCache: make(map[int]*series),
+	}
+}
+
+// API response shapes
+type (
+	page[T any] struct {
+		Count   int `json:"count"`
+		Results []T `json:"results"`
+	}
+
+	namedRef struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	seriesListItem struct {
+		ID          int    `json:"id"`
+		DisplayName string `json:"display_name"`
+		YearBegan   int    `json:"year_began"`
+		IssueCount  int    `json:"issue_count"`
+	}
+
+	series struct {
+		ID          int      `json:"id"`
+		Name        string   `json:"name"`
+		YearBegan   int      `json:"year_began"`
+		IssueCount  int      `json:"issue_count"`
+		Publisher   namedRef `json:"publisher"`
+		ResourceURL string   `json:"resource_url"`
+	}
+
+	issueListItem struct {
+		ID        int         `json:"id"`
+		Number    string      `json:"number"`
+		CoverDate models.Date `json:"cover_date"`
+		StoreDate models.Date `json:"store_date"`
+		Image     string      `json:"image"`
+	}
+
+	issueDetail struct {
+		issueListItem
+		Series struct {
+			ID int `json:"id"`
+		} `json:"series"`
+		Title       string   `json:"title"`
+		Names       []string `json:"name"`
+		Desc        string   `json:"desc"`
+		ResourceURL string   `json:"resource_url"`
+		Credits     []struct {
+			ID      int        `json:"id"`
+			Creator string     `json:"creator"`
+			Role    []namedRef `json:"role"`
+		} `json:"credits"`
+	}
+)
+
+// Name identifies the client as a metadata provider.
+func (c *Client) Name() string {
+	return ProviderName
+}
+
+// SearchSeries searches Metron series by name.
+func (c *Client) SearchSeries(ctx context.Context, name string) ([]models.ComicVineVolume, error) {
+	items, err := c.searchSeries(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make([]models.ComicVineVolume, len(items))
+	for i, s := range items {
+		volumes[i] = models.ComicVineVolume{
+			ID:            s.ID,
+			Name:          displayYear.ReplaceAllString(s.DisplayName, ""),
+			CountOfIssues: s.IssueCount,
+		}
+		if s.YearBegan > 0 {
+			volumes[i].StartYear = strconv.Itoa(s.YearBegan)
+		}
+	}
+	return volumes, nil
+}
+
+// SearchIssues finds issues by series title and optional issue number. Like
+// the ComicVine client it searches series first, then the issues of the
+// best few series.
+func (c *Client) SearchIssues(ctx context.Context, title string, issueNumber string) ([]models.ComicVineIssue, error) {
+	items, err := c.searchSeries(ctx, title)
+	if err != nil {
+		return nil, fmt.Errorf("searching series: %w", err)
+	}
+	if len(items) > maxSeriesToCheck {
+		items = items[:maxSeriesToCheck]
+	}
+
+	var issues []models.ComicVineIssue
+	for _, item := range items {
+		params := url.Values{}
+		params.Set("series_id", strconv.Itoa(item.ID))
+		if issueNumber != "" {
+			params.Set("number", models.NormalizeIssueNumber(issueNumber))
+		}
+
+		var result page[issueListItem]
+		if err := c.get(ctx, "/issue/", params, &result); err != nil {
+			return nil, fmt.Errorf("listing issues for series %d: %w", item.ID, err)
+		}
+		if len(result.Results) == 0 {
+			continue
+		}
+
+		s, err := c.getSeries(ctx, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, li := range result.Results {
+			issue := li.toModel()
+			issue.Volume = s.toRef()
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// GetIssue fetches a single issue with its description and credits.
+func (c *Client) GetIssue(ctx context.Context, id int) (*models.ComicVineIssue, error) {
+	var detail issueDetail
+	if err := c.get(ctx, fmt.Sprintf("/issue/%d/", id), nil, &detail); err != nil {
+		return nil, err
+	}
+
+	issue := detail.toModel()
+	issue.Name = detail.Title
+	if issue.Name == "" && len(detail.Names) > 0 {
+		issue.Name = detail.Names[0]
+	}
+	issue.Description = detail.Desc
+	issue.SiteDetailURL = detail.ResourceURL
+
+	for _, credit := range detail.Credits {
+		roles := make([]string, len(credit.Role))
+		for i, r := range credit.Role {
+			roles[i] = strings.ToLower(r.Name)
+		}
+		issue.Credits = append(issue.Credits, models.Credit{
+			ID:   credit.ID,
+			Name: credit.Creator,
+			Role: strings.Join(roles, ", "),
+		})
+	}
+
+	if detail.Series.ID > 0 {
+		s, err := c.getSeries(ctx, detail.Series.ID)
+		if err != nil {
+			return nil, err
+		}
+		issue.Volume = s.toRef()
+	}
+	return &issue, nil
+}
+
+// Close cleans up the client resources
+func (c *Client) Close() {
+	if c.rateLimiter != nil {
+		c.rateLimiter.Stop()
+	}
+}
+
+func (c *Client) searchSeries(ctx context.Context, name string) ([]seriesListItem, error) {
+	params := url.Values{}
+	params.Set("name", name)
+
+	var result page[seriesListItem]
+	if err := c.get(ctx, "/series/", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Results, nil
+}
+
+// getSeries retrieves series details (with caching)
+func (c *Client) getSeries(ctx context.Context, id int) (*series, error) {
+	c.cacheMutex.RLock()
+	if s, ok := c.seriesCache[id]; ok {
+		c.cacheMutex.RUnlock()
+		return s, nil
+	}
+	c.cacheMutex.RUnlock()
+
+	var s series
+	if err := c.get(ctx, fmt.Sprintf("/series/%d/", id), nil, &s); err != nil {
+		return nil, fmt.Errorf("fetching series %d: %w", id, err)
+	}
+
+	c.cacheMutex.Lock()
+	c.seriesCache[id] = &s
+	c.cacheMutex.Unlock()
+	return &s, nil
+}
+
+// get performs a rate-limited, authenticated GET and decodes the JSON body
+// into out.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out any) error {
+	if err := c.waitRateLimit(ctx); err != nil {
+		return err
+	}
+
+	reqURL := c.baseURL + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set(headerUserAgent, userAgentValue)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("sending request: %w", err)
+		}
+		return models.Fail(models.ErrProviderUnavailable, fmt.Errorf("sending request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", path, ErrNotFound)
+	case http.StatusUnauthorized:
+		return errors.New("metron: invalid username or password")
+	default:
+		err := fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return models.Fail(models.StatusFailure(resp.StatusCode), err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
+// waitRateLimit waits for the rate limiter to allow a request. Concurrent
+// callers take turns on the ticker, and all of them return as soon as ctx is
+// done; a cancelled ctx never takes a tick.
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.rateLimiter.C:
+		return nil
+	}
+}
+
+func (li issueListItem) toModel() models.ComicVineIssue {
+	return models.ComicVineIssue{
+		ID:          li.ID,
+		IssueNumber: li.Number,
+		CoverDate:   li.CoverDate,
+		StoreDate:   li.StoreDate,
+		Image:       models.ImageRef{SmallURL: li.Image, MediumURL: li.Image, LargeURL: li.Image},
+	}
+}
+
+func (s *series) toRef() models.VolumeRef {
+	ref := models.VolumeRef{
+		ID:          s.ID,
+		Name:        s.Name,
+		SiteURL:     s.ResourceURL,
+		Publisher:   s.Publisher.Name,
+		PublisherID: s.Publisher.ID,
+		IssueCount:  s.IssueCount,
+	}
+	if s.YearBegan > 0 {
+		ref.StartYear = strconv.Itoa(s.YearBegan)
+	}
+	return ref
+}
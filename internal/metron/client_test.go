@@ -0,0 +1,113 @@
+package metron
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"comic-parser/internal/config"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	client := NewClient(&config.Config{
+		MetronUsername:   "user",
+		MetronPassword:   "pass",
+		MetronAPIBaseURL: ts.URL + "/",
+	}, ts.Client())
+	t.Cleanup(client.Close)
+
+	// Speed up rate limiter for test
+	client.rateLimiter.Stop()
+	client.rateLimiter = time.NewTicker(1 * time.Millisecond)
+	return client
+}
+
+func TestSearchIssues(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("Unexpected basic auth %q/%q", user, pass)
+		}
+		switch r.URL.Path {
+		case "/series/":
+			if name := r.URL.Query().Get("name"); name != "Saga" {
+				t.Errorf("Expected name Saga, got %q", name)
+			}
+			w.Write([]byte(`{"count":2,"results":[{"id":10,"display_name":"Saga (2012)","year_began":2012,"issue_count":66},{"id":11,"display_name":"Saga Deluxe (2020)","year_began":2020}]}`))
+		case "/issue/":
+			query := r.URL.Query()
+			if query.Get("number") != "1" {
+				t.Errorf("Expected normalized number 1, got %q", query.Get("number"))
+			}
+			if query.Get("series_id") != "10" {
+				w.Write([]byte(`{"count":0,"results":[]}`))
+				return
+			}
+			w.Write([]byte(`{"count":1,"results":[{"id":500,"number":"1","cover_date":"2012-03-01","image":"https://example.com/saga.jpg"}]}`))
+		case "/series/10/":
+			w.Write([]byte(`{"id":10,"name":"Saga","year_began":2012,"issue_count":66,"publisher":{"id":3,"name":"Image"},"resource_url":"https://metron.cloud/series/saga-2012/"}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	issues, err := client.SearchIssues(context.Background(), "Saga", "001")
+	if err != nil {
+		t.Fatalf("SearchIssues failed: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d", len(issues))
+	}
+	issue := issues[0]
+	if issue.ID != 500 || issue.CoverDate.Year != 2012 || issue.Image.SmallURL == "" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	if issue.Volume.ID != 10 || issue.Volume.Publisher != "Image" || issue.Volume.StartYear != "2012" || issue.Volume.IssueCount != 66 {
+		t.Errorf("Unexpected volume: %+v", issue.Volume)
+	}
+
+	vols, err := client.SearchSeries(context.Background(), "Saga")
+	if err != nil {
+		t.Fatalf("SearchSeries failed: %v", err)
+	}
+	if len(vols) != 2 || vols[0].Name != "Saga" || vols[0].StartYear != "2012" {
+		t.Errorf("Unexpected series: %+v", vols)
+	}
+}
+
+func TestGetIssue(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/issue/500/":
+			w.Write([]byte(`{"id":500,"number":"1","series":{"id":10},"name":["Chapter One"],"desc":"The beginning.","resource_url":"https://metron.cloud/issue/saga-2012-1/","credits":[{"id":1,"creator":"Brian K. Vaughan","role":[{"id":1,"name":"Writer"}]},{"id":2,"creator":"Fiona Staples","role":[{"id":2,"name":"Artist"},{"id":3,"name":"Cover"}]}]}`))
+		case "/series/10/":
+			w.Write([]byte(`{"id":10,"name":"Saga","year_began":2012,"publisher":{"id":3,"name":"Image"}}`))
+		case "/issue/404/":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	issue, err := client.GetIssue(context.Background(), 500)
+	if err != nil {
+		t.Fatalf("GetIssue failed: %v", err)
+	}
+	if issue.Name != "Chapter One" || issue.Volume.Publisher != "Image" {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	credits := issue.Credits
+	if len(credits) != 2 || credits[1].Name != "Fiona Staples" || credits[1].Role != "artist, cover" {
+		t.Errorf("Unexpected credits: %+v", credits)
+	}
+
+	if _, err := client.GetIssue(context.Background(), 404); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
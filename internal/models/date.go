@@ -0,0 +1,134 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date is a calendar date that may be partial: ComicVine and filenames often
+// only give a year, or a year and month. Missing parts are zero.
+// Its string form ("2012", "2012-03", "2012-03-01") sorts chronologically.
+type Date struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+// ParseDate parses "YYYY", "YYYY-MM" or "YYYY-MM-DD", ignoring any time part.
+// An empty string yields the zero Date.
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}, nil
+	}
+	if i := strings.IndexAny(s, " T"); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 {
+		return Date{}, fmt.Errorf("invalid date %q", s)
+	}
+
+	var d Date
+	fields := []*int{&d.Year, &d.Month, &d.Day}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Date{}, fmt.Errorf("invalid date %q", s)
+		}
+		*fields[i] = n
+	}
+
+	if d.Year < 1 || d.Month < 0 || d.Month > 12 || d.Day < 0 || d.Day > 31 || (d.Day > 0 && d.Month == 0) {
+		return Date{}, fmt.Errorf("invalid date %q", s)
+	}
+	// ComicVine uses "-00" for unknown day/month; treat them as missing
+	if d.Month == 0 {
+		d.Day = 0
+	}
+	return d, nil
+}
+
+// ParseDateLenient is ParseDate that returns the zero Date for invalid input.
+func ParseDateLenient(s string) Date {
+	d, _ := ParseDate(s)
+	return d
+}
+
+// ParseYear parses a four-digit year such as a filename's "(2012)".
+func ParseYear(s string) (int, bool) {
+	d, err := ParseDate(strings.Trim(strings.TrimSpace(s), "()"))
+	if err != nil || d.IsZero() {
+		return 0, false
+	}
+	return d.Year, true
+}
+
+// IsZero reports whether the date is unset.
+func (d Date) IsZero() bool {
+	return d.Year == 0
+}
+
+// String formats the date with the precision it has, or "" when unset.
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.Month == 0:
+		return fmt.Sprintf("%04d", d.Year)
+	case d.Day == 0:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	default:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	}
+}
+
+// Time returns the first instant of the period the date covers, in UTC.
+func (d Date) Time() time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+	month, day := time.Month(max(d.Month, 1)), max(d.Day, 1)
+	return time.Date(d.Year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Compare returns -1, 0 or +1 depending on whether d is before, equal to or
+// after other. Partial dates sort before complete dates within their period.
+func (d Date) Compare(other Date) int {
+	for _, pair := range [][2]int{{d.Year, other.Year}, {d.Month, other.Month}, {d.Day, other.Day}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// MarshalJSON encodes the date as its string form, or null when unset.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts null or a date string. Unparseable dates from
+// external APIs are treated as unset rather than failing the whole response.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	if s == nil {
+		*d = Date{}
+		return nil
+	}
+	*d = ParseDateLenient(*s)
+	return nil
+}
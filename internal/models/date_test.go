@@ -0,0 +1,83 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Date
+		wantErr bool
+	}{
+		{"", Date{}, false},
+		{"2012", Date{Year: 2012}, false},
+		{"2012-03", Date{Year: 2012, Month: 3}, false},
+		{"2012-03-01", Date{Year: 2012, Month: 3, Day: 1}, false},
+		{"2012-03-01 00:00:00", Date{Year: 2012, Month: 3, Day: 1}, false},
+		{"2012-00-00", Date{Year: 2012}, false},
+		{"2012-13", Date{}, true},
+		{"March 2012", Date{}, true},
+		{"2012-03-01-05", Date{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDate(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDate(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDate(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDate_SortableString(t *testing.T) {
+	dates := []Date{
+		{Year: 2012, Month: 3, Day: 14},
+		{Year: 1999},
+		{Year: 2012, Month: 3},
+		{Year: 2012, Month: 11, Day: 1},
+	}
+
+	byCompare := append([]Date(nil), dates...)
+	sort.Slice(byCompare, func(i, j int) bool { return byCompare[i].Compare(byCompare[j]) < 0 })
+
+	byString := append([]Date(nil), dates...)
+	sort.Slice(byString, func(i, j int) bool { return byString[i].String() < byString[j].String() })
+
+	for i := range byCompare {
+		if byCompare[i] != byString[i] {
+			t.Fatalf("String order %v differs from Compare order %v", byString, byCompare)
+		}
+	}
+	if byCompare[0].String() != "1999" || byCompare[1].String() != "2012-03" {
+		t.Errorf("Unexpected order: %v", byCompare)
+	}
+}
+
+func TestDate_JSON(t *testing.T) {
+	var issue ComicVineIssue
+	if err := json.Unmarshal([]byte(`{"cover_date":"2012-03-01","store_date":null}`), &issue); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if issue.CoverDate != (Date{Year: 2012, Month: 3, Day: 1}) || !issue.StoreDate.IsZero() {
+		t.Errorf("Unexpected dates: %+v / %+v", issue.CoverDate, issue.StoreDate)
+	}
+
+	data, err := json.Marshal(Date{Year: 2012, Month: 3})
+	if err != nil || string(data) != `"2012-03"` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+
+	if got := (Date{Year: 2012}).Time(); got.Month() != 1 || got.Day() != 1 {
+		t.Errorf("Time() = %v, want January 1st", got)
+	}
+
+	if y, ok := ParseYear("(2018)"); !ok || y != 2018 {
+		t.Errorf("ParseYear = %d, %v", y, ok)
+	}
+}
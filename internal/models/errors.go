@@ -0,0 +1,82 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Causes of a failed result. The pipeline steps and API clients mark their
+// errors with them (see Fail), so callers can tell failures apart with
+// errors.Is instead of reading messages.
+var (
+	ErrParseFailed         = errors.New("parse failed")
+	ErrNoMatch             = errors.New("no match")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrProviderUnavailable = errors.New("provider unavailable")
+)
+
+// Error codes of failed results, as recorded in ProcessingResult.ErrorCode
+const (
+	ErrorCodeParseFailed         = "parse_failed"
+	ErrorCodeNoMatch             = "no_match"
+	ErrorCodeRateLimited         = "rate_limited"
+	ErrorCodeProviderUnavailable = "provider_unavailable"
+	ErrorCodeCancelled           = "cancelled"
+	ErrorCodeOther               = "other"
+)
+
+// failure is an error marked with its cause. It reads like the error.
+type failure struct {
+	cause error
+	err   error
+}
+
+func (f *failure) Error() string   { return f.err.Error() }
+func (f *failure) Unwrap() []error { return []error{f.err, f.cause} }
+
+// Fail marks err as caused by cause, one of the Err* failures, keeping its
+// message: errors.Is(Fail(cause, err), cause) holds, as it does for the
+// errors err wraps. A nil cause or err returns err unchanged.
+func Fail(cause, err error) error {
+	if cause == nil || err == nil {
+		return err
+	}
+	return &failure{cause: cause, err: err}
+}
+
+// StatusFailure returns the failure an HTTP status reports: ErrRateLimited
+// for 429 (and ComicVine's 420), ErrProviderUnavailable for 5xx, and nil for
+// the rest.
+func StatusFailure(status int) error {
+	switch {
+	case status == http.StatusTooManyRequests || status == 420:
+		return ErrRateLimited
+	case status >= 500:
+		return ErrProviderUnavailable
+	}
+	return nil
+}
+
+// ErrorCodeOf returns the error code of a failure: "" for nil,
+// ErrorCodeCancelled for a cancelled or timed out context, the code of the
+// Err* failure err is marked with, and ErrorCodeOther otherwise. A parse
+// that failed because the LLM was rate limited is ErrorCodeRateLimited: the
+// cause that decides whether a retry can help wins.
+func ErrorCodeOf(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeCancelled
+	case errors.Is(err, ErrRateLimited):
+		return ErrorCodeRateLimited
+	case errors.Is(err, ErrProviderUnavailable):
+		return ErrorCodeProviderUnavailable
+	case errors.Is(err, ErrParseFailed):
+		return ErrorCodeParseFailed
+	case errors.Is(err, ErrNoMatch):
+		return ErrorCodeNoMatch
+	}
+	return ErrorCodeOther
+}
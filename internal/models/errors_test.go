@@ -0,0 +1,47 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	limited := Fail(StatusFailure(429), errors.New("API error (status 429)"))
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"plain", errors.New("boom"), ErrorCodeOther},
+		{"parse", Fail(ErrParseFailed, errors.New("bad json")), ErrorCodeParseFailed},
+		{"no match", fmt.Errorf("selecting: %w", ErrNoMatch), ErrorCodeNoMatch},
+		{"rate limited", limited, ErrorCodeRateLimited},
+		{"unavailable", Fail(StatusFailure(503), errors.New("API error (status 503)")), ErrorCodeProviderUnavailable},
+		{"parse rate limited", Fail(ErrParseFailed, fmt.Errorf("llm parse: %w", limited)), ErrorCodeRateLimited},
+		{"cancelled", Fail(ErrParseFailed, context.Canceled), ErrorCodeCancelled},
+		{"client error", Fail(StatusFailure(400), errors.New("API error (status 400)")), ErrorCodeOther},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorCodeOf(tt.err); got != tt.want {
+			t.Errorf("%s: ErrorCodeOf(%v) = %q, want %q", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFail_KeepsMessage(t *testing.T) {
+	inner := errors.New("bad json")
+	err := Fail(ErrParseFailed, fmt.Errorf("llm parse: %w", inner))
+	if err.Error() != "llm parse: bad json" {
+		t.Errorf("Error() = %q, want the wrapped message", err.Error())
+	}
+	if !errors.Is(err, ErrParseFailed) || !errors.Is(err, inner) {
+		t.Errorf("Fail should match both its cause and the errors it wraps")
+	}
+	if Fail(nil, inner) != inner {
+		t.Errorf("Fail with a nil cause should return err unchanged")
+	}
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// IgnoreRule names files that scans and batches skip: a glob pattern, as
+// for -exclude, or an exact file name.
+type IgnoreRule struct {
+	Pattern   string    `json:"pattern"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches reports whether the rule ignores file. The pattern is matched
+// against the file's base name and its whole path, and also compared with
+// them as is, so names with brackets, like "Saga [Group].cbz", can be
+// ignored without escaping them.
+func (r IgnoreRule) Matches(file string) bool {
+	base := filepath.Base(file)
+	for _, name := range []string{base, file} {
+		if r.Pattern == name {
+			return true
+		}
+		if ok, _ := filepath.Match(r.Pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoreList is a set of ignore rules.
+type IgnoreList []IgnoreRule
+
+// Match returns the first rule ignoring file, reporting false when none
+// does.
+func (l IgnoreList) Match(file string) (IgnoreRule, bool) {
+	for _, rule := range l {
+		if rule.Matches(file) {
+			return rule, true
+		}
+	}
+	return IgnoreRule{}, false
+}
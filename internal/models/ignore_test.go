@@ -0,0 +1,31 @@
+package models
+
+import "testing"
+
+func TestIgnoreList_Match(t *testing.T) {
+	list := IgnoreList{
+		{Pattern: "*.sample.cbz"},
+		{Pattern: "Saga [Group].cbz"},
+		{Pattern: "/comics/previews/*"},
+	}
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"Batman 001.sample.cbz", "*.sample.cbz"},
+		{"/comics/Batman/Batman 001.sample.cbz", "*.sample.cbz"},
+		{"Saga [Group].cbz", "Saga [Group].cbz"},
+		{"/comics/Saga/Saga [Group].cbz", "Saga [Group].cbz"},
+		{"/comics/previews/Saga 001.cbz", "/comics/previews/*"},
+		{"Saga 001.cbz", ""},
+		{"Batman 001.cbz", ""},
+		{"/comics/previews/2024/Saga 001.cbz", ""},
+	}
+
+	for _, tt := range tests {
+		rule, ok := list.Match(tt.file)
+		if ok != (tt.want != "") || rule.Pattern != tt.want {
+			t.Errorf("Match(%q) = %q, %v, want %q", tt.file, rule.Pattern, ok, tt.want)
+		}
+	}
+}
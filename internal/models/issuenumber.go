@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fractions maps the ways filenames and ComicVine write fractional issue
+// numbers to their decimal value.
+var fractions = map[string]string{
+	"½": "0.5", "1/2": "0.5",
+	"¼": "0.25", "1/4": "0.25",
+	"¾": "0.75", "3/4": "0.75",
+}
+
+// issueNumber is an issue number split into its parts: "-001.1" is negative
+// with whole "1" and decimal "1", "1.MU" has whole "1" and suffix ".MU".
+type issueNumber struct {
+	negative bool
+	whole    string // digits without leading zeros, "0" for zero
+	decimal  string // digits after the point, as written
+	suffix   string // what follows the number, like ".MU" or "AU"
+}
+
+// parseIssueNumber splits n into its parts. It reports false for numbers
+// without digits, like "Annual".
+func parseIssueNumber(n string) (issueNumber, bool) {
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(n), "#"))
+	rest = strings.Replace(rest, "−", "-", 1) // Unicode minus sign
+
+	var num issueNumber
+	if len(rest) > 1 && rest[0] == '-' {
+		num.negative = true
+		rest = rest[1:]
+	}
+	if value, ok := fractions[rest]; ok {
+		rest = value
+	}
+
+	whole := leadingDigits(rest)
+	num.whole, rest = strings.TrimLeft(whole, "0"), rest[len(whole):]
+	// ".5" and "1.1" have a decimal part, "1.MU" has a suffix
+	if len(rest) > 1 && rest[0] == '.' {
+		num.decimal = leadingDigits(rest[1:])
+		if num.decimal != "" {
+			rest = rest[1+len(num.decimal):]
+		}
+	}
+	if whole == "" && num.decimal == "" {
+		return issueNumber{}, false
+	}
+	if num.whole == "" {
+		num.whole = "0"
+	}
+
+	// A single letter, as in "1A", marks a variant cover of the issue
+	num.suffix = strings.TrimSpace(rest)
+	if len(num.suffix) == 1 && unicode.IsLetter(rune(num.suffix[0])) {
+		num.suffix = ""
+	}
+	return num, true
+}
+
+// leadingDigits returns the ASCII digits s starts with.
+func leadingDigits(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		return s
+	}
+	return s[:end]
+}
+
+func (n issueNumber) String() string {
+	s := n.whole
+	if n.negative && s != "0" {
+		s = "-" + s
+	}
+	if n.decimal != "" {
+		s += "." + n.decimal
+	}
+	return s + n.suffix
+}
+
+// NormalizeIssueNumber returns issue number n in the form ComicVine and
+// Metron number issues by, for searching them: without a "#" or leading
+// zeros, fractions as decimals and variant letters dropped, so "#001",
+// "1A" and "1" are all "1", "½" is "0.5", "001.1" is "1.1" and "-01" is
+// "-1". Suffixes like the ".MU" of "1.MU" are kept. Numbers without digits,
+// like "Annual", are returned trimmed, and an empty n stays empty.
+func NormalizeIssueNumber(n string) string {
+	num, ok := parseIssueNumber(n)
+	if !ok {
+		return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(n), "#"))
+	}
+	return num.String()
+}
+
+// SameIssueNumber reports whether a and b number the same issue, ignoring
+// case and the differences NormalizeIssueNumber removes.
+func SameIssueNumber(a, b string) bool {
+	return strings.EqualFold(NormalizeIssueNumber(a), NormalizeIssueNumber(b))
+}
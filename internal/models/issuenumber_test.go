@@ -0,0 +1,77 @@
+package models
+
+import "testing"
+
+func TestNormalizeIssueNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1", "1"},
+		{"01", "1"},
+		{"001", "1"},
+		{"#1", "1"},
+		{"#001", "1"},
+		{"  1  ", "1"},
+		{"0", "0"},
+		{"000", "0"},
+		{"", ""},
+		{"1.1", "1.1"},
+		{"001.1", "1.1"},
+		{"1.10", "1.10"},
+		{".5", "0.5"},
+		{"0.5", "0.5"},
+		{"½", "0.5"},
+		{"1/2", "0.5"},
+		{"#½", "0.5"},
+		{"¾", "0.75"},
+		{"-1", "-1"},
+		{"-01", "-1"},
+		{"−1", "-1"},
+		{"-0", "0"},
+		{"1A", "1"},
+		{"001b", "1"},
+		{"1.MU", "1.MU"},
+		{"001.MU", "1.MU"},
+		{"5AU", "5AU"},
+		{"Annual", "Annual"},
+		{"#Annual", "Annual"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeIssueNumber(tt.input); got != tt.want {
+			t.Errorf("NormalizeIssueNumber(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSameIssueNumber(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"001", "1", true},
+		{"#1", "1", true},
+		{"½", "0.5", true},
+		{"1/2", ".5", true},
+		{"1A", "1", true},
+		{"1a", "1B", true},
+		{"1.mu", "1.MU", true},
+		{"-1", "-001", true},
+		{"001.1", "1.1", true},
+		{"annual", "Annual", true},
+		{"1", "10", false},
+		{"1", "1.1", false},
+		{"1", "1.MU", false},
+		{"-1", "1", false},
+		{"½", "1", false},
+		{"5AU", "5", false},
+		{"", "0", false},
+	}
+
+	for _, tt := range tests {
+		if got := SameIssueNumber(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameIssueNumber(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import "strings"
+
+// Language filters of MatchesLanguage besides language codes
+const (
+	LanguageOriginal   = "original"   // files without a language tag
+	LanguageTranslated = "translated" // files with one
+)
+
+// languageCodes maps the language names, native names and codes release
+// tags use to ISO 639-1 codes.
+var languageCodes = map[string]string{
+	"english": "en", "eng": "en", "en": "en",
+	"french": "fr", "français": "fr", "francais": "fr", "fre": "fr", "fra": "fr", "fr": "fr",
+	"spanish": "es", "español": "es", "espanol": "es", "castellano": "es", "spa": "es", "esp": "es", "es": "es",
+	"german": "de", "deutsch": "de", "ger": "de", "deu": "de", "de": "de",
+	"italian": "it", "italiano": "it", "ita": "it", "it": "it",
+	"portuguese": "pt", "português": "pt", "portugues": "pt", "por": "pt", "pt": "pt", "pt-br": "pt", "ptbr": "pt",
+	"russian": "ru", "rus": "ru", "ru": "ru",
+	"polish": "pl", "polski": "pl", "pol": "pl", "pl": "pl",
+	"dutch": "nl", "nederlands": "nl", "dut": "nl", "nld": "nl", "nl": "nl",
+	"japanese": "ja", "jpn": "ja", "jp": "ja", "ja": "ja",
+	"chinese": "zh", "chi": "zh", "zho": "zh", "zh": "zh",
+	"korean": "ko", "kor": "ko", "ko": "ko",
+	"turkish": "tr", "tur": "tr", "tr": "tr",
+	"indonesian": "id", "ind": "id",
+	"vietnamese": "vi", "vie": "vi", "vi": "vi",
+	"arabic": "ar", "ara": "ar", "ar": "ar",
+}
+
+// LanguageCode returns the ISO 639-1 code of a language name or code, as in
+// "French", "fra" or "FR", or "" when it names no language it knows.
+func LanguageCode(name string) string {
+	return languageCodes[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// MatchesLanguage reports whether a file tagged with language (a code, ""
+// for none) passes filter: LanguageOriginal, LanguageTranslated, a language
+// name or code, or "" for any.
+func MatchesLanguage(language, filter string) bool {
+	switch filter = strings.ToLower(strings.TrimSpace(filter)); filter {
+	case "":
+		return true
+	case LanguageOriginal:
+		return language == ""
+	case LanguageTranslated:
+		return language != ""
+	}
+	return language != "" && language == LanguageCode(filter)
+}
+
+// ValidLanguageFilter reports whether MatchesLanguage understands filter.
+func ValidLanguageFilter(filter string) bool {
+	switch filter = strings.ToLower(strings.TrimSpace(filter)); filter {
+	case "", LanguageOriginal, LanguageTranslated:
+		return true
+	}
+	return LanguageCode(filter) != ""
+}
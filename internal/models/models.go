@@ -2,20 +2,48 @@
 // These models represent parsed filenames, ComicVine API responses, and processing results.
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode"
+)
 
 // ParsedFilename represents the LLM-extracted information from a comic filename.
 type ParsedFilename struct {
-	OriginalFilename string `json:"original_filename"`
-	Title            string `json:"title"`
-	IssueNumber      string `json:"issue_number"`
-	Year             string `json:"year,omitempty"`
-	Publisher        string `json:"publisher,omitempty"`
-	VolumeNumber     string `json:"volume_number,omitempty"`
-	Confidence       string `json:"confidence"` // high, medium, low
-	Notes            string `json:"notes,omitempty"`
+	OriginalFilename string   `json:"original_filename"`
+	Title            string   `json:"title"`
+	IssueNumber      string   `json:"issue_number"`
+	Year             string   `json:"year,omitempty"`
+	Publisher        string   `json:"publisher,omitempty"`
+	VolumeNumber     string   `json:"volume_number,omitempty"`
+	Chapter          string   `json:"chapter,omitempty"` // manga chapter; a manga volume is parsed as the IssueNumber
+	Issues           []string `json:"issues,omitempty"`  // every issue of a file collecting several, starting with IssueNumber
+	Confidence       string   `json:"confidence"`        // high, medium, low
+	Notes            string   `json:"notes,omitempty"`
+	Special          string   `json:"special,omitempty"`    // see Special* constants
+	IssueType        string   `json:"issue_type,omitempty"` // see IssueType* constants; "" for a regular issue
+	Language         string   `json:"language,omitempty"`   // ISO 639-1 code from a tag like "(French)"; "" for untagged files
+	Path             string   `json:"path,omitempty"`       // absolute path when the file exists locally
 }
 
+// Special release kinds. These are published in dedicated ComicVine volumes
+// rather than as issues of the main series.
+const (
+	SpecialFCBD    = "fcbd"    // Free Comic Book Day edition
+	SpecialPreview = "preview" // preview or sneak peek
+	SpecialAshcan  = "ashcan"  // ashcan edition
+	SpecialPromo   = "promo"   // promotional giveaway, often numbered #0
+)
+
+// Issue types of releases outside a series' regular numbering. ComicVine
+// usually files them under volumes of their own, such as "Batman Annual".
+const (
+	IssueTypeAnnual    = "annual"     // yearly annual, often numbered by year
+	IssueTypeOneShot   = "one-shot"   // single-issue story
+	IssueTypeSpecial   = "special"    // special issue of a series
+	IssueTypeGiantSize = "giant-size" // oversized Giant-Size edition
+)
+
 // ComicVineSearchParams holds the parameters for a ComicVine search
 type ComicVineSearchParams struct {
 	Title       string
@@ -27,20 +55,71 @@ type ComicVineIssue struct {
 	ID            int       `json:"id"`
 	Name          string    `json:"name"`
 	IssueNumber   string    `json:"issue_number"`
-	CoverDate     string    `json:"cover_date"`
-	StoreDate     string    `json:"store_date"`
+	CoverDate     Date      `json:"cover_date"`
+	StoreDate     Date      `json:"store_date"`
 	Description   string    `json:"description"`
 	SiteDetailURL string    `json:"site_detail_url"`
 	Volume        VolumeRef `json:"volume"`
 	Image         ImageRef  `json:"image"`
+	Credits       []Credit  `json:"person_credits,omitempty"` // only from issue detail lookups
+
+	// Also only from issue detail lookups
+	Characters []ResourceRef `json:"character_credits,omitempty"`
+	Teams      []ResourceRef `json:"team_credits,omitempty"`
+	Locations  []ResourceRef `json:"location_credits,omitempty"`
+	StoryArcs  []ResourceRef `json:"story_arc_credits,omitempty"`
+}
+
+// HasDetails reports whether the issue carries details only issue detail
+// lookups return.
+func (i *ComicVineIssue) HasDetails() bool {
+	return i.Description != "" || len(i.Credits) > 0 || len(i.Characters) > 0 ||
+		len(i.Teams) > 0 || len(i.Locations) > 0 || len(i.StoryArcs) > 0
+}
+
+// SetDetails copies the details only issue detail lookups return from d.
+func (i *ComicVineIssue) SetDetails(d *ComicVineIssue) {
+	i.Description = d.Description
+	i.Credits = d.Credits
+	i.Characters = d.Characters
+	i.Teams = d.Teams
+	i.Locations = d.Locations
+	i.StoryArcs = d.StoryArcs
+}
+
+// ResourceRef is a reference to a ComicVine resource such as a character,
+// team, location or story arc.
+type ResourceRef struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Credit is a creator credited on an issue. Role may list several
+// comma-separated roles, e.g. "writer, penciler".
+type Credit struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
 }
 
 // VolumeRef is a reference to a volume in ComicVine
 type VolumeRef struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	SiteURL   string `json:"site_detail_url"`
-	Publisher string `json:"publisher_name,omitempty"` // We'll populate this
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	SiteURL     string `json:"site_detail_url"`
+	Publisher   string `json:"publisher_name,omitempty"` // We'll populate this
+	PublisherID int    `json:"publisher_id,omitempty"`
+	StartYear   string `json:"start_year,omitempty"` // disambiguates same-named series
+	IssueCount  int    `json:"count_of_issues,omitempty"`
+}
+
+// Label names the volume with its start year, as in "Batman (2016)", to
+// tell same-named volumes apart.
+func (v VolumeRef) Label() string {
+	if v.StartYear == "" {
+		return v.Name
+	}
+	return v.Name + " (" + v.StartYear + ")"
 }
 
 // ImageRef holds image URLs from ComicVine
@@ -61,6 +140,13 @@ type ComicVineResponse struct {
 	Results              []ComicVineIssue `json:"results"`
 }
 
+// ComicVineIssueResponse for issue detail lookups
+type ComicVineIssueResponse struct {
+	Error      string         `json:"error"`
+	StatusCode int            `json:"status_code"`
+	Results    ComicVineIssue `json:"results"`
+}
+
 // ComicVineVolumeResponse for volume lookups
 type ComicVineVolumeResponse struct {
 	Error      string          `json:"error"`
@@ -70,10 +156,12 @@ type ComicVineVolumeResponse struct {
 
 // ComicVineVolume represents volume details
 type ComicVineVolume struct {
-	ID        int          `json:"id"`
-	Name      string       `json:"name"`
-	StartYear string       `json:"start_year"`
-	Publisher PublisherRef `json:"publisher"`
+	ID            int          `json:"id"`
+	Name          string       `json:"name"`
+	StartYear     string       `json:"start_year"`
+	CountOfIssues int          `json:"count_of_issues"`
+	SiteDetailURL string       `json:"site_detail_url"`
+	Publisher     PublisherRef `json:"publisher"`
 }
 
 // PublisherRef is a reference to a publisher
@@ -91,16 +179,39 @@ type MatchResult struct {
 	Reasoning        string          `json:"reasoning"`
 	ComicVineID      int             `json:"comicvine_id,omitempty"`
 	ComicVineURL     string          `json:"comicvine_url,omitempty"`
+	Provenance       string          `json:"provenance,omitempty"` // origin of imported matches; empty for local ones
 }
 
 // ProcessingResult is the final output for each file
 type ProcessingResult struct {
-	Filename         string       `json:"filename"`
-	Success          bool         `json:"success"`
-	Error            string       `json:"error,omitempty"`
-	Match            *MatchResult `json:"match,omitempty"`
-	ProcessedAt      time.Time    `json:"processed_at"`
-	ProcessingTimeMS int64        `json:"processing_time_ms"`
+	Filename         string        `json:"filename"`
+	Path             string        `json:"path,omitempty"`         // absolute path when the file exists locally
+	FileHash         string        `json:"file_hash,omitempty"`    // hex SHA-256 of the local file
+	FileSize         int64         `json:"file_size,omitempty"`    // size of the local file in bytes
+	SameFileAs       []string      `json:"same_file_as,omitempty"` // stored files with the same hash, such as an earlier download
+	Success          bool          `json:"success"`
+	Error            string        `json:"error,omitempty"`
+	ErrorCode        string        `json:"error_code,omitempty"` // the kind of failure, one of the ErrorCode* values
+	Match            *MatchResult  `json:"match,omitempty"`
+	ProcessedAt      time.Time     `json:"processed_at"`
+	ProcessingTimeMS int64         `json:"processing_time_ms"`
+	StageTimings     *StageTimings `json:"stage_timings,omitempty"`
+	SearchRetries    int           `json:"search_retries,omitempty"`  // ComicVine requests retried after transient failures
+	SourceFilename   string        `json:"source_filename,omitempty"` // the file collecting several issues this result is one of
+}
+
+// LaterIssue reports whether r is one of the issues after the first of a
+// file collecting several. The file itself is tagged, moved and pushed with
+// its first issue's result.
+func (r *ProcessingResult) LaterIssue() bool {
+	return r.SourceFilename != "" && r.Filename != r.SourceFilename
+}
+
+// StageTimings breaks down where a file's processing time went
+type StageTimings struct {
+	ParseMS  int64 `json:"parse_ms"`
+	SearchMS int64 `json:"search_ms"`
+	SelectMS int64 `json:"select_ms"`
 }
 
 // BatchProgress tracks progress of batch processing
@@ -110,4 +221,483 @@ type BatchProgress struct {
 	Successful int `json:"successful"`
 	Failed     int `json:"failed"`
 	Skipped    int `json:"skipped"`
+
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"` // when the snapshot was taken
+	Workers   int       `json:"workers"`
+
+	// Stages totals the time processed files spent in each stage
+	Stages StageTimings `json:"stage_ms"`
+	// AvgLatencyMS is a rolling average of the time a file takes, weighted
+	// toward the most recent files
+	AvgLatencyMS float64 `json:"avg_latency_ms"`
+}
+
+// latencyWeight is the weight of the newest file in the rolling average
+// latency.
+const latencyWeight = 0.2
+
+// Observe adds a processed file's latency and, when known, its stage
+// timings to the progress. It doesn't count the file.
+func (p *BatchProgress) Observe(latency time.Duration, stages *StageTimings) {
+	ms := float64(latency) / float64(time.Millisecond)
+	if p.AvgLatencyMS == 0 {
+		p.AvgLatencyMS = ms
+	} else {
+		p.AvgLatencyMS += latencyWeight * (ms - p.AvgLatencyMS)
+	}
+	if stages != nil {
+		p.Stages.ParseMS += stages.ParseMS
+		p.Stages.SearchMS += stages.SearchMS
+		p.Stages.SelectMS += stages.SelectMS
+	}
+}
+
+// Remaining returns the number of files not yet processed or skipped.
+func (p BatchProgress) Remaining() int {
+	return max(p.Total-p.Processed-p.Skipped, 0)
+}
+
+// ETA estimates the time left from UpdatedAt at the rolling average latency
+// with all workers busy. It is zero when nothing is left or no file has
+// finished yet.
+func (p BatchProgress) ETA() time.Duration {
+	if p.AvgLatencyMS == 0 {
+		return 0
+	}
+	workers := max(p.Workers, 1)
+	ms := p.AvgLatencyMS * float64(p.Remaining()) / float64(workers)
+	return time.Duration(ms) * time.Millisecond
+}
+
+// BatchRun summarizes a single batch invocation so its context outlives the process
+type BatchRun struct {
+	ID              int64     `json:"id"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at,omitempty"`
+	Mode            string    `json:"mode"` // parse, process
+	InputSource     string    `json:"input_source"`
+	ParserName      string    `json:"parser_name,omitempty"`
+	Total           int       `json:"total"`
+	Processed       int       `json:"processed"`
+	Successful      int       `json:"successful"`
+	Failed          int       `json:"failed"`
+	Skipped         int       `json:"skipped"`
+	Settings        string    `json:"settings,omitempty"` // JSON snapshot of the redacted config
+	LLMInputTokens  int       `json:"llm_input_tokens"`
+	LLMOutputTokens int       `json:"llm_output_tokens"`
+	LLMCost         float64   `json:"llm_cost"`
+}
+
+// Checkpoint states of a file within a batch run
+const (
+	CheckpointQueued     = "queued"
+	CheckpointInProgress = "in_progress"
+	CheckpointDone       = "done"
+	CheckpointFailed     = "failed"
+)
+
+// RunProgress is the live progress of an unfinished batch run, counted from
+// the checkpoints of its files
+type RunProgress struct {
+	Run        *BatchRun `json:"run"`
+	Queued     int       `json:"queued"`
+	InProgress int       `json:"in_progress"`
+	Done       int       `json:"done"`
+	Failed     int       `json:"failed"`
+
+	// Snapshot is the latest progress the running process saved, nil if
+	// it saved none
+	Snapshot *BatchProgress `json:"snapshot,omitempty"`
+}
+
+// RunResult is the outcome recorded for a single file within a batch run
+type RunResult struct {
+	Filename        string `json:"filename"`
+	Success         bool   `json:"success"`
+	Error           string `json:"error,omitempty"`
+	ErrorCode       string `json:"error_code,omitempty"`
+	Title           string `json:"title,omitempty"`
+	IssueNumber     string `json:"issue_number,omitempty"`
+	Year            string `json:"year,omitempty"`
+	MatchConfidence string `json:"match_confidence,omitempty"`
+	ComicVineID     int    `json:"comicvine_id,omitempty"`
+}
+
+// Matched reports whether the file counts as matched: a ComicVine selection
+// for full processing, or a successful parse for parse-only runs.
+func (r RunResult) Matched() bool {
+	if !r.Success {
+		return false
+	}
+	if r.MatchConfidence != "" {
+		return r.ComicVineID != 0
+	}
+	return true
+}
+
+// RunResultChange pairs a file's outcome in two runs
+type RunResultChange struct {
+	Filename string     `json:"filename"`
+	Before   *RunResult `json:"before,omitempty"`
+	After    *RunResult `json:"after,omitempty"`
+}
+
+// RunDiff summarizes how results changed between two batch runs
+type RunDiff struct {
+	NewlyMatched []RunResultChange `json:"newly_matched"`
+	NewlyFailed  []RunResultChange `json:"newly_failed"`
+	Changed      []RunResultChange `json:"changed"`
+	OnlyBefore   []string          `json:"only_before"`
+	OnlyAfter    []string          `json:"only_after"`
+	Unchanged    int               `json:"unchanged"`
+}
+
+// Mapping kinds
+const (
+	MappingKindFilename = "filename" // exact base filename
+	MappingKindPattern  = "pattern"  // glob matched against the base filename
+	MappingKindSHA256   = "sha256"   // hex SHA-256 of the file contents
+)
+
+// Mapping ties a well-known release to a ComicVine issue, bypassing matching
+type Mapping struct {
+	Kind       string         `json:"kind"`
+	Key        string         `json:"key"`
+	Issue      ComicVineIssue `json:"issue"`
+	Source     string         `json:"source"`
+	ImportedAt time.Time      `json:"imported_at"`
+}
+
+// SeriesAlias maps a series name as filenames spell it to the series
+// searched for instead, and optionally the ComicVine volume its issues are
+// looked up in.
+type SeriesAlias struct {
+	Name      string    `json:"name"`
+	Series    string    `json:"series"`
+	VolumeID  int       `json:"volume_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SeriesAliasKey returns the key series aliases are looked up by: name in
+// lowercase letters and digits, so "TMNT", "T.M.N.T." and "tmnt" share one.
+func SeriesAliasKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Review states of a match in the review queue
+const (
+	ReviewPending  = "pending"
+	ReviewAccepted = "accepted"
+	ReviewRejected = "rejected"
+)
+
+// ReviewItem is a medium or low confidence match waiting for a person to
+// confirm it, pick another candidate or reject it
+type ReviewItem struct {
+	ID         int64             `json:"id"`
+	RunID      int64             `json:"run_id,omitempty"` // run that queued the match
+	Filename   string            `json:"filename"`
+	Result     *ProcessingResult `json:"result"`
+	Candidates []ComicVineIssue  `json:"candidates"` // the search results the match was selected from
+	Status     string            `json:"status"`
+	QueuedAt   time.Time         `json:"queued_at"`
+	ReviewedAt time.Time         `json:"reviewed_at,omitempty"`
+}
+
+// AcceptCandidate returns match with issue confirmed as the selection by
+// the reviewer.
+func AcceptCandidate(match *MatchResult, issue ComicVineIssue) *MatchResult {
+	accepted := MatchResult{}
+	if match != nil {
+		accepted = *match
+	}
+	if accepted.SelectedIssue != nil && accepted.SelectedIssue.ID == issue.ID {
+		accepted.Reasoning = strings.TrimSpace(accepted.Reasoning + " (confirmed in review)")
+	} else {
+		accepted.Reasoning = "Selected in review"
+	}
+	accepted.SelectedIssue = &issue
+	accepted.ComicVineID = issue.ID
+	accepted.ComicVineURL = issue.SiteDetailURL
+	accepted.MatchConfidence = "high"
+	return &accepted
+}
+
+// RejectMatch returns match with its selection cleared by the reviewer.
+func RejectMatch(match *MatchResult) *MatchResult {
+	rejected := MatchResult{}
+	if match != nil {
+		rejected = *match
+	}
+	rejected.SelectedIssue = nil
+	rejected.ComicVineID = 0
+	rejected.ComicVineURL = ""
+	rejected.MatchConfidence = "none"
+	rejected.Reasoning = "Rejected in review"
+	return &rejected
+}
+
+// Where a match correction was made
+const (
+	CorrectionReview      = "review"
+	CorrectionInteractive = "interactive"
+	CorrectionAssigned    = "assigned" // by ComicVine ID with `comic-parser match`
+)
+
+// MatchCorrection is a match a user chose by hand, kept as an example of
+// how their library's files are named
+type MatchCorrection struct {
+	Filename            string    `json:"filename"`
+	Title               string    `json:"title"` // parsed title, issue number and year of the file
+	IssueNumber         string    `json:"issue_number"`
+	Year                string    `json:"year"`
+	OriginalComicVineID int       `json:"original_comicvine_id,omitempty"` // the automatic match, if any
+	ComicVineID         int       `json:"comicvine_id,omitempty"`          // 0 when the user rejected the match
+	VolumeName          string    `json:"volume_name,omitempty"`
+	VolumeStartYear     string    `json:"volume_start_year,omitempty"`
+	CorrectedIssue      string    `json:"corrected_issue_number,omitempty"`
+	Source              string    `json:"source"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// NewMatchCorrection records match as chosen by hand for the file it was
+// made for, in place of the automatic match original (nil if none).
+func NewMatchCorrection(match, original *MatchResult, source string) MatchCorrection {
+	c := MatchCorrection{
+		Filename:    match.OriginalFilename,
+		Title:       match.ParsedInfo.Title,
+		IssueNumber: match.ParsedInfo.IssueNumber,
+		Year:        match.ParsedInfo.Year,
+		Source:      source,
+	}
+	if original != nil {
+		c.OriginalComicVineID = original.ComicVineID
+	}
+	if issue := match.SelectedIssue; issue != nil {
+		c.ComicVineID = issue.ID
+		c.VolumeName = issue.Volume.Name
+		c.VolumeStartYear = issue.Volume.StartYear
+		c.CorrectedIssue = issue.IssueNumber
+	}
+	return c
+}
+
+// Rejected reports whether the user rejected every candidate.
+func (c MatchCorrection) Rejected() bool {
+	return c.ComicVineID == 0
+}
+
+// SearchHit is a stored comic matching a text search
+type SearchHit struct {
+	Filename string `json:"filename"`
+	Series   string `json:"series,omitempty"` // matched volume and parsed title
+	Title    string `json:"title,omitempty"`  // matched issue name
+	Snippet  string `json:"snippet,omitempty"`
+}
+
+// LLMUsage records the tokens and estimated cost of one LLM call
+type LLMUsage struct {
+	Filename     string    `json:"filename,omitempty"`
+	Kind         string    `json:"kind"` // schema name, or "text" for plain completions
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	Cost         float64   `json:"cost"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UsageSummary aggregates LLM usage by batch run or by model and call kind.
+// RunID is 0 for calls made outside a run.
+type UsageSummary struct {
+	RunID        int64   `json:"run_id,omitempty"`
+	Mode         string  `json:"mode,omitempty"`
+	InputSource  string  `json:"input_source,omitempty"`
+	Provider     string  `json:"provider,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	Kind         string  `json:"kind,omitempty"`
+	Calls        int     `json:"calls"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// LibraryStats summarizes the stored processing results.
+type LibraryStats struct {
+	Total       int     `json:"total"`
+	Matched     int     `json:"matched"`
+	Unmatched   int     `json:"unmatched"` // processed without error but not matched, e.g. parse-only
+	Failed      int     `json:"failed"`
+	Ignored     int     `json:"ignored"`      // failed, but the file is on the ignore list
+	FailureRate float64 `json:"failure_rate"` // Failed / Total
+
+	Confidence []StatCount `json:"confidence"` // matches by confidence, best first
+	Publishers []StatCount `json:"publishers"` // matches by publisher, most first
+	Years      []StatCount `json:"years"`      // matches by cover year, oldest first
+	Added      []MonthStat `json:"added"`      // results by month first stored, oldest first
+}
+
+// StatCount is one bar of a LibraryStats distribution.
+type StatCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// MonthStat counts the results first stored in a month ("2006-01") and how
+// many of them failed.
+type MonthStat struct {
+	Month       string  `json:"month"`
+	Added       int     `json:"added"`
+	Failed      int     `json:"failed"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// FileMove records a file renamed by organize so the move can be undone
+type FileMove struct {
+	ID          int64     `json:"id"`
+	OperationID int64     `json:"operation_id"`
+	Filename    string    `json:"filename"` // stored record name before the move
+	OldPath     string    `json:"old_path"`
+	NewPath     string    `json:"new_path"`
+	MovedAt     time.Time `json:"moved_at"`
+}
+
+// Health of an ArchiveCheck
+const (
+	ArchiveHealthy = "ok"
+	ArchiveDamaged = "damaged"
+)
+
+// ArchiveCheck records the pages and health of a comic archive on disk, as
+// found by reading every entry.
+type ArchiveCheck struct {
+	Path      string    `json:"path"`
+	PageCount int       `json:"page_count"` // image entries
+	Health    string    `json:"health"`     // ArchiveHealthy or ArchiveDamaged
+	Problems  []string  `json:"problems,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Succeeded reports whether the file was processed without error.
+func (r *ProcessingResult) Succeeded() bool {
+	return r.Success
+}
+
+// FailureReason returns the error that stopped the file, if any.
+func (r *ProcessingResult) FailureReason() string {
+	return r.Error
+}
+
+// ParsedEpisode is what a TV episode filename says about the episode. A
+// file is numbered by season and episode, by air date, or (for anime) by
+// absolute episode number.
+type ParsedEpisode struct {
+	OriginalFilename string `json:"original_filename"`
+	Series           string `json:"series"`
+	Year             int    `json:"year,omitempty"` // series year, when the name carries one
+	Season           int    `json:"season,omitempty"`
+	Episode          int    `json:"episode,omitempty"`
+	AbsoluteNumber   int    `json:"absolute_number,omitempty"`
+	AirDate          string `json:"air_date,omitempty"` // YYYY-MM-DD
+}
+
+// TVEpisode is an episode from TheTVDB
+type TVEpisode struct {
+	ID             int    `json:"id"`
+	SeriesID       int    `json:"series_id"`
+	SeriesName     string `json:"series_name"`
+	SeriesYear     string `json:"series_year,omitempty"`
+	Season         int    `json:"season"`
+	Number         int    `json:"number"`
+	AbsoluteNumber int    `json:"absolute_number,omitempty"`
+	Name           string `json:"name"`
+	Aired          string `json:"aired,omitempty"`
+}
+
+// EpisodeResult is the outcome of matching one TV episode file
+type EpisodeResult struct {
+	Filename    string         `json:"filename"`
+	Path        string         `json:"path,omitempty"`
+	Parsed      *ParsedEpisode `json:"parsed,omitempty"`
+	Episode     *TVEpisode     `json:"episode,omitempty"`
+	Confidence  string         `json:"confidence,omitempty"` // high, medium
+	Success     bool           `json:"success"`
+	Error       string         `json:"error,omitempty"`
+	ErrorCode   string         `json:"error_code,omitempty"`
+	ProcessedAt time.Time      `json:"processed_at"`
+}
+
+// Succeeded reports whether the episode was matched.
+func (r *EpisodeResult) Succeeded() bool {
+	return r.Success
+}
+
+// FailureReason returns why the episode could not be matched, if it wasn't.
+func (r *EpisodeResult) FailureReason() string {
+	return r.Error
+}
+
+// Collected edition formats
+const (
+	FormatTPB     = "tpb"     // trade paperback
+	FormatHC      = "hc"      // hardcover
+	FormatOmnibus = "omnibus" // oversized omnibus
+)
+
+// ParsedCollection is what a collected edition filename says about the
+// edition, such as "Saga Vol. 01 (2012) (TPB).cbz".
+type ParsedCollection struct {
+	OriginalFilename string `json:"original_filename"`
+	Title            string `json:"title"`
+	Format           string `json:"format"`                  // see Format* constants
+	VolumeNumber     string `json:"volume_number,omitempty"` // "Vol. 3" of a numbered run of collections
+	Year             string `json:"year,omitempty"`
+}
+
+// CollectionResult is the outcome of matching one collected edition file.
+// Its match is a ComicVine volume rather than an issue.
+type CollectionResult struct {
+	Filename    string            `json:"filename"`
+	Path        string            `json:"path,omitempty"`
+	Parsed      *ParsedCollection `json:"parsed,omitempty"`
+	Volume      *ComicVineVolume  `json:"volume,omitempty"`
+	Confidence  string            `json:"confidence,omitempty"` // high, medium, low
+	Success     bool              `json:"success"`
+	Error       string            `json:"error,omitempty"`
+	ErrorCode   string            `json:"error_code,omitempty"`
+	ProcessedAt time.Time         `json:"processed_at"`
+}
+
+// Succeeded reports whether the collection was matched.
+func (r *CollectionResult) Succeeded() bool {
+	return r.Success
+}
+
+// FailureReason returns why the collection could not be matched, if it
+// wasn't.
+func (r *CollectionResult) FailureReason() string {
+	return r.Error
+}
+
+// ReadingList is a named, ordered list of ComicVine issues, such as a story
+// arc.
+type ReadingList struct {
+	ID         int64              `json:"id"`
+	Name       string             `json:"name"`
+	CreatedAt  time.Time          `json:"created_at"`
+	EntryCount int                `json:"entry_count"`
+	Entries    []ReadingListEntry `json:"entries,omitempty"`
+}
+
+// ReadingListEntry is an issue of a reading list at its 1-based position.
+type ReadingListEntry struct {
+	Position int            `json:"position"`
+	Issue    ComicVineIssue `json:"issue"`
 }
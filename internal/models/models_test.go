@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBatchProgressETA(t *testing.T) {
+	p := BatchProgress{Total: 10, Workers: 2}
+	if eta := p.ETA(); eta != 0 {
+		t.Errorf("Expected no ETA before a file finishes, got %s", eta)
+	}
+
+	p.Processed = 2
+	p.Observe(2*time.Second, &StageTimings{ParseMS: 100, SearchMS: 1400, SelectMS: 500})
+	p.Observe(4*time.Second, nil)
+	if p.AvgLatencyMS != 2400 {
+		t.Errorf("Expected a rolling average of 2400ms, got %v", p.AvgLatencyMS)
+	}
+	if p.Stages != (StageTimings{ParseMS: 100, SearchMS: 1400, SelectMS: 500}) {
+		t.Errorf("Expected the stage timings of the first file only, got %+v", p.Stages)
+	}
+
+	// 8 files left at 2.4s each over 2 workers
+	if eta := p.ETA(); eta != 9600*time.Millisecond {
+		t.Errorf("Expected an ETA of 9.6s, got %s", eta)
+	}
+
+	p.Processed, p.Skipped = 8, 2
+	if p.Remaining() != 0 || p.ETA() != 0 {
+		t.Errorf("Expected nothing left, got %d files and %s", p.Remaining(), p.ETA())
+	}
+}
@@ -0,0 +1,177 @@
+// Package notify posts a summary of finished batch runs to a webhook, so
+// long runs can report to Discord, Slack or any service accepting JSON.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"comic-parser/internal/config"
+)
+
+const (
+	userAgentValue  = "ComicParser/1.0"
+	headerUserAgent = "User-Agent"
+	contentTypeJSON = "application/json"
+
+	// MaxFailures caps the failed files listed in a summary
+	MaxFailures = 10
+
+	// maxMessageLength keeps chat messages under Discord's 2000 character
+	// limit, the lower of the two services
+	maxMessageLength = 1900
+)
+
+// Run statuses
+const (
+	StatusCompleted   = "completed"
+	StatusInterrupted = "interrupted"
+	StatusFailed      = "failed"
+)
+
+// HTTPClient defines the interface for making HTTP requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Failure is a file that failed during the run.
+type Failure struct {
+	Filename string `json:"filename"`
+	Error    string `json:"error"`
+	Code     string `json:"code,omitempty"` // parse_failed, no_match, rate_limited, ...
+}
+
+// Summary describes a finished batch run. It is the body posted to generic
+// webhooks.
+type Summary struct {
+	Status         string    `json:"status"` // completed, interrupted or failed
+	Error          string    `json:"error,omitempty"`
+	RunID          int64     `json:"run_id,omitempty"`
+	Mode           string    `json:"mode"` // parse, process
+	Source         string    `json:"source"`
+	Results        string    `json:"results,omitempty"` // output file or database
+	Total          int       `json:"total"`
+	Processed      int       `json:"processed"`
+	Successful     int       `json:"successful"`
+	Failed         int       `json:"failed"`
+	Skipped        int       `json:"skipped"`
+	Failures       []Failure `json:"failures,omitempty"` // at most MaxFailures
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	LLMCost        float64   `json:"llm_cost"`
+	FinishedAt     time.Time `json:"finished_at"`
+}
+
+// Notifier posts run summaries to a webhook.
+type Notifier struct {
+	url        string
+	httpClient HTTPClient
+}
+
+// New creates a Notifier for cfg.NotifyURL, or returns nil when no webhook
+// is configured.
+func New(cfg *config.Config, httpClient HTTPClient) *Notifier {
+	if cfg.NotifyURL == "" {
+		return nil
+	}
+	return &Notifier{url: cfg.NotifyURL, httpClient: httpClient}
+}
+
+// Send posts s to the webhook. Discord and Slack webhooks get a chat message;
+// any other URL gets s as JSON.
+func (n *Notifier) Send(ctx context.Context, s *Summary) error {
+	var payload any = s
+	switch service(n.url) {
+	case "discord":
+		payload = map[string]string{"content": message(s)}
+	case "slack":
+		payload = map[string]string{"text": message(s)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("notify: encoding summary: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notify: creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentTypeJSON)
+	req.Header.Set(headerUserAgent, userAgentValue)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		// The URL holds the webhook's token, so keep it out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("notify: sending summary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notify: webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// service identifies the chat service of a webhook URL, or returns "" for
+// generic webhooks.
+func service(webhook string) string {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case (host == "discord.com" || host == "discordapp.com" || strings.HasSuffix(host, ".discord.com")) &&
+		strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return "discord"
+	case host == "hooks.slack.com":
+		return "slack"
+	}
+	return ""
+}
+
+// message formats s as a short chat message.
+func message(s *Summary) string {
+	var b strings.Builder
+	title := "Batch run"
+	if s.RunID != 0 {
+		title = fmt.Sprintf("Batch run %d", s.RunID)
+	}
+	fmt.Fprintf(&b, "%s (%s) %s: %s\n", title, s.Mode, s.Status, s.Source)
+	if s.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", s.Error)
+	}
+	fmt.Fprintf(&b, "Processed %d of %d: %d succeeded, %d failed", s.Processed, s.Total, s.Successful, s.Failed)
+	if s.Skipped > 0 {
+		fmt.Fprintf(&b, ", %d skipped", s.Skipped)
+	}
+	fmt.Fprintf(&b, " in %s", time.Duration(s.ElapsedSeconds*float64(time.Second)).Round(time.Second))
+	if s.LLMCost > 0 {
+		fmt.Fprintf(&b, " (~$%.2f LLM)", s.LLMCost)
+	}
+	b.WriteString("\n")
+	if s.Results != "" {
+		fmt.Fprintf(&b, "Results: %s\n", s.Results)
+	}
+	for _, f := range s.Failures {
+		line := fmt.Sprintf("✗ %s: %s\n", f.Filename, f.Error)
+		if b.Len()+len(line) > maxMessageLength {
+			b.WriteString("…\n")
+			break
+		}
+		b.WriteString(line)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"comic-parser/internal/config"
+)
+
+func testSummary() *Summary {
+	return &Summary{
+		Status:         StatusCompleted,
+		RunID:          12,
+		Mode:           "parse",
+		Source:         "filenames.txt",
+		Results:        "comics.db",
+		Total:          3,
+		Processed:      3,
+		Successful:     2,
+		Failed:         1,
+		Failures:       []Failure{{Filename: "Saga 054.cbz", Error: "no match found"}},
+		ElapsedSeconds: 90,
+	}
+}
+
+func TestSend(t *testing.T) {
+	var got Summary
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != contentTypeJSON {
+			t.Errorf("Unexpected request %s with content type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Decoding body failed: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	n := New(&config.Config{NotifyURL: ts.URL + "/hook"}, ts.Client())
+	if err := n.Send(context.Background(), testSummary()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got.Status != StatusCompleted || got.RunID != 12 || got.Failed != 1 || len(got.Failures) != 1 || got.Results != "comics.db" {
+		t.Errorf("Unexpected summary: %+v", got)
+	}
+}
+
+func TestSend_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid webhook token", http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	n := New(&config.Config{NotifyURL: ts.URL}, ts.Client())
+	err := n.Send(context.Background(), testSummary())
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid webhook token") {
+		t.Errorf("Expected a 401 error, got %v", err)
+	}
+
+	if New(&config.Config{}, ts.Client()) != nil {
+		t.Error("Expected no notifier without notify_url")
+	}
+}
+
+func TestService(t *testing.T) {
+	tests := map[string]string{
+		"https://discord.com/api/webhooks/123/abc":    "discord",
+		"https://discordapp.com/api/webhooks/123/abc": "discord",
+		"https://ptb.discord.com/api/webhooks/1/a":    "discord",
+		"https://discord.com/channels/123":            "",
+		"https://hooks.slack.com/services/T0/B0/XYZ":  "slack",
+		"https://example.com/hooks/comics":            "",
+	}
+	for webhook, want := range tests {
+		if got := service(webhook); got != want {
+			t.Errorf("service(%q) = %q, want %q", webhook, got, want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	msg := message(testSummary())
+	for _, want := range []string{"Batch run 12 (parse) completed: filenames.txt", "Processed 3 of 3: 2 succeeded, 1 failed in 1m30s", "Results: comics.db", "Saga 054.cbz: no match found"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected %q in message:\n%s", want, msg)
+		}
+	}
+
+	s := testSummary()
+	for i := range MaxFailures {
+		s.Failures = append(s.Failures, Failure{Filename: fmt.Sprintf("Saga %03d.cbz", i), Error: strings.Repeat("x", 300)})
+	}
+	if msg := message(s); len(msg) > maxMessageLength || !strings.HasSuffix(msg, "…") {
+		t.Errorf("Expected a truncated message under %d bytes, got %d bytes", maxMessageLength, len(msg))
+	}
+}
@@ -0,0 +1,227 @@
+// Package parquet writes flat tables as Apache Parquet files that DuckDB,
+// pandas and other analysis tools read. It covers only what exports need:
+// optional INT64, DOUBLE, BOOLEAN and UTF-8 string columns, written
+// uncompressed with PLAIN encoding as a single row group.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Type is the type of a column's values.
+type Type int
+
+const (
+	Int64 Type = iota
+	Double
+	Boolean
+	String
+)
+
+// Column describes a table column. Every column is optional, so any value
+// may be nil.
+type Column struct {
+	Name string
+	Type Type
+}
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// Parquet format enum values
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+
+	convertedUTF8      = 0
+	repetitionOptional = 1
+	encodingPlain      = 0
+	encodingRLE        = 3
+	pageTypeData       = 0
+	codecUncompressed  = 0
+)
+
+// Write writes rows as a Parquet file. Each row holds one value per column:
+// nil, or an int64, float64, bool or string matching the column's type.
+func Write(w io.Writer, columns []Column, rows [][]any) error {
+	var file bytes.Buffer
+	file.WriteString(magic)
+
+	var chunks []func(*thriftWriter)
+	for i, col := range columns {
+		page, err := encodePage(col, i, rows)
+		if err != nil {
+			return err
+		}
+		header := newThriftWriter()
+		header.i32Field(1, pageTypeData)
+		header.i32Field(2, int32(len(page)))
+		header.i32Field(3, int32(len(page)))
+		header.structField(5, func(t *thriftWriter) {
+			t.i32Field(1, int32(len(rows)))
+			t.i32Field(2, encodingPlain)
+			t.i32Field(3, encodingRLE)
+			t.i32Field(4, encodingRLE)
+		})
+		header.stop()
+
+		offset := int64(file.Len())
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+		size := int64(file.Len()) - offset
+
+		name, physical := col.Name, physicalType(col.Type)
+		chunks = append(chunks, func(t *thriftWriter) {
+			t.i64Field(2, offset)
+			t.structField(3, func(t *thriftWriter) {
+				t.i32Field(1, physical)
+				t.i32ListField(2, []int32{encodingPlain, encodingRLE})
+				t.stringListField(3, []string{name})
+				t.i32Field(4, codecUncompressed)
+				t.i64Field(5, int64(len(rows)))
+				t.i64Field(6, size)
+				t.i64Field(7, size)
+				t.i64Field(9, offset)
+			})
+		})
+	}
+	dataSize := int64(file.Len() - len(magic))
+
+	meta := newThriftWriter()
+	meta.i32Field(1, 1)
+	schema := []func(*thriftWriter){func(t *thriftWriter) {
+		t.stringField(4, "schema")
+		t.i32Field(5, int32(len(columns)))
+	}}
+	for _, col := range columns {
+		schema = append(schema, func(t *thriftWriter) {
+			t.i32Field(1, physicalType(col.Type))
+			t.i32Field(3, repetitionOptional)
+			t.stringField(4, col.Name)
+			if col.Type == String {
+				t.i32Field(6, convertedUTF8)
+			}
+		})
+	}
+	meta.structListField(2, schema)
+	meta.i64Field(3, int64(len(rows)))
+	var rowGroups []func(*thriftWriter)
+	if len(rows) > 0 {
+		rowGroups = append(rowGroups, func(t *thriftWriter) {
+			t.structListField(1, chunks)
+			t.i64Field(2, dataSize)
+			t.i64Field(3, int64(len(rows)))
+		})
+	}
+	meta.structListField(4, rowGroups)
+	meta.stringField(6, "comic-parser")
+	meta.stop()
+
+	file.Write(meta.buf.Bytes())
+	binary.Write(&file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.WriteString(magic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+func physicalType(t Type) int32 {
+	switch t {
+	case Int64:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	case Boolean:
+		return physicalBoolean
+	}
+	return physicalByteArray
+}
+
+// encodePage encodes column i of rows as the body of a version 1 data page:
+// the definition levels, saying which rows have a value, then the values.
+func encodePage(col Column, i int, rows [][]any) ([]byte, error) {
+	levels := make([]bool, len(rows))
+	var values bytes.Buffer
+	var bits []bool
+	for r, row := range rows {
+		v := row[i]
+		if v == nil {
+			continue
+		}
+		levels[r] = true
+		var ok bool
+		switch col.Type {
+		case Int64:
+			var n int64
+			if n, ok = v.(int64); ok {
+				binary.Write(&values, binary.LittleEndian, n)
+			}
+		case Double:
+			var f float64
+			if f, ok = v.(float64); ok {
+				binary.Write(&values, binary.LittleEndian, math.Float64bits(f))
+			}
+		case Boolean:
+			var b bool
+			if b, ok = v.(bool); ok {
+				bits = append(bits, b)
+			}
+		case String:
+			var s string
+			if s, ok = v.(string); ok {
+				binary.Write(&values, binary.LittleEndian, uint32(len(s)))
+				values.WriteString(s)
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("parquet: column %s: row %d: unexpected %T", col.Name, r, v)
+		}
+	}
+	if col.Type == Boolean {
+		values.Write(packBits(bits))
+	}
+
+	encoded := encodeLevels(levels)
+	var page bytes.Buffer
+	binary.Write(&page, binary.LittleEndian, uint32(len(encoded)))
+	page.Write(encoded)
+	page.Write(values.Bytes())
+	return page.Bytes(), nil
+}
+
+// encodeLevels encodes definition levels of bit width 1 with the RLE half
+// of Parquet's RLE/bit-packing hybrid: a run length and a value per run.
+func encodeLevels(levels []bool) []byte {
+	var buf []byte
+	for start := 0; start < len(levels); {
+		end := start
+		for end < len(levels) && levels[end] == levels[start] {
+			end++
+		}
+		buf = binary.AppendUvarint(buf, uint64(end-start)<<1)
+		if levels[start] {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		start = end
+	}
+	return buf
+}
+
+// packBits packs booleans one bit each, least significant bit first.
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, b := range bits {
+		if b {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
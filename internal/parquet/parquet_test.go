@@ -0,0 +1,207 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes the compact protocol structs Write produces: structs
+// become maps by field ID, integers int64, binaries []byte and lists []any.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	n, size := binary.Uvarint(r.data[r.pos:])
+	r.pos += size
+	return n
+}
+
+func (r *thriftReader) varint() int64 {
+	n := r.uvarint()
+	return int64(n>>1) ^ -int64(n&1)
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case compactI32, compactI64:
+		return r.varint()
+	case compactBinary:
+		n := int(r.uvarint())
+		b := r.data[r.pos : r.pos+n]
+		r.pos += n
+		return b
+	case compactList:
+		header := r.byte()
+		size, elem := int(header>>4), header&0x0f
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case compactStruct:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", typ))
+}
+
+func (r *thriftReader) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var id int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(header & 0x0f)
+	}
+}
+
+// readFile decodes a file written by Write into its column names and rows.
+func readFile(t *testing.T, data []byte) ([]string, [][]any) {
+	t.Helper()
+	if string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		t.Fatalf("missing magic bytes")
+	}
+	metaLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&thriftReader{data: data[len(data)-8-metaLen : len(data)-8]}).structure()
+
+	schema := meta[2].([]any)
+	root := schema[0].(map[int16]any)
+	if string(root[4].([]byte)) != "schema" || int(root[5].(int64)) != len(schema)-1 {
+		t.Fatalf("unexpected schema root %v", root)
+	}
+	var names []string
+	var types []int64
+	for _, el := range schema[1:] {
+		field := el.(map[int16]any)
+		if field[3].(int64) != repetitionOptional {
+			t.Errorf("expected optional column, got %v", field)
+		}
+		names = append(names, string(field[4].([]byte)))
+		types = append(types, field[1].(int64))
+	}
+
+	numRows := int(meta[3].(int64))
+	rows := make([][]any, numRows)
+	for i := range rows {
+		rows[i] = make([]any, len(names))
+	}
+	groups := meta[4].([]any)
+	if numRows == 0 {
+		if len(groups) != 0 {
+			t.Errorf("expected no row groups, got %v", groups)
+		}
+		return names, rows
+	}
+	chunks := groups[0].(map[int16]any)[1].([]any)
+	for c, chunk := range chunks {
+		cmeta := chunk.(map[int16]any)[3].(map[int16]any)
+		if cmeta[1].(int64) != types[c] || string(cmeta[3].([]any)[0].([]byte)) != names[c] {
+			t.Errorf("column chunk %d doesn't match the schema: %v", c, cmeta)
+		}
+		r := &thriftReader{data: data, pos: int(cmeta[9].(int64))}
+		header := r.structure()
+		if header[1].(int64) != pageTypeData || int(header[5].(map[int16]any)[1].(int64)) != numRows {
+			t.Fatalf("unexpected page header %v", header)
+		}
+		if int64(r.pos)+header[3].(int64)-cmeta[9].(int64) != cmeta[7].(int64) {
+			t.Errorf("column chunk %d size %d doesn't match its page", c, cmeta[7])
+		}
+
+		levelsLen := int(binary.LittleEndian.Uint32(data[r.pos:]))
+		levels := &thriftReader{data: data[r.pos+4 : r.pos+4+levelsLen]}
+		var defined []bool
+		for levels.pos < len(levels.data) {
+			run := int(levels.uvarint() >> 1)
+			v := levels.byte() == 1
+			for range run {
+				defined = append(defined, v)
+			}
+		}
+		pos := r.pos + 4 + levelsLen
+		bit := 0
+		for i := range numRows {
+			if !defined[i] {
+				continue
+			}
+			switch types[c] {
+			case physicalInt64:
+				rows[i][c] = int64(binary.LittleEndian.Uint64(data[pos:]))
+				pos += 8
+			case physicalDouble:
+				rows[i][c] = math.Float64frombits(binary.LittleEndian.Uint64(data[pos:]))
+				pos += 8
+			case physicalBoolean:
+				rows[i][c] = data[pos+bit/8]&(1<<(bit%8)) != 0
+				bit++
+			case physicalByteArray:
+				n := int(binary.LittleEndian.Uint32(data[pos:]))
+				rows[i][c] = string(data[pos+4 : pos+4+n])
+				pos += 4 + n
+			}
+		}
+	}
+	return names, rows
+}
+
+func TestWrite(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Type: Int64},
+		{Name: "filename", Type: String},
+		{Name: "success", Type: Boolean},
+		{Name: "cost", Type: Double},
+	}
+	rows := [][]any{
+		{int64(1), "Saga 001.cbz", true, 0.25},
+		{int64(2), nil, false, nil},
+		{int64(-3), "Bone – 01.cbz", nil, 1.5},
+	}
+	// Enough rows for lists and runs past the compact forms' short limits
+	for i := range 20 {
+		rows = append(rows, []any{int64(100 + i), fmt.Sprint("file ", i), i%3 == 0, nil})
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, columns, rows); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	names, got := readFile(t, buf.Bytes())
+	if !reflect.DeepEqual(names, []string{"id", "filename", "success", "cost"}) {
+		t.Errorf("unexpected columns %v", names)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("rows = %v\nwant %v", got, rows)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, columns, nil); err != nil {
+		t.Fatalf("Write without rows: %v", err)
+	}
+	if _, got := readFile(t, buf.Bytes()); len(got) != 0 {
+		t.Errorf("expected no rows, got %v", got)
+	}
+
+	if err := Write(&buf, columns, [][]any{{"1", nil, nil, nil}}); err == nil {
+		t.Error("expected an error for a value of the wrong type")
+	}
+}
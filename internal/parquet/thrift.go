@@ -0,0 +1,118 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol type codes
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// thriftWriter encodes a struct with Thrift's compact protocol, which
+// Parquet uses for page headers and file metadata. Fields must be written in
+// increasing ID order.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	lastIDs []int16 // the last field ID of each open struct
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastIDs: []int16{0}}
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &t.lastIDs[len(t.lastIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	*last = id
+}
+
+// varint writes a zigzag-encoded integer.
+func (t *thriftWriter) varint(n int64) {
+	t.uvarint(uint64(n<<1) ^ uint64(n>>63))
+}
+
+func (t *thriftWriter) uvarint(n uint64) {
+	t.buf.Write(binary.AppendUvarint(nil, n))
+}
+
+func (t *thriftWriter) listHeader(size int, typ byte) {
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | typ)
+		return
+	}
+	t.buf.WriteByte(0xf0 | typ)
+	t.uvarint(uint64(size))
+}
+
+func (t *thriftWriter) str(s string) {
+	t.uvarint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+// structBody writes a nested struct's fields and its stop byte.
+func (t *thriftWriter) structBody(fields func(*thriftWriter)) {
+	t.lastIDs = append(t.lastIDs, 0)
+	fields(t)
+	t.stop()
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+func (t *thriftWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, compactI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, compactI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) stringField(id int16, s string) {
+	t.fieldHeader(id, compactBinary)
+	t.str(s)
+}
+
+func (t *thriftWriter) structField(id int16, fields func(*thriftWriter)) {
+	t.fieldHeader(id, compactStruct)
+	t.structBody(fields)
+}
+
+func (t *thriftWriter) i32ListField(id int16, values []int32) {
+	t.fieldHeader(id, compactList)
+	t.listHeader(len(values), compactI32)
+	for _, v := range values {
+		t.varint(int64(v))
+	}
+}
+
+func (t *thriftWriter) stringListField(id int16, values []string) {
+	t.fieldHeader(id, compactList)
+	t.listHeader(len(values), compactBinary)
+	for _, v := range values {
+		t.str(v)
+	}
+}
+
+func (t *thriftWriter) structListField(id int16, elems []func(*thriftWriter)) {
+	t.fieldHeader(id, compactList)
+	t.listHeader(len(elems), compactStruct)
+	for _, fields := range elems {
+		t.structBody(fields)
+	}
+}
+
+// stop ends the current struct.
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
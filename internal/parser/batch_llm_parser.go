@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"comic-parser/internal/llm"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
+	"comic-parser/internal/prompts"
+)
+
+// batchWait is how long the first filename of a batch waits for others
+// before the batch is sent anyway.
+const batchWait = 250 * time.Millisecond
+
+// BatchLLMParser implements the Parser interface like LLMParser, but sends
+// the filenames of concurrent Parse calls to the LLM together, up to size
+// per request. A batch fills with the files being parsed at the same time,
+// so the number of workers caps its size. Filenames a response leaves out,
+// or all of them when the response is malformed, are parsed one at a time.
+type BatchLLMParser struct {
+	single *LLMParser
+	size   int
+	wait   time.Duration
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending []*batchRequest
+	batchID int // identifies the pending batch to its timer
+}
+
+// batchRequest is a Parse call waiting for its batch.
+type batchRequest struct {
+	ctx   context.Context
+	input *models.ParsedFilename
+	done  chan batchReply
+}
+
+// batchReply answers a batchRequest. A nil parse without an error leaves the
+// file to be parsed on its own.
+type batchReply struct {
+	parsed *models.ParsedFilename
+	err    error
+}
+
+// NewBatchLLMParser creates a BatchLLMParser sending up to size filenames
+// per request.
+func NewBatchLLMParser(client LLMClient, size int, retryAttempts int, retryDelaySeconds int) *BatchLLMParser {
+	return &BatchLLMParser{
+		single: NewLLMParser(client, retryAttempts, retryDelaySeconds),
+		size:   max(size, 1),
+		wait:   batchWait,
+		logger: logging.Logger(logging.Parser),
+	}
+}
+
+// Parse implements the Parser interface.
+func (p *BatchLLMParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	req := &batchRequest{ctx: ctx, input: input, done: make(chan batchReply, 1)}
+	p.enqueue(req)
+
+	select {
+	case reply := <-req.done:
+		if reply.err != nil {
+			return nil, reply.err
+		}
+		if reply.parsed != nil {
+			return reply.parsed, nil
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return p.single.Parse(ctx, input)
+}
+
+// enqueue adds req to the pending batch, sending the batch when it is full.
+// The first request of a batch starts the timer that sends it otherwise.
+func (p *BatchLLMParser) enqueue(req *batchRequest) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = append(p.pending, req)
+	if len(p.pending) >= p.size {
+		p.sendPending()
+		return
+	}
+	if len(p.pending) == 1 {
+		id := p.batchID
+		time.AfterFunc(p.wait, func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if p.batchID == id {
+				p.sendPending()
+			}
+		})
+	}
+}
+
+// sendPending starts sending the pending batch. p.mu must be held.
+func (p *BatchLLMParser) sendPending() {
+	batch := p.pending
+	p.pending = nil
+	p.batchID++
+	go p.send(batch)
+}
+
+// send parses a batch with one request and answers its Parse calls. The
+// request outlives the cancellation of the call that started the batch, as
+// the others still wait for it.
+func (p *BatchLLMParser) send(batch []*batchRequest) {
+	if len(batch) == 1 {
+		// A lone filename is parsed with the regular prompt
+		batch[0].done <- batchReply{}
+		return
+	}
+
+	filenames := make([]string, len(batch))
+	for i, req := range batch {
+		filenames[i] = filepath.Base(req.input.OriginalFilename)
+	}
+	ctx := llm.WithFilename(context.WithoutCancel(batch[0].ctx), "")
+	response, err := p.single.client.CompleteJSONWithRetry(
+		ctx,
+		prompts.FilenameBatchParsePrompt(filenames),
+		prompts.FilenameBatchParseSchema,
+		p.single.retryAttempts,
+		time.Duration(p.single.retryDelaySeconds)*time.Second,
+	)
+	if errors.Is(err, llm.ErrBudgetExceeded) {
+		for _, req := range batch {
+			req.done <- batchReply{err: err}
+		}
+		return
+	}
+
+	parsed := make([]*models.ParsedFilename, len(batch))
+	var resp prompts.BatchParseResponse
+	if err == nil {
+		err = json.Unmarshal([]byte(response), &resp)
+	}
+	if err != nil {
+		p.logger.Warn("batch parse failed; parsing the files one at a time", "files", len(batch), "error", err)
+	}
+	for _, r := range resp.Results {
+		if r.Index < 0 || r.Index >= len(batch) || parsed[r.Index] != nil {
+			continue
+		}
+		result := r.ParsedFilename
+		parsed[r.Index] = finishLLMParse(&result, batch[r.Index].input)
+	}
+
+	for i, req := range batch {
+		if err == nil && parsed[i] == nil {
+			p.logger.Debug("batch parse left out a file; parsing it on its own", "file", req.input.OriginalFilename)
+		}
+		req.done <- batchReply{parsed: parsed[i]}
+	}
+}
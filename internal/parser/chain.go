@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"comic-parser/internal/llm"
+	"comic-parser/internal/models"
+)
+
+// ChainStage is a named parser of a ChainParser.
+type ChainStage struct {
+	Name   string
+	Parser Parser
+}
+
+// StageStats counts the outcomes of a ChainParser stage.
+type StageStats struct {
+	Name     string
+	Attempts int
+	Accepted int // parses returned with medium or high confidence
+	Passed   int // low confidence or failed parses handed to the next stage
+	Failed   int // parse errors
+}
+
+// ChainParser implements the Parser interface by trying parsers in order.
+// A parse with low or no confidence, or a failed one, falls through to the
+// next stage; the last stage's parse is used whatever its confidence. When
+// the last stage fails, the most recent low confidence parse is returned.
+type ChainParser struct {
+	stages []ChainStage
+
+	mu    sync.Mutex
+	stats []StageStats
+}
+
+// NewChainParser creates a ChainParser trying stages in order.
+func NewChainParser(stages ...ChainStage) *ChainParser {
+	stats := make([]StageStats, len(stages))
+	for i, stage := range stages {
+		stats[i].Name = stage.Name
+	}
+	return &ChainParser{stages: stages, stats: stats}
+}
+
+// Parse implements the Parser interface.
+// An exhausted LLM budget is returned right away rather than falling back,
+// so the processor stops spending.
+func (p *ChainParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	var fallback *models.ParsedFilename
+	var errs []error
+	for i, stage := range p.stages {
+		// Parsers may fill in their input, so each stage gets its own copy
+		in := *input
+		parsed, err := stage.Parser.Parse(ctx, &in)
+		last := i == len(p.stages)-1
+		switch {
+		case err != nil:
+			p.count(i, func(s *StageStats) { s.Failed++ })
+			if errors.Is(err, llm.ErrBudgetExceeded) {
+				return nil, err
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", stage.Name, err))
+		case last || !lowConfidence(parsed.Confidence):
+			p.count(i, func(s *StageStats) { s.Accepted++ })
+			return parsed, nil
+		default:
+			p.count(i, func(s *StageStats) { s.Passed++ })
+			fallback = parsed
+		}
+	}
+	if fallback != nil {
+		return fallback, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// count records an attempt of stage i and its outcome.
+func (p *ChainParser) count(i int, outcome func(*StageStats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats[i].Attempts++
+	outcome(&p.stats[i])
+}
+
+// Stats returns the outcome counts of each stage, in chain order.
+func (p *ChainParser) Stats() []StageStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]StageStats(nil), p.stats...)
+}
+
+// ChainStats returns the stage stats of p when it is a ChainParser, also
+// behind a ComicInfoParser, and nil otherwise.
+func ChainStats(p Parser) []StageStats {
+	if ci, ok := p.(*ComicInfoParser); ok {
+		p = ci.fallback
+	}
+	if chain, ok := p.(*ChainParser); ok {
+		return chain.Stats()
+	}
+	return nil
+}
+
+// lowConfidence reports whether a parse is too uncertain to stop a chain.
+func lowConfidence(confidence string) bool {
+	return confidence == "" || confidence == "low"
+}
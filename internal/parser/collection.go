@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+// collectionFormats recognize collected edition formats, most specific
+// first: an "Omnibus HC" is an omnibus
+var collectionFormats = []struct {
+	format string
+	re     *regexp.Regexp
+}{
+	{models.FormatOmnibus, regexp.MustCompile(`(?i)\bomnibus\b`)},
+	{models.FormatHC, regexp.MustCompile(`(?i)\b(hc|hardcover|hardback)\b`)},
+	{models.FormatTPB, regexp.MustCompile(`(?i)\b(tpb|trade[\s.-]*paperback)\b`)},
+}
+
+// ParseCollection parses collected editions, such as
+// "Saga Vol. 01 (2012) (TPB).cbz", "Batman - Year One (2005) (HC).cbr" or
+// "Daredevil by Frank Miller Omnibus (2007).cbz". The title is what precedes
+// the volume number, the format or the tags. It reports false for filenames
+// that don't name a TPB, HC or Omnibus format.
+func ParseCollection(filename string) (*models.ParsedCollection, bool) {
+	name := filepath.Base(filename)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.ReplaceAll(name, "_", " ")
+
+	parsed := &models.ParsedCollection{OriginalFilename: filename}
+	end := len(name)
+	for _, f := range collectionFormats {
+		if loc := f.re.FindStringIndex(name); loc != nil {
+			parsed.Format = f.format
+			end = loc[0]
+			break
+		}
+	}
+	if parsed.Format == "" {
+		return nil, false
+	}
+
+	if m := yearRe.FindStringSubmatch(name); m != nil {
+		parsed.Year = m[1]
+	}
+	if i := strings.IndexAny(name, "(["); i >= 0 && i < end {
+		end = i
+	}
+	if m := mangaVolumeRe.FindStringSubmatchIndex(name); m != nil && m[0] < end {
+		parsed.VolumeNumber = trimNumber(name[m[2]:m[3]])
+		end = m[0]
+	}
+
+	parsed.Title = strings.Join(strings.Fields(strings.Trim(name[:end], " -.")), " ")
+	if parsed.Title == "" {
+		return nil, false
+	}
+	return parsed, true
+}
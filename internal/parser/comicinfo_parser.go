@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"path/filepath"
+
+	"comic-parser/internal/archive"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
+)
+
+// ComicInfoParser implements the Parser interface using the ComicInfo.xml
+// embedded in local archives, falling back to another parser for files
+// without usable metadata.
+type ComicInfoParser struct {
+	fallback Parser
+	logger   *slog.Logger
+}
+
+// NewComicInfoParser creates a ComicInfoParser that defers to fallback.
+func NewComicInfoParser(fallback Parser) *ComicInfoParser {
+	return &ComicInfoParser{fallback: fallback, logger: logging.Logger(logging.Parser)}
+}
+
+// Parse implements the Parser interface.
+// Only files with a local path (input.Path) are opened.
+func (p *ComicInfoParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	if input.Path != "" {
+		ci, err := archive.ReadComicInfo(input.Path)
+		switch {
+		case err == nil:
+			if parsed := ci.ParsedFilename(input.OriginalFilename); parsed != nil {
+				parsed.Path = input.Path
+				parsed.Special = DetectSpecial(filepath.Base(input.Path))
+				parsed.Language = DetectLanguage(filepath.Base(input.Path))
+				return parsed, nil
+			}
+		case errors.Is(err, archive.ErrNoComicInfo), errors.Is(err, archive.ErrUnsupported):
+			// Nothing to read; parse the filename instead
+		default:
+			p.logger.Warn("reading ComicInfo.xml failed", "path", input.Path, "error", err)
+		}
+	}
+	return p.fallback.Parse(ctx, input)
+}
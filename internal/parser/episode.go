@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// videoExtensions lists the video formats recognized as TV episodes
+var videoExtensions = map[string]bool{
+	".mkv":  true,
+	".mp4":  true,
+	".m4v":  true,
+	".avi":  true,
+	".mov":  true,
+	".wmv":  true,
+	".ts":   true,
+	".webm": true,
+}
+
+// Episode numbering patterns, tried in order. The first group is the series
+// name.
+var (
+	// "Show.Name.S01E02", "Show Name - s1e2", "Show_Name_S01.E02"
+	seasonEpisode = regexp.MustCompile(`(?i)^(.*?)[\s._-]*\bs(\d{1,2})[\s._-]?e(\d{1,3})`)
+	// "Show Name 1x02"
+	crossEpisode = regexp.MustCompile(`(?i)^(.*?)[\s._-]*\b(\d{1,2})x(\d{2,3})\b`)
+	// "Show.Name.2024.01.15", "Show Name 2024-01-15"
+	datedEpisode = regexp.MustCompile(`^(.*?)[\s._-]*\b((?:19|20)\d{2})[.-](\d{2})[.-](\d{2})\b`)
+	// "[Group] Show Name - 12 [1080p]", "Show Name - 012v2"
+	absoluteEpisode = regexp.MustCompile(`^(.*?)\s+-\s+(\d{1,4})(?:v\d)?(?:[\s\[(]|$)`)
+
+	// releaseGroup matches bracketed tags such as "[SubsPlease]"
+	releaseGroup = regexp.MustCompile(`\[[^\]]*\]`)
+	// seriesYear matches a trailing "(2008)" or " 2008" of a series name
+	seriesYear = regexp.MustCompile(`^(.+?)[\s.]+\(?((?:19|20)\d{2})\)?$`)
+)
+
+// IsVideoFile reports whether path has a video extension.
+func IsVideoFile(path string) bool {
+	return videoExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// ParseEpisode reads the series and episode numbering of a TV episode
+// filename: season and episode (S01E02 or 1x02), air date (2024-01-15) or
+// the absolute numbering of anime releases ("[Group] Show - 12").
+func ParseEpisode(filename string) (*models.ParsedEpisode, error) {
+	name := filepath.Base(filename)
+	if IsVideoFile(name) {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	parsed := &models.ParsedEpisode{OriginalFilename: filename}
+
+	if m := seasonEpisode.FindStringSubmatch(name); m != nil {
+		parsed.Season, _ = strconv.Atoi(m[2])
+		parsed.Episode, _ = strconv.Atoi(m[3])
+		parsed.Series, parsed.Year = cleanSeries(m[1])
+	} else if m := crossEpisode.FindStringSubmatch(name); m != nil {
+		parsed.Season, _ = strconv.Atoi(m[2])
+		parsed.Episode, _ = strconv.Atoi(m[3])
+		parsed.Series, parsed.Year = cleanSeries(m[1])
+	} else if m := datedEpisode.FindStringSubmatch(name); m != nil {
+		date := m[2] + "-" + m[3] + "-" + m[4]
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("invalid air date %s in %q", date, filename)
+		}
+		parsed.AirDate = date
+		parsed.Series, parsed.Year = cleanSeries(m[1])
+	} else if m := absoluteEpisode.FindStringSubmatch(name); m != nil {
+		parsed.AbsoluteNumber, _ = strconv.Atoi(m[2])
+		parsed.Series, parsed.Year = cleanSeries(m[1])
+	} else {
+		return nil, fmt.Errorf("no season and episode, air date or episode number in %q", filename)
+	}
+
+	if parsed.Series == "" {
+		return nil, fmt.Errorf("no series name in %q", filename)
+	}
+	return parsed, nil
+}
+
+// cleanSeries turns the series part of a filename into a name, splitting
+// off a trailing year. Dots and underscores separate words in scene names
+// but are kept in names that already have spaces ("Mr. Robot").
+func cleanSeries(s string) (string, int) {
+	s = releaseGroup.ReplaceAllString(s, " ")
+	if !strings.Contains(strings.TrimSpace(s), " ") {
+		s = strings.NewReplacer(".", " ", "_", " ").Replace(s)
+	} else {
+		s = strings.ReplaceAll(s, "_", " ")
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.Trim(s, " -")
+
+	var year int
+	if m := seriesYear.FindStringSubmatch(s); m != nil {
+		s = m[1]
+		year, _ = strconv.Atoi(m[2])
+	}
+	return s, year
+}
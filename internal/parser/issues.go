@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxIssueRange is the most issues a range in a filename may span. Larger
+// spans are more likely years or other numbers than a collection.
+const maxIssueRange = 100
+
+// issueRangeRe matches an issue range following the title, as in
+// "Batman 001-006" or "Saga #1-3"
+var issueRangeRe = regexp.MustCompile(`^(.*?\S)[\s_]+#?(\d{1,4})\s*-\s*#?(\d{1,4})\s*$`)
+
+// DetectIssueRange returns the title and the issue numbers of a file that
+// collects a range of issues, such as "Batman 001-006 (2016).cbz", or nil
+// issues when filename names a single issue.
+func DetectIssueRange(filename string) (title string, issues []string) {
+	name := filepath.Base(filename)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if i := strings.IndexAny(name, "(["); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ReplaceAll(name, "_", " ")
+
+	m := issueRangeRe.FindStringSubmatch(name)
+	if m == nil {
+		return "", nil
+	}
+	first, _ := strconv.Atoi(m[2])
+	last, _ := strconv.Atoi(m[3])
+	if last <= first || last-first >= maxIssueRange || isYear(first) && isYear(last) {
+		return "", nil
+	}
+
+	for n := first; n <= last; n++ {
+		issues = append(issues, strconv.Itoa(n))
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(m[1]), "-")), issues
+}
+
+func isYear(n int) bool {
+	return n >= 1900 && n < 2100
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"comic-parser/internal/llm"
@@ -13,7 +14,7 @@ import (
 
 // LLMClient defines the interface for LLM interactions required by the parser.
 type LLMClient interface {
-	CompleteWithRetry(ctx context.Context, prompt string, maxRetries int, delay time.Duration) (string, error)
+	CompleteJSONWithRetry(ctx context.Context, prompt string, schema llm.Schema, maxRetries int, delay time.Duration) (string, error)
 }
 
 // LLMParser implements the Parser interface using an LLM.
@@ -33,13 +34,15 @@ func NewLLMParser(client LLMClient, retryAttempts int, retryDelaySeconds int) *L
 }
 
 // Parse implements the Parser interface.
-// It uses an LLM to parse the filename.
+// It uses an LLM to parse the filename; any directory part is not sent.
 func (p *LLMParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
-	prompt := prompts.FilenameParsePrompt(input.OriginalFilename)
+	name := filepath.Base(input.OriginalFilename)
+	prompt := prompts.FilenameParsePrompt(name)
 
-	response, err := p.client.CompleteWithRetry(
+	response, err := p.client.CompleteJSONWithRetry(
 		ctx,
 		prompt,
+		prompts.FilenameParseSchema,
 		p.retryAttempts,
 		time.Duration(p.retryDelaySeconds)*time.Second,
 	)
@@ -47,18 +50,36 @@ func (p *LLMParser) Parse(ctx context.Context, input *models.ParsedFilename) (*m
 		return nil, fmt.Errorf("LLM completion: %w", err)
 	}
 
-	// Extract JSON from response
-	jsonStr := llm.ExtractJSON(response)
-
 	// Create a new struct to hold the result
 	// We unmarshal into a new struct to ensure we get a fresh parse
 	var parsed models.ParsedFilename
-	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
 		return nil, fmt.Errorf("parsing LLM response: %w (response: %s)", err, response)
 	}
 
+	return finishLLMParse(&parsed, input), nil
+}
+
+// finishLLMParse completes the LLM's parse of input: the filename and path
+// are kept from the input, and what the filename shows reliably is filled
+// in from it.
+func finishLLMParse(parsed, input *models.ParsedFilename) *models.ParsedFilename {
+	name := filepath.Base(input.OriginalFilename)
+
 	// Ensure OriginalFilename is preserved from the input
 	parsed.OriginalFilename = input.OriginalFilename
-
-	return &parsed, nil
+	parsed.Path = input.Path
+	if parsed.Special == "" {
+		parsed.Special = DetectSpecial(name)
+	}
+	if parsed.IssueType == "" {
+		parsed.IssueType = DetectIssueType(name)
+	}
+	parsed.Language = DetectLanguage(name)
+	// Ranges are expanded here rather than trusted to the model
+	if _, issues := DetectIssueRange(name); issues != nil {
+		parsed.IssueNumber = issues[0]
+		parsed.Issues = issues
+	}
+	return parsed
 }
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+var (
+	// Leading scanlation group tags, e.g. "[Group] "
+	mangaGroupRe = regexp.MustCompile(`^\s*(\[[^\]]*\]\s*)+`)
+	yearRe       = regexp.MustCompile(`\(((?:19|20)\d{2})\)`)
+
+	// "v05", "Vol. 5", "Volume 05"
+	mangaVolumeRe = regexp.MustCompile(`(?i)\b(?:v|vol\.?|volume)\s*(\d+(?:\.\d+)?)\b`)
+	// "c034", "Ch. 34", "Chapter 120"
+	mangaChapterRe = regexp.MustCompile(`(?i)\b(?:c|ch\.?|chapter)\s*(\d+(?:\.\d+)?)\b`)
+	// An issue number following the volume, as in "Batman v2 001"
+	issueAfterVolumeRe = regexp.MustCompile(`^[\s#]*\d`)
+)
+
+// ParseManga parses manga releases numbered by volume and chapter, such as
+// "Series v05 c034 (2019).cbz", "[Group] Series - Chapter 120.cbz" or
+// "Series Vol. 3.cbz". ComicVine lists manga volumes as issues, so the
+// volume becomes the issue number and the chapter is kept apart. It reports
+// false for other filenames, including western comics with a volume and an
+// issue number.
+func ParseManga(filename string) (*models.ParsedFilename, bool) {
+	name := filepath.Base(filename)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = mangaGroupRe.ReplaceAllString(name, "")
+
+	var year string
+	if m := yearRe.FindStringSubmatch(name); m != nil {
+		year = m[1]
+	}
+	// Tags in parentheses or brackets follow the numbering
+	if i := strings.IndexAny(name, "(["); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ReplaceAll(name, "_", " ")
+
+	volume := mangaVolumeRe.FindStringSubmatchIndex(name)
+	chapter := mangaChapterRe.FindStringSubmatchIndex(name)
+	if volume == nil && chapter == nil {
+		return nil, false
+	}
+	if chapter == nil && issueAfterVolumeRe.MatchString(name[volume[1]:]) {
+		return nil, false
+	}
+
+	end := len(name)
+	for _, m := range [][]int{volume, chapter} {
+		if m != nil && m[0] < end {
+			end = m[0]
+		}
+	}
+	title := strings.Join(strings.Fields(strings.Trim(name[:end], " -.")), " ")
+	if title == "" {
+		return nil, false
+	}
+
+	parsed := &models.ParsedFilename{
+		OriginalFilename: filename,
+		Title:            title,
+		Year:             year,
+		Confidence:       "high",
+	}
+	var notes []string
+	if volume != nil {
+		parsed.IssueNumber = trimNumber(name[volume[2]:volume[3]])
+		notes = append(notes, "volume "+parsed.IssueNumber)
+	}
+	if chapter != nil {
+		parsed.Chapter = trimNumber(name[chapter[2]:chapter[3]])
+		notes = append(notes, "chapter "+parsed.Chapter)
+	}
+	if volume == nil {
+		// Without a volume the release can't be pinned to a ComicVine issue
+		parsed.Confidence = "medium"
+		notes = append(notes, "no volume to search as the issue number")
+	}
+	parsed.Notes = strings.Join(notes, ", ")
+	return parsed, true
+}
+
+// trimNumber drops the zero padding of a number, keeping a lone "0".
+func trimNumber(n string) string {
+	n = strings.TrimLeft(n, "0")
+	if n == "" || n[0] == '.' {
+		n = "0" + n
+	}
+	return n
+}
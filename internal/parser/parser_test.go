@@ -0,0 +1,547 @@
+package parser
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"comic-parser/internal/llm"
+	"comic-parser/internal/models"
+	"comic-parser/internal/prompts"
+)
+
+func TestDetectSpecial(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"Saga 001 (2012) (Digital).cbz", ""},
+		{"FCBD 2019 - Avengers (2019).cbz", models.SpecialFCBD},
+		{"Free_Comic_Book_Day_2015_Spongebob.cbr", models.SpecialFCBD},
+		{"Star Wars Preview (2015).cbz", models.SpecialPreview},
+		{"Bone Ashcan (1991).cbr", models.SpecialAshcan},
+		{"Spawn #0 Promo (1993).cbz", models.SpecialPromo},
+		{"Previews Vol 28 #3.cbz", ""},
+		{"Ashcanville 001.cbz", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectSpecial(tt.filename); got != tt.want {
+			t.Errorf("DetectSpecial(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestDetectIssueType(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"Saga 001 (2012) (Digital).cbz", ""},
+		{"Batman Annual 2021 (2021).cbz", models.IssueTypeAnnual},
+		{"X-Men_Annual_03_(1979).cbr", models.IssueTypeAnnual},
+		{"Giant-Size X-Men 001 (1975).cbz", models.IssueTypeGiantSize},
+		{"Giant Size Spider-Man 01.cbz", models.IssueTypeGiantSize},
+		{"Wolverine One-Shot (2020).cbz", models.IssueTypeOneShot},
+		{"Hellboy Oneshot.cbz", models.IssueTypeOneShot},
+		{"Spawn Special 001 (2000).cbz", models.IssueTypeSpecial},
+		{"Specialist 001.cbz", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectIssueType(tt.filename); got != tt.want {
+			t.Errorf("DetectIssueType(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"Saga 001 (2012) (Digital).cbz", ""},
+		{"Asterix 01 (French).cbz", "fr"},
+		{"Saga 001 [RUS].cbz", "ru"},
+		{"Saga 001 (2012) (Spanish digital).cbz", "es"},
+		{"One Piece v01 [pt-br].cbz", "pt"},
+		{"Batman 001 (2016) (Deutsch) (Panini).cbr", "de"},
+		{"Saga 001 (FR).cbz", "fr"},
+		{"Saga 001 (por Grupo X).cbz", ""},
+		{"Frenchman 001.cbz", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.filename); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestDetectIssueRange(t *testing.T) {
+	tests := []struct {
+		filename  string
+		wantTitle string
+		want      []string
+	}{
+		{"Batman 001-006 (2016) (Digital).cbz", "Batman", []string{"1", "2", "3", "4", "5", "6"}},
+		{"Saga #1-3.cbr", "Saga", []string{"1", "2", "3"}},
+		{"Spider-Man_2099_10_-_12.cbz", "Spider-Man 2099", []string{"10", "11", "12"}},
+		{"Saga 001 (2012).cbz", "", nil},
+		{"X-Men 1991-1992 Annual.cbz", "", nil},
+		{"Sandman 5-1.cbz", "", nil},
+		{"Batman 1-500.cbz", "", nil},
+	}
+
+	for _, tt := range tests {
+		title, issues := DetectIssueRange(tt.filename)
+		if title != tt.wantTitle || !reflect.DeepEqual(issues, tt.want) {
+			t.Errorf("DetectIssueRange(%q) = %q, %v; want %q, %v", tt.filename, title, issues, tt.wantTitle, tt.want)
+		}
+	}
+
+	parsed, err := NewRegexParser().Parse(context.Background(), &models.ParsedFilename{OriginalFilename: "Batman 001-003 (2016).cbz"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Title != "Batman" || parsed.IssueNumber != "1" || len(parsed.Issues) != 3 || parsed.Year != "2016" {
+		t.Errorf("Unexpected parse of an issue range: %+v", parsed)
+	}
+}
+
+func TestParseManga(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     models.ParsedFilename
+	}{
+		{"Berserk v05 c034 (2019) (Digital).cbz", models.ParsedFilename{Title: "Berserk", IssueNumber: "5", Chapter: "34", Year: "2019", Confidence: "high", Notes: "volume 5, chapter 34"}},
+		{"[MangaGroup] Chainsaw Man - Chapter 120.cbz", models.ParsedFilename{Title: "Chainsaw Man", Chapter: "120", Confidence: "medium", Notes: "chapter 120, no volume to search as the issue number"}},
+		{"One_Piece_Vol._100.cbr", models.ParsedFilename{Title: "One Piece", IssueNumber: "100", Confidence: "high", Notes: "volume 100"}},
+		{"Vinland Saga Volume 01 [Kodansha].cbz", models.ParsedFilename{Title: "Vinland Saga", IssueNumber: "1", Confidence: "high", Notes: "volume 1"}},
+		{"Blame! Ch. 10.5.cbz", models.ParsedFilename{Title: "Blame!", Chapter: "10.5", Confidence: "medium", Notes: "chapter 10.5, no volume to search as the issue number"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseManga(tt.filename)
+		if !ok {
+			t.Errorf("ParseManga(%q) did not recognize manga", tt.filename)
+			continue
+		}
+		tt.want.OriginalFilename = tt.filename
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ParseManga(%q) = %+v, want %+v", tt.filename, *got, tt.want)
+		}
+	}
+
+	// Western comics keep their volume and issue number for the other parsers
+	for _, filename := range []string{"Batman v2 001 (2011).cbz", "Saga 001 (2012).cbz", "Vol. 3.cbz", "Action Comics #1000.cbz"} {
+		if got, ok := ParseManga(filename); ok {
+			t.Errorf("ParseManga(%q) = %+v, want not manga", filename, *got)
+		}
+	}
+}
+
+func TestParseWeekly(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     models.ParsedFilename
+	}{
+		{"2000AD prog 2350.cbz", models.ParsedFilename{Title: "2000 AD", IssueNumber: "2350", Confidence: "high", Notes: "weekly anthology, prog 2350"}},
+		{"2000_AD_Prog_0042_(1977)_(digital).cbr", models.ParsedFilename{Title: "2000 AD", IssueNumber: "42", Year: "1977", Confidence: "high", Notes: "weekly anthology, prog 42"}},
+		{"2000 A.D. #1234.cbz", models.ParsedFilename{Title: "2000 AD", IssueNumber: "1234", Confidence: "high", Notes: "weekly anthology, issue 1234"}},
+		{"Judge Dredd Megazine 463.cbz", models.ParsedFilename{Title: "Judge Dredd Megazine", IssueNumber: "463", Confidence: "high", Notes: "weekly anthology, issue 463"}},
+		{"Judge Dredd - The Megazine 001 [Rebellion].cbz", models.ParsedFilename{Title: "Judge Dredd Megazine", IssueNumber: "1", Confidence: "high", Notes: "weekly anthology, issue 1"}},
+		{"Starlord Prog 5.cbz", models.ParsedFilename{Title: "Starlord", IssueNumber: "5", Confidence: "high", Notes: "weekly anthology, prog 5"}},
+		{"2000 AD Sci-Fi Special Prog 3.cbz", models.ParsedFilename{Title: "2000 AD Sci-Fi Special", IssueNumber: "3", Confidence: "high", Notes: "weekly anthology, prog 3"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseWeekly(tt.filename)
+		if !ok {
+			t.Errorf("ParseWeekly(%q) did not recognize a weekly", tt.filename)
+			continue
+		}
+		tt.want.OriginalFilename = tt.filename
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ParseWeekly(%q) = %+v, want %+v", tt.filename, *got, tt.want)
+		}
+	}
+
+	for _, filename := range []string{"Saga 001 (2012).cbz", "2000 AD Sci-Fi Special (1995).cbz", "Progress 2 (2020).cbz", "Judge Dredd 001 (2012).cbz"} {
+		if got, ok := ParseWeekly(filename); ok {
+			t.Errorf("ParseWeekly(%q) = %+v, want not a weekly", filename, *got)
+		}
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	p := NewRegexParser()
+	tests := []struct {
+		filename string
+		outcome  string
+		reason   string
+	}{
+		{"2000AD prog 2350.cbz", PreflightRegex, ""},
+		{"Batman 001-012 (2011).cbz", PreflightRegex, ""},
+		{"Saga 001 (2012).cbz", PreflightLLM, ""},
+		{"/comics/0001 (2012).cbz", PreflightUnparseable, "no title in the name"},
+		{"3f2a9c0d1e8b7a6f5c4d.cbr", PreflightUnparseable, "name is a hash"},
+		{".cbz", PreflightUnparseable, "empty name"},
+	}
+	for _, tt := range tests {
+		got := Preflight(context.Background(), p, tt.filename)
+		if got.Outcome != tt.outcome || got.Reason != tt.reason {
+			t.Errorf("Preflight(%q) = %s %q, want %s %q", tt.filename, got.Outcome, got.Reason, tt.outcome, tt.reason)
+		}
+		if (got.Parsed != nil) != (tt.outcome == PreflightRegex) {
+			t.Errorf("Preflight(%q) parse = %+v", tt.filename, got.Parsed)
+		}
+	}
+}
+
+func TestParseCollection(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     models.ParsedCollection
+	}{
+		{"Saga Vol. 01 (2012) (TPB).cbz", models.ParsedCollection{Title: "Saga", Format: models.FormatTPB, VolumeNumber: "1", Year: "2012"}},
+		{"Batman - Year One (2005) (HC).cbr", models.ParsedCollection{Title: "Batman - Year One", Format: models.FormatHC, Year: "2005"}},
+		{"Daredevil by Frank Miller Omnibus HC (2007).cbz", models.ParsedCollection{Title: "Daredevil by Frank Miller", Format: models.FormatOmnibus, Year: "2007"}},
+		{"Y_-_The_Last_Man_v01_-_Unmanned_(TPB)_(2003).cbz", models.ParsedCollection{Title: "Y - The Last Man", Format: models.FormatTPB, VolumeNumber: "1", Year: "2003"}},
+		{"Watchmen Trade Paperback.cbz", models.ParsedCollection{Title: "Watchmen", Format: models.FormatTPB}},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseCollection(tt.filename)
+		if !ok {
+			t.Errorf("ParseCollection(%q) did not recognize a collection", tt.filename)
+			continue
+		}
+		tt.want.OriginalFilename = tt.filename
+		if *got != tt.want {
+			t.Errorf("ParseCollection(%q) = %+v, want %+v", tt.filename, *got, tt.want)
+		}
+	}
+
+	for _, filename := range []string{"Saga 001 (2012).cbz", "Berserk v05 c034.cbz", "(TPB).cbz"} {
+		if got, ok := ParseCollection(filename); ok {
+			t.Errorf("ParseCollection(%q) = %+v, want not a collection", filename, *got)
+		}
+	}
+}
+
+func TestParseEpisode(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     models.ParsedEpisode
+	}{
+		{"Breaking.Bad.S01E02.720p.BluRay.x264.mkv", models.ParsedEpisode{Series: "Breaking Bad", Season: 1, Episode: 2}},
+		{"The Office (US) - s3e10 - A Benihana Christmas.mp4", models.ParsedEpisode{Series: "The Office (US)", Season: 3, Episode: 10}},
+		{"Doctor.Who.2005.S10E01.mkv", models.ParsedEpisode{Series: "Doctor Who", Year: 2005, Season: 10, Episode: 1}},
+		{"Mr. Robot 2x05.avi", models.ParsedEpisode{Series: "Mr. Robot", Season: 2, Episode: 5}},
+		{"The.Daily.Show.2024.01.15.1080p.WEB.mkv", models.ParsedEpisode{Series: "The Daily Show", AirDate: "2024-01-15"}},
+		{"[SubsPlease] Sousou no Frieren - 12 (1080p) [ABCD1234].mkv", models.ParsedEpisode{Series: "Sousou no Frieren", AbsoluteNumber: 12}},
+		{"One Piece - 1071v2 [720p].mkv", models.ParsedEpisode{Series: "One Piece", AbsoluteNumber: 1071}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEpisode(tt.filename)
+		if err != nil {
+			t.Errorf("ParseEpisode(%q) failed: %v", tt.filename, err)
+			continue
+		}
+		tt.want.OriginalFilename = tt.filename
+		if *got != tt.want {
+			t.Errorf("ParseEpisode(%q) = %+v, want %+v", tt.filename, *got, tt.want)
+		}
+	}
+
+	for _, filename := range []string{"Home Movie.mkv", "S01E02.mkv", "Show.2024.13.45.mkv"} {
+		if _, err := ParseEpisode(filename); err == nil {
+			t.Errorf("ParseEpisode(%q) should fail", filename)
+		}
+	}
+}
+
+type stubParser struct{ calls int }
+
+func (s *stubParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	s.calls++
+	return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "From Filename", Confidence: "low"}, nil
+}
+
+func TestComicInfoParser(t *testing.T) {
+	dir := t.TempDir()
+	tagged := filepath.Join(dir, "tagged.cbz")
+	untagged := filepath.Join(dir, "untagged.cbz")
+
+	for path, files := range map[string]map[string]string{
+		tagged:   {"ComicInfo.xml": "<ComicInfo><Series>Saga</Series><Number>7</Number></ComicInfo>"},
+		untagged: {"001.jpg": "img"},
+	} {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create archive: %v", err)
+		}
+		zw := zip.NewWriter(f)
+		for name, content := range files {
+			w, _ := zw.Create(name)
+			w.Write([]byte(content))
+		}
+		zw.Close()
+		f.Close()
+	}
+
+	fallback := &stubParser{}
+	p := NewComicInfoParser(fallback)
+	ctx := context.Background()
+
+	parsed, err := p.Parse(ctx, &models.ParsedFilename{OriginalFilename: "tagged.cbz", Path: tagged})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Title != "Saga" || parsed.IssueNumber != "7" || parsed.Confidence != "high" || parsed.Path != tagged {
+		t.Errorf("Expected ComicInfo parse, got %+v", parsed)
+	}
+
+	for _, input := range []*models.ParsedFilename{
+		{OriginalFilename: "untagged.cbz", Path: untagged},
+		{OriginalFilename: "remote.cbz"},
+	} {
+		parsed, err := p.Parse(ctx, input)
+		if err != nil || parsed.Title != "From Filename" {
+			t.Errorf("Expected fallback parse for %s, got %+v, %v", input.OriginalFilename, parsed, err)
+		}
+	}
+	if fallback.calls != 2 {
+		t.Errorf("Expected 2 fallback calls, got %d", fallback.calls)
+	}
+}
+
+// fixedParser returns the same parse, or error, for every filename.
+type fixedParser struct {
+	title, confidence string
+	err               error
+}
+
+func (f fixedParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	input.Title = f.title
+	input.Confidence = f.confidence
+	return input, nil
+}
+
+func TestChainParser(t *testing.T) {
+	ctx := context.Background()
+	parse := func(p Parser) (*models.ParsedFilename, error) {
+		return p.Parse(ctx, &models.ParsedFilename{OriginalFilename: "Saga 001.cbz"})
+	}
+
+	chain := NewChainParser(
+		ChainStage{"regex", fixedParser{title: "Saga 001", confidence: "low"}},
+		ChainStage{"llm", fixedParser{title: "Saga", confidence: "high"}},
+	)
+	parsed, err := parse(chain)
+	if err != nil || parsed.Title != "Saga" {
+		t.Errorf("Expected the low confidence parse to fall through, got %+v, %v", parsed, err)
+	}
+	want := []StageStats{
+		{Name: "regex", Attempts: 1, Passed: 1},
+		{Name: "llm", Attempts: 1, Accepted: 1},
+	}
+	if got := ChainStats(NewComicInfoParser(chain)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChainStats = %+v, want %+v", got, want)
+	}
+
+	chain = NewChainParser(
+		ChainStage{"regex", fixedParser{title: "Saga", confidence: "medium"}},
+		ChainStage{"llm", fixedParser{err: errors.New("not called")}},
+	)
+	if parsed, err := parse(chain); err != nil || parsed.Title != "Saga" || chain.Stats()[1].Attempts != 0 {
+		t.Errorf("Expected the medium confidence parse to stop the chain, got %+v, %v", parsed, err)
+	}
+
+	// A failing last stage leaves the earlier low confidence parse
+	chain = NewChainParser(
+		ChainStage{"regex", fixedParser{title: "Saga 001", confidence: "low"}},
+		ChainStage{"llm", fixedParser{err: errors.New("timeout")}},
+	)
+	if parsed, err := parse(chain); err != nil || parsed.Title != "Saga 001" {
+		t.Errorf("Expected the fallback parse, got %+v, %v", parsed, err)
+	}
+
+	chain = NewChainParser(
+		ChainStage{"regex", fixedParser{title: "Saga 001", confidence: "low"}},
+		ChainStage{"llm", fixedParser{err: llm.ErrBudgetExceeded}},
+	)
+	if _, err := parse(chain); !errors.Is(err, llm.ErrBudgetExceeded) {
+		t.Errorf("Expected the budget error, got %v", err)
+	}
+
+	if ChainStats(NewRegexParser()) != nil {
+		t.Error("Expected no stats for a single parser")
+	}
+}
+
+func TestRegexParserPatterns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "patterns.json")
+	os.WriteFile(path, []byte(`[
+		{"name": "scene", "pattern": "^(?P<publisher>[A-Z]+)\\.(?P<title>.+?)\\.(?P<issue>\\d+)\\.(?P<year>\\d{4})$"},
+		{"name": "bracket-issue", "pattern": "^(?P<title>.+?) \\[#(?P<issue>\\d+)\\]"}
+	]`), 0644)
+	patterns, err := LoadPatterns(path)
+	if err != nil {
+		t.Fatalf("LoadPatterns failed: %v", err)
+	}
+	p := NewRegexParser(patterns...)
+
+	tests := []struct {
+		filename string
+		want     models.ParsedFilename
+	}{
+		{"DC.Batman_Beyond.007.2016.cbz", models.ParsedFilename{Title: "Batman Beyond", IssueNumber: "7", Year: "2016", Publisher: "DC", Confidence: "high", Notes: "matched pattern scene"}},
+		{"Saga Annual [#01] (2019).cbr", models.ParsedFilename{Title: "Saga Annual", IssueNumber: "1", Confidence: "high", Notes: "matched pattern bracket-issue", IssueType: models.IssueTypeAnnual}},
+		// Built-in patterns still apply to the rest
+		{"Batman 001-002 (2016).cbz", models.ParsedFilename{Title: "Batman", IssueNumber: "1", Issues: []string{"1", "2"}, Year: "2016", Confidence: "high", Notes: "issues 1-2"}},
+	}
+	for _, tt := range tests {
+		got, err := p.Parse(context.Background(), &models.ParsedFilename{OriginalFilename: tt.filename})
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.filename, err)
+		}
+		tt.want.OriginalFilename = tt.filename
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.filename, *got, tt.want)
+		}
+	}
+
+	for _, content := range []string{
+		`[{"name": "bad", "pattern": "(?P<title>"}]`,
+		`[{"name": "no-groups", "pattern": "^Batman"}]`,
+		`[]`,
+		`{"name": "not a list"}`,
+	} {
+		os.WriteFile(path, []byte(content), 0644)
+		if _, err := LoadPatterns(path); err == nil {
+			t.Errorf("LoadPatterns(%s) should fail", content)
+		}
+	}
+}
+
+// batchClient answers batch parse prompts with the titles of the filenames
+// it knows, or with malformed when set, and single ones with the filename as
+// the title.
+type batchClient struct {
+	titles    map[string]string
+	malformed string
+	err       error
+
+	mu      sync.Mutex
+	batches int
+	singles []string
+}
+
+func (c *batchClient) CompleteJSONWithRetry(ctx context.Context, prompt string, schema llm.Schema, maxRetries int, delay time.Duration) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if schema.Name == prompts.FilenameBatchParseSchema.Name {
+		c.batches++
+		if c.err != nil || c.malformed != "" {
+			return c.malformed, c.err
+		}
+		var results []string
+		list := prompt[strings.Index(prompt, "FILENAMES TO PARSE:\n")+len("FILENAMES TO PARSE:\n"):]
+		for _, line := range strings.Split(list[:strings.Index(list, "\n\n")], "\n") {
+			index, name, _ := strings.Cut(line, ": ")
+			if title, ok := c.titles[name]; ok {
+				results = append(results, fmt.Sprintf(`{"index": %s, "title": %q, "issue_number": "1", "confidence": "high"}`, index, title))
+			}
+		}
+		return `{"results": [` + strings.Join(results, ", ") + `]}`, nil
+	}
+	name := prompt[strings.Index(prompt, "FILENAME TO PARSE:\n")+len("FILENAME TO PARSE:\n"):]
+	name = name[:strings.Index(name, "\n")]
+	c.singles = append(c.singles, name)
+	return fmt.Sprintf(`{"title": %q, "issue_number": "1", "confidence": "medium"}`, name+" (single)"), nil
+}
+
+func TestBatchLLMParser(t *testing.T) {
+	filenames := []string{"Saga 001.cbz", "dir/Batman 002.cbz", "Monstress 003.cbz"}
+	parseAll := func(p *BatchLLMParser) []string {
+		titles := make([]string, len(filenames))
+		var wg sync.WaitGroup
+		for i, filename := range filenames {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				parsed, err := p.Parse(context.Background(), &models.ParsedFilename{OriginalFilename: filename})
+				if err != nil {
+					t.Errorf("Parse(%s) failed: %v", filename, err)
+					return
+				}
+				if parsed.OriginalFilename != filename {
+					t.Errorf("Parse(%s) kept the filename %q", filename, parsed.OriginalFilename)
+				}
+				titles[i] = parsed.Title
+			}()
+		}
+		wg.Wait()
+		return titles
+	}
+
+	// The second file is missing from the response and parsed on its own
+	client := &batchClient{titles: map[string]string{"Saga 001.cbz": "Saga", "Monstress 003.cbz": "Monstress"}}
+	p := NewBatchLLMParser(client, 3, 0, 0)
+	p.wait = time.Minute // only a full batch is sent
+	want := []string{"Saga", "Batman 002.cbz (single)", "Monstress"}
+	if got := parseAll(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %q, want %q", got, want)
+	}
+	if client.batches != 1 || !reflect.DeepEqual(client.singles, []string{"Batman 002.cbz"}) {
+		t.Errorf("Expected 1 batch and 1 single request, got %d and %q", client.batches, client.singles)
+	}
+
+	// A malformed response falls back to a request per file
+	client = &batchClient{malformed: `{"results": "Saga"}`}
+	p = NewBatchLLMParser(client, 3, 0, 0)
+	p.wait = time.Minute
+	if got := parseAll(p); len(client.singles) != 3 || got[0] != "Saga 001.cbz (single)" {
+		t.Errorf("Expected every file parsed on its own, got %q", got)
+	}
+
+	// An exhausted budget is returned rather than spent on single requests
+	client = &batchClient{err: llm.ErrBudgetExceeded}
+	p = NewBatchLLMParser(client, 2, 0, 0)
+	p.wait = time.Minute
+	errs := make(chan error, 2)
+	for _, filename := range filenames[:2] {
+		go func() {
+			_, err := p.Parse(context.Background(), &models.ParsedFilename{OriginalFilename: filename})
+			errs <- err
+		}()
+	}
+	for range 2 {
+		if err := <-errs; !errors.Is(err, llm.ErrBudgetExceeded) {
+			t.Errorf("Expected the budget error, got %v", err)
+		}
+	}
+
+	// A lone filename is sent with the regular prompt once the wait is over
+	client = &batchClient{}
+	p = NewBatchLLMParser(client, 3, 0, 0)
+	p.wait = time.Millisecond
+	if parsed, err := p.Parse(context.Background(), &models.ParsedFilename{OriginalFilename: "Saga 001.cbz"}); err != nil ||
+		parsed.Title != "Saga 001.cbz (single)" || client.batches != 0 {
+		t.Errorf("Expected a single request, got %+v, %v", parsed, err)
+	}
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+// Capture group names a Pattern fills the parse from.
+const (
+	groupTitle     = "title"
+	groupIssue     = "issue"
+	groupYear      = "year"
+	groupPublisher = "publisher"
+)
+
+// Pattern is a user-defined filename pattern for the RegexParser. Its named
+// capture groups (title, issue, year and publisher) fill the parse.
+type Pattern struct {
+	Name string
+	re   *regexp.Regexp
+}
+
+// CompilePattern compiles a named pattern. It must capture the title or the
+// issue number.
+func CompilePattern(name, expr string) (*Pattern, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("pattern %q: %w", name, err)
+	}
+	if re.SubexpIndex(groupTitle) < 0 && re.SubexpIndex(groupIssue) < 0 {
+		return nil, fmt.Errorf("pattern %q: needs a (?P<title>...) or (?P<issue>...) group", name)
+	}
+	return &Pattern{Name: name, re: re}, nil
+}
+
+// DefaultPatternsPath returns where user patterns are read from when no
+// patterns file is configured: xander/patterns.json in the user's
+// configuration directory, such as ~/.config on Linux.
+func DefaultPatternsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "xander", "patterns.json"), nil
+}
+
+// LoadPatterns reads a JSON array of {"name": ..., "pattern": ...} objects
+// and compiles the patterns in order.
+func LoadPatterns(path string) ([]*Pattern, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Name    string `json:"name"`
+		Pattern string `json:"pattern"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	patterns := make([]*Pattern, 0, len(entries))
+	for i, e := range entries {
+		if e.Name == "" {
+			e.Name = fmt.Sprintf("#%d", i+1)
+		}
+		if strings.TrimSpace(e.Pattern) == "" {
+			return nil, fmt.Errorf("%s: pattern %q is empty", path, e.Name)
+		}
+		p, err := CompilePattern(e.Name, e.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		patterns = append(patterns, p)
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New(path + ": no patterns")
+	}
+	return patterns, nil
+}
+
+// match parses filename with the pattern, matching the base name without
+// its extension. It returns nil when the pattern doesn't match or captures
+// neither a title nor an issue number.
+func (p *Pattern) match(filename string) *models.ParsedFilename {
+	name := filepath.Base(filename)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	m := p.re.FindStringSubmatch(name)
+	if m == nil {
+		return nil
+	}
+	group := func(name string) string {
+		if i := p.re.SubexpIndex(name); i >= 0 {
+			return strings.TrimSpace(m[i])
+		}
+		return ""
+	}
+
+	parsed := &models.ParsedFilename{
+		OriginalFilename: filename,
+		Title:            strings.Join(strings.Fields(strings.ReplaceAll(group(groupTitle), "_", " ")), " "),
+		Year:             group(groupYear),
+		Publisher:        group(groupPublisher),
+		Confidence:       "high",
+		Notes:            "matched pattern " + p.Name,
+	}
+	if issue := strings.TrimPrefix(group(groupIssue), "#"); issue != "" {
+		parsed.IssueNumber = trimNumber(issue)
+	}
+	if parsed.Title == "" && parsed.IssueNumber == "" {
+		return nil
+	}
+	return parsed
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"comic-parser/internal/models"
+)
+
+// Outcomes of Preflight.
+const (
+	PreflightRegex       = "regex"       // the regex parser parses the filename
+	PreflightLLM         = "llm"         // the filename needs the LLM
+	PreflightUnparseable = "unparseable" // the filename names no comic
+)
+
+// hashRe matches names made of a long hex string, like content hashes and
+// download IDs
+var hashRe = regexp.MustCompile(`(?i)^[0-9a-f_-]{16,}$`)
+
+// PreflightResult is the outcome of Preflight for a filename.
+type PreflightResult struct {
+	Filename string                 `json:"filename"`
+	Outcome  string                 `json:"outcome"` // a Preflight* constant
+	Reason   string                 `json:"reason,omitempty"`
+	Parsed   *models.ParsedFilename `json:"parsed,omitempty"` // the regex parse, when it was accepted
+}
+
+// Preflight predicts how a run would parse filename by trying only the
+// regex parser p: a medium or high confidence parse is kept, as a parser
+// chain would, and other filenames go on to the LLM unless their name has no
+// title to parse at all.
+func Preflight(ctx context.Context, p *RegexParser, filename string) PreflightResult {
+	res := PreflightResult{Filename: filename}
+	if reason := unparseable(filename); reason != "" {
+		res.Outcome = PreflightUnparseable
+		res.Reason = reason
+		return res
+	}
+	parsed, err := p.Parse(ctx, &models.ParsedFilename{OriginalFilename: filename})
+	if err != nil || lowConfidence(parsed.Confidence) {
+		res.Outcome = PreflightLLM
+		return res
+	}
+	res.Outcome = PreflightRegex
+	res.Parsed = parsed
+	return res
+}
+
+// unparseable returns why filename can't name a comic, or "" when it may.
+func unparseable(filename string) string {
+	base := filepath.Base(filename)
+	name := strings.TrimSpace(strings.TrimSuffix(base, filepath.Ext(base)))
+	switch {
+	case name == "" || name == ".":
+		return "empty name"
+	case hashRe.MatchString(name):
+		return "name is a hash"
+	case !strings.ContainsFunc(tagRe.ReplaceAllString(name, ""), unicode.IsLetter):
+		return "no title in the name"
+	}
+	return ""
+}
@@ -2,24 +2,65 @@ package parser
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
 
 	"comic-parser/internal/models"
 )
 
 // RegexParser implements the Parser interface using regular expressions.
-// Currently it is a placeholder that passes the input through unchanged.
-type RegexParser struct{}
+// It tries user-defined patterns first, then recognizes weekly anthology
+// progs, manga volume and chapter releases and files collecting a range of
+// issues, and passes other filenames through unchanged.
+type RegexParser struct {
+	patterns []*Pattern
+}
 
-// NewRegexParser creates a new RegexParser.
-func NewRegexParser() *RegexParser {
-	return &RegexParser{}
+// NewRegexParser creates a new RegexParser trying patterns, in order,
+// before the built-in ones.
+func NewRegexParser(patterns ...*Pattern) *RegexParser {
+	return &RegexParser{patterns: patterns}
 }
 
 // Parse implements the Parser interface.
-// It currently returns the input struct as-is, simulating a low-confidence match
-// or a pass-through behavior.
+// A filename matching a user-defined pattern is parsed by the first such
+// pattern, which is named in the notes. Weekly anthologies are parsed with
+// ParseWeekly, manga releases with ParseManga and issue ranges with
+// DetectIssueRange; other inputs are returned as-is apart from the special
+// release kind, issue type and language.
 func (p *RegexParser) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
-	// In the future, this will use regex to extract info.
-	// For now, it just returns the input.
+	if parsed := p.matchPatterns(input.OriginalFilename); parsed != nil {
+		parsed.Path = input.Path
+		input = parsed
+	} else if parsed, ok := ParseWeekly(input.OriginalFilename); ok {
+		parsed.Path = input.Path
+		input = parsed
+	} else if parsed, ok := ParseManga(input.OriginalFilename); ok {
+		parsed.Path = input.Path
+		input = parsed
+	} else if title, issues := DetectIssueRange(input.OriginalFilename); issues != nil {
+		input.Title = title
+		input.IssueNumber = issues[0]
+		input.Issues = issues
+		input.Confidence = "high"
+		input.Notes = fmt.Sprintf("issues %s-%s", issues[0], issues[len(issues)-1])
+		if m := yearRe.FindStringSubmatch(filepath.Base(input.OriginalFilename)); m != nil {
+			input.Year = m[1]
+		}
+	}
+	input.Special = DetectSpecial(filepath.Base(input.OriginalFilename))
+	input.IssueType = DetectIssueType(filepath.Base(input.OriginalFilename))
+	input.Language = DetectLanguage(filepath.Base(input.OriginalFilename))
 	return input, nil
 }
+
+// matchPatterns parses filename with the first user-defined pattern that
+// matches it, or returns nil.
+func (p *RegexParser) matchPatterns(filename string) *models.ParsedFilename {
+	for _, pattern := range p.patterns {
+		if parsed := pattern.match(filename); parsed != nil {
+			return parsed
+		}
+	}
+	return nil
+}
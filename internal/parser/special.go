@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+// specialPatterns recognize special releases in filenames, most specific first
+var specialPatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{models.SpecialFCBD, regexp.MustCompile(`(?i)\bfcbd\b|free[\s_.-]*comic[\s_.-]*book[\s_.-]*day`)},
+	{models.SpecialAshcan, regexp.MustCompile(`(?i)\bash[\s_.-]?can\b`)},
+	{models.SpecialPromo, regexp.MustCompile(`(?i)\bpromo(tional)?\b`)},
+	{models.SpecialPreview, regexp.MustCompile(`(?i)\b(preview|sneak[\s_.-]*peek)\b`)},
+}
+
+// issueTypePatterns recognize issues outside the regular numbering, most
+// specific first
+var issueTypePatterns = []struct {
+	kind string
+	re   *regexp.Regexp
+}{
+	{models.IssueTypeAnnual, regexp.MustCompile(`(?i)\bannual\b`)},
+	{models.IssueTypeGiantSize, regexp.MustCompile(`(?i)\bgiant[\s_.-]*size\b`)},
+	{models.IssueTypeOneShot, regexp.MustCompile(`(?i)\bone[\s_.-]*shot\b`)},
+	{models.IssueTypeSpecial, regexp.MustCompile(`(?i)\bspecial\b`)},
+}
+
+// DetectSpecial returns the special release kind (models.Special*) named in
+// filename, or "" for a regular issue.
+func DetectSpecial(filename string) string {
+	for _, p := range specialPatterns {
+		if p.re.MatchString(filename) {
+			return p.kind
+		}
+	}
+	return ""
+}
+
+// DetectIssueType returns the issue type (models.IssueType*) named in
+// filename, or "" for a regular issue.
+func DetectIssueType(filename string) string {
+	// Underscores are word characters to \b
+	filename = strings.ReplaceAll(filename, "_", " ")
+	for _, p := range issueTypePatterns {
+		if p.re.MatchString(filename) {
+			return p.kind
+		}
+	}
+	return ""
+}
+
+// tagRe matches the parenthesized and bracketed tags of a filename
+var tagRe = regexp.MustCompile(`[(\[]([^()\[\]]+)[)\]]`)
+
+// DetectLanguage returns the ISO 639-1 code of the language a tag of
+// filename names, as in "(French)", "[RUS]" or "(Spanish digital)", or ""
+// when there is none. Codes count only as a whole tag, since short words
+// like "it" or "por" are common in other tags.
+func DetectLanguage(filename string) string {
+	for _, m := range tagRe.FindAllStringSubmatch(filename, -1) {
+		tag := strings.TrimSpace(m[1])
+		if code := models.LanguageCode(tag); code != "" {
+			return code
+		}
+		for _, word := range strings.FieldsFunc(tag, func(r rune) bool {
+			return r == ' ' || r == '_' || r == ',' || r == '+' || r == '-'
+		}) {
+			if len([]rune(word)) < 5 {
+				continue
+			}
+			if code := models.LanguageCode(word); code != "" {
+				return code
+			}
+		}
+	}
+	return ""
+}
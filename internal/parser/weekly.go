@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+var (
+	// "prog 2350", "Prog. 0042", "Programme #12"
+	weeklyProgRe = regexp.MustCompile(`(?i)\bprog(?:ramme)?\.?\s*#?(\d+)\b`)
+	// The number following a weekly's title, as in "Judge Dredd Megazine 463"
+	weeklyNumberRe = regexp.MustCompile(`^[\s\-#.]*(\d+)\b`)
+)
+
+// weeklies are the anthologies recognized by their title and number alone,
+// without a "prog", with the title they are searched by.
+var weeklies = []struct {
+	re    *regexp.Regexp
+	title string
+}{
+	{regexp.MustCompile(`(?i)^2000\s*a\.?\s*d\b\.?`), "2000 AD"},
+	{regexp.MustCompile(`(?i)^judge\s+dredd\s*[:\-]?\s*(?:the\s+)?megazine\b`), "Judge Dredd Megazine"},
+}
+
+// ParseWeekly parses weekly anthology releases numbered by prog, such as
+// "2000AD prog 2350.cbz", "2000 AD Prog 0042 (1977).cbr" or "Judge Dredd
+// Megazine 463.cbz". These rarely carry a year, and the "2000" of "2000 AD"
+// is part of the title rather than one. The prog becomes the issue number.
+// It reports false for other filenames.
+func ParseWeekly(filename string) (*models.ParsedFilename, bool) {
+	name := filepath.Base(filename)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	var year string
+	if m := yearRe.FindStringSubmatch(name); m != nil {
+		year = m[1]
+	}
+	// Tags in parentheses or brackets follow the numbering
+	if i := strings.IndexAny(name, "(["); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(strings.ReplaceAll(name, "_", " "))
+
+	var title, number, note string
+	if m := weeklyProgRe.FindStringSubmatchIndex(name); m != nil {
+		title = strings.Join(strings.Fields(strings.Trim(name[:m[0]], " -.#")), " ")
+		number = trimNumber(name[m[2]:m[3]])
+		note = "prog " + number
+	}
+	for _, weekly := range weeklies {
+		if number != "" {
+			// "2000AD prog" is searched as "2000 AD", "2000 AD Sci-Fi Special
+			// prog" names another series
+			if loc := weekly.re.FindStringIndex(title); loc != nil && loc[1] == len(title) {
+				title = weekly.title
+			}
+			continue
+		}
+		loc := weekly.re.FindStringIndex(name)
+		if loc == nil {
+			continue
+		}
+		m := weeklyNumberRe.FindStringSubmatch(name[loc[1]:])
+		if m == nil {
+			return nil, false
+		}
+		title, number = weekly.title, trimNumber(m[1])
+		note = "issue " + number
+		break
+	}
+	if title == "" || number == "" {
+		return nil, false
+	}
+
+	return &models.ParsedFilename{
+		OriginalFilename: filename,
+		Title:            title,
+		IssueNumber:      number,
+		Year:             year,
+		Confidence:       "high",
+		Notes:            "weekly anthology, " + note,
+	}, true
+}
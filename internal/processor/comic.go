@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/library"
+	"comic-parser/internal/mapping"
+	"comic-parser/internal/media"
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/storage"
+)
+
+// comicType is the comic media type. It parses with the processor's parser,
+// searches its metadata provider and picks a match with its selector.
+type comicType struct {
+	p *Processor
+}
+
+// comicSearch is the Candidates of a comic item
+type comicSearch struct {
+	issues  []models.ComicVineIssue
+	retries int
+}
+
+// Comic returns the comic media type built from the processor's parser,
+// provider and selector, for running comics through RunBatch.
+func (p *Processor) Comic() media.Type {
+	return comicType{p: p}
+}
+
+func (c comicType) Name() string {
+	return media.Comic
+}
+
+func (c comicType) Matches(path string) bool {
+	return library.IsComicArchive(path)
+}
+
+// Parse resolves files known from imported mappings directly, setting the
+// item's Match, and parses the filename of the others. Collected editions
+// fail instead of being matched to an issue: they are identified as
+// volumes by the collection media type.
+func (c comicType) Parse(ctx context.Context, item *media.Item) error {
+	p := c.p
+	if p.mappings != nil {
+		m, err := p.mappings.Lookup(item.Filename)
+		if err != nil {
+			p.logger.Warn("mapping lookup failed", "file", item.Filename, "error", err)
+		}
+		if m != nil {
+			p.logger.Debug("mapped", "file", item.Filename, "comicvine_id", m.Issue.ID, "source", m.Source)
+			item.Match = mapping.Result(item.Filename, m)
+			return nil
+		}
+	}
+
+	if collection, ok := parser.ParseCollection(item.Filename); ok {
+		return models.Fail(models.ErrParseFailed, fmt.Errorf("parsing filename: collected edition (%s); identify it with -type %s", collection.Format, media.Collection))
+	}
+
+	p.logger.Debug("parsing filename", "file", item.Filename)
+	parsed, err := p.parser.Parse(ctx, &models.ParsedFilename{OriginalFilename: item.Filename, Path: item.Path})
+	if err != nil {
+		return models.Fail(models.ErrParseFailed, fmt.Errorf("parsing filename: %w", err))
+	}
+	parsed.Path = item.Path
+	item.Parsed = parsed
+
+	p.logger.Debug("parsed", "file", item.Filename, "title", parsed.Title, "issue", parsed.IssueNumber, "year", parsed.Year)
+	return nil
+}
+
+func (c comicType) Search(ctx context.Context, item *media.Item) error {
+	parsed := item.Parsed.(*models.ParsedFilename)
+	volumeID := c.applySeriesAlias(parsed)
+	title, issueNumber := searchTerms(parsed)
+	c.p.logger.Debug("searching ComicVine", "file", item.Filename, "title", title, "issue", issueNumber)
+
+	ctx = comicvine.WithRetryCount(ctx)
+	ctx = comicvine.WithVolumeHint(ctx, comicvine.VolumeHint{Year: parsed.Year, Publisher: parsed.Publisher, VolumeID: volumeID})
+	issues, err := c.p.cvClient.SearchIssues(ctx, title, issueNumber)
+	search := &comicSearch{issues: issues, retries: comicvine.RetryCount(ctx)}
+	item.Candidates = search
+	if err != nil {
+		return fmt.Errorf("searching comicvine: %w", err)
+	}
+
+	c.p.logger.Debug("searched ComicVine", "file", item.Filename, "results", len(issues), "retries", search.retries)
+	return nil
+}
+
+// applySeriesAlias replaces the parsed title with the series its alias
+// names, so the search and the selector both see the series' real name, and
+// returns the volume the alias pins (0 for none).
+func (c comicType) applySeriesAlias(parsed *models.ParsedFilename) int {
+	alias, ok := c.p.aliases[models.SeriesAliasKey(parsed.Title)]
+	if !ok || parsed.Title == "" {
+		return 0
+	}
+	c.p.logger.Debug("series alias", "title", parsed.Title, "series", alias.Series, "volume", alias.VolumeID)
+	parsed.Title = alias.Series
+	return alias.VolumeID
+}
+
+func (c comicType) Select(ctx context.Context, item *media.Item) error {
+	match, err := c.p.selector.Select(ctx, item.Parsed.(*models.ParsedFilename), item.Candidates.(*comicSearch).issues)
+	if err != nil {
+		return fmt.Errorf("matching results: %w", err)
+	}
+	item.Match = match
+	return nil
+}
+
+// Result builds the *models.ProcessingResult of item, with the hash of the
+// file when it exists locally. Successful matches are also enriched with
+// issue details when enabled, queued for review in review mode and have
+// their covers cached.
+func (c comicType) Result(ctx context.Context, item *media.Item, failed error) media.Result {
+	result := &models.ProcessingResult{
+		Filename:    item.Filename,
+		Path:        item.Path,
+		ProcessedAt: item.Started,
+	}
+	c.p.hashFile(ctx, result)
+	search, _ := item.Candidates.(*comicSearch)
+	if search != nil {
+		result.SearchRetries = search.retries
+	}
+	// Mapped files skip the timed steps
+	if item.Parsed != nil || failed != nil {
+		timings := item.Timings
+		result.StageTimings = &timings
+	}
+
+	if failed != nil {
+		result.Error = failed.Error()
+		result.ErrorCode = models.ErrorCodeOf(failed)
+		result.ProcessingTimeMS = time.Since(item.Started).Milliseconds()
+		return result
+	}
+	result.Success = true
+	result.Match = item.Match.(*models.MatchResult)
+	c.p.fetchDetails(ctx, result)
+	result.ProcessingTimeMS = time.Since(item.Started).Milliseconds()
+
+	if item.Parsed != nil {
+		c.p.queueForReview(ctx, result, search.issues)
+		c.logMatch(result)
+	}
+	c.p.downloadCovers(ctx, result)
+	return result
+}
+
+func (c comicType) logMatch(result *models.ProcessingResult) {
+	if issue := result.Match.SelectedIssue; issue != nil {
+		c.p.logger.Debug("matched", "file", result.Filename,
+			"series", issue.Volume.Name,
+			"issue", issue.IssueNumber,
+			"confidence", result.Match.MatchConfidence,
+			"url", result.Match.ComicVineURL)
+	} else {
+		c.p.logger.Debug("no match found", "file", result.Filename, "reasoning", result.Match.Reasoning)
+	}
+}
+
+// Store saves the result, unless review mode already stored its match.
+func (c comicType) Store(ctx context.Context, store *storage.Storage, result media.Result) error {
+	comic, ok := result.(*models.ProcessingResult)
+	if !ok {
+		return errors.New("processor: comic can only store processing results")
+	}
+	if c.p.cfg.ReviewQueue && comic.Match != nil {
+		return nil
+	}
+	return store.SaveResult(ctx, comic)
+}
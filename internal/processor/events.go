@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// EventType identifies what happened to a file during a batch.
+type EventType string
+
+// Batch event types
+const (
+	EventFileStarted  EventType = "file_started"
+	EventFileFinished EventType = "file_finished"
+	EventFileSkipped  EventType = "file_skipped"
+)
+
+// Event reports per-file progress from ProcessBatch, ParseBatch and RunBatch.
+type Event struct {
+	Type     EventType
+	Worker   int // index of the worker handling the file
+	Filename string
+	Time     time.Time
+
+	// Result is set on EventFileFinished for comics; its StageTimings
+	// break down the time spent parsing, searching and selecting.
+	Result *models.ProcessingResult
+	// Err is set on EventFileFinished when a parse-only file, or a file of
+	// another media type, failed.
+	Err error
+
+	// Progress is a snapshot taken when the event was emitted.
+	Progress models.BatchProgress
+}
+
+// EventHandler receives batch events. It is called from worker goroutines,
+// so it must be safe for concurrent use and should return quickly.
+type EventHandler func(Event)
+
+// OnEvent registers a handler for batch events, replacing any previous one.
+// Pass nil to stop receiving events. Register it before starting a batch.
+func (p *Processor) OnEvent(handler EventHandler) {
+	p.onEvent = handler
+}
+
+// EventChannel returns a handler that forwards events to ch, dropping events
+// when ch is full so a slow consumer never stalls processing.
+func EventChannel(ch chan<- Event) EventHandler {
+	return func(e Event) {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (p *Processor) emit(e Event) {
+	if p.onEvent == nil {
+		return
+	}
+	e.Time = time.Now()
+	e.Progress = p.GetProgress()
+	p.onEvent(e)
+}
@@ -0,0 +1,47 @@
+package processor
+
+import "context"
+
+// Pause stops batch workers from starting new files until Resume is called.
+// Files already in progress are finished.
+func (p *Processor) Pause() {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	if p.resumed == nil {
+		p.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets paused batch workers continue.
+func (p *Processor) Resume() {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	if p.resumed != nil {
+		close(p.resumed)
+		p.resumed = nil
+	}
+}
+
+// Paused reports whether the batch is paused.
+func (p *Processor) Paused() bool {
+	p.pauseMu.Lock()
+	defer p.pauseMu.Unlock()
+	return p.resumed != nil
+}
+
+// waitIfPaused blocks while the batch is paused. It reports false when ctx
+// is cancelled first.
+func (p *Processor) waitIfPaused(ctx context.Context) bool {
+	p.pauseMu.Lock()
+	resumed := p.resumed
+	p.pauseMu.Unlock()
+	if resumed == nil {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -4,12 +4,21 @@ package processor
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"comic-parser/internal/config"
+	"comic-parser/internal/covers"
+	"comic-parser/internal/library"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/mapping"
+	"comic-parser/internal/media"
 	"comic-parser/internal/models"
 	"comic-parser/internal/parser"
 	"comic-parser/internal/selector"
@@ -22,6 +31,15 @@ type CVClient interface {
 	Close()
 }
 
+// IssueDetailer fetches an issue with the details search results lack,
+// such as credits and story arcs.
+type IssueDetailer interface {
+	GetIssue(ctx context.Context, id int) (*models.ComicVineIssue, error)
+}
+
+// snapshotInterval is how often a running batch saves its progress.
+const snapshotInterval = 2 * time.Second
+
 // Processor orchestrates the comic parsing and matching workflow.
 type Processor struct {
 	cfg      *config.Config
@@ -29,11 +47,30 @@ type Processor struct {
 	cvClient CVClient
 	selector selector.Selector
 	store    *storage.Storage
-	verbose  bool
+	mappings *mapping.Set
+	aliases  map[string]models.SeriesAlias // by models.SeriesAliasKey
+	ignored  models.IgnoreList
+	covers   *covers.Cache
+	details  IssueDetailer
+	logger   *slog.Logger
+
+	// Queue parsed files for matching by sync
+	queueMatches bool
 
 	// Progress tracking
 	progressMu sync.Mutex
 	progress   models.BatchProgress
+	snapshotAt time.Time // when progress was last saved
+
+	// Set once the LLM budget is exhausted; remaining files are skipped.
+	llmExhausted atomic.Bool
+
+	// resumed is open while the batch is paused and closed by Resume
+	pauseMu sync.Mutex
+	resumed chan struct{}
+
+	// Optional per-file event callback
+	onEvent EventHandler
 }
 
 // NewProcessor creates a new processor.
@@ -44,10 +81,79 @@ func NewProcessor(cfg *config.Config, p parser.Parser, cvClient CVClient, sel se
 		cvClient: cvClient,
 		selector: sel,
 		store:    store,
-		verbose:  cfg.Verbose,
+		logger:   logging.Logger(logging.Processor),
 	}
 }
 
+// SetStore replaces the storage used to persist results, for example with a
+// run-scoped store from storage.WithRun.
+func (p *Processor) SetStore(store *storage.Storage) {
+	p.store = store
+}
+
+// SetMappings installs imported filename/hash mappings. Files they resolve
+// are matched directly, skipping parsing and the ComicVine search.
+func (p *Processor) SetMappings(set *mapping.Set) {
+	p.mappings = set
+}
+
+// SetSeriesAliases installs stored series aliases. A parsed title with an
+// alias is replaced with the alias's series before the search.
+func (p *Processor) SetSeriesAliases(aliases []models.SeriesAlias) {
+	p.aliases = make(map[string]models.SeriesAlias, len(aliases))
+	for _, alias := range aliases {
+		p.aliases[models.SeriesAliasKey(alias.Name)] = alias
+	}
+}
+
+// SetIgnoreRules installs the ignore list that FilterIgnored and Ignored
+// apply.
+func (p *Processor) SetIgnoreRules(rules models.IgnoreList) {
+	p.ignored = rules
+}
+
+// Ignored reports whether filename is on the ignore list.
+func (p *Processor) Ignored(filename string) bool {
+	_, ok := p.ignored.Match(filename)
+	return ok
+}
+
+// FilterIgnored returns the filenames not on the ignore list, and how many
+// were left out. Batches skip ignored files before they start, so they are
+// neither processed nor counted.
+func (p *Processor) FilterIgnored(filenames []string) ([]string, int) {
+	if len(p.ignored) == 0 {
+		return filenames, 0
+	}
+	kept := make([]string, 0, len(filenames))
+	for _, filename := range filenames {
+		if rule, ok := p.ignored.Match(filename); ok {
+			p.logger.Debug("ignoring file", "file", filename, "rule", rule.Pattern)
+			continue
+		}
+		kept = append(kept, filename)
+	}
+	return kept, len(filenames) - len(kept)
+}
+
+// SetCovers makes the processor download the cover images of every match
+// into cache.
+func (p *Processor) SetCovers(cache *covers.Cache) {
+	p.covers = cache
+}
+
+// SetDetails makes the processor enrich every match with the issue's
+// details from d, at one extra request per file.
+func (p *Processor) SetDetails(d IssueDetailer) {
+	p.details = d
+}
+
+// SetQueueMatches makes parse-only processing queue every parsed file for
+// matching later, as `comic-parser -offline` does.
+func (p *Processor) SetQueueMatches(on bool) {
+	p.queueMatches = on
+}
+
 // Close cleans up processor resources.
 func (p *Processor) Close() {
 	if p.cvClient != nil {
@@ -58,134 +164,314 @@ func (p *Processor) Close() {
 
 // ProcessFile processes a single comic filename.
 // It returns a ProcessingResult containing match information or an error description.
+// If the run's LLM budget is exhausted, it returns llm.ErrBudgetExceeded so the
+// caller can skip the file rather than record it as a failure.
 func (p *Processor) ProcessFile(ctx context.Context, filename string) (*models.ProcessingResult, error) {
-	startTime := time.Now()
+	result, err := p.Process(ctx, p.Comic(), filename)
+	return result.(*models.ProcessingResult), err
+}
 
-	result := &models.ProcessingResult{
-		Filename:    filename,
-		ProcessedAt: startTime,
+// Process identifies filename as media of type mt, timing its Parse, Search
+// and Select steps. A step's failure is recorded in the result; only an
+// exhausted LLM budget is returned as an error, along with the result so far.
+func (p *Processor) Process(ctx context.Context, mt media.Type, filename string) (media.Result, error) {
+	return p.run(ctx, mt, &media.Item{
+		Filename: filename,
+		Path:     library.LocalPath(filename),
+		Started:  time.Now(),
+	})
+}
+
+// run runs the steps of mt on item, starting with Search when it is
+// already parsed.
+func (p *Processor) run(ctx context.Context, mt media.Type, item *media.Item) (media.Result, error) {
+	ctx = llm.WithFilename(ctx, item.Filename)
+	steps := []struct {
+		run func(context.Context, *media.Item) error
+		ms  *int64
+	}{
+		{mt.Parse, &item.Timings.ParseMS},
+		{mt.Search, &item.Timings.SearchMS},
+		{mt.Select, &item.Timings.SelectMS},
+	}
+	if item.Parsed != nil {
+		steps = steps[1:]
+	}
+	for _, step := range steps {
+		// Parse sets the match of files known without searching
+		if item.Match != nil {
+			break
+		}
+		stageStart := time.Now()
+		err := step.run(ctx, item)
+		*step.ms = time.Since(stageStart).Milliseconds()
+		if err != nil {
+			result := mt.Result(ctx, item, err)
+			if p.checkBudget(err) {
+				return result, err
+			}
+			return result, nil
+		}
 	}
+	return mt.Result(ctx, item, nil), nil
+}
 
-	// Step 1: Parse the filename
-	if p.verbose {
-		log.Printf("Parsing filename: %s", filename)
+// queueForReview stores a match in review mode. High confidence matches are
+// accepted as they are; medium and low confidence ones are queued with their
+// candidates so a person can confirm, correct or reject them.
+func (p *Processor) queueForReview(ctx context.Context, result *models.ProcessingResult, candidates []models.ComicVineIssue) {
+	if !p.cfg.ReviewQueue || p.store == nil || result.Match == nil {
+		return
 	}
 
-	parsed, err := p.parser.Parse(ctx, &models.ParsedFilename{OriginalFilename: filename})
+	ctx = context.WithoutCancel(ctx)
+	var err error
+	switch result.Match.MatchConfidence {
+	case "medium", "low":
+		err = p.store.QueueReview(ctx, result, candidates)
+	default:
+		err = p.store.SaveResult(ctx, result)
+	}
 	if err != nil {
-		result.Error = fmt.Sprintf("parsing filename: %v", err)
-		result.ProcessingTimeMS = time.Since(startTime).Milliseconds()
-		return result, nil
+		p.logger.Warn("storing match for review failed", "file", result.Filename, "error", err)
 	}
+}
 
-	if p.verbose {
-		log.Printf("Parsed: title=%q issue=%q year=%q", parsed.Title, parsed.IssueNumber, parsed.Year)
+// hashFile records the hash and size of the result's local file, and the
+// stored files with the same content, such as an earlier download under
+// another name. Failures only cost the integrity check, so they are logged.
+func (p *Processor) hashFile(ctx context.Context, result *models.ProcessingResult) {
+	if result.Path == "" {
+		return
 	}
-
-	// Step 2: Search ComicVine
-	if p.verbose {
-		log.Printf("Searching ComicVine for: %s #%s", parsed.Title, parsed.IssueNumber)
+	hash, size, err := library.HashFile(result.Path)
+	if err != nil {
+		p.logger.Warn("hashing file failed", "file", result.Filename, "error", err)
+		return
 	}
-
-	issues, err := p.cvClient.SearchIssues(ctx, parsed.Title, parsed.IssueNumber)
+	result.FileHash, result.FileSize = hash, size
+	if p.store == nil {
+		return
+	}
+	same, err := p.store.FilenamesWithHash(ctx, hash, result.Filename)
 	if err != nil {
-		result.Error = fmt.Sprintf("searching comicvine: %v", err)
-		result.ProcessingTimeMS = time.Since(startTime).Milliseconds()
-		return result, nil
+		p.logger.Warn("looking up file hash failed", "file", result.Filename, "error", err)
+		return
+	}
+	if len(same) > 0 {
+		p.logger.Info("same file already stored", "file", result.Filename, "stored_as", same)
+		result.SameFileAs = same
 	}
+}
 
-	if p.verbose {
-		log.Printf("Found %d results from ComicVine", len(issues))
+// downloadCovers caches the cover images of the matched issue. Failures only
+// cost the preview, so they are logged rather than failing the file.
+func (p *Processor) downloadCovers(ctx context.Context, result *models.ProcessingResult) {
+	if p.covers == nil || result.Match == nil || result.Match.SelectedIssue == nil {
+		return
 	}
+	if _, err := p.covers.Download(ctx, result.Match.SelectedIssue); err != nil {
+		p.logger.Warn("caching covers failed", "file", result.Filename, "error", err)
+	}
+}
 
-	// Step 3: Match results using Selector
-	match, err := p.selector.Select(ctx, parsed, issues)
+// fetchDetails fills in the details of the matched issue. Failures only cost
+// the details, which the enrich command can fetch later, so they are logged
+// rather than failing the file.
+func (p *Processor) fetchDetails(ctx context.Context, result *models.ProcessingResult) {
+	if p.details == nil || result.Match == nil || result.Match.SelectedIssue == nil {
+		return
+	}
+	issue := result.Match.SelectedIssue
+	if issue.HasDetails() {
+		return
+	}
+	details, err := p.details.GetIssue(ctx, issue.ID)
 	if err != nil {
-		result.Error = fmt.Sprintf("matching results: %v", err)
-		result.ProcessingTimeMS = time.Since(startTime).Milliseconds()
-		return result, nil
-	}
-
-	result.Success = true
-	result.Match = match
-	result.ProcessingTimeMS = time.Since(startTime).Milliseconds()
-
-	if p.verbose {
-		if match.SelectedIssue != nil {
-			log.Printf("Matched: %s #%s (%s) - %s",
-				match.SelectedIssue.Volume.Name,
-				match.SelectedIssue.IssueNumber,
-				match.MatchConfidence,
-				match.ComicVineURL)
-		} else {
-			log.Printf("No match found: %s", match.Reasoning)
-		}
+		p.logger.Warn("fetching issue details failed", "file", result.Filename, "error", err)
+		return
 	}
-
-	return result, nil
+	issue.SetDetails(details)
 }
 
-// ProcessBatch processes multiple files concurrently using a worker pool.
-// Results are sent to the provided channel as they complete.
-func (p *Processor) ProcessBatch(ctx context.Context, filenames []string, resultChan chan<- *models.ProcessingResult) {
-	p.progress = models.BatchProgress{
-		Total: len(filenames),
+// searchTerms returns the ComicVine title and issue number to search for.
+// Special releases live in their own volumes ("Free Comic Book Day 2019",
+// "Avengers Preview") where the main series' issue number rarely applies, so
+// placeholder numbers like #0 are not used as a filter. Annuals, specials and
+// Giant-Size editions are searched for in their own volumes too; an annual
+// named by its year ("Batman Annual 2021") has no issue number to filter
+// on, and a one-shot is issue 1.
+func searchTerms(parsed *models.ParsedFilename) (title, issueNumber string) {
+	title, issueNumber = parsed.Title, parsed.IssueNumber
+
+	switch parsed.Special {
+	case "":
+		return issueTypeTerms(parsed.IssueType, title, issueNumber)
+	case models.SpecialFCBD:
+		title = strings.Join(strings.Fields("Free Comic Book Day "+parsed.Year+" "+title), " ")
+	case models.SpecialPreview:
+		title += " Preview"
+	case models.SpecialAshcan:
+		title += " Ashcan"
+	case models.SpecialPromo:
+		title += " Promo"
 	}
 
-	// Create worker pool
-	jobs := make(chan string, len(filenames))
-	var wg sync.WaitGroup
+	if n := strings.TrimLeft(issueNumber, "0"); n == "" {
+		issueNumber = ""
+	}
+	return title, issueNumber
+}
 
-	// Start workers
-	for i := 0; i < p.cfg.WorkerCount; i++ {
-		wg.Add(1)
-		go func(workerID int) {
-			defer wg.Done()
-			for filename := range jobs {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
+// issueTypeTerms narrows the search terms of a regular release to the
+// volume of its issue type.
+func issueTypeTerms(issueType, title, issueNumber string) (string, string) {
+	switch issueType {
+	case models.IssueTypeAnnual:
+		title = withSuffix(title, "Annual")
+		if isYear(issueNumber) {
+			issueNumber = ""
+		}
+	case models.IssueTypeSpecial:
+		title = withSuffix(title, "Special")
+	case models.IssueTypeGiantSize:
+		if !strings.HasPrefix(strings.ToLower(title), "giant-size ") {
+			title = "Giant-Size " + title
+		}
+	case models.IssueTypeOneShot:
+		if issueNumber == "" {
+			issueNumber = "1"
+		}
+	}
+	return title, issueNumber
+}
 
-				result, _ := p.ProcessFile(ctx, filename)
+// isYear reports whether n is a four-digit year rather than an issue number.
+func isYear(n string) bool {
+	if len(n) != 4 || !(strings.HasPrefix(n, "19") || strings.HasPrefix(n, "20")) {
+		return false
+	}
+	_, err := strconv.Atoi(n)
+	return err == nil
+}
 
-				p.progressMu.Lock()
-				p.progress.Processed++
-				if result.Success {
-					p.progress.Successful++
-				} else {
-					p.progress.Failed++
-				}
-				p.progressMu.Unlock()
+// withSuffix appends word to title unless it already ends with it.
+func withSuffix(title, word string) string {
+	if strings.HasSuffix(strings.ToLower(title), " "+strings.ToLower(word)) {
+		return title
+	}
+	return title + " " + word
+}
 
-				resultChan <- result
+// ProcessBatch processes multiple files concurrently using a worker pool.
+// Results are sent to the provided channel as they complete, one for each
+// issue of files that collect several.
+func (p *Processor) ProcessBatch(ctx context.Context, filenames []string, resultChan chan<- *models.ProcessingResult) {
+	p.runWorkers(ctx, filenames, func(ctx context.Context, filename string) (Event, error) {
+		results, err := p.processIssues(ctx, filename)
+		if err != nil {
+			return Event{}, err
+		}
+		var failed error
+		for _, result := range results {
+			resultChan <- result
+			if failed == nil {
+				failed = resultError(result)
 			}
-		}(i)
+		}
+		return Event{Result: results[0]}, failed
+	})
+}
+
+// processIssues processes filename like ProcessFile. When the file collects
+// a range of issues, each of the others is searched and matched too, and a
+// result is returned per issue. The first issue's result keeps the file's
+// name and path; the others are named by IssueFilename. All of them record
+// the file as their SourceFilename.
+func (p *Processor) processIssues(ctx context.Context, filename string) ([]*models.ProcessingResult, error) {
+	first, err := p.ProcessFile(ctx, filename)
+	if err != nil || first.Match == nil || len(first.Match.ParsedInfo.Issues) < 2 {
+		return []*models.ProcessingResult{first}, err
 	}
 
-	// Send jobs
-	for _, filename := range filenames {
-		jobs <- filename
+	parsed := first.Match.ParsedInfo
+	first.SourceFilename = filename
+	results := []*models.ProcessingResult{first}
+	for _, issue := range parsed.Issues[1:] {
+		issueParsed := parsed
+		issueParsed.IssueNumber = issue
+		r, err := p.run(ctx, p.Comic(), &media.Item{
+			Filename: IssueFilename(filename, issue),
+			Parsed:   &issueParsed,
+			Started:  time.Now(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		result := r.(*models.ProcessingResult)
+		result.SourceFilename = filename
+		results = append(results, result)
 	}
-	close(jobs)
+	return results, nil
+}
 
-	// Wait for completion
-	wg.Wait()
+// IssueFilename returns the name results are stored under for an issue of a
+// file collecting several, other than the first.
+func IssueFilename(filename, issue string) string {
+	return filename + " #" + issue
 }
 
-// GetProgress returns the current processing progress in a thread-safe manner.
-func (p *Processor) GetProgress() models.BatchProgress {
-	p.progressMu.Lock()
-	defer p.progressMu.Unlock()
-	return p.progress
+// RunBatch identifies files as media of type mt with the same workers,
+// checkpoints and progress as ProcessBatch, storing each result with the
+// type when the processor has a store. Results are also sent to results,
+// which may be nil.
+func (p *Processor) RunBatch(ctx context.Context, mt media.Type, filenames []string, results chan<- media.Result) {
+	p.runWorkers(ctx, filenames, func(ctx context.Context, filename string) (Event, error) {
+		result, err := p.Process(ctx, mt, filename)
+		if err != nil {
+			return Event{}, err
+		}
+		if p.store != nil {
+			if err := mt.Store(context.WithoutCancel(ctx), p.store, result); err != nil {
+				p.logger.Warn("storing result failed", "file", filename, "error", err)
+			}
+		}
+		if results != nil {
+			results <- result
+		}
+
+		e := Event{}
+		if comic, ok := result.(*models.ProcessingResult); ok {
+			e.Result = comic
+		}
+		if !result.Succeeded() {
+			e.Err = errors.New(result.FailureReason())
+		}
+		return e, e.Err
+	})
 }
 
-// ParseBatch processes files for parsing only and saves results to the database.
-func (p *Processor) ParseBatch(ctx context.Context, filenames []string, parserName string) {
+// resultError returns the failure of a comic result as an error, or nil.
+func resultError(result *models.ProcessingResult) error {
+	if result.Success {
+		return nil
+	}
+	return errors.New(result.Error)
+}
+
+// runWorkers runs handle on every file with the configured number of
+// workers, tracking progress, checkpoints and events. handle returns the
+// details of the file's finished event and its failure, or
+// llm.ErrBudgetExceeded to skip the file.
+func (p *Processor) runWorkers(ctx context.Context, filenames []string, handle func(ctx context.Context, filename string) (Event, error)) {
+	p.progressMu.Lock()
 	p.progress = models.BatchProgress{
-		Total: len(filenames),
+		Total:     len(filenames),
+		StartedAt: time.Now(),
+		Workers:   p.cfg.WorkerCount,
 	}
+	p.snapshotAt = time.Time{}
+	p.progressMu.Unlock()
 
 	// Create worker pool
 	jobs := make(chan string, len(filenames))
@@ -197,14 +483,29 @@ func (p *Processor) ParseBatch(ctx context.Context, filenames []string, parserNa
 		go func(workerID int) {
 			defer wg.Done()
 			for filename := range jobs {
-				select {
-				case <-ctx.Done():
+				if !p.waitIfPaused(ctx) {
 					return
-				default:
 				}
 
-				err := p.ProcessFileParseOnly(ctx, filename, parserName)
+				if p.llmExhausted.Load() {
+					p.markSkipped(workerID, filename)
+					continue
+				}
+
+				p.emit(Event{Type: EventFileStarted, Worker: workerID, Filename: filename})
+				p.checkpoint(ctx, filename, models.CheckpointInProgress, nil)
+				started := time.Now()
+				finished, err := handle(ctx, filename)
+				if errors.Is(err, llm.ErrBudgetExceeded) {
+					p.checkpoint(ctx, filename, models.CheckpointQueued, nil)
+					p.markSkipped(workerID, filename)
+					continue
+				}
 
+				var stages *models.StageTimings
+				if finished.Result != nil {
+					stages = finished.Result.StageTimings
+				}
 				p.progressMu.Lock()
 				p.progress.Processed++
 				if err == nil {
@@ -212,7 +513,24 @@ func (p *Processor) ParseBatch(ctx context.Context, filenames []string, parserNa
 				} else {
 					p.progress.Failed++
 				}
+				p.progress.Observe(time.Since(started), stages)
+				snapshot, due := p.progress, time.Since(p.snapshotAt) >= snapshotInterval
+				if due {
+					p.snapshotAt = time.Now()
+				}
 				p.progressMu.Unlock()
+				if due {
+					p.saveProgress(ctx, snapshot)
+				}
+				if err == nil {
+					p.checkpoint(ctx, filename, models.CheckpointDone, nil)
+				} else {
+					p.checkpoint(ctx, filename, models.CheckpointFailed, err)
+				}
+				finished.Type = EventFileFinished
+				finished.Worker = workerID
+				finished.Filename = filename
+				p.emit(finished)
 			}
 		}(i)
 	}
@@ -225,35 +543,108 @@ func (p *Processor) ParseBatch(ctx context.Context, filenames []string, parserNa
 
 	// Wait for completion
 	wg.Wait()
+	p.saveProgress(ctx, p.GetProgress())
+}
+
+// saveProgress persists a progress snapshot for `comic-parser status`. It
+// ignores cancellation so the snapshot of an interrupted run is still saved.
+func (p *Processor) saveProgress(ctx context.Context, progress models.BatchProgress) {
+	if p.store == nil {
+		return
+	}
+	progress.UpdatedAt = time.Now()
+	if err := p.store.SaveRunProgress(context.WithoutCancel(ctx), progress); err != nil {
+		p.logger.Warn("saving progress failed", "error", err)
+	}
+}
+
+// markSkipped records a file that was not processed because the LLM budget ran out.
+func (p *Processor) markSkipped(workerID int, filename string) {
+	p.progressMu.Lock()
+	p.progress.Skipped++
+	p.progressMu.Unlock()
+	p.emit(Event{Type: EventFileSkipped, Worker: workerID, Filename: filename})
+}
+
+// checkpoint records a file's state for resuming the run later. It ignores
+// cancellation so files interrupted by a shutdown are still recorded.
+func (p *Processor) checkpoint(ctx context.Context, filename, state string, cause error) {
+	if p.store == nil {
+		return
+	}
+	if err := p.store.SetCheckpoint(context.WithoutCancel(ctx), filename, state, cause); err != nil {
+		p.logger.Warn("recording checkpoint failed", "file", filename, "error", err)
+	}
+}
+
+// checkBudget reports whether err is an LLM budget error and, the first time
+// one is seen, halts further LLM work for the remainder of the run.
+func (p *Processor) checkBudget(err error) bool {
+	if !errors.Is(err, llm.ErrBudgetExceeded) {
+		return false
+	}
+	if p.llmExhausted.CompareAndSwap(false, true) {
+		p.logger.Warn("LLM budget exhausted; skipping remaining files")
+	}
+	return true
+}
+
+// Parser returns the filename parser of the processor.
+func (p *Processor) Parser() parser.Parser {
+	return p.parser
+}
+
+// GetProgress returns the current processing progress in a thread-safe manner.
+func (p *Processor) GetProgress() models.BatchProgress {
+	p.progressMu.Lock()
+	defer p.progressMu.Unlock()
+	return p.progress
+}
+
+// ParseBatch processes files for parsing only and saves results to the database.
+func (p *Processor) ParseBatch(ctx context.Context, filenames []string, parserName string) {
+	p.runWorkers(ctx, filenames, func(ctx context.Context, filename string) (Event, error) {
+		err := p.ProcessFileParseOnly(ctx, filename, parserName)
+		return Event{Err: err}, err
+	})
 }
 
 // ProcessFileParseOnly parses a single file and saves the result to the database.
 func (p *Processor) ProcessFileParseOnly(ctx context.Context, filename string, parserName string) error {
-	if p.verbose {
-		log.Printf("Parsing filename: %s", filename)
-	}
+	ctx = llm.WithFilename(ctx, filename)
+	p.logger.Debug("parsing filename", "file", filename)
 
-	parsed, err := p.parser.Parse(ctx, &models.ParsedFilename{OriginalFilename: filename})
+	path := library.LocalPath(filename)
+	parsed, err := p.parser.Parse(ctx, &models.ParsedFilename{OriginalFilename: filename, Path: path})
 	if err != nil {
-		if p.verbose {
-			log.Printf("Error parsing %s: %v", filename, err)
+		if p.checkBudget(err) {
+			return err
+		}
+		p.logger.Debug("parsing failed", "file", filename, "error", err)
+		if p.store != nil {
+			if recErr := p.store.RecordRunFailure(ctx, filename, models.Fail(models.ErrParseFailed, err)); recErr != nil {
+				p.logger.Warn("recording run failure failed", "file", filename, "error", recErr)
+			}
 		}
 		return err
 	}
 
-	if p.verbose {
-		log.Printf("Parsed: title=%q issue=%q", parsed.Title, parsed.IssueNumber)
-	}
+	parsed.Path = path
+
+	p.logger.Debug("parsed", "file", filename, "title", parsed.Title, "issue", parsed.IssueNumber)
 
 	if p.store != nil {
 		if err := p.store.SaveParsedFilename(ctx, parsed, parserName); err != nil {
-			if p.verbose {
-				log.Printf("Error saving parsed result for %s: %v", filename, err)
-			}
+			p.logger.Debug("saving parsed result failed", "file", filename, "error", err)
 			return err
 		}
+		if p.queueMatches {
+			if err := p.store.QueueMatch(ctx, filename); err != nil {
+				return err
+			}
+		}
 	} else {
-		log.Printf("Warning: No storage configured, result not saved for %s", filename)
+		p.logger.Warn("no storage configured, result not saved", "file", filename)
 	}
 
 	return nil
@@ -3,10 +3,20 @@ package processor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"comic-parser/internal/config"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/mapping"
+	"comic-parser/internal/media"
 	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
 )
 
 // MockParser implements parser.Parser
@@ -154,3 +164,481 @@ func TestProcessor_ProcessFile(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessor_ProcessBatch_BudgetExceeded(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.WorkerCount = 1
+
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			return nil, fmt.Errorf("LLM completion: %w", llm.ErrBudgetExceeded)
+		},
+	}
+
+	proc := NewProcessor(cfg, parserMock, &MockCVClient{}, &MockSelector{}, nil)
+
+	resultChan := make(chan *models.ProcessingResult, 3)
+	proc.ProcessBatch(context.Background(), []string{"a.cbz", "b.cbz", "c.cbz"}, resultChan)
+	close(resultChan)
+
+	if len(resultChan) != 0 {
+		t.Errorf("Expected no results for skipped files, got %d", len(resultChan))
+	}
+
+	progress := proc.GetProgress()
+	if progress.Skipped != 3 {
+		t.Errorf("Expected 3 skipped, got %d", progress.Skipped)
+	}
+	if progress.Failed != 0 {
+		t.Errorf("Expected 0 failed, got %d", progress.Failed)
+	}
+}
+
+func TestProcessor_ProcessFile_Mapped(t *testing.T) {
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			t.Error("parser should not be called for mapped files")
+			return nil, errors.New("unexpected parse")
+		},
+	}
+
+	proc := NewProcessor(config.DefaultConfig(), parserMock, &MockCVClient{}, &MockSelector{}, nil)
+	proc.SetMappings(mapping.NewSet([]models.Mapping{{
+		Kind:   models.MappingKindFilename,
+		Key:    "Saga 001.cbz",
+		Source: "community",
+		Issue:  models.ComicVineIssue{ID: 42, IssueNumber: "1", Volume: models.VolumeRef{ID: 7, Name: "Saga"}},
+	}}))
+
+	result, err := proc.ProcessFile(context.Background(), "/comics/Saga 001.cbz")
+	if err != nil {
+		t.Fatalf("ProcessFile: %v", err)
+	}
+	if !result.Success || result.Match == nil || result.Match.ComicVineID != 42 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Match.Provenance != "mapping:community" {
+		t.Errorf("Provenance = %q, want mapping:community", result.Match.Provenance)
+	}
+}
+
+func TestProcessor_ProcessFile_Collection(t *testing.T) {
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			t.Error("parser should not be called for collected editions")
+			return nil, errors.New("unexpected parse")
+		},
+	}
+	proc := NewProcessor(config.DefaultConfig(), parserMock, &MockCVClient{}, &MockSelector{}, nil)
+
+	result, err := proc.ProcessFile(context.Background(), "Saga Vol. 01 (2012) (TPB).cbz")
+	if err != nil {
+		t.Fatalf("ProcessFile: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "-type collection") {
+		t.Errorf("Expected the collected edition to fail, got %+v", result)
+	}
+}
+
+func TestProcessor_ProcessBatch_IssueRange(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.WorkerCount = 1
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "Batman", IssueNumber: "1", Issues: []string{"1", "2", "3"}}, nil
+		},
+	}
+	var searched []string
+	cvMock := &MockCVClient{
+		SearchIssuesFunc: func(ctx context.Context, title string, issueNumber string) ([]models.ComicVineIssue, error) {
+			searched = append(searched, issueNumber)
+			return []models.ComicVineIssue{{ID: 100 + len(searched), IssueNumber: issueNumber}}, nil
+		},
+	}
+	selectorMock := &MockSelector{
+		SelectFunc: func(ctx context.Context, parsed *models.ParsedFilename, candidates []models.ComicVineIssue) (*models.MatchResult, error) {
+			return &models.MatchResult{ParsedInfo: *parsed, SelectedIssue: &candidates[0], MatchConfidence: "high"}, nil
+		},
+	}
+	proc := NewProcessor(cfg, parserMock, cvMock, selectorMock, nil)
+
+	resultChan := make(chan *models.ProcessingResult, 5)
+	proc.ProcessBatch(context.Background(), []string{"Batman 001-003.cbz"}, resultChan)
+	close(resultChan)
+
+	var names []string
+	for result := range resultChan {
+		names = append(names, result.Filename)
+		if result.SourceFilename != "Batman 001-003.cbz" {
+			t.Errorf("%s: SourceFilename = %q", result.Filename, result.SourceFilename)
+		}
+		if result.Match.SelectedIssue.IssueNumber != result.Match.ParsedInfo.IssueNumber {
+			t.Errorf("%s matched issue %s", result.Filename, result.Match.SelectedIssue.IssueNumber)
+		}
+	}
+	want := []string{"Batman 001-003.cbz", "Batman 001-003.cbz #2", "Batman 001-003.cbz #3"}
+	if !reflect.DeepEqual(names, want) || !reflect.DeepEqual(searched, []string{"1", "2", "3"}) {
+		t.Errorf("Results %v after searching %v, want %v", names, searched, want)
+	}
+	if progress := proc.GetProgress(); progress.Processed != 1 || progress.Successful != 1 {
+		t.Errorf("Expected the file counted once, got %+v", progress)
+	}
+}
+
+func TestProcessor_Events(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.WorkerCount = 2
+
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "T", IssueNumber: "1"}, nil
+		},
+	}
+	selectorMock := &MockSelector{
+		SelectFunc: func(ctx context.Context, parsed *models.ParsedFilename, candidates []models.ComicVineIssue) (*models.MatchResult, error) {
+			return &models.MatchResult{MatchConfidence: "none"}, nil
+		},
+	}
+
+	proc := NewProcessor(cfg, parserMock, &MockCVClient{}, selectorMock, nil)
+
+	events := make(chan Event, 10)
+	proc.OnEvent(EventChannel(events))
+
+	resultChan := make(chan *models.ProcessingResult, 2)
+	proc.ProcessBatch(context.Background(), []string{"a.cbz", "b.cbz"}, resultChan)
+	close(events)
+
+	counts := make(map[EventType]int)
+	for e := range events {
+		counts[e.Type]++
+		if e.Type == EventFileFinished {
+			if e.Result == nil || e.Result.StageTimings == nil {
+				t.Errorf("Finished event for %s lacks result timings", e.Filename)
+			}
+			if e.Progress.Total != 2 {
+				t.Errorf("Expected progress total 2, got %d", e.Progress.Total)
+			}
+		}
+	}
+	if counts[EventFileStarted] != 2 || counts[EventFileFinished] != 2 {
+		t.Errorf("Unexpected event counts: %v", counts)
+	}
+}
+
+func TestProcessor_Pause(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.WorkerCount = 1
+
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "T", IssueNumber: "1"}, nil
+		},
+	}
+	proc := NewProcessor(cfg, parserMock, &MockCVClient{}, &MockSelector{}, nil)
+
+	proc.Pause()
+	if !proc.Paused() {
+		t.Fatal("Expected the processor paused")
+	}
+	done := make(chan struct{})
+	go func() {
+		proc.ParseBatch(context.Background(), []string{"a.cbz", "b.cbz"}, "regex")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Expected a paused batch not to finish")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if progress := proc.GetProgress(); progress.Processed != 0 {
+		t.Errorf("Expected no files processed while paused, got %d", progress.Processed)
+	}
+
+	proc.Resume()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the batch to finish after resuming")
+	}
+	if progress := proc.GetProgress(); progress.Processed != 2 {
+		t.Errorf("Expected 2 files processed, got %d", progress.Processed)
+	}
+
+	// Cancelling releases paused workers
+	proc.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	proc.ParseBatch(ctx, []string{"c.cbz"}, "regex")
+	if progress := proc.GetProgress(); progress.Processed != 0 {
+		t.Errorf("Expected no files processed after cancelling, got %d", progress.Processed)
+	}
+}
+
+func TestProcessor_ParseBatch_Checkpoints(t *testing.T) {
+	store, err := storage.Open(storage.BackendMemory, "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	run := &models.BatchRun{Mode: "parse", InputSource: "list.txt", Total: 3}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("CreateBatchRun: %v", err)
+	}
+	runStore := store.WithRun(run.ID)
+	filenames := []string{"a.cbz", "bad.cbz", "c.cbz"}
+	if err := runStore.QueueCheckpoints(ctx, filenames); err != nil {
+		t.Fatalf("QueueCheckpoints: %v", err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.WorkerCount = 1
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			switch input.OriginalFilename {
+			case "bad.cbz":
+				return nil, errors.New("unparseable")
+			case "c.cbz":
+				return nil, fmt.Errorf("LLM completion: %w", llm.ErrBudgetExceeded)
+			}
+			return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "A", IssueNumber: "1", Confidence: "high"}, nil
+		},
+	}
+	proc := NewProcessor(cfg, parserMock, &MockCVClient{}, &MockSelector{}, runStore)
+	proc.ParseBatch(ctx, filenames, "regex")
+
+	// The failed and the budget-skipped file are left for a resumed run
+	pending, err := store.PendingCheckpoints(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("PendingCheckpoints: %v", err)
+	}
+	if len(pending) != 2 || pending[0] != "bad.cbz" || pending[1] != "c.cbz" {
+		t.Errorf("Expected bad.cbz and c.cbz pending, got %v", pending)
+	}
+}
+
+func TestProcessor_ReviewQueue(t *testing.T) {
+	store, err := storage.Open(storage.BackendMemory, "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.ReviewQueue = true
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}},
+		{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}},
+	}
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "Saga", IssueNumber: "1"}, nil
+		},
+	}
+	cvMock := &MockCVClient{
+		SearchIssuesFunc: func(ctx context.Context, title, issueNumber string) ([]models.ComicVineIssue, error) {
+			return candidates, nil
+		},
+	}
+	selectorMock := &MockSelector{
+		SelectFunc: func(ctx context.Context, parsed *models.ParsedFilename, issues []models.ComicVineIssue) (*models.MatchResult, error) {
+			confidence := "high"
+			if parsed.OriginalFilename == "unsure.cbz" {
+				confidence = "medium"
+			}
+			return &models.MatchResult{OriginalFilename: parsed.OriginalFilename, ParsedInfo: *parsed, SelectedIssue: &issues[0], ComicVineID: issues[0].ID, MatchConfidence: confidence}, nil
+		},
+	}
+	proc := NewProcessor(cfg, parserMock, cvMock, selectorMock, store)
+
+	ctx := context.Background()
+	for _, filename := range []string{"sure.cbz", "unsure.cbz"} {
+		if _, err := proc.ProcessFile(ctx, filename); err != nil {
+			t.Fatalf("ProcessFile(%s): %v", filename, err)
+		}
+	}
+
+	// Both matches are stored; only the medium confidence one is queued
+	if names, _ := store.ListKnownFilenames(ctx); len(names) != 2 {
+		t.Errorf("Expected both results stored, got %v", names)
+	}
+	queue, err := store.ListPendingReviews(ctx)
+	if err != nil {
+		t.Fatalf("ListPendingReviews: %v", err)
+	}
+	if len(queue) != 1 || queue[0].Filename != "unsure.cbz" || len(queue[0].Candidates) != 2 {
+		t.Fatalf("Expected unsure.cbz queued with 2 candidates, got %+v", queue)
+	}
+}
+
+func TestProcessor_SeriesAlias(t *testing.T) {
+	parserMock := &MockParser{
+		ParseFunc: func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+			return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: "TMNT", IssueNumber: "1"}, nil
+		},
+	}
+	var searched string
+	cvMock := &MockCVClient{
+		SearchIssuesFunc: func(ctx context.Context, title, issueNumber string) ([]models.ComicVineIssue, error) {
+			searched = title
+			return []models.ComicVineIssue{{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Teenage Mutant Ninja Turtles"}}}, nil
+		},
+	}
+	var selected string
+	selectorMock := &MockSelector{
+		SelectFunc: func(ctx context.Context, parsed *models.ParsedFilename, issues []models.ComicVineIssue) (*models.MatchResult, error) {
+			selected = parsed.Title
+			return &models.MatchResult{OriginalFilename: parsed.OriginalFilename, ParsedInfo: *parsed, SelectedIssue: &issues[0], ComicVineID: issues[0].ID, MatchConfidence: "high"}, nil
+		},
+	}
+	proc := NewProcessor(config.DefaultConfig(), parserMock, cvMock, selectorMock, nil)
+	proc.SetSeriesAliases([]models.SeriesAlias{{Name: "TMNT", Series: "Teenage Mutant Ninja Turtles"}})
+
+	if _, err := proc.ProcessFile(context.Background(), "TMNT 001.cbz"); err != nil {
+		t.Fatalf("ProcessFile: %v", err)
+	}
+	if searched != "Teenage Mutant Ninja Turtles" || selected != "Teenage Mutant Ninja Turtles" {
+		t.Errorf("Expected the aliased series searched and selected, got %q and %q", searched, selected)
+	}
+}
+
+func TestProcessor_FilterIgnored(t *testing.T) {
+	proc := NewProcessor(config.DefaultConfig(), &MockParser{}, &MockCVClient{}, &MockSelector{}, nil)
+	filenames := []string{"/comics/Saga 001.cbz", "/comics/Saga 001.sample.cbz", "/comics/previews/Saga 002.cbz"}
+	if kept, ignored := proc.FilterIgnored(filenames); len(kept) != 3 || ignored != 0 {
+		t.Errorf("Expected nothing ignored without rules, got %v, %d", kept, ignored)
+	}
+
+	proc.SetIgnoreRules(models.IgnoreList{{Pattern: "*.sample.cbz"}, {Pattern: "/comics/previews/*"}})
+	kept, ignored := proc.FilterIgnored(filenames)
+	if !reflect.DeepEqual(kept, []string{"/comics/Saga 001.cbz"}) || ignored != 2 {
+		t.Errorf("FilterIgnored = %v, %d, want only Saga 001.cbz kept", kept, ignored)
+	}
+	if !proc.Ignored("Saga 003.sample.cbz") || proc.Ignored("Saga 003.cbz") {
+		t.Errorf("Ignored should follow the rules")
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		parsed    models.ParsedFilename
+		wantTitle string
+		wantIssue string
+	}{
+		{models.ParsedFilename{Title: "Saga", IssueNumber: "1"}, "Saga", "1"},
+		{models.ParsedFilename{Title: "Saga", IssueNumber: "0"}, "Saga", "0"},
+		{models.ParsedFilename{Title: "Avengers", IssueNumber: "1", Year: "2019", Special: models.SpecialFCBD}, "Free Comic Book Day 2019 Avengers", "1"},
+		{models.ParsedFilename{Title: "Avengers", Special: models.SpecialFCBD}, "Free Comic Book Day Avengers", ""},
+		{models.ParsedFilename{Title: "Spawn", IssueNumber: "00", Special: models.SpecialPromo}, "Spawn Promo", ""},
+		{models.ParsedFilename{Title: "Saga", IssueNumber: "0", Special: models.SpecialPreview}, "Saga Preview", ""},
+		{models.ParsedFilename{Title: "Bone", IssueNumber: "1", Special: models.SpecialAshcan}, "Bone Ashcan", "1"},
+		{models.ParsedFilename{Title: "Batman", IssueNumber: "2021", IssueType: models.IssueTypeAnnual}, "Batman Annual", ""},
+		{models.ParsedFilename{Title: "X-Men Annual", IssueNumber: "3", IssueType: models.IssueTypeAnnual}, "X-Men Annual", "3"},
+		{models.ParsedFilename{Title: "X-Men", IssueNumber: "1", IssueType: models.IssueTypeGiantSize}, "Giant-Size X-Men", "1"},
+		{models.ParsedFilename{Title: "Giant-Size X-Men", IssueNumber: "1", IssueType: models.IssueTypeGiantSize}, "Giant-Size X-Men", "1"},
+		{models.ParsedFilename{Title: "Wolverine", IssueType: models.IssueTypeOneShot}, "Wolverine", "1"},
+		{models.ParsedFilename{Title: "Spawn", IssueNumber: "1", IssueType: models.IssueTypeSpecial}, "Spawn Special", "1"},
+	}
+
+	for _, tt := range tests {
+		title, issue := searchTerms(&tt.parsed)
+		if title != tt.wantTitle || issue != tt.wantIssue {
+			t.Errorf("searchTerms(%+v) = %q, %q; want %q, %q", tt.parsed, title, issue, tt.wantTitle, tt.wantIssue)
+		}
+	}
+}
+
+// stubType is a media type whose files fail to search when named "bad"
+type stubType struct {
+	mu       sync.Mutex
+	searched []string
+	stored   []string
+}
+
+type stubResult struct{ err string }
+
+func (r stubResult) Succeeded() bool       { return r.err == "" }
+func (r stubResult) FailureReason() string { return r.err }
+
+func (s *stubType) Name() string             { return "stub" }
+func (s *stubType) Matches(path string) bool { return true }
+func (s *stubType) Parse(ctx context.Context, item *media.Item) error {
+	item.Parsed = item.Filename
+	if item.Filename == "known" {
+		item.Match = "known"
+	}
+	return nil
+}
+
+func (s *stubType) Search(ctx context.Context, item *media.Item) error {
+	s.mu.Lock()
+	s.searched = append(s.searched, item.Filename)
+	s.mu.Unlock()
+	if item.Filename == "bad" {
+		return errors.New("searching stub: down")
+	}
+	return nil
+}
+
+func (s *stubType) Select(ctx context.Context, item *media.Item) error {
+	item.Match = item.Filename
+	return nil
+}
+
+func (s *stubType) Result(ctx context.Context, item *media.Item, failed error) media.Result {
+	if failed != nil {
+		return stubResult{err: failed.Error()}
+	}
+	return stubResult{}
+}
+
+func (s *stubType) Store(ctx context.Context, store *storage.Storage, result media.Result) error {
+	s.mu.Lock()
+	s.stored = append(s.stored, fmt.Sprint(result))
+	s.mu.Unlock()
+	return nil
+}
+
+func TestProcessor_RunBatch(t *testing.T) {
+	store, err := storage.Open(storage.BackendMemory, "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.WorkerCount = 2
+	proc := NewProcessor(cfg, nil, nil, nil, store)
+
+	var mu sync.Mutex
+	var failures []string
+	proc.OnEvent(func(e Event) {
+		if e.Type == EventFileFinished && e.Err != nil {
+			mu.Lock()
+			failures = append(failures, e.Filename+": "+e.Err.Error())
+			mu.Unlock()
+		}
+	})
+
+	mt := &stubType{}
+	results := make(chan media.Result, 3)
+	proc.RunBatch(context.Background(), mt, []string{"good", "bad", "known"}, results)
+	close(results)
+
+	if len(results) != 3 || len(mt.stored) != 3 {
+		t.Errorf("Expected 3 results sent and stored, got %d and %d", len(results), len(mt.stored))
+	}
+	sort.Strings(mt.searched)
+	if len(mt.searched) != 2 || mt.searched[0] != "bad" || mt.searched[1] != "good" {
+		t.Errorf("Expected only files without a match from Parse searched, got %v", mt.searched)
+	}
+	if progress := proc.GetProgress(); progress.Successful != 2 || progress.Failed != 1 {
+		t.Errorf("Unexpected progress %+v", progress)
+	}
+	if len(failures) != 1 || failures[0] != "bad: searching stub: down" {
+		t.Errorf("Unexpected failure events %v", failures)
+	}
+}
@@ -4,122 +4,147 @@ package prompts
 
 import (
 	"encoding/json"
-	"fmt"
 
+	"comic-parser/internal/llm"
 	"comic-parser/internal/models"
 )
 
+var confidenceLevels = []string{"high", "medium", "low"}
+
+// FilenameParseSchema is the structured response to FilenameParsePrompt.
+var FilenameParseSchema = llm.Schema{
+	Name:        "parsed_filename",
+	Description: "Record the details extracted from the comic filename",
+	Properties: map[string]llm.Property{
+		"title":         {Type: "string", Description: "The main comic series title, cleaned up"},
+		"issue_number":  {Type: "string", Description: "The issue number as a simple string"},
+		"year":          {Type: "string", Description: "Publication year if present, or empty string"},
+		"publisher":     {Type: "string", Description: "Publisher if identifiable, or empty string"},
+		"volume_number": {Type: "string", Description: "Volume number if present, or empty string"},
+		"chapter":       {Type: "string", Description: "Manga chapter number if present, or empty string"},
+		"confidence":    {Type: "string", Enum: confidenceLevels},
+		"notes":         {Type: "string", Description: "Notes about ambiguity or special cases"},
+		"special":       {Type: "string", Enum: []string{"", models.SpecialFCBD, models.SpecialPreview, models.SpecialAshcan, models.SpecialPromo}},
+		"issue_type":    {Type: "string", Enum: []string{"", models.IssueTypeAnnual, models.IssueTypeOneShot, models.IssueTypeSpecial, models.IssueTypeGiantSize}},
+	},
+	Required: []string{"title", "issue_number", "confidence"},
+}
+
+// FilenameBatchParseSchema is the structured response to
+// FilenameBatchParsePrompt, decoded into BatchParseResponse. Each result is
+// a FilenameParseSchema object with the index of its filename.
+var FilenameBatchParseSchema = llm.Schema{
+	Name:        "parsed_filenames",
+	Description: "Record the details extracted from each comic filename",
+	Properties: map[string]llm.Property{
+		"results": {Type: "array", Description: "One entry per filename", Items: batchParseItemSchema()},
+	},
+	Required: []string{"results"},
+}
+
+func batchParseItemSchema() *llm.Schema {
+	item := llm.Schema{
+		Name:       "parsed_filename",
+		Properties: map[string]llm.Property{"index": {Type: "integer", Description: "The index of the filename"}},
+		Required:   append([]string{"index"}, FilenameParseSchema.Required...),
+	}
+	for name, prop := range FilenameParseSchema.Properties {
+		item.Properties[name] = prop
+	}
+	return &item
+}
+
+// MatchSchema is the structured response to ResultMatchPrompt, decoded
+// into MatchResponse.
+var MatchSchema = llm.Schema{
+	Name:        "select_match",
+	Description: "Record the best matching search result",
+	Properties: map[string]llm.Property{
+		"selected_index":   {Type: "integer", Description: "Index of the best match, or -1 if no good match"},
+		"match_confidence": {Type: "string", Enum: []string{"high", "medium", "low", "none"}},
+		"reasoning":        {Type: "string", Description: "Brief explanation of the choice"},
+	},
+	Required: []string{"selected_index", "match_confidence"},
+}
+
+// Candidate is a search result as presented to the match prompt.
+type Candidate struct {
+	Index       int    `json:"index"`
+	ID          int    `json:"id"`
+	VolumeName  string `json:"volume_name"`
+	StartYear   string `json:"volume_start_year,omitempty"`
+	IssueNumber string `json:"issue_number"`
+	CoverDate   string `json:"cover_date"`
+	Publisher   string `json:"publisher,omitempty"`
+	URL         string `json:"url"`
+}
+
+// ParseData is the data of the parse prompt template.
+type ParseData struct {
+	Filename string
+}
+
+// BatchFilename is a filename of the batch parse prompt.
+type BatchFilename struct {
+	Index    int
+	Filename string
+}
+
+// ParseBatchData is the data of the batch parse prompt template.
+type ParseBatchData struct {
+	Filenames []BatchFilename
+}
+
+// MatchData is the data of the match prompt template.
+type MatchData struct {
+	Parsed     models.ParsedFilename
+	Candidates []Candidate
+	Results    string                   // Candidates as indented JSON
+	Examples   []models.MatchCorrection // the user's past corrections, most relevant first
+}
+
 // FilenameParsePrompt generates the prompt for parsing a comic filename.
 // This prompt instructs the LLM to extract structured information from various filename formats.
 func FilenameParsePrompt(filename string) string {
-	return fmt.Sprintf(`You are a comic book filename parser. Your task is to extract structured information from comic book archive filenames (CBR/CBZ files).
-
-Analyze the following filename and extract the comic title and issue number. Comic filenames come in many formats, such as:
-- "Amazing Spider-Man 001 (2018).cbz"
-- "Batman - The Long Halloween 01.cbr"  
-- "X-Men v2 #45 (1995).cbz"
-- "Saga 001 (2012) (Digital) (Zone-Empire).cbr"
-- "The Walking Dead #100 (2012) (Digital).cbz"
-- "Action_Comics_1000_(2018).cbr"
-- "Invincible 001 (2003) (digital) (Son of Ultron-Empire).cbr"
-
-Key patterns to recognize:
-- Issue numbers may be preceded by #, No., or nothing
-- Issue numbers may be zero-padded (001, 01, 1)
-- Volume indicators: v1, v2, Vol. 1, Volume 2
-- Years in parentheses: (2018), (1995)
-- Publisher names sometimes appear
-- Digital/scan group tags in parentheses at the end
-- Underscores or hyphens used as word separators
-
-FILENAME TO PARSE:
-%s
-
-Respond with ONLY a JSON object in this exact format (no markdown, no explanation):
-{
-  "title": "The main comic series title, cleaned up (e.g., 'Amazing Spider-Man', not 'Amazing_Spider-Man')",
-  "issue_number": "The issue number as a simple string (e.g., '1', '100', '45.1')",
-  "year": "Publication year if present, or empty string",
-  "publisher": "Publisher if identifiable, or empty string",
-  "volume_number": "Volume number if present (e.g., '2' for v2), or empty string",
-  "confidence": "high/medium/low - your confidence in the extraction",
-  "notes": "Any relevant notes about ambiguity or special cases"
-}`, filename)
+	return render(ParseTemplate, ParseData{Filename: filename})
 }
 
-// ResultMatchPrompt generates the prompt for selecting the best ComicVine match.
-// It presents the LLM with parsed information and search results to make an informed choice.
-func ResultMatchPrompt(parsed models.ParsedFilename, results []models.ComicVineIssue) string {
-	// Prepare a simplified view of the results for the LLM
-	type SimpleResult struct {
-		Index       int    `json:"index"`
-		ID          int    `json:"id"`
-		VolumeName  string `json:"volume_name"`
-		IssueNumber string `json:"issue_number"`
-		CoverDate   string `json:"cover_date"`
-		Publisher   string `json:"publisher,omitempty"`
-		URL         string `json:"url"`
+// FilenameBatchParsePrompt generates the prompt for parsing several comic
+// filenames in one request. Each result refers to its filename by index.
+func FilenameBatchParsePrompt(filenames []string) string {
+	data := ParseBatchData{Filenames: make([]BatchFilename, len(filenames))}
+	for i, filename := range filenames {
+		data.Filenames[i] = BatchFilename{Index: i, Filename: filename}
 	}
+	return render(ParseBatchTemplate, data)
+}
 
-	simpleResults := make([]SimpleResult, len(results))
+// ResultMatchPrompt generates the prompt for selecting the best ComicVine match.
+// It presents the LLM with parsed information and search results to make an informed choice,
+// along with any of the user's past corrections as examples.
+func ResultMatchPrompt(parsed models.ParsedFilename, results []models.ComicVineIssue, examples ...models.MatchCorrection) string {
+	candidates := make([]Candidate, len(results))
 	for i, r := range results {
-		simpleResults[i] = SimpleResult{
+		candidates[i] = Candidate{
 			Index:       i,
 			ID:          r.ID,
 			VolumeName:  r.Volume.Name,
+			StartYear:   r.Volume.StartYear,
 			IssueNumber: r.IssueNumber,
-			CoverDate:   r.CoverDate,
+			CoverDate:   r.CoverDate.String(),
 			Publisher:   r.Volume.Publisher,
 			URL:         r.SiteDetailURL,
 		}
 	}
 
-	resultsJSON, _ := json.MarshalIndent(simpleResults, "", "  ")
-
-	return fmt.Sprintf(`You are a comic book matching expert. Your task is to select the best match from ComicVine search results for a given comic file.
-
-ORIGINAL FILENAME: %s
-
-PARSED INFORMATION:
-- Title: %s
-- Issue Number: %s
-- Year: %s
-- Publisher: %s
-- Volume: %s
-- Parser Notes: %s
-
-COMICVINE SEARCH RESULTS:
-%s
-
-Your task:
-1. Analyze each result against the parsed information
-2. Select the BEST match based on:
-   - Title/volume name similarity (most important)
-   - Issue number match (must match exactly or very closely)
-   - Year/cover date alignment (if available)
-   - Publisher match (if known)
-3. If no result is a good match, indicate that
-
-Consider these matching rules:
-- The volume name should match the comic title (accounting for variations like "The Amazing Spider-Man" vs "Amazing Spider-Man")
-- Issue numbers must match (01 = 1 = 001)
-- If a year is specified, the cover_date should be close (within 1-2 years to account for publication delays)
-- Some comics have multiple volumes/series with the same name - prefer the one with matching year
-
-Respond with ONLY a JSON object in this exact format (no markdown, no explanation):
-{
-  "selected_index": <index number of best match, or -1 if no good match>,
-  "match_confidence": "high/medium/low/none",
-  "reasoning": "Brief explanation of why this match was selected or why no match was found"
-}`,
-		parsed.OriginalFilename,
-		parsed.Title,
-		parsed.IssueNumber,
-		parsed.Year,
-		parsed.Publisher,
-		parsed.VolumeNumber,
-		parsed.Notes,
-		string(resultsJSON))
+	resultsJSON, _ := json.MarshalIndent(candidates, "", "  ")
+
+	return render(MatchTemplate, MatchData{
+		Parsed:     parsed,
+		Candidates: candidates,
+		Results:    string(resultsJSON),
+		Examples:   examples,
+	})
 }
 
 // MatchResponse represents the LLM's response to the matching prompt.
@@ -128,3 +153,14 @@ type MatchResponse struct {
 	MatchConfidence string `json:"match_confidence"`
 	Reasoning       string `json:"reasoning"`
 }
+
+// BatchParseResponse represents the LLM's response to the batch parse prompt.
+type BatchParseResponse struct {
+	Results []BatchParseResult `json:"results"`
+}
+
+// BatchParseResult is the parse of the filename at Index.
+type BatchParseResult struct {
+	Index int `json:"index"`
+	models.ParsedFilename
+}
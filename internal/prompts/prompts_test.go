@@ -1,6 +1,9 @@
 package prompts
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -22,6 +25,16 @@ func TestFilenameParsePrompt(t *testing.T) {
 	}
 }
 
+func TestFilenameBatchParsePrompt(t *testing.T) {
+	prompt := FilenameBatchParsePrompt([]string{"Saga 001.cbz", "Batman 002.cbz"})
+	if !strings.Contains(prompt, "FILENAMES TO PARSE:\n0: Saga 001.cbz\n1: Batman 002.cbz\n") {
+		t.Errorf("FilenameBatchParsePrompt() does not list the filenames by index:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, `"results": [`) {
+		t.Error("FilenameBatchParsePrompt() missing the results format")
+	}
+}
+
 func TestResultMatchPrompt(t *testing.T) {
 	parsed := models.ParsedFilename{
 		OriginalFilename: "Test Comic 001.cbz",
@@ -68,3 +81,72 @@ func TestResultMatchPrompt(t *testing.T) {
 		t.Error("ResultMatchPrompt() missing result ID")
 	}
 }
+
+func TestResultMatchPromptExamples(t *testing.T) {
+	parsed := models.ParsedFilename{OriginalFilename: "Saga 003.cbz", Title: "Saga", IssueNumber: "3"}
+	results := []models.ComicVineIssue{{ID: 3, IssueNumber: "3", Volume: models.VolumeRef{Name: "Saga"}}}
+
+	if prompt := ResultMatchPrompt(parsed, results); strings.Contains(prompt, "PAST CORRECTIONS") {
+		t.Error("ResultMatchPrompt() without examples has a PAST CORRECTIONS section")
+	}
+
+	prompt := ResultMatchPrompt(parsed, results,
+		models.MatchCorrection{
+			Filename: "Saga 001.cbz", Title: "Saga", IssueNumber: "1",
+			ComicVineID: 1, VolumeName: "Saga", VolumeStartYear: "2012", CorrectedIssue: "1",
+		},
+		models.MatchCorrection{Filename: "Saga Preview.cbz", Title: "Saga", IssueNumber: "0"},
+	)
+	for _, want := range []string{
+		"PAST CORRECTIONS:",
+		"- Saga 001.cbz (parsed as Saga #1) -> Saga (2012) #1\n",
+		"- Saga Preview.cbz (parsed as Saga #0) -> no match\n",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("ResultMatchPrompt() missing %q", want)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	defer ResetTemplates()
+
+	// The documentation comment of the built-in templates is not sent
+	if prompt := FilenameParsePrompt("Saga 001.cbz"); !strings.HasPrefix(prompt, "You are a comic book filename parser") {
+		t.Errorf("Unexpected start of the built-in prompt: %.60q", prompt)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ParseTemplate), []byte("Parse {{.Filename}} the house way.\n"), 0644)
+	names, err := LoadDir(dir)
+	if err != nil || !reflect.DeepEqual(names, []string{ParseTemplate}) {
+		t.Fatalf("LoadDir = %v, %v", names, err)
+	}
+	if prompt := FilenameParsePrompt("Saga 001.cbz"); prompt != "Parse Saga 001.cbz the house way." {
+		t.Errorf("FilenameParsePrompt() = %q, want the override", prompt)
+	}
+	parsed := models.ParsedFilename{OriginalFilename: "Saga 001.cbz", Title: "Saga"}
+	if prompt := ResultMatchPrompt(parsed, nil); !strings.Contains(prompt, "COMICVINE SEARCH RESULTS") {
+		t.Error("ResultMatchPrompt() should keep the built-in template")
+	}
+
+	for _, bad := range []string{"{{.Filename", "{{.Title}}"} {
+		os.WriteFile(filepath.Join(dir, ParseTemplate), []byte(bad), 0644)
+		if _, err := LoadDir(dir); err == nil {
+			t.Errorf("LoadDir should refuse %q", bad)
+		}
+	}
+	if prompt := FilenameParsePrompt("Saga 001.cbz"); prompt != "Parse Saga 001.cbz the house way." {
+		t.Errorf("A failed load replaced the template: %q", prompt)
+	}
+
+	ResetTemplates()
+	if prompt := FilenameParsePrompt("Saga 001.cbz"); !strings.Contains(prompt, "FILENAME TO PARSE:") {
+		t.Error("ResetTemplates() should restore the built-in template")
+	}
+
+	var b strings.Builder
+	if err := WriteBuiltin(&b, MatchTemplate); err != nil || !strings.Contains(b.String(), "{{.Results}}") {
+		t.Errorf("WriteBuiltin = %v", err)
+	}
+}
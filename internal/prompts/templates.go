@@ -0,0 +1,153 @@
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
+)
+
+// Template file names, both built in and in an override directory.
+const (
+	ParseTemplate      = "parse.tmpl"
+	ParseBatchTemplate = "parse_batch.tmpl"
+	MatchTemplate      = "match.tmpl"
+)
+
+// Names lists the prompt templates.
+var Names = []string{ParseTemplate, ParseBatchTemplate, MatchTemplate}
+
+//go:embed templates
+var builtinFiles embed.FS
+
+var (
+	builtin = mustParseBuiltin()
+
+	mu        sync.RWMutex
+	overrides = map[string]*template.Template{}
+)
+
+func mustParseBuiltin() map[string]*template.Template {
+	templates := make(map[string]*template.Template)
+	for _, name := range Names {
+		data, err := builtinFiles.ReadFile("templates/" + name)
+		if err != nil {
+			panic(err) // the embedded templates are always there
+		}
+		templates[name] = template.Must(template.New(name).Parse(string(data)))
+	}
+	return templates
+}
+
+// DefaultDir returns where prompt templates are read from when no prompts
+// directory is configured: xander/prompts in the user's configuration
+// directory, such as ~/.config on Linux.
+func DefaultDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "xander", "prompts"), nil
+}
+
+// LoadDir replaces the built-in prompt templates with those of the same name
+// in dir, returning the names it loaded. Each template is tried on sample
+// data, so mistakes such as unknown variables show up here rather than
+// during a batch. Templates missing from dir keep the built-in version; on
+// error none are replaced.
+func LoadDir(dir string) ([]string, error) {
+	loaded := make(map[string]*template.Template)
+	var names []string
+	for _, name := range Names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("prompt template %s: %w", name, err)
+		}
+		if err := tmpl.Execute(io.Discard, sampleData(name)); err != nil {
+			return nil, fmt.Errorf("prompt template %s: %w", name, err)
+		}
+		loaded[name] = tmpl
+		names = append(names, name)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for name, tmpl := range loaded {
+		overrides[name] = tmpl
+	}
+	return names, nil
+}
+
+// ResetTemplates restores the built-in prompt templates.
+func ResetTemplates() {
+	mu.Lock()
+	defer mu.Unlock()
+	overrides = map[string]*template.Template{}
+}
+
+// WriteBuiltin writes the built-in template name to w, as a starting point
+// for an override.
+func WriteBuiltin(w io.Writer, name string) error {
+	data, err := builtinFiles.ReadFile("templates/" + name)
+	if err != nil {
+		return fmt.Errorf("unknown prompt template %q", name)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// render executes the named template, falling back to the built-in one if
+// an override fails.
+func render(name string, data any) string {
+	mu.RLock()
+	tmpl := overrides[name]
+	mu.RUnlock()
+
+	var b strings.Builder
+	if tmpl != nil {
+		err := tmpl.Execute(&b, data)
+		if err == nil {
+			return strings.TrimSpace(b.String())
+		}
+		logging.Logger(logging.LLM).Warn("prompt template failed; using the built-in one", "template", name, "error", err)
+		b.Reset()
+	}
+	if err := builtin[name].Execute(&b, data); err != nil {
+		panic(err) // the built-in templates are tested
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// sampleData returns data to try the template name on.
+func sampleData(name string) any {
+	switch name {
+	case ParseTemplate:
+		return ParseData{Filename: "Saga 001 (2012).cbz"}
+	case ParseBatchTemplate:
+		return ParseBatchData{Filenames: []BatchFilename{{Index: 0, Filename: "Saga 001 (2012).cbz"}}}
+	}
+	return MatchData{
+		Parsed:     models.ParsedFilename{OriginalFilename: "Saga 001 (2012).cbz", Title: "Saga", IssueNumber: "1", Year: "2012"},
+		Candidates: []Candidate{{Index: 0, ID: 1, VolumeName: "Saga", StartYear: "2012", IssueNumber: "1"}},
+		Results:    "[]",
+		Examples: []models.MatchCorrection{{
+			Filename: "Saga 002 (2012).cbz", Title: "Saga", IssueNumber: "2", Year: "2012",
+			ComicVineID: 2, VolumeName: "Saga", VolumeStartYear: "2012", CorrectedIssue: "2",
+		}},
+	}
+}
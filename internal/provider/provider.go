@@ -0,0 +1,110 @@
+// Package provider defines the interface comic metadata sources implement and
+// keeps a registry of them, so the pipeline and TUI can search any registered
+// source by name.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"comic-parser/internal/comicvine"
+	"comic-parser/internal/config"
+	"comic-parser/internal/metron"
+	"comic-parser/internal/models"
+)
+
+// Built-in provider names
+const (
+	ComicVine = comicvine.ProviderName
+	Metron    = metron.ProviderName
+
+	// Default is used when no provider is configured
+	Default = ComicVine
+)
+
+// MetadataProvider is a source of series and issue metadata. Results use the
+// ComicVine-shaped models so the selector and storage work with any provider.
+type MetadataProvider interface {
+	// Name returns the provider's registered name.
+	Name() string
+	// SearchSeries finds series (volumes) by name.
+	SearchSeries(ctx context.Context, name string) ([]models.ComicVineVolume, error)
+	// SearchIssues finds issues by series title and optional issue number.
+	SearchIssues(ctx context.Context, title string, issueNumber string) ([]models.ComicVineIssue, error)
+	// GetIssue fetches one issue by the provider's ID, including credits.
+	GetIssue(ctx context.Context, id int) (*models.ComicVineIssue, error)
+	// Close releases resources such as rate limiters.
+	Close()
+}
+
+// Factory creates a provider from the configuration.
+type Factory func(cfg *config.Config, httpClient *http.Client) (MetadataProvider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		ComicVine: newComicVine,
+		Metron:    newMetron,
+	}
+)
+
+// Register makes a provider available by name. Like database/sql drivers,
+// it panics if the name is registered twice or factory is nil.
+func Register(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if factory == nil {
+		panic("provider: Register factory is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("provider: Register called twice for provider " + name)
+	}
+	providers[name] = factory
+}
+
+// Providers returns the sorted names of the registered providers.
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the named provider, or Default when name is empty.
+func New(name string, cfg *config.Config, httpClient *http.Client) (MetadataProvider, error) {
+	if name == "" {
+		name = Default
+	}
+
+	providersMu.RLock()
+	factory := providers[name]
+	providersMu.RUnlock()
+
+	if factory == nil {
+		return nil, fmt.Errorf("unknown metadata provider %q (available: %s)", name, strings.Join(Providers(), ", "))
+	}
+	return factory(cfg, httpClient)
+}
+
+func newComicVine(cfg *config.Config, httpClient *http.Client) (MetadataProvider, error) {
+	if cfg.ComicVineAPIKey == "" {
+		return nil, errors.New("comicvine API key is required")
+	}
+	return comicvine.NewClient(cfg, httpClient), nil
+}
+
+func newMetron(cfg *config.Config, httpClient *http.Client) (MetadataProvider, error) {
+	if cfg.MetronUsername == "" || cfg.MetronPassword == "" {
+		return nil, errors.New("metron username and password are required")
+	}
+	return metron.NewClient(cfg, httpClient), nil
+}
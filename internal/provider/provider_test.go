@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+type fakeProvider struct{}
+
+func (fakeProvider) Name() string { return "fake" }
+func (fakeProvider) SearchSeries(ctx context.Context, name string) ([]models.ComicVineVolume, error) {
+	return []models.ComicVineVolume{{ID: 1, Name: name}}, nil
+}
+func (fakeProvider) SearchIssues(ctx context.Context, title, issueNumber string) ([]models.ComicVineIssue, error) {
+	return nil, nil
+}
+func (fakeProvider) GetIssue(ctx context.Context, id int) (*models.ComicVineIssue, error) {
+	return &models.ComicVineIssue{ID: id}, nil
+}
+func (fakeProvider) Close() {}
+
+func TestNew(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	if _, err := New("", cfg, http.DefaultClient); err == nil {
+		t.Error("Expected an error for comicvine without an API key")
+	}
+
+	cfg.ComicVineAPIKey = "test-key"
+	p, err := New("", cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("New default failed: %v", err)
+	}
+	defer p.Close()
+	if p.Name() != ComicVine {
+		t.Errorf("Expected default provider %q, got %q", ComicVine, p.Name())
+	}
+
+	if _, err := New("nope", cfg, http.DefaultClient); err == nil || !strings.Contains(err.Error(), ComicVine) {
+		t.Errorf("Expected unknown provider error listing available providers, got %v", err)
+	}
+
+	Register("fake", func(*config.Config, *http.Client) (MetadataProvider, error) { return fakeProvider{}, nil })
+	p, err = New("fake", cfg, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("New fake failed: %v", err)
+	}
+	if vols, _ := p.SearchSeries(context.Background(), "Saga"); len(vols) != 1 || vols[0].Name != "Saga" {
+		t.Errorf("Unexpected fake results: %+v", vols)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on duplicate registration")
+		}
+	}()
+	Register("fake", func(*config.Config, *http.Client) (MetadataProvider, error) { return fakeProvider{}, nil })
+}
@@ -0,0 +1,70 @@
+// Package readinglist writes reading lists in the ComicRack CBL format read
+// by comic readers and servers such as Komga and Kavita.
+package readinglist
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"comic-parser/internal/models"
+)
+
+// cblDatabase names the ComicVine IDs in a CBL file
+const cblDatabase = "cv"
+
+type cbl struct {
+	XMLName   xml.Name  `xml:"ReadingList"`
+	Name      string    `xml:"Name"`
+	NumIssues int       `xml:"NumIssues"`
+	Books     []cblBook `xml:"Books>Book"`
+}
+
+type cblBook struct {
+	Series   string         `xml:"Series,attr"`
+	Number   string         `xml:"Number,attr"`
+	Volume   string         `xml:"Volume,attr,omitempty"`
+	Year     string         `xml:"Year,attr,omitempty"`
+	Database cblDatabaseRef `xml:"Database"`
+}
+
+type cblDatabaseRef struct {
+	Name   string `xml:"Name,attr"`
+	Series int    `xml:"Series,attr"`
+	Issue  int    `xml:"Issue,attr"`
+}
+
+// WriteCBL writes list as a CBL file. Each book names its series, number,
+// volume start year and cover year, and carries its ComicVine volume and
+// issue IDs so readers can find the file without matching names.
+func WriteCBL(w io.Writer, list *models.ReadingList) error {
+	doc := cbl{Name: list.Name, NumIssues: len(list.Entries)}
+	for _, e := range list.Entries {
+		issue := e.Issue
+		book := cblBook{
+			Series: issue.Volume.Name,
+			Number: issue.IssueNumber,
+			Volume: issue.Volume.StartYear,
+			Database: cblDatabaseRef{
+				Name:   cblDatabase,
+				Series: issue.Volume.ID,
+				Issue:  issue.ID,
+			},
+		}
+		if !issue.CoverDate.IsZero() {
+			book.Year = strconv.Itoa(issue.CoverDate.Year)
+		}
+		doc.Books = append(doc.Books, book)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,50 @@
+package readinglist
+
+import (
+	"bytes"
+	"testing"
+
+	"comic-parser/internal/models"
+)
+
+func TestWriteCBL(t *testing.T) {
+	list := &models.ReadingList{
+		Name: "Civil War",
+		Entries: []models.ReadingListEntry{
+			{Position: 1, Issue: models.ComicVineIssue{
+				ID:          105958,
+				IssueNumber: "1",
+				CoverDate:   models.Date{Year: 2006, Month: 7},
+				Volume:      models.VolumeRef{ID: 18023, Name: "Civil War", StartYear: "2006"},
+			}},
+			{Position: 2, Issue: models.ComicVineIssue{
+				ID:          106000,
+				IssueNumber: "2",
+				Volume:      models.VolumeRef{ID: 18023, Name: "Civil War & Friends"},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCBL(&buf, list); err != nil {
+		t.Fatalf("WriteCBL: %v", err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<ReadingList>
+  <Name>Civil War</Name>
+  <NumIssues>2</NumIssues>
+  <Books>
+    <Book Series="Civil War" Number="1" Volume="2006" Year="2006">
+      <Database Name="cv" Series="18023" Issue="105958"></Database>
+    </Book>
+    <Book Series="Civil War &amp; Friends" Number="2">
+      <Database Name="cv" Series="18023" Issue="106000"></Database>
+    </Book>
+  </Books>
+</ReadingList>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCBL wrote\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package selector
+
+import (
+	"context"
+	"sort"
+
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
+)
+
+// correctionPool is how many of the most recent corrections are ranked
+// when picking examples for a match.
+const correctionPool = 500
+
+// SetExamples makes the selector show the LLM up to n of the user's past
+// corrections from src, those with titles most like the file's, so matches
+// follow the naming of the user's library. n of 0 turns examples off.
+func (s *LLMSelector) SetExamples(src CorrectionSource, n int) {
+	s.corrections = src
+	s.exampleCount = n
+}
+
+// examples returns the past corrections to show for parsed. Failing to load
+// them only costs the examples, so the error is logged rather than returned.
+func (s *LLMSelector) examples(ctx context.Context, parsed *models.ParsedFilename) []models.MatchCorrection {
+	if s.corrections == nil || s.exampleCount <= 0 || parsed.Title == "" {
+		return nil
+	}
+	corrections, err := s.corrections.ListMatchCorrections(ctx, correctionPool)
+	if err != nil {
+		logging.Logger(logging.LLM).Warn("loading match corrections failed; matching without examples", "error", err)
+		return nil
+	}
+	return relevantCorrections(parsed, corrections, s.exampleCount)
+}
+
+// relevantCorrections returns up to n of corrections whose parsed titles
+// are similar to parsed's, most similar first and newest first among equals.
+// The file's own earlier corrections are left out.
+func relevantCorrections(parsed *models.ParsedFilename, corrections []models.MatchCorrection, n int) []models.MatchCorrection {
+	type ranked struct {
+		correction models.MatchCorrection
+		similarity float64
+	}
+	var candidates []ranked
+	for _, c := range corrections {
+		if c.Filename == parsed.OriginalFilename {
+			continue
+		}
+		if sim := titleSimilarity(parsed.Title, c.Title); sim >= minTitleSimilarity {
+			candidates = append(candidates, ranked{c, sim})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].similarity > candidates[j].similarity
+	})
+
+	examples := make([]models.MatchCorrection, 0, min(n, len(candidates)))
+	for _, c := range candidates[:min(n, len(candidates))] {
+		examples = append(examples, c.correction)
+	}
+	return examples
+}
@@ -0,0 +1,68 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"comic-parser/internal/models"
+)
+
+type fakeCorrections []models.MatchCorrection
+
+func (f fakeCorrections) ListMatchCorrections(ctx context.Context, limit int) ([]models.MatchCorrection, error) {
+	if f == nil {
+		return nil, errors.New("no database")
+	}
+	return f[:min(limit, len(f))], nil
+}
+
+func TestRelevantCorrections(t *testing.T) {
+	corrections := fakeCorrections{
+		{Filename: "Batman 001.cbz", Title: "Batman", ComicVineID: 1},
+		{Filename: "Saga 002.cbz", Title: "Saga", ComicVineID: 2},
+		{Filename: "The Amazing Spider-Man 010.cbz", Title: "The Amazing Spider-Man", ComicVineID: 3},
+		{Filename: "Sagas 001.cbz", Title: "Sagas", ComicVineID: 4},
+		{Filename: "Saga 003.cbz", Title: "Saga", ComicVineID: 5},
+		{Filename: "Saga 001.cbz", Title: "Saga", ComicVineID: 6},
+	}
+	parsed := &models.ParsedFilename{OriginalFilename: "Saga 003.cbz", Title: "Saga"}
+
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{n: 1, want: []int{2}},
+		{n: 2, want: []int{2, 6}},
+		{n: 5, want: []int{2, 6, 4}},
+	}
+	for _, tt := range tests {
+		got := relevantCorrections(parsed, corrections, tt.n)
+		var ids []int
+		for _, c := range got {
+			ids = append(ids, c.ComicVineID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("relevantCorrections(n=%d) = %v; want %v", tt.n, ids, tt.want)
+		}
+	}
+}
+
+func TestLLMSelectorExamples(t *testing.T) {
+	parsed := &models.ParsedFilename{OriginalFilename: "Saga 003.cbz", Title: "Saga"}
+	s := NewLLMSelector(nil, nil)
+	if got := s.examples(context.Background(), parsed); got != nil {
+		t.Errorf("examples() without a source = %+v; want none", got)
+	}
+
+	s.SetExamples(fakeCorrections{{Filename: "Saga 002.cbz", Title: "Saga", ComicVineID: 2}}, 3)
+	if got := s.examples(context.Background(), parsed); len(got) != 1 {
+		t.Errorf("examples() = %+v; want the Saga correction", got)
+	}
+
+	s.SetExamples(fakeCorrections(nil), 3)
+	if got := s.examples(context.Background(), parsed); got != nil {
+		t.Errorf("examples() with a failing source = %+v; want none", got)
+	}
+}
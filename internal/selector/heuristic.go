@@ -0,0 +1,259 @@
+package selector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"comic-parser/internal/models"
+)
+
+// Score weights, out of a total of 100 when every signal is available
+const (
+	titleWeight     = 50
+	issueWeight     = 30
+	yearWeight      = 10
+	publisherWeight = 10
+)
+
+// Score thresholds for each match confidence. Candidates scoring below
+// minLowScore are not selected at all.
+const (
+	minHighScore   = 85
+	minMediumScore = 65
+	minLowScore    = 45
+
+	// ambiguousMargin is how close the runner-up may score before a match
+	// is no longer trusted with high confidence
+	ambiguousMargin = 5
+
+	// minTitleSimilarity is the similarity at which a title stops earning
+	// points. Jaro-Winkler rates unrelated names well above zero, so the
+	// range above it is stretched to the full title weight.
+	minTitleSimilarity = 0.7
+)
+
+// HeuristicSelector scores candidates on title similarity, issue number,
+// year proximity and publisher, without calling an LLM.
+type HeuristicSelector struct{}
+
+// NewHeuristicSelector creates a new HeuristicSelector.
+func NewHeuristicSelector() *HeuristicSelector {
+	return &HeuristicSelector{}
+}
+
+// candidateScore is a candidate's score and the signals behind it.
+type candidateScore struct {
+	total     int
+	title     float64
+	issue     bool
+	noIssue   bool // the filename has no issue number, like a manga chapter
+	yearGap   int  // -1 when either year is unknown
+	publisher string
+}
+
+// Select implements the Selector interface. The best-scoring candidate is
+// selected, and its score is recorded as the reasoning.
+func (s *HeuristicSelector) Select(ctx context.Context, parsed *models.ParsedFilename, issues []models.ComicVineIssue) (*models.MatchResult, error) {
+	result := &models.MatchResult{
+		OriginalFilename: parsed.OriginalFilename,
+		ParsedInfo:       *parsed,
+	}
+
+	if len(issues) == 0 {
+		result.MatchConfidence = "none"
+		result.Reasoning = "No results found in ComicVine"
+		return result, nil
+	}
+
+	best, runnerUp := -1, -1
+	scores := make([]candidateScore, len(issues))
+	for i := range issues {
+		scores[i] = scoreCandidate(parsed, &issues[i])
+		switch {
+		case best < 0 || scores[i].total > scores[best].total:
+			best, runnerUp = i, best
+		case runnerUp < 0 || scores[i].total > scores[runnerUp].total:
+			runnerUp = i
+		}
+	}
+
+	score := scores[best]
+	switch {
+	case score.total >= minHighScore:
+		result.MatchConfidence = "high"
+	case score.total >= minMediumScore:
+		result.MatchConfidence = "medium"
+	case score.total >= minLowScore:
+		result.MatchConfidence = "low"
+	default:
+		result.MatchConfidence = "none"
+		result.Reasoning = fmt.Sprintf("No candidate scored at least %d; best was %s", minLowScore, score)
+		return result, nil
+	}
+	result.Reasoning = fmt.Sprintf("Heuristic %s", score)
+
+	// The right series with the wrong issue is still the wrong comic, and
+	// without an issue number only the series is known
+	if !score.issue {
+		result.MatchConfidence = "low"
+	}
+
+	if runnerUp >= 0 && score.total-scores[runnerUp].total < ambiguousMargin &&
+		issues[runnerUp].Volume.ID != issues[best].Volume.ID {
+		if result.MatchConfidence == "high" {
+			result.MatchConfidence = "medium"
+		}
+		result.Reasoning += fmt.Sprintf("; %s #%s scored %d", issues[runnerUp].Volume.Name, issues[runnerUp].IssueNumber, scores[runnerUp].total)
+	}
+
+	selected := issues[best]
+	result.SelectedIssue = &selected
+	result.ComicVineID = selected.ID
+	result.ComicVineURL = selected.SiteDetailURL
+	return result, nil
+}
+
+// String describes the score for match reasoning.
+func (c candidateScore) String() string {
+	parts := []string{fmt.Sprintf("title %.2f", c.title)}
+	switch {
+	case c.noIssue:
+		parts = append(parts, "no issue number")
+	case c.issue:
+		parts = append(parts, "issue number matches")
+	default:
+		parts = append(parts, "issue number differs")
+	}
+	if c.yearGap >= 0 {
+		parts = append(parts, fmt.Sprintf("%d years apart", c.yearGap))
+	}
+	if c.publisher != "" {
+		parts = append(parts, "publisher "+c.publisher)
+	}
+	return fmt.Sprintf("score %d/100 (%s)", c.total, strings.Join(parts, ", "))
+}
+
+// scoreCandidate scores issue against parsed out of 100. Signals missing
+// from either side, such as an unknown year, are left out and the score is
+// scaled to the remaining weights.
+func scoreCandidate(parsed *models.ParsedFilename, issue *models.ComicVineIssue) candidateScore {
+	c := candidateScore{
+		title:   titleSimilarity(parsed.Title, issue.Volume.Name),
+		noIssue: strings.TrimSpace(parsed.IssueNumber) == "",
+		yearGap: -1,
+	}
+
+	earned := max(0, c.title-minTitleSimilarity) / (1 - minTitleSimilarity) * titleWeight
+	possible := float64(titleWeight)
+	if !c.noIssue {
+		possible += issueWeight
+		c.issue = models.SameIssueNumber(parsed.IssueNumber, issue.IssueNumber)
+		if c.issue {
+			earned += issueWeight
+		}
+	}
+
+	year, hasYear := models.ParseYear(parsed.Year)
+	candidateYear := issue.CoverDate.Year
+	if candidateYear == 0 {
+		candidateYear, _ = models.ParseYear(issue.Volume.StartYear)
+	}
+	if hasYear && candidateYear > 0 {
+		c.yearGap = max(year-candidateYear, candidateYear-year)
+		possible += yearWeight
+		// Cover dates run a few months ahead of release, so a year off
+		// still earns most of the weight
+		earned += float64(yearWeight * max(0, 3-c.yearGap) / 3)
+	}
+
+	if parsed.Publisher != "" && issue.Volume.Publisher != "" {
+		possible += publisherWeight
+		want, got := normalizeTitle(parsed.Publisher), normalizeTitle(issue.Volume.Publisher)
+		if strings.Contains(got, want) || strings.Contains(want, got) {
+			c.publisher = "matches"
+			earned += publisherWeight
+		} else {
+			c.publisher = "differs"
+		}
+	}
+
+	c.total = int(earned/possible*100 + 0.5)
+	return c
+}
+
+// titleSimilarity compares series names after normalization with the
+// Jaro-Winkler metric, from 0 (nothing alike) to 1 (equal).
+func titleSimilarity(a, b string) float64 {
+	return jaroWinkler(normalizeTitle(a), normalizeTitle(b))
+}
+
+// normalizeTitle lowercases a name and reduces it to space-separated words
+// of letters and digits, spelling out "&" and dropping a leading "the".
+func normalizeTitle(name string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+	for i, w := range words {
+		words[i] = strings.ReplaceAll(w, "'", "")
+	}
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b.
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	window := max(len(s1), len(s2))/2 - 1
+	window = max(window, 0)
+	matched1 := make([]bool, len(s1))
+	matched2 := make([]bool, len(s2))
+	matches := 0
+	for i, r := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s2[j] == r {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i, r := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if r != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,166 @@
+package selector
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"comic-parser/internal/models"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.84},
+		{"dixon", "dicksonx", 0.813},
+		{"saga", "saga", 1},
+		{"", "", 1},
+		{"saga", "", 0},
+		{"abc", "xyz", 0},
+	}
+	for _, tt := range tests {
+		if got := jaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("jaroWinkler(%q, %q) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"The Amazing Spider-Man": "amazing spider man",
+		"Batman & Robin":         "batman and robin",
+		"Harley Quinn's Villain": "harley quinns villain",
+		"The Boys":               "boys",
+		"The":                    "the",
+	}
+	for in, want := range tests {
+		if got := normalizeTitle(in); got != want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHeuristicSelector_Select(t *testing.T) {
+	issue := func(id int, volumeID int, volume, number string, year int, publisher string) models.ComicVineIssue {
+		return models.ComicVineIssue{
+			ID:          id,
+			IssueNumber: number,
+			CoverDate:   models.Date{Year: year},
+			Volume:      models.VolumeRef{ID: volumeID, Name: volume, Publisher: publisher},
+		}
+	}
+	candidates := []models.ComicVineIssue{
+		issue(1, 10, "Saga Deluxe Edition", "1", 2012, "Image"),
+		issue(2, 20, "Saga", "2", 2012, "Image"),
+		issue(3, 20, "Saga", "1", 2012, "Image"),
+		issue(4, 30, "Sage", "1", 1998, "Marvel"),
+	}
+	sel := NewHeuristicSelector()
+	ctx := context.Background()
+
+	parsed := &models.ParsedFilename{OriginalFilename: "Saga 001 (2012).cbz", Title: "Saga", IssueNumber: "001", Year: "2012", Publisher: "Image Comics"}
+	result, err := sel.Select(ctx, parsed, candidates)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if result.SelectedIssue == nil || result.ComicVineID != 3 {
+		t.Fatalf("Expected issue 3 selected, got %+v", result)
+	}
+	if result.MatchConfidence != "high" || !strings.Contains(result.Reasoning, "score 100/100") {
+		t.Errorf("Expected a high confidence perfect score, got %s: %s", result.MatchConfidence, result.Reasoning)
+	}
+
+	// A misspelled title still matches, but not with high confidence
+	parsed = &models.ParsedFilename{OriginalFilename: "Sgaa 1.cbz", Title: "Sgaa", IssueNumber: "1"}
+	result, err = sel.Select(ctx, parsed, candidates[2:3])
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if result.ComicVineID != 3 || result.MatchConfidence == "high" || result.MatchConfidence == "none" {
+		t.Errorf("Expected issue 3 with reduced confidence, got %d (%s): %s", result.ComicVineID, result.MatchConfidence, result.Reasoning)
+	}
+
+	// Nothing alike is not selected
+	parsed = &models.ParsedFilename{OriginalFilename: "Hellboy 7.cbz", Title: "Hellboy", IssueNumber: "7", Year: "1995"}
+	result, err = sel.Select(ctx, parsed, candidates)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if result.SelectedIssue != nil || result.MatchConfidence != "none" {
+		t.Errorf("Expected no selection, got %+v", result)
+	}
+
+	result, err = sel.Select(ctx, parsed, nil)
+	if err != nil || result.MatchConfidence != "none" {
+		t.Errorf("Expected no match without candidates, got %+v, %v", result, err)
+	}
+
+	// Half issues and variant covers match the issue ComicVine numbers
+	halves := []models.ComicVineIssue{
+		issue(5, 40, "Wolverine", "½", 1997, "Marvel"),
+		issue(6, 40, "Wolverine", "1", 1997, "Marvel"),
+	}
+	for number, want := range map[string]int{"0.5": 5, "1/2": 5, "1A": 6, "001b": 6} {
+		parsed = &models.ParsedFilename{OriginalFilename: "Wolverine " + number + ".cbz", Title: "Wolverine", IssueNumber: number, Year: "1997"}
+		result, err = sel.Select(ctx, parsed, halves)
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		if result.ComicVineID != want {
+			t.Errorf("Expected issue %d for #%s, got %d (%s)", want, number, result.ComicVineID, result.Reasoning)
+		}
+	}
+}
+
+func TestHeuristicSelector_Ambiguous(t *testing.T) {
+	// Two volumes of the same name and start year are indistinguishable
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", CoverDate: models.Date{Year: 2016}, Volume: models.VolumeRef{ID: 10, Name: "Batman"}},
+		{ID: 2, IssueNumber: "1", CoverDate: models.Date{Year: 2016}, Volume: models.VolumeRef{ID: 20, Name: "Batman"}},
+	}
+	parsed := &models.ParsedFilename{OriginalFilename: "Batman 001 (2016).cbz", Title: "Batman", IssueNumber: "1", Year: "2016"}
+	result, err := NewHeuristicSelector().Select(context.Background(), parsed, candidates)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if result.ComicVineID != 1 || result.MatchConfidence != "medium" {
+		t.Errorf("Expected the first candidate with medium confidence, got %d (%s): %s", result.ComicVineID, result.MatchConfidence, result.Reasoning)
+	}
+}
+
+func TestHeuristicSelector_IssueMismatch(t *testing.T) {
+	candidates := []models.ComicVineIssue{
+		{ID: 2, IssueNumber: "2", CoverDate: models.Date{Year: 2012}, Volume: models.VolumeRef{ID: 20, Name: "Saga", Publisher: "Image"}},
+	}
+	parsed := &models.ParsedFilename{OriginalFilename: "Saga 001 (2012).cbz", Title: "Saga", IssueNumber: "1", Year: "2012", Publisher: "Image"}
+	result, err := NewHeuristicSelector().Select(context.Background(), parsed, candidates)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if result.MatchConfidence != "low" {
+		t.Errorf("Expected low confidence for a different issue number, got %s: %s", result.MatchConfidence, result.Reasoning)
+	}
+}
+
+func TestHeuristicSelector_NoIssueNumber(t *testing.T) {
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", CoverDate: models.Date{Year: 2019}, Volume: models.VolumeRef{ID: 10, Name: "Chainsaw Man"}},
+		{ID: 2, IssueNumber: "1", CoverDate: models.Date{Year: 2019}, Volume: models.VolumeRef{ID: 20, Name: "Chainsaw Devil"}},
+	}
+	// A manga chapter is matched on the series alone
+	parsed := &models.ParsedFilename{OriginalFilename: "Chainsaw Man - Chapter 120.cbz", Title: "Chainsaw Man", Chapter: "120"}
+	result, err := NewHeuristicSelector().Select(context.Background(), parsed, candidates)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if result.SelectedIssue == nil || result.SelectedIssue.ID != 1 {
+		t.Fatalf("Expected the Chainsaw Man volume, got %+v: %s", result.SelectedIssue, result.Reasoning)
+	}
+	if result.MatchConfidence != "low" || !strings.Contains(result.Reasoning, "no issue number") {
+		t.Errorf("Expected a low confidence series match, got %s: %s", result.MatchConfidence, result.Reasoning)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"comic-parser/internal/config"
-	"comic-parser/internal/llm"
 	"comic-parser/internal/models"
 	"comic-parser/internal/prompts"
 )
@@ -16,6 +15,9 @@ import (
 type LLMSelector struct {
 	client LLMClient
 	cfg    *config.Config
+
+	corrections  CorrectionSource // past corrections shown as examples, if set
+	exampleCount int
 }
 
 // NewLLMSelector creates a new LLMSelector.
@@ -39,11 +41,12 @@ func (s *LLMSelector) Select(ctx context.Context, parsed *models.ParsedFilename,
 		return result, nil
 	}
 
-	prompt := prompts.ResultMatchPrompt(*parsed, issues)
+	prompt := prompts.ResultMatchPrompt(*parsed, issues, s.examples(ctx, parsed)...)
 
-	response, err := s.client.CompleteWithRetry(
+	response, err := s.client.CompleteJSONWithRetry(
 		ctx,
 		prompt,
+		prompts.MatchSchema,
 		s.cfg.RetryAttempts,
 		time.Duration(s.cfg.RetryDelaySeconds)*time.Second,
 	)
@@ -51,11 +54,8 @@ func (s *LLMSelector) Select(ctx context.Context, parsed *models.ParsedFilename,
 		return nil, fmt.Errorf("LLM completion: %w", err)
 	}
 
-	// Extract JSON from response
-	jsonStr := llm.ExtractJSON(response)
-
 	var matchResp prompts.MatchResponse
-	if err := json.Unmarshal([]byte(jsonStr), &matchResp); err != nil {
+	if err := json.Unmarshal([]byte(response), &matchResp); err != nil {
 		return nil, fmt.Errorf("parsing LLM response: %w (response: %s)", err, response)
 	}
 
@@ -0,0 +1,90 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
+)
+
+// defaultReviewPoll is how often RemoteSelector checks for a decision
+const defaultReviewPoll = 2 * time.Second
+
+// ReviewQueue queues matches for a person to review and reports the
+// decisions made on them. *storage.Storage satisfies it.
+type ReviewQueue interface {
+	QueueReview(ctx context.Context, result *models.ProcessingResult, candidates []models.ComicVineIssue) error
+	GetReview(ctx context.Context, filename string) (*models.ReviewItem, error)
+}
+
+// RemoteSelector asks a person to decide ambiguous matches from the review
+// page of `serve`, which may run on another machine sharing the database.
+// Another selector picks the match first; high confidence matches and
+// files without candidates are taken as they are, and the rest are queued
+// for review while Select waits for the decision. When none arrives within
+// the timeout, the selector's own match is returned and the file stays in
+// the queue, where a later decision still updates the stored match.
+type RemoteSelector struct {
+	next    Selector
+	queue   ReviewQueue
+	timeout time.Duration
+	poll    time.Duration
+}
+
+// NewRemoteSelector creates a RemoteSelector that queues the matches next
+// picks with less than high confidence and waits up to timeout for each
+// decision.
+func NewRemoteSelector(next Selector, queue ReviewQueue, timeout time.Duration) *RemoteSelector {
+	return &RemoteSelector{next: next, queue: queue, timeout: timeout, poll: defaultReviewPoll}
+}
+
+// Select implements the Selector interface.
+func (s *RemoteSelector) Select(ctx context.Context, parsed *models.ParsedFilename, issues []models.ComicVineIssue) (*models.MatchResult, error) {
+	match, err := s.next.Select(ctx, parsed, issues)
+	if err != nil || len(issues) == 0 || match.MatchConfidence == "high" {
+		return match, err
+	}
+
+	result := &models.ProcessingResult{
+		Filename:    parsed.OriginalFilename,
+		Path:        parsed.Path,
+		Success:     true,
+		Match:       match,
+		ProcessedAt: time.Now(),
+	}
+	if err := s.queue.QueueReview(ctx, result, issues); err != nil {
+		return nil, fmt.Errorf("queueing for review: %w", err)
+	}
+	logger := logging.Logger(logging.Server)
+	logger.Info("waiting for a review decision", "file", result.Filename, "confidence", match.MatchConfidence, "timeout", s.timeout)
+
+	wait, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	ticker := time.NewTicker(s.poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wait.Done():
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			logger.Info("no review decision in time; keeping the selected match", "file", result.Filename)
+			return match, nil
+		case <-ticker.C:
+		}
+		item, err := s.queue.GetReview(wait, result.Filename)
+		if err != nil {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				logger.Warn("checking for a review decision failed", "file", result.Filename, "error", err)
+			}
+			continue
+		}
+		if item.Status != models.ReviewPending && item.Result != nil {
+			logger.Info("review decided", "file", result.Filename, "status", item.Status)
+			return item.Result.Match, nil
+		}
+	}
+}
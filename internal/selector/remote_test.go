@@ -0,0 +1,108 @@
+package selector
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"comic-parser/internal/models"
+)
+
+// fixedSelector selects the first candidate with a fixed confidence
+type fixedSelector string
+
+func (s fixedSelector) Select(ctx context.Context, parsed *models.ParsedFilename, issues []models.ComicVineIssue) (*models.MatchResult, error) {
+	result := &models.MatchResult{OriginalFilename: parsed.OriginalFilename, MatchConfidence: string(s)}
+	if len(issues) > 0 {
+		result.SelectedIssue = &issues[0]
+		result.ComicVineID = issues[0].ID
+	}
+	return result, nil
+}
+
+// memoryQueue is a review queue whose decisions a test makes
+type memoryQueue struct {
+	mu    sync.Mutex
+	items map[string]*models.ReviewItem
+}
+
+func (q *memoryQueue) QueueReview(ctx context.Context, result *models.ProcessingResult, candidates []models.ComicVineIssue) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.items[result.Filename] = &models.ReviewItem{Filename: result.Filename, Result: result, Candidates: candidates, Status: models.ReviewPending}
+	return nil
+}
+
+func (q *memoryQueue) GetReview(ctx context.Context, filename string) (*models.ReviewItem, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.items[filename]
+	if !ok {
+		return nil, errors.New("not queued")
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (q *memoryQueue) decide(filename string, issue models.ComicVineIssue) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item := q.items[filename]
+	result := *item.Result
+	result.Match = models.AcceptCandidate(result.Match, issue)
+	item.Result, item.Status = &result, models.ReviewAccepted
+}
+
+func TestRemoteSelector(t *testing.T) {
+	issues := []models.ComicVineIssue{{ID: 1, IssueNumber: "1"}, {ID: 2, IssueNumber: "1"}}
+	parsed := &models.ParsedFilename{OriginalFilename: "Saga 001.cbz", Title: "Saga", IssueNumber: "1"}
+	newSelector := func(confidence string, timeout time.Duration) (*RemoteSelector, *memoryQueue) {
+		queue := &memoryQueue{items: make(map[string]*models.ReviewItem)}
+		s := NewRemoteSelector(fixedSelector(confidence), queue, timeout)
+		s.poll = time.Millisecond
+		return s, queue
+	}
+	ctx := context.Background()
+
+	// High confidence matches aren't queued
+	s, queue := newSelector("high", time.Minute)
+	if match, err := s.Select(ctx, parsed, issues); err != nil || match.ComicVineID != 1 || len(queue.items) != 0 {
+		t.Errorf("Select = %+v, %v with %d queued; want the match unqueued", match, err, len(queue.items))
+	}
+
+	// A decision replaces the selected match
+	s, queue = newSelector("medium", time.Minute)
+	go func() {
+		for {
+			if _, err := queue.GetReview(ctx, parsed.OriginalFilename); err == nil {
+				queue.decide(parsed.OriginalFilename, issues[1])
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	match, err := s.Select(ctx, parsed, issues)
+	if err != nil || match.ComicVineID != 2 || match.MatchConfidence != "high" {
+		t.Errorf("Select = %+v, %v; want the reviewer's choice", match, err)
+	}
+
+	// Without a decision the selected match is kept and stays queued
+	s, queue = newSelector("low", 20*time.Millisecond)
+	match, err = s.Select(ctx, parsed, issues)
+	if err != nil || match.ComicVineID != 1 || match.MatchConfidence != "low" {
+		t.Errorf("Select = %+v, %v; want the selected match after the timeout", match, err)
+	}
+	if item, _ := queue.GetReview(ctx, parsed.OriginalFilename); item == nil || item.Status != models.ReviewPending {
+		t.Errorf("Expected the match to stay pending, got %+v", item)
+	}
+
+	// Cancelling the run stops the wait
+	s, _ = newSelector("medium", time.Minute)
+	cancelled, cancel := context.WithCancel(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := s.Select(cancelled, parsed, issues); !errors.Is(err, context.Canceled) {
+		t.Errorf("Select error = %v, want context.Canceled", err)
+	}
+}
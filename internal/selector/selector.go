@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"comic-parser/internal/llm"
 	"comic-parser/internal/models"
 )
 
@@ -14,5 +15,15 @@ type Selector interface {
 
 // LLMClient defines the interface for LLM interactions needed by LLMSelector.
 type LLMClient interface {
-	CompleteWithRetry(ctx context.Context, prompt string, maxRetries int, delay time.Duration) (string, error)
+	CompleteJSONWithRetry(ctx context.Context, prompt string, schema llm.Schema, maxRetries int, delay time.Duration) (string, error)
+}
+
+// CorrectionSource supplies the user's past match corrections, newest first.
+type CorrectionSource interface {
+	ListMatchCorrections(ctx context.Context, limit int) ([]models.MatchCorrection, error)
+}
+
+// CorrectionRecorder saves the matches a user chooses by hand.
+type CorrectionRecorder interface {
+	SaveMatchCorrection(ctx context.Context, c models.MatchCorrection) error
 }
@@ -9,12 +9,14 @@ import (
 	"strings"
 	"sync"
 
+	"comic-parser/internal/logging"
 	"comic-parser/internal/models"
 )
 
 // TUISelector presents candidates to the user via CLI.
 type TUISelector struct {
-	mu sync.Mutex
+	mu       sync.Mutex
+	recorder CorrectionRecorder // saves the user's choices, if set
 }
 
 // NewTUISelector creates a new TUISelector.
@@ -22,6 +24,12 @@ func NewTUISelector() *TUISelector {
 	return &TUISelector{}
 }
 
+// RecordCorrections makes the selector save each choice the user makes
+// between candidates to r, for use as matching examples.
+func (s *TUISelector) RecordCorrections(r CorrectionRecorder) {
+	s.recorder = r
+}
+
 // Select implements the Selector interface.
 func (s *TUISelector) Select(ctx context.Context, parsed *models.ParsedFilename, issues []models.ComicVineIssue) (*models.MatchResult, error) {
 	// Lock to ensure only one interaction happens at a time
@@ -55,7 +63,7 @@ func (s *TUISelector) Select(ctx context.Context, parsed *models.ParsedFilename,
 
 	for i, issue := range issues {
 		fmt.Printf("[%d] %s #%s (%s) - %s\n",
-			i+1, issue.Volume.Name, issue.IssueNumber, issue.CoverDate, issue.Volume.Publisher)
+			i+1, issue.Volume.Label(), issue.IssueNumber, issue.CoverDate, issue.Volume.Publisher)
 	}
 	fmt.Printf("--------------------------------------------------\n")
 
@@ -75,6 +83,7 @@ func (s *TUISelector) Select(ctx context.Context, parsed *models.ParsedFilename,
 			result.MatchConfidence = "none"
 			result.Reasoning = "User selected No Match"
 			fmt.Println("Marked as No Match.")
+			s.record(ctx, result)
 			return result, nil
 		}
 
@@ -86,9 +95,22 @@ func (s *TUISelector) Select(ctx context.Context, parsed *models.ParsedFilename,
 			result.MatchConfidence = "high" // User manually selected it
 			result.Reasoning = "User manual selection"
 			fmt.Printf("Selected: %s #%s\n", selectedIssue.Volume.Name, selectedIssue.IssueNumber)
+			s.record(ctx, result)
 			return result, nil
 		}
 
 		fmt.Println("Selection out of range.")
 	}
 }
+
+// record saves the user's choice as a match correction. A failure is only
+// logged, since the match itself is unaffected.
+func (s *TUISelector) record(ctx context.Context, result *models.MatchResult) {
+	if s.recorder == nil {
+		return
+	}
+	correction := models.NewMatchCorrection(result, nil, models.CorrectionInteractive)
+	if err := s.recorder.SaveMatchCorrection(ctx, correction); err != nil {
+		logging.Logger(logging.Storage).Warn("saving match correction failed", "file", result.OriginalFilename, "error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"comic-parser/internal/catalog"
+	"comic-parser/internal/covers"
+	"comic-parser/internal/models"
+)
+
+// opdsRoot is the path of the root OPDS feed
+const opdsRoot = "/opds"
+
+// handleOPDSRoot serves the navigation feed of the library: an entry for all
+// comics followed by one per series.
+func (s *Server) handleOPDSRoot(w http.ResponseWriter, r *http.Request) {
+	results, ok := s.matchedResults(w, r)
+	if !ok {
+		return
+	}
+	feed := catalog.NewSeriesFeed("urn:xander:catalog", "Comics", opdsRoot, results, s.opdsLinks())
+	all := catalog.Entry{
+		Title:   "All comics",
+		ID:      "urn:xander:catalog:all",
+		Updated: feed.Updated,
+		Links:   []catalog.Link{{Rel: "subsection", Href: opdsRoot + "/all", Type: catalog.AcquisitionType}},
+	}
+	feed.Entries = append([]catalog.Entry{all}, feed.Entries...)
+	s.writeFeed(w, r, feed, catalog.NavigationType)
+}
+
+// handleOPDSAll serves the acquisition feed of every matched comic.
+func (s *Server) handleOPDSAll(w http.ResponseWriter, r *http.Request) {
+	results, ok := s.matchedResults(w, r)
+	if !ok {
+		return
+	}
+	feed := catalog.NewAcquisitionFeed("urn:xander:catalog:all", "All comics", opdsRoot+"/all", results, s.opdsLinks())
+	s.writeFeed(w, r, feed, catalog.AcquisitionType)
+}
+
+// handleOPDSSeries serves the acquisition feed of the comics of a volume.
+func (s *Server) handleOPDSSeries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid volume id %q", r.PathValue("id")))
+		return
+	}
+	results, ok := s.matchedResults(w, r)
+	if !ok {
+		return
+	}
+	var series []*models.ProcessingResult
+	for _, result := range results {
+		if result.Match.SelectedIssue.Volume.ID == id {
+			series = append(series, result)
+		}
+	}
+	if len(series) == 0 {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("no comics of volume %d", id))
+		return
+	}
+	title := series[0].Match.SelectedIssue.Volume.Name
+	feed := catalog.NewAcquisitionFeed("urn:comicvine:volume:"+strconv.Itoa(id), title, r.URL.Path, series, s.opdsLinks())
+	s.writeFeed(w, r, feed, catalog.AcquisitionType)
+}
+
+// handleOPDSFile serves the archive of a matched comic that exists locally.
+// Only files in the library can be downloaded.
+func (s *Server) handleOPDSFile(w http.ResponseWriter, r *http.Request) {
+	filename := r.PathValue("filename")
+	results, ok := s.matchedResults(w, r)
+	if !ok {
+		return
+	}
+	for _, result := range results {
+		if result.Filename != filename || result.Path == "" {
+			continue
+		}
+		if _, err := os.Stat(result.Path); err != nil {
+			break
+		}
+		w.Header().Set("Content-Type", catalog.ArchiveType(filename))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		http.ServeFile(w, r, result.Path)
+		return
+	}
+	s.writeError(w, r, http.StatusNotFound, errors.New("no local file for "+filename))
+}
+
+// opdsLinks links entries to their series feeds, to downloads of the files
+// that exist locally and to cached covers.
+func (s *Server) opdsLinks() catalog.Links {
+	return catalog.Links{
+		Start:  opdsRoot,
+		Series: func(id int) string { return opdsRoot + "/series/" + strconv.Itoa(id) },
+		File: func(result *models.ProcessingResult) string {
+			if result.Path == "" {
+				return ""
+			}
+			return opdsRoot + "/files/" + url.PathEscape(result.Filename)
+		},
+		Cover: func(result *models.ProcessingResult) string {
+			if s.covers == nil {
+				return ""
+			}
+			id := result.Match.SelectedIssue.ID
+			for _, size := range covers.Sizes {
+				if _, ok := s.covers.Lookup(id, size); ok {
+					return "/covers/" + strconv.Itoa(id)
+				}
+			}
+			return ""
+		},
+	}
+}
+
+// matchedResults lists the matched comics, writing a 500 response when
+// storage fails.
+func (s *Server) matchedResults(w http.ResponseWriter, r *http.Request) ([]*models.ProcessingResult, bool) {
+	results, err := s.store.ListMatchedResults(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return results, true
+}
+
+func (s *Server) writeFeed(w http.ResponseWriter, r *http.Request, feed *catalog.Feed, kind string) {
+	w.Header().Set("Content-Type", kind)
+	if err := feed.Write(w); err != nil {
+		s.logger.Debug("writing response failed", "path", r.URL.Path, "error", err)
+	}
+}
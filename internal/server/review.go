@@ -0,0 +1,157 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"comic-parser/internal/covers"
+	"comic-parser/internal/models"
+)
+
+// maxReviewCandidates caps the candidates shown for a queued match, as in
+// the terminal review
+const maxReviewCandidates = 10
+
+// Review decisions posted to /reviews/{id}
+const (
+	actionAccept = "accept"
+	actionReject = "reject"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// ReviewsResponse is the body returned by /reviews.
+type ReviewsResponse struct {
+	Reviews []models.ReviewItem `json:"reviews"`
+}
+
+// reviewRequest is the body of /reviews/{id}. IssueID picks the accepted
+// candidate.
+type reviewRequest struct {
+	Action  string `json:"action"` // accept or reject
+	IssueID int    `json:"issue_id,omitempty"`
+}
+
+// webHandler serves the review page and its assets.
+func webHandler() http.Handler {
+	root, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err) // the embedded directory is always there
+	}
+	return http.StripPrefix("/review/", http.FileServerFS(root))
+}
+
+func (s *Server) handleReviews(w http.ResponseWriter, r *http.Request) {
+	items, err := s.store.ListPendingReviews(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	for i := range items {
+		if len(items[i].Candidates) > maxReviewCandidates {
+			items[i].Candidates = items[i].Candidates[:maxReviewCandidates]
+		}
+	}
+	s.writeJSON(w, r, http.StatusOK, ReviewsResponse{Reviews: items})
+}
+
+// handleReview applies a reviewer's decision to a pending match and saves
+// it as the file's stored match.
+func (s *Server) handleReview(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid review id %q", r.PathValue("id")))
+		return
+	}
+	var req reviewRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	items, err := s.store.ListPendingReviews(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	var item *models.ReviewItem
+	for i := range items {
+		if items[i].ID == id {
+			item = &items[i]
+			break
+		}
+	}
+	if item == nil {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("no pending review %d", id))
+		return
+	}
+
+	result := *item.Result
+	var status string
+	switch req.Action {
+	case actionAccept:
+		issue, ok := findCandidate(item.Candidates, req.IssueID)
+		if !ok {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("issue %d is not a candidate of review %d", req.IssueID, id))
+			return
+		}
+		result.Match = models.AcceptCandidate(result.Match, issue)
+		status = models.ReviewAccepted
+	case actionReject:
+		result.Match = models.RejectMatch(result.Match)
+		status = models.ReviewRejected
+	default:
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("unknown action %q (want %s or %s)", req.Action, actionAccept, actionReject))
+		return
+	}
+	item.Result = &result
+
+	if err := s.store.ResolveReview(r.Context(), item, status); err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, item)
+}
+
+func findCandidate(candidates []models.ComicVineIssue, issueID int) (models.ComicVineIssue, bool) {
+	for _, c := range candidates {
+		if c.ID == issueID {
+			return c, true
+		}
+	}
+	return models.ComicVineIssue{}, false
+}
+
+// handleCover serves a cached cover of an issue, in the requested size when
+// it is cached and otherwise the first cached size. Pages fall back to the
+// ComicVine image URL on a 404.
+func (s *Server) handleCover(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid issue id %q", r.PathValue("id")))
+		return
+	}
+	if s.covers == nil {
+		s.writeError(w, r, http.StatusNotFound, errors.New("no cover cache configured"))
+		return
+	}
+
+	sizes := covers.Sizes
+	if size := covers.Size(r.URL.Query().Get("size")); slices.Contains(covers.Sizes, size) {
+		sizes = append([]covers.Size{size}, sizes...)
+	}
+	for _, size := range sizes {
+		if path, ok := s.covers.Lookup(id, size); ok {
+			http.ServeFile(w, r, path)
+			return
+		}
+	}
+	s.writeError(w, r, http.StatusNotFound, fmt.Errorf("no cached cover for issue %d", id))
+}
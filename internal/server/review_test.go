@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"comic-parser/internal/covers"
+	"comic-parser/internal/models"
+)
+
+func TestReviews(t *testing.T) {
+	ts, store := newTestServer(t, nil)
+	ctx := context.Background()
+
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}},
+		{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}},
+	}
+	for _, filename := range []string{"Saga 001.cbz", "Saga 002.cbz"} {
+		result := &models.ProcessingResult{
+			Filename:    filename,
+			Success:     true,
+			ProcessedAt: time.Now(),
+			Match:       &models.MatchResult{SelectedIssue: &candidates[1], ComicVineID: 2, MatchConfidence: "low"},
+		}
+		if err := store.QueueReview(ctx, result, candidates); err != nil {
+			t.Fatalf("QueueReview: %v", err)
+		}
+	}
+
+	var reviews ReviewsResponse
+	if status := get(t, ts.URL+"/reviews", &reviews); status != http.StatusOK || len(reviews.Reviews) != 2 {
+		t.Fatalf("Expected 2 pending reviews, got %d %+v", status, reviews)
+	}
+	accept, reject := reviews.Reviews[0], reviews.Reviews[1]
+	if len(accept.Candidates) != 2 {
+		t.Errorf("Expected the candidates with the review, got %+v", accept.Candidates)
+	}
+
+	var errResp map[string]string
+	if status := post(t, ts.URL+"/reviews/"+fmt.Sprint(accept.ID), `{"action": "accept", "issue_id": 99}`, &errResp); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an issue that is not a candidate, got %d", status)
+	}
+	if status := post(t, ts.URL+"/reviews/"+fmt.Sprint(accept.ID), `{"action": "maybe"}`, &errResp); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown action, got %d", status)
+	}
+
+	var item models.ReviewItem
+	if status := post(t, ts.URL+"/reviews/"+fmt.Sprint(accept.ID), `{"action": "accept", "issue_id": 1}`, &item); status != http.StatusOK {
+		t.Fatalf("Expected 200 accepting, got %d", status)
+	}
+	if item.Status != models.ReviewAccepted || item.Result.Match.ComicVineID != 1 || item.Result.Match.MatchConfidence != "high" {
+		t.Errorf("Unexpected accepted review: %+v", item.Result.Match)
+	}
+	if status := post(t, ts.URL+"/reviews/"+fmt.Sprint(reject.ID), `{"action": "reject"}`, &item); status != http.StatusOK || item.Status != models.ReviewRejected {
+		t.Errorf("Expected the review rejected, got %d %+v", status, item)
+	}
+
+	// Decisions are saved as the stored matches and leave the queue
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		t.Fatalf("ListMatchedResults: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Filename != "Saga 001.cbz" || matched[0].Match.ComicVineID != 1 {
+		t.Errorf("Expected only the accepted match stored, got %+v", matched)
+	}
+	if status := get(t, ts.URL+"/reviews", &reviews); status != http.StatusOK || len(reviews.Reviews) != 0 {
+		t.Errorf("Expected an empty queue, got %+v", reviews.Reviews)
+	}
+	if status := post(t, ts.URL+"/reviews/"+fmt.Sprint(accept.ID), `{"action": "reject"}`, &errResp); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for a resolved review, got %d", status)
+	}
+}
+
+func TestReviewPage(t *testing.T) {
+	ts, _ := newTestServer(t, nil)
+
+	resp, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Request.URL.Path != "/review/" || !strings.Contains(string(body), "Review queue") {
+		t.Errorf("Expected the review page at /review/, got %s", resp.Request.URL)
+	}
+
+	resp, err = http.Get(ts.URL + "/review/app.js")
+	if err != nil {
+		t.Fatalf("GET app.js failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), "javascript") {
+		t.Errorf("Expected app.js, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestCovers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "covers", "7"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "covers", "7", "small.jpg"), []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newTestStore(t)
+	srv := New(testParser, nil, store)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	var errResp map[string]string
+	if status := get(t, ts.URL+"/covers/7", &errResp); status != http.StatusNotFound {
+		t.Errorf("Expected 404 without a cover cache, got %d", status)
+	}
+
+	srv.SetCovers(covers.NewCache(dir, nil))
+	// The requested size falls back to the cached one
+	resp, err := http.Get(ts.URL + "/covers/7?size=medium")
+	if err != nil {
+		t.Fatalf("GET cover failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "jpeg" {
+		t.Errorf("Expected the cached cover, got %d %q", resp.StatusCode, body)
+	}
+	if status := get(t, ts.URL+"/covers/8", &errResp); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an uncached cover, got %d", status)
+	}
+}
@@ -0,0 +1,269 @@
+// Package server exposes parsing, matching and the stored library as a JSON
+// HTTP API, so other tools can integrate without shelling out.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"comic-parser/internal/covers"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/logging"
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/storage"
+)
+
+const (
+	// maxRequestSize caps request bodies, which only carry a filename
+	maxRequestSize = 64 << 10
+
+	// defaultLimit is the page size of /comics when no limit is given
+	defaultLimit = 100
+)
+
+// Matcher matches a filename against the metadata provider.
+// *processor.Processor satisfies it.
+type Matcher interface {
+	ProcessFile(ctx context.Context, filename string) (*models.ProcessingResult, error)
+}
+
+// Server handles the API requests.
+type Server struct {
+	parser  parser.Parser
+	matcher Matcher
+	store   *storage.Storage
+	covers  *covers.Cache
+	logger  *slog.Logger
+}
+
+// New creates a Server that parses with p, matches with matcher and lists
+// comics and runs from store.
+func New(p parser.Parser, matcher Matcher, store *storage.Storage) *Server {
+	return &Server{
+		parser:  p,
+		matcher: matcher,
+		store:   store,
+		logger:  logging.Logger(logging.Server),
+	}
+}
+
+// SetCovers serves cached covers to the review page from cache.
+func (s *Server) SetCovers(cache *covers.Cache) {
+	s.covers = cache
+}
+
+// Handler returns the API routes, the review page and the OPDS catalog:
+//
+//	POST /parse                 {"filename": "..."} → parsed filename
+//	POST /match                 {"filename": "..."} → processing result with the match
+//	GET  /comics                matched comics, filtered by series, publisher, year and confidence
+//	GET  /progress              progress of unfinished batch runs
+//	GET  /reviews               pending matches of the review queue with their candidates
+//	POST /reviews/{id}          {"action": "accept", "issue_id": n} or {"action": "reject"}
+//	GET  /covers/{id}           cached cover of an issue
+//	GET  /review/               web page for working through the review queue
+//	GET  /opds                  OPDS navigation feed of the series, for comic readers
+//	GET  /opds/all              OPDS acquisition feed of every matched comic
+//	GET  /opds/series/{id}      OPDS acquisition feed of a volume
+//	GET  /opds/files/{filename} download of a matched file that exists locally
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /parse", s.handleParse)
+	mux.HandleFunc("POST /match", s.handleMatch)
+	mux.HandleFunc("GET /comics", s.handleComics)
+	mux.HandleFunc("GET /progress", s.handleProgress)
+	mux.HandleFunc("GET /reviews", s.handleReviews)
+	mux.HandleFunc("POST /reviews/{id}", s.handleReview)
+	mux.HandleFunc("GET /covers/{id}", s.handleCover)
+	mux.HandleFunc("GET /opds", s.handleOPDSRoot)
+	mux.HandleFunc("GET /opds/all", s.handleOPDSAll)
+	mux.HandleFunc("GET /opds/series/{id}", s.handleOPDSSeries)
+	mux.HandleFunc("GET /opds/files/{filename}", s.handleOPDSFile)
+	mux.Handle("GET /review/", webHandler())
+	mux.Handle("GET /{$}", http.RedirectHandler("/review/", http.StatusFound))
+	return mux
+}
+
+// fileRequest is the body of /parse and /match.
+type fileRequest struct {
+	Filename string `json:"filename"`
+}
+
+// ComicsResponse is the body returned by /comics.
+type ComicsResponse struct {
+	Total  int                        `json:"total"` // matching comics before limit and offset
+	Comics []*models.ProcessingResult `json:"comics"`
+}
+
+// ProgressResponse is the body returned by /progress.
+type ProgressResponse struct {
+	Runs []models.RunProgress `json:"runs"`
+}
+
+func (s *Server) handleParse(w http.ResponseWriter, r *http.Request) {
+	filename, ok := s.readFilename(w, r)
+	if !ok {
+		return
+	}
+
+	parsed, err := s.parser.Parse(r.Context(), &models.ParsedFilename{OriginalFilename: filename})
+	if err != nil {
+		s.writeError(w, r, failureStatus(err), fmt.Errorf("parsing filename: %w", err))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, parsed)
+}
+
+func (s *Server) handleMatch(w http.ResponseWriter, r *http.Request) {
+	filename, ok := s.readFilename(w, r)
+	if !ok {
+		return
+	}
+
+	// Failed matches are results too: they come back with success false
+	result, err := s.matcher.ProcessFile(r.Context(), filename)
+	if err != nil {
+		s.writeError(w, r, failureStatus(err), err)
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, result)
+}
+
+func (s *Server) handleComics(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseComicFilter(r)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	results, err := s.store.ListMatchedResults(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	resp := ComicsResponse{Comics: []*models.ProcessingResult{}}
+	for _, result := range results {
+		if !filter.match(result) {
+			continue
+		}
+		if resp.Total >= filter.offset && (filter.limit == 0 || len(resp.Comics) < filter.limit) {
+			resp.Comics = append(resp.Comics, result)
+		}
+		resp.Total++
+	}
+	s.writeJSON(w, r, http.StatusOK, resp)
+}
+
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	runs, err := s.store.ListRunProgress(r.Context())
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, ProgressResponse{Runs: runs})
+}
+
+// comicFilter selects matched comics for /comics. Text filters are
+// case-insensitive substrings; zero values match everything.
+type comicFilter struct {
+	series     string
+	publisher  string
+	year       int
+	confidence string
+	language   string
+	limit      int
+	offset     int
+}
+
+func parseComicFilter(r *http.Request) (comicFilter, error) {
+	q := r.URL.Query()
+	f := comicFilter{
+		series:     strings.ToLower(q.Get("series")),
+		publisher:  strings.ToLower(q.Get("publisher")),
+		confidence: strings.ToLower(q.Get("confidence")),
+		language:   q.Get("language"),
+		limit:      defaultLimit,
+	}
+	if !models.ValidLanguageFilter(f.language) {
+		return comicFilter{}, fmt.Errorf("invalid language %q", f.language)
+	}
+	for name, dst := range map[string]*int{"year": &f.year, "limit": &f.limit, "offset": &f.offset} {
+		value := q.Get(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return comicFilter{}, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*dst = n
+	}
+	return f, nil
+}
+
+func (f comicFilter) match(result *models.ProcessingResult) bool {
+	issue := result.Match.SelectedIssue
+	switch {
+	case f.series != "" && !strings.Contains(strings.ToLower(issue.Volume.Name), f.series):
+		return false
+	case f.publisher != "" && !strings.Contains(strings.ToLower(issue.Volume.Publisher), f.publisher):
+		return false
+	case f.year != 0 && issue.CoverDate.Year != f.year:
+		return false
+	case f.confidence != "" && result.Match.MatchConfidence != f.confidence:
+		return false
+	case !models.MatchesLanguage(result.Match.ParsedInfo.Language, f.language):
+		return false
+	}
+	return true
+}
+
+// readFilename decodes a fileRequest, writing a 400 response when the body
+// is invalid or has no filename.
+func (s *Server) readFilename(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req fileRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return "", false
+	}
+	if strings.TrimSpace(req.Filename) == "" {
+		s.writeError(w, r, http.StatusBadRequest, errors.New("filename is required"))
+		return "", false
+	}
+	return req.Filename, true
+}
+
+// failureStatus maps a parse or match error to a response status.
+func failureStatus(err error) int {
+	switch {
+	case errors.Is(err, llm.ErrBudgetExceeded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusUnprocessableEntity
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Debug("writing response failed", "path", r.URL.Path, "error", err)
+	}
+}
+
+// writeError responds with {"error": "..."}. Server errors are also logged.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		s.logger.Warn("request failed", "method", r.Method, "path", r.URL.Path, "status", status, "error", err)
+	}
+	s.writeJSON(w, r, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"comic-parser/internal/llm"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+)
+
+type matcherFunc func(ctx context.Context, filename string) (*models.ProcessingResult, error)
+
+func (f matcherFunc) ProcessFile(ctx context.Context, filename string) (*models.ProcessingResult, error) {
+	return f(ctx, filename)
+}
+
+type parserFunc func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error)
+
+func (f parserFunc) Parse(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	return f(ctx, input)
+}
+
+// testParser parses "Title NNN (YYYY).cbz"
+var testParser = parserFunc(func(ctx context.Context, input *models.ParsedFilename) (*models.ParsedFilename, error) {
+	var title, issue, year string
+	if _, err := fmt.Sscanf(input.OriginalFilename, "%s %s (%4s).cbz", &title, &issue, &year); err != nil {
+		return nil, fmt.Errorf("unrecognized filename: %w", err)
+	}
+	return &models.ParsedFilename{OriginalFilename: input.OriginalFilename, Title: title, IssueNumber: issue, Year: year, Confidence: "high"}, nil
+})
+
+func newTestStore(t *testing.T) *storage.Storage {
+	t.Helper()
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "comics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func newTestServer(t *testing.T, matcher Matcher) (*httptest.Server, *storage.Storage) {
+	t.Helper()
+	store := newTestStore(t)
+	ts := httptest.NewServer(New(testParser, matcher, store).Handler())
+	t.Cleanup(ts.Close)
+	return ts, store
+}
+
+func post(t *testing.T, url, body string, v any) int {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Decoding response failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func get(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Decoding response failed: %v", err)
+	}
+	return resp.StatusCode
+}
+
+func TestParse(t *testing.T) {
+	ts, _ := newTestServer(t, nil)
+
+	var parsed models.ParsedFilename
+	if status := post(t, ts.URL+"/parse", `{"filename": "Saga 001 (2012).cbz"}`, &parsed); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if parsed.Title != "Saga" || parsed.Year != "2012" {
+		t.Errorf("Unexpected parse: %+v", parsed)
+	}
+
+	var errResp map[string]string
+	if status := post(t, ts.URL+"/parse", `{"filename": "notes.txt"}`, &errResp); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an unparseable filename, got %d %v", status, errResp)
+	}
+
+	for _, body := range []string{`{}`, `not json`} {
+		var errResp map[string]string
+		if status := post(t, ts.URL+"/parse", body, &errResp); status != http.StatusBadRequest || errResp["error"] == "" {
+			t.Errorf("Expected a 400 error for %q, got %d %v", body, status, errResp)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/parse")
+	if err != nil {
+		t.Fatalf("GET /parse failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /parse, got %d", resp.StatusCode)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	ts, _ := newTestServer(t, matcherFunc(func(ctx context.Context, filename string) (*models.ProcessingResult, error) {
+		switch filename {
+		case "over budget.cbz":
+			return nil, fmt.Errorf("LLM completion: %w", llm.ErrBudgetExceeded)
+		case "unknown.cbz":
+			return &models.ProcessingResult{Filename: filename, Error: "searching comicvine: no results"}, nil
+		}
+		return &models.ProcessingResult{
+			Filename: filename,
+			Success:  true,
+			Match:    &models.MatchResult{MatchConfidence: "high", ComicVineID: 7},
+		}, nil
+	}))
+
+	var result models.ProcessingResult
+	if status := post(t, ts.URL+"/match", `{"filename": "Saga 001.cbz"}`, &result); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if !result.Success || result.Match == nil || result.Match.ComicVineID != 7 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	result = models.ProcessingResult{}
+	if status := post(t, ts.URL+"/match", `{"filename": "unknown.cbz"}`, &result); status != http.StatusOK || result.Success || result.Error == "" {
+		t.Errorf("Expected a failed result, got %d %+v", status, result)
+	}
+
+	var errResp map[string]string
+	if status := post(t, ts.URL+"/match", `{"filename": "over budget.cbz"}`, &errResp); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 once the budget is spent, got %d %v", status, errResp)
+	}
+}
+
+func TestComics(t *testing.T) {
+	ts, store := newTestServer(t, nil)
+	ctx := context.Background()
+	for i, c := range []struct {
+		filename, series, publisher, confidence, language string
+		year                                              int
+	}{
+		{"Saga 001.cbz", "Saga", "Image", "high", "", 2012},
+		{"Saga 002.cbz", "Saga", "Image", "low", "", 2012},
+		{"Batman 050.cbz", "Batman", "DC Comics", "high", "fr", 2018},
+	} {
+		err := store.SaveResult(ctx, &models.ProcessingResult{
+			Filename:    c.filename,
+			Success:     true,
+			ProcessedAt: time.Now(),
+			Match: &models.MatchResult{
+				ParsedInfo:      models.ParsedFilename{OriginalFilename: c.filename, Language: c.language},
+				MatchConfidence: c.confidence,
+				SelectedIssue: &models.ComicVineIssue{
+					ID:        i + 1,
+					CoverDate: models.Date{Year: c.year},
+					Volume:    models.VolumeRef{ID: 100 + i, Name: c.series, Publisher: c.publisher},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("SaveResult: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		total int
+		want  []string
+	}{
+		{"", 3, []string{"Batman 050.cbz", "Saga 001.cbz", "Saga 002.cbz"}},
+		{"?series=saga", 2, []string{"Saga 001.cbz", "Saga 002.cbz"}},
+		{"?series=saga&confidence=high", 1, []string{"Saga 001.cbz"}},
+		{"?publisher=dc&year=2018", 1, []string{"Batman 050.cbz"}},
+		{"?year=1999", 0, nil},
+		{"?language=translated", 1, []string{"Batman 050.cbz"}},
+		{"?language=french", 1, []string{"Batman 050.cbz"}},
+		{"?language=original", 2, []string{"Saga 001.cbz", "Saga 002.cbz"}},
+		{"?limit=1&offset=1", 3, []string{"Saga 001.cbz"}},
+	}
+	for _, tt := range tests {
+		var resp ComicsResponse
+		if status := get(t, ts.URL+"/comics"+tt.query, &resp); status != http.StatusOK {
+			t.Fatalf("GET /comics%s: expected 200, got %d", tt.query, status)
+		}
+		var got []string
+		for _, c := range resp.Comics {
+			got = append(got, c.Filename)
+		}
+		if resp.Total != tt.total || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("GET /comics%s: got %d total %v, want %d total %v", tt.query, resp.Total, got, tt.total, tt.want)
+		}
+	}
+
+	var errResp map[string]string
+	if status := get(t, ts.URL+"/comics?year=recent", &errResp); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid year, got %d", status)
+	}
+	if status := get(t, ts.URL+"/comics?language=klingon", &errResp); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown language, got %d", status)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	ts, store := newTestServer(t, nil)
+	ctx := context.Background()
+
+	var resp ProgressResponse
+	if status := get(t, ts.URL+"/progress", &resp); status != http.StatusOK || resp.Runs == nil || len(resp.Runs) != 0 {
+		t.Fatalf("Expected an empty run list, got %d %+v", status, resp)
+	}
+
+	run := &models.BatchRun{StartedAt: time.Now(), Mode: "parse", InputSource: "list.txt", Total: 2}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("CreateBatchRun: %v", err)
+	}
+	runStore := store.WithRun(run.ID)
+	if err := runStore.QueueCheckpoints(ctx, []string{"a.cbz", "b.cbz"}); err != nil {
+		t.Fatalf("QueueCheckpoints: %v", err)
+	}
+	if err := runStore.SetCheckpoint(ctx, "a.cbz", models.CheckpointDone, nil); err != nil {
+		t.Fatalf("SetCheckpoint: %v", err)
+	}
+
+	resp = ProgressResponse{}
+	if status := get(t, ts.URL+"/progress", &resp); status != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", status)
+	}
+	if len(resp.Runs) != 1 || resp.Runs[0].Run.ID != run.ID || resp.Runs[0].Done != 1 || resp.Runs[0].Queued != 1 {
+		t.Errorf("Unexpected progress: %+v", resp.Runs)
+	}
+}
+
+func TestOPDS(t *testing.T) {
+	ts, store := newTestServer(t, nil)
+	ctx := context.Background()
+	local := filepath.Join(t.TempDir(), "Saga 001.cbz")
+	if err := os.WriteFile(local, []byte("archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range []struct{ filename, path, series string }{
+		{"Saga 001.cbz", local, "Saga"},
+		{"Batman 050.cbz", "", "Batman"},
+	} {
+		err := store.SaveResult(ctx, &models.ProcessingResult{
+			Filename:    c.filename,
+			Path:        c.path,
+			Success:     true,
+			ProcessedAt: time.Now(),
+			Match: &models.MatchResult{
+				MatchConfidence: "high",
+				SelectedIssue: &models.ComicVineIssue{
+					ID:          i + 1,
+					IssueNumber: "1",
+					Volume:      models.VolumeRef{ID: 100 + i, Name: c.series},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("SaveResult: %v", err)
+		}
+	}
+
+	fetch := func(path string) (int, string, string) {
+		t.Helper()
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+
+	status, kind, body := fetch("/opds")
+	if status != http.StatusOK || !strings.Contains(kind, "kind=navigation") {
+		t.Fatalf("GET /opds = %d %s", status, kind)
+	}
+	for _, want := range []string{`href="/opds/all"`, `href="/opds/series/100"`, `href="/opds/series/101"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("root feed is missing %s:\n%s", want, body)
+		}
+	}
+
+	status, kind, body = fetch("/opds/series/100")
+	if status != http.StatusOK || !strings.Contains(kind, "kind=acquisition") || !strings.Contains(body, `href="/opds/files/Saga%20001.cbz"`) {
+		t.Errorf("GET /opds/series/100 = %d %s:\n%s", status, kind, body)
+	}
+	if _, _, body = fetch("/opds/series/101"); strings.Contains(body, "/opds/files/") {
+		t.Errorf("Expected no download link for a file that isn't local:\n%s", body)
+	}
+	if status, _, _ = fetch("/opds/series/999"); status != http.StatusNotFound {
+		t.Errorf("GET /opds/series/999: expected 404, got %d", status)
+	}
+
+	status, kind, body = fetch("/opds/files/Saga%20001.cbz")
+	if status != http.StatusOK || kind != "application/vnd.comicbook+zip" || body != "archive" {
+		t.Errorf("GET file = %d %s %q", status, kind, body)
+	}
+	if status, _, _ = fetch("/opds/files/Batman%20050.cbz"); status != http.StatusNotFound {
+		t.Errorf("GET file without a local path: expected 404, got %d", status)
+	}
+}
+
+func TestFailureStatus(t *testing.T) {
+	if got := failureStatus(errors.New("unparseable")); got != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422, got %d", got)
+	}
+	if got := failureStatus(fmt.Errorf("parse: %w", llm.ErrBudgetExceeded)); got != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", got)
+	}
+}
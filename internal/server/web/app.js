@@ -0,0 +1,125 @@
+// Review queue page: lists pending matches with their candidates and posts
+// the reviewer's decisions to /reviews/{id}.
+"use strict";
+
+const reviewsEl = document.getElementById("reviews");
+const statusEl = document.getElementById("status");
+const reviewTemplate = document.getElementById("review-template");
+const candidateTemplate = document.getElementById("candidate-template");
+
+let pending = 0;
+
+function updateStatus() {
+  statusEl.textContent = pending === 0
+    ? "The review queue is empty."
+    : `${pending} match${pending === 1 ? "" : "es"} to review. Confirm the right issue or reject the match.`;
+}
+
+function describeParsed(parsed) {
+  if (!parsed) {
+    return "";
+  }
+  const parts = [parsed.title, parsed.issue_number && `#${parsed.issue_number}`, parsed.year && `(${parsed.year})`];
+  return "Parsed as " + parts.filter(Boolean).join(" ");
+}
+
+function describeIssue(issue) {
+  const volume = issue.volume || {};
+  const parts = [volume.publisher_name, volume.start_year && `vol. ${volume.start_year}`, issue.cover_date, issue.name];
+  return parts.filter(Boolean).join(" · ");
+}
+
+// setCover shows the cover from the server's cache, falling back to the
+// ComicVine image when the cover is not cached.
+function setCover(img, issue) {
+  const image = issue.image || {};
+  const remote = image.medium_url || image.small_url || "";
+  img.src = `/covers/${issue.id}?size=medium`;
+  img.onerror = () => {
+    img.onerror = null;
+    if (remote) {
+      img.src = remote;
+    } else {
+      img.removeAttribute("src");
+      img.classList.add("missing");
+    }
+  };
+}
+
+async function decide(item, section, body) {
+  const errorEl = section.querySelector(".error");
+  errorEl.textContent = "";
+  section.querySelectorAll("button").forEach((b) => (b.disabled = true));
+  try {
+    const resp = await fetch(`/reviews/${item.id}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+    });
+    if (!resp.ok) {
+      const data = await resp.json().catch(() => ({}));
+      throw new Error(data.error || resp.statusText);
+    }
+    section.remove();
+    pending--;
+    updateStatus();
+  } catch (err) {
+    errorEl.textContent = `Could not save: ${err.message}`;
+    section.querySelectorAll("button").forEach((b) => (b.disabled = false));
+  }
+}
+
+function renderReview(item) {
+  const section = reviewTemplate.content.firstElementChild.cloneNode(true);
+  const match = (item.result && item.result.match) || {};
+  const selectedID = match.selected_issue ? match.selected_issue.id : 0;
+
+  section.querySelector(".filename").textContent = item.filename;
+  section.querySelector(".parsed").textContent = describeParsed(match.parsed_info);
+  section.querySelector(".reasoning").textContent =
+    `${match.match_confidence || "unknown"} confidence: ${match.reasoning || "no reasoning given"}`;
+
+  const candidatesEl = section.querySelector(".candidates");
+  for (const issue of item.candidates) {
+    const figure = candidateTemplate.content.firstElementChild.cloneNode(true);
+    if (issue.id === selectedID) {
+      figure.classList.add("selected");
+    }
+    setCover(figure.querySelector("img"), issue);
+    figure.querySelector(".title").textContent = `${(issue.volume || {}).name || "Unknown"} #${issue.issue_number}`;
+    figure.querySelector(".details").textContent = describeIssue(issue);
+    const link = figure.querySelector(".link");
+    if (issue.site_detail_url) {
+      link.href = issue.site_detail_url;
+    } else {
+      link.remove();
+    }
+    figure.querySelector(".accept").addEventListener("click", () =>
+      decide(item, section, { action: "accept", issue_id: issue.id }));
+    candidatesEl.appendChild(figure);
+  }
+  if (item.candidates.length === 0) {
+    candidatesEl.textContent = "No candidates were found.";
+  }
+
+  section.querySelector(".reject").addEventListener("click", () =>
+    decide(item, section, { action: "reject" }));
+  return section;
+}
+
+async function load() {
+  try {
+    const resp = await fetch("/reviews");
+    if (!resp.ok) {
+      throw new Error(resp.statusText);
+    }
+    const data = await resp.json();
+    pending = data.reviews.length;
+    reviewsEl.replaceChildren(...data.reviews.map(renderReview));
+    updateStatus();
+  } catch (err) {
+    statusEl.textContent = `Could not load the review queue: ${err.message}`;
+  }
+}
+
+load();
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ErrAliasNotFound is returned for series aliases that don't exist.
+var ErrAliasNotFound = errors.New("storage: series alias not found")
+
+// SaveSeriesAlias stores a series alias, replacing the alias of any name
+// with the same SeriesAliasKey.
+func (s *Storage) SaveSeriesAlias(ctx context.Context, alias models.SeriesAlias) error {
+	key := models.SeriesAliasKey(alias.Name)
+	if key == "" {
+		return fmt.Errorf("storage: series alias %q has no letters or digits", alias.Name)
+	}
+	createdAt := alias.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return s.write(ctx, func(qtx *db.Queries) error {
+		err := qtx.UpsertSeriesAlias(ctx, db.UpsertSeriesAliasParams{
+			Key:       key,
+			Name:      alias.Name,
+			Series:    alias.Series,
+			VolumeID:  sql.NullInt64{Int64: int64(alias.VolumeID), Valid: alias.VolumeID > 0},
+			CreatedAt: createdAt,
+		})
+		if err != nil {
+			return fmt.Errorf("storage: save series alias %s: %w", alias.Name, err)
+		}
+		return nil
+	})
+}
+
+// SeriesAlias returns the alias of a series name as filenames spell it,
+// reporting false when it has none.
+func (s *Storage) SeriesAlias(ctx context.Context, name string) (*models.SeriesAlias, bool, error) {
+	key := models.SeriesAliasKey(name)
+	if key == "" {
+		return nil, false, nil
+	}
+	row, err := s.q.GetSeriesAlias(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("storage: get series alias %s: %w", name, err)
+	}
+	alias := seriesAliasFromRow(row)
+	return &alias, true, nil
+}
+
+// ListSeriesAliases returns the series aliases by name.
+func (s *Storage) ListSeriesAliases(ctx context.Context) ([]models.SeriesAlias, error) {
+	rows, err := s.q.ListSeriesAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list series aliases: %w", err)
+	}
+	aliases := make([]models.SeriesAlias, 0, len(rows))
+	for _, row := range rows {
+		aliases = append(aliases, seriesAliasFromRow(row))
+	}
+	return aliases, nil
+}
+
+// DeleteSeriesAlias removes the alias of a series name.
+func (s *Storage) DeleteSeriesAlias(ctx context.Context, name string) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		n, err := qtx.DeleteSeriesAlias(ctx, models.SeriesAliasKey(name))
+		if err != nil {
+			return fmt.Errorf("storage: delete series alias %s: %w", name, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("%w: %s", ErrAliasNotFound, name)
+		}
+		return nil
+	})
+}
+
+func seriesAliasFromRow(row db.SeriesAlias) models.SeriesAlias {
+	return models.SeriesAlias{
+		Name:      row.Name,
+		Series:    row.Series,
+		VolumeID:  int(row.VolumeID.Int64),
+		CreatedAt: row.CreatedAt,
+	}
+}
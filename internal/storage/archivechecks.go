@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// SaveArchiveCheck stores the check of an archive, replacing any earlier
+// check of the same path.
+func (s *Storage) SaveArchiveCheck(ctx context.Context, check *models.ArchiveCheck) error {
+	var problems sql.NullString
+	if len(check.Problems) > 0 {
+		data, err := json.Marshal(check.Problems)
+		if err != nil {
+			return fmt.Errorf("storage: encode problems: %w", err)
+		}
+		problems = sql.NullString{String: string(data), Valid: true}
+	}
+	return s.write(ctx, func(qtx *db.Queries) error {
+		err := qtx.UpsertArchiveCheck(ctx, db.UpsertArchiveCheckParams{
+			Path:      check.Path,
+			PageCount: int64(check.PageCount),
+			Health:    check.Health,
+			Problems:  problems,
+			CheckedAt: check.CheckedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("storage: save archive check %s: %w", check.Path, err)
+		}
+		return nil
+	})
+}
+
+// ListArchiveChecks returns the latest check of every archive, by path.
+func (s *Storage) ListArchiveChecks(ctx context.Context) ([]models.ArchiveCheck, error) {
+	rows, err := s.q.ListArchiveChecks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list archive checks: %w", err)
+	}
+	checks := make([]models.ArchiveCheck, 0, len(rows))
+	for _, row := range rows {
+		check := models.ArchiveCheck{
+			Path:      row.Path,
+			PageCount: int(row.PageCount),
+			Health:    row.Health,
+			CheckedAt: row.CheckedAt,
+		}
+		if row.Problems.Valid {
+			if err := json.Unmarshal([]byte(row.Problems.String), &check.Problems); err != nil {
+				return nil, fmt.Errorf("storage: decode problems of %s: %w", row.Path, err)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
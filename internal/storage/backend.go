@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Built-in backend names
+const (
+	BackendSQLite = "sqlite"
+	BackendMemory = "memory"
+
+	// DefaultBackend is used when no backend is configured or named in the DSN
+	DefaultBackend = BackendSQLite
+)
+
+// Factory opens a storage backend from a backend-specific data source name.
+type Factory func(dsn string) (*Storage, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Factory{
+		BackendSQLite: NewStorage,
+		BackendMemory: newMemoryStorage,
+	}
+
+	// memoryDBCount gives each in-memory database a unique name
+	memoryDBCount atomic.Int64
+)
+
+// Register makes a storage backend available by name. Like database/sql
+// drivers, it panics if the name is registered twice or factory is nil.
+func Register(name string, factory Factory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if factory == nil {
+		panic("storage: Register factory is nil")
+	}
+	if _, dup := backends[name]; dup {
+		panic("storage: Register called twice for backend " + name)
+	}
+	backends[name] = factory
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens storage with the named backend. When backend is empty, a
+// registered "<backend>:" prefix on dsn selects it (e.g. "memory:"),
+// otherwise DefaultBackend is used with dsn as a database path.
+func Open(backend, dsn string) (*Storage, error) {
+	if backend == "" {
+		backend = DefaultBackend
+		if name, rest, ok := strings.Cut(dsn, ":"); ok && lookupBackend(name) != nil {
+			backend, dsn = name, rest
+		}
+	}
+
+	factory := lookupBackend(backend)
+	if factory == nil {
+		return nil, fmt.Errorf("unknown storage backend %q (available: %s)", backend, strings.Join(Backends(), ", "))
+	}
+	return factory(dsn)
+}
+
+func lookupBackend(name string) Factory {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	return backends[name]
+}
+
+// newMemoryStorage opens a private in-memory SQLite database. The dsn is ignored.
+func newMemoryStorage(string) (*Storage, error) {
+	name := fmt.Sprintf("file:comic-parser-mem-%d?mode=memory&cache=shared", memoryDBCount.Add(1))
+	return NewStorage(name)
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// QueueCheckpoints records filenames as queued for the current run, so an
+// interrupted run knows which files it never reached. It does nothing when
+// the storage is not scoped to a run.
+func (s *Storage) QueueCheckpoints(ctx context.Context, filenames []string) error {
+	if s.runID == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.q.WithTx(tx)
+	now := time.Now()
+	for _, filename := range filenames {
+		err := qtx.UpsertCheckpoint(ctx, db.UpsertCheckpointParams{
+			RunID:     s.runID,
+			Filename:  filename,
+			State:     models.CheckpointQueued,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to queue checkpoint for %s: %w", filename, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetCheckpoint records the processing state of a file in the current run.
+// cause is stored for failed files. It does nothing when the storage is not
+// scoped to a run.
+func (s *Storage) SetCheckpoint(ctx context.Context, filename, state string, cause error) error {
+	if s.runID == 0 {
+		return nil
+	}
+
+	var errText sql.NullString
+	if cause != nil {
+		errText = sql.NullString{String: cause.Error(), Valid: true}
+	}
+	err := s.write(ctx, func(qtx *db.Queries) error {
+		return qtx.UpsertCheckpoint(ctx, db.UpsertCheckpointParams{
+			RunID:     s.runID,
+			Filename:  filename,
+			State:     state,
+			Error:     errText,
+			UpdatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("storage: set checkpoint for %s: %w", filename, err)
+	}
+	return nil
+}
+
+// FindResumableRun returns the latest run with the given mode and input
+// source that still has files not done, or nil if there is none.
+func (s *Storage) FindResumableRun(ctx context.Context, mode, inputSource string) (*models.BatchRun, error) {
+	row, err := s.q.FindResumableRun(ctx, db.FindResumableRunParams{Mode: mode, InputSource: inputSource})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: find resumable run: %w", err)
+	}
+	return batchRunFromDB(row), nil
+}
+
+// PendingCheckpoints returns the files of a run that are queued, were in
+// progress when it stopped, or failed, in their original order.
+func (s *Storage) PendingCheckpoints(ctx context.Context, runID int64) ([]string, error) {
+	filenames, err := s.q.ListPendingCheckpoints(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list pending checkpoints: %w", err)
+	}
+	return filenames, nil
+}
+
+// ListRunProgress returns the progress of every run that has not finished,
+// newest first, counted from the checkpoints of its files, with the latest
+// snapshot the run saved. Runs killed without finishing stay listed until
+// they are resumed.
+func (s *Storage) ListRunProgress(ctx context.Context) ([]models.RunProgress, error) {
+	rows, err := s.q.ListUnfinishedBatchRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list unfinished batch runs: %w", err)
+	}
+
+	progress := make([]models.RunProgress, 0, len(rows))
+	for _, row := range rows {
+		counts, err := s.q.CountCheckpointsByState(ctx, row.ID)
+		if err != nil {
+			return nil, fmt.Errorf("storage: count checkpoints of run %d: %w", row.ID, err)
+		}
+		p := models.RunProgress{Run: batchRunFromDB(row)}
+		if p.Snapshot, err = s.runSnapshot(ctx, row.ID); err != nil {
+			return nil, err
+		}
+		for _, c := range counts {
+			switch c.State {
+			case models.CheckpointQueued:
+				p.Queued = int(c.Count)
+			case models.CheckpointInProgress:
+				p.InProgress = int(c.Count)
+			case models.CheckpointDone:
+				p.Done = int(c.Count)
+			case models.CheckpointFailed:
+				p.Failed = int(c.Count)
+			}
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
+
+// SaveRunProgress records a snapshot of the current run's progress,
+// replacing the previous one. It does nothing when the storage is not
+// scoped to a run.
+func (s *Storage) SaveRunProgress(ctx context.Context, progress models.BatchProgress) error {
+	if s.runID == 0 {
+		return nil
+	}
+
+	snapshot, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("storage: encode progress of run %d: %w", s.runID, err)
+	}
+	err = s.write(ctx, func(qtx *db.Queries) error {
+		return qtx.UpsertRunProgress(ctx, db.UpsertRunProgressParams{
+			RunID:     s.runID,
+			Snapshot:  string(snapshot),
+			UpdatedAt: progress.UpdatedAt,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("storage: save progress of run %d: %w", s.runID, err)
+	}
+	return nil
+}
+
+// runSnapshot returns the latest progress snapshot of a run, or nil if it
+// saved none.
+func (s *Storage) runSnapshot(ctx context.Context, runID int64) (*models.BatchProgress, error) {
+	snapshot, err := s.q.GetRunProgress(ctx, runID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get progress of run %d: %w", runID, err)
+	}
+
+	var progress models.BatchProgress
+	if err := json.Unmarshal([]byte(snapshot), &progress); err != nil {
+		return nil, fmt.Errorf("storage: decode progress of run %d: %w", runID, err)
+	}
+	return &progress, nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// SaveCollection stores the outcome of matching a collected edition file,
+// replacing any earlier one for the same file.
+func (s *Storage) SaveCollection(ctx context.Context, result *models.CollectionResult) error {
+	params := db.UpsertCollectionParams{
+		Filename:    result.Filename,
+		Path:        sql.NullString{String: result.Path, Valid: result.Path != ""},
+		Success:     result.Success,
+		Error:       sql.NullString{String: result.Error, Valid: result.Error != ""},
+		ProcessedAt: result.ProcessedAt,
+	}
+	if params.ProcessedAt.IsZero() {
+		params.ProcessedAt = time.Now()
+	}
+	if result.Parsed != nil {
+		parsed, err := json.Marshal(result.Parsed)
+		if err != nil {
+			return fmt.Errorf("storage: encode collection parse: %w", err)
+		}
+		params.Parsed = sql.NullString{String: string(parsed), Valid: true}
+		params.Format = sql.NullString{String: result.Parsed.Format, Valid: result.Parsed.Format != ""}
+	}
+	if vol := result.Volume; vol != nil {
+		volume, err := json.Marshal(vol)
+		if err != nil {
+			return fmt.Errorf("storage: encode collection volume: %w", err)
+		}
+		params.Volume = sql.NullString{String: string(volume), Valid: true}
+		params.VolumeName = sql.NullString{String: vol.Name, Valid: true}
+		params.ComicvineVolumeID = sql.NullInt64{Int64: int64(vol.ID), Valid: true}
+		params.MatchConfidence = sql.NullString{String: result.Confidence, Valid: result.Confidence != ""}
+	}
+
+	return s.write(ctx, func(qtx *db.Queries) error {
+		if err := qtx.UpsertCollection(ctx, params); err != nil {
+			return fmt.Errorf("storage: save collection %s: %w", result.Filename, err)
+		}
+		return nil
+	})
+}
+
+// ListCollections returns the stored collected edition results, by
+// filename.
+func (s *Storage) ListCollections(ctx context.Context) ([]*models.CollectionResult, error) {
+	rows, err := s.q.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list collections: %w", err)
+	}
+
+	results := make([]*models.CollectionResult, 0, len(rows))
+	for _, row := range rows {
+		result := &models.CollectionResult{
+			Filename:    row.Filename,
+			Path:        row.Path.String,
+			Success:     row.Success,
+			Error:       row.Error.String,
+			Confidence:  row.MatchConfidence.String,
+			ProcessedAt: row.ProcessedAt,
+		}
+		if row.Parsed.Valid {
+			if err := json.Unmarshal([]byte(row.Parsed.String), &result.Parsed); err != nil {
+				return nil, fmt.Errorf("storage: decode collection parse of %s: %w", row.Filename, err)
+			}
+		}
+		if row.Volume.Valid {
+			if err := json.Unmarshal([]byte(row.Volume.String), &result.Volume); err != nil {
+				return nil, fmt.Errorf("storage: decode collection volume of %s: %w", row.Filename, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// SaveMatchCorrection records a match the user chose by hand. A zero
+// CreatedAt is set to now.
+func (s *Storage) SaveMatchCorrection(ctx context.Context, c models.MatchCorrection) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		return saveMatchCorrection(ctx, qtx, c)
+	})
+}
+
+func saveMatchCorrection(ctx context.Context, qtx *db.Queries, c models.MatchCorrection) error {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	err := qtx.CreateMatchCorrection(ctx, db.CreateMatchCorrectionParams{
+		Filename:             c.Filename,
+		Title:                c.Title,
+		IssueNumber:          c.IssueNumber,
+		Year:                 c.Year,
+		OriginalComicvineID:  sql.NullInt64{Int64: int64(c.OriginalComicVineID), Valid: c.OriginalComicVineID != 0},
+		ComicvineID:          sql.NullInt64{Int64: int64(c.ComicVineID), Valid: c.ComicVineID != 0},
+		VolumeName:           c.VolumeName,
+		VolumeStartYear:      c.VolumeStartYear,
+		CorrectedIssueNumber: c.CorrectedIssue,
+		Source:               c.Source,
+		CreatedAt:            c.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("storage: save match correction of %s: %w", c.Filename, err)
+	}
+	return nil
+}
+
+// AssignMatch stores result, whose match was chosen by hand, and records the
+// match as a correction from source when it differs from the one stored for
+// the file before.
+func (s *Storage) AssignMatch(ctx context.Context, result *models.ProcessingResult, source string) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		var original *models.MatchResult
+		prev, err := qtx.GetProcessingResult(ctx, result.Filename)
+		switch {
+		case err == nil:
+			original = &models.MatchResult{ComicVineID: int(prev.ComicvineID.Int64)}
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("storage: get processing result of %s: %w", result.Filename, err)
+		}
+
+		if err := s.saveResult(ctx, qtx, result); err != nil {
+			return err
+		}
+		if match := result.Match; match != nil && matchID(match) != matchID(original) {
+			correction := models.NewMatchCorrection(match, original, source)
+			correction.Filename = result.Filename
+			return saveMatchCorrection(ctx, qtx, correction)
+		}
+		return nil
+	})
+}
+
+// ListMatchCorrections returns up to limit match corrections, newest first.
+func (s *Storage) ListMatchCorrections(ctx context.Context, limit int) ([]models.MatchCorrection, error) {
+	rows, err := s.q.ListMatchCorrections(ctx, int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("storage: list match corrections: %w", err)
+	}
+	corrections := make([]models.MatchCorrection, 0, len(rows))
+	for _, row := range rows {
+		corrections = append(corrections, models.MatchCorrection{
+			Filename:            row.Filename,
+			Title:               row.Title,
+			IssueNumber:         row.IssueNumber,
+			Year:                row.Year,
+			OriginalComicVineID: int(row.OriginalComicvineID.Int64),
+			ComicVineID:         int(row.ComicvineID.Int64),
+			VolumeName:          row.VolumeName,
+			VolumeStartYear:     row.VolumeStartYear,
+			CorrectedIssue:      row.CorrectedIssueNumber,
+			Source:              row.Source,
+			CreatedAt:           row.CreatedAt,
+		})
+	}
+	return corrections, nil
+}
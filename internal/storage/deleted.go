@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ResultRef identifies a stored processing result.
+type ResultRef struct {
+	ID        int64
+	Filename  string
+	DeletedAt time.Time // zero unless the result is soft-deleted
+}
+
+// GetResultRef returns the processing result with database ID id, whether or
+// not it is soft-deleted.
+func (s *Storage) GetResultRef(ctx context.Context, id int64) (*ResultRef, error) {
+	row, err := s.q.GetResultRef(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("storage: no processing result %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get processing result %d: %w", id, err)
+	}
+	return &ResultRef{ID: row.ID, Filename: row.Filename, DeletedAt: row.DeletedAt.Time}, nil
+}
+
+// FindResults returns the processing results matched to the given issue of
+// the ComicVine volume named series, ignoring case and the issue number's
+// zero padding. Soft-deleted results are included.
+func (s *Storage) FindResults(ctx context.Context, series, issue string) ([]ResultRef, error) {
+	rows, err := s.q.ListResultRefsBySeries(ctx, series)
+	if err != nil {
+		return nil, fmt.Errorf("storage: find results for %s #%s: %w", series, issue, err)
+	}
+
+	var refs []ResultRef
+	for _, row := range rows {
+		if !models.SameIssueNumber(row.IssueNumber.String, issue) {
+			continue
+		}
+		refs = append(refs, ResultRef{ID: row.ID, Filename: row.Filename, DeletedAt: row.DeletedAt.Time})
+	}
+	return refs, nil
+}
+
+// ListDeletedResults returns the soft-deleted processing results, oldest
+// deletion first.
+func (s *Storage) ListDeletedResults(ctx context.Context) ([]ResultRef, error) {
+	rows, err := s.q.ListDeletedResults(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list deleted results: %w", err)
+	}
+
+	refs := make([]ResultRef, 0, len(rows))
+	for _, row := range rows {
+		refs = append(refs, ResultRef{ID: row.ID, Filename: row.Filename, DeletedAt: row.DeletedAt.Time})
+	}
+	return refs, nil
+}
+
+// SoftDeleteResults marks the processing results with the given IDs deleted.
+// They are left out of listings, search, statistics and exports until
+// UndeleteResults restores them or Purge removes them; reprocessing a file
+// restores it too.
+func (s *Storage) SoftDeleteResults(ctx context.Context, ids []int64) error {
+	return s.setDeletedAt(ctx, ids, sql.NullTime{Time: time.Now(), Valid: true})
+}
+
+// UndeleteResults clears the deletion mark of the processing results with
+// the given IDs.
+func (s *Storage) UndeleteResults(ctx context.Context, ids []int64) error {
+	return s.setDeletedAt(ctx, ids, sql.NullTime{})
+}
+
+func (s *Storage) setDeletedAt(ctx context.Context, ids []int64, deletedAt sql.NullTime) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		for _, id := range ids {
+			n, err := qtx.SetResultDeletedAt(ctx, db.SetResultDeletedAtParams{DeletedAt: deletedAt, ID: id})
+			if err != nil {
+				return fmt.Errorf("storage: update processing result %d: %w", id, err)
+			}
+			if n == 0 {
+				return fmt.Errorf("storage: no processing result %d", id)
+			}
+		}
+		return nil
+	})
+}
+
+// Purge permanently removes the soft-deleted results with their parses, then
+// the ComicVine issues and volumes only they referenced, as Prune does, and
+// vacuums the database to reclaim the space. With dryRun set, nothing is
+// changed.
+func (s *Storage) Purge(ctx context.Context, dryRun bool) (*PruneStats, error) {
+	deleted, err := s.ListDeletedResults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filenames := make([]string, 0, len(deleted))
+	for _, ref := range deleted {
+		filenames = append(filenames, ref.Filename)
+	}
+
+	stats, err := s.Prune(ctx, filenames, dryRun)
+	if err != nil || dryRun {
+		return stats, err
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return nil, fmt.Errorf("storage: vacuum: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// SaveIssueDetails stores the details of an issue already stored with a
+// match: its description, credits, characters, teams, locations and story
+// arcs, as fetched by an issue detail lookup.
+func (s *Storage) SaveIssueDetails(ctx context.Context, issue *models.ComicVineIssue) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		return saveIssueDetails(ctx, qtx, issue)
+	})
+}
+
+func saveIssueDetails(ctx context.Context, q *db.Queries, issue *models.ComicVineIssue) error {
+	params := db.SetIssueDetailsParams{
+		Description:      sql.NullString{String: issue.Description, Valid: issue.Description != ""},
+		DetailsFetchedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:               int64(issue.ID),
+	}
+	columns := []struct {
+		dst *sql.NullString
+		v   any
+		n   int
+	}{
+		{&params.Credits, issue.Credits, len(issue.Credits)},
+		{&params.Characters, issue.Characters, len(issue.Characters)},
+		{&params.Teams, issue.Teams, len(issue.Teams)},
+		{&params.Locations, issue.Locations, len(issue.Locations)},
+		{&params.StoryArcs, issue.StoryArcs, len(issue.StoryArcs)},
+	}
+	for _, c := range columns {
+		if c.n == 0 {
+			continue
+		}
+		data, err := json.Marshal(c.v)
+		if err != nil {
+			return fmt.Errorf("storage: encode details of issue %d: %w", issue.ID, err)
+		}
+		*c.dst = sql.NullString{String: string(data), Valid: true}
+	}
+	if err := q.SetIssueDetails(ctx, params); err != nil {
+		return fmt.Errorf("storage: save details of issue %d: %w", issue.ID, err)
+	}
+	return nil
+}
+
+// decodeIssueDetails fills in the stored details of an issue from their
+// JSON columns.
+func decodeIssueDetails(issue *models.ComicVineIssue, credits, characters, teams, locations, storyArcs sql.NullString) error {
+	columns := []struct {
+		src sql.NullString
+		dst any
+	}{
+		{credits, &issue.Credits},
+		{characters, &issue.Characters},
+		{teams, &issue.Teams},
+		{locations, &issue.Locations},
+		{storyArcs, &issue.StoryArcs},
+	}
+	for _, c := range columns {
+		if !c.src.Valid {
+			continue
+		}
+		if err := json.Unmarshal([]byte(c.src.String), c.dst); err != nil {
+			return fmt.Errorf("storage: decode details of issue %d: %w", issue.ID, err)
+		}
+	}
+	return nil
+}
+
+// IssuesToEnrich returns the IDs of matched issues whose details were never
+// fetched, or were fetched before stale (zero for never-fetched only).
+func (s *Storage) IssuesToEnrich(ctx context.Context, stale time.Time) ([]int, error) {
+	rows, err := s.q.ListMatchedIssueDetails(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list matched issues: %w", err)
+	}
+	var ids []int
+	for _, row := range rows {
+		if !row.DetailsFetchedAt.Valid || row.DetailsFetchedAt.Time.Before(stale) {
+			ids = append(ids, int(row.ID))
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,18 @@
+//go:build cgo
+
+package storage
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqlDriver is the database/sql driver used for SQLite. cgo builds use
+// mattn/go-sqlite3; see driver_purego.go for builds without cgo.
+const sqlDriver = "sqlite3"
+
+// withPragmas adds the connection settings every connection needs to a
+// database path: foreign keys, WAL so readers don't block the writer, and a
+// busy timeout so a connection waits for the write lock instead of failing.
+func withPragmas(dbPath string) string {
+	return dbPath + dsnSeparator(dbPath) + "_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000"
+}
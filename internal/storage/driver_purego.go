@@ -0,0 +1,19 @@
+//go:build !cgo
+
+package storage
+
+import (
+	_ "modernc.org/sqlite"
+)
+
+// sqlDriver is the database/sql driver used for SQLite. Builds without cgo
+// (e.g. cross-compiling for ARM NAS boxes) use the pure-Go modernc.org/sqlite
+// driver, which reads and writes the same database files.
+const sqlDriver = "sqlite"
+
+// withPragmas adds the connection settings every connection needs to a
+// database path: foreign keys, WAL so readers don't block the writer, and a
+// busy timeout so a connection waits for the write lock instead of failing.
+func withPragmas(dbPath string) string {
+	return dbPath + dsnSeparator(dbPath) + "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+}
@@ -0,0 +1,397 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"comic-parser/internal/parquet"
+)
+
+// Dumps leave out SQLite's own tables and the FTS5 index, whose virtual and
+// shadow tables can't be recreated with plain SQL; enableFTS rebuilds it.
+const skipObjects = `name LIKE 'sqlite\_%' ESCAPE '\' OR name LIKE 'search\_fts%' ESCAPE '\'`
+
+// Tables returns the tables a dump or Parquet export can include, in
+// creation order.
+func (s *Storage) Tables(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND NOT ("+skipObjects+") ORDER BY rowid")
+	if err != nil {
+		return nil, fmt.Errorf("storage: list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("storage: list tables: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage: list tables: %w", err)
+	}
+	return tables, nil
+}
+
+// DumpSQL writes the database as SQL statements that recreate it in an
+// empty database, like the sqlite3 shell's .dump. The schema is always
+// complete; tables limits the rows to those of the named tables, or all
+// tables when empty. The schema version is always included so Restore can
+// migrate an older dump.
+func (s *Storage) DumpSQL(ctx context.Context, w io.Writer, tables []string) error {
+	include, err := s.tableFilter(ctx, tables)
+	if err != nil {
+		return err
+	}
+	include["schema_version"] = true
+
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "-- comic-parser database dump, schema version %d\n", version)
+	fmt.Fprintln(w, "PRAGMA foreign_keys=OFF;")
+	fmt.Fprintln(w, "BEGIN TRANSACTION;")
+
+	objects, err := s.schemaObjects(ctx)
+	if err != nil {
+		return err
+	}
+	// Tables and their rows first, then what refers to them; triggers come
+	// last so they don't fire on the dumped rows
+	for _, o := range objects {
+		if o.kind != "table" {
+			continue
+		}
+		fmt.Fprintf(w, "%s;\n", o.sql)
+		if include[o.name] {
+			if err := s.dumpRows(ctx, w, o.name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.dumpSequences(ctx, w, include); err != nil {
+		return err
+	}
+	for _, kind := range []string{"index", "view", "trigger"} {
+		for _, o := range objects {
+			if o.kind == kind {
+				fmt.Fprintf(w, "%s;\n", o.sql)
+			}
+		}
+	}
+	_, err = fmt.Fprintln(w, "COMMIT;")
+	return err
+}
+
+type schemaObject struct {
+	kind, name, sql string
+}
+
+// schemaObjects returns the tables, indexes, views and triggers a dump
+// recreates, in creation order. Automatic indexes have no SQL and come with
+// their tables.
+func (s *Storage) schemaObjects(ctx context.Context) ([]schemaObject, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT type, name, sql FROM sqlite_master WHERE sql IS NOT NULL AND NOT ("+skipObjects+") ORDER BY rowid")
+	if err != nil {
+		return nil, fmt.Errorf("storage: read schema: %w", err)
+	}
+	defer rows.Close()
+
+	var objects []schemaObject
+	for rows.Next() {
+		var o schemaObject
+		if err := rows.Scan(&o.kind, &o.name, &o.sql); err != nil {
+			return nil, fmt.Errorf("storage: read schema: %w", err)
+		}
+		objects = append(objects, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage: read schema: %w", err)
+	}
+	return objects, nil
+}
+
+// dumpRows writes an INSERT per row of table. SQLite's quote() renders each
+// value as the literal that stores it unchanged.
+func (s *Storage) dumpRows(ctx context.Context, w io.Writer, table string) error {
+	columns, err := s.tableColumns(ctx, table)
+	if err != nil {
+		return err
+	}
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = "quote(" + quoteIdent(c.name) + ")"
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), quoteIdent(table)))
+	if err != nil {
+		return fmt.Errorf("storage: dump %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	values := make([]string, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("storage: dump %s: %w", table, err)
+		}
+		if _, err := fmt.Fprintf(w, "INSERT INTO %s VALUES(%s);\n", quoteIdent(table), strings.Join(values, ",")); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("storage: dump %s: %w", table, err)
+	}
+	return nil
+}
+
+// dumpSequences writes the AUTOINCREMENT counters of the dumped tables, so
+// restored tables don't reuse the IDs of deleted rows.
+func (s *Storage) dumpSequences(ctx context.Context, w io.Writer, include map[string]bool) error {
+	rows, err := s.db.QueryContext(ctx, "SELECT quote(name), quote(seq), name FROM sqlite_sequence")
+	if err != nil {
+		return fmt.Errorf("storage: dump sequences: %w", err)
+	}
+	defer rows.Close()
+
+	fmt.Fprintln(w, "DELETE FROM sqlite_sequence;")
+	for rows.Next() {
+		var name, seq, table string
+		if err := rows.Scan(&name, &seq, &table); err != nil {
+			return fmt.Errorf("storage: dump sequences: %w", err)
+		}
+		if include[table] {
+			fmt.Fprintf(w, "INSERT INTO sqlite_sequence VALUES(%s,%s);\n", name, seq)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("storage: dump sequences: %w", err)
+	}
+	return nil
+}
+
+// Restore creates a database at path from a DumpSQL dump, then migrates it,
+// so dumps of older schema versions are brought up to date. path must not
+// exist; a failed restore removes what it created.
+func Restore(ctx context.Context, path string, r io.Reader) (err error) {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("storage: restore: %s already exists", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: restore: %w", err)
+	}
+	dump, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("storage: restore: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+				os.Remove(path + suffix)
+			}
+		}
+	}()
+
+	dbConn, err := sql.Open(sqlDriver, path)
+	if err != nil {
+		return fmt.Errorf("storage: restore: %w", err)
+	}
+	// One connection, as the dump's transaction spans its statements
+	dbConn.SetMaxOpenConns(1)
+	_, err = dbConn.ExecContext(ctx, string(dump))
+	if closeErr := dbConn.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("storage: restore: %w", err)
+	}
+
+	s, err := NewStorage(path)
+	if err != nil {
+		return fmt.Errorf("storage: restore: %w", err)
+	}
+	return s.Close()
+}
+
+// WriteParquet writes the rows of table as a Parquet file and returns how
+// many it wrote. INTEGER, BOOLEAN and REAL columns keep their types; the
+// others, including dates, are written as text.
+func (s *Storage) WriteParquet(ctx context.Context, w io.Writer, table string) (int, error) {
+	if _, err := s.tableFilter(ctx, []string{table}); err != nil {
+		return 0, err
+	}
+	columns, err := s.tableColumns(ctx, table)
+	if err != nil {
+		return 0, err
+	}
+
+	schema := make([]parquet.Column, len(columns))
+	selects := make([]string, len(columns))
+	for i, c := range columns {
+		schema[i] = parquet.Column{Name: c.name, Type: parquetType(c.declType)}
+		selects[i] = quoteIdent(c.name)
+		if schema[i].Type == parquet.String {
+			// Without a declared type the driver doesn't turn dates into
+			// time.Time
+			selects[i] = "CAST(" + selects[i] + " AS TEXT)"
+		}
+	}
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), quoteIdent(table)))
+	if err != nil {
+		return 0, fmt.Errorf("storage: export %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var data [][]any
+	for rows.Next() {
+		row := make([]any, len(columns))
+		dest := make([]any, len(columns))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return 0, fmt.Errorf("storage: export %s: %w", table, err)
+		}
+		for i, v := range row {
+			row[i] = parquetValue(schema[i].Type, v)
+		}
+		data = append(data, row)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("storage: export %s: %w", table, err)
+	}
+
+	if err := parquet.Write(w, schema, data); err != nil {
+		return 0, fmt.Errorf("storage: export %s: %w", table, err)
+	}
+	return len(data), nil
+}
+
+// parquetType picks the Parquet type of a column by SQLite's type affinity
+// rules.
+func parquetType(declType string) parquet.Type {
+	t := strings.ToUpper(declType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return parquet.Int64
+	case strings.Contains(t, "BOOL"):
+		return parquet.Boolean
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return parquet.Double
+	}
+	return parquet.String
+}
+
+// parquetValue converts a scanned value to the Go type parquet.Write
+// expects for t. SQLite columns may hold values of any type; ones that
+// don't convert are written as null.
+func parquetValue(t parquet.Type, v any) any {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	switch t {
+	case parquet.Int64, parquet.Boolean:
+		var n int64
+		switch x := v.(type) {
+		case int64:
+			n = x
+		case float64:
+			n = int64(x)
+		case bool:
+			if x {
+				n = 1
+			}
+		case string:
+			parsed, err := strconv.ParseInt(x, 10, 64)
+			if err != nil {
+				return nil
+			}
+			n = parsed
+		default:
+			return nil
+		}
+		if t == parquet.Boolean {
+			return n != 0
+		}
+		return n
+	case parquet.Double:
+		switch x := v.(type) {
+		case float64:
+			return x
+		case int64:
+			return float64(x)
+		case string:
+			if f, err := strconv.ParseFloat(x, 64); err == nil {
+				return f
+			}
+		}
+		return nil
+	}
+	if v == nil {
+		return nil
+	}
+	return fmt.Sprint(v)
+}
+
+type tableColumn struct {
+	name, declType string
+}
+
+func (s *Storage) tableColumns(ctx context.Context, table string) ([]tableColumn, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT name, type FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("storage: inspect %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var c tableColumn
+		if err := rows.Scan(&c.name, &c.declType); err != nil {
+			return nil, fmt.Errorf("storage: inspect %s: %w", table, err)
+		}
+		columns = append(columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage: inspect %s: %w", table, err)
+	}
+	return columns, nil
+}
+
+// tableFilter returns the set of tables named, or of all tables when none
+// are, checking that each exists.
+func (s *Storage) tableFilter(ctx context.Context, names []string) (map[string]bool, error) {
+	all, err := s.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(all))
+	for _, t := range all {
+		known[t] = true
+	}
+	if len(names) == 0 {
+		return known, nil
+	}
+	include := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("storage: unknown table %q (tables: %s)", name, strings.Join(all, ", "))
+		}
+		include[name] = true
+	}
+	return include, nil
+}
+
+// quoteIdent quotes an SQL identifier.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// Fields of a stored parse that EditRecord changes.
+const (
+	EditSeries    = "series"
+	EditIssue     = "issue"
+	EditYear      = "year"
+	EditPublisher = "publisher"
+	EditVolume    = "volume"
+)
+
+// EditFields lists the editable fields in display order.
+var EditFields = []string{EditSeries, EditIssue, EditYear, EditPublisher, EditVolume}
+
+// ErrNoParse is returned when no parse is stored for a file.
+var ErrNoParse = errors.New("storage: no parse stored")
+
+// Sources of a record edit.
+const (
+	EditSourceManual = "manual" // a person, from the CLI or TUI
+	EditSourceAPI    = "api"    // a metadata lookup
+)
+
+// RecordEdit is one changed field in the audit trail of a stored parse.
+type RecordEdit struct {
+	Filename string
+	Field    string
+	OldValue string
+	NewValue string
+	Source   string
+	EditedAt time.Time
+}
+
+// LatestParse returns the most recent stored parse of filename.
+func (s *Storage) LatestParse(ctx context.Context, filename string) (*models.ParsedFilename, error) {
+	row, err := s.q.GetLatestParsedFilename(ctx, filename)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w for %s", ErrNoParse, filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get parse of %s: %w", filename, err)
+	}
+	return parsedFilenameFromDB(row), nil
+}
+
+// EditFieldValue returns the value of an editable field of a parse.
+func EditFieldValue(p *models.ParsedFilename, field string) string {
+	switch field {
+	case EditSeries:
+		return p.Title
+	case EditIssue:
+		return p.IssueNumber
+	case EditYear:
+		return p.Year
+	case EditPublisher:
+		return p.Publisher
+	case EditVolume:
+		return p.VolumeNumber
+	}
+	return ""
+}
+
+// EditRecord changes fields of the most recent stored parse of filename,
+// keyed by the Edit* field names, and records each changed field in the
+// audit trail with source. Unchanged fields are skipped; the returned edits
+// are the ones made. Series and issue can't be cleared. Processing the file
+// again replaces the edited parse.
+func (s *Storage) EditRecord(ctx context.Context, filename string, changes map[string]string, source string) ([]RecordEdit, error) {
+	for field, value := range changes {
+		switch field {
+		case EditSeries, EditIssue:
+			if strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("storage: %s can't be empty", field)
+			}
+		case EditYear, EditPublisher, EditVolume:
+		default:
+			return nil, fmt.Errorf("storage: unknown field %q (want one of %s)", field, strings.Join(EditFields, ", "))
+		}
+	}
+
+	var edits []RecordEdit
+	err := s.write(ctx, func(qtx *db.Queries) error {
+		row, err := qtx.GetLatestParsedFilename(ctx, filename)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w for %s", ErrNoParse, filename)
+		}
+		if err != nil {
+			return fmt.Errorf("storage: get parse of %s: %w", filename, err)
+		}
+
+		parsed := parsedFilenameFromDB(row)
+		now := time.Now()
+		edits = nil
+		for _, field := range EditFields {
+			value, ok := changes[field]
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			old := EditFieldValue(parsed, field)
+			if value == old {
+				continue
+			}
+			err := qtx.CreateRecordEdit(ctx, db.CreateRecordEditParams{
+				Filename: filename,
+				Field:    field,
+				OldValue: old,
+				NewValue: value,
+				Source:   source,
+				EditedAt: now,
+			})
+			if err != nil {
+				return fmt.Errorf("storage: record edit of %s: %w", filename, err)
+			}
+			edits = append(edits, RecordEdit{Filename: filename, Field: field, OldValue: old, NewValue: value, Source: source, EditedAt: now})
+		}
+		if len(edits) == 0 {
+			return nil
+		}
+
+		params := db.UpdateParsedFieldsParams{
+			Title:        row.Title,
+			IssueNumber:  row.IssueNumber,
+			Year:         row.Year,
+			Publisher:    row.Publisher,
+			VolumeNumber: row.VolumeNumber,
+			ID:           row.ID,
+		}
+		for _, edit := range edits {
+			value := sql.NullString{String: edit.NewValue, Valid: edit.NewValue != ""}
+			switch edit.Field {
+			case EditSeries:
+				params.Title = edit.NewValue
+			case EditIssue:
+				params.IssueNumber = edit.NewValue
+			case EditYear:
+				params.Year = value
+			case EditPublisher:
+				params.Publisher = value
+			case EditVolume:
+				params.VolumeNumber = value
+			}
+		}
+		if err := qtx.UpdateParsedFields(ctx, params); err != nil {
+			return fmt.Errorf("storage: edit parse of %s: %w", filename, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// ListRecordEdits returns the audit trail of filename, oldest edit first.
+func (s *Storage) ListRecordEdits(ctx context.Context, filename string) ([]RecordEdit, error) {
+	rows, err := s.q.ListRecordEdits(ctx, filename)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list edits of %s: %w", filename, err)
+	}
+
+	edits := make([]RecordEdit, 0, len(rows))
+	for _, row := range rows {
+		edits = append(edits, RecordEdit{
+			Filename: row.Filename,
+			Field:    row.Field,
+			OldValue: row.OldValue,
+			NewValue: row.NewValue,
+			Source:   row.Source,
+			EditedAt: row.EditedAt,
+		})
+	}
+	return edits, nil
+}
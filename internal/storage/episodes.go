@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// SaveEpisode stores the outcome of matching a TV episode file, replacing
+// any earlier one for the same file.
+func (s *Storage) SaveEpisode(ctx context.Context, result *models.EpisodeResult) error {
+	params := db.UpsertEpisodeParams{
+		Filename:    result.Filename,
+		Path:        sql.NullString{String: result.Path, Valid: result.Path != ""},
+		Success:     result.Success,
+		Error:       sql.NullString{String: result.Error, Valid: result.Error != ""},
+		ProcessedAt: result.ProcessedAt,
+	}
+	if params.ProcessedAt.IsZero() {
+		params.ProcessedAt = time.Now()
+	}
+	if result.Parsed != nil {
+		parsed, err := json.Marshal(result.Parsed)
+		if err != nil {
+			return fmt.Errorf("storage: encode episode parse: %w", err)
+		}
+		params.Parsed = sql.NullString{String: string(parsed), Valid: true}
+	}
+	if ep := result.Episode; ep != nil {
+		episode, err := json.Marshal(ep)
+		if err != nil {
+			return fmt.Errorf("storage: encode episode: %w", err)
+		}
+		params.Episode = sql.NullString{String: string(episode), Valid: true}
+		params.SeriesName = sql.NullString{String: ep.SeriesName, Valid: true}
+		params.TvdbSeriesID = sql.NullInt64{Int64: int64(ep.SeriesID), Valid: true}
+		params.TvdbEpisodeID = sql.NullInt64{Int64: int64(ep.ID), Valid: true}
+		params.MatchConfidence = sql.NullString{String: result.Confidence, Valid: result.Confidence != ""}
+	}
+
+	return s.write(ctx, func(qtx *db.Queries) error {
+		if err := qtx.UpsertEpisode(ctx, params); err != nil {
+			return fmt.Errorf("storage: save episode %s: %w", result.Filename, err)
+		}
+		return nil
+	})
+}
+
+// ListEpisodes returns the stored TV episode results, by filename.
+func (s *Storage) ListEpisodes(ctx context.Context) ([]*models.EpisodeResult, error) {
+	rows, err := s.q.ListEpisodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list episodes: %w", err)
+	}
+
+	results := make([]*models.EpisodeResult, 0, len(rows))
+	for _, row := range rows {
+		result := &models.EpisodeResult{
+			Filename:    row.Filename,
+			Path:        row.Path.String,
+			Success:     row.Success,
+			Error:       row.Error.String,
+			Confidence:  row.MatchConfidence.String,
+			ProcessedAt: row.ProcessedAt,
+		}
+		if row.Parsed.Valid {
+			if err := json.Unmarshal([]byte(row.Parsed.String), &result.Parsed); err != nil {
+				return nil, fmt.Errorf("storage: decode episode parse of %s: %w", row.Filename, err)
+			}
+		}
+		if row.Episode.Valid {
+			if err := json.Unmarshal([]byte(row.Episode.String), &result.Episode); err != nil {
+				return nil, fmt.Errorf("storage: decode episode of %s: %w", row.Filename, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
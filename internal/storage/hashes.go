@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ListFileHashes returns the stored results whose files were hashed, with
+// only their filename, path, hash and size set.
+func (s *Storage) ListFileHashes(ctx context.Context) ([]*models.ProcessingResult, error) {
+	rows, err := s.q.ListFileHashes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list file hashes: %w", err)
+	}
+	results := make([]*models.ProcessingResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, &models.ProcessingResult{
+			Filename: row.Filename,
+			Path:     row.Path.String,
+			FileHash: row.FileHash.String,
+			FileSize: row.FileSize.Int64,
+		})
+	}
+	return results, nil
+}
+
+// FilenamesWithHash returns the stored files other than filename whose
+// content hashes to hash, such as an earlier download of the same file.
+func (s *Storage) FilenamesWithHash(ctx context.Context, hash, filename string) ([]string, error) {
+	names, err := s.q.ListFilenamesByHash(ctx, db.ListFilenamesByHashParams{
+		FileHash: sql.NullString{String: hash, Valid: true},
+		Filename: filename,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: list files by hash: %w", err)
+	}
+	return names, nil
+}
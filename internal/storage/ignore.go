@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ErrIgnoreRuleNotFound is returned when removing an ignore rule that
+// doesn't exist.
+var ErrIgnoreRuleNotFound = errors.New("storage: ignore rule not found")
+
+// AddIgnoreRule adds a glob pattern or exact file name to the ignore list.
+// Adding a pattern already on the list does nothing.
+func (s *Storage) AddIgnoreRule(ctx context.Context, pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("storage: empty ignore pattern")
+	}
+	return s.write(ctx, func(qtx *db.Queries) error {
+		err := qtx.CreateIgnoreRule(ctx, db.CreateIgnoreRuleParams{Pattern: pattern, CreatedAt: time.Now()})
+		if err != nil {
+			return fmt.Errorf("storage: add ignore rule %s: %w", pattern, err)
+		}
+		return nil
+	})
+}
+
+// RemoveIgnoreRule removes a pattern from the ignore list.
+func (s *Storage) RemoveIgnoreRule(ctx context.Context, pattern string) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		n, err := qtx.DeleteIgnoreRule(ctx, pattern)
+		if err != nil {
+			return fmt.Errorf("storage: remove ignore rule %s: %w", pattern, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("%w: %s", ErrIgnoreRuleNotFound, pattern)
+		}
+		return nil
+	})
+}
+
+// ListIgnoreRules returns the ignore list by pattern.
+func (s *Storage) ListIgnoreRules(ctx context.Context) (models.IgnoreList, error) {
+	rows, err := s.q.ListIgnoreRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list ignore rules: %w", err)
+	}
+	rules := make(models.IgnoreList, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, models.IgnoreRule{Pattern: row.Pattern, CreatedAt: row.CreatedAt})
+	}
+	return rules, nil
+}
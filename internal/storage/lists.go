@@ -0,0 +1,204 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ErrListNotFound is returned for reading lists that don't exist.
+var ErrListNotFound = errors.New("storage: reading list not found")
+
+// ErrIssueNotStored is returned when adding an issue that isn't in the
+// database to a reading list. Store it with SaveIssue first.
+var ErrIssueNotStored = errors.New("storage: issue not stored")
+
+// CreateReadingList creates an empty reading list.
+func (s *Storage) CreateReadingList(ctx context.Context, name string) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		if _, err := qtx.CreateReadingList(ctx, db.CreateReadingListParams{Name: name, CreatedAt: time.Now()}); err != nil {
+			return fmt.Errorf("storage: create reading list %s: %w", name, err)
+		}
+		return nil
+	})
+}
+
+// DeleteReadingList removes a reading list and its entries.
+func (s *Storage) DeleteReadingList(ctx context.Context, name string) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		n, err := qtx.DeleteReadingList(ctx, name)
+		if err != nil {
+			return fmt.Errorf("storage: delete reading list %s: %w", name, err)
+		}
+		if n == 0 {
+			return fmt.Errorf("%w: %s", ErrListNotFound, name)
+		}
+		return nil
+	})
+}
+
+// ListReadingLists returns the reading lists by name, with their entry
+// counts but without their entries.
+func (s *Storage) ListReadingLists(ctx context.Context) ([]models.ReadingList, error) {
+	rows, err := s.q.ListReadingLists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list reading lists: %w", err)
+	}
+	lists := make([]models.ReadingList, 0, len(rows))
+	for _, row := range rows {
+		lists = append(lists, models.ReadingList{
+			ID:         row.ID,
+			Name:       row.Name,
+			CreatedAt:  row.CreatedAt,
+			EntryCount: int(row.EntryCount),
+		})
+	}
+	return lists, nil
+}
+
+// GetReadingList returns a reading list with its entries in order.
+func (s *Storage) GetReadingList(ctx context.Context, name string) (*models.ReadingList, error) {
+	return getReadingList(ctx, s.q, name)
+}
+
+func getReadingList(ctx context.Context, q *db.Queries, name string) (*models.ReadingList, error) {
+	row, err := q.GetReadingList(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrListNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get reading list %s: %w", name, err)
+	}
+
+	entries, err := q.ListReadingListEntries(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entries of %s: %w", name, err)
+	}
+	list := &models.ReadingList{ID: row.ID, Name: row.Name, CreatedAt: row.CreatedAt, EntryCount: len(entries)}
+	for _, e := range entries {
+		list.Entries = append(list.Entries, models.ReadingListEntry{
+			Position: int(e.Position),
+			Issue: models.ComicVineIssue{
+				ID:            int(e.IssueID),
+				Name:          e.IssueName.String,
+				IssueNumber:   e.IssueNumber.String,
+				CoverDate:     models.ParseDateLenient(e.CoverDate.String),
+				SiteDetailURL: e.SiteDetailUrl.String,
+				Volume: models.VolumeRef{
+					ID:        int(e.VolumeID),
+					Name:      e.VolumeName,
+					StartYear: e.StartYear.String,
+					Publisher: e.PublisherName.String,
+				},
+			},
+		})
+	}
+	return list, nil
+}
+
+// AddToReadingList appends stored ComicVine issues to a reading list. It
+// fails with ErrIssueNotStored, adding none, if any of them isn't stored.
+func (s *Storage) AddToReadingList(ctx context.Context, name string, issueIDs []int) error {
+	return s.editReadingList(ctx, name, func(ids []int) ([]int, error) {
+		return append(ids, issueIDs...), nil
+	})
+}
+
+// MoveReadingListEntry moves the entry at position from to position to,
+// shifting the entries in between. Positions start at 1.
+func (s *Storage) MoveReadingListEntry(ctx context.Context, name string, from, to int) error {
+	return s.editReadingList(ctx, name, func(ids []int) ([]int, error) {
+		if from < 1 || from > len(ids) || to < 1 || to > len(ids) {
+			return nil, fmt.Errorf("storage: %s has no position %d or %d (1-%d)", name, from, to, len(ids))
+		}
+		id := ids[from-1]
+		ids = append(ids[:from-1], ids[from:]...)
+		return append(ids[:to-1], append([]int{id}, ids[to-1:]...)...), nil
+	})
+}
+
+// RemoveReadingListEntry removes the entry at position, shifting the later
+// entries up.
+func (s *Storage) RemoveReadingListEntry(ctx context.Context, name string, position int) error {
+	return s.editReadingList(ctx, name, func(ids []int) ([]int, error) {
+		if position < 1 || position > len(ids) {
+			return nil, fmt.Errorf("storage: %s has no position %d (1-%d)", name, position, len(ids))
+		}
+		return append(ids[:position-1], ids[position:]...), nil
+	})
+}
+
+// editReadingList rewrites the issues of a reading list in the order edit
+// returns them.
+func (s *Storage) editReadingList(ctx context.Context, name string, edit func(ids []int) ([]int, error)) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		list, err := getReadingList(ctx, qtx, name)
+		if err != nil {
+			return err
+		}
+		ids := make([]int, len(list.Entries))
+		for i, e := range list.Entries {
+			ids[i] = e.Issue.ID
+		}
+		if ids, err = edit(ids); err != nil {
+			return err
+		}
+
+		if err := qtx.DeleteReadingListEntries(ctx, list.ID); err != nil {
+			return fmt.Errorf("storage: clear entries of %s: %w", name, err)
+		}
+		for i, id := range ids {
+			n, err := qtx.CountIssues(ctx, int64(id))
+			if err != nil {
+				return fmt.Errorf("storage: look up issue %d: %w", id, err)
+			}
+			if n == 0 {
+				return fmt.Errorf("%w: %d", ErrIssueNotStored, id)
+			}
+			err = qtx.CreateReadingListEntry(ctx, db.CreateReadingListEntryParams{
+				ListID:      list.ID,
+				Position:    int64(i + 1),
+				ComicvineID: int64(id),
+			})
+			if err != nil {
+				return fmt.Errorf("storage: add issue %d to %s: %w", id, name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// HasIssue reports whether a ComicVine issue is stored.
+func (s *Storage) HasIssue(ctx context.Context, issueID int) (bool, error) {
+	n, err := s.q.CountIssues(ctx, int64(issueID))
+	if err != nil {
+		return false, fmt.Errorf("storage: look up issue %d: %w", issueID, err)
+	}
+	return n > 0, nil
+}
+
+// SaveIssue stores a ComicVine issue and its volume without a file, e.g. to
+// add it to a reading list.
+func (s *Storage) SaveIssue(ctx context.Context, issue *models.ComicVineIssue) error {
+	return s.write(ctx, func(qtx *db.Queries) error {
+		return saveIssue(ctx, qtx, issue)
+	})
+}
+
+// ResultIssueID returns the ComicVine issue matched by the processing result
+// with database ID id, or 0 when it has no match.
+func (s *Storage) ResultIssueID(ctx context.Context, id int64) (int, error) {
+	cvID, err := s.q.GetResultComicVineID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("storage: no processing result %d", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("storage: get processing result %d: %w", id, err)
+	}
+	return int(cvID.Int64), nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// SaveMappings stores imported filename/hash mappings together with the
+// ComicVine issues they point to. Existing mappings with the same kind and
+// key are replaced.
+func (s *Storage) SaveMappings(ctx context.Context, mappings []models.Mapping) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.q.WithTx(tx)
+
+	for i := range mappings {
+		m := &mappings[i]
+		if err := saveIssue(ctx, qtx, &m.Issue); err != nil {
+			return err
+		}
+
+		importedAt := m.ImportedAt
+		if importedAt.IsZero() {
+			importedAt = time.Now()
+		}
+
+		err := qtx.UpsertMapping(ctx, db.UpsertMappingParams{
+			Kind:        m.Kind,
+			Key:         m.Key,
+			ComicvineID: int64(m.Issue.ID),
+			Source:      m.Source,
+			ImportedAt:  importedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to upsert mapping %s %q: %w", m.Kind, m.Key, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListMappings returns all stored mappings with their issue details.
+func (s *Storage) ListMappings(ctx context.Context) ([]models.Mapping, error) {
+	rows, err := s.q.ListMappings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list mappings: %w", err)
+	}
+
+	mappings := make([]models.Mapping, 0, len(rows))
+	for _, row := range rows {
+		mappings = append(mappings, models.Mapping{
+			Kind:       row.Kind,
+			Key:        row.Key,
+			Source:     row.Source,
+			ImportedAt: row.ImportedAt,
+			Issue: models.ComicVineIssue{
+				ID:            int(row.IssueID),
+				Name:          row.IssueName.String,
+				IssueNumber:   row.IssueNumber.String,
+				CoverDate:     models.ParseDateLenient(row.CoverDate.String),
+				StoreDate:     models.ParseDateLenient(row.StoreDate.String),
+				SiteDetailURL: row.SiteDetailUrl.String,
+				Volume: models.VolumeRef{
+					ID:          int(row.VolumeID),
+					Name:        row.VolumeName,
+					SiteURL:     row.VolumeUrl.String,
+					Publisher:   row.PublisherName.String,
+					PublisherID: int(row.PublisherID.Int64),
+					StartYear:   row.StartYear.String,
+					IssueCount:  int(row.CountOfIssues.Int64),
+				},
+				Image: models.ImageRef{
+					SmallURL:  row.ImageSmallUrl.String,
+					MediumURL: row.ImageMediumUrl.String,
+					LargeURL:  row.ImageLargeUrl.String,
+				},
+			},
+		})
+	}
+	return mappings, nil
+}
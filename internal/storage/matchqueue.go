@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+)
+
+// QueuedMatch is a file parsed offline that is waiting to be matched.
+type QueuedMatch struct {
+	Filename string
+	QueuedAt time.Time
+}
+
+// QueueMatch adds filename to the queue of files waiting to be matched. A
+// file already queued keeps its place.
+func (s *Storage) QueueMatch(ctx context.Context, filename string) error {
+	err := s.write(ctx, func(qtx *db.Queries) error {
+		return qtx.EnqueueMatch(ctx, db.EnqueueMatchParams{Filename: filename, QueuedAt: time.Now()})
+	})
+	if err != nil {
+		return fmt.Errorf("storage: queue %s for matching: %w", filename, err)
+	}
+	return nil
+}
+
+// ListMatchQueue returns the files waiting to be matched, oldest first.
+func (s *Storage) ListMatchQueue(ctx context.Context) ([]QueuedMatch, error) {
+	rows, err := s.q.ListMatchQueue(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list match queue: %w", err)
+	}
+
+	queued := make([]QueuedMatch, 0, len(rows))
+	for _, row := range rows {
+		queued = append(queued, QueuedMatch{Filename: row.Filename, QueuedAt: row.QueuedAt})
+	}
+	return queued, nil
+}
+
+// DequeueMatch removes filename from the match queue. Removing a file that
+// isn't queued is not an error.
+func (s *Storage) DequeueMatch(ctx context.Context, filename string) error {
+	err := s.write(ctx, func(qtx *db.Queries) error {
+		return qtx.DeleteMatchQueueEntry(ctx, filename)
+	})
+	if err != nil {
+		return fmt.Errorf("storage: dequeue %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the schema as numbered migrations named
+// NNNN_description.sql. Each runs once, in order, inside a transaction; a
+// schema change is a new file, never an edit to an applied one.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// schemaVersionTable records the applied migrations. It is created outside
+// the migrations since they depend on it.
+const schemaVersionTable = `
+CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at DATETIME NOT NULL
+);
+`
+
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// MigrationStatus describes a schema migration and when it was applied.
+// AppliedAt is zero for pending migrations.
+type MigrationStatus struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at,omitempty"`
+}
+
+// Applied reports whether the migration has run.
+func (m MigrationStatus) Applied() bool {
+	return !m.AppliedAt.IsZero()
+}
+
+// loadMigrations returns the embedded migrations ordered by version. Versions
+// must run from 1 without gaps so a missing file cannot go unnoticed.
+func loadMigrations() ([]migration, error) {
+	return parseMigrations(migrationFiles, "migrations")
+}
+
+func parseMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		number, name, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_description.sql", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("migration %04d_%s: expected version %d", m.version, m.name, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// migrate brings the database schema up to date. Databases created before
+// schema versioning are first given the columns added since their tables
+// were created, then take the baseline migration like a new database.
+func migrate(dbConn *sql.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := dbConn.Exec(schemaVersionTable); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+	current, err := schemaVersion(dbConn)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d); upgrade comic-parser", current, len(migrations))
+	}
+
+	if current == 0 {
+		legacy, err := tableExists(dbConn, "processing_results")
+		if err != nil {
+			return err
+		}
+		if legacy {
+			if err := upgradeLegacySchema(dbConn); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, m := range migrations[current:] {
+		if err := applyMigration(dbConn, m); err != nil {
+			return err
+		}
+		logger.Debug("applied migration", "version", m.version, "name", m.name)
+	}
+	return nil
+}
+
+func applyMigration(dbConn *sql.DB, m migration) error {
+	tx, err := dbConn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.sql); err != nil {
+		return fmt.Errorf("failed to apply migration %04d_%s: %w", m.version, m.name, err)
+	}
+	if _, err := tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)", m.version, m.name, time.Now()); err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", m.version, m.name, err)
+	}
+	return tx.Commit()
+}
+
+func schemaVersion(dbConn *sql.DB) (int, error) {
+	var version int
+	if err := dbConn.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+func tableExists(dbConn *sql.DB, table string) (bool, error) {
+	var n int
+	if err := dbConn.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n); err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	return n > 0, nil
+}
+
+// upgradeLegacySchema adds the columns introduced before schema versioning
+// to the tables of an unversioned database. The baseline migration only
+// creates missing tables, so without this its queries would fail on them.
+func upgradeLegacySchema(dbConn *sql.DB) error {
+	for _, table := range []string{"processing_results", "parsed_filenames"} {
+		if err := ensureColumn(dbConn, table, "run_id", "INTEGER REFERENCES batch_runs(id)"); err != nil {
+			return err
+		}
+	}
+	if err := ensureColumn(dbConn, "processing_results", "provenance", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(dbConn, "parsed_filenames", "special", "TEXT"); err != nil {
+		return err
+	}
+	for _, table := range []string{"processing_results", "parsed_filenames"} {
+		if err := ensureColumn(dbConn, table, "path", "TEXT"); err != nil {
+			return err
+		}
+	}
+	for _, column := range []string{"publisher_id", "count_of_issues"} {
+		if err := ensureColumn(dbConn, "comic_vine_volumes", column, "INTEGER"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureColumn adds a column to an existing table if it is missing.
+// CREATE TABLE IF NOT EXISTS leaves tables from older databases untouched,
+// so columns added to the schema later have to be added explicitly.
+func ensureColumn(dbConn *sql.DB, table, column, definition string) error {
+	rows, err := dbConn.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := dbConn.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the version of the last migration applied to the
+// database.
+func (s *Storage) SchemaVersion() (int, error) {
+	return schemaVersion(s.db)
+}
+
+// ReadMigrationStatus lists every migration known to this build and when it
+// was applied to the SQLite database at dbPath, without migrating it.
+func ReadMigrationStatus(dbPath string) ([]MigrationStatus, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("storage: no database at %s: %w", dbPath, err)
+	}
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	dbConn, err := sql.Open(sqlDriver, withPragmas(dbPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer dbConn.Close()
+
+	applied := make(map[int]time.Time)
+	versioned, err := tableExists(dbConn, "schema_version")
+	if err != nil {
+		return nil, err
+	}
+	if versioned {
+		rows, err := dbConn.Query("SELECT version, applied_at FROM schema_version")
+		if err != nil {
+			return nil, fmt.Errorf("storage: read schema versions: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				version   int
+				appliedAt time.Time
+			)
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, fmt.Errorf("storage: read schema versions: %w", err)
+			}
+			applied[version] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("storage: read schema versions: %w", err)
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, MigrationStatus{Version: m.version, Name: m.name, AppliedAt: applied[m.version]})
+		delete(applied, m.version)
+	}
+	for version, appliedAt := range applied {
+		statuses = append(statuses, MigrationStatus{Version: version, Name: "(unknown to this build)", AppliedAt: appliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
@@ -0,0 +1,229 @@
+CREATE TABLE IF NOT EXISTS batch_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    started_at DATETIME NOT NULL,
+    finished_at DATETIME,
+    mode TEXT NOT NULL,
+    input_source TEXT NOT NULL,
+    parser_name TEXT,
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    successful INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    skipped INTEGER NOT NULL DEFAULT 0,
+    settings TEXT,
+    llm_input_tokens INTEGER NOT NULL DEFAULT 0,
+    llm_output_tokens INTEGER NOT NULL DEFAULT 0,
+    llm_cost REAL NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS comic_vine_volumes (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    start_year TEXT,
+    publisher_name TEXT,
+    site_detail_url TEXT,
+    publisher_id INTEGER,
+    count_of_issues INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS comic_vine_issues (
+    id INTEGER PRIMARY KEY,
+    volume_id INTEGER NOT NULL,
+    name TEXT,
+    issue_number TEXT,
+    cover_date TEXT,
+    store_date TEXT,
+    description TEXT,
+    site_detail_url TEXT,
+    image_small_url TEXT,
+    image_medium_url TEXT,
+    image_large_url TEXT,
+    FOREIGN KEY (volume_id) REFERENCES comic_vine_volumes(id)
+);
+
+CREATE TABLE IF NOT EXISTS processing_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL UNIQUE,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    processed_at DATETIME NOT NULL,
+    processing_time_ms INTEGER NOT NULL,
+    match_confidence TEXT,
+    reasoning TEXT,
+    comicvine_id INTEGER,
+    comicvine_url TEXT,
+    run_id INTEGER REFERENCES batch_runs(id),
+    provenance TEXT,
+    path TEXT,
+    FOREIGN KEY (comicvine_id) REFERENCES comic_vine_issues(id)
+);
+
+CREATE TABLE IF NOT EXISTS parsed_filenames (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    processing_result_id INTEGER,
+    parser_name TEXT NOT NULL DEFAULT 'unknown',
+    original_filename TEXT NOT NULL,
+    title TEXT NOT NULL,
+    issue_number TEXT NOT NULL,
+    year TEXT,
+    publisher TEXT,
+    volume_number TEXT,
+    confidence TEXT NOT NULL,
+    notes TEXT,
+    run_id INTEGER REFERENCES batch_runs(id),
+    special TEXT,
+    path TEXT,
+    FOREIGN KEY (processing_result_id) REFERENCES processing_results(id) ON DELETE CASCADE,
+    UNIQUE(original_filename, parser_name)
+);
+
+CREATE TABLE IF NOT EXISTS comicvine_mappings (
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL,
+    comicvine_id INTEGER NOT NULL REFERENCES comic_vine_issues(id),
+    source TEXT NOT NULL,
+    imported_at DATETIME NOT NULL,
+    PRIMARY KEY (kind, key)
+);
+
+CREATE TABLE IF NOT EXISTS run_results (
+    run_id INTEGER NOT NULL REFERENCES batch_runs(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    title TEXT,
+    issue_number TEXT,
+    year TEXT,
+    match_confidence TEXT,
+    comicvine_id INTEGER,
+    PRIMARY KEY (run_id, filename)
+);
+
+CREATE TABLE IF NOT EXISTS batch_checkpoints (
+    run_id INTEGER NOT NULL REFERENCES batch_runs(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    state TEXT NOT NULL,
+    error TEXT,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (run_id, filename)
+);
+
+CREATE TABLE IF NOT EXISTS file_moves (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    operation_id INTEGER NOT NULL,
+    filename TEXT NOT NULL,
+    old_path TEXT NOT NULL,
+    new_path TEXT NOT NULL,
+    moved_at DATETIME NOT NULL,
+    undone_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS llm_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id INTEGER REFERENCES batch_runs(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL,
+    output_tokens INTEGER NOT NULL,
+    cost REAL NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_run_id ON llm_usage(run_id);
+
+CREATE TABLE IF NOT EXISTS review_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id INTEGER REFERENCES batch_runs(id),
+    filename TEXT NOT NULL UNIQUE,
+    result TEXT NOT NULL,
+    candidates TEXT NOT NULL,
+    status TEXT NOT NULL,
+    queued_at DATETIME NOT NULL,
+    reviewed_at DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS search_documents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL UNIQUE,
+    series TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL
+);
+
+-- search_sources derives the searchable text of every stored file: the
+-- matched volume and parsed title as series, the issue name as title and
+-- the issue description. Files that were only parsed use the parsed title.
+CREATE VIEW IF NOT EXISTS search_sources AS
+SELECT
+    r.filename,
+    TRIM(COALESCE(v.name, '') || ' ' || COALESCE(
+        (SELECT p.title FROM parsed_filenames p WHERE p.original_filename = r.filename ORDER BY p.id DESC LIMIT 1), ''
+    )) AS series,
+    COALESCE(i.name, '') AS title,
+    COALESCE(i.description, '') AS description
+FROM processing_results r
+LEFT JOIN comic_vine_issues i ON i.id = r.comicvine_id
+LEFT JOIN comic_vine_volumes v ON v.id = i.volume_id
+UNION ALL
+SELECT p.original_filename, p.title, '', ''
+FROM parsed_filenames p
+WHERE p.id = (SELECT MAX(p2.id) FROM parsed_filenames p2 WHERE p2.original_filename = p.original_filename)
+  AND NOT EXISTS (SELECT 1 FROM processing_results r WHERE r.filename = p.original_filename);
+
+-- The triggers below keep search_documents in step with the records it is
+-- derived from by rebuilding the document of every affected filename.
+CREATE TRIGGER IF NOT EXISTS search_processing_results_insert AFTER INSERT ON processing_results BEGIN
+    DELETE FROM search_documents WHERE filename = NEW.filename;
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources WHERE filename = NEW.filename;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_processing_results_update AFTER UPDATE ON processing_results BEGIN
+    DELETE FROM search_documents WHERE filename IN (OLD.filename, NEW.filename);
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources WHERE filename IN (OLD.filename, NEW.filename);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_processing_results_delete AFTER DELETE ON processing_results BEGIN
+    DELETE FROM search_documents WHERE filename = OLD.filename;
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources WHERE filename = OLD.filename;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_parsed_filenames_insert AFTER INSERT ON parsed_filenames BEGIN
+    DELETE FROM search_documents WHERE filename = NEW.original_filename;
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources WHERE filename = NEW.original_filename;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_parsed_filenames_update AFTER UPDATE ON parsed_filenames BEGIN
+    DELETE FROM search_documents WHERE filename IN (OLD.original_filename, NEW.original_filename);
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources WHERE filename IN (OLD.original_filename, NEW.original_filename);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_parsed_filenames_delete AFTER DELETE ON parsed_filenames BEGIN
+    DELETE FROM search_documents WHERE filename = OLD.original_filename;
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources WHERE filename = OLD.original_filename;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_issues_update AFTER UPDATE ON comic_vine_issues BEGIN
+    DELETE FROM search_documents WHERE filename IN (SELECT filename FROM processing_results WHERE comicvine_id = NEW.id);
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources
+    WHERE filename IN (SELECT filename FROM processing_results WHERE comicvine_id = NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_volumes_update AFTER UPDATE ON comic_vine_volumes BEGIN
+    DELETE FROM search_documents WHERE filename IN (
+        SELECT r.filename FROM processing_results r JOIN comic_vine_issues i ON i.id = r.comicvine_id WHERE i.volume_id = NEW.id
+    );
+    INSERT INTO search_documents (filename, series, title, description)
+    SELECT filename, series, title, description FROM search_sources
+    WHERE filename IN (
+        SELECT r.filename FROM processing_results r JOIN comic_vine_issues i ON i.id = r.comicvine_id WHERE i.volume_id = NEW.id
+    );
+END;
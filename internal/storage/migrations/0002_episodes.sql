@@ -0,0 +1,17 @@
+-- episodes holds TV episode files matched against TheTVDB by the tv
+-- command, alongside processing_results for comics. The parse and the
+-- matched episode are stored as JSON.
+CREATE TABLE IF NOT EXISTS episodes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL UNIQUE,
+    path TEXT,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    series_name TEXT,
+    tvdb_series_id INTEGER,
+    tvdb_episode_id INTEGER,
+    match_confidence TEXT,
+    parsed TEXT,
+    episode TEXT,
+    processed_at DATETIME NOT NULL
+);
@@ -0,0 +1,3 @@
+-- chapter records the chapter of manga releases, whose volume is parsed
+-- as the issue number.
+ALTER TABLE parsed_filenames ADD COLUMN chapter TEXT;
@@ -0,0 +1,3 @@
+-- source_filename links the results of the issues of a file collecting
+-- several, such as "Batman 001-006", to that file.
+ALTER TABLE processing_results ADD COLUMN source_filename TEXT;
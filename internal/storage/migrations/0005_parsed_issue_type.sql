@@ -0,0 +1,3 @@
+-- issue_type records annuals, one-shots, specials and Giant-Size editions,
+-- which ComicVine lists apart from the regular numbering.
+ALTER TABLE parsed_filenames ADD COLUMN issue_type TEXT;
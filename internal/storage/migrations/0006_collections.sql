@@ -0,0 +1,18 @@
+-- collections holds collected editions (TPB, HC, Omnibus) matched to a
+-- ComicVine volume by the collection media type, apart from the single
+-- issues in processing_results. The parse and the matched volume are stored
+-- as JSON.
+CREATE TABLE IF NOT EXISTS collections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL UNIQUE,
+    path TEXT,
+    success BOOLEAN NOT NULL,
+    error TEXT,
+    format TEXT,
+    volume_name TEXT,
+    comicvine_volume_id INTEGER,
+    match_confidence TEXT,
+    parsed TEXT,
+    volume TEXT,
+    processed_at DATETIME NOT NULL
+);
@@ -0,0 +1,14 @@
+-- reading_lists are named, ordered lists of ComicVine issues, such as story
+-- arcs, built with the list command and exported as ComicRack CBL files.
+CREATE TABLE IF NOT EXISTS reading_lists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reading_list_entries (
+    list_id INTEGER NOT NULL REFERENCES reading_lists(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    comicvine_id INTEGER NOT NULL REFERENCES comic_vine_issues(id),
+    PRIMARY KEY (list_id, position)
+);
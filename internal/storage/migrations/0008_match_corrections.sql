@@ -0,0 +1,17 @@
+-- match_corrections record the matches a user chose by hand, in review or
+-- interactively, so later LLM matches can follow the library's naming. A
+-- NULL comicvine_id means the user rejected every candidate.
+CREATE TABLE IF NOT EXISTS match_corrections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL,
+    title TEXT NOT NULL,
+    issue_number TEXT NOT NULL,
+    year TEXT NOT NULL,
+    original_comicvine_id INTEGER,
+    comicvine_id INTEGER,
+    volume_name TEXT NOT NULL,
+    volume_start_year TEXT NOT NULL,
+    corrected_issue_number TEXT NOT NULL,
+    source TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
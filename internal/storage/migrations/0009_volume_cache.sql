@@ -0,0 +1,20 @@
+-- fetched_at stamps volumes stored from a ComicVine volume lookup or search,
+-- and issues_fetched_at those whose whole issue list is in comic_vine_issues,
+-- so later runs can skip those requests until the stamps expire. Volumes
+-- only stored as part of a match have neither.
+ALTER TABLE comic_vine_volumes ADD COLUMN fetched_at DATETIME;
+ALTER TABLE comic_vine_volumes ADD COLUMN issues_fetched_at DATETIME;
+
+-- volume_searches cache the volumes a ComicVine search by name returned, in
+-- the order it returned them.
+CREATE TABLE IF NOT EXISTS volume_searches (
+    query TEXT PRIMARY KEY,
+    fetched_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS volume_search_results (
+    query TEXT NOT NULL REFERENCES volume_searches(query) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    volume_id INTEGER NOT NULL,
+    PRIMARY KEY (query, position)
+);
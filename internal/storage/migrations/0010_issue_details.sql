@@ -0,0 +1,8 @@
+-- Details only ComicVine issue lookups return, stored as JSON arrays once a
+-- matched issue is enriched; details_fetched_at is NULL until then.
+ALTER TABLE comic_vine_issues ADD COLUMN credits TEXT;
+ALTER TABLE comic_vine_issues ADD COLUMN characters TEXT;
+ALTER TABLE comic_vine_issues ADD COLUMN teams TEXT;
+ALTER TABLE comic_vine_issues ADD COLUMN locations TEXT;
+ALTER TABLE comic_vine_issues ADD COLUMN story_arcs TEXT;
+ALTER TABLE comic_vine_issues ADD COLUMN details_fetched_at DATETIME;
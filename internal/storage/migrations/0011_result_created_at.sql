@@ -0,0 +1,5 @@
+-- When a file was first stored; processed_at changes whenever it is
+-- reprocessed. Results stored before this migration count from their last
+-- processing.
+ALTER TABLE processing_results ADD COLUMN created_at DATETIME;
+UPDATE processing_results SET created_at = processed_at;
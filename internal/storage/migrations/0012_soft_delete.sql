@@ -0,0 +1,23 @@
+-- Soft-deleted results keep their row, with deleted_at set, until
+-- `db purge` removes them; reads skip them and reprocessing the file clears
+-- the mark. search_sources is rebuilt to leave them out of the search index.
+ALTER TABLE processing_results ADD COLUMN deleted_at DATETIME;
+
+DROP VIEW IF EXISTS search_sources;
+CREATE VIEW search_sources AS
+SELECT
+    r.filename,
+    TRIM(COALESCE(v.name, '') || ' ' || COALESCE(
+        (SELECT p.title FROM parsed_filenames p WHERE p.original_filename = r.filename ORDER BY p.id DESC LIMIT 1), ''
+    )) AS series,
+    COALESCE(i.name, '') AS title,
+    COALESCE(i.description, '') AS description
+FROM processing_results r
+LEFT JOIN comic_vine_issues i ON i.id = r.comicvine_id
+LEFT JOIN comic_vine_volumes v ON v.id = i.volume_id
+WHERE r.deleted_at IS NULL
+UNION ALL
+SELECT p.original_filename, p.title, '', ''
+FROM parsed_filenames p
+WHERE p.id = (SELECT MAX(p2.id) FROM parsed_filenames p2 WHERE p2.original_filename = p.original_filename)
+  AND NOT EXISTS (SELECT 1 FROM processing_results r WHERE r.filename = p.original_filename);
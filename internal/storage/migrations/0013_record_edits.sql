@@ -0,0 +1,14 @@
+-- record_edits is the audit trail of corrections to stored parses: the old
+-- and new value of each changed field and whether a person or an API lookup
+-- made the change.
+CREATE TABLE IF NOT EXISTS record_edits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT NOT NULL,
+    new_value TEXT NOT NULL,
+    source TEXT NOT NULL,
+    edited_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_record_edits_filename ON record_edits(filename);
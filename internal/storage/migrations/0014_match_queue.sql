@@ -0,0 +1,7 @@
+-- match_queue holds the files parsed with -offline whose ComicVine search and
+-- LLM matching wait for `comic-parser sync`, oldest first.
+CREATE TABLE IF NOT EXISTS match_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    filename TEXT NOT NULL UNIQUE,
+    queued_at DATETIME NOT NULL
+);
@@ -0,0 +1,7 @@
+-- run_progress holds the latest progress snapshot a running batch saved, for
+-- `comic-parser status` in another terminal: a JSON models.BatchProgress.
+CREATE TABLE IF NOT EXISTS run_progress (
+    run_id INTEGER PRIMARY KEY REFERENCES batch_runs(id),
+    snapshot TEXT NOT NULL,
+    updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,5 @@
+-- error_code classifies a failed result (models.ErrorCode*: parse_failed,
+-- no_match, rate_limited, ...) so failures can be told apart without
+-- reading their messages.
+ALTER TABLE processing_results ADD COLUMN error_code TEXT;
+ALTER TABLE run_results ADD COLUMN error_code TEXT;
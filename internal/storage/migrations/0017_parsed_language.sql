@@ -0,0 +1,3 @@
+-- language records the language tag of a filename, like "(French)", as an
+-- ISO 639-1 code, so translated releases can be told from original ones.
+ALTER TABLE parsed_filenames ADD COLUMN language TEXT;
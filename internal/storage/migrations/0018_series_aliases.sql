@@ -0,0 +1,10 @@
+-- series_aliases map series names as filenames spell them, like "TMNT", to
+-- the series searched for instead and optionally the ComicVine volume the
+-- issue is looked up in. key is the name in lowercase letters and digits.
+CREATE TABLE IF NOT EXISTS series_aliases (
+    key TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    series TEXT NOT NULL,
+    volume_id INTEGER,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,7 @@
+-- ignore_rules list the files scans and batches skip, by glob pattern or
+-- exact name, such as "*.sample.cbz". Stored results of ignored files are
+-- not counted as failures.
+CREATE TABLE IF NOT EXISTS ignore_rules (
+    pattern TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,8 @@
+-- file_hash and file_size record the SHA-256 and size of files processed
+-- from disk, so copies can be found whatever their names and `db verify`
+-- can check that the stored files are unchanged. Results of bare filenames
+-- leave them NULL.
+ALTER TABLE processing_results ADD COLUMN file_hash TEXT;
+ALTER TABLE processing_results ADD COLUMN file_size INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_processing_results_file_hash ON processing_results(file_hash);
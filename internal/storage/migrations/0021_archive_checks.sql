@@ -0,0 +1,10 @@
+-- archive_checks keep the latest `check` of each archive on disk: its page
+-- count, whether it is healthy, and the problems found reading it, as a
+-- JSON array of messages.
+CREATE TABLE IF NOT EXISTS archive_checks (
+    path TEXT PRIMARY KEY,
+    page_count INTEGER NOT NULL,
+    health TEXT NOT NULL,
+    problems TEXT,
+    checked_at DATETIME NOT NULL
+);
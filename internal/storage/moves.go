@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// NextMoveOperation returns a new ID for grouping the moves of one organize
+// invocation, so they can be undone together.
+func (s *Storage) NextMoveOperation(ctx context.Context) (int64, error) {
+	id, err := s.q.NextMoveOperation(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("storage: next move operation: %w", err)
+	}
+	return id, nil
+}
+
+// RecordMove logs a completed file move and points the file's records at its
+// new path, in one transaction.
+func (s *Storage) RecordMove(ctx context.Context, move *models.FileMove) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	movedAt := move.MovedAt
+	if movedAt.IsZero() {
+		movedAt = time.Now()
+	}
+
+	qtx := s.q.WithTx(tx)
+	err = qtx.CreateFileMove(ctx, db.CreateFileMoveParams{
+		OperationID: move.OperationID,
+		Filename:    move.Filename,
+		OldPath:     move.OldPath,
+		NewPath:     move.NewPath,
+		MovedAt:     movedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("storage: record move of %s: %w", move.Filename, err)
+	}
+	if err := renameRecords(ctx, qtx, move.Filename, move.NewPath); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListLastMoveOperation returns the moves of the most recent organize
+// operation that has not been undone, newest first.
+func (s *Storage) ListLastMoveOperation(ctx context.Context) ([]models.FileMove, error) {
+	rows, err := s.q.ListLastMoveOperation(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list moves: %w", err)
+	}
+
+	moves := make([]models.FileMove, 0, len(rows))
+	for _, row := range rows {
+		moves = append(moves, models.FileMove{
+			ID:          row.ID,
+			OperationID: row.OperationID,
+			Filename:    row.Filename,
+			OldPath:     row.OldPath,
+			NewPath:     row.NewPath,
+			MovedAt:     row.MovedAt,
+		})
+	}
+	return moves, nil
+}
+
+// UndoMove marks a move as undone and points the records back at the
+// file's original stored name, after the file itself was moved back.
+func (s *Storage) UndoMove(ctx context.Context, move *models.FileMove) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.q.WithTx(tx)
+	err = qtx.MarkFileMoveUndone(ctx, db.MarkFileMoveUndoneParams{
+		UndoneAt: sql.NullTime{Time: time.Now(), Valid: true},
+		ID:       move.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("storage: undo move of %s: %w", move.Filename, err)
+	}
+	if err := renameRecords(ctx, qtx, move.NewPath, move.Filename); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ListKnownFilenames returns every filename that has a parsed or processed
+// record, as it was stored (a bare filename or a path).
+func (s *Storage) ListKnownFilenames(ctx context.Context) ([]string, error) {
+	names, err := s.q.ListKnownFilenames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list known filenames: %w", err)
+	}
+	return names, nil
+}
+
+// DeleteRecords removes all parsed and processed records for a filename.
+func (s *Storage) DeleteRecords(ctx context.Context, filename string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteRecords(ctx, s.q.WithTx(tx), filename); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteRecords(ctx context.Context, q *db.Queries, filename string) error {
+	if err := q.DeleteProcessingResultByFilename(ctx, filename); err != nil {
+		return fmt.Errorf("storage: delete processing result: %w", err)
+	}
+	if err := q.DeleteParsedFilenamesByFilename(ctx, filename); err != nil {
+		return fmt.Errorf("storage: delete parsed filenames: %w", err)
+	}
+	return nil
+}
+
+// RenameRecords points all records stored under oldName at newName, for
+// example after a file was moved. Existing records for newName are replaced.
+// An absolute newName is also recorded as the file's path.
+func (s *Storage) RenameRecords(ctx context.Context, oldName, newName string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := renameRecords(ctx, s.q.WithTx(tx), oldName, newName); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func renameRecords(ctx context.Context, q *db.Queries, oldName, newName string) error {
+	newPath := sql.NullString{String: newName, Valid: filepath.IsAbs(newName)}
+
+	if err := q.RenameProcessingResult(ctx, db.RenameProcessingResultParams{OldFilename: oldName, NewFilename: newName, NewPath: newPath}); err != nil {
+		return fmt.Errorf("storage: rename processing result: %w", err)
+	}
+	if err := q.RenameParsedFilenames(ctx, db.RenameParsedFilenamesParams{OldFilename: oldName, NewFilename: newName, NewPath: newPath}); err != nil {
+		return fmt.Errorf("storage: rename parsed filenames: %w", err)
+	}
+	return nil
+}
+
+// PruneStats reports what a prune removed (or would remove).
+type PruneStats struct {
+	Records int
+	Issues  int64
+	Volumes int64
+}
+
+// Prune deletes the records for the given filenames, then removes ComicVine
+// issues no longer referenced by any result and volumes with no remaining
+// issues. The volume cache's searches and issue lists go with them, as they
+// may have lost volumes or issues. With dryRun set, the changes are computed
+// and rolled back.
+func (s *Storage) Prune(ctx context.Context, filenames []string, dryRun bool) (*PruneStats, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	qtx := s.q.WithTx(tx)
+	stats := &PruneStats{}
+
+	for _, name := range filenames {
+		if err := deleteRecords(ctx, qtx, name); err != nil {
+			return nil, err
+		}
+		stats.Records++
+	}
+
+	if err := qtx.DeleteVolumeSearches(ctx); err != nil {
+		return nil, fmt.Errorf("storage: clear volume searches: %w", err)
+	}
+	if err := qtx.ResetVolumeIssueLists(ctx); err != nil {
+		return nil, fmt.Errorf("storage: clear volume issue lists: %w", err)
+	}
+	if stats.Issues, err = qtx.DeleteOrphanedIssues(ctx); err != nil {
+		return nil, fmt.Errorf("storage: delete orphaned issues: %w", err)
+	}
+	if stats.Volumes, err = qtx.DeleteOrphanedVolumes(ctx); err != nil {
+		return nil, fmt.Errorf("storage: delete orphaned volumes: %w", err)
+	}
+
+	if dryRun {
+		return stats, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ParsedRecord is a stored parse together with the parser that produced it.
+type ParsedRecord struct {
+	ParserName string
+	Parsed     *models.ParsedFilename
+}
+
+// ListParsedRecords returns all stored parses with their parser names.
+func (s *Storage) ListParsedRecords(ctx context.Context) ([]ParsedRecord, error) {
+	rows, err := s.q.ListParsedFilenames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list parsed filenames: %w", err)
+	}
+
+	records := make([]ParsedRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, ParsedRecord{
+			ParserName: row.ParserName,
+			Parsed:     parsedFilenameFromDB(row),
+		})
+	}
+	return records, nil
+}
+
+// ListMatchedResults returns every successful processing result that has a
+// selected ComicVine issue, with the issue and volume details and the
+// language of the parse filled in.
+func (s *Storage) ListMatchedResults(ctx context.Context) ([]*models.ProcessingResult, error) {
+	rows, err := s.q.ListMatchedResults(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list matched results: %w", err)
+	}
+
+	results := make([]*models.ProcessingResult, 0, len(rows))
+	for _, row := range rows {
+		issue := &models.ComicVineIssue{
+			ID:            int(row.IssueID),
+			Name:          row.IssueName.String,
+			IssueNumber:   row.IssueNumber.String,
+			CoverDate:     models.ParseDateLenient(row.CoverDate.String),
+			StoreDate:     models.ParseDateLenient(row.StoreDate.String),
+			SiteDetailURL: row.SiteDetailUrl.String,
+			Volume: models.VolumeRef{
+				ID:          int(row.VolumeID),
+				Name:        row.VolumeName,
+				SiteURL:     row.VolumeUrl.String,
+				Publisher:   row.PublisherName.String,
+				PublisherID: int(row.PublisherID.Int64),
+				StartYear:   row.StartYear.String,
+				IssueCount:  int(row.CountOfIssues.Int64),
+			},
+			Image: models.ImageRef{
+				SmallURL:  row.ImageSmallUrl.String,
+				MediumURL: row.ImageMediumUrl.String,
+				LargeURL:  row.ImageLargeUrl.String,
+			},
+			Description: row.Description.String,
+		}
+		if err := decodeIssueDetails(issue, row.Credits, row.Characters, row.Teams, row.Locations, row.StoryArcs); err != nil {
+			return nil, err
+		}
+		results = append(results, &models.ProcessingResult{
+			Filename:       row.Filename,
+			Path:           row.Path.String,
+			Success:        true,
+			ProcessedAt:    row.ProcessedAt,
+			SourceFilename: row.SourceFilename.String,
+			Match: &models.MatchResult{
+				OriginalFilename: row.Filename,
+				ParsedInfo:       models.ParsedFilename{OriginalFilename: row.Filename, Language: row.Language.String},
+				SelectedIssue:    issue,
+				MatchConfidence:  row.MatchConfidence.String,
+				Reasoning:        row.Reasoning.String,
+				Provenance:       row.Provenance.String,
+				ComicVineID:      issue.ID,
+				ComicVineURL:     issue.SiteDetailURL,
+			},
+		})
+	}
+	return results, nil
+}
+
+// RematchCandidate is a stored processing result that failed or matched
+// with little confidence.
+type RematchCandidate struct {
+	Filename        string
+	SourceFilename  string // the file a later issue of a multi-issue file came from
+	Success         bool
+	MatchConfidence string
+}
+
+// ListRematchCandidates returns the failed processing results when failed
+// is set, and the successful ones matched with "low" or "none" confidence
+// when lowConfidence is set. Files on the ignore list are left out.
+func (s *Storage) ListRematchCandidates(ctx context.Context, failed, lowConfidence bool) ([]RematchCandidate, error) {
+	rows, err := s.q.ListRematchCandidates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list rematch candidates: %w", err)
+	}
+	ignored, err := s.ListIgnoreRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []RematchCandidate
+	for _, row := range rows {
+		if row.Success && !lowConfidence || !row.Success && !failed {
+			continue
+		}
+		if _, ok := ignored.Match(row.Filename); ok {
+			continue
+		}
+		candidates = append(candidates, RematchCandidate{
+			Filename:        row.Filename,
+			SourceFilename:  row.SourceFilename.String,
+			Success:         row.Success,
+			MatchConfidence: row.MatchConfidence.String,
+		})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// ErrReviewNotFound is returned for a file that was never queued for
+// review.
+var ErrReviewNotFound = errors.New("storage: review not found")
+
+// QueueReview saves result and queues its match for review along with the
+// candidates it was selected from. Queueing a file again replaces its
+// earlier entry and makes it pending.
+func (s *Storage) QueueReview(ctx context.Context, result *models.ProcessingResult, candidates []models.ComicVineIssue) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("storage: encode review result: %w", err)
+	}
+	if candidates == nil {
+		candidates = []models.ComicVineIssue{}
+	}
+	candidatesJSON, err := json.Marshal(candidates)
+	if err != nil {
+		return fmt.Errorf("storage: encode review candidates: %w", err)
+	}
+
+	return s.write(ctx, func(qtx *db.Queries) error {
+		if err := s.saveResult(ctx, qtx, result); err != nil {
+			return err
+		}
+		err := qtx.UpsertReviewItem(ctx, db.UpsertReviewItemParams{
+			RunID:      s.runIDParam(),
+			Filename:   result.Filename,
+			Result:     string(resultJSON),
+			Candidates: string(candidatesJSON),
+			QueuedAt:   time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("storage: queue review of %s: %w", result.Filename, err)
+		}
+		return nil
+	})
+}
+
+// ListPendingReviews returns the queued matches still waiting for review,
+// oldest first.
+func (s *Storage) ListPendingReviews(ctx context.Context) ([]models.ReviewItem, error) {
+	rows, err := s.q.ListPendingReviews(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list pending reviews: %w", err)
+	}
+
+	items := make([]models.ReviewItem, 0, len(rows))
+	for _, row := range rows {
+		item, err := reviewItemFromDB(row)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+	return items, nil
+}
+
+// GetReview returns the review queue entry of filename, pending or
+// resolved. It returns ErrReviewNotFound when the file was never queued.
+func (s *Storage) GetReview(ctx context.Context, filename string) (*models.ReviewItem, error) {
+	row, err := s.q.GetReviewItemByFilename(ctx, filename)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrReviewNotFound, filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get review of %s: %w", filename, err)
+	}
+	return reviewItemFromDB(row)
+}
+
+func reviewItemFromDB(row db.ReviewQueue) (*models.ReviewItem, error) {
+	item := &models.ReviewItem{
+		ID:       row.ID,
+		RunID:    row.RunID.Int64,
+		Filename: row.Filename,
+		Status:   row.Status,
+		QueuedAt: row.QueuedAt,
+	}
+	if row.ReviewedAt.Valid {
+		item.ReviewedAt = row.ReviewedAt.Time
+	}
+	if err := json.Unmarshal([]byte(row.Result), &item.Result); err != nil {
+		return nil, fmt.Errorf("storage: decode review result of %s: %w", row.Filename, err)
+	}
+	if err := json.Unmarshal([]byte(row.Candidates), &item.Candidates); err != nil {
+		return nil, fmt.Errorf("storage: decode review candidates of %s: %w", row.Filename, err)
+	}
+	return item, nil
+}
+
+// ResolveReview records the reviewer's decision on item, ReviewAccepted or
+// ReviewRejected, and saves item.Result as the file's stored match, still
+// linked to the run that queued it. A decision that differs from the queued
+// match is also saved as a match correction.
+func (s *Storage) ResolveReview(ctx context.Context, item *models.ReviewItem, status string) error {
+	if status != models.ReviewAccepted && status != models.ReviewRejected {
+		return fmt.Errorf("storage: invalid review status %q", status)
+	}
+	resultJSON, err := json.Marshal(item.Result)
+	if err != nil {
+		return fmt.Errorf("storage: encode review result: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := s.q.WithTx(tx)
+	queuedJSON, err := qtx.GetReviewItemResult(ctx, item.ID)
+	if err != nil {
+		return fmt.Errorf("storage: load review of %s: %w", item.Filename, err)
+	}
+	var queued models.ProcessingResult
+	if err := json.Unmarshal([]byte(queuedJSON), &queued); err != nil {
+		return fmt.Errorf("storage: decode review result of %s: %w", item.Filename, err)
+	}
+	if err := s.WithRun(item.RunID).saveResult(ctx, qtx, item.Result); err != nil {
+		return err
+	}
+	if match := item.Result.Match; match != nil && matchID(match) != matchID(queued.Match) {
+		correction := models.NewMatchCorrection(match, queued.Match, models.CorrectionReview)
+		correction.Filename = item.Filename
+		if err := saveMatchCorrection(ctx, qtx, correction); err != nil {
+			return err
+		}
+	}
+
+	reviewedAt := time.Now()
+	err = qtx.ResolveReviewItem(ctx, db.ResolveReviewItemParams{
+		Status:     status,
+		Result:     string(resultJSON),
+		ReviewedAt: sql.NullTime{Time: reviewedAt, Valid: true},
+		ID:         item.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("storage: resolve review of %s: %w", item.Filename, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	item.Status = status
+	item.ReviewedAt = reviewedAt
+	return nil
+}
+
+// matchID returns the ComicVine ID of match's selection, or 0.
+func matchID(match *models.MatchResult) int {
+	if match == nil {
+		return 0
+	}
+	return match.ComicVineID
+}
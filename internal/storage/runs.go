@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// WithRun returns a Storage that links every saved result to the given batch
+// run. It shares the underlying connection, so only the original should be closed.
+func (s *Storage) WithRun(runID int64) *Storage {
+	return &Storage{
+		db:     s.db,
+		q:      s.q,
+		runID:  runID,
+		fts:    s.fts,
+		writer: s.writer,
+	}
+}
+
+func (s *Storage) runIDParam() sql.NullInt64 {
+	return sql.NullInt64{Int64: s.runID, Valid: s.runID != 0}
+}
+
+// saveRunResult snapshots a file's outcome for the current run so runs can be
+// compared later. It does nothing when the storage is not scoped to a run.
+func (s *Storage) saveRunResult(ctx context.Context, q *db.Queries, arg db.UpsertRunResultParams) error {
+	if s.runID == 0 {
+		return nil
+	}
+	arg.RunID = s.runID
+	if err := q.UpsertRunResult(ctx, arg); err != nil {
+		return fmt.Errorf("failed to save run result: %w", err)
+	}
+	return nil
+}
+
+// RecordRunFailure records a file that failed during the current run.
+// It does nothing when the storage is not scoped to a run.
+func (s *Storage) RecordRunFailure(ctx context.Context, filename string, cause error) error {
+	if s.runID == 0 {
+		return nil
+	}
+	return s.write(ctx, func(qtx *db.Queries) error {
+		return s.saveRunResult(ctx, qtx, db.UpsertRunResultParams{
+			Filename:  filename,
+			Success:   false,
+			Error:     sql.NullString{String: cause.Error(), Valid: true},
+			ErrorCode: sql.NullString{String: models.ErrorCodeOf(cause), Valid: true},
+		})
+	})
+}
+
+// ListRunResults returns the per-file outcomes recorded for a run.
+func (s *Storage) ListRunResults(ctx context.Context, runID int64) ([]models.RunResult, error) {
+	rows, err := s.q.ListRunResults(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list run results: %w", err)
+	}
+
+	results := make([]models.RunResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, models.RunResult{
+			Filename:        row.Filename,
+			Success:         row.Success,
+			Error:           row.Error.String,
+			ErrorCode:       row.ErrorCode.String,
+			Title:           row.Title.String,
+			IssueNumber:     row.IssueNumber.String,
+			Year:            row.Year.String,
+			MatchConfidence: row.MatchConfidence.String,
+			ComicVineID:     int(row.ComicvineID.Int64),
+		})
+	}
+	return results, nil
+}
+
+// DiffRuns compares the per-file outcomes of two runs.
+func (s *Storage) DiffRuns(ctx context.Context, runA, runB int64) (*models.RunDiff, error) {
+	a, err := s.ListRunResults(ctx, runA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.ListRunResults(ctx, runB)
+	if err != nil {
+		return nil, err
+	}
+	return diffRunResults(a, b), nil
+}
+
+// CreateBatchRun records the start of a batch run and sets run.ID.
+func (s *Storage) CreateBatchRun(ctx context.Context, run *models.BatchRun) error {
+	id, err := s.q.CreateBatchRun(ctx, db.CreateBatchRunParams{
+		StartedAt:   run.StartedAt,
+		Mode:        run.Mode,
+		InputSource: run.InputSource,
+		ParserName:  sql.NullString{String: run.ParserName, Valid: run.ParserName != ""},
+		Total:       int64(run.Total),
+		Settings:    sql.NullString{String: run.Settings, Valid: run.Settings != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("storage: create batch run: %w", err)
+	}
+	run.ID = id
+	return nil
+}
+
+// FinishBatchRun stores the final counts and LLM usage of a batch run.
+func (s *Storage) FinishBatchRun(ctx context.Context, run *models.BatchRun) error {
+	err := s.q.FinishBatchRun(ctx, db.FinishBatchRunParams{
+		FinishedAt:      sql.NullTime{Time: run.FinishedAt, Valid: !run.FinishedAt.IsZero()},
+		Processed:       int64(run.Processed),
+		Successful:      int64(run.Successful),
+		Failed:          int64(run.Failed),
+		Skipped:         int64(run.Skipped),
+		LlmInputTokens:  int64(run.LLMInputTokens),
+		LlmOutputTokens: int64(run.LLMOutputTokens),
+		LlmCost:         run.LLMCost,
+		ID:              run.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("storage: finish batch run: %w", err)
+	}
+	return nil
+}
+
+// ReopenBatchRun clears the finish time of a run that is being resumed, so it
+// counts as running again until FinishBatchRun.
+func (s *Storage) ReopenBatchRun(ctx context.Context, id int64) error {
+	if err := s.q.ReopenBatchRun(ctx, id); err != nil {
+		return fmt.Errorf("storage: reopen batch run %d: %w", id, err)
+	}
+	return nil
+}
+
+// GetBatchRun returns a single batch run by ID.
+func (s *Storage) GetBatchRun(ctx context.Context, id int64) (*models.BatchRun, error) {
+	row, err := s.q.GetBatchRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("storage: get batch run %d: %w", id, err)
+	}
+	return batchRunFromDB(row), nil
+}
+
+// ListBatchRuns returns all batch runs, newest first.
+func (s *Storage) ListBatchRuns(ctx context.Context) ([]*models.BatchRun, error) {
+	rows, err := s.q.ListBatchRuns(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list batch runs: %w", err)
+	}
+
+	var runs []*models.BatchRun
+	for _, row := range rows {
+		runs = append(runs, batchRunFromDB(row))
+	}
+	return runs, nil
+}
+
+// CountRunResults returns how many parsed filenames and processing results
+// are currently linked to the given run.
+func (s *Storage) CountRunResults(ctx context.Context, runID int64) (parsed int64, processed int64, err error) {
+	id := sql.NullInt64{Int64: runID, Valid: true}
+	if parsed, err = s.q.CountParsedFilenamesByRun(ctx, id); err != nil {
+		return 0, 0, fmt.Errorf("storage: count parsed filenames: %w", err)
+	}
+	if processed, err = s.q.CountProcessingResultsByRun(ctx, id); err != nil {
+		return 0, 0, fmt.Errorf("storage: count processing results: %w", err)
+	}
+	return parsed, processed, nil
+}
+
+func batchRunFromDB(row db.BatchRun) *models.BatchRun {
+	return &models.BatchRun{
+		ID:              row.ID,
+		StartedAt:       row.StartedAt,
+		FinishedAt:      row.FinishedAt.Time,
+		Mode:            row.Mode,
+		InputSource:     row.InputSource,
+		ParserName:      row.ParserName.String,
+		Total:           int(row.Total),
+		Processed:       int(row.Processed),
+		Successful:      int(row.Successful),
+		Failed:          int(row.Failed),
+		Skipped:         int(row.Skipped),
+		Settings:        row.Settings.String,
+		LLMInputTokens:  int(row.LlmInputTokens),
+		LLMOutputTokens: int(row.LlmOutputTokens),
+		LLMCost:         row.LlmCost,
+	}
+}
+
+// diffRunResults compares two sets of run results keyed by filename.
+// A selection counts as changed when the ComicVine ID or the parsed
+// title/issue/year differ while both runs matched.
+func diffRunResults(before, after []models.RunResult) *models.RunDiff {
+	diff := &models.RunDiff{}
+
+	afterByName := make(map[string]*models.RunResult, len(after))
+	for i := range after {
+		afterByName[after[i].Filename] = &after[i]
+	}
+
+	seen := make(map[string]bool, len(before))
+	for i := range before {
+		b := &before[i]
+		seen[b.Filename] = true
+
+		a, ok := afterByName[b.Filename]
+		if !ok {
+			diff.OnlyBefore = append(diff.OnlyBefore, b.Filename)
+			continue
+		}
+
+		change := models.RunResultChange{Filename: b.Filename, Before: b, After: a}
+		switch {
+		case !b.Matched() && a.Matched():
+			diff.NewlyMatched = append(diff.NewlyMatched, change)
+		case b.Matched() && !a.Matched():
+			diff.NewlyFailed = append(diff.NewlyFailed, change)
+		case b.Matched() && a.Matched() &&
+			(b.ComicVineID != a.ComicVineID || b.Title != a.Title || b.IssueNumber != a.IssueNumber || b.Year != a.Year):
+			diff.Changed = append(diff.Changed, change)
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	for i := range after {
+		if !seen[after[i].Filename] {
+			diff.OnlyAfter = append(diff.OnlyAfter, after[i].Filename)
+		}
+	}
+
+	return diff
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"comic-parser/internal/models"
+)
+
+// ftsSchema is an FTS5 index over search_documents. It is created apart from
+// schema because not every SQLite build ships FTS5: mattn/go-sqlite3 only
+// includes it with the sqlite_fts5 build tag, while the pure-Go driver always
+// does.
+const ftsSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS search_fts USING fts5(
+    filename, series, title, description,
+    content = 'search_documents',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS search_fts_insert AFTER INSERT ON search_documents BEGIN
+    INSERT INTO search_fts (rowid, filename, series, title, description)
+    VALUES (NEW.id, NEW.filename, NEW.series, NEW.title, NEW.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_fts_delete AFTER DELETE ON search_documents BEGIN
+    INSERT INTO search_fts (search_fts, rowid, filename, series, title, description)
+    VALUES ('delete', OLD.id, OLD.filename, OLD.series, OLD.title, OLD.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_fts_update AFTER UPDATE ON search_documents BEGIN
+    INSERT INTO search_fts (search_fts, rowid, filename, series, title, description)
+    VALUES ('delete', OLD.id, OLD.filename, OLD.series, OLD.title, OLD.description);
+    INSERT INTO search_fts (rowid, filename, series, title, description)
+    VALUES (NEW.id, NEW.filename, NEW.series, NEW.title, NEW.description);
+END;
+`
+
+const dropFTSTriggers = `
+DROP TRIGGER IF EXISTS search_fts_insert;
+DROP TRIGGER IF EXISTS search_fts_delete;
+DROP TRIGGER IF EXISTS search_fts_update;
+`
+
+// searchFTS is not in query.sql because sqlc does not know the fts5 module.
+const searchFTS = `
+SELECT d.filename, d.series, d.title, snippet(search_fts, -1, '[', ']', '...', 10)
+FROM search_fts
+JOIN search_documents d ON d.id = search_fts.rowid
+WHERE search_fts MATCH ?
+ORDER BY rank
+LIMIT ?
+`
+
+// enableFTS attaches the FTS5 index when the driver supports it and reports
+// whether it did. Without FTS5 the index triggers are dropped so a database
+// shared with an FTS5-enabled build stays writable. The index is rebuilt
+// whenever its triggers were missing, as search_documents may have changed
+// while nothing kept it in sync.
+func enableFTS(dbConn *sql.DB) (bool, error) {
+	if _, err := dbConn.Exec("SELECT fts5_source_id()"); err != nil {
+		if _, err := dbConn.Exec(dropFTSTriggers); err != nil {
+			return false, fmt.Errorf("failed to drop search index triggers: %w", err)
+		}
+		logger.Debug("FTS5 unavailable, search falls back to substring matching")
+		return false, nil
+	}
+
+	var synced int
+	err := dbConn.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'search_fts_insert'").Scan(&synced)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect search index: %w", err)
+	}
+	if _, err := dbConn.Exec(ftsSchema); err != nil {
+		return false, fmt.Errorf("failed to create search index: %w", err)
+	}
+	if synced == 0 {
+		if _, err := dbConn.Exec("INSERT INTO search_fts (search_fts) VALUES ('rebuild')"); err != nil {
+			return false, fmt.Errorf("failed to rebuild search index: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// Search finds stored comics whose filename, series, title or description
+// contain every word of query, matching words by prefix. limit caps the
+// number of hits; zero or less returns all of them. With FTS5 the hits are
+// ranked by relevance and carry a snippet with the match in brackets;
+// otherwise they are ordered by filename and have no snippet.
+func (s *Storage) Search(ctx context.Context, query string, limit int) ([]models.SearchHit, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+	if !s.fts {
+		return s.searchLike(ctx, terms, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, searchFTS, matchExpression(terms), limit)
+	if err != nil {
+		return nil, fmt.Errorf("storage: search %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	var hits []models.SearchHit
+	for rows.Next() {
+		var hit models.SearchHit
+		if err := rows.Scan(&hit.Filename, &hit.Series, &hit.Title, &hit.Snippet); err != nil {
+			return nil, fmt.Errorf("storage: search %q: %w", query, err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("storage: search %q: %w", query, err)
+	}
+	return hits, nil
+}
+
+// searchLike is the Search fallback for drivers without FTS5. SQL narrows the
+// documents to those containing the first term; the rest are checked here.
+func (s *Storage) searchLike(ctx context.Context, terms []string, limit int) ([]models.SearchHit, error) {
+	docs, err := s.q.SearchDocuments(ctx, likePattern(terms[0]))
+	if err != nil {
+		return nil, fmt.Errorf("storage: search %q: %w", strings.Join(terms, " "), err)
+	}
+
+	var hits []models.SearchHit
+	for _, doc := range docs {
+		text := strings.ToLower(strings.Join([]string{doc.Filename, doc.Series, doc.Title, doc.Description}, "\n"))
+		matched := true
+		for _, term := range terms[1:] {
+			if !strings.Contains(text, strings.ToLower(term)) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		hits = append(hits, models.SearchHit{Filename: doc.Filename, Series: doc.Series, Title: doc.Title})
+		if len(hits) == limit {
+			break
+		}
+	}
+	return hits, nil
+}
+
+// matchExpression turns user input into an FTS5 query matching every term by
+// prefix. Quoting each term keeps FTS5 operators and punctuation in user
+// input from being interpreted as query syntax.
+func matchExpression(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// likePattern matches term anywhere in a value, escaping LIKE wildcards.
+func likePattern(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+	return "%" + escaped + "%"
+}
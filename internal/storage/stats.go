@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"comic-parser/internal/models"
+)
+
+// unknownLabel stands in for a missing publisher or confidence.
+const unknownLabel = "unknown"
+
+// Stats summarizes the stored results: how many matched, unmatched and
+// failed, with failures of files on the ignore list counted apart, and the distribution of matches by confidence, publisher and cover
+// year, and of results by the month they were first stored.
+func (s *Storage) Stats(ctx context.Context) (*models.LibraryStats, error) {
+	rows, err := s.q.ListResultFacts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list results: %w", err)
+	}
+	ignored, err := s.ListIgnoreRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.LibraryStats{Total: len(rows)}
+	confidence := make(map[string]int)
+	publishers := make(map[string]int)
+	years := make(map[string]int)
+	months := make(map[string]*models.MonthStat)
+	for _, row := range rows {
+		_, isIgnored := ignored.Match(row.Filename)
+		switch {
+		case !row.Success && isIgnored:
+			stats.Ignored++
+		case !row.Success:
+			stats.Failed++
+		case !row.ComicvineID.Valid:
+			stats.Unmatched++
+		default:
+			stats.Matched++
+			label := row.MatchConfidence.String
+			if label == "" {
+				label = unknownLabel
+			}
+			confidence[label]++
+
+			publisher := row.PublisherName.String
+			if publisher == "" {
+				publisher = unknownLabel
+			}
+			publishers[publisher]++
+
+			if date := models.ParseDateLenient(row.CoverDate.String); date.Year != 0 {
+				years[fmt.Sprintf("%04d", date.Year)]++
+			}
+		}
+
+		if row.CreatedAt.Valid {
+			month := row.CreatedAt.Time.Local().Format("2006-01")
+			m, ok := months[month]
+			if !ok {
+				m = &models.MonthStat{Month: month}
+				months[month] = m
+			}
+			m.Added++
+			if !row.Success && !isIgnored {
+				m.Failed++
+			}
+		}
+	}
+
+	if stats.Total > 0 {
+		stats.FailureRate = float64(stats.Failed) / float64(stats.Total)
+	}
+	stats.Confidence = sortedCounts(confidence, func(a, b models.StatCount) bool {
+		return confidenceRank(a.Label) < confidenceRank(b.Label)
+	})
+	stats.Publishers = sortedCounts(publishers, func(a, b models.StatCount) bool {
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Label < b.Label
+	})
+	stats.Years = sortedCounts(years, func(a, b models.StatCount) bool {
+		return a.Label < b.Label
+	})
+	stats.Added = []models.MonthStat{}
+	for _, m := range months {
+		m.FailureRate = float64(m.Failed) / float64(m.Added)
+		stats.Added = append(stats.Added, *m)
+	}
+	sort.Slice(stats.Added, func(i, j int) bool { return stats.Added[i].Month < stats.Added[j].Month })
+	return stats, nil
+}
+
+func sortedCounts(counts map[string]int, less func(a, b models.StatCount) bool) []models.StatCount {
+	result := make([]models.StatCount, 0, len(counts))
+	for label, count := range counts {
+		result = append(result, models.StatCount{Label: label, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if less(result[i], result[j]) {
+			return true
+		}
+		if less(result[j], result[i]) {
+			return false
+		}
+		return result[i].Label < result[j].Label
+	})
+	return result
+}
+
+// confidenceRank orders match confidences best first, others after in name
+// order.
+func confidenceRank(confidence string) int {
+	switch confidence {
+	case "high":
+		return 0
+	case "medium":
+		return 1
+	case "low":
+		return 2
+	}
+	return 3
+}
@@ -7,73 +7,25 @@ import (
 	"time"
 
 	"comic-parser/internal/db"
+	"comic-parser/internal/logging"
 	"comic-parser/internal/models"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS comic_vine_volumes (
-    id INTEGER PRIMARY KEY,
-    name TEXT NOT NULL,
-    start_year TEXT,
-    publisher_name TEXT,
-    site_detail_url TEXT
-);
-
-CREATE TABLE IF NOT EXISTS comic_vine_issues (
-    id INTEGER PRIMARY KEY,
-    volume_id INTEGER NOT NULL,
-    name TEXT,
-    issue_number TEXT,
-    cover_date TEXT,
-    store_date TEXT,
-    description TEXT,
-    site_detail_url TEXT,
-    image_small_url TEXT,
-    image_medium_url TEXT,
-    image_large_url TEXT,
-    FOREIGN KEY (volume_id) REFERENCES comic_vine_volumes(id)
-);
-
-CREATE TABLE IF NOT EXISTS processing_results (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    filename TEXT NOT NULL UNIQUE,
-    success BOOLEAN NOT NULL,
-    error TEXT,
-    processed_at DATETIME NOT NULL,
-    processing_time_ms INTEGER NOT NULL,
-    match_confidence TEXT,
-    reasoning TEXT,
-    comicvine_id INTEGER,
-    comicvine_url TEXT,
-    FOREIGN KEY (comicvine_id) REFERENCES comic_vine_issues(id)
-);
-
-CREATE TABLE IF NOT EXISTS parsed_filenames (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    processing_result_id INTEGER,
-    parser_name TEXT NOT NULL DEFAULT 'unknown',
-    original_filename TEXT NOT NULL,
-    title TEXT NOT NULL,
-    issue_number TEXT NOT NULL,
-    year TEXT,
-    publisher TEXT,
-    volume_number TEXT,
-    confidence TEXT NOT NULL,
-    notes TEXT,
-    FOREIGN KEY (processing_result_id) REFERENCES processing_results(id) ON DELETE CASCADE,
-    UNIQUE(original_filename, parser_name)
-);
-`
+// logger is the storage subsystem's logger
+var logger = logging.Logger(logging.Storage)
 
 type Storage struct {
-	db *sql.DB
-	q  *db.Queries
+	db     *sql.DB
+	q      *db.Queries
+	runID  int64
+	fts    bool // search_fts is available; see enableFTS
+	writer *writer
 }
 
 func NewStorage(dbPath string) (*Storage, error) {
-	dbConn, err := sql.Open("sqlite3", dbPath)
+	// Pragmas go in the DSN so every pooled connection gets them, not just
+	// the first one
+	dbConn, err := sql.Open(sqlDriver, withPragmas(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -82,71 +34,57 @@ func NewStorage(dbPath string) (*Storage, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Enable foreign keys
-	if _, err := dbConn.Exec("PRAGMA foreign_keys = ON;"); err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	if err := migrate(dbConn); err != nil {
+		return nil, err
+	}
+
+	fts, err := enableFTS(dbConn)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create tables
-	if _, err := dbConn.Exec(schema); err != nil {
-		return nil, fmt.Errorf("failed to create schema: %w", err)
+	// Index records stored before search_documents existed
+	q := db.New(dbConn)
+	indexed, err := q.CountSearchDocuments(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count search documents: %w", err)
+	}
+	if indexed == 0 {
+		if err := q.IndexSearchDocuments(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to index search documents: %w", err)
+		}
 	}
 
 	return &Storage{
-		db: dbConn,
-		q:  db.New(dbConn),
+		db:     dbConn,
+		q:      q,
+		fts:    fts,
+		writer: newWriter(dbConn, q),
 	}, nil
 }
 
+// Close waits for queued writes and closes the database.
 func (s *Storage) Close() error {
+	s.writer.close()
 	return s.db.Close()
 }
 
 func (s *Storage) SaveResult(ctx context.Context, result *models.ProcessingResult) error {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	qtx := s.q.WithTx(tx)
+	return s.write(ctx, func(qtx *db.Queries) error {
+		return s.saveResult(ctx, qtx, result)
+	})
+}
 
+// saveResult stores result and its parse within the caller's transaction.
+func (s *Storage) saveResult(ctx context.Context, qtx *db.Queries, result *models.ProcessingResult) error {
 	// Save ComicVine data if match exists
 	var cvID sql.NullInt64
 	var cvURL sql.NullString
 
 	if result.Match != nil && result.Match.SelectedIssue != nil {
 		issue := result.Match.SelectedIssue
-		vol := issue.Volume
-
-		// Save Volume
-		err = qtx.UpsertVolume(ctx, db.UpsertVolumeParams{
-			ID:            int64(vol.ID),
-			Name:          vol.Name,
-			StartYear:     sql.NullString{}, // Not in VolumeRef
-			PublisherName: sql.NullString{String: vol.Publisher, Valid: vol.Publisher != ""},
-			SiteDetailUrl: sql.NullString{String: vol.SiteURL, Valid: vol.SiteURL != ""},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to upsert volume: %w", err)
-		}
-
-		// Save Issue
-		err = qtx.UpsertIssue(ctx, db.UpsertIssueParams{
-			ID:             int64(issue.ID),
-			VolumeID:       int64(vol.ID),
-			Name:           sql.NullString{String: issue.Name, Valid: issue.Name != ""},
-			IssueNumber:    sql.NullString{String: issue.IssueNumber, Valid: issue.IssueNumber != ""},
-			CoverDate:      sql.NullString{String: issue.CoverDate, Valid: issue.CoverDate != ""},
-			StoreDate:      sql.NullString{String: issue.StoreDate, Valid: issue.StoreDate != ""},
-			Description:    sql.NullString{String: issue.Description, Valid: issue.Description != ""},
-			SiteDetailUrl:  sql.NullString{String: issue.SiteDetailURL, Valid: issue.SiteDetailURL != ""},
-			ImageSmallUrl:  sql.NullString{String: issue.Image.SmallURL, Valid: issue.Image.SmallURL != ""},
-			ImageMediumUrl: sql.NullString{String: issue.Image.MediumURL, Valid: issue.Image.MediumURL != ""},
-			ImageLargeUrl:  sql.NullString{String: issue.Image.LargeURL, Valid: issue.Image.LargeURL != ""},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to upsert issue: %w", err)
+		if err := saveIssue(ctx, qtx, issue); err != nil {
+			return err
 		}
 
 		cvID = sql.NullInt64{Int64: int64(issue.ID), Valid: true}
@@ -156,10 +94,12 @@ func (s *Storage) SaveResult(ctx context.Context, result *models.ProcessingResul
 	// Save Processing Result
 	matchConf := sql.NullString{}
 	reasoning := sql.NullString{}
+	provenance := sql.NullString{}
 
 	if result.Match != nil {
 		matchConf = sql.NullString{String: result.Match.MatchConfidence, Valid: true}
 		reasoning = sql.NullString{String: result.Match.Reasoning, Valid: true}
+		provenance = sql.NullString{String: result.Match.Provenance, Valid: result.Match.Provenance != ""}
 	}
 
 	// ProcessedAt is required, but if it's zero, we should probably set it to now
@@ -172,29 +112,38 @@ func (s *Storage) SaveResult(ctx context.Context, result *models.ProcessingResul
 		Filename:         result.Filename,
 		Success:          result.Success,
 		Error:            sql.NullString{String: result.Error, Valid: result.Error != ""},
+		ErrorCode:        sql.NullString{String: result.ErrorCode, Valid: result.ErrorCode != ""},
 		ProcessedAt:      processedAt,
 		ProcessingTimeMs: result.ProcessingTimeMS,
 		MatchConfidence:  matchConf,
 		Reasoning:        reasoning,
 		ComicvineID:      cvID,
 		ComicvineUrl:     cvURL,
+		RunID:            s.runIDParam(),
+		Provenance:       provenance,
+		Path:             sql.NullString{String: result.Path, Valid: result.Path != ""},
+		SourceFilename:   sql.NullString{String: result.SourceFilename, Valid: result.SourceFilename != ""},
+		CreatedAt:        sql.NullTime{Time: processedAt, Valid: true},
+		FileHash:         sql.NullString{String: result.FileHash, Valid: result.FileHash != ""},
+		FileSize:         sql.NullInt64{Int64: result.FileSize, Valid: result.FileHash != ""},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to upsert processing result: %w", err)
 	}
 
 	// Delete old parsed filenames
-	if err := qtx.DeleteParsedFilenamesByResultID(ctx, resID); err != nil {
+	if err := qtx.DeleteParsedFilenamesByResultID(ctx, sql.NullInt64{Int64: resID, Valid: true}); err != nil {
 		return fmt.Errorf("failed to delete old parsed filenames: %w", err)
 	}
 
-	// Insert new parsed filename
+	// Insert new parsed filename, keyed like the result so each issue of a
+	// file collecting several keeps its own parse
 	if result.Match != nil {
 		info := result.Match.ParsedInfo
 		err = qtx.CreateParsedFilename(ctx, db.CreateParsedFilenameParams{
 			ProcessingResultID: sql.NullInt64{Int64: resID, Valid: true},
 			ParserName:         "pipeline",
-			OriginalFilename:   info.OriginalFilename,
+			OriginalFilename:   result.Filename,
 			Title:              info.Title,
 			IssueNumber:        info.IssueNumber,
 			Year:               sql.NullString{String: info.Year, Valid: info.Year != ""},
@@ -202,27 +151,108 @@ func (s *Storage) SaveResult(ctx context.Context, result *models.ProcessingResul
 			VolumeNumber:       sql.NullString{String: info.VolumeNumber, Valid: info.VolumeNumber != ""},
 			Confidence:         info.Confidence,
 			Notes:              sql.NullString{String: info.Notes, Valid: info.Notes != ""},
+			RunID:              s.runIDParam(),
+			Special:            sql.NullString{String: info.Special, Valid: info.Special != ""},
+			Path:               sql.NullString{String: result.Path, Valid: result.Path != ""},
+			Chapter:            sql.NullString{String: info.Chapter, Valid: info.Chapter != ""},
+			IssueType:          sql.NullString{String: info.IssueType, Valid: info.IssueType != ""},
+			Language:           sql.NullString{String: info.Language, Valid: info.Language != ""},
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create parsed filename: %w", err)
 		}
 	}
 
-	return tx.Commit()
+	runResult := db.UpsertRunResultParams{
+		Filename:        result.Filename,
+		Success:         result.Success,
+		Error:           sql.NullString{String: result.Error, Valid: result.Error != ""},
+		ErrorCode:       sql.NullString{String: result.ErrorCode, Valid: result.ErrorCode != ""},
+		MatchConfidence: matchConf,
+		ComicvineID:     cvID,
+	}
+	if result.Match != nil {
+		info := result.Match.ParsedInfo
+		runResult.Title = sql.NullString{String: info.Title, Valid: true}
+		runResult.IssueNumber = sql.NullString{String: info.IssueNumber, Valid: true}
+		runResult.Year = sql.NullString{String: info.Year, Valid: info.Year != ""}
+	}
+	return s.saveRunResult(ctx, qtx, runResult)
+}
+
+// saveIssue upserts a ComicVine issue and its volume, with the issue's
+// details when it carries them.
+func saveIssue(ctx context.Context, q *db.Queries, issue *models.ComicVineIssue) error {
+	vol := issue.Volume
+
+	// Save Volume
+	err := q.UpsertVolume(ctx, db.UpsertVolumeParams{
+		ID:            int64(vol.ID),
+		Name:          vol.Name,
+		StartYear:     sql.NullString{String: vol.StartYear, Valid: vol.StartYear != ""},
+		PublisherName: sql.NullString{String: vol.Publisher, Valid: vol.Publisher != ""},
+		SiteDetailUrl: sql.NullString{String: vol.SiteURL, Valid: vol.SiteURL != ""},
+		PublisherID:   sql.NullInt64{Int64: int64(vol.PublisherID), Valid: vol.PublisherID != 0},
+		CountOfIssues: sql.NullInt64{Int64: int64(vol.IssueCount), Valid: vol.IssueCount != 0},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert volume: %w", err)
+	}
+
+	// Save Issue
+	err = q.UpsertIssue(ctx, db.UpsertIssueParams{
+		ID:             int64(issue.ID),
+		VolumeID:       int64(vol.ID),
+		Name:           sql.NullString{String: issue.Name, Valid: issue.Name != ""},
+		IssueNumber:    sql.NullString{String: issue.IssueNumber, Valid: issue.IssueNumber != ""},
+		CoverDate:      sql.NullString{String: issue.CoverDate.String(), Valid: !issue.CoverDate.IsZero()},
+		StoreDate:      sql.NullString{String: issue.StoreDate.String(), Valid: !issue.StoreDate.IsZero()},
+		Description:    sql.NullString{String: issue.Description, Valid: issue.Description != ""},
+		SiteDetailUrl:  sql.NullString{String: issue.SiteDetailURL, Valid: issue.SiteDetailURL != ""},
+		ImageSmallUrl:  sql.NullString{String: issue.Image.SmallURL, Valid: issue.Image.SmallURL != ""},
+		ImageMediumUrl: sql.NullString{String: issue.Image.MediumURL, Valid: issue.Image.MediumURL != ""},
+		ImageLargeUrl:  sql.NullString{String: issue.Image.LargeURL, Valid: issue.Image.LargeURL != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert issue: %w", err)
+	}
+	if issue.HasDetails() {
+		return saveIssueDetails(ctx, q, issue)
+	}
+	return nil
 }
 
 func (s *Storage) SaveParsedFilename(ctx context.Context, info *models.ParsedFilename, parserName string) error {
-	return s.q.CreateParsedFilename(ctx, db.CreateParsedFilenameParams{
-		ProcessingResultID: sql.NullInt64{Valid: false},
-		ParserName:         parserName,
-		OriginalFilename:   info.OriginalFilename,
-		Title:              info.Title,
-		IssueNumber:        info.IssueNumber,
-		Year:               sql.NullString{String: info.Year, Valid: info.Year != ""},
-		Publisher:          sql.NullString{String: info.Publisher, Valid: info.Publisher != ""},
-		VolumeNumber:       sql.NullString{String: info.VolumeNumber, Valid: info.VolumeNumber != ""},
-		Confidence:         info.Confidence,
-		Notes:              sql.NullString{String: info.Notes, Valid: info.Notes != ""},
+	return s.write(ctx, func(qtx *db.Queries) error {
+		err := qtx.CreateParsedFilename(ctx, db.CreateParsedFilenameParams{
+			ProcessingResultID: sql.NullInt64{Valid: false},
+			ParserName:         parserName,
+			OriginalFilename:   info.OriginalFilename,
+			Title:              info.Title,
+			IssueNumber:        info.IssueNumber,
+			Year:               sql.NullString{String: info.Year, Valid: info.Year != ""},
+			Publisher:          sql.NullString{String: info.Publisher, Valid: info.Publisher != ""},
+			VolumeNumber:       sql.NullString{String: info.VolumeNumber, Valid: info.VolumeNumber != ""},
+			Confidence:         info.Confidence,
+			Notes:              sql.NullString{String: info.Notes, Valid: info.Notes != ""},
+			RunID:              s.runIDParam(),
+			Special:            sql.NullString{String: info.Special, Valid: info.Special != ""},
+			Path:               sql.NullString{String: info.Path, Valid: info.Path != ""},
+			Chapter:            sql.NullString{String: info.Chapter, Valid: info.Chapter != ""},
+			IssueType:          sql.NullString{String: info.IssueType, Valid: info.IssueType != ""},
+			Language:           sql.NullString{String: info.Language, Valid: info.Language != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		return s.saveRunResult(ctx, qtx, db.UpsertRunResultParams{
+			Filename:    info.OriginalFilename,
+			Success:     true,
+			Title:       sql.NullString{String: info.Title, Valid: true},
+			IssueNumber: sql.NullString{String: info.IssueNumber, Valid: true},
+			Year:        sql.NullString{String: info.Year, Valid: info.Year != ""},
+		})
 	})
 }
 
@@ -235,17 +265,25 @@ func (s *Storage) ListParsedFilenames(ctx context.Context) ([]*models.ParsedFile
 
 	var items []*models.ParsedFilename
 	for _, dbItem := range dbItems {
-		item := &models.ParsedFilename{
-			OriginalFilename: dbItem.OriginalFilename,
-			Title:            dbItem.Title,
-			IssueNumber:      dbItem.IssueNumber,
-			Year:             dbItem.Year.String,
-			Publisher:        dbItem.Publisher.String,
-			VolumeNumber:     dbItem.VolumeNumber.String,
-			Confidence:       dbItem.Confidence,
-			Notes:            dbItem.Notes.String,
-		}
-		items = append(items, item)
+		items = append(items, parsedFilenameFromDB(dbItem))
 	}
 	return items, nil
 }
+
+func parsedFilenameFromDB(dbItem db.ParsedFilename) *models.ParsedFilename {
+	return &models.ParsedFilename{
+		OriginalFilename: dbItem.OriginalFilename,
+		Title:            dbItem.Title,
+		IssueNumber:      dbItem.IssueNumber,
+		Year:             dbItem.Year.String,
+		Publisher:        dbItem.Publisher.String,
+		VolumeNumber:     dbItem.VolumeNumber.String,
+		Confidence:       dbItem.Confidence,
+		Notes:            dbItem.Notes.String,
+		Special:          dbItem.Special.String,
+		Path:             dbItem.Path.String,
+		Chapter:          dbItem.Chapter.String,
+		IssueType:        dbItem.IssueType.String,
+		Language:         dbItem.Language.String,
+	}
+}
@@ -2,15 +2,14 @@ package storage
 
 import (
 	"context"
-	"os"
+	"path/filepath"
 	"testing"
 
 	"comic-parser/internal/models"
 )
 
 func TestListParsedFilenames(t *testing.T) {
-	dbPath := "test_comics_list.db"
-	defer os.Remove(dbPath)
+	dbPath := filepath.Join(t.TempDir(), "test_comics_list.db")
 
 	store, err := NewStorage(dbPath)
 	if err != nil {
@@ -35,7 +34,8 @@ func TestListParsedFilenames(t *testing.T) {
 	p2 := &models.ParsedFilename{
 		OriginalFilename: "file2.cbz",
 		Title:            "Title 2",
-		IssueNumber:      "2",
+		Chapter:          "34",
+		IssueType:        models.IssueTypeOneShot,
 		Confidence:       "medium",
 	}
 	if err := store.SaveParsedFilename(ctx, p2, "llm"); err != nil {
@@ -67,4 +67,7 @@ func TestListParsedFilenames(t *testing.T) {
 	if items[1].Notes != "note 1" {
 		t.Errorf("Expected p1 notes 'note 1', got %s", items[1].Notes)
 	}
+	if items[0].Chapter != "34" || items[0].IssueNumber != "" || items[0].IssueType != models.IssueTypeOneShot {
+		t.Errorf("Expected p2 to be chapter 34 without an issue number, got %+v", items[0])
+	}
 }
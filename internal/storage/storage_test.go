@@ -1,17 +1,24 @@
 package storage
 
 import (
+	"bytes"
 	"context"
-	"os"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"comic-parser/internal/models"
 )
 
 func TestStorage(t *testing.T) {
-	dbPath := "test_comics.db"
-	defer os.Remove(dbPath)
+	dbPath := filepath.Join(t.TempDir(), "test_comics.db")
 
 	store, err := NewStorage(dbPath)
 	if err != nil {
@@ -115,4 +122,1933 @@ func TestStorage(t *testing.T) {
 	if timeMs != 5000 {
 		t.Errorf("Expected time 5000, got %d", timeMs)
 	}
+
+	// Test case 3: Absolute path is recorded alongside the filename
+	result.Path = "/comics/Amazing Spider-Man 001.cbz"
+	if err := store.SaveResult(ctx, result); err != nil {
+		t.Fatalf("Failed to save result with path: %v", err)
+	}
+	var path, parsedPath string
+	err = store.db.QueryRow("SELECT path FROM processing_results WHERE filename = ?", result.Filename).Scan(&path)
+	if err != nil {
+		t.Fatalf("Failed to query path: %v", err)
+	}
+	err = store.db.QueryRow("SELECT path FROM parsed_filenames WHERE original_filename = ?", result.Filename).Scan(&parsedPath)
+	if err != nil {
+		t.Fatalf("Failed to query parsed path: %v", err)
+	}
+	if path != result.Path || parsedPath != result.Path {
+		t.Errorf("Expected path %q, got %q and %q", result.Path, path, parsedPath)
+	}
+
+	// Test case 4: Each issue of a multi-issue file keeps its own records
+	second := *result
+	second.Filename = result.Filename + " #2"
+	second.Path = ""
+	second.SourceFilename = result.Filename
+	if err := store.SaveResult(ctx, &second); err != nil {
+		t.Fatalf("Failed to save second issue: %v", err)
+	}
+	err = store.db.QueryRow("SELECT count(*) FROM parsed_filenames").Scan(&count)
+	if err != nil || count != 2 {
+		t.Errorf("Expected a parse per issue, got %d, %v", count, err)
+	}
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil || len(matched) != 2 {
+		t.Fatalf("ListMatchedResults = %d results, %v", len(matched), err)
+	}
+	if matched[1].SourceFilename != result.Filename || !matched[1].LaterIssue() || matched[0].LaterIssue() {
+		t.Errorf("Unexpected issue results %+v, %+v", matched[0], matched[1])
+	}
+}
+
+func TestBatchRuns(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "runs.db")
+
+	store, err := NewStorage(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	run := &models.BatchRun{
+		StartedAt:   time.Now(),
+		Mode:        "parse",
+		InputSource: "filenames.txt",
+		ParserName:  "regex",
+		Total:       2,
+		Settings:    `{"worker_count":3}`,
+	}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+	if run.ID == 0 {
+		t.Fatal("Expected run ID to be set")
+	}
+
+	runStore := store.WithRun(run.ID)
+	if err := runStore.SaveParsedFilename(ctx, &models.ParsedFilename{OriginalFilename: "a.cbz", Title: "A", IssueNumber: "1", Confidence: "high"}, "regex"); err != nil {
+		t.Fatalf("Failed to save parsed filename: %v", err)
+	}
+	// Saved without a run, should not be linked
+	if err := store.SaveParsedFilename(ctx, &models.ParsedFilename{OriginalFilename: "b.cbz", Title: "B", IssueNumber: "1", Confidence: "high"}, "regex"); err != nil {
+		t.Fatalf("Failed to save parsed filename: %v", err)
+	}
+
+	run.FinishedAt = time.Now()
+	run.Processed = 2
+	run.Successful = 2
+	run.LLMCost = 0.25
+	if err := store.FinishBatchRun(ctx, run); err != nil {
+		t.Fatalf("Failed to finish run: %v", err)
+	}
+
+	got, err := store.GetBatchRun(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get run: %v", err)
+	}
+	if got.Successful != 2 || got.LLMCost != 0.25 || got.FinishedAt.IsZero() {
+		t.Errorf("Unexpected run summary: %+v", got)
+	}
+
+	runs, err := store.ListBatchRuns(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list runs: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("Expected 1 run, got %d", len(runs))
+	}
+
+	parsed, _, err := store.CountRunResults(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to count run results: %v", err)
+	}
+	if parsed != 1 {
+		t.Errorf("Expected 1 parsed filename linked to run, got %d", parsed)
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "checkpoints.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	if run, err := store.FindResumableRun(ctx, "parse", "list.txt"); err != nil || run != nil {
+		t.Fatalf("Expected no resumable run, got %+v, %v", run, err)
+	}
+
+	run := &models.BatchRun{StartedAt: time.Now(), Mode: "parse", InputSource: "list.txt", Total: 3}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+	runStore := store.WithRun(run.ID)
+	if err := runStore.QueueCheckpoints(ctx, []string{"c.cbz", "a.cbz", "b.cbz"}); err != nil {
+		t.Fatalf("Failed to queue checkpoints: %v", err)
+	}
+	if err := runStore.SetCheckpoint(ctx, "c.cbz", models.CheckpointDone, nil); err != nil {
+		t.Fatalf("Failed to set checkpoint: %v", err)
+	}
+	if err := runStore.SetCheckpoint(ctx, "a.cbz", models.CheckpointFailed, errors.New("rate limited")); err != nil {
+		t.Fatalf("Failed to set checkpoint: %v", err)
+	}
+
+	got, err := store.FindResumableRun(ctx, "parse", "list.txt")
+	if err != nil || got == nil || got.ID != run.ID {
+		t.Fatalf("Expected run %d to be resumable, got %+v, %v", run.ID, got, err)
+	}
+	if other, _ := store.FindResumableRun(ctx, "process", "list.txt"); other != nil {
+		t.Errorf("Expected no resumable process run, got %+v", other)
+	}
+
+	pending, err := store.PendingCheckpoints(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to list pending checkpoints: %v", err)
+	}
+	if len(pending) != 2 || pending[0] != "a.cbz" || pending[1] != "b.cbz" {
+		t.Errorf("Expected a.cbz and b.cbz pending in queue order, got %v", pending)
+	}
+
+	progress, err := store.ListRunProgress(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list run progress: %v", err)
+	}
+	if len(progress) != 1 || progress[0].Run.ID != run.ID || progress[0].Done != 1 || progress[0].Failed != 1 || progress[0].Queued != 1 {
+		t.Errorf("Unexpected run progress: %+v", progress)
+	}
+	if progress[0].Snapshot != nil {
+		t.Errorf("Expected no snapshot before one is saved, got %+v", progress[0].Snapshot)
+	}
+
+	snapshot := models.BatchProgress{Total: 3, Processed: 2, Successful: 1, Failed: 1, Workers: 2, AvgLatencyMS: 1500, UpdatedAt: time.Now()}
+	snapshot.Stages.SearchMS = 2000
+	if err := runStore.SaveRunProgress(ctx, snapshot); err != nil {
+		t.Fatalf("Failed to save run progress: %v", err)
+	}
+	snapshot.Processed = 3
+	if err := runStore.SaveRunProgress(ctx, snapshot); err != nil {
+		t.Fatalf("Failed to save run progress: %v", err)
+	}
+	progress, err = store.ListRunProgress(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list run progress: %v", err)
+	}
+	if got := progress[0].Snapshot; got == nil || got.Processed != 3 || got.Stages.SearchMS != 2000 || got.AvgLatencyMS != 1500 {
+		t.Errorf("Expected the latest snapshot, got %+v", got)
+	}
+
+	for _, name := range pending {
+		runStore.SetCheckpoint(ctx, name, models.CheckpointDone, nil)
+	}
+	if got, _ := store.FindResumableRun(ctx, "parse", "list.txt"); got != nil {
+		t.Errorf("Expected finished run not to be resumable, got %+v", got)
+	}
+
+	// Finished runs drop out of the progress list until they are reopened
+	run.FinishedAt = time.Now()
+	if err := store.FinishBatchRun(ctx, run); err != nil {
+		t.Fatalf("Failed to finish run: %v", err)
+	}
+	if progress, _ := store.ListRunProgress(ctx); len(progress) != 0 {
+		t.Errorf("Expected no unfinished runs, got %+v", progress)
+	}
+	if err := store.ReopenBatchRun(ctx, run.ID); err != nil {
+		t.Fatalf("Failed to reopen run: %v", err)
+	}
+	if progress, _ := store.ListRunProgress(ctx); len(progress) != 1 || progress[0].Done != 3 {
+		t.Errorf("Expected the reopened run with 3 files done, got %+v", progress)
+	}
+}
+
+func TestFileMoves(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "moves.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.SaveResult(ctx, &models.ProcessingResult{Filename: "saga1.cbz", Success: true}); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+
+	op, err := store.NextMoveOperation(ctx)
+	if err != nil || op != 1 {
+		t.Fatalf("NextMoveOperation = %d, %v; want 1", op, err)
+	}
+	move := &models.FileMove{OperationID: op, Filename: "saga1.cbz", OldPath: "/in/saga1.cbz", NewPath: "/lib/Saga #001.cbz"}
+	if err := store.RecordMove(ctx, move); err != nil {
+		t.Fatalf("Failed to record move: %v", err)
+	}
+	if names, _ := store.ListKnownFilenames(ctx); len(names) != 1 || names[0] != "/lib/Saga #001.cbz" {
+		t.Errorf("Expected the record to follow the move, got %v", names)
+	}
+
+	moves, err := store.ListLastMoveOperation(ctx)
+	if err != nil || len(moves) != 1 {
+		t.Fatalf("ListLastMoveOperation = %+v, %v", moves, err)
+	}
+	if err := store.UndoMove(ctx, &moves[0]); err != nil {
+		t.Fatalf("Failed to undo move: %v", err)
+	}
+	if names, _ := store.ListKnownFilenames(ctx); len(names) != 1 || names[0] != "saga1.cbz" {
+		t.Errorf("Expected the record restored, got %v", names)
+	}
+	if moves, _ := store.ListLastMoveOperation(ctx); len(moves) != 0 {
+		t.Errorf("Expected nothing left to undo, got %+v", moves)
+	}
+}
+
+func TestEpisodes(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "episodes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	parsed := &models.ParsedEpisode{OriginalFilename: "Breaking.Bad.S01E02.mkv", Series: "Breaking Bad", Season: 1, Episode: 2}
+	failed := &models.EpisodeResult{Filename: "Breaking.Bad.S01E02.mkv", Parsed: parsed, Error: "no matching episode"}
+	if err := store.SaveEpisode(ctx, failed); err != nil {
+		t.Fatalf("Failed to save episode: %v", err)
+	}
+
+	// Matching again replaces the failure
+	matched := &models.EpisodeResult{
+		Filename:   "Breaking.Bad.S01E02.mkv",
+		Parsed:     parsed,
+		Episode:    &models.TVEpisode{ID: 349232, SeriesID: 81189, SeriesName: "Breaking Bad", Season: 1, Number: 2, Name: "Cat's in the Bag..."},
+		Confidence: "high",
+		Success:    true,
+	}
+	if err := store.SaveEpisode(ctx, matched); err != nil {
+		t.Fatalf("Failed to save episode: %v", err)
+	}
+	if err := store.SaveEpisode(ctx, &models.EpisodeResult{Filename: "Home Movie.mkv", Error: "no episode number"}); err != nil {
+		t.Fatalf("Failed to save episode: %v", err)
+	}
+
+	episodes, err := store.ListEpisodes(ctx)
+	if err != nil {
+		t.Fatalf("ListEpisodes failed: %v", err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %+v", episodes)
+	}
+	got := episodes[0]
+	if !got.Success || got.Error != "" || got.Confidence != "high" || *got.Episode != *matched.Episode || *got.Parsed != *parsed {
+		t.Errorf("Unexpected stored episode: %+v", got)
+	}
+	if episodes[1].Parsed != nil || episodes[1].Episode != nil || episodes[1].Error != "no episode number" {
+		t.Errorf("Unexpected stored failure: %+v", episodes[1])
+	}
+}
+
+func TestCollections(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "collections.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	parsed := &models.ParsedCollection{OriginalFilename: "Saga Vol. 01 (TPB).cbz", Title: "Saga", Format: models.FormatTPB, VolumeNumber: "1"}
+	matched := &models.CollectionResult{
+		Filename:   "Saga Vol. 01 (TPB).cbz",
+		Parsed:     parsed,
+		Volume:     &models.ComicVineVolume{ID: 66592, Name: "Saga", StartYear: "2014", CountOfIssues: 9},
+		Confidence: "high",
+		Success:    true,
+	}
+	if err := store.SaveCollection(ctx, matched); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+	if err := store.SaveCollection(ctx, &models.CollectionResult{Filename: "Watchmen (HC).cbz", Error: "no matching volume found"}); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+
+	collections, err := store.ListCollections(ctx)
+	if err != nil {
+		t.Fatalf("ListCollections failed: %v", err)
+	}
+	if len(collections) != 2 {
+		t.Fatalf("Expected 2 collections, got %+v", collections)
+	}
+	got := collections[0]
+	if !got.Success || got.Confidence != "high" || *got.Volume != *matched.Volume || *got.Parsed != *parsed {
+		t.Errorf("Unexpected stored collection: %+v", got)
+	}
+	if collections[1].Volume != nil || collections[1].Error != "no matching volume found" {
+		t.Errorf("Unexpected stored failure: %+v", collections[1])
+	}
+}
+
+func TestSeriesAliases(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "aliases.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.SaveSeriesAlias(ctx, models.SeriesAlias{Name: "TMNT", Series: "Teenage Mutant Ninja Turtles"}); err != nil {
+		t.Fatalf("SaveSeriesAlias failed: %v", err)
+	}
+	if err := store.SaveSeriesAlias(ctx, models.SeriesAlias{Name: "2000AD", Series: "2000 AD", VolumeID: 1234}); err != nil {
+		t.Fatalf("SaveSeriesAlias failed: %v", err)
+	}
+	if err := store.SaveSeriesAlias(ctx, models.SeriesAlias{Name: "?!", Series: "Nothing"}); err == nil {
+		t.Error("Expected an alias without letters or digits to fail")
+	}
+
+	alias, ok, err := store.SeriesAlias(ctx, "t.m.n.t.")
+	if err != nil || !ok {
+		t.Fatalf("SeriesAlias = %v, %v, want the TMNT alias", ok, err)
+	}
+	if alias.Series != "Teenage Mutant Ninja Turtles" || alias.VolumeID != 0 {
+		t.Errorf("Unexpected alias %+v", alias)
+	}
+	if _, ok, err := store.SeriesAlias(ctx, "Batman"); ok || err != nil {
+		t.Errorf("SeriesAlias(Batman) = %v, %v, want no alias", ok, err)
+	}
+
+	// Re-adding a name replaces its alias
+	if err := store.SaveSeriesAlias(ctx, models.SeriesAlias{Name: "tmnt", Series: "TMNT", VolumeID: 42}); err != nil {
+		t.Fatalf("SaveSeriesAlias failed: %v", err)
+	}
+	aliases, err := store.ListSeriesAliases(ctx)
+	if err != nil {
+		t.Fatalf("ListSeriesAliases failed: %v", err)
+	}
+	if len(aliases) != 2 || aliases[0].Name != "2000AD" || aliases[0].VolumeID != 1234 || aliases[1].Name != "tmnt" || aliases[1].VolumeID != 42 {
+		t.Errorf("Unexpected aliases %+v", aliases)
+	}
+
+	if err := store.DeleteSeriesAlias(ctx, "TMNT"); err != nil {
+		t.Fatalf("DeleteSeriesAlias failed: %v", err)
+	}
+	if err := store.DeleteSeriesAlias(ctx, "TMNT"); !errors.Is(err, ErrAliasNotFound) {
+		t.Errorf("Expected ErrAliasNotFound, got %v", err)
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "ignore.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, r := range []*models.ProcessingResult{
+		{Filename: "Saga 001.cbz", Success: false, Error: "no match"},
+		{Filename: "Saga 002.sample.cbz", Success: false, Error: "no match"},
+		{Filename: "Saga 003 [Group].cbz", Success: false, Error: "no match"},
+	} {
+		r.ProcessedAt = time.Now()
+		if err := store.SaveResult(ctx, r); err != nil {
+			t.Fatalf("SaveResult failed: %v", err)
+		}
+	}
+
+	for _, pattern := range []string{"*.sample.cbz", "Saga 003 [Group].cbz", "*.sample.cbz"} {
+		if err := store.AddIgnoreRule(ctx, pattern); err != nil {
+			t.Fatalf("AddIgnoreRule(%q) failed: %v", pattern, err)
+		}
+	}
+	rules, err := store.ListIgnoreRules(ctx)
+	if err != nil {
+		t.Fatalf("ListIgnoreRules failed: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Errorf("Expected 2 rules, got %+v", rules)
+	}
+
+	// Failures of ignored files are counted apart and not rematched
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Failed != 1 || stats.Ignored != 2 {
+		t.Errorf("Expected 1 failure and 2 ignored, got %+v", stats)
+	}
+	candidates, err := store.ListRematchCandidates(ctx, true, false)
+	if err != nil {
+		t.Fatalf("ListRematchCandidates failed: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Filename != "Saga 001.cbz" {
+		t.Errorf("Expected only Saga 001.cbz to rematch, got %+v", candidates)
+	}
+
+	if err := store.RemoveIgnoreRule(ctx, "*.sample.cbz"); err != nil {
+		t.Fatalf("RemoveIgnoreRule failed: %v", err)
+	}
+	if err := store.RemoveIgnoreRule(ctx, "*.sample.cbz"); !errors.Is(err, ErrIgnoreRuleNotFound) {
+		t.Errorf("Expected ErrIgnoreRuleNotFound, got %v", err)
+	}
+}
+
+func TestReadingLists(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "lists.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.CreateReadingList(ctx, "Civil War"); err != nil {
+		t.Fatalf("CreateReadingList failed: %v", err)
+	}
+	if err := store.CreateReadingList(ctx, "Civil War"); err == nil {
+		t.Error("Expected a second list of the same name to fail")
+	}
+
+	vol := models.VolumeRef{ID: 18023, Name: "Civil War", StartYear: "2006"}
+	for _, id := range []int{1, 2, 3} {
+		issue := &models.ComicVineIssue{ID: id, IssueNumber: fmt.Sprint(id), Volume: vol}
+		if err := store.SaveIssue(ctx, issue); err != nil {
+			t.Fatalf("SaveIssue failed: %v", err)
+		}
+	}
+	if err := store.AddToReadingList(ctx, "Civil War", []int{1, 2, 99}); !errors.Is(err, ErrIssueNotStored) {
+		t.Errorf("Expected ErrIssueNotStored, got %v", err)
+	}
+	if err := store.AddToReadingList(ctx, "Civil War", []int{1, 2, 3}); err != nil {
+		t.Fatalf("AddToReadingList failed: %v", err)
+	}
+	if err := store.MoveReadingListEntry(ctx, "Civil War", 3, 1); err != nil {
+		t.Fatalf("MoveReadingListEntry failed: %v", err)
+	}
+	if err := store.RemoveReadingListEntry(ctx, "Civil War", 2); err != nil {
+		t.Fatalf("RemoveReadingListEntry failed: %v", err)
+	}
+	if err := store.MoveReadingListEntry(ctx, "Civil War", 1, 5); err == nil {
+		t.Error("Expected moving past the end to fail")
+	}
+
+	// Listed issues are kept by prune
+	if _, err := store.Prune(ctx, nil, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	list, err := store.GetReadingList(ctx, "Civil War")
+	if err != nil {
+		t.Fatalf("GetReadingList failed: %v", err)
+	}
+	var ids []int
+	for i, e := range list.Entries {
+		if e.Position != i+1 || e.Issue.Volume.Name != "Civil War" {
+			t.Errorf("Unexpected entry %+v", e)
+		}
+		ids = append(ids, e.Issue.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{3, 2}) {
+		t.Errorf("Expected issues 3, 2, got %v", ids)
+	}
+
+	lists, err := store.ListReadingLists(ctx)
+	if err != nil || len(lists) != 1 || lists[0].EntryCount != 2 {
+		t.Errorf("Unexpected lists %+v: %v", lists, err)
+	}
+	if err := store.DeleteReadingList(ctx, "Civil War"); err != nil {
+		t.Fatalf("DeleteReadingList failed: %v", err)
+	}
+	if _, err := store.GetReadingList(ctx, "Civil War"); !errors.Is(err, ErrListNotFound) {
+		t.Errorf("Expected ErrListNotFound, got %v", err)
+	}
+}
+
+func TestLLMUsage(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	run := &models.BatchRun{StartedAt: time.Now(), Mode: "parse", InputSource: "todo.txt", Total: 2}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create batch run: %v", err)
+	}
+
+	runStore := store.WithRun(run.ID)
+	calls := []struct {
+		store *Storage
+		usage models.LLMUsage
+	}{
+		{runStore, models.LLMUsage{Filename: "a.cbz", Kind: "parsed_filename", Provider: "anthropic", Model: "claude", InputTokens: 100, OutputTokens: 20, Cost: 0.01}},
+		{runStore, models.LLMUsage{Filename: "b.cbz", Kind: "parsed_filename", Provider: "anthropic", Model: "claude", InputTokens: 120, OutputTokens: 30, Cost: 0.02}},
+		{runStore, models.LLMUsage{Filename: "b.cbz", Kind: "select_match", Provider: "anthropic", Model: "claude", InputTokens: 500, OutputTokens: 50, Cost: 0.03}},
+		{store, models.LLMUsage{Filename: "c.cbz", Kind: "parsed_filename", Provider: "openai", Model: "llama3.1", InputTokens: 90, OutputTokens: 10}},
+	}
+	for _, c := range calls {
+		if err := c.store.RecordLLMUsage(ctx, &c.usage); err != nil {
+			t.Fatalf("Failed to record usage: %v", err)
+		}
+	}
+
+	byRun, err := store.UsageByRun(ctx)
+	if err != nil {
+		t.Fatalf("UsageByRun failed: %v", err)
+	}
+	if len(byRun) != 2 {
+		t.Fatalf("Expected usage for 2 runs, got %+v", byRun)
+	}
+	if byRun[0].RunID != 0 || byRun[0].Calls != 1 {
+		t.Errorf("Expected the call outside a run first, got %+v", byRun[0])
+	}
+	got := byRun[1]
+	if got.RunID != run.ID || got.Mode != "parse" || got.InputSource != "todo.txt" ||
+		got.Calls != 3 || got.InputTokens != 720 || got.OutputTokens != 100 || got.Cost < 0.0599 || got.Cost > 0.0601 {
+		t.Errorf("Unexpected run usage %+v", got)
+	}
+
+	byModel, err := store.UsageByModel(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("UsageByModel failed: %v", err)
+	}
+	if len(byModel) != 2 || byModel[0].Kind != "parsed_filename" || byModel[0].Calls != 2 || byModel[1].Kind != "select_match" {
+		t.Errorf("Unexpected usage by model for the run: %+v", byModel)
+	}
+
+	all, err := store.UsageByModel(ctx, 0)
+	if err != nil {
+		t.Fatalf("UsageByModel failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected 3 provider/model/kind groups overall, got %+v", all)
+	}
+}
+
+func TestReviewQueue(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "review.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	run := &models.BatchRun{StartedAt: time.Now(), Mode: "process", InputSource: "todo.txt", Total: 1}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create batch run: %v", err)
+	}
+
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}},
+		{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}},
+	}
+	result := &models.ProcessingResult{
+		Filename: "saga1.cbz",
+		Success:  true,
+		Match: &models.MatchResult{
+			ParsedInfo:      models.ParsedFilename{OriginalFilename: "saga1.cbz", Title: "Saga", IssueNumber: "1"},
+			SelectedIssue:   &candidates[1],
+			ComicVineID:     2,
+			MatchConfidence: "low",
+		},
+	}
+	if err := store.WithRun(run.ID).QueueReview(ctx, result, candidates); err != nil {
+		t.Fatalf("Failed to queue review: %v", err)
+	}
+
+	items, err := store.ListPendingReviews(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list reviews: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 pending review, got %d", len(items))
+	}
+	item := items[0]
+	if item.RunID != run.ID || item.Status != models.ReviewPending || len(item.Candidates) != 2 ||
+		item.Result.Match.ComicVineID != 2 {
+		t.Errorf("Unexpected review item %+v", item)
+	}
+
+	// Correct the match to the first candidate
+	item.Result.Match.SelectedIssue = &item.Candidates[0]
+	item.Result.Match.ComicVineID = 1
+	item.Result.Match.MatchConfidence = "high"
+	if err := store.ResolveReview(ctx, &item, models.ReviewAccepted); err != nil {
+		t.Fatalf("Failed to resolve review: %v", err)
+	}
+	if item.Status != models.ReviewAccepted || item.ReviewedAt.IsZero() {
+		t.Errorf("Expected the item to be marked accepted, got %+v", item)
+	}
+
+	if items, _ := store.ListPendingReviews(ctx); len(items) != 0 {
+		t.Errorf("Expected an empty queue after review, got %d items", len(items))
+	}
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list matched results: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Match.ComicVineID != 1 || matched[0].Match.MatchConfidence != "high" {
+		t.Errorf("Expected the stored match to be corrected, got %+v", matched)
+	}
+
+	corrections, err := store.ListMatchCorrections(ctx, 10)
+	if err != nil {
+		t.Fatalf("Failed to list match corrections: %v", err)
+	}
+	want := models.MatchCorrection{
+		Filename: "saga1.cbz", Title: "Saga", IssueNumber: "1",
+		OriginalComicVineID: 2, ComicVineID: 1, VolumeName: "Saga", CorrectedIssue: "1",
+		Source: models.CorrectionReview,
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("Expected 1 match correction, got %+v", corrections)
+	}
+	got := corrections[0]
+	got.CreatedAt = time.Time{}
+	if got != want {
+		t.Errorf("Unexpected match correction %+v, want %+v", got, want)
+	}
+
+	if err := store.ResolveReview(ctx, &item, "maybe"); err == nil {
+		t.Error("Expected an invalid status to be refused")
+	}
+}
+
+func TestReviewConfirmedIsNotACorrection(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "review.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	issue := models.ComicVineIssue{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}}
+	result := &models.ProcessingResult{
+		Filename: "saga1.cbz",
+		Success:  true,
+		Match: &models.MatchResult{
+			ParsedInfo:      models.ParsedFilename{OriginalFilename: "saga1.cbz", Title: "Saga", IssueNumber: "1"},
+			SelectedIssue:   &issue,
+			ComicVineID:     1,
+			MatchConfidence: "medium",
+		},
+	}
+	if err := store.QueueReview(ctx, result, []models.ComicVineIssue{issue}); err != nil {
+		t.Fatalf("Failed to queue review: %v", err)
+	}
+	items, err := store.ListPendingReviews(ctx)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("Expected 1 pending review, got %d (%v)", len(items), err)
+	}
+	item := items[0]
+	item.Result.Match = models.AcceptCandidate(item.Result.Match, issue)
+	if err := store.ResolveReview(ctx, &item, models.ReviewAccepted); err != nil {
+		t.Fatalf("Failed to resolve review: %v", err)
+	}
+
+	if corrections, _ := store.ListMatchCorrections(ctx, 10); len(corrections) != 0 {
+		t.Errorf("Expected no correction for a confirmed match, got %+v", corrections)
+	}
+
+	// The decision is read back by filename
+	got, err := store.GetReview(ctx, "saga1.cbz")
+	if err != nil || got.Status != models.ReviewAccepted || got.ReviewedAt.IsZero() || got.Result.Match.MatchConfidence != "high" {
+		t.Errorf("GetReview = %+v, %v; want the accepted match", got, err)
+	}
+	if _, err := store.GetReview(ctx, "other.cbz"); !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("Expected ErrReviewNotFound, got %v", err)
+	}
+}
+
+func TestAssignMatch(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "assign.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	assign := func(issue models.ComicVineIssue) {
+		t.Helper()
+		result := &models.ProcessingResult{
+			Filename: "weird_name.cbz",
+			Success:  true,
+			Match: &models.MatchResult{
+				OriginalFilename: "weird_name.cbz",
+				ParsedInfo:       models.ParsedFilename{OriginalFilename: "weird_name.cbz", Title: "weird name", IssueNumber: "1"},
+				SelectedIssue:    &issue,
+				ComicVineID:      issue.ID,
+				MatchConfidence:  "high",
+			},
+		}
+		if err := store.AssignMatch(ctx, result, models.CorrectionAssigned); err != nil {
+			t.Fatalf("AssignMatch failed: %v", err)
+		}
+	}
+
+	assign(models.ComicVineIssue{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}})
+	assign(models.ComicVineIssue{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}})
+	assign(models.ComicVineIssue{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}})
+
+	corrections, err := store.ListMatchCorrections(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListMatchCorrections failed: %v", err)
+	}
+	if len(corrections) != 2 {
+		t.Fatalf("Expected a correction per changed match, got %+v", corrections)
+	}
+	// Newest first
+	if c := corrections[0]; c.ComicVineID != 2 || c.OriginalComicVineID != 1 || c.Source != models.CorrectionAssigned || c.VolumeName != "Saga Deluxe" {
+		t.Errorf("Unexpected correction %+v", c)
+	}
+	if c := corrections[1]; c.ComicVineID != 1 || c.OriginalComicVineID != 0 {
+		t.Errorf("Expected the first assignment to have no original match, got %+v", c)
+	}
+
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		t.Fatalf("ListMatchedResults failed: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Match.ComicVineID != 2 || matched[0].Match.MatchConfidence != "high" {
+		t.Errorf("Expected the assigned match to be stored, got %+v", matched)
+	}
+}
+
+func TestRematchCandidates(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "rematch.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	issue := &models.ComicVineIssue{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}}
+	match := func(filename, confidence string) *models.MatchResult {
+		return &models.MatchResult{
+			ParsedInfo:      models.ParsedFilename{OriginalFilename: filename, Title: "Saga", IssueNumber: "1"},
+			SelectedIssue:   issue,
+			ComicVineID:     issue.ID,
+			MatchConfidence: confidence,
+		}
+	}
+	for _, result := range []*models.ProcessingResult{
+		{Filename: "failed.cbz", Error: "no results"},
+		{Filename: "high.cbz", Success: true, Match: match("high.cbz", "high")},
+		{Filename: "low.cbz", Success: true, Match: match("low.cbz", "low")},
+		{Filename: "low.cbz #2", SourceFilename: "low.cbz", Success: true, Match: match("low.cbz #2", "none")},
+	} {
+		if err := store.SaveResult(ctx, result); err != nil {
+			t.Fatalf("Failed to save %s: %v", result.Filename, err)
+		}
+	}
+
+	tests := []struct {
+		failed, low bool
+		want        []string
+	}{
+		{true, false, []string{"failed.cbz"}},
+		{false, true, []string{"low.cbz", "low.cbz #2"}},
+		{true, true, []string{"failed.cbz", "low.cbz", "low.cbz #2"}},
+	}
+	for _, tt := range tests {
+		candidates, err := store.ListRematchCandidates(ctx, tt.failed, tt.low)
+		if err != nil {
+			t.Fatalf("ListRematchCandidates failed: %v", err)
+		}
+		var got []string
+		for _, c := range candidates {
+			got = append(got, c.Filename)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ListRematchCandidates(%v, %v) = %v, want %v", tt.failed, tt.low, got, tt.want)
+		}
+	}
+
+	candidates, _ := store.ListRematchCandidates(ctx, false, true)
+	if len(candidates) != 2 || candidates[1].SourceFilename != "low.cbz" || candidates[1].MatchConfidence != "none" {
+		t.Errorf("Unexpected candidates %+v", candidates)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "search.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	saga := &models.ProcessingResult{
+		Filename: "saga1.cbz",
+		Success:  true,
+		Match: &models.MatchResult{
+			ParsedInfo: models.ParsedFilename{OriginalFilename: "saga1.cbz", Title: "Saga", IssueNumber: "1"},
+			SelectedIssue: &models.ComicVineIssue{
+				ID: 1, Name: "Chapter One", IssueNumber: "1", Description: "Alana and Marko flee with their newborn.",
+				Volume: models.VolumeRef{ID: 10, Name: "Saga"},
+			},
+			ComicVineID:     1,
+			MatchConfidence: "high",
+		},
+	}
+	if err := store.SaveResult(ctx, saga); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+	batman := &models.ParsedFilename{OriginalFilename: "batman_001.cbz", Title: "Batman", IssueNumber: "1", Confidence: "high"}
+	if err := store.SaveParsedFilename(ctx, batman, "regex"); err != nil {
+		t.Fatalf("Failed to save parsed filename: %v", err)
+	}
+
+	search := func(query string) []string {
+		t.Helper()
+		hits, err := store.Search(ctx, query, 0)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		var names []string
+		for _, hit := range hits {
+			names = append(names, hit.Filename)
+		}
+		return names
+	}
+
+	ftsAvailable := store.fts
+	for _, fts := range []bool{ftsAvailable, false} {
+		store.fts = fts
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"marko", []string{"saga1.cbz"}},        // description
+			{"saga chapter", []string{"saga1.cbz"}}, // series and title
+			{"bat", []string{"batman_001.cbz"}},     // parsed title prefix
+			{"batman saga", nil},
+			{"", nil},
+		}
+		for _, tt := range tests {
+			if got := search(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fts=%v: Search(%q) = %v, want %v", fts, tt.query, got, tt.want)
+			}
+		}
+	}
+	store.fts = ftsAvailable
+
+	// The index follows renames, new matches and deletions
+	if err := store.RenameRecords(ctx, "saga1.cbz", "Saga 001.cbz"); err != nil {
+		t.Fatalf("Failed to rename records: %v", err)
+	}
+	if got := search("alana"); !reflect.DeepEqual(got, []string{"Saga 001.cbz"}) {
+		t.Errorf("Expected the renamed file, got %v", got)
+	}
+	saga.Filename = "Saga 001.cbz"
+	saga.Match.SelectedIssue.Description = "The Will hunts them."
+	if err := store.SaveResult(ctx, saga); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+	if got := search("alana"); got != nil {
+		t.Errorf("Expected the old description to be dropped, got %v", got)
+	}
+	if got := search("hunts"); !reflect.DeepEqual(got, []string{"Saga 001.cbz"}) {
+		t.Errorf("Expected the new description to be indexed, got %v", got)
+	}
+	if err := store.DeleteRecords(ctx, "batman_001.cbz"); err != nil {
+		t.Fatalf("Failed to delete records: %v", err)
+	}
+	if got := search("batman"); got != nil {
+		t.Errorf("Expected deleted records to leave the index, got %v", got)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	latest := len(migrations)
+
+	// A database from before schema versioning, with the original columns only
+	legacyPath := filepath.Join(t.TempDir(), "legacy.db")
+	legacy, err := sql.Open(sqlDriver, legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	_, err = legacy.Exec(`
+CREATE TABLE comic_vine_volumes (id INTEGER PRIMARY KEY, name TEXT NOT NULL, start_year TEXT, publisher_name TEXT, site_detail_url TEXT);
+CREATE TABLE comic_vine_issues (
+    id INTEGER PRIMARY KEY, volume_id INTEGER NOT NULL, name TEXT, issue_number TEXT, cover_date TEXT, store_date TEXT,
+    description TEXT, site_detail_url TEXT, image_small_url TEXT, image_medium_url TEXT, image_large_url TEXT
+);
+CREATE TABLE processing_results (
+    id INTEGER PRIMARY KEY AUTOINCREMENT, filename TEXT NOT NULL UNIQUE, success BOOLEAN NOT NULL, error TEXT,
+    processed_at DATETIME NOT NULL, processing_time_ms INTEGER NOT NULL, match_confidence TEXT, reasoning TEXT,
+    comicvine_id INTEGER, comicvine_url TEXT
+);
+CREATE TABLE parsed_filenames (
+    id INTEGER PRIMARY KEY AUTOINCREMENT, processing_result_id INTEGER, parser_name TEXT NOT NULL DEFAULT 'unknown',
+    original_filename TEXT NOT NULL, title TEXT NOT NULL, issue_number TEXT NOT NULL, year TEXT, publisher TEXT,
+    volume_number TEXT, confidence TEXT NOT NULL, notes TEXT, UNIQUE(original_filename, parser_name)
+);
+INSERT INTO parsed_filenames (original_filename, title, issue_number, confidence) VALUES ('old.cbz', 'Old Timer', '1', 'high');
+`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	statuses, err := ReadMigrationStatus(legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to read migration status: %v", err)
+	}
+	if len(statuses) != latest || statuses[0].Applied() {
+		t.Errorf("Expected %d pending migrations before opening, got %+v", latest, statuses)
+	}
+
+	store, err := NewStorage(legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	if version, err := store.SchemaVersion(); err != nil || version != latest {
+		t.Errorf("SchemaVersion() = %d, %v; want %d", version, err, latest)
+	}
+	if err := store.SaveParsedFilename(ctx, &models.ParsedFilename{OriginalFilename: "new.cbz", Title: "New", IssueNumber: "1", Confidence: "high", Path: "/comics/new.cbz"}, "regex"); err != nil {
+		t.Errorf("Failed to save into the upgraded schema: %v", err)
+	}
+	if hits, err := store.Search(ctx, "timer", 0); err != nil || len(hits) != 1 {
+		t.Errorf("Expected legacy records to be searchable, got %v, %v", hits, err)
+	}
+	store.Close()
+
+	statuses, err = ReadMigrationStatus(legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to read migration status: %v", err)
+	}
+	for _, status := range statuses {
+		if !status.Applied() {
+			t.Errorf("Expected migration %d to be applied", status.Version)
+		}
+	}
+
+	// A database migrated by a newer build is refused rather than misused
+	newer, err := sql.Open(sqlDriver, legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = newer.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'future', ?)", latest+1, time.Now())
+	newer.Close()
+	if err != nil {
+		t.Fatalf("Failed to record a future migration: %v", err)
+	}
+	if store, err := NewStorage(legacyPath); err == nil {
+		store.Close()
+		t.Error("Expected a database from a newer build to be refused")
+	}
+
+	// Migration files must be numbered without gaps
+	gappy := fstest.MapFS{
+		"m/0001_initial.sql": {Data: []byte("SELECT 1;")},
+		"m/0003_later.sql":   {Data: []byte("SELECT 1;")},
+	}
+	if _, err := parseMigrations(gappy, "m"); err == nil {
+		t.Error("Expected a gap in migration versions to be rejected")
+	}
+}
+
+func TestFileHashes(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "hashes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, r := range []*models.ProcessingResult{
+		{Filename: "/comics/Saga 001.cbz", Path: "/comics/Saga 001.cbz", FileHash: "aa", FileSize: 10},
+		{Filename: "/downloads/saga-1.cbz", Path: "/downloads/saga-1.cbz", FileHash: "aa", FileSize: 10},
+		{Filename: "Bone 001.cbz"},
+	} {
+		r.ProcessedAt = time.Now()
+		if err := store.SaveResult(ctx, r); err != nil {
+			t.Fatalf("SaveResult failed: %v", err)
+		}
+	}
+
+	// Reprocessing a file by its bare name keeps the hash
+	if err := store.SaveResult(ctx, &models.ProcessingResult{Filename: "/comics/Saga 001.cbz", ProcessedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveResult failed: %v", err)
+	}
+	hashed, err := store.ListFileHashes(ctx)
+	if err != nil {
+		t.Fatalf("ListFileHashes failed: %v", err)
+	}
+	if len(hashed) != 2 || hashed[0].Filename != "/comics/Saga 001.cbz" || hashed[0].FileHash != "aa" || hashed[0].FileSize != 10 {
+		t.Errorf("Unexpected hashed files %+v", hashed)
+	}
+
+	same, err := store.FilenamesWithHash(ctx, "aa", "/downloads/saga-1.cbz")
+	if err != nil {
+		t.Fatalf("FilenamesWithHash failed: %v", err)
+	}
+	if len(same) != 1 || same[0] != "/comics/Saga 001.cbz" {
+		t.Errorf("Expected the earlier copy, got %v", same)
+	}
+}
+
+func TestArchiveChecks(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "checks.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	checked := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, c := range []*models.ArchiveCheck{
+		{Path: "/comics/b.cbz", PageCount: 3, Health: models.ArchiveDamaged, Problems: []string{"p04.jpg: zero-byte entry"}, CheckedAt: checked},
+		{Path: "/comics/a.cbz", PageCount: 22, Health: models.ArchiveHealthy, CheckedAt: checked},
+		// A second check replaces the first
+		{Path: "/comics/b.cbz", PageCount: 24, Health: models.ArchiveHealthy, CheckedAt: checked.Add(time.Hour)},
+	} {
+		if err := store.SaveArchiveCheck(ctx, c); err != nil {
+			t.Fatalf("SaveArchiveCheck failed: %v", err)
+		}
+	}
+	if err := store.SaveArchiveCheck(ctx, &models.ArchiveCheck{Path: "/comics/c.cbr", Health: models.ArchiveDamaged, Problems: []string{"empty file"}, CheckedAt: checked}); err != nil {
+		t.Fatalf("SaveArchiveCheck failed: %v", err)
+	}
+
+	checks, err := store.ListArchiveChecks(ctx)
+	if err != nil {
+		t.Fatalf("ListArchiveChecks failed: %v", err)
+	}
+	if len(checks) != 3 || checks[0].Path != "/comics/a.cbz" || checks[0].PageCount != 22 || checks[0].Problems != nil {
+		t.Fatalf("Unexpected checks %+v", checks)
+	}
+	if b := checks[1]; b.PageCount != 24 || b.Health != models.ArchiveHealthy || !b.CheckedAt.Equal(checked.Add(time.Hour)) {
+		t.Errorf("Expected the latest check of b.cbz, got %+v", b)
+	}
+	if c := checks[2]; c.Health != models.ArchiveDamaged || len(c.Problems) != 1 || c.Problems[0] != "empty file" {
+		t.Errorf("Unexpected check of c.cbr %+v", c)
+	}
+}
+
+func TestDiffRuns(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "diff.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	runA := &models.BatchRun{StartedAt: time.Now(), Mode: "parse", InputSource: "list.txt"}
+	runB := &models.BatchRun{StartedAt: time.Now(), Mode: "parse", InputSource: "list.txt"}
+	for _, run := range []*models.BatchRun{runA, runB} {
+		if err := store.CreateBatchRun(ctx, run); err != nil {
+			t.Fatalf("Failed to create run: %v", err)
+		}
+	}
+
+	save := func(s *Storage, filename, title string) {
+		t.Helper()
+		if err := s.SaveParsedFilename(ctx, &models.ParsedFilename{OriginalFilename: filename, Title: title, IssueNumber: "1", Confidence: "high"}, "llm"); err != nil {
+			t.Fatalf("Failed to save %s: %v", filename, err)
+		}
+	}
+
+	a := store.WithRun(runA.ID)
+	save(a, "same.cbz", "Same")
+	save(a, "changed.cbz", "Old Title")
+	save(a, "fixed.cbz", "Fixed")
+	if err := a.RecordRunFailure(ctx, "fixed.cbz", errors.New("boom")); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+	save(a, "broken.cbz", "Broken")
+
+	b := store.WithRun(runB.ID)
+	save(b, "same.cbz", "Same")
+	save(b, "changed.cbz", "New Title")
+	save(b, "fixed.cbz", "Fixed")
+	if err := b.RecordRunFailure(ctx, "broken.cbz", errors.New("boom")); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+	save(b, "new.cbz", "New")
+
+	diff, err := store.DiffRuns(ctx, runA.ID, runB.ID)
+	if err != nil {
+		t.Fatalf("DiffRuns failed: %v", err)
+	}
+
+	if len(diff.NewlyMatched) != 1 || diff.NewlyMatched[0].Filename != "fixed.cbz" {
+		t.Errorf("Expected fixed.cbz newly matched, got %+v", diff.NewlyMatched)
+	}
+	if len(diff.NewlyFailed) != 1 || diff.NewlyFailed[0].Filename != "broken.cbz" {
+		t.Errorf("Expected broken.cbz newly failed, got %+v", diff.NewlyFailed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Filename != "changed.cbz" {
+		t.Errorf("Expected changed.cbz changed, got %+v", diff.Changed)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Expected 1 unchanged, got %d", diff.Unchanged)
+	}
+	if len(diff.OnlyAfter) != 1 || diff.OnlyAfter[0] != "new.cbz" {
+		t.Errorf("Expected new.cbz only in second run, got %v", diff.OnlyAfter)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "prune.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	for i, name := range []string{"/lib/keep.cbz", "/lib/gone.cbz"} {
+		result := &models.ProcessingResult{
+			Filename: name,
+			Success:  true,
+			Match: &models.MatchResult{
+				ParsedInfo:      models.ParsedFilename{OriginalFilename: name, Title: "T", IssueNumber: "1", Confidence: "high"},
+				MatchConfidence: "high",
+				SelectedIssue: &models.ComicVineIssue{
+					ID:     100 + i,
+					Volume: models.VolumeRef{ID: 10 + i, Name: "Vol"},
+				},
+			},
+		}
+		if err := store.SaveResult(ctx, result); err != nil {
+			t.Fatalf("Failed to save result: %v", err)
+		}
+	}
+
+	count := func(table string) int {
+		t.Helper()
+		var n int
+		if err := store.db.QueryRow("SELECT count(*) FROM " + table).Scan(&n); err != nil {
+			t.Fatalf("Failed to count %s: %v", table, err)
+		}
+		return n
+	}
+
+	stats, err := store.Prune(ctx, []string{"/lib/gone.cbz"}, true)
+	if err != nil {
+		t.Fatalf("Dry-run prune failed: %v", err)
+	}
+	if stats.Records != 1 || stats.Issues != 1 || stats.Volumes != 1 {
+		t.Errorf("Unexpected dry-run stats: %+v", stats)
+	}
+	if count("processing_results") != 2 || count("comic_vine_issues") != 2 {
+		t.Fatal("Dry run should not change the database")
+	}
+
+	if _, err := store.Prune(ctx, []string{"/lib/gone.cbz"}, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if n := count("processing_results"); n != 1 {
+		t.Errorf("Expected 1 processing result, got %d", n)
+	}
+	if n := count("comic_vine_issues"); n != 1 {
+		t.Errorf("Expected 1 issue, got %d", n)
+	}
+	if n := count("comic_vine_volumes"); n != 1 {
+		t.Errorf("Expected 1 volume, got %d", n)
+	}
+	if n := count("parsed_filenames"); n != 1 {
+		t.Errorf("Expected 1 parsed filename, got %d", n)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "delete.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	save := func(name string, issueID int, issue string) {
+		t.Helper()
+		result := &models.ProcessingResult{
+			Filename: name,
+			Success:  true,
+			Match: &models.MatchResult{
+				ParsedInfo:      models.ParsedFilename{OriginalFilename: name, Title: "Saga", IssueNumber: issue, Confidence: "high"},
+				MatchConfidence: "high",
+				SelectedIssue: &models.ComicVineIssue{
+					ID:          issueID,
+					IssueNumber: issue,
+					Volume:      models.VolumeRef{ID: 10, Name: "Saga"},
+				},
+			},
+		}
+		if err := store.SaveResult(ctx, result); err != nil {
+			t.Fatalf("Failed to save result: %v", err)
+		}
+	}
+	save("/lib/Saga 001.cbz", 101, "1")
+	save("/lib/Saga 002.cbz", 102, "2")
+
+	refs, err := store.FindResults(ctx, "saga", "001")
+	if err != nil {
+		t.Fatalf("FindResults failed: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Filename != "/lib/Saga 001.cbz" {
+		t.Fatalf("Expected Saga 001, got %+v", refs)
+	}
+	if err := store.SoftDeleteResults(ctx, []int64{refs[0].ID}); err != nil {
+		t.Fatalf("SoftDeleteResults failed: %v", err)
+	}
+
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		t.Fatalf("ListMatchedResults failed: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Filename != "/lib/Saga 002.cbz" {
+		t.Errorf("Expected only Saga 002 to be listed, got %d results", len(matched))
+	}
+	known, err := store.ListKnownFilenames(ctx)
+	if err != nil {
+		t.Fatalf("ListKnownFilenames failed: %v", err)
+	}
+	if len(known) != 1 {
+		t.Errorf("Expected the deleted file's records to be hidden, got %v", known)
+	}
+	if hits, _ := store.Search(ctx, "001", 0); len(hits) != 0 {
+		t.Errorf("Expected no search hits for a deleted file, got %v", hits)
+	}
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Total != 1 {
+		t.Errorf("Expected 1 result in stats, got %d", stats.Total)
+	}
+
+	deleted, err := store.ListDeletedResults(ctx)
+	if err != nil {
+		t.Fatalf("ListDeletedResults failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != refs[0].ID || deleted[0].DeletedAt.IsZero() {
+		t.Fatalf("Unexpected deleted results %+v", deleted)
+	}
+
+	if err := store.UndeleteResults(ctx, []int64{refs[0].ID}); err != nil {
+		t.Fatalf("UndeleteResults failed: %v", err)
+	}
+	if hits, _ := store.Search(ctx, "001", 0); len(hits) != 1 {
+		t.Errorf("Expected an undeleted file to be searchable again, got %v", hits)
+	}
+
+	// Reprocessing a deleted file restores it
+	if err := store.SoftDeleteResults(ctx, []int64{refs[0].ID}); err != nil {
+		t.Fatalf("SoftDeleteResults failed: %v", err)
+	}
+	save("/lib/Saga 001.cbz", 101, "1")
+	if deleted, _ := store.ListDeletedResults(ctx); len(deleted) != 0 {
+		t.Errorf("Expected reprocessing to restore the result, got %+v", deleted)
+	}
+
+	if err := store.SoftDeleteResults(ctx, []int64{refs[0].ID}); err != nil {
+		t.Fatalf("SoftDeleteResults failed: %v", err)
+	}
+	purged, err := store.Purge(ctx, false)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if purged.Records != 1 || purged.Issues != 1 || purged.Volumes != 0 {
+		t.Errorf("Unexpected purge stats: %+v", purged)
+	}
+	if _, err := store.GetResultRef(ctx, refs[0].ID); err == nil {
+		t.Error("Expected the purged result to be gone")
+	}
+	if err := store.UndeleteResults(ctx, []int64{refs[0].ID}); err == nil {
+		t.Error("Expected an error undeleting a purged result")
+	}
+}
+
+func TestEditRecord(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "edit.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, parser := range []string{"regex", "llm"} {
+		item := &models.ParsedFilename{OriginalFilename: "Sgaa 002.cbz", Title: "Sgaa", IssueNumber: "2", Publisher: "Image", Confidence: "medium"}
+		if err := store.SaveParsedFilename(ctx, item, parser); err != nil {
+			t.Fatalf("Failed to save parsed filename: %v", err)
+		}
+	}
+
+	edits, err := store.EditRecord(ctx, "Sgaa 002.cbz", map[string]string{
+		EditSeries:    "Saga",
+		EditIssue:     "2",
+		EditPublisher: "",
+	}, EditSourceManual)
+	if err != nil {
+		t.Fatalf("EditRecord failed: %v", err)
+	}
+	if len(edits) != 2 || edits[0].Field != EditSeries || edits[0].OldValue != "Sgaa" || edits[1].Field != EditPublisher || edits[1].NewValue != "" {
+		t.Errorf("Expected series and publisher edits, got %+v", edits)
+	}
+
+	parsed, err := store.LatestParse(ctx, "Sgaa 002.cbz")
+	if err != nil {
+		t.Fatalf("LatestParse failed: %v", err)
+	}
+	if parsed.Title != "Saga" || parsed.Publisher != "" {
+		t.Errorf("Unexpected parse after editing: %+v", parsed)
+	}
+	if hits, _ := store.Search(ctx, "saga", 0); len(hits) != 1 {
+		t.Errorf("Expected the edited series to be searchable, got %v", hits)
+	}
+
+	if _, err := store.EditRecord(ctx, "Sgaa 002.cbz", map[string]string{EditYear: "2012"}, EditSourceAPI); err != nil {
+		t.Fatalf("EditRecord failed: %v", err)
+	}
+	trail, err := store.ListRecordEdits(ctx, "Sgaa 002.cbz")
+	if err != nil {
+		t.Fatalf("ListRecordEdits failed: %v", err)
+	}
+	if len(trail) != 3 || trail[2].Field != EditYear || trail[2].Source != EditSourceAPI || trail[2].EditedAt.IsZero() {
+		t.Errorf("Unexpected audit trail %+v", trail)
+	}
+
+	if _, err := store.EditRecord(ctx, "Sgaa 002.cbz", map[string]string{EditIssue: " "}, EditSourceManual); err == nil {
+		t.Error("Expected an error clearing the issue")
+	}
+	if _, err := store.EditRecord(ctx, "Sgaa 002.cbz", map[string]string{"title": "x"}, EditSourceManual); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if _, err := store.EditRecord(ctx, "missing.cbz", map[string]string{EditYear: "2012"}, EditSourceManual); err == nil {
+		t.Error("Expected an error for a file without a parse")
+	}
+}
+
+func TestVolumeCache(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "volumes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	before := time.Now().Add(-time.Minute)
+
+	if _, ok, err := store.CachedVolumeSearch(ctx, "Saga", time.Time{}); ok || err != nil {
+		t.Fatalf("Expected no stored search, got %v, %v", ok, err)
+	}
+
+	volumes := []models.ComicVineVolume{
+		{ID: 2, Name: "Saga", StartYear: "2012", CountOfIssues: 66, Publisher: models.PublisherRef{ID: 5, Name: "Image"}},
+		{ID: 1, Name: "Saga of the Swamp Thing", StartYear: "1982"},
+	}
+	if err := store.SaveVolumeSearch(ctx, "Saga", volumes); err != nil {
+		t.Fatalf("SaveVolumeSearch failed: %v", err)
+	}
+	got, ok, err := store.CachedVolumeSearch(ctx, "Saga", before)
+	if err != nil || !ok || !reflect.DeepEqual(got, volumes) {
+		t.Errorf("Expected the stored search in order, got %+v, %v, %v", got, ok, err)
+	}
+	if _, ok, _ := store.CachedVolumeSearch(ctx, "Saga", time.Now().Add(time.Minute)); ok {
+		t.Error("Expected an expired search to be a miss")
+	}
+
+	// Volumes from a search count as fetched; ones only stored with a match
+	// don't
+	if vol, ok, err := store.CachedVolume(ctx, 2, before); err != nil || !ok || vol.Publisher.Name != "Image" {
+		t.Errorf("Expected volume 2 from the search, got %+v, %v, %v", vol, ok, err)
+	}
+	result := &models.ProcessingResult{
+		Filename: "Paper Girls 001.cbz",
+		Success:  true,
+		Match: &models.MatchResult{
+			MatchConfidence: "high",
+			SelectedIssue: &models.ComicVineIssue{
+				ID: 300, IssueNumber: "1", Description: "First issue",
+				Volume: models.VolumeRef{ID: 3, Name: "Paper Girls"},
+			},
+		},
+	}
+	if err := store.SaveResult(ctx, result); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+	if _, ok, _ := store.CachedVolume(ctx, 3, time.Time{}); ok {
+		t.Error("Expected a volume stored with a match not to count as fetched")
+	}
+
+	if _, ok, _ := store.CachedVolumeIssues(ctx, 3, time.Time{}); ok {
+		t.Error("Expected no stored issue list before one was saved")
+	}
+	issues := []models.ComicVineIssue{
+		{ID: 300, IssueNumber: "1", CoverDate: models.Date{Year: 2015, Month: 10}},
+		{ID: 301, IssueNumber: "2", Image: models.ImageRef{SmallURL: "small.jpg"}},
+	}
+	if err := store.SaveVolumeIssues(ctx, &models.ComicVineVolume{ID: 3, Name: "Paper Girls"}, issues); err != nil {
+		t.Fatalf("SaveVolumeIssues failed: %v", err)
+	}
+	listed, ok, err := store.CachedVolumeIssues(ctx, 3, before)
+	if err != nil || !ok || len(listed) != 2 {
+		t.Fatalf("Expected 2 stored issues, got %+v, %v, %v", listed, ok, err)
+	}
+	if listed[0].CoverDate.Year != 2015 || listed[1].Image.SmallURL != "small.jpg" || listed[1].Volume.Name != "Paper Girls" {
+		t.Errorf("Unexpected stored issues: %+v", listed)
+	}
+	var description string
+	store.db.QueryRow("SELECT description FROM comic_vine_issues WHERE id = 300").Scan(&description)
+	if description != "First issue" {
+		t.Errorf("Expected the issue list to keep the description, got %q", description)
+	}
+
+	// Pruning may drop cached issues and volumes, so it drops the lists and
+	// searches that refer to them
+	if _, err := store.Prune(ctx, nil, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, ok, _ := store.CachedVolumeIssues(ctx, 3, time.Time{}); ok {
+		t.Error("Expected prune to drop the stored issue list")
+	}
+	if _, ok, _ := store.CachedVolumeSearch(ctx, "Saga", time.Time{}); ok {
+		t.Error("Expected prune to drop the stored search")
+	}
+}
+
+func TestIssueDetails(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "details.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	result := &models.ProcessingResult{
+		Filename: "Saga 001.cbz",
+		Success:  true,
+		Match: &models.MatchResult{
+			MatchConfidence: "high",
+			SelectedIssue: &models.ComicVineIssue{
+				ID: 100, IssueNumber: "1",
+				Volume: models.VolumeRef{ID: 2, Name: "Saga"},
+			},
+		},
+	}
+	if err := store.SaveResult(ctx, result); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+
+	ids, err := store.IssuesToEnrich(ctx, time.Time{})
+	if err != nil || !reflect.DeepEqual(ids, []int{100}) {
+		t.Fatalf("Expected issue 100 to need details, got %v, %v", ids, err)
+	}
+
+	issue := &models.ComicVineIssue{
+		ID:          100,
+		Description: "Alana and Marko flee",
+		Credits:     []models.Credit{{ID: 40439, Name: "Brian K. Vaughan", Role: "writer"}},
+		Characters:  []models.ResourceRef{{ID: 1, Name: "Alana"}, {ID: 2, Name: "Marko"}},
+		StoryArcs:   []models.ResourceRef{{ID: 9, Name: "Chapter One"}},
+	}
+	if err := store.SaveIssueDetails(ctx, issue); err != nil {
+		t.Fatalf("SaveIssueDetails failed: %v", err)
+	}
+	if ids, _ := store.IssuesToEnrich(ctx, time.Time{}); len(ids) != 0 {
+		t.Errorf("Expected no issues left to enrich, got %v", ids)
+	}
+	if ids, _ := store.IssuesToEnrich(ctx, time.Now().Add(time.Minute)); len(ids) != 1 {
+		t.Errorf("Expected stale details to be refetched, got %v", ids)
+	}
+
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil || len(matched) != 1 {
+		t.Fatalf("ListMatchedResults = %d results, %v", len(matched), err)
+	}
+	got := matched[0].Match.SelectedIssue
+	if got.Description != issue.Description || !reflect.DeepEqual(got.Credits, issue.Credits) ||
+		!reflect.DeepEqual(got.Characters, issue.Characters) || !reflect.DeepEqual(got.StoryArcs, issue.StoryArcs) {
+		t.Errorf("Expected the stored details back, got %+v", got)
+	}
+	if got.Teams != nil || got.Locations != nil {
+		t.Errorf("Expected no teams or locations, got %+v, %+v", got.Teams, got.Locations)
+	}
+
+	// Storing the match again, as a plain run does, keeps the details
+	if err := store.SaveResult(ctx, result); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+	matched, _ = store.ListMatchedResults(ctx)
+	if got := matched[0].Match.SelectedIssue; got.Description != issue.Description || len(got.Characters) != 2 {
+		t.Errorf("Expected a later save to keep the details, got %+v", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	jan := time.Date(2026, 1, 15, 12, 0, 0, 0, time.Local)
+	feb := time.Date(2026, 2, 15, 12, 0, 0, 0, time.Local)
+	match := func(filename, confidence string, id int, publisher string, year int, at time.Time) *models.ProcessingResult {
+		return &models.ProcessingResult{
+			Filename:    filename,
+			Success:     true,
+			ProcessedAt: at,
+			Match: &models.MatchResult{
+				MatchConfidence: confidence,
+				ComicVineID:     id,
+				SelectedIssue: &models.ComicVineIssue{
+					ID: id, IssueNumber: "1", CoverDate: models.Date{Year: year, Month: 1},
+					Volume: models.VolumeRef{ID: id, Name: filename, Publisher: publisher},
+				},
+			},
+		}
+	}
+	results := []*models.ProcessingResult{
+		match("Saga 001.cbz", "high", 1, "Image", 2012, jan),
+		match("Bone 001.cbz", "high", 2, "Cartoon Books", 1991, jan),
+		match("Saga 002.cbz", "low", 3, "Image", 2012, feb),
+		{Filename: "Mystery.cbz", Success: false, Error: "no match", ProcessedAt: feb},
+	}
+	for _, r := range results {
+		if err := store.SaveResult(ctx, r); err != nil {
+			t.Fatalf("Failed to save %s: %v", r.Filename, err)
+		}
+	}
+	// Reprocessing a file doesn't move it to a later month
+	if err := store.SaveResult(ctx, match("Saga 001.cbz", "medium", 1, "Image", 2012, feb)); err != nil {
+		t.Fatalf("Failed to save result: %v", err)
+	}
+
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.Total != 4 || stats.Matched != 3 || stats.Failed != 1 || stats.Unmatched != 0 || stats.FailureRate != 0.25 {
+		t.Errorf("Unexpected totals %+v", stats)
+	}
+	wantConfidence := []models.StatCount{{Label: "high", Count: 1}, {Label: "medium", Count: 1}, {Label: "low", Count: 1}}
+	if !reflect.DeepEqual(stats.Confidence, wantConfidence) {
+		t.Errorf("Expected confidence %v, got %v", wantConfidence, stats.Confidence)
+	}
+	wantPublishers := []models.StatCount{{Label: "Image", Count: 2}, {Label: "Cartoon Books", Count: 1}}
+	if !reflect.DeepEqual(stats.Publishers, wantPublishers) {
+		t.Errorf("Expected publishers %v, got %v", wantPublishers, stats.Publishers)
+	}
+	wantYears := []models.StatCount{{Label: "1991", Count: 1}, {Label: "2012", Count: 2}}
+	if !reflect.DeepEqual(stats.Years, wantYears) {
+		t.Errorf("Expected years %v, got %v", wantYears, stats.Years)
+	}
+	wantAdded := []models.MonthStat{
+		{Month: "2026-01", Added: 2},
+		{Month: "2026-02", Added: 2, Failed: 1, FailureRate: 0.5},
+	}
+	if !reflect.DeepEqual(stats.Added, wantAdded) {
+		t.Errorf("Expected additions %+v, got %+v", wantAdded, stats.Added)
+	}
+}
+
+func TestDumpRestore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStorage(filepath.Join(dir, "source.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for i, name := range []string{"Saga 001.cbz", "Saga 002 (it's here).cbz"} {
+		result := &models.ProcessingResult{
+			Filename: name,
+			Success:  true,
+			Match: &models.MatchResult{
+				MatchConfidence: "high",
+				Reasoning:       "line one\nline two",
+				SelectedIssue: &models.ComicVineIssue{
+					ID: 100 + i, IssueNumber: fmt.Sprint(i + 1),
+					Volume: models.VolumeRef{ID: 2, Name: "Saga", Publisher: "Image"},
+				},
+			},
+		}
+		if err := store.SaveResult(ctx, result); err != nil {
+			t.Fatalf("Failed to save result: %v", err)
+		}
+	}
+	usage := &models.LLMUsage{Filename: "Saga 001.cbz", Kind: "parse", Provider: "test", Model: "m", InputTokens: 10, Cost: 0.5}
+	if err := store.RecordLLMUsage(ctx, usage); err != nil {
+		t.Fatalf("RecordLLMUsage failed: %v", err)
+	}
+
+	var dump bytes.Buffer
+	if err := store.DumpSQL(ctx, &dump, nil); err != nil {
+		t.Fatalf("DumpSQL failed: %v", err)
+	}
+	if strings.Contains(dump.String(), "search_fts") {
+		t.Error("Expected the dump to leave out the FTS index")
+	}
+
+	restoredPath := filepath.Join(dir, "restored.db")
+	if err := Restore(ctx, restoredPath, bytes.NewReader(dump.Bytes())); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if err := Restore(ctx, restoredPath, bytes.NewReader(dump.Bytes())); err == nil {
+		t.Error("Expected restoring over an existing database to fail")
+	}
+	restored, err := NewStorage(restoredPath)
+	if err != nil {
+		t.Fatalf("Failed to open restored storage: %v", err)
+	}
+	defer restored.Close()
+
+	want, _ := store.ListMatchedResults(ctx)
+	got, err := restored.ListMatchedResults(ctx)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Expected 2 restored matches, got %d, %v", len(got), err)
+	}
+	for i := range got {
+		if got[i].Filename != want[i].Filename || got[i].Match.Reasoning != want[i].Match.Reasoning ||
+			!got[i].ProcessedAt.Equal(want[i].ProcessedAt) || got[i].Match.SelectedIssue.Volume.Publisher != "Image" {
+			t.Errorf("Restored match %+v differs from %+v", got[i].Match, want[i].Match)
+		}
+	}
+	if hits, err := restored.Search(ctx, "saga", 0); err != nil || len(hits) != 2 {
+		t.Errorf("Expected search to find both restored files, got %v, %v", hits, err)
+	}
+	if usage, _ := restored.UsageByModel(ctx, 0); len(usage) != 1 || usage[0].Cost != 0.5 {
+		t.Errorf("Expected the restored usage, got %+v", usage)
+	}
+	if v, _ := restored.SchemaVersion(); v == 0 {
+		t.Error("Expected the restored database to keep its schema version")
+	}
+
+	// A subset keeps the whole schema but only the named tables' rows
+	dump.Reset()
+	if err := store.DumpSQL(ctx, &dump, []string{"llm_usage"}); err != nil {
+		t.Fatalf("DumpSQL of a subset failed: %v", err)
+	}
+	if !strings.Contains(dump.String(), "CREATE TABLE processing_results") ||
+		strings.Contains(dump.String(), `INSERT INTO "processing_results"`) ||
+		!strings.Contains(dump.String(), `INSERT INTO "llm_usage"`) {
+		t.Errorf("Unexpected subset dump:\n%s", dump.String())
+	}
+	if err := store.DumpSQL(ctx, &dump, []string{"nope"}); err == nil {
+		t.Error("Expected an unknown table to fail")
+	}
+
+	var file bytes.Buffer
+	n, err := store.WriteParquet(ctx, &file, "processing_results")
+	if err != nil || n != 2 {
+		t.Fatalf("WriteParquet = %d, %v", n, err)
+	}
+	if data := file.Bytes(); !bytes.HasPrefix(data, []byte("PAR1")) || !bytes.HasSuffix(data, []byte("PAR1")) ||
+		!bytes.Contains(data, []byte("Saga 002 (it's here).cbz")) {
+		t.Error("Expected a Parquet file with the results")
+	}
+}
+
+func TestMappings(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "mappings.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	mapping := models.Mapping{
+		Kind:   models.MappingKindFilename,
+		Key:    "Saga 001.cbz",
+		Source: "community",
+		Issue: models.ComicVineIssue{
+			ID:          42,
+			IssueNumber: "1",
+			Volume:      models.VolumeRef{ID: 7, Name: "Saga", Publisher: "Image", StartYear: "2012", IssueCount: 66},
+		},
+	}
+	if err := store.SaveMappings(ctx, []models.Mapping{mapping}); err != nil {
+		t.Fatalf("SaveMappings failed: %v", err)
+	}
+
+	// A later save without volume details must not erase the known ones
+	sparse := mapping
+	sparse.Issue.Volume = models.VolumeRef{ID: 7, Name: "Saga"}
+	if err := store.SaveMappings(ctx, []models.Mapping{sparse}); err != nil {
+		t.Fatalf("SaveMappings failed: %v", err)
+	}
+
+	// Mapped issues are referenced and must survive pruning
+	if _, err := store.Prune(ctx, nil, false); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+
+	mappings, err := store.ListMappings(ctx)
+	if err != nil {
+		t.Fatalf("ListMappings failed: %v", err)
+	}
+	if len(mappings) != 1 {
+		t.Fatalf("Expected 1 mapping, got %d", len(mappings))
+	}
+	got := mappings[0]
+	if got.Kind != mapping.Kind || got.Key != mapping.Key || got.Source != "community" {
+		t.Errorf("Unexpected mapping: %+v", got)
+	}
+	if got.Issue.ID != 42 || got.Issue.Volume.Name != "Saga" || got.Issue.Volume.Publisher != "Image" ||
+		got.Issue.Volume.StartYear != "2012" || got.Issue.Volume.IssueCount != 66 {
+		t.Errorf("Unexpected mapped issue: %+v", got.Issue)
+	}
+}
+
+func TestOpenBackends(t *testing.T) {
+	ctx := context.Background()
+
+	// Memory backends are isolated from each other
+	a, err := Open(BackendMemory, "")
+	if err != nil {
+		t.Fatalf("Open memory failed: %v", err)
+	}
+	defer a.Close()
+	b, err := Open("", "memory:")
+	if err != nil {
+		t.Fatalf("Open memory via DSN prefix failed: %v", err)
+	}
+	defer b.Close()
+
+	parsed := &models.ParsedFilename{OriginalFilename: "a.cbz", Title: "A", IssueNumber: "1", Confidence: "high"}
+	if err := a.SaveParsedFilename(ctx, parsed, "regex"); err != nil {
+		t.Fatalf("SaveParsedFilename failed: %v", err)
+	}
+	if got, _ := b.ListParsedFilenames(ctx); len(got) != 0 {
+		t.Errorf("Expected separate memory databases, got %d rows", len(got))
+	}
+
+	// Plain paths use SQLite
+	path := filepath.Join(t.TempDir(), "comics.db")
+	s, err := Open("", path)
+	if err != nil {
+		t.Fatalf("Open sqlite failed: %v", err)
+	}
+	s.Close()
+
+	if _, err := Open("nosuch", "x"); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+
+	Register("test-backend", func(dsn string) (*Storage, error) {
+		return Open(BackendMemory, dsn)
+	})
+	custom, err := Open("", "test-backend:anything")
+	if err != nil {
+		t.Fatalf("Open registered backend failed: %v", err)
+	}
+	custom.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on duplicate registration")
+		}
+	}()
+	Register(BackendSQLite, NewStorage)
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewStorage(filepath.Join(t.TempDir(), "comics.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	var mode string
+	if err := store.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("Expected WAL journal mode, got %q, %v", mode, err)
+	}
+
+	run := &models.BatchRun{Mode: "parse", StartedAt: time.Now()}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("CreateBatchRun failed: %v", err)
+	}
+	runStore := store.WithRun(run.ID)
+
+	// Workers save results while others read, as a batch does
+	const workers, files = 8, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*files)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < files; i++ {
+				name := fmt.Sprintf("Worker %d - %03d.cbz", w, i)
+				if err := runStore.SetCheckpoint(ctx, name, models.CheckpointDone, nil); err != nil {
+					errs <- err
+				}
+				parsed := &models.ParsedFilename{OriginalFilename: name, Title: "Worker", IssueNumber: fmt.Sprint(i), Confidence: "high"}
+				if err := runStore.SaveParsedFilename(ctx, parsed, "regex"); err != nil {
+					errs <- err
+				}
+				if _, err := runStore.ListRunResults(ctx, run.ID); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Concurrent write failed: %v", err)
+	}
+
+	results, err := store.ListRunResults(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("ListRunResults failed: %v", err)
+	}
+	if len(results) != workers*files {
+		t.Errorf("Expected %d run results, got %d", workers*files, len(results))
+	}
+}
+
+func TestMatchQueue(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "queue.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	for _, filename := range []string{"Saga 001.cbz", "Saga 002.cbz", "Saga 001.cbz"} {
+		if err := store.QueueMatch(ctx, filename); err != nil {
+			t.Fatalf("Failed to queue %s: %v", filename, err)
+		}
+	}
+
+	queued, err := store.ListMatchQueue(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list match queue: %v", err)
+	}
+	if len(queued) != 2 || queued[0].Filename != "Saga 001.cbz" || queued[1].Filename != "Saga 002.cbz" {
+		t.Fatalf("Expected both files once, oldest first, got %+v", queued)
+	}
+
+	if err := store.DequeueMatch(ctx, "Saga 001.cbz"); err != nil {
+		t.Fatalf("Failed to dequeue: %v", err)
+	}
+	if err := store.DequeueMatch(ctx, "Saga 001.cbz"); err != nil {
+		t.Errorf("Expected dequeuing an unqueued file to succeed, got %v", err)
+	}
+	queued, err = store.ListMatchQueue(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list match queue: %v", err)
+	}
+	if len(queued) != 1 || queued[0].Filename != "Saga 002.cbz" {
+		t.Errorf("Expected only Saga 002.cbz queued, got %+v", queued)
+	}
+}
+
+func TestRunResultErrorCodes(t *testing.T) {
+	store, err := NewStorage(filepath.Join(t.TempDir(), "codes.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	run := &models.BatchRun{Mode: "process", StartedAt: time.Now()}
+	if err := store.CreateBatchRun(ctx, run); err != nil {
+		t.Fatalf("CreateBatchRun failed: %v", err)
+	}
+	runStore := store.WithRun(run.ID)
+
+	unmatched := &models.ProcessingResult{
+		Filename:  "Unmatched 001.cbz",
+		Error:     "no candidates found",
+		ErrorCode: models.ErrorCodeNoMatch,
+	}
+	if err := runStore.SaveResult(ctx, unmatched); err != nil {
+		t.Fatalf("SaveResult failed: %v", err)
+	}
+	limited := models.Fail(models.ErrRateLimited, errors.New("API error (status 429)"))
+	if err := runStore.RecordRunFailure(ctx, "Limited 001.cbz", limited); err != nil {
+		t.Fatalf("Failed to record failure: %v", err)
+	}
+
+	results, err := store.ListRunResults(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("ListRunResults failed: %v", err)
+	}
+	codes := make(map[string]string)
+	for _, r := range results {
+		codes[r.Filename] = r.ErrorCode
+	}
+	if codes["Unmatched 001.cbz"] != models.ErrorCodeNoMatch {
+		t.Errorf("Expected no_match for the unmatched file, got %q", codes["Unmatched 001.cbz"])
+	}
+	if codes["Limited 001.cbz"] != models.ErrorCodeRateLimited {
+		t.Errorf("Expected rate_limited for the rate limited file, got %q", codes["Limited 001.cbz"])
+	}
 }
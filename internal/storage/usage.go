@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// RecordLLMUsage stores the usage of one LLM call, linked to the current
+// batch run if there is one.
+func (s *Storage) RecordLLMUsage(ctx context.Context, usage *models.LLMUsage) error {
+	createdAt := usage.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	err := s.write(ctx, func(qtx *db.Queries) error {
+		return qtx.CreateLLMUsage(ctx, db.CreateLLMUsageParams{
+			RunID:        s.runIDParam(),
+			Filename:     usage.Filename,
+			Kind:         usage.Kind,
+			Provider:     usage.Provider,
+			Model:        usage.Model,
+			InputTokens:  int64(usage.InputTokens),
+			OutputTokens: int64(usage.OutputTokens),
+			Cost:         usage.Cost,
+			CreatedAt:    createdAt,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("storage: record llm usage: %w", err)
+	}
+	return nil
+}
+
+// UsageByRun totals LLM usage per batch run, oldest run first. Calls made
+// outside a run are totalled under RunID 0.
+func (s *Storage) UsageByRun(ctx context.Context) ([]models.UsageSummary, error) {
+	rows, err := s.q.SummarizeUsageByRun(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: summarize usage by run: %w", err)
+	}
+
+	summaries := make([]models.UsageSummary, len(rows))
+	for i, row := range rows {
+		summaries[i] = models.UsageSummary{
+			RunID:        row.RunID.Int64,
+			Mode:         row.Mode,
+			InputSource:  row.InputSource,
+			Calls:        int(row.Calls),
+			InputTokens:  int(row.InputTokens),
+			OutputTokens: int(row.OutputTokens),
+			Cost:         row.Cost,
+		}
+	}
+	return summaries, nil
+}
+
+// UsageByModel totals LLM usage per provider, model and call kind, for one
+// batch run or for all calls when runID is 0.
+func (s *Storage) UsageByModel(ctx context.Context, runID int64) ([]models.UsageSummary, error) {
+	rows, err := s.q.SummarizeUsageByModel(ctx, runID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: summarize usage by model: %w", err)
+	}
+
+	summaries := make([]models.UsageSummary, len(rows))
+	for i, row := range rows {
+		summaries[i] = models.UsageSummary{
+			RunID:        runID,
+			Provider:     row.Provider,
+			Model:        row.Model,
+			Kind:         row.Kind,
+			Calls:        int(row.Calls),
+			InputTokens:  int(row.InputTokens),
+			OutputTokens: int(row.OutputTokens),
+			Cost:         row.Cost,
+		}
+	}
+	return summaries, nil
+}
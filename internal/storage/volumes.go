@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"comic-parser/internal/db"
+	"comic-parser/internal/models"
+)
+
+// CachedVolumeSearch returns the volumes a ComicVine search for query
+// returned, if it was fetched at or after since. Together with CachedVolume
+// and CachedVolumeIssues it lets the API client skip requests earlier runs
+// made.
+func (s *Storage) CachedVolumeSearch(ctx context.Context, query string, since time.Time) ([]models.ComicVineVolume, bool, error) {
+	fetchedAt, err := s.q.GetVolumeSearch(ctx, query)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && fetchedAt.Before(since)) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("storage: get volume search %q: %w", query, err)
+	}
+
+	rows, err := s.q.ListVolumeSearchResults(ctx, query)
+	if err != nil {
+		return nil, false, fmt.Errorf("storage: list volume search %q: %w", query, err)
+	}
+	volumes := make([]models.ComicVineVolume, 0, len(rows))
+	for _, row := range rows {
+		volumes = append(volumes, models.ComicVineVolume{
+			ID:            int(row.ID),
+			Name:          row.Name,
+			StartYear:     row.StartYear.String,
+			CountOfIssues: int(row.CountOfIssues.Int64),
+			SiteDetailURL: row.SiteDetailUrl.String,
+			Publisher:     models.PublisherRef{ID: int(row.PublisherID.Int64), Name: row.PublisherName.String},
+		})
+	}
+	return volumes, true, nil
+}
+
+// SaveVolumeSearch stores the volumes a search for query returned, in order.
+func (s *Storage) SaveVolumeSearch(ctx context.Context, query string, volumes []models.ComicVineVolume) error {
+	now := time.Now()
+	return s.write(ctx, func(qtx *db.Queries) error {
+		if err := qtx.UpsertVolumeSearch(ctx, db.UpsertVolumeSearchParams{Query: query, FetchedAt: now}); err != nil {
+			return fmt.Errorf("storage: save volume search %q: %w", query, err)
+		}
+		if err := qtx.DeleteVolumeSearchResults(ctx, query); err != nil {
+			return fmt.Errorf("storage: save volume search %q: %w", query, err)
+		}
+		for i := range volumes {
+			if err := saveFetchedVolume(ctx, qtx, &volumes[i], now); err != nil {
+				return err
+			}
+			err := qtx.CreateVolumeSearchResult(ctx, db.CreateVolumeSearchResultParams{
+				Query:    query,
+				Position: int64(i),
+				VolumeID: int64(volumes[i].ID),
+			})
+			if err != nil {
+				return fmt.Errorf("storage: save volume search %q: %w", query, err)
+			}
+		}
+		return nil
+	})
+}
+
+// CachedVolume returns the volume with the given ID if it was fetched at or
+// after since.
+func (s *Storage) CachedVolume(ctx context.Context, id int, since time.Time) (*models.ComicVineVolume, bool, error) {
+	row, err := s.q.GetFetchedVolume(ctx, int64(id))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && row.FetchedAt.Time.Before(since)) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("storage: get volume %d: %w", id, err)
+	}
+	return &models.ComicVineVolume{
+		ID:            int(row.ID),
+		Name:          row.Name,
+		StartYear:     row.StartYear.String,
+		CountOfIssues: int(row.CountOfIssues.Int64),
+		SiteDetailURL: row.SiteDetailUrl.String,
+		Publisher:     models.PublisherRef{ID: int(row.PublisherID.Int64), Name: row.PublisherName.String},
+	}, true, nil
+}
+
+// SaveVolume stores a volume fetched from the API.
+func (s *Storage) SaveVolume(ctx context.Context, vol *models.ComicVineVolume) error {
+	now := time.Now()
+	return s.write(ctx, func(qtx *db.Queries) error {
+		return saveFetchedVolume(ctx, qtx, vol, now)
+	})
+}
+
+func saveFetchedVolume(ctx context.Context, qtx *db.Queries, vol *models.ComicVineVolume, fetchedAt time.Time) error {
+	err := qtx.UpsertFetchedVolume(ctx, db.UpsertFetchedVolumeParams{
+		ID:            int64(vol.ID),
+		Name:          vol.Name,
+		StartYear:     sql.NullString{String: vol.StartYear, Valid: vol.StartYear != ""},
+		PublisherName: sql.NullString{String: vol.Publisher.Name, Valid: vol.Publisher.Name != ""},
+		SiteDetailUrl: sql.NullString{String: vol.SiteDetailURL, Valid: vol.SiteDetailURL != ""},
+		PublisherID:   sql.NullInt64{Int64: int64(vol.Publisher.ID), Valid: vol.Publisher.ID != 0},
+		CountOfIssues: sql.NullInt64{Int64: int64(vol.CountOfIssues), Valid: vol.CountOfIssues != 0},
+		FetchedAt:     sql.NullTime{Time: fetchedAt, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("storage: save volume %d: %w", vol.ID, err)
+	}
+	return nil
+}
+
+// CachedVolumeIssues returns every issue of a volume if its issue list was
+// fetched at or after since.
+func (s *Storage) CachedVolumeIssues(ctx context.Context, volumeID int, since time.Time) ([]models.ComicVineIssue, bool, error) {
+	fetchedAt, err := s.q.GetVolumeIssuesFetchedAt(ctx, int64(volumeID))
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (!fetchedAt.Valid || fetchedAt.Time.Before(since))) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("storage: get issue list of volume %d: %w", volumeID, err)
+	}
+
+	rows, err := s.q.ListVolumeIssues(ctx, int64(volumeID))
+	if err != nil {
+		return nil, false, fmt.Errorf("storage: list issues of volume %d: %w", volumeID, err)
+	}
+	issues := make([]models.ComicVineIssue, 0, len(rows))
+	for _, row := range rows {
+		issues = append(issues, models.ComicVineIssue{
+			ID:            int(row.ID),
+			Name:          row.Name.String,
+			IssueNumber:   row.IssueNumber.String,
+			CoverDate:     models.ParseDateLenient(row.CoverDate.String),
+			StoreDate:     models.ParseDateLenient(row.StoreDate.String),
+			SiteDetailURL: row.SiteDetailUrl.String,
+			Volume: models.VolumeRef{
+				ID:      volumeID,
+				Name:    row.VolumeName,
+				SiteURL: row.VolumeUrl.String,
+			},
+			Image: models.ImageRef{
+				SmallURL:  row.ImageSmallUrl.String,
+				MediumURL: row.ImageMediumUrl.String,
+				LargeURL:  row.ImageLargeUrl.String,
+			},
+		})
+	}
+	return issues, true, nil
+}
+
+// SaveVolumeIssues stores the whole issue list of a volume. Details only
+// issue lookups return, such as descriptions, are kept.
+func (s *Storage) SaveVolumeIssues(ctx context.Context, vol *models.ComicVineVolume, issues []models.ComicVineIssue) error {
+	now := time.Now()
+	return s.write(ctx, func(qtx *db.Queries) error {
+		err := qtx.UpsertVolume(ctx, db.UpsertVolumeParams{
+			ID:            int64(vol.ID),
+			Name:          vol.Name,
+			StartYear:     sql.NullString{String: vol.StartYear, Valid: vol.StartYear != ""},
+			PublisherName: sql.NullString{String: vol.Publisher.Name, Valid: vol.Publisher.Name != ""},
+			SiteDetailUrl: sql.NullString{String: vol.SiteDetailURL, Valid: vol.SiteDetailURL != ""},
+			PublisherID:   sql.NullInt64{Int64: int64(vol.Publisher.ID), Valid: vol.Publisher.ID != 0},
+			CountOfIssues: sql.NullInt64{Int64: int64(vol.CountOfIssues), Valid: vol.CountOfIssues != 0},
+		})
+		if err != nil {
+			return fmt.Errorf("storage: save volume %d: %w", vol.ID, err)
+		}
+		for _, issue := range issues {
+			err := qtx.UpsertListedIssue(ctx, db.UpsertListedIssueParams{
+				ID:             int64(issue.ID),
+				VolumeID:       int64(vol.ID),
+				Name:           sql.NullString{String: issue.Name, Valid: issue.Name != ""},
+				IssueNumber:    sql.NullString{String: issue.IssueNumber, Valid: issue.IssueNumber != ""},
+				CoverDate:      sql.NullString{String: issue.CoverDate.String(), Valid: !issue.CoverDate.IsZero()},
+				StoreDate:      sql.NullString{String: issue.StoreDate.String(), Valid: !issue.StoreDate.IsZero()},
+				SiteDetailUrl:  sql.NullString{String: issue.SiteDetailURL, Valid: issue.SiteDetailURL != ""},
+				ImageSmallUrl:  sql.NullString{String: issue.Image.SmallURL, Valid: issue.Image.SmallURL != ""},
+				ImageMediumUrl: sql.NullString{String: issue.Image.MediumURL, Valid: issue.Image.MediumURL != ""},
+				ImageLargeUrl:  sql.NullString{String: issue.Image.LargeURL, Valid: issue.Image.LargeURL != ""},
+			})
+			if err != nil {
+				return fmt.Errorf("storage: save issue %d: %w", issue.ID, err)
+			}
+		}
+		err = qtx.SetVolumeIssuesFetchedAt(ctx, db.SetVolumeIssuesFetchedAtParams{
+			IssuesFetchedAt: sql.NullTime{Time: now, Valid: true},
+			ID:              int64(vol.ID),
+		})
+		if err != nil {
+			return fmt.Errorf("storage: save issue list of volume %d: %w", vol.ID, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"comic-parser/internal/db"
+)
+
+// writeQueueSize is how many writes may wait for the writer before callers
+// block on sending
+const writeQueueSize = 64
+
+// writer applies writes one transaction at a time on a single goroutine.
+// SQLite allows one writer at a time, so batch workers saving results
+// concurrently would otherwise contend for the write lock and fail with
+// "database is locked" once busy_timeout runs out.
+type writer struct {
+	db      *sql.DB
+	q       *db.Queries
+	jobs    chan writeJob
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// writeJob is a write waiting for the writer. The outcome is sent on done.
+type writeJob struct {
+	ctx  context.Context
+	fn   func(qtx *db.Queries) error
+	done chan error
+}
+
+func newWriter(dbConn *sql.DB, q *db.Queries) *writer {
+	w := &writer{
+		db:      dbConn,
+		q:       q,
+		jobs:    make(chan writeJob, writeQueueSize),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *writer) run() {
+	defer close(w.stopped)
+	for job := range w.jobs {
+		job.done <- w.apply(job)
+	}
+}
+
+// apply runs a job's writes in a transaction, skipping jobs whose caller
+// has given up waiting.
+func (w *writer) apply(job writeJob) error {
+	if err := job.ctx.Err(); err != nil {
+		return err
+	}
+	tx, err := w.db.BeginTx(job.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := job.fn(w.q.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// close stops the writer after the queued writes are applied.
+func (w *writer) close() {
+	w.once.Do(func() { close(w.jobs) })
+	<-w.stopped
+}
+
+// write queues fn to run in a transaction on the writer and waits for it to
+// commit. fn must only use qtx; calling back into s from it deadlocks.
+func (s *Storage) write(ctx context.Context, fn func(qtx *db.Queries) error) error {
+	done := make(chan error, 1)
+	select {
+	case s.writer.jobs <- writeJob{ctx: ctx, fn: fn, done: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return <-done
+}
+
+// dsnSeparator returns the character that starts the next DSN parameter.
+func dsnSeparator(dsn string) string {
+	if strings.Contains(dsn, "?") {
+		return "&"
+	}
+	return "?"
+}
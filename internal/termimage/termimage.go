@@ -0,0 +1,304 @@
+// Package termimage draws images in the terminal with the kitty, iTerm2 or
+// sixel graphics protocols, falling back to ASCII art where none of them is
+// available.
+package termimage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // register GIF decoding
+	_ "image/jpeg" // register JPEG decoding
+	"image/png"
+	"os"
+	"strings"
+)
+
+// Protocol is a way of drawing images in a terminal.
+type Protocol string
+
+// Supported protocols
+const (
+	Kitty  Protocol = "kitty"
+	ITerm2 Protocol = "iterm2"
+	Sixel  Protocol = "sixel"
+	ASCII  Protocol = "ascii"
+	Off    Protocol = "off"
+)
+
+const (
+	// cellWidth and cellHeight approximate a terminal cell in pixels, used to
+	// size bitmaps and to keep aspect ratios when fitting images to cells
+	cellWidth  = 10
+	cellHeight = 20
+
+	// kittyChunk is the largest payload kitty accepts per escape sequence
+	kittyChunk = 4096
+
+	// asciiRamp orders characters from dark to bright, for dark backgrounds
+	asciiRamp = " .:-=+*#%@"
+)
+
+// ParseProtocol returns the protocol with the given name.
+func ParseProtocol(name string) (Protocol, error) {
+	switch p := Protocol(strings.ToLower(name)); p {
+	case Kitty, ITerm2, Sixel, ASCII, Off:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown image protocol %q (want kitty, iterm2, sixel, ascii or off)", name)
+}
+
+// Detect guesses the protocol of the current terminal from environment
+// variables looked up with getenv, usually os.Getenv. Terminal multiplexers
+// rarely pass graphics through, so inside tmux or screen it picks ASCII.
+func Detect(getenv func(string) string) Protocol {
+	term := getenv("TERM")
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux"):
+		return ASCII
+	case term == "xterm-kitty" || getenv("KITTY_WINDOW_ID") != "" || program == "ghostty":
+		return Kitty
+	case program == "iTerm.app" || program == "WezTerm":
+		return ITerm2
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return Sixel
+	}
+	return ASCII
+}
+
+// Load decodes the JPEG, PNG or GIF image at path.
+func Load(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// Fit returns the size in cells of img scaled to fit in maxCols by maxRows
+// cells, keeping its aspect ratio.
+func Fit(img image.Image, maxCols, maxRows int) (cols, rows int) {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 || maxCols <= 0 || maxRows <= 0 {
+		return 0, 0
+	}
+	cols = maxCols
+	rows = b.Dy() * cols * cellWidth / (b.Dx() * cellHeight)
+	if rows > maxRows {
+		rows = maxRows
+		cols = b.Dx() * rows * cellHeight / (b.Dy() * cellWidth)
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// Render draws img in cols by rows cells as a block of exactly rows lines,
+// ready to be placed at the start of a line in a bubbletea view. Graphics
+// protocols draw from the last line of the block, moving the cursor up and
+// restoring it afterwards, so the renderer's line bookkeeping stays intact
+// and lines it clears above do not erase the image. Off renders blank lines.
+func Render(img image.Image, p Protocol, cols, rows int) (string, error) {
+	if cols <= 0 || rows <= 0 {
+		return "", nil
+	}
+	var seq string
+	switch p {
+	case Kitty:
+		data, err := encodePNG(scale(img, cols*cellWidth, rows*cellHeight))
+		if err != nil {
+			return "", err
+		}
+		seq = Clear(Kitty) + kitty(data, cols, rows)
+	case ITerm2:
+		data, err := encodePNG(scale(img, cols*cellWidth, rows*cellHeight))
+		if err != nil {
+			return "", err
+		}
+		seq = fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+			len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+	case Sixel:
+		seq = sixel(scale(img, cols*cellWidth, rows*cellHeight))
+	case ASCII:
+		return ascii(scale(img, cols, rows)), nil
+	case Off:
+		return strings.Repeat("\n", rows-1), nil
+	default:
+		return "", fmt.Errorf("unknown image protocol %q", p)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat("\n", rows-1))
+	b.WriteString("\x1b7") // save cursor
+	if rows > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", rows-1)
+	}
+	b.WriteString(seq)
+	b.WriteString("\x1b8") // restore cursor
+	return b.String(), nil
+}
+
+// Clear returns the sequence removing images drawn with p from the screen.
+// Only kitty keeps images apart from the text they were drawn over, so the
+// other protocols need none.
+func Clear(p Protocol) string {
+	if p == Kitty {
+		return "\x1b_Ga=d,q=2\x1b\\"
+	}
+	return ""
+}
+
+// kitty transmits and displays PNG data in chunks, as the protocol requires.
+func kitty(data []byte, cols, rows int) string {
+	payload := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for first := true; first || payload != ""; first = false {
+		chunk := payload[:min(len(payload), kittyChunk)]
+		payload = payload[len(chunk):]
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// sixel encodes img with a 6x6x6 color cube palette, run-length encoding
+// each color's pixels band by band.
+func sixel(img *image.RGBA) string {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	index := make([]int, w*h)
+	used := make(map[int]bool)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			i := cubeLevel(c.R)*36 + cubeLevel(c.G)*6 + cubeLevel(c.B)
+			index[y*w+x] = i
+			used[i] = true
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bPq\"1;1;%d;%d", w, h)
+	for i := 0; i < 216; i++ {
+		if used[i] {
+			fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+
+	bits := make([]byte, w)
+	for band := 0; band < h; band += 6 {
+		first := true
+		for ci := 0; ci < 216; ci++ {
+			if !used[ci] {
+				continue
+			}
+			present := false
+			for x := 0; x < w; x++ {
+				bits[x] = 0
+				for dy := 0; dy < 6 && band+dy < h; dy++ {
+					if index[(band+dy)*w+x] == ci {
+						bits[x] |= 1 << dy
+						present = true
+					}
+				}
+			}
+			if !present {
+				continue
+			}
+			if !first {
+				b.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", ci)
+			writeSixelRuns(&b, bits)
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRuns writes one color's band, compressing repeated columns.
+func writeSixelRuns(b *strings.Builder, bits []byte) {
+	for x := 0; x < len(bits); {
+		run := 1
+		for x+run < len(bits) && bits[x+run] == bits[x] {
+			run++
+		}
+		ch := byte('?' + bits[x])
+		if run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, ch)
+		} else {
+			b.WriteString(strings.Repeat(string(ch), run))
+		}
+		x += run
+	}
+}
+
+// cubeLevel maps an 8-bit channel to one of the color cube's six levels.
+func cubeLevel(v uint8) int {
+	return (int(v)*5 + 127) / 255
+}
+
+// ascii draws one character per pixel of img by brightness.
+func ascii(img *image.RGBA) string {
+	bounds := img.Bounds()
+	lines := make([]string, 0, bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		line := make([]byte, 0, bounds.Dx())
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray := color.GrayModel.Convert(img.RGBAAt(x, y)).(color.Gray)
+			line = append(line, asciiRamp[int(gray.Y)*(len(asciiRamp)-1)/255])
+		}
+		lines = append(lines, string(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scale resizes img to w by h pixels, averaging the source pixels each
+// target pixel covers.
+func scale(img image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	src := img.Bounds()
+	for y := 0; y < h; y++ {
+		y0 := src.Min.Y + y*src.Dy()/h
+		y1 := max(src.Min.Y+(y+1)*src.Dy()/h, y0+1)
+		for x := 0; x < w; x++ {
+			x0 := src.Min.X + x*src.Dx()/w
+			x1 := max(src.Min.X+(x+1)*src.Dx()/w, x0+1)
+
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := img.At(sx, sy).RGBA()
+					r, g, bl, n = r+cr, g+cg, bl+cb, n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: 0xff})
+		}
+	}
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encoding image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
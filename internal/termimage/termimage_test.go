@@ -0,0 +1,154 @@
+package termimage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// gradient is a w by h image going from black on the left to white on the
+// right.
+func gradient(w, h int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x * 255 / (w - 1))})
+		}
+	}
+	return img
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want Protocol
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, Kitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, Kitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ITerm2},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ITerm2},
+		{map[string]string{"TERM": "foot"}, Sixel},
+		{map[string]string{"TERM": "xterm-256color"}, ASCII},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-1000/default,1,0"}, ASCII},
+		{map[string]string{}, ASCII},
+	}
+	for _, tt := range tests {
+		if got := Detect(func(k string) string { return tt.env[k] }); got != tt.want {
+			t.Errorf("Detect(%v) = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	if p, err := ParseProtocol("Kitty"); err != nil || p != Kitty {
+		t.Errorf("ParseProtocol(Kitty) = %q, %v", p, err)
+	}
+	if _, err := ParseProtocol("braille"); err == nil {
+		t.Error("Expected an error for an unknown protocol")
+	}
+}
+
+func TestFit(t *testing.T) {
+	// A portrait cover 2:3 is 20 cells wide and 15 tall at 10x20 cells
+	cols, rows := Fit(gradient(200, 300), 20, 30)
+	if cols != 20 || rows != 15 {
+		t.Errorf("Fit = %dx%d, want 20x15", cols, rows)
+	}
+	// Limited by height, the width shrinks along
+	cols, rows = Fit(gradient(200, 300), 20, 6)
+	if cols != 8 || rows != 6 {
+		t.Errorf("Fit = %dx%d, want 8x6", cols, rows)
+	}
+}
+
+func TestRender_ASCII(t *testing.T) {
+	out, err := Render(gradient(100, 40), ASCII, 10, 2)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	lines := strings.Split(out, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", out)
+	}
+	for _, line := range lines {
+		if len(line) != 10 || line[0] != ' ' || strings.IndexByte(asciiRamp, line[9]) < len(asciiRamp)-2 {
+			t.Errorf("Expected a dark to bright ramp of 10 characters, got %q", line)
+			continue
+		}
+		for i := 1; i < len(line); i++ {
+			if strings.IndexByte(asciiRamp, line[i]) < strings.IndexByte(asciiRamp, line[i-1]) {
+				t.Errorf("Expected brightness to increase to the right, got %q", line)
+				break
+			}
+		}
+	}
+}
+
+func TestRender_Graphics(t *testing.T) {
+	img := gradient(200, 300)
+	tests := []struct {
+		protocol Protocol
+		pattern  string
+	}{
+		{Kitty, `^\x1b_Ga=d,q=2\x1b\\\x1b_Ga=T,f=100,q=2,C=1,c=8,r=6,m=[01];[A-Za-z0-9+/=]+\x1b\\(\x1b_Gm=[01];[A-Za-z0-9+/=]+\x1b\\)*$`},
+		{ITerm2, `^\x1b\]1337;File=inline=1;size=\d+;width=8;height=6;preserveAspectRatio=0:[A-Za-z0-9+/=]+\a$`},
+		{Sixel, `^\x1bPq"1;1;80;120(#\d+;2;\d+;\d+;\d+)+(#\d+[?-~!0-9]+(\$#\d+[?-~!0-9]+)*-){20}\x1b\\$`},
+	}
+	for _, tt := range tests {
+		out, err := Render(img, tt.protocol, 8, 6)
+		if err != nil {
+			t.Fatalf("Render(%s) failed: %v", tt.protocol, err)
+		}
+		// Drawn from the last of 6 lines, moving up 5 lines and back
+		prefix := "\n\n\n\n\n\x1b7\x1b[5A"
+		if !strings.HasPrefix(out, prefix) || !strings.HasSuffix(out, "\x1b8") {
+			t.Fatalf("Render(%s) is not placed as a 6 line block: %q", tt.protocol, out[:min(len(out), 40)])
+		}
+		seq := strings.TrimSuffix(strings.TrimPrefix(out, prefix), "\x1b8")
+		if !regexp.MustCompile(tt.pattern).MatchString(seq) {
+			t.Errorf("Render(%s) produced an unexpected sequence: %q", tt.protocol, seq[:min(len(seq), 200)])
+		}
+	}
+}
+
+func TestKitty_Chunks(t *testing.T) {
+	// 6000 bytes are 8000 base64 characters: a full chunk and a partial one
+	out := kitty(make([]byte, 6000), 8, 6)
+	chunks := regexp.MustCompile(`\x1b_G([^;]*);([^\x1b]*)\x1b\\`).FindAllStringSubmatch(out, -1)
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0][1] != "a=T,f=100,q=2,C=1,c=8,r=6,m=1" || len(chunks[0][2]) != kittyChunk {
+		t.Errorf("Unexpected first chunk: %q with %d characters", chunks[0][1], len(chunks[0][2]))
+	}
+	if chunks[1][1] != "m=0" || len(chunks[1][2]) != 8000-kittyChunk {
+		t.Errorf("Unexpected last chunk: %q with %d characters", chunks[1][1], len(chunks[1][2]))
+	}
+}
+
+func TestRender_KittyPayload(t *testing.T) {
+	out, err := Render(gradient(200, 300), Kitty, 8, 6)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	var payload strings.Builder
+	for _, m := range regexp.MustCompile(`;([A-Za-z0-9+/=]+)\x1b\\`).FindAllStringSubmatch(out, -1) {
+		payload.WriteString(m[1])
+	}
+	data, err := base64.StdEncoding.DecodeString(payload.String())
+	if err != nil {
+		t.Fatalf("Decoding payload: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Payload is not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 80 || b.Dy() != 120 {
+		t.Errorf("Expected an 80x120 bitmap, got %dx%d", b.Dx(), b.Dy())
+	}
+}
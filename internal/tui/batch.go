@@ -0,0 +1,262 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"comic-parser/internal/processor"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// progressWidth is the width of the progress bar in cells
+	progressWidth = 40
+
+	// maxLogLines caps the errors and log lines kept for the log pane
+	maxLogLines = 8
+)
+
+// BatchControl pauses and resumes a running batch; *processor.Processor
+// implements it.
+type BatchControl interface {
+	Pause()
+	Resume()
+}
+
+// BatchEventMsg delivers a processor event to the dashboard.
+type BatchEventMsg processor.Event
+
+// BatchDoneMsg tells the dashboard the batch has returned.
+type BatchDoneMsg struct{}
+
+// LogMsg is a line of log output shown in the log pane.
+type LogMsg string
+
+// BatchModel is a dashboard for a running batch: overall progress, the file
+// each worker is on, counters, recent errors and log output, and an ETA.
+// Pausing stops workers from starting new files; quitting stops the batch
+// after the files in progress so their results are still saved.
+type BatchModel struct {
+	control BatchControl
+	stop    func()
+
+	total   int
+	started time.Time
+	now     time.Time
+
+	processed  int
+	successful int
+	failed     int
+	skipped    int
+
+	workers map[int]string // current file by worker
+	logs    []string
+
+	paused   bool
+	stopping bool
+	done     bool
+}
+
+// tickMsg refreshes the elapsed time and ETA.
+type tickMsg time.Time
+
+// NewBatchModel creates a dashboard for a batch of total files. stop is
+// called when the user quits, and should cancel the batch's context.
+func NewBatchModel(total int, control BatchControl, stop func()) BatchModel {
+	now := time.Now()
+	return BatchModel{
+		control: control,
+		stop:    stop,
+		total:   total,
+		started: now,
+		now:     now,
+		workers: make(map[int]string),
+	}
+}
+
+func (m BatchModel) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m BatchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tickMsg:
+		if m.done {
+			return m, nil
+		}
+		m.now = time.Time(msg)
+		return m, tick()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "p", " ":
+			if m.stopping {
+				break
+			}
+			if m.paused {
+				m.control.Resume()
+			} else {
+				m.control.Pause()
+			}
+			m.paused = !m.paused
+		case "q", "ctrl+c":
+			if m.stopping {
+				break
+			}
+			m.stopping = true
+			m.stop()
+		}
+
+	case BatchEventMsg:
+		m.apply(processor.Event(msg))
+
+	case LogMsg:
+		m.addLog(string(msg))
+
+	case BatchDoneMsg:
+		m.done = true
+		m.now = time.Now()
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// apply updates the counters and worker states from an event. Workers
+// emit concurrently, so a snapshot may arrive after a newer one and the
+// counters only move forward.
+func (m *BatchModel) apply(e processor.Event) {
+	m.processed = max(m.processed, e.Progress.Processed)
+	m.successful = max(m.successful, e.Progress.Successful)
+	m.failed = max(m.failed, e.Progress.Failed)
+	m.skipped = max(m.skipped, e.Progress.Skipped)
+	if e.Progress.Total > 0 {
+		m.total = e.Progress.Total
+	}
+
+	switch e.Type {
+	case processor.EventFileStarted:
+		m.workers[e.Worker] = e.Filename
+	case processor.EventFileFinished:
+		delete(m.workers, e.Worker)
+		switch {
+		case e.Err != nil:
+			m.addLog(fmt.Sprintf("✗ %s: %v", e.Filename, e.Err))
+		case e.Result != nil && !e.Result.Success:
+			m.addLog(fmt.Sprintf("✗ %s: %s", e.Filename, e.Result.Error))
+		}
+	case processor.EventFileSkipped:
+		delete(m.workers, e.Worker)
+	}
+}
+
+func (m *BatchModel) addLog(line string) {
+	line = strings.TrimRight(line, "\n")
+	if line == "" {
+		return
+	}
+	m.logs = append(m.logs, line)
+	if len(m.logs) > maxLogLines {
+		m.logs = m.logs[len(m.logs)-maxLogLines:]
+	}
+}
+
+func (m BatchModel) View() string {
+	var b strings.Builder
+
+	status := "Processing"
+	switch {
+	case m.done:
+		status = "Finished"
+	case m.stopping:
+		status = "Stopping after the files in progress"
+	case m.paused:
+		status = "Paused"
+	}
+	fmt.Fprintf(&b, "%s %d of %d files\n\n", status, m.processed+m.skipped, m.total)
+	fmt.Fprintf(&b, "%s %3.0f%%\n\n", progressBar(m.processed+m.skipped, m.total, progressWidth), percent(m.processed+m.skipped, m.total))
+
+	fmt.Fprintf(&b, "✓ %d  ✗ %d", m.successful, m.failed)
+	if m.skipped > 0 {
+		fmt.Fprintf(&b, "  skipped %d", m.skipped)
+	}
+	elapsed := m.now.Sub(m.started)
+	fmt.Fprintf(&b, "  elapsed %s", elapsed.Round(time.Second))
+	if eta, ok := m.eta(elapsed); ok && !m.done {
+		fmt.Fprintf(&b, "  ETA %s", eta.Round(time.Second))
+	}
+	b.WriteString("\n")
+
+	if len(m.workers) > 0 {
+		b.WriteString("\nWorkers:\n")
+		ids := make([]int, 0, len(m.workers))
+		for id := range m.workers {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			fmt.Fprintf(&b, "  %d: %s\n", id+1, m.workers[id])
+		}
+	}
+
+	if len(m.logs) > 0 {
+		b.WriteString("\nRecent errors and log:\n")
+		for _, line := range m.logs {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	if !m.done {
+		b.WriteString("\n(p)ause/resume, (q)uit and save\n")
+	}
+	return b.String()
+}
+
+// eta extrapolates the remaining time from the pace so far.
+func (m BatchModel) eta(elapsed time.Duration) (time.Duration, bool) {
+	finished := m.processed + m.skipped
+	if m.processed == 0 || finished >= m.total {
+		return 0, false
+	}
+	return elapsed / time.Duration(m.processed) * time.Duration(m.total-finished), true
+}
+
+// progressBar draws done out of total as a bar width cells wide.
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(done*width/total, width)
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+func percent(done, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(done) * 100 / float64(total)
+}
+
+// LogWriter returns an io.Writer for logging.SetOutput that shows each line in
+// the log pane of the dashboard run by program.
+func LogWriter(program *tea.Program) io.Writer {
+	return &logWriter{program: program}
+}
+
+type logWriter struct {
+	program *tea.Program
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.program.Send(LogMsg(line))
+	}
+	return len(p), nil
+}
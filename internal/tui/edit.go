@@ -0,0 +1,130 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"comic-parser/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// EditModel is a form correcting the stored parse of one file. Every key
+// types into the highlighted field; enter saves the changed fields to the
+// audit trail as manual edits and quits.
+type EditModel struct {
+	ctx      context.Context
+	store    *storage.Storage
+	filename string
+	original []string // field values by storage.EditFields
+	values   []string
+	cursor   int
+
+	saved []storage.RecordEdit
+	done  bool
+	err   error
+}
+
+// NewEditModel loads the latest stored parse of filename.
+func NewEditModel(ctx context.Context, store *storage.Storage, filename string) (EditModel, error) {
+	parsed, err := store.LatestParse(ctx, filename)
+	if err != nil {
+		return EditModel{}, err
+	}
+
+	values := make([]string, len(storage.EditFields))
+	for i, field := range storage.EditFields {
+		values[i] = storage.EditFieldValue(parsed, field)
+	}
+	return EditModel{
+		ctx:      ctx,
+		store:    store,
+		filename: filename,
+		original: values,
+		values:   append([]string(nil), values...),
+	}, nil
+}
+
+// Saved returns the edits made, none if the form was cancelled or unchanged.
+func (m EditModel) Saved() []storage.RecordEdit {
+	return m.saved
+}
+
+func (m EditModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m EditModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || m.done {
+		return m, nil
+	}
+
+	value := &m.values[m.cursor]
+	switch key.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.done = true
+		return m, tea.Quit
+	case tea.KeyUp, tea.KeyShiftTab:
+		m.cursor = (m.cursor + len(m.values) - 1) % len(m.values)
+	case tea.KeyDown, tea.KeyTab:
+		m.cursor = (m.cursor + 1) % len(m.values)
+	case tea.KeyBackspace:
+		if r := []rune(*value); len(r) > 0 {
+			*value = string(r[:len(r)-1])
+		}
+	case tea.KeyCtrlU:
+		*value = ""
+	case tea.KeySpace:
+		*value += " "
+	case tea.KeyRunes:
+		*value += string(key.Runes)
+	case tea.KeyEnter:
+		changes := make(map[string]string)
+		for i, field := range storage.EditFields {
+			if m.values[i] != m.original[i] {
+				changes[field] = m.values[i]
+			}
+		}
+		saved, err := m.store.EditRecord(m.ctx, m.filename, changes, storage.EditSourceManual)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.saved = saved
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m EditModel) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Editing %s\n\n", m.filename)
+
+	width := 0
+	for _, field := range storage.EditFields {
+		width = max(width, len(field))
+	}
+	for i, field := range storage.EditFields {
+		pointer, cursor := "  ", ""
+		if i == m.cursor && !m.done {
+			pointer, cursor = "> ", "_"
+		}
+		changed := ""
+		if m.values[i] != m.original[i] {
+			changed = fmt.Sprintf("  (was %q)", m.original[i])
+		}
+		fmt.Fprintf(&b, "%s%-*s  %s%s%s\n", pointer, width, field, m.values[i], cursor, changed)
+	}
+
+	if m.err != nil {
+		fmt.Fprintf(&b, "\nError: %v\n", m.err)
+	}
+	if m.done {
+		return b.String()
+	}
+	b.WriteString("\nType to edit, tab/up/down move, ctrl+u clear, enter save, esc cancel\n")
+	return b.String()
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"comic-parser/internal/covers"
+	"comic-parser/internal/models"
+	"comic-parser/internal/storage"
+	"comic-parser/internal/termimage"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// maxReviewCandidates caps the candidates listed for a queued match
+	maxReviewCandidates = 10
+
+	// coverCols and coverRows bound the cover of the highlighted candidate
+	coverCols = 32
+	coverRows = 16
+)
+
+// ReviewModel steps through the review queue. For each queued match the
+// reviewer confirms the selected candidate, picks another one or rejects
+// the match; decisions are saved as they are made.
+type ReviewModel struct {
+	ctx    context.Context
+	store  *storage.Storage
+	items  []models.ReviewItem
+	index  int
+	cursor int // highlighted candidate
+
+	accepted int
+	rejected int
+	err      error
+
+	covers   *covers.Cache
+	protocol termimage.Protocol
+	rendered map[int]string // cover blocks by issue ID, shared by copies
+
+	width  int
+	height int
+}
+
+// NewReviewModel loads the pending review queue.
+func NewReviewModel(ctx context.Context, store *storage.Storage) (ReviewModel, error) {
+	items, err := store.ListPendingReviews(ctx)
+	if err != nil {
+		return ReviewModel{}, err
+	}
+
+	m := ReviewModel{
+		ctx:   ctx,
+		store: store,
+		items: items,
+	}
+	m.resetCursor()
+	return m, nil
+}
+
+// WithCovers shows the cached cover of the highlighted candidate below the
+// candidate list, drawn with protocol.
+func (m ReviewModel) WithCovers(cache *covers.Cache, protocol termimage.Protocol) ReviewModel {
+	m.covers = cache
+	m.protocol = protocol
+	m.rendered = make(map[int]string)
+	return m
+}
+
+func (m ReviewModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		if m.rendered != nil {
+			// Covers are sized to the terminal
+			m.rendered = make(map[int]string)
+		}
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+		if m.done() {
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.candidates())-1 {
+				m.cursor++
+			}
+		case "a", "enter":
+			if len(m.candidates()) > 0 {
+				m.decide(models.ReviewAccepted)
+			}
+		case "r", "x":
+			m.decide(models.ReviewRejected)
+		case "n", "right", "l":
+			m.advance()
+		}
+	}
+	return m, nil
+}
+
+func (m ReviewModel) View() string {
+	if len(m.items) == 0 {
+		return termimage.Clear(m.protocol) + "The review queue is empty.\n\nPress 'q' to quit."
+	}
+	if m.done() {
+		return termimage.Clear(m.protocol) + fmt.Sprintf("Review finished: %d accepted, %d rejected, %d left for later.\n\nPress 'q' to quit.",
+			m.accepted, m.rejected, len(m.items)-m.accepted-m.rejected)
+	}
+
+	var b strings.Builder
+	item := m.items[m.index]
+	match := item.Result.Match
+
+	fmt.Fprintf(&b, "Review %d of %d\n\n", m.index+1, len(m.items))
+	fmt.Fprintf(&b, "Filename:   %s\n", item.Filename)
+	if match != nil {
+		info := match.ParsedInfo
+		fmt.Fprintf(&b, "Parsed:     %s #%s", info.Title, info.IssueNumber)
+		if info.Year != "" {
+			fmt.Fprintf(&b, " (%s)", info.Year)
+		}
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "Confidence: %s\n", match.MatchConfidence)
+		if match.Reasoning != "" {
+			fmt.Fprintf(&b, "Reasoning:  %s\n", match.Reasoning)
+		}
+	}
+
+	b.WriteString("\n---\n")
+
+	candidates := m.candidates()
+	if len(candidates) == 0 {
+		b.WriteString("No candidates were found.\n")
+	}
+	for i, c := range candidates {
+		pointer := "  "
+		if i == m.cursor {
+			pointer = "> "
+		}
+		selected := ""
+		if match != nil && match.SelectedIssue != nil && match.SelectedIssue.ID == c.ID {
+			selected = "  [selected]"
+		}
+		fmt.Fprintf(&b, "%s%s #%s (%s) [%d]%s\n", pointer, c.Volume.Label(), c.IssueNumber, c.CoverDate, c.ID, selected)
+	}
+	if m.covers != nil && m.protocol != termimage.Off && len(candidates) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.coverView(candidates[m.cursor].ID))
+		b.WriteString("\n")
+	}
+
+	if m.err != nil {
+		fmt.Fprintf(&b, "\nError: %v\n", m.err)
+	}
+
+	b.WriteString("\n(a)ccept highlighted, (r)eject, (n)ext, j/k move, (q)uit\n")
+	return b.String()
+}
+
+// coverView draws the cached cover of an issue, preferring the smallest
+// size as covers are shrunk to a few dozen cells anyway. Drawing is cached
+// since View runs on every update.
+func (m ReviewModel) coverView(issueID int) string {
+	if view, ok := m.rendered[issueID]; ok {
+		return view
+	}
+
+	view := termimage.Clear(m.protocol) + "No cached cover (see `comic-parser covers download`)."
+	for _, size := range covers.Sizes {
+		path, ok := m.covers.Lookup(issueID, size)
+		if !ok {
+			continue
+		}
+		img, err := termimage.Load(path)
+		if err == nil {
+			cols, rows := termimage.Fit(img, coverCols, m.coverRows())
+			view, err = termimage.Render(img, m.protocol, cols, rows)
+		}
+		if err != nil {
+			view = termimage.Clear(m.protocol) + fmt.Sprintf("Cover unavailable: %v", err)
+		}
+		break
+	}
+	m.rendered[issueID] = view
+	return view
+}
+
+// coverRows returns the cover height, shrunk to leave room for the text
+// when the terminal is short.
+func (m ReviewModel) coverRows() int {
+	if m.height == 0 {
+		return coverRows
+	}
+	// Header, parsed info, candidates and help take roughly this many lines
+	text := 12 + len(m.candidates())
+	return max(min(coverRows, m.height-text), 4)
+}
+
+// candidates returns the listed candidates of the current item.
+func (m ReviewModel) candidates() []models.ComicVineIssue {
+	if m.done() {
+		return nil
+	}
+	c := m.items[m.index].Candidates
+	if len(c) > maxReviewCandidates {
+		c = c[:maxReviewCandidates]
+	}
+	return c
+}
+
+func (m ReviewModel) done() bool {
+	return m.index >= len(m.items)
+}
+
+// decide applies the decision to the current item, saves it and moves on.
+// On a save error the item stays current so the reviewer can retry.
+func (m *ReviewModel) decide(status string) {
+	item := m.items[m.index]
+	result := *item.Result
+	if status == models.ReviewAccepted {
+		result.Match = models.AcceptCandidate(result.Match, m.candidates()[m.cursor])
+	} else {
+		result.Match = models.RejectMatch(result.Match)
+	}
+	item.Result = &result
+
+	if err := m.store.ResolveReview(m.ctx, &item, status); err != nil {
+		m.err = err
+		return
+	}
+	m.items[m.index] = item
+	if status == models.ReviewAccepted {
+		m.accepted++
+	} else {
+		m.rejected++
+	}
+	m.advance()
+}
+
+// advance moves to the next item, leaving the current one pending.
+func (m *ReviewModel) advance() {
+	m.index++
+	m.err = nil
+	m.resetCursor()
+}
+
+// resetCursor highlights the candidate the current match selected.
+func (m *ReviewModel) resetCursor() {
+	m.cursor = 0
+	if m.done() {
+		return
+	}
+	match := m.items[m.index].Result.Match
+	if match == nil || match.SelectedIssue == nil {
+		return
+	}
+	for i, c := range m.candidates() {
+		if c.ID == match.SelectedIssue.ID {
+			m.cursor = i
+			return
+		}
+	}
+}
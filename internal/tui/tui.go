@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"comic-parser/internal/comicvine"
 	"comic-parser/internal/models"
+	"comic-parser/internal/provider"
 	"comic-parser/internal/storage"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -17,29 +19,47 @@ const maxSearchResults = 5
 type Model struct {
 	ctx      context.Context
 	store    *storage.Storage
-	cvClient *comicvine.Client
-	items    []*models.ParsedFilename
+	provider provider.MetadataProvider
+	source   string // provider name shown in the UI
+	all      []*models.ParsedFilename
+	items    []*models.ParsedFilename // all, or the ones matching filter
 	index    int
 
+	filter    string // stored-comic search narrowing items
+	typing    bool   // the filter box has focus
+	filterErr error
+
 	searchResults []models.ComicVineIssue
 	searching     bool
 	searchErr     error
+	cursor        int    // highlighted search result
+	selected      string // description of the match saved for the item
+	selectErr     error
+	ignored       bool // the item was added to the ignore list
+	ignoreErr     error
 
 	width  int
 	height int
 }
 
-func NewModel(ctx context.Context, store *storage.Storage, cvClient *comicvine.Client) (Model, error) {
+func NewModel(ctx context.Context, store *storage.Storage, metaProvider provider.MetadataProvider) (Model, error) {
 	// Load items initially
 	items, err := store.ListParsedFilenames(context.Background())
 	if err != nil {
 		return Model{}, err
 	}
 
+	source := provider.Default
+	if metaProvider != nil {
+		source = metaProvider.Name()
+	}
+
 	return Model{
 		ctx:      ctx,
 		store:    store,
-		cvClient: cvClient,
+		provider: metaProvider,
+		source:   source,
+		all:      items,
 		items:    items,
 		index:    0,
 	}, nil
@@ -49,6 +69,23 @@ func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+type filterMsg struct {
+	query string
+	hits  []models.SearchHit
+	err   error
+}
+
+type selectMsg struct {
+	id    string
+	issue models.ComicVineIssue
+	err   error
+}
+
+type ignoreMsg struct {
+	id  string
+	err error
+}
+
 type searchMsg struct {
 	id      string
 	results []models.ComicVineIssue
@@ -62,26 +99,68 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 	case tea.KeyMsg:
+		if m.typing {
+			return m.updateFilter(msg)
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case "/":
+			m.typing = true
+		case "esc":
+			if m.filter != "" {
+				m.filter = ""
+				m.filterErr = nil
+				m.setItems(m.all)
+			}
 		case "n", "right", "l":
 			m.navigate(1)
 		case "p", "left", "h":
 			m.navigate(-1)
-		case "s", "enter": // Search
-			if !m.searching && len(m.items) > 0 {
-				m.searching = true
-				m.searchResults = nil
-				m.searchErr = nil
-				item := m.items[m.index]
-				return m, func() tea.Msg {
-					results, err := m.cvClient.SearchIssues(m.ctx, item.Title, item.IssueNumber)
-					return searchMsg{id: item.OriginalFilename, results: results, err: err}
-				}
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < min(len(m.searchResults), maxSearchResults)-1 {
+				m.cursor++
 			}
+		case "enter":
+			var cmd tea.Cmd
+			if len(m.searchResults) > 0 && !m.searching {
+				cmd = m.selectResult()
+			} else {
+				cmd = m.search()
+			}
+			return m, cmd
+		case "s":
+			cmd := m.search()
+			return m, cmd
+		case "i":
+			cmd := m.ignore()
+			return m, cmd
 		}
 
+	case filterMsg:
+		if msg.query != m.filter {
+			break
+		}
+		m.filterErr = msg.err
+		if msg.err != nil {
+			break
+		}
+		byName := make(map[string]*models.ParsedFilename, len(m.all))
+		for _, item := range m.all {
+			byName[item.OriginalFilename] = item
+		}
+		var items []*models.ParsedFilename
+		for _, hit := range msg.hits {
+			if item, ok := byName[hit.Filename]; ok {
+				items = append(items, item)
+			}
+		}
+		m.setItems(items)
+
 	case searchMsg:
 		if m.index < len(m.items) && m.items[m.index].OriginalFilename == msg.id {
 			m.searching = false
@@ -89,23 +168,139 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.searchErr = msg.err
 			} else {
 				m.searchResults = msg.results
+				m.cursor = 0
+			}
+		}
+
+	case ignoreMsg:
+		if m.index < len(m.items) && m.items[m.index].OriginalFilename == msg.id {
+			m.ignoreErr = msg.err
+			m.ignored = msg.err == nil
+		}
+
+	case selectMsg:
+		if m.index < len(m.items) && m.items[m.index].OriginalFilename == msg.id {
+			m.selectErr = msg.err
+			if msg.err == nil {
+				m.selected = fmt.Sprintf("%s #%s [%d]", msg.issue.Volume.Name, msg.issue.IssueNumber, msg.issue.ID)
 			}
 		}
 	}
 	return m, nil
 }
 
-func (m Model) View() string {
+// search looks the current item up with the metadata provider.
+func (m *Model) search() tea.Cmd {
+	if m.searching || len(m.items) == 0 {
+		return nil
+	}
+	m.searching = true
+	m.searchResults = nil
+	m.searchErr = nil
+	item := m.items[m.index]
+	return func() tea.Msg {
+		ctx := comicvine.WithVolumeHint(m.ctx, comicvine.VolumeHint{Year: item.Year, Publisher: item.Publisher})
+		results, err := m.provider.SearchIssues(ctx, item.Title, item.IssueNumber)
+		return searchMsg{id: item.OriginalFilename, results: results, err: err}
+	}
+}
+
+// selectResult stores the highlighted search result as the match of the
+// current item, replacing any match stored for its file before.
+func (m *Model) selectResult() tea.Cmd {
+	item := *m.items[m.index]
+	issue := m.searchResults[m.cursor]
+	m.selectErr = nil
+	return func() tea.Msg {
+		result := &models.ProcessingResult{
+			Filename: item.OriginalFilename,
+			Path:     item.Path,
+			Success:  true,
+			Match: &models.MatchResult{
+				OriginalFilename: item.OriginalFilename,
+				ParsedInfo:       item,
+				SelectedIssue:    &issue,
+				MatchConfidence:  "high",
+				Reasoning:        "Selected in the TUI",
+				ComicVineID:      issue.ID,
+				ComicVineURL:     issue.SiteDetailURL,
+			},
+			ProcessedAt: time.Now(),
+		}
+		err := m.store.SaveResult(m.ctx, result)
+		return selectMsg{id: item.OriginalFilename, issue: issue, err: err}
+	}
+}
+
+// ignore adds the current item's file to the ignore list, so later scans
+// and batches skip it and its failures stop counting.
+func (m *Model) ignore() tea.Cmd {
 	if len(m.items) == 0 {
-		return "No items found in database.\n\nPress 'q' to quit."
+		return nil
 	}
+	filename := m.items[m.index].OriginalFilename
+	m.ignoreErr = nil
+	return func() tea.Msg {
+		return ignoreMsg{id: filename, err: m.store.AddIgnoreRule(m.ctx, filename)}
+	}
+}
+
+// updateFilter handles keys while the filter box has focus. Enter searches
+// the stored comics and narrows the items to the hits; an empty query
+// shows every item again.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.typing = false
+	case tea.KeyBackspace:
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.filter += " "
+	case tea.KeyRunes:
+		m.filter += string(msg.Runes)
+	case tea.KeyEnter:
+		m.typing = false
+		m.filterErr = nil
+		query := m.filter
+		if strings.TrimSpace(query) == "" {
+			m.filter = ""
+			m.setItems(m.all)
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			hits, err := m.store.Search(m.ctx, query, 0)
+			return filterMsg{query: query, hits: hits, err: err}
+		}
+	}
+	return m, nil
+}
 
+func (m Model) View() string {
 	var b strings.Builder
 
+	if len(m.items) == 0 {
+		if m.filter != "" {
+			fmt.Fprintf(&b, "No stored comics match %q.\n", m.filter)
+		} else {
+			b.WriteString("No items found in database.\n")
+		}
+		b.WriteString(m.filterView())
+		b.WriteString("\nPress '/' to search, 'esc' to clear, 'q' to quit.")
+		return b.String()
+	}
+
 	item := m.items[m.index]
 
 	// 3. Write directly to the builder using Fprintf
-	fmt.Fprintf(&b, "Item %d of %d\n\n", m.index+1, len(m.items))
+	fmt.Fprintf(&b, "Item %d of %d", m.index+1, len(m.items))
+	if m.filter != "" && !m.typing {
+		fmt.Fprintf(&b, " matching %q", m.filter)
+	}
+	b.WriteString("\n\n")
 	fmt.Fprintf(&b, "Filename: %s\n", item.OriginalFilename)
 	fmt.Fprintf(&b, "Title:    %s\n", item.Title)
 	fmt.Fprintf(&b, "Issue:    %s\n", item.IssueNumber)
@@ -119,7 +314,7 @@ func (m Model) View() string {
 	b.WriteString("\n---\n")
 
 	if m.searching {
-		b.WriteString("Searching ComicVine...\n")
+		fmt.Fprintf(&b, "Searching %s...\n", m.source)
 	} else if m.searchErr != nil {
 		fmt.Fprintf(&b, "Error: %v\n", m.searchErr)
 	} else if len(m.searchResults) > 0 {
@@ -129,24 +324,71 @@ func (m Model) View() string {
 				fmt.Fprintf(&b, "... and %d more\n", len(m.searchResults)-maxSearchResults)
 				break
 			}
-			fmt.Fprintf(&b, "- %s #%s (%s) [%d]\n", res.Volume.Name, res.IssueNumber, res.CoverDate, res.ID)
+			pointer := "  "
+			if i == m.cursor {
+				pointer = "> "
+			}
+			fmt.Fprintf(&b, "%s%s #%s (%s) [%d]\n", pointer, res.Volume.Label(), res.IssueNumber, res.CoverDate, res.ID)
 		}
 	} else if m.searchResults != nil {
-		b.WriteString("No matches found on ComicVine.\n")
+		fmt.Fprintf(&b, "No matches found on %s.\n", m.source)
 	} else {
-		b.WriteString("Press 's' or 'enter' to search ComicVine.\n")
+		fmt.Fprintf(&b, "Press 's' or 'enter' to search %s.\n", m.source)
 	}
 
-	b.WriteString("\n(n)ext, (p)rev, (s)earch, (q)uit\n")
+	if m.selectErr != nil {
+		fmt.Fprintf(&b, "\nSaving the match failed: %v\n", m.selectErr)
+	} else if m.selected != "" {
+		fmt.Fprintf(&b, "\nMatched to %s\n", m.selected)
+	}
+	if m.ignoreErr != nil {
+		fmt.Fprintf(&b, "\nIgnoring the file failed: %v\n", m.ignoreErr)
+	} else if m.ignored {
+		b.WriteString("\nIgnored: scans and batches skip this file from now on\n")
+	}
+
+	b.WriteString(m.filterView())
+	b.WriteString("\n(n)ext, (p)rev, (s)earch, j/k move, enter select, (i)gnore, (/) find stored, (q)uit\n")
 
 	return b.String()
 }
 
+// filterView renders the filter box while it has focus and any error of
+// the last stored-comic search.
+func (m Model) filterView() string {
+	switch {
+	case m.typing:
+		return fmt.Sprintf("\nFind: %s_\n", m.filter)
+	case m.filterErr != nil:
+		return fmt.Sprintf("\nSearch failed: %v\n", m.filterErr)
+	}
+	return ""
+}
+
+// setItems replaces the items shown and starts again from the first one.
+func (m *Model) setItems(items []*models.ParsedFilename) {
+	m.items = items
+	m.index = 0
+	m.searching = false
+	m.searchResults = nil
+	m.searchErr = nil
+	m.cursor = 0
+	m.selected = ""
+	m.selectErr = nil
+	m.ignored = false
+	m.ignoreErr = nil
+}
+
 func (m *Model) navigate(offset int) {
 	newIndex := m.index + offset
 	if newIndex >= 0 && newIndex < len(m.items) {
 		m.index = newIndex
 		m.searchResults = nil
 		m.searchErr = nil
+		m.cursor = 0
+		m.selected = ""
+		m.selectErr = nil
+		m.ignored = false
+		m.ignoreErr = nil
 	}
 }
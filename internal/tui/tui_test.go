@@ -1,17 +1,24 @@
 package tui
 
 import (
+	"bytes"
 	"context"
+	"image"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"comic-parser/internal/comicvine"
 	"comic-parser/internal/config"
+	"comic-parser/internal/covers"
 	"comic-parser/internal/models"
+	"comic-parser/internal/processor"
 	"comic-parser/internal/storage"
+	"comic-parser/internal/termimage"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -197,3 +204,402 @@ func TestModel_View(t *testing.T) {
 		t.Error("View output missing title")
 	}
 }
+
+func TestModel_SelectResult(t *testing.T) {
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.SaveParsedFilename(ctx, &models.ParsedFilename{
+		OriginalFilename: "Saga 001.cbz",
+		Title:            "Saga",
+		IssueNumber:      "1",
+		Confidence:       "high",
+	}, "test"); err != nil {
+		t.Fatalf("Failed to save parsed filename: %v", err)
+	}
+
+	model, err := NewModel(ctx, store, nil)
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+	updated, _ := model.Update(searchMsg{id: "Saga 001.cbz", results: []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga Deluxe"}},
+		{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga"}, SiteDetailURL: "https://comicvine.example/2"},
+	}})
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if !strings.Contains(updated.View(), "> Saga #1") {
+		t.Errorf("Expected the second result highlighted, got:\n%s", updated.View())
+	}
+
+	updated, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command saving the selection")
+	}
+	updated, _ = updated.Update(cmd())
+	if fm := updated.(Model); fm.selectErr != nil {
+		t.Fatalf("Saving the selection failed: %v", fm.selectErr)
+	}
+	if !strings.Contains(updated.View(), "Matched to Saga #1 [2]") {
+		t.Errorf("Expected the saved match in the view, got:\n%s", updated.View())
+	}
+
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list matched results: %v", err)
+	}
+	if len(matched) != 1 || matched[0].Filename != "Saga 001.cbz" || matched[0].Match.ComicVineID != 2 {
+		t.Fatalf("Expected Saga 001.cbz matched to issue 2, got %+v", matched)
+	}
+	if matched[0].Match.MatchConfidence != "high" {
+		t.Errorf("Expected a high confidence match, got %s", matched[0].Match.MatchConfidence)
+	}
+}
+
+func TestModel_Ignore(t *testing.T) {
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.SaveParsedFilename(ctx, &models.ParsedFilename{OriginalFilename: "Saga 001.sample.cbz", Title: "Saga"}, "test"); err != nil {
+		t.Fatalf("Failed to save parsed filename: %v", err)
+	}
+
+	model, err := NewModel(ctx, store, nil)
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if cmd == nil {
+		t.Fatal("Expected a command adding the file to the ignore list")
+	}
+	updated, _ = updated.Update(cmd())
+	if !strings.Contains(updated.View(), "Ignored: scans and batches skip this file") {
+		t.Errorf("Expected the file shown as ignored, got:\n%s", updated.View())
+	}
+
+	rules, err := store.ListIgnoreRules(ctx)
+	if err != nil {
+		t.Fatalf("ListIgnoreRules failed: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Pattern != "Saga 001.sample.cbz" {
+		t.Errorf("Expected the file on the ignore list, got %+v", rules)
+	}
+}
+
+func TestModel_Filter(t *testing.T) {
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	for _, item := range []*models.ParsedFilename{
+		{OriginalFilename: "saga_001.cbz", Title: "Saga", IssueNumber: "1"},
+		{OriginalFilename: "batman_001.cbz", Title: "Batman", IssueNumber: "1"},
+		{OriginalFilename: "batman_002.cbz", Title: "Batman", IssueNumber: "2"},
+	} {
+		if err := store.SaveParsedFilename(context.Background(), item, "test"); err != nil {
+			t.Fatalf("Failed to save parsed filename: %v", err)
+		}
+	}
+
+	model, err := NewModel(context.Background(), store, nil)
+	if err != nil {
+		t.Fatalf("Failed to create new model: %v", err)
+	}
+
+	var m tea.Model = model
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		return cmd
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("batm")})
+	if view := m.View(); !strings.Contains(view, "Find: batm_") {
+		t.Errorf("Expected the filter box in the view, got:\n%s", view)
+	}
+	cmd := press(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command after enter")
+	}
+	m, _ = m.Update(cmd())
+
+	fm := m.(Model)
+	if fm.typing || fm.filterErr != nil {
+		t.Fatalf("Unexpected filter state: typing=%v err=%v", fm.typing, fm.filterErr)
+	}
+	if len(fm.items) != 2 || !strings.HasPrefix(fm.items[0].OriginalFilename, "batman") ||
+		!strings.HasPrefix(fm.items[1].OriginalFilename, "batman") {
+		t.Errorf("Expected the two batman items, got %d items", len(fm.items))
+	}
+	if view := m.View(); !strings.Contains(view, `Item 1 of 2 matching "batm"`) {
+		t.Errorf("Expected the filter in the view, got:\n%s", view)
+	}
+
+	// A query without hits leaves nothing to show
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	for range "batm" {
+		press(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	m, _ = m.Update(press(tea.KeyMsg{Type: tea.KeyEnter})())
+	if view := m.View(); !strings.Contains(view, `No stored comics match "zzz"`) {
+		t.Errorf("Expected the no-match message, got:\n%s", view)
+	}
+
+	// Escape clears the filter
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if fm := m.(Model); fm.filter != "" || len(fm.items) != 3 {
+		t.Errorf("Expected all 3 items after clearing the filter, got %d (filter %q)", len(fm.items), fm.filter)
+	}
+}
+
+func TestReviewModel(t *testing.T) {
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "review.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}},
+		{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}},
+	}
+	for _, name := range []string{"a.cbz", "b.cbz", "c.cbz"} {
+		result := &models.ProcessingResult{
+			Filename: name,
+			Success:  true,
+			Match: &models.MatchResult{
+				ParsedInfo:      models.ParsedFilename{OriginalFilename: name, Title: "Saga", IssueNumber: "1"},
+				SelectedIssue:   &candidates[1],
+				ComicVineID:     2,
+				MatchConfidence: "medium",
+				Reasoning:       "Title is close",
+			},
+		}
+		if err := store.QueueReview(ctx, result, candidates); err != nil {
+			t.Fatalf("Failed to queue review: %v", err)
+		}
+	}
+
+	model, err := NewReviewModel(ctx, store)
+	if err != nil {
+		t.Fatalf("NewReviewModel failed: %v", err)
+	}
+	if model.cursor != 1 {
+		t.Errorf("Expected the cursor on the selected candidate, got %d", model.cursor)
+	}
+	if view := model.View(); !strings.Contains(view, "Review 1 of 3") || !strings.Contains(view, "[selected]") {
+		t.Errorf("Unexpected view:\n%s", view)
+	}
+
+	press := func(m ReviewModel, key string) ReviewModel {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		return updated.(ReviewModel)
+	}
+
+	// Confirm a.cbz, pick the other candidate for b.cbz, reject c.cbz
+	model = press(model, "a")
+	model = press(model, "k")
+	model = press(model, "a")
+	model = press(model, "r")
+	if model.err != nil {
+		t.Fatalf("Unexpected error: %v", model.err)
+	}
+	if model.accepted != 2 || model.rejected != 1 || !model.done() {
+		t.Errorf("Expected 2 accepted and 1 rejected, got %d and %d", model.accepted, model.rejected)
+	}
+
+	if pending, _ := store.ListPendingReviews(ctx); len(pending) != 0 {
+		t.Errorf("Expected no pending reviews, got %d", len(pending))
+	}
+	matched, err := store.ListMatchedResults(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list matched results: %v", err)
+	}
+	got := map[string]int{}
+	for _, r := range matched {
+		got[r.Filename] = r.Match.ComicVineID
+		if r.Match.MatchConfidence != "high" {
+			t.Errorf("Expected %s accepted with high confidence, got %s", r.Filename, r.Match.MatchConfidence)
+		}
+	}
+	if len(got) != 2 || got["a.cbz"] != 2 || got["b.cbz"] != 1 {
+		t.Errorf("Unexpected stored matches %v", got)
+	}
+}
+
+func TestEditModel(t *testing.T) {
+	store, err := storage.NewStorage(filepath.Join(t.TempDir(), "edit.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	item := &models.ParsedFilename{OriginalFilename: "Sgaa 02.cbz", Title: "Sgaa", IssueNumber: "02", Confidence: "low"}
+	if err := store.SaveParsedFilename(ctx, item, "regex"); err != nil {
+		t.Fatalf("Failed to save parsed filename: %v", err)
+	}
+
+	model, err := NewEditModel(ctx, store, "Sgaa 02.cbz")
+	if err != nil {
+		t.Fatalf("NewEditModel failed: %v", err)
+	}
+	update := func(msg tea.KeyMsg) {
+		t.Helper()
+		updated, _ := model.Update(msg)
+		model = updated.(EditModel)
+	}
+
+	// Retype the series, then set the year two fields down
+	update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Saga")})
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	update(tea.KeyMsg{Type: tea.KeyTab})
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2012")})
+	if view := model.View(); !strings.Contains(view, `(was "Sgaa")`) {
+		t.Errorf("Expected the view to show the old series, got:\n%s", view)
+	}
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.err != nil {
+		t.Fatalf("Unexpected error: %v", model.err)
+	}
+
+	if saved := model.Saved(); len(saved) != 2 || saved[0].Field != storage.EditSeries || saved[1].Field != storage.EditYear {
+		t.Errorf("Unexpected edits %+v", saved)
+	}
+	parsed, err := store.LatestParse(ctx, "Sgaa 02.cbz")
+	if err != nil {
+		t.Fatalf("LatestParse failed: %v", err)
+	}
+	if parsed.Title != "Saga" || parsed.Year != "2012" || parsed.IssueNumber != "02" {
+		t.Errorf("Unexpected parse after editing: %+v", parsed)
+	}
+	edits, _ := store.ListRecordEdits(ctx, "Sgaa 02.cbz")
+	if len(edits) != 2 || edits[0].Source != storage.EditSourceManual {
+		t.Errorf("Expected 2 manual edits in the audit trail, got %+v", edits)
+	}
+}
+
+func TestReviewModel_Covers(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewStorage(filepath.Join(dir, "review.db"))
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	candidates := []models.ComicVineIssue{
+		{ID: 1, IssueNumber: "1", Volume: models.VolumeRef{ID: 10, Name: "Saga"}},
+		{ID: 2, IssueNumber: "1", Volume: models.VolumeRef{ID: 20, Name: "Saga Deluxe"}},
+	}
+	result := &models.ProcessingResult{
+		Filename: "a.cbz",
+		Success:  true,
+		Match: &models.MatchResult{
+			ParsedInfo:      models.ParsedFilename{OriginalFilename: "a.cbz", Title: "Saga", IssueNumber: "1"},
+			SelectedIssue:   &candidates[0],
+			ComicVineID:     1,
+			MatchConfidence: "medium",
+		},
+	}
+	if err := store.QueueReview(ctx, result, candidates); err != nil {
+		t.Fatalf("Failed to queue review: %v", err)
+	}
+
+	// A white cover for issue 1 only
+	cover := image.NewGray(image.Rect(0, 0, 40, 60))
+	for i := range cover.Pix {
+		cover.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, cover); err != nil {
+		t.Fatalf("Failed to encode cover: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "covers", "1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "covers", "1", "small.png"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := NewReviewModel(ctx, store)
+	if err != nil {
+		t.Fatalf("NewReviewModel failed: %v", err)
+	}
+	model = model.WithCovers(covers.NewCache(dir, http.DefaultClient), termimage.ASCII)
+
+	if view := model.View(); !strings.Contains(view, "@@@@@@@@@@") {
+		t.Errorf("Expected the cover drawn in ASCII, got:\n%s", view)
+	}
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if view := updated.View(); !strings.Contains(view, "No cached cover") || strings.Contains(view, "@@@") {
+		t.Errorf("Expected no cover for the second candidate, got:\n%s", view)
+	}
+}
+
+type fakeControl struct {
+	paused bool
+}
+
+func (c *fakeControl) Pause()  { c.paused = true }
+func (c *fakeControl) Resume() { c.paused = false }
+
+func TestBatchModel(t *testing.T) {
+	control := &fakeControl{}
+	stopped := false
+	var m tea.Model = NewBatchModel(4, control, func() { stopped = true })
+
+	send := func(msg tea.Msg) tea.Cmd {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		return cmd
+	}
+	send(BatchEventMsg{Type: processor.EventFileStarted, Worker: 0, Filename: "a.cbz", Progress: models.BatchProgress{Total: 4}})
+	send(BatchEventMsg{Type: processor.EventFileStarted, Worker: 1, Filename: "b.cbz", Progress: models.BatchProgress{Total: 4}})
+	send(BatchEventMsg{Type: processor.EventFileFinished, Worker: 1, Filename: "b.cbz",
+		Result:   &models.ProcessingResult{Filename: "b.cbz", Error: "searching comicvine: timeout"},
+		Progress: models.BatchProgress{Total: 4, Processed: 1, Failed: 1}})
+	// A snapshot taken before the last one must not move the counters back
+	send(BatchEventMsg{Type: processor.EventFileStarted, Worker: 1, Filename: "c.cbz", Progress: models.BatchProgress{Total: 4}})
+	send(LogMsg("rate limited, waiting"))
+
+	view := m.View()
+	for _, want := range []string{"Processing 1 of 4 files", "25%", "✓ 0  ✗ 1", "1: a.cbz", "2: c.cbz", "✗ b.cbz: searching comicvine: timeout", "rate limited, waiting"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in view:\n%s", want, view)
+		}
+	}
+
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if !control.paused || !strings.Contains(m.View(), "Paused") {
+		t.Errorf("Expected the batch paused, got:\n%s", m.View())
+	}
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if control.paused {
+		t.Error("Expected the batch resumed")
+	}
+
+	// Quitting stops the batch but waits for it to return
+	if cmd := send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd != nil {
+		t.Error("Expected the dashboard to keep running until the batch returns")
+	}
+	if !stopped || !strings.Contains(m.View(), "Stopping") {
+		t.Errorf("Expected the batch stopped, got:\n%s", m.View())
+	}
+	if cmd := send(BatchDoneMsg{}); cmd == nil {
+		t.Error("Expected the dashboard to quit once the batch returned")
+	}
+}
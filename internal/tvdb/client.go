@@ -0,0 +1,346 @@
+// Package tvdb provides a client for TheTVDB v4 API, used to match TV
+// episode files the way the comicvine package matches comics.
+package tvdb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+const (
+	userAgentValue  = "ComicParser/1.0"
+	headerUserAgent = "User-Agent"
+
+	// maxSeriesToCheck limits how many matching series are searched for the
+	// episode
+	maxSeriesToCheck = 3
+)
+
+var (
+	// ErrNotFound is returned when TheTVDB has no such resource.
+	ErrNotFound = errors.New("tvdb: not found")
+
+	// ErrUnauthorized is returned when TheTVDB rejects the API key or PIN.
+	ErrUnauthorized = errors.New("tvdb: invalid API key or PIN")
+)
+
+// HTTPClient defines the interface for making HTTP requests
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is a TheTVDB API client. It logs in with the API key on the first
+// request and again whenever the token expires.
+type Client struct {
+	apiKey     string
+	pin        string
+	baseURL    string
+	httpClient HTTPClient
+
+	tokenMutex sync.Mutex
+	token      string
+}
+
+// NewClient creates a new TheTVDB API client for the configured API key.
+func NewClient(cfg *config.Config, httpClient HTTPClient) *Client {
+	return &Client{
+		apiKey:     cfg.TVDBAPIKey,
+		pin:        cfg.TVDBPIN,
+		baseURL:    strings.TrimSuffix(cfg.TVDBAPIBaseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// API response shapes
+type (
+	response[T any] struct {
+		Status string `json:"status"`
+		Data   T      `json:"data"`
+		Links  struct {
+			Next *string `json:"next"`
+		} `json:"links"`
+	}
+
+	searchResult struct {
+		TVDBID  string   `json:"tvdb_id"`
+		Name    string   `json:"name"`
+		Year    string   `json:"year"`
+		Aliases []string `json:"aliases"`
+	}
+
+	seriesEpisodes struct {
+		Series struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Year string `json:"year"`
+		} `json:"series"`
+		Episodes []episode `json:"episodes"`
+	}
+
+	episode struct {
+		ID             int    `json:"id"`
+		SeriesID       int    `json:"seriesId"`
+		Name           string `json:"name"`
+		Aired          string `json:"aired"`
+		SeasonNumber   int    `json:"seasonNumber"`
+		Number         int    `json:"number"`
+		AbsoluteNumber int    `json:"absoluteNumber"`
+	}
+)
+
+// Series is a TheTVDB series found by a search.
+type Series struct {
+	ID      int
+	Name    string
+	Year    string
+	Aliases []string
+}
+
+// SearchSeries searches series by name, limited to series from year when
+// it is not 0.
+func (c *Client) SearchSeries(ctx context.Context, name string, year int) ([]Series, error) {
+	params := url.Values{}
+	params.Set("query", name)
+	params.Set("type", "series")
+	if year > 0 {
+		params.Set("year", strconv.Itoa(year))
+	}
+
+	var result response[[]searchResult]
+	err := c.get(ctx, "/search", params, &result)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("searching series: %w", err)
+	}
+
+	series := make([]Series, 0, len(result.Data))
+	for _, r := range result.Data {
+		id, err := strconv.Atoi(r.TVDBID)
+		if err != nil {
+			continue
+		}
+		series = append(series, Series{ID: id, Name: r.Name, Year: r.Year, Aliases: r.Aliases})
+	}
+	return series, nil
+}
+
+// FindEpisode returns the episode of a series that parsed refers to by
+// season and episode, air date or absolute number, or nil if the series has
+// no such episode.
+func (c *Client) FindEpisode(ctx context.Context, seriesID int, parsed *models.ParsedEpisode) (*models.TVEpisode, error) {
+	params := url.Values{}
+	switch {
+	case parsed.AirDate != "":
+		params.Set("airDate", parsed.AirDate)
+	case parsed.AbsoluteNumber == 0:
+		params.Set("season", strconv.Itoa(parsed.Season))
+		params.Set("episodeNumber", strconv.Itoa(parsed.Episode))
+	}
+
+	// Absolute numbers can't be filtered by the API, so anime series are
+	// paged through
+	for page := 0; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+		var result response[seriesEpisodes]
+		err := c.get(ctx, fmt.Sprintf("/series/%d/episodes/default", seriesID), params, &result)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("listing episodes of series %d: %w", seriesID, err)
+		}
+
+		for _, e := range result.Data.Episodes {
+			if !episodeMatches(e, parsed) {
+				continue
+			}
+			return &models.TVEpisode{
+				ID:             e.ID,
+				SeriesID:       result.Data.Series.ID,
+				SeriesName:     result.Data.Series.Name,
+				SeriesYear:     result.Data.Series.Year,
+				Season:         e.SeasonNumber,
+				Number:         e.Number,
+				AbsoluteNumber: e.AbsoluteNumber,
+				Name:           e.Name,
+				Aired:          e.Aired,
+			}, nil
+		}
+		if result.Links.Next == nil || *result.Links.Next == "" || len(result.Data.Episodes) == 0 {
+			return nil, nil
+		}
+	}
+}
+
+// SelectEpisode finds the episode parsed refers to in the best few of the
+// series a search returned. The confidence is high when the series name
+// matches the parsed one, medium otherwise. It returns a nil episode when
+// none of the series has it.
+func (c *Client) SelectEpisode(ctx context.Context, series []Series, parsed *models.ParsedEpisode) (*models.TVEpisode, string, error) {
+	if len(series) > maxSeriesToCheck {
+		series = series[:maxSeriesToCheck]
+	}
+
+	for _, s := range series {
+		ep, err := c.FindEpisode(ctx, s.ID, parsed)
+		if err != nil {
+			return nil, "", err
+		}
+		if ep == nil {
+			continue
+		}
+		confidence := "medium"
+		if s.named(parsed.Series) {
+			confidence = "high"
+		}
+		return ep, confidence, nil
+	}
+	return nil, "", nil
+}
+
+// named reports whether name is the series' name or one of its aliases,
+// ignoring case, punctuation and a year suffix.
+func (s Series) named(name string) bool {
+	want := normalizeName(name)
+	for _, candidate := range append([]string{s.Name}, s.Aliases...) {
+		if normalizeName(candidate) == want {
+			return true
+		}
+		if s.Year != "" && normalizeName(strings.TrimSuffix(candidate, "("+s.Year+")")) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func episodeMatches(e episode, parsed *models.ParsedEpisode) bool {
+	switch {
+	case parsed.AirDate != "":
+		return e.Aired == parsed.AirDate
+	case parsed.AbsoluteNumber > 0:
+		return e.AbsoluteNumber == parsed.AbsoluteNumber
+	}
+	return e.SeasonNumber == parsed.Season && e.Number == parsed.Episode
+}
+
+// get performs an authenticated GET and decodes the JSON body into out,
+// logging in again once if the token has expired.
+func (c *Client) get(ctx context.Context, path string, params url.Values, out any) error {
+	token, err := c.login(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = c.do(ctx, path, params, token, out)
+	if errors.Is(err, ErrUnauthorized) {
+		if token, err = c.login(ctx, true); err != nil {
+			return err
+		}
+		err = c.do(ctx, path, params, token, out)
+	}
+	return err
+}
+
+func (c *Client) do(ctx context.Context, path string, params url.Values, token string, out any) error {
+	reqURL := c.baseURL + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return c.send(req, out)
+}
+
+// login returns the session token, logging in when there is none yet or
+// when renew is set.
+func (c *Client) login(ctx context.Context, renew bool) (string, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	if c.token != "" && !renew {
+		return c.token, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"apikey": c.apiKey, "pin": c.pin})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/login", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result response[struct {
+		Token string `json:"token"`
+	}]
+	if err := c.send(req, &result); err != nil {
+		return "", fmt.Errorf("logging in: %w", err)
+	}
+	if result.Data.Token == "" {
+		return "", errors.New("tvdb: login returned no token")
+	}
+	c.token = result.Data.Token
+	return c.token, nil
+}
+
+func (c *Client) send(req *http.Request, out any) error {
+	req.Header.Set(headerUserAgent, userAgentValue)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return fmt.Errorf("sending request: %w", err)
+		}
+		return models.Fail(models.ErrProviderUnavailable, fmt.Errorf("sending request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", req.URL.Path, ErrNotFound)
+	default:
+		err := fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return models.Fail(models.StatusFailure(resp.StatusCode), err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
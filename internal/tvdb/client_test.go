@@ -0,0 +1,137 @@
+package tvdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/models"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+
+	return NewClient(&config.Config{
+		TVDBAPIKey:     "key",
+		TVDBAPIBaseURL: ts.URL + "/",
+	}, ts.Client())
+}
+
+func TestSelectEpisode(t *testing.T) {
+	logins := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			logins++
+			w.Write([]byte(`{"status":"success","data":{"token":"tok"}}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/search":
+			if query.Get("query") != "Breaking Bad" || query.Get("type") != "series" {
+				t.Errorf("Unexpected search %v", query)
+			}
+			w.Write([]byte(`{"status":"success","data":[{"tvdb_id":"900","name":"Breaking Bad Extras","year":"2009"},{"tvdb_id":"81189","name":"Breaking Bad","year":"2008"}]}`))
+		case "/series/900/episodes/default":
+			http.NotFound(w, r)
+		case "/series/81189/episodes/default":
+			if query.Get("season") != "1" || query.Get("episodeNumber") != "2" {
+				t.Errorf("Unexpected episode query %v", query)
+			}
+			w.Write([]byte(`{"status":"success","data":{"series":{"id":81189,"name":"Breaking Bad","year":"2008"},"episodes":[{"id":349232,"name":"Cat's in the Bag...","aired":"2008-01-27","seasonNumber":1,"number":2,"absoluteNumber":2}]},"links":{"next":null}}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	})
+
+	parsed := &models.ParsedEpisode{Series: "Breaking Bad", Season: 1, Episode: 2}
+	series, err := client.SearchSeries(context.Background(), parsed.Series, 0)
+	if err != nil || len(series) != 2 {
+		t.Fatalf("SearchSeries = %+v, %v", series, err)
+	}
+	ep, confidence, err := client.SelectEpisode(context.Background(), series, parsed)
+	if err != nil {
+		t.Fatalf("SelectEpisode failed: %v", err)
+	}
+	if ep == nil || ep.ID != 349232 || ep.SeriesName != "Breaking Bad" || ep.Aired != "2008-01-27" {
+		t.Fatalf("Unexpected episode: %+v", ep)
+	}
+	if confidence != "high" {
+		t.Errorf("Expected high confidence for the exact series name, got %q", confidence)
+	}
+	if logins != 1 {
+		t.Errorf("Expected one login, got %d", logins)
+	}
+}
+
+func TestFindEpisode_Absolute(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Write([]byte(`{"status":"success","data":{"token":"tok"}}`))
+		case "/series/7/episodes/default":
+			if r.URL.Query().Has("season") {
+				t.Errorf("Absolute lookups should not filter by season: %v", r.URL.Query())
+			}
+			if r.URL.Query().Get("page") == "0" {
+				w.Write([]byte(`{"data":{"series":{"id":7,"name":"Frieren"},"episodes":[{"id":1,"seasonNumber":1,"number":1,"absoluteNumber":1}]},"links":{"next":"page=1"}}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"series":{"id":7,"name":"Frieren"},"episodes":[{"id":12,"seasonNumber":1,"number":12,"absoluteNumber":12}]},"links":{"next":null}}`))
+		}
+	})
+
+	ep, err := client.FindEpisode(context.Background(), 7, &models.ParsedEpisode{Series: "Frieren", AbsoluteNumber: 12})
+	if err != nil {
+		t.Fatalf("FindEpisode failed: %v", err)
+	}
+	if ep == nil || ep.ID != 12 {
+		t.Errorf("Expected episode 12 from the second page, got %+v", ep)
+	}
+
+	ep, err = client.FindEpisode(context.Background(), 7, &models.ParsedEpisode{Series: "Frieren", AbsoluteNumber: 99})
+	if err != nil || ep != nil {
+		t.Errorf("Expected no episode, got %+v, %v", ep, err)
+	}
+}
+
+func TestLogin_Renew(t *testing.T) {
+	logins := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			logins++
+			if logins > 2 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"data":{"token":"tok` + string(rune('0'+logins)) + `"}}`))
+			return
+		}
+		// The first token has expired
+		if r.Header.Get("Authorization") != "Bearer tok2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	if _, err := client.SearchSeries(context.Background(), "Saga", 0); err != nil {
+		t.Fatalf("SearchSeries failed after renewing the token: %v", err)
+	}
+	if logins != 2 {
+		t.Errorf("Expected a second login, got %d", logins)
+	}
+
+	client.token = "stale"
+	if _, err := client.SearchSeries(context.Background(), "Saga", 0); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized, got %v", err)
+	}
+}
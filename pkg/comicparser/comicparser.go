@@ -0,0 +1,341 @@
+// Package comicparser is the stable, embeddable API for identifying comic
+// files: filename parsing, ComicVine lookup and match selection, plus the
+// SQLite store used by the comic-parser binary. Programs can use it instead
+// of shelling out to the command line tool.
+package comicparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"comic-parser/internal/config"
+	"comic-parser/internal/covers"
+	"comic-parser/internal/llm"
+	"comic-parser/internal/media"
+	"comic-parser/internal/models"
+	"comic-parser/internal/parser"
+	"comic-parser/internal/processor"
+	"comic-parser/internal/provider"
+	"comic-parser/internal/selector"
+	"comic-parser/internal/storage"
+)
+
+// Built-in parser names
+const (
+	ParserRegex = "regex"
+	ParserLLM   = "llm"
+)
+
+// defaultHTTPTimeout matches the timeout used by the command line tool
+const defaultHTTPTimeout = 60 * time.Second
+
+// Public types shared with the rest of the tool.
+type (
+	ParsedFilename = models.ParsedFilename
+	Issue          = models.ComicVineIssue
+	Volume         = models.VolumeRef
+	Match          = models.MatchResult
+	Result         = models.ProcessingResult
+	Date           = models.Date
+	BatchProgress  = models.BatchProgress
+
+	// Parser extracts title, issue number and other details from a filename.
+	Parser = parser.Parser
+	// Selector picks the best ComicVine candidate for a parsed filename.
+	Selector = selector.Selector
+	// Store persists parses and results in SQLite.
+	Store = storage.Storage
+
+	// Event reports a file IdentifyAll started, finished or skipped, with
+	// a snapshot of the batch's progress.
+	Event = processor.Event
+	// EventType identifies what happened to the file of an Event.
+	EventType = processor.EventType
+	// EventHandler receives events. It is called from worker goroutines, so
+	// it must be safe for concurrent use and should return quickly.
+	EventHandler = processor.EventHandler
+)
+
+// Event types
+const (
+	EventFileStarted  = processor.EventFileStarted
+	EventFileFinished = processor.EventFileFinished
+	EventFileSkipped  = processor.EventFileSkipped
+)
+
+// ErrBudgetExceeded is returned once the configured LLM spend budget is used up.
+var ErrBudgetExceeded = llm.ErrBudgetExceeded
+
+// Options configures an Identifier. Zero values select the same defaults as
+// the command line tool.
+type Options struct {
+	// API keys. The ComicVine key is needed by Identify; the Anthropic key is
+	// needed by the LLM parser and the default LLM selector unless
+	// LLMProvider is "openai".
+	AnthropicAPIKey string
+	ComicVineAPIKey string
+	OpenAIAPIKey    string
+
+	// LLMProvider selects the LLM backend, "anthropic" (default) or "openai"
+	// for OpenAI-compatible servers including Ollama and LM Studio, which
+	// also need Model and usually LLMBaseURL.
+	LLMProvider string
+	LLMBaseURL  string
+
+	// Parser is the built-in parser to use, ParserRegex (default) or ParserLLM.
+	// CustomParser takes precedence when set.
+	Parser       string
+	CustomParser Parser
+
+	// Selector overrides the default LLM-based match selection, e.g. with
+	// NewHeuristicSelector to identify files without an LLM.
+	Selector Selector
+
+	// Model overrides the LLM model.
+	Model string
+
+	// Workers is the number of files IdentifyAll identifies at once
+	// (default 3).
+	Workers int
+
+	// Provider names the metadata provider Identify searches (default
+	// "comicvine").
+	Provider string
+
+	// Base URLs override the API endpoints, e.g. for testing or proxies.
+	AnthropicBaseURL string
+	ComicVineBaseURL string
+
+	// CacheDir, when set, caches ComicVine responses on disk there for a
+	// week. Caching is off by default.
+	CacheDir string
+
+	// CoverDir, when set, makes Identify download the cover images of
+	// matched issues into a cache there (see the covers command).
+	CoverDir string
+
+	// HTTPClient is used for all API requests. Defaults to a client with a 60s timeout.
+	HTTPClient *http.Client
+
+	// LLM spend limits for the lifetime of the Identifier (0 means unlimited).
+	MaxLLMTokens int
+	MaxLLMCost   float64
+}
+
+// Identifier parses and identifies comic filenames. It is safe for
+// concurrent use.
+type Identifier struct {
+	cfg       *config.Config
+	llmClient *llm.Client
+	parser    Parser
+	selector  Selector
+	proc      *processor.Processor
+
+	// batchMu runs one IdentifyAll at a time, as the processor tracks the
+	// progress of a single batch
+	batchMu sync.Mutex
+
+	providerErr error
+}
+
+// New creates an Identifier from opts.
+func New(opts Options) (*Identifier, error) {
+	cfg := config.DefaultConfig()
+	cfg.AnthropicAPIKey = opts.AnthropicAPIKey
+	cfg.ComicVineAPIKey = opts.ComicVineAPIKey
+	cfg.OpenAIAPIKey = opts.OpenAIAPIKey
+	cfg.MaxLLMTokens = opts.MaxLLMTokens
+	cfg.MaxLLMCost = opts.MaxLLMCost
+	if opts.LLMProvider != "" {
+		cfg.LLMProvider = opts.LLMProvider
+	}
+	cfg.LLMModel = opts.Model
+	cfg.LLMBaseURL = opts.LLMBaseURL
+	if opts.AnthropicBaseURL != "" {
+		cfg.AnthropicAPIBaseURL = opts.AnthropicBaseURL
+	}
+	if opts.ComicVineBaseURL != "" {
+		cfg.ComicVineAPIBaseURL = opts.ComicVineBaseURL
+	}
+	if opts.Provider != "" {
+		cfg.MetadataProvider = opts.Provider
+	}
+	if opts.Workers > 0 {
+		cfg.WorkerCount = opts.Workers
+	}
+	cfg.CacheEnabled = opts.CacheDir != ""
+	cfg.CacheDir = opts.CacheDir
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+
+	llmClient := llm.NewClient(cfg, httpClient)
+
+	p := opts.CustomParser
+	if p == nil {
+		switch opts.Parser {
+		case "", ParserRegex:
+			p = parser.NewRegexParser()
+		case ParserLLM:
+			if !llmConfigured(cfg) {
+				llmClient.Close()
+				return nil, errors.New("comicparser: the llm parser requires an Anthropic API key or an openai Model")
+			}
+			if cfg.LLMBatchSize > 1 {
+				p = parser.NewBatchLLMParser(llmClient, cfg.LLMBatchSize, cfg.RetryAttempts, cfg.RetryDelaySeconds)
+			} else {
+				p = parser.NewLLMParser(llmClient, cfg.RetryAttempts, cfg.RetryDelaySeconds)
+			}
+		default:
+			llmClient.Close()
+			return nil, fmt.Errorf("comicparser: unknown parser %q (must be %s or %s)", opts.Parser, ParserRegex, ParserLLM)
+		}
+	}
+
+	sel := opts.Selector
+	if sel == nil && llmConfigured(cfg) {
+		sel = selector.NewLLMSelector(llmClient, cfg)
+	}
+
+	id := &Identifier{
+		cfg:       cfg,
+		llmClient: llmClient,
+		parser:    p,
+		selector:  sel,
+	}
+	if sel != nil {
+		// Identify reports why the provider is unavailable
+		meta, err := provider.New(cfg.MetadataProvider, cfg, httpClient)
+		if err == nil {
+			id.proc = processor.NewProcessor(cfg, p, meta, sel, nil)
+			if opts.CoverDir != "" {
+				id.proc.SetCovers(covers.NewCache(opts.CoverDir, httpClient))
+			}
+		}
+		id.providerErr = err
+	}
+	return id, nil
+}
+
+// NewHeuristicSelector returns a Selector that scores candidates on title
+// similarity, issue number, year and publisher instead of asking an LLM.
+func NewHeuristicSelector() Selector {
+	return selector.NewHeuristicSelector()
+}
+
+// llmConfigured reports whether cfg has what its LLM provider needs.
+func llmConfigured(cfg *config.Config) bool {
+	if cfg.LLMProvider == llm.ProviderOpenAI {
+		return cfg.LLMModel != ""
+	}
+	return cfg.AnthropicAPIKey != ""
+}
+
+// Parse extracts comic details from a filename without any lookups.
+func (i *Identifier) Parse(ctx context.Context, filename string) (*ParsedFilename, error) {
+	return i.parser.Parse(ctx, &models.ParsedFilename{OriginalFilename: filename})
+}
+
+// Identify parses filename, searches ComicVine and selects the best match.
+// Lookup failures are reported in Result.Error; the returned error is only
+// set for configuration problems and ErrBudgetExceeded.
+func (i *Identifier) Identify(ctx context.Context, filename string) (*Result, error) {
+	if i.proc == nil {
+		if i.providerErr != nil {
+			return nil, fmt.Errorf("comicparser: Identify requires a metadata provider: %w", i.providerErr)
+		}
+		return nil, errors.New("comicparser: Identify requires an LLM (Anthropic API key or openai Model) or a custom Selector")
+	}
+	return i.proc.ProcessFile(ctx, filename)
+}
+
+// IdentifyAll identifies filenames like Identify, Options.Workers at a
+// time, and returns their results in the order of filenames. Progress is
+// reported through the handler registered with OnEvent. Cancelling ctx stops
+// the batch after the files in progress: files that weren't started have nil
+// results and ctx's error is returned. An exhausted LLM budget skips the
+// remaining files the same way and returns ErrBudgetExceeded. Batches run one
+// at a time: a call waits for the batch in progress to finish first.
+func (i *Identifier) IdentifyAll(ctx context.Context, filenames []string) ([]*Result, error) {
+	if i.proc == nil {
+		// Identify explains what is missing
+		_, err := i.Identify(ctx, "")
+		return nil, err
+	}
+	i.batchMu.Lock()
+	defer i.batchMu.Unlock()
+
+	finished := make(chan media.Result, len(filenames))
+	i.proc.RunBatch(ctx, i.proc.Comic(), filenames, finished)
+	close(finished)
+
+	byName := make(map[string]*Result, len(filenames))
+	for r := range finished {
+		result := r.(*Result)
+		byName[result.Filename] = result
+	}
+	results := make([]*Result, len(filenames))
+	for n, filename := range filenames {
+		results[n] = byName[filename]
+	}
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	if i.proc.GetProgress().Skipped > 0 {
+		return results, ErrBudgetExceeded
+	}
+	return results, nil
+}
+
+// OnEvent registers a handler for the per-file events of IdentifyAll,
+// replacing any previous one. Pass nil to stop receiving events. A handler
+// registered during a batch takes over once the batch finishes.
+func (i *Identifier) OnEvent(handler EventHandler) {
+	if i.proc == nil {
+		return
+	}
+	i.batchMu.Lock()
+	defer i.batchMu.Unlock()
+	i.proc.OnEvent(handler)
+}
+
+// EventChannel returns an EventHandler that forwards events to ch, dropping
+// events when ch is full so a slow consumer never stalls the batch.
+func EventChannel(ch chan<- Event) EventHandler {
+	return processor.EventChannel(ch)
+}
+
+// LLMUsage returns the tokens used and estimated cost in USD so far.
+func (i *Identifier) LLMUsage() (inputTokens, outputTokens int, cost float64) {
+	usage := i.llmClient.Usage()
+	return usage.InputTokens, usage.OutputTokens, i.llmClient.EstimatedCost()
+}
+
+// Close releases the Identifier's resources.
+func (i *Identifier) Close() {
+	if i.proc != nil {
+		i.proc.Close()
+	}
+	i.llmClient.Close()
+}
+
+// OpenStore opens (creating if needed) a comic-parser SQLite database.
+func OpenStore(path string) (*Store, error) {
+	return storage.Open(storage.BackendSQLite, path)
+}
+
+// StoreFactory opens a storage backend from a data source name.
+type StoreFactory = storage.Factory
+
+// RegisterStoreBackend makes a storage backend selectable by name through
+// the storage_backend setting. It panics if the name is already registered.
+func RegisterStoreBackend(name string, factory StoreFactory) {
+	storage.Register(name, factory)
+}
@@ -0,0 +1,166 @@
+package comicparser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type stubParser struct{}
+
+func (stubParser) Parse(ctx context.Context, input *ParsedFilename) (*ParsedFilename, error) {
+	return &ParsedFilename{
+		OriginalFilename: input.OriginalFilename,
+		Title:            "Amazing Spider-Man",
+		IssueNumber:      "1",
+		Year:             "2018",
+	}, nil
+}
+
+func TestIdentifier_Parse(t *testing.T) {
+	id, err := New(Options{CustomParser: stubParser{}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer id.Close()
+
+	parsed, err := id.Parse(context.Background(), "Amazing Spider-Man 001 (2018).cbz")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Title != "Amazing Spider-Man" || parsed.OriginalFilename != "Amazing Spider-Man 001 (2018).cbz" {
+		t.Errorf("Unexpected parse: %+v", parsed)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(Options{Parser: "unknown"}); err == nil {
+		t.Error("Expected error for unknown parser")
+	}
+	if _, err := New(Options{Parser: ParserLLM}); err == nil {
+		t.Error("Expected error for llm parser without an API key")
+	}
+
+	id, err := New(Options{ComicVineAPIKey: "cv"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer id.Close()
+	if _, err := id.Identify(context.Background(), "a.cbz"); err == nil {
+		t.Error("Expected Identify to fail without a selector")
+	}
+}
+
+func TestIdentifier_IdentifyAll(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status_code":1,"results":[]}`))
+	}))
+	defer ts.Close()
+
+	id, err := New(Options{
+		ComicVineAPIKey:  "cv",
+		ComicVineBaseURL: ts.URL,
+		CustomParser:     stubParser{},
+		Selector:         NewHeuristicSelector(),
+		Workers:          2,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer id.Close()
+
+	var mu sync.Mutex
+	finished := map[string]bool{}
+	id.OnEvent(func(e Event) {
+		if e.Type == EventFileFinished && e.Progress.Total == 3 {
+			mu.Lock()
+			finished[e.Filename] = e.Result != nil
+			mu.Unlock()
+		}
+	})
+
+	filenames := []string{"a.cbz", "b.cbz", "c.cbz"}
+	results, err := id.IdentifyAll(context.Background(), filenames)
+	if err != nil {
+		t.Fatalf("IdentifyAll failed: %v", err)
+	}
+	for n, result := range results {
+		if result == nil || result.Filename != filenames[n] {
+			t.Errorf("results[%d] = %+v, want the result of %s", n, result, filenames[n])
+		}
+	}
+	if !reflect.DeepEqual(finished, map[string]bool{"a.cbz": true, "b.cbz": true, "c.cbz": true}) {
+		t.Errorf("finished events = %v, want one with a result per file", finished)
+	}
+
+	// A cancelled batch starts no more files
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err = id.IdentifyAll(ctx, filenames)
+	if !errors.Is(err, context.Canceled) || len(results) != len(filenames) || results[0] != nil {
+		t.Fatalf("Expected a cancelled batch, got %v, %v", results, err)
+	}
+}
+
+func TestIdentifier_IdentifyAllConcurrent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status_code":1,"results":[]}`))
+	}))
+	defer ts.Close()
+
+	id, err := New(Options{
+		ComicVineAPIKey:  "cv",
+		ComicVineBaseURL: ts.URL,
+		CustomParser:     stubParser{},
+		Selector:         NewHeuristicSelector(),
+		Workers:          2,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer id.Close()
+
+	// Each finished event reports the progress of its own batch
+	var mu sync.Mutex
+	totals := map[string]int{}
+	id.OnEvent(func(e Event) {
+		if e.Type == EventFileFinished {
+			mu.Lock()
+			totals[e.Filename] = e.Progress.Total
+			mu.Unlock()
+		}
+	})
+
+	batches := [][]string{{"a1.cbz", "a2.cbz"}, {"b1.cbz", "b2.cbz", "b3.cbz"}}
+	errs := make([]error, len(batches))
+	var wg sync.WaitGroup
+	for n, filenames := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := id.IdentifyAll(context.Background(), filenames)
+			for k, result := range results {
+				if result == nil || result.Filename != filenames[k] {
+					err = fmt.Errorf("results[%d] = %+v, want the result of %s", k, result, filenames[k])
+				}
+			}
+			errs[n] = err
+		}()
+	}
+	wg.Wait()
+
+	for n, err := range errs {
+		if err != nil {
+			t.Errorf("batch %d: %v", n, err)
+		}
+	}
+	want := map[string]int{"a1.cbz": 2, "a2.cbz": 2, "b1.cbz": 3, "b2.cbz": 3, "b3.cbz": 3}
+	if !reflect.DeepEqual(totals, want) {
+		t.Errorf("event totals = %v, want %v", totals, want)
+	}
+}